@@ -3,6 +3,7 @@ package configs
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -12,7 +13,9 @@ type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
 	Auth     AuthConfig
+	Mail     MailConfig
 	Log      LogConfig
+	Redis    RedisConfig
 }
 
 type ServerConfig struct {
@@ -32,15 +35,37 @@ type DatabaseConfig struct {
 
 type AuthConfig struct {
 	JWTSecret           string
+	JWTAlgorithm        string            // HS256, RS256 or EdDSA
+	JWTKeyID            string            // kid of the key used to sign new tokens
+	JWTPrivateKeyFile   string            // PEM private key, required for RS256 and EdDSA
+	JWTRetiredKeys      map[string]string // kid -> secret (HS256) or PEM public key file, still accepted when verifying
+	JWTIssuer           string
+	JWTAudience         string
 	AccessTokenExpiry   time.Duration
 	RefreshTokenExpiry  time.Duration
 	PasswordResetExpiry time.Duration
+	PasswordResetURL    string
+}
+
+type MailConfig struct {
+	Driver      string // smtp by default; file and memory are for development and tests
+	Host        string
+	Port        int
+	Username    string
+	Password    string
+	From        string
+	TemplateDir string
+	OutputDir   string // used by the file driver
 }
 
 type LogConfig struct {
 	Level string
 }
 
+type RedisConfig struct {
+	URL string // live attempt channels stay on one instance when empty
+}
+
 func Load() (*Config, error) {
 	// Load .env file if it exists
 	_ = godotenv.Load()
@@ -60,14 +85,34 @@ func Load() (*Config, error) {
 			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
 		},
 		Auth: AuthConfig{
-			JWTSecret:           getEnv("JWT_SECRET", "access-sap-secrets"),
+			JWTSecret:           getEnv("JWT_SECRET", getEnv("SECRET_KEY", "access-sap-secrets")),
+			JWTAlgorithm:        getEnv("JWT_ALGORITHM", "HS256"),
+			JWTKeyID:            getEnv("JWT_KEY_ID", "default"),
+			JWTPrivateKeyFile:   getEnv("JWT_PRIVATE_KEY_FILE", ""),
+			JWTRetiredKeys:      getMapEnv("JWT_RETIRED_KEYS"),
+			JWTIssuer:           getEnv("JWT_ISSUER", "assessment_service"),
+			JWTAudience:         getEnv("JWT_AUDIENCE", "assessment_service"),
 			AccessTokenExpiry:   getDurationEnv("ACCESS_TOKEN_EXPIRY", 30*time.Minute),
 			RefreshTokenExpiry:  getDurationEnv("REFRESH_TOKEN_EXPIRY", 7*24*time.Hour),
 			PasswordResetExpiry: getDurationEnv("PASSWORD_RESET_EXPIRY", 24*time.Hour),
+			PasswordResetURL:    getEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+		},
+		Mail: MailConfig{
+			Driver:      getEnv("MAIL_DRIVER", "smtp"),
+			Host:        getEnv("SMTP_HOST", "localhost"),
+			Port:        getIntEnv("SMTP_PORT", 587),
+			Username:    getEnv("SMTP_USERNAME", ""),
+			Password:    getEnv("SMTP_PASSWORD", ""),
+			From:        getEnv("MAIL_FROM", "no-reply@assessment.local"),
+			TemplateDir: getEnv("MAIL_TEMPLATE_DIR", "assets"),
+			OutputDir:   getEnv("MAIL_OUTPUT_DIR", "log/mail"),
 		},
 		Log: LogConfig{
 			Level: getEnv("LOG_LEVEL", "info"),
 		},
+		Redis: RedisConfig{
+			URL: getEnv("REDIS_URL", ""),
+		},
 	}
 
 	return config, nil
@@ -96,6 +141,18 @@ func getIntEnv(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getMapEnv parses "key1=value1,key2=value2"
+func getMapEnv(key string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(getEnv(key, ""), ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && k != "" {
+			result[k] = v
+		}
+	}
+	return result
+}
+
 func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	valStr := getEnv(key, "")
 	if val, err := time.ParseDuration(valStr); err == nil {
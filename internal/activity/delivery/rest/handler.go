@@ -2,11 +2,12 @@ package rest
 
 import (
 	"assessment_service/internal/activity/service"
+	"assessment_service/internal/middleware"
 	models "assessment_service/internal/model"
 	"assessment_service/internal/util"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/gorilla/mux"
 	"net/http"
 	"strconv"
@@ -49,9 +50,8 @@ func (h *AnalyticsHandler) GetAssessmentPerformanceAnalytics(w http.ResponseWrit
 
 func (h *AnalyticsHandler) ReportActivity(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context
-	claims, _ := r.Context().Value("user").(jwt.MapClaims)
-	userID, exists := claims["id"]
-	if !exists {
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
 		util.ResponseMap(w, map[string]interface{}{
 			"status":  "UNAUTHORIZED",
 			"message": "User ID not found in context",
@@ -74,19 +74,9 @@ func (h *AnalyticsHandler) ReportActivity(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	userIDUINT, err := strconv.ParseUint(userID.(string), 10, 32)
-	if err != nil {
-		util.ResponseMap(w, map[string]interface{}{
-			"status":  "BAD_REQUEST",
-			"message": "Invalid user ID",
-		}, http.StatusBadRequest)
-		return
-
-	}
-
 	// Create activity
 	activity := &models.Activity{
-		UserID:       uint(userIDUINT),
+		UserID:       principal.UserID,
 		Action:       req.Action,
 		AssessmentID: req.AssessmentID,
 		Details:      req.Details,
@@ -114,9 +104,8 @@ func (h *AnalyticsHandler) ReportActivity(w http.ResponseWriter, r *http.Request
 
 func (h *AnalyticsHandler) TrackAssessmentSession(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context
-	claims, _ := r.Context().Value("user").(jwt.MapClaims)
-	userID, exists := claims["id"]
-	if !exists {
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
 		util.ResponseMap(w, map[string]interface{}{
 			"status":  "UNAUTHORIZED",
 			"message": "User ID not found in context",
@@ -149,19 +138,9 @@ func (h *AnalyticsHandler) TrackAssessmentSession(w http.ResponseWriter, r *http
 		return
 	}
 
-	userIDUINT, err := strconv.ParseUint(userID.(string), 10, 32)
-	if err != nil {
-		util.ResponseMap(w, map[string]interface{}{
-			"status":  "BAD_REQUEST",
-			"message": "Invalid user ID",
-		}, http.StatusBadRequest)
-		return
-
-	}
-
 	// Create session data
 	sessionData := &models.SessionData{
-		UserID:       uint(userIDUINT),
+		UserID:       principal.UserID,
 		AssessmentID: uint(id),
 		Action:       req.Action,
 		UserAgent:    req.UserAgent,
@@ -191,9 +170,8 @@ func (h *AnalyticsHandler) TrackAssessmentSession(w http.ResponseWriter, r *http
 
 func (h *AnalyticsHandler) LogSuspiciousActivity(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context
-	claims, _ := r.Context().Value("user").(jwt.MapClaims)
-	userID, exists := claims["userID"]
-	if !exists {
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
 		util.ResponseMap(w, map[string]interface{}{
 			"status":  "UNAUTHORIZED",
 			"message": "User ID not found in context",
@@ -228,15 +206,6 @@ func (h *AnalyticsHandler) LogSuspiciousActivity(w http.ResponseWriter, r *http.
 		return
 	}
 
-	userIDUINT, err := strconv.ParseUint(userID.(string), 10, 32)
-	if err != nil {
-		util.ResponseMap(w, map[string]interface{}{
-			"status":  "BAD_REQUEST",
-			"message": "Invalid user ID",
-		}, http.StatusBadRequest)
-		return
-	}
-
 	attemptID, err := strconv.ParseUint(req.AttemptID, 10, 32)
 	if err != nil {
 		util.ResponseMap(w, map[string]interface{}{
@@ -249,7 +218,7 @@ func (h *AnalyticsHandler) LogSuspiciousActivity(w http.ResponseWriter, r *http.
 	// Create suspicious activity
 	activity := &models.SuspiciousActivity{
 		AttemptID:    uint(attemptID),
-		UserID:       uint(userIDUINT),
+		UserID:       principal.UserID,
 		AssessmentID: uint(assIDUINT),
 		Type:         req.Type,
 		Details:      req.Details,
@@ -280,7 +249,26 @@ func (h *AnalyticsHandler) LogSuspiciousActivity(w http.ResponseWriter, r *http.
 	}
 
 	newErr := h.analyticsService.LogSuspiciousActivity(activity)
-	if newErr != nil {
+	switch {
+	case errors.Is(newErr, service.ErrAttemptNotFound):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "NOT_FOUND",
+			"message": newErr.Error(),
+		}, http.StatusNotFound)
+		return
+	case errors.Is(newErr, service.ErrNotAttemptOwner):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "FORBIDDEN",
+			"message": newErr.Error(),
+		}, http.StatusForbidden)
+		return
+	case errors.Is(newErr, service.ErrAttemptNotInProgress):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "CONFLICT",
+			"message": newErr.Error(),
+		}, http.StatusConflict)
+		return
+	case newErr != nil:
 		util.ResponseMap(w, map[string]interface{}{
 			"status":  "ERROR",
 			"message": "Failed to log suspicious activity",
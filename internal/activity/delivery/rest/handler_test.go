@@ -2,10 +2,11 @@ package rest
 
 import (
 	// Không import mock service nữa
+	"assessment_service/internal/activity/service"
+	"assessment_service/internal/middleware"
 	models "assessment_service/internal/model"
 	"assessment_service/internal/util"
 	"bytes"
-	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,7 +15,6 @@ import (
 	"testing"
 	"time" // Cần thiết cho LogSuspiciousActivity
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -81,12 +81,11 @@ func (m *MockAnalyticsService) GetSuspiciousActivity(userID uint, attemptID uint
 	return args.Get(0).([]models.SuspiciousActivity), args.Get(1).(int64), args.Error(2)
 }
 
-// Helper function to create a request with context containing JWT claims
-func createRequestWithActivityClaims(method, url string, body []byte, claims jwt.MapClaims) *http.Request {
+// Helper function to create a request with context containing the authenticated principal
+func createRequestWithActivityClaims(method, url string, body []byte, principal *middleware.Principal) *http.Request {
 	req := httptest.NewRequest(method, url, bytes.NewBuffer(body))
-	if claims != nil {
-		// Key "user" phải khớp với key được sử dụng trong middleware AuthMiddleware
-		ctx := context.WithValue(req.Context(), "user", claims)
+	if principal != nil {
+		ctx := middleware.WithPrincipal(req.Context(), principal)
 		req = req.WithContext(ctx)
 	}
 	return req
@@ -178,8 +177,8 @@ func TestAnalyticsHandler_ReportActivity(t *testing.T) {
 	}
 	body, _ := json.Marshal(activityReq)
 
-	// Sử dụng string cho userID trong claims như trong handler gốc
-	claims := jwt.MapClaims{"id": "123"} // Handler gốc lấy "id" và convert
+	// userID lấy từ Principal do AuthMiddleware đặt vào context
+	principal := &middleware.Principal{UserID: 123, Role: "student"} // Handler gốc lấy "id" và convert
 
 	mockService.On("ReportActivity", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
 		arg := args.Get(0).(*models.Activity)
@@ -187,7 +186,7 @@ func TestAnalyticsHandler_ReportActivity(t *testing.T) {
 		arg.UserID = 123 // Gán UserID để kiểm tra response
 	})
 
-	req := createRequestWithActivityClaims(http.MethodPost, "/analytics/activity", body, claims)
+	req := createRequestWithActivityClaims(http.MethodPost, "/analytics/activity", body, principal)
 	rr := httptest.NewRecorder()
 	router := mux.NewRouter()
 	router.HandleFunc("/analytics/activity", handler.ReportActivity)
@@ -209,7 +208,7 @@ func TestAnalyticsHandler_ReportActivity_NoUserIDInContext(t *testing.T) {
 	activityReq := map[string]interface{}{"action": "TEST"}
 	body, _ := json.Marshal(activityReq)
 
-	req := createRequestWithActivityClaims(http.MethodPost, "/analytics/activity", body, nil) // Không có claims
+	req := createRequestWithActivityClaims(http.MethodPost, "/analytics/activity", body, nil) // Không có Principal
 	rr := httptest.NewRecorder()
 	router := mux.NewRouter()
 	router.HandleFunc("/analytics/activity", handler.ReportActivity)
@@ -224,9 +223,9 @@ func TestAnalyticsHandler_ReportActivity_InvalidInput(t *testing.T) {
 	handler := NewAnalyticsHandler(mockService)
 
 	invalidBody := []byte(`{"action":`) // Invalid JSON
-	claims := jwt.MapClaims{"id": "123"}
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
 
-	req := createRequestWithActivityClaims(http.MethodPost, "/analytics/activity", invalidBody, claims)
+	req := createRequestWithActivityClaims(http.MethodPost, "/analytics/activity", invalidBody, principal)
 	rr := httptest.NewRecorder()
 	router := mux.NewRouter()
 	router.HandleFunc("/analytics/activity", handler.ReportActivity)
@@ -242,11 +241,11 @@ func TestAnalyticsHandler_ReportActivity_ServiceError(t *testing.T) {
 
 	activityReq := map[string]interface{}{"action": "TEST"}
 	body, _ := json.Marshal(activityReq)
-	claims := jwt.MapClaims{"id": "123"}
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
 
 	mockService.On("ReportActivity", mock.Anything).Return(errors.New("report error"))
 
-	req := createRequestWithActivityClaims(http.MethodPost, "/analytics/activity", body, claims)
+	req := createRequestWithActivityClaims(http.MethodPost, "/analytics/activity", body, principal)
 	rr := httptest.NewRecorder()
 	router := mux.NewRouter()
 	router.HandleFunc("/analytics/activity", handler.ReportActivity)
@@ -266,13 +265,13 @@ func TestAnalyticsHandler_TrackAssessmentSession(t *testing.T) {
 		"userAgent": "test-agent",
 	}
 	body, _ := json.Marshal(sessionReq)
-	claims := jwt.MapClaims{"id": "123"} // Handler gốc lấy "id"
+	principal := &middleware.Principal{UserID: 123, Role: "student"} // Handler gốc lấy "id"
 
 	mockService.On("TrackAssessmentSession", mock.MatchedBy(func(sd *models.SessionData) bool {
 		return sd.UserID == uint(123) && sd.AssessmentID == assessmentID && sd.Action == "SESSION_START"
 	})).Return(nil)
 
-	req := createRequestWithActivityClaims(http.MethodPost, fmt.Sprintf("/analytics/assessments/%d/session", assessmentID), body, claims)
+	req := createRequestWithActivityClaims(http.MethodPost, fmt.Sprintf("/analytics/assessments/%d/session", assessmentID), body, principal)
 	rr := httptest.NewRecorder()
 	router := mux.NewRouter()
 	router.HandleFunc("/analytics/assessments/{id:[0-9]+}/session", handler.TrackAssessmentSession)
@@ -294,10 +293,10 @@ func TestAnalyticsHandler_TrackAssessmentSession(t *testing.T) {
 func TestAnalyticsHandler_TrackAssessmentSession_InvalidID(t *testing.T) {
 	mockService := new(MockAnalyticsService)
 	handler := NewAnalyticsHandler(mockService)
-	claims := jwt.MapClaims{"id": "123"}
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
 	body := []byte(`{"action":"START"}`)
 
-	req := createRequestWithActivityClaims(http.MethodPost, "/analytics/assessments/invalid/session", body, claims)
+	req := createRequestWithActivityClaims(http.MethodPost, "/analytics/assessments/invalid/session", body, principal)
 	rr := httptest.NewRecorder()
 	router := mux.NewRouter()
 	router.HandleFunc("/analytics/assessments/{id}/session", handler.TrackAssessmentSession) // Route không có regex
@@ -313,11 +312,11 @@ func TestAnalyticsHandler_TrackAssessmentSession_ServiceError(t *testing.T) {
 	assessmentID := uint(1)
 	sessionReq := map[string]interface{}{"action": "START"}
 	body, _ := json.Marshal(sessionReq)
-	claims := jwt.MapClaims{"id": "123"}
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
 
 	mockService.On("TrackAssessmentSession", mock.Anything).Return(errors.New("track error"))
 
-	req := createRequestWithActivityClaims(http.MethodPost, fmt.Sprintf("/analytics/assessments/%d/session", assessmentID), body, claims)
+	req := createRequestWithActivityClaims(http.MethodPost, fmt.Sprintf("/analytics/assessments/%d/session", assessmentID), body, principal)
 	rr := httptest.NewRecorder()
 	router := mux.NewRouter()
 	router.HandleFunc("/analytics/assessments/{id:[0-9]+}/session", handler.TrackAssessmentSession)
@@ -339,14 +338,14 @@ func TestAnalyticsHandler_LogSuspiciousActivity(t *testing.T) {
 		"timestamp":    time.Now().Format(time.RFC3339), // Thêm timestamp hợp lệ
 	}
 	body, _ := json.Marshal(suspiciousReq)
-	claims := jwt.MapClaims{"userID": "123"} // Handler lấy userID từ claims
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
 
 	mockService.On("LogSuspiciousActivity", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
 		arg := args.Get(0).(*models.SuspiciousActivity)
 		arg.ID = 99 // Simulate ID assignment
 	})
 
-	req := createRequestWithActivityClaims(http.MethodPost, "/analytics/suspicious", body, claims)
+	req := createRequestWithActivityClaims(http.MethodPost, "/analytics/suspicious", body, principal)
 	rr := httptest.NewRecorder()
 	router := mux.NewRouter()
 	router.HandleFunc("/analytics/suspicious", handler.LogSuspiciousActivity)
@@ -366,9 +365,9 @@ func TestAnalyticsHandler_LogSuspiciousActivity_InvalidInput(t *testing.T) {
 	handler := NewAnalyticsHandler(mockService)
 
 	invalidBody := []byte(`{"type":`) // JSON không hợp lệ
-	claims := jwt.MapClaims{"userID": "123"}
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
 
-	req := createRequestWithActivityClaims(http.MethodPost, "/analytics/suspicious", invalidBody, claims)
+	req := createRequestWithActivityClaims(http.MethodPost, "/analytics/suspicious", invalidBody, principal)
 	rr := httptest.NewRecorder()
 	router := mux.NewRouter()
 	router.HandleFunc("/analytics/suspicious", handler.LogSuspiciousActivity)
@@ -386,9 +385,9 @@ func TestAnalyticsHandler_LogSuspiciousActivity_InvalidTimestamp(t *testing.T) {
 		"attemptID": "100", "assessmentId": "1", "type": "TEST", "timestamp": "invalid-date",
 	}
 	body, _ := json.Marshal(suspiciousReq)
-	claims := jwt.MapClaims{"userID": "123"}
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
 
-	req := createRequestWithActivityClaims(http.MethodPost, "/analytics/suspicious", body, claims)
+	req := createRequestWithActivityClaims(http.MethodPost, "/analytics/suspicious", body, principal)
 	rr := httptest.NewRecorder()
 	router := mux.NewRouter()
 	router.HandleFunc("/analytics/suspicious", handler.LogSuspiciousActivity)
@@ -404,11 +403,11 @@ func TestAnalyticsHandler_LogSuspiciousActivity_ServiceError(t *testing.T) {
 
 	suspiciousReq := map[string]interface{}{"attemptID": "100", "assessmentId": "1", "type": "TEST"}
 	body, _ := json.Marshal(suspiciousReq)
-	claims := jwt.MapClaims{"userID": "123"}
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
 
 	mockService.On("LogSuspiciousActivity", mock.Anything).Return(errors.New("log error"))
 
-	req := createRequestWithActivityClaims(http.MethodPost, "/analytics/suspicious", body, claims)
+	req := createRequestWithActivityClaims(http.MethodPost, "/analytics/suspicious", body, principal)
 	rr := httptest.NewRecorder()
 	router := mux.NewRouter()
 	router.HandleFunc("/analytics/suspicious", handler.LogSuspiciousActivity)
@@ -418,6 +417,38 @@ func TestAnalyticsHandler_LogSuspiciousActivity_ServiceError(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestAnalyticsHandler_LogSuspiciousActivity_AttemptRefused(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"not found", service.ErrAttemptNotFound, http.StatusNotFound},
+		{"other user", service.ErrNotAttemptOwner, http.StatusForbidden},
+		{"not in progress", service.ErrAttemptNotInProgress, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAnalyticsService)
+			handler := NewAnalyticsHandler(mockService)
+
+			body, _ := json.Marshal(map[string]interface{}{"attemptID": "100", "assessmentId": "1", "type": "TEST"})
+			principal := &middleware.Principal{UserID: 123, Role: "student"}
+			mockService.On("LogSuspiciousActivity", mock.Anything).Return(tt.err)
+
+			req := createRequestWithActivityClaims(http.MethodPost, "/analytics/suspicious", body, principal)
+			rr := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/analytics/suspicious", handler.LogSuspiciousActivity)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.err.Error())
+		})
+	}
+}
+
 func TestAnalyticsHandler_GetDashboardSummary(t *testing.T) {
 	mockService := new(MockAnalyticsService)
 	handler := NewAnalyticsHandler(mockService)
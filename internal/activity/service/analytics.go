@@ -4,13 +4,26 @@ import (
 	repository4 "assessment_service/internal/activity/repository"
 	repository2 "assessment_service/internal/assessments/repository"
 	repository3 "assessment_service/internal/attempts/repository"
+	"assessment_service/internal/live"
 	models "assessment_service/internal/model"
 	"assessment_service/internal/users/repository"
 	"assessment_service/internal/util"
+	"errors"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 	"time"
 )
 
+var (
+	// ErrAttemptNotFound is returned when suspicious activity is logged for an attempt that does not exist
+	ErrAttemptNotFound = errors.New("attempt not found")
+	// ErrNotAttemptOwner is returned when suspicious activity is logged for another user's attempt
+	ErrNotAttemptOwner = errors.New("attempt does not belong to you")
+	// ErrAttemptNotInProgress is returned when suspicious activity is logged for an attempt that is
+	// not in progress
+	ErrAttemptNotInProgress = errors.New("attempt is not in progress")
+)
+
 type AnalyticsService interface {
 	GetUserActivityAnalytics() (map[string]interface{}, error)
 	GetAssessmentPerformanceAnalytics() (map[string]interface{}, error)
@@ -28,6 +41,7 @@ type analyticsService struct {
 	assessmentRepo repository2.AssessmentRepository
 	attemptRepo    repository3.AttemptRepository
 	activityRepo   repository4.ActivityRepository
+	hub            live.Publisher
 	log            *zap.Logger
 }
 
@@ -36,6 +50,7 @@ func NewAnalyticsService(
 	assessmentRepo repository2.AssessmentRepository,
 	attemptRepo repository3.AttemptRepository,
 	activityRepo repository4.ActivityRepository,
+	hub live.Publisher,
 	log *zap.Logger,
 ) AnalyticsService {
 	return &analyticsService{
@@ -43,6 +58,7 @@ func NewAnalyticsService(
 		assessmentRepo: assessmentRepo,
 		attemptRepo:    attemptRepo,
 		activityRepo:   activityRepo,
+		hub:            hub,
 		log:            log,
 	}
 }
@@ -168,12 +184,40 @@ func (s *analyticsService) TrackAssessmentSession(sessionData *models.SessionDat
 }
 
 func (s *analyticsService) LogSuspiciousActivity(activity *models.SuspiciousActivity) error {
+	// Only the student taking the attempt reports on it, and only while it is in progress
+	attempt, err := s.attemptRepo.FindByID(activity.AttemptID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAttemptNotFound
+		}
+		s.log.Error("[AnalyticsService][LogSuspiciousActivity] failed to find attempt", zap.Error(err))
+		return err
+	}
+	if attempt.UserID != activity.UserID {
+		return ErrNotAttemptOwner
+	}
+	if attempt.Status != models.AttemptInProgress {
+		return ErrAttemptNotInProgress
+	}
+	activity.AssessmentID = attempt.AssessmentID
+
 	// Set timestamp if not provided
 	if activity.Timestamp.IsZero() {
 		activity.Timestamp = time.Now()
 	}
 
-	return s.attemptRepo.SaveSuspiciousActivity(activity)
+	if err := s.attemptRepo.SaveSuspiciousActivity(activity); err != nil {
+		return err
+	}
+
+	// Proctors watching the assessment see the event as it happens
+	live.Send(s.hub, models.NewAttemptEvent(attempt, models.LiveEventSuspicious, map[string]interface{}{
+		"type":     activity.Type,
+		"severity": activity.Severity,
+		"activity": activity,
+	}), s.log)
+
+	return nil
 }
 
 func (s *analyticsService) GetDashboardSummary() (map[string]interface{}, error) {
@@ -247,7 +291,9 @@ func (s *analyticsService) GetDashboardSummary() (map[string]interface{}, error)
 			"total":       assessmentStats["totalAssessments"],
 			"active":      assessmentStats["activeAssessments"],
 			"draft":       assessmentStats["draftAssessments"],
-			"expired":     assessmentStats["expiredAssessments"],
+			"scheduled":   assessmentStats["scheduledAssessments"],
+			"closed":      assessmentStats["closedAssessments"],
+			"archived":    assessmentStats["archivedAssessments"],
 			"newThisWeek": newThisWeek, // Placeholder - need to implement this in repository
 		},
 		"activity": map[string]interface{}{
@@ -4,14 +4,24 @@ import (
 	"assessment_service/internal/activity/delivery/rest"
 	"assessment_service/internal/activity/service"
 	assessment_handler "assessment_service/internal/assessments/delivery/rest"
+	"assessment_service/internal/assessments/policy"
 	assessment_service "assessment_service/internal/assessments/service"
 	"assessment_service/internal/attempts/delivery"
 	service3 "assessment_service/internal/attempts/service"
+	auth_handler "assessment_service/internal/auth/delivery/rest"
+	auth_service "assessment_service/internal/auth/service"
+	bank_handler "assessment_service/internal/bank/delivery/rest"
+	bank_service "assessment_service/internal/bank/service"
+	group_handler "assessment_service/internal/groups/delivery/rest"
+	group_service "assessment_service/internal/groups/service"
+	"assessment_service/internal/live"
 	"assessment_service/internal/middleware"
 	question_handler "assessment_service/internal/questions/delivery/rest"
 	question_service "assessment_service/internal/questions/service"
 	rest2 "assessment_service/internal/student/delivery/rest"
 	service2 "assessment_service/internal/student/service"
+	user_handler "assessment_service/internal/users/delivery/rest"
+	user_service "assessment_service/internal/users/service"
 	"assessment_service/internal/util"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
@@ -20,14 +30,25 @@ import (
 
 func SetupRoutes(
 	assessmentService assessment_service.AssessmentService,
+	collaboratorService assessment_service.CollaboratorService,
+	assignmentService assessment_service.AssignmentService,
+	accommodationService assessment_service.AccommodationService,
+	groupService group_service.GroupService,
 	questionService question_service.QuestionService,
+	sectionService question_service.SectionService,
+	bankService bank_service.BankService,
 	analyticsService service.AnalyticsService,
 	studentService service2.StudentService,
 	attemptService service3.AttemptService,
+	authService auth_service.AuthService,
+	userService user_service.UserService,
+	assessmentPolicy policy.AssessmentPolicy,
+	hub live.Hub,
+	jwtService util.Jwt,
+	keySet util.KeySet,
 	log *zap.Logger,
 ) *mux.Router {
 	router := mux.NewRouter()
-	jwtService := util.NewJwtImpl()
 
 	loggingMiddleware := middleware.NewLogMiddleware(log)
 	authMiddleware := middleware.NewAuthMiddleware(jwtService)
@@ -37,15 +58,52 @@ func SetupRoutes(
 	})
 
 	router.Use(loggingMiddleware.LoggingMiddleware)
-	router.Use(authMiddleware.AuthMiddleware())
+	router.Use(authMiddleware.AuthMiddleware("/auth/", "/health", "/.well-known/"))
 	// router.Use(middleware.CORSMiddleware)
-	// router.Use(authMiddleware.OwnerMiddleware())
+	guard := policy.NewGuard(assessmentPolicy, log)
 	// Assessments
 	assessmentHandler := assessment_handler.NewAssessmentHandler(assessmentService, log)
+	collaboratorHandler := assessment_handler.NewCollaboratorHandler(collaboratorService, log)
+	assignmentHandler := assessment_handler.NewAssignmentHandler(assignmentService, log)
+	accommodationHandler := assessment_handler.NewAccommodationHandler(accommodationService, log)
+	groupHandler := group_handler.NewGroupHandler(groupService, log)
 	questionHandler := question_handler.NewQuestionHandler(questionService, log)
+	sectionHandler := question_handler.NewSectionHandler(sectionService, log)
+	bankHandler := bank_handler.NewBankHandler(bankService, log)
 	analyticsHandler := rest.NewAnalyticsHandler(analyticsService)
 	studentHandler := rest2.NewStudentHandler(studentService, log)
+	liveHandler := rest2.NewLiveHandler(studentService, hub, log)
 	attemptHandler := delivery.NewAttemptHandler(attemptService, log)
+	proctoringHandler := delivery.NewProctoringHandler(attemptService, hub, log)
+	authHandler := auth_handler.NewAuthHandler(authService, log)
+	userHandler := user_handler.NewUserHandler(userService, log)
+
+	// Authentication (public, exempted from AuthMiddleware)
+	authRouter := router.PathPrefix("/auth").Subrouter()
+	authRouter.HandleFunc("/login", authHandler.Login).Methods("POST")
+	authRouter.HandleFunc("/register", authHandler.Register).Methods("POST")
+	authRouter.HandleFunc("/refresh-token", authHandler.RefreshToken).Methods("POST")
+	authRouter.HandleFunc("/logout", authHandler.Logout).Methods("POST")
+	authRouter.HandleFunc("/forgot-password", authHandler.ForgotPassword).Methods("POST")
+	authRouter.HandleFunc("/reset-password", authHandler.ResetPassword).Methods("POST")
+	router.HandleFunc("/.well-known/jwks.json", auth_handler.JWKS(keySet)).Methods("GET")
+
+	// Users: admins manage every account, other users can only read and update their own
+	usersRouter := router.PathPrefix("/users").Subrouter()
+	usersRouter.HandleFunc("/{id:[0-9]+}", userHandler.GetUser).Methods("GET")
+	usersRouter.HandleFunc("/{id:[0-9]+}/change-password", userHandler.ChangePassword).Methods("POST")
+	usersRouter.HandleFunc("/{id:[0-9]+}/activity", userHandler.GetUserActivity).Methods("GET")
+	usersRouter.HandleFunc("/{id:[0-9]+}/assessments", userHandler.GetUserAssessments).Methods("GET")
+
+	usersAdminRouter := usersRouter.PathPrefix("").Subrouter()
+	usersAdminRouter.Use(authMiddleware.ACLMiddleware("admin"))
+	usersAdminRouter.HandleFunc("", userHandler.ListUsers).Methods("GET")
+	usersAdminRouter.HandleFunc("", userHandler.CreateUser).Methods("POST")
+	usersAdminRouter.HandleFunc("/{id:[0-9]+}", userHandler.UpdateUser).Methods("PUT")
+	usersAdminRouter.HandleFunc("/{id:[0-9]+}", userHandler.DeleteUser).Methods("DELETE")
+	usersAdminRouter.HandleFunc("/{id:[0-9]+}/role", userHandler.ChangeRole).Methods("PUT")
+	usersAdminRouter.HandleFunc("/{id:[0-9]+}/activate", userHandler.ActivateUser).Methods("POST")
+	usersAdminRouter.HandleFunc("/{id:[0-9]+}/deactivate", userHandler.DeactivateUser).Methods("POST")
 
 	assessmentsRouter := router.PathPrefix("/assessments").Subrouter()
 	{
@@ -54,25 +112,95 @@ func SetupRoutes(
 		assessmentsRouter.HandleFunc("", assessmentHandler.CreateAssessment).Methods("POST")
 		assessmentsRouter.Use(authMiddleware.ACLMiddleware("admin", "teacher"))
 
-		assessmentsRouter.HandleFunc("/{id:[0-9]+}", assessmentHandler.GetAssessmentById).Methods("GET")
-		assessmentsRouter.HandleFunc("/{id:[0-9]+}", assessmentHandler.UpdateAssessment).Methods("PUT")
-		assessmentsRouter.HandleFunc("/{id:[0-9]+}", assessmentHandler.DeleteAssessment).Methods("DELETE")
-		assessmentsRouter.HandleFunc("/{id:[0-9]+}/duplicate", assessmentHandler.DuplicateAssessment).Methods("POST")
-		assessmentsRouter.HandleFunc("/{id:[0-9]+}/settings", assessmentHandler.UpdateSettings).Methods("PUT")
-		assessmentsRouter.HandleFunc("/{id:[0-9]+}/results", assessmentHandler.GetAssessmentResults).Methods("GET")
-		assessmentsRouter.HandleFunc("/{id:[0-9]+}/publish", assessmentHandler.PublishAssessment).Methods("POST")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}", guard.Assessment(policy.ActionView, "id", assessmentHandler.GetAssessmentById)).Methods("GET")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}", guard.Assessment(policy.ActionEdit, "id", assessmentHandler.UpdateAssessment)).Methods("PUT")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}", guard.Assessment(policy.ActionDelete, "id", assessmentHandler.DeleteAssessment)).Methods("DELETE")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/duplicate", guard.Assessment(policy.ActionView, "id", assessmentHandler.DuplicateAssessment)).Methods("POST")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/settings", guard.Assessment(policy.ActionEdit, "id", assessmentHandler.UpdateSettings)).Methods("PUT")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/results", guard.Assessment(policy.ActionViewResults, "id", guard.Check(policy.ActionViewIdentities, "id", assessmentHandler.GetAssessmentResults))).Methods("GET")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/publish", guard.Assessment(policy.ActionPublish, "id", assessmentHandler.PublishAssessment)).Methods("POST")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/close", guard.Assessment(policy.ActionPublish, "id", assessmentHandler.CloseAssessment)).Methods("POST")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/reopen", guard.Assessment(policy.ActionPublish, "id", assessmentHandler.ReopenAssessment)).Methods("POST")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/archive", guard.Assessment(policy.ActionDelete, "id", assessmentHandler.ArchiveAssessment)).Methods("POST")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/status-history", guard.Assessment(policy.ActionView, "id", assessmentHandler.GetStatusHistory)).Methods("GET")
+
+		// Question routes (nested under assessments), restricted to users who can manage the assessment
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/questions", guard.Assessment(policy.ActionManageQuestions, "id", questionHandler.GetQuestionsByAssessment)).Methods("GET")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/questions", guard.Assessment(policy.ActionManageQuestions, "id", questionHandler.AddQuestion)).Methods("POST")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/questions/from-bank", guard.Assessment(policy.ActionManageQuestions, "id", bankHandler.AddToAssessment)).Methods("POST")
+
+		// Sections draw the questions of each attempt from pools of the assessment's questions
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/sections", guard.Assessment(policy.ActionManageQuestions, "id", sectionHandler.ListSections)).Methods("GET")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/sections", guard.Assessment(policy.ActionManageQuestions, "id", sectionHandler.CreateSection)).Methods("POST")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/sections/{sectionId:[0-9]+}", guard.Assessment(policy.ActionManageQuestions, "id", sectionHandler.UpdateSection)).Methods("PUT")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/sections/{sectionId:[0-9]+}", guard.Assessment(policy.ActionManageQuestions, "id", sectionHandler.DeleteSection)).Methods("DELETE")
+		assessmentsRouter.HandleFunc("/{assessmentId:[0-9]+}/questions/{questionId:[0-9]+}", guard.Question(policy.ActionManageQuestions, "assessmentId", "questionId", questionHandler.UpdateQuestion)).Methods("PUT")
+		assessmentsRouter.HandleFunc("/{assessmentId:[0-9]+}/questions/{questionId:[0-9]+}", guard.Question(policy.ActionManageQuestions, "assessmentId", "questionId", questionHandler.DeleteQuestion)).Methods("DELETE")
+
+		// Collaborators: anyone with access can see who else has it, only owners can change it
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/collaborators", guard.Assessment(policy.ActionView, "id", collaboratorHandler.ListCollaborators)).Methods("GET")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/collaborators", guard.Assessment(policy.ActionManageSharing, "id", collaboratorHandler.AddCollaborator)).Methods("POST")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/collaborators/{userId:[0-9]+}", guard.Assessment(policy.ActionManageSharing, "id", collaboratorHandler.UpdateCollaborator)).Methods("PUT")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/collaborators/{userId:[0-9]+}", guard.Assessment(policy.ActionManageSharing, "id", collaboratorHandler.RemoveCollaborator)).Methods("DELETE")
 
-		// Question routes (nested under assessments)
-		assessmentsRouter.HandleFunc("/{id:[0-9]+}/questions", questionHandler.GetQuestionsByAssessment).Methods("GET")
-		assessmentsRouter.HandleFunc("/{id:[0-9]+}/questions", questionHandler.AddQuestion).Methods("POST")
-		assessmentsRouter.HandleFunc("/{assessmentId:[0-9]+}/questions/{questionId:[0-9]+}", questionHandler.UpdateQuestion).Methods("PUT")
-		assessmentsRouter.HandleFunc("/{assessmentId:[0-9]+}/questions/{questionId:[0-9]+}", questionHandler.DeleteQuestion).Methods("DELETE")
+		// Assignments: which groups and students may take the assessment
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/assignments", guard.Assessment(policy.ActionView, "id", assignmentHandler.ListAssignments)).Methods("GET")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/assignments", guard.Assessment(policy.ActionAssign, "id", assignmentHandler.AssignAssessment)).Methods("POST")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/assignments/{assignmentId:[0-9]+}", guard.Assessment(policy.ActionAssign, "id", assignmentHandler.UnassignAssessment)).Methods("DELETE")
+
+		// Accommodations: extended time, extra attempts and later due dates for students and groups
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/accommodations", guard.Assessment(policy.ActionView, "id", accommodationHandler.ListAccommodations)).Methods("GET")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/accommodations", guard.Assessment(policy.ActionAssign, "id", accommodationHandler.CreateAccommodation)).Methods("POST")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/accommodations/{accommodationId:[0-9]+}", guard.Assessment(policy.ActionAssign, "id", accommodationHandler.UpdateAccommodation)).Methods("PUT")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/accommodations/{accommodationId:[0-9]+}", guard.Assessment(policy.ActionAssign, "id", accommodationHandler.DeleteAccommodation)).Methods("DELETE")
+
+		// Grading by owners and graders of the attempt's assessment
+		assessmentsRouter.HandleFunc("/attempts/{attemptID:[0-9]+}/grade", guard.Attempt(policy.ActionGrade, "attemptID", attemptHandler.GradeAttempt)).Methods("POST")
+		assessmentsRouter.HandleFunc("/attempts/{attemptID:[0-9]+}/void", guard.Attempt(policy.ActionGrade, "attemptID", attemptHandler.VoidAttempt)).Methods("POST")
+		assessmentsRouter.HandleFunc("/attempts/{attemptID:[0-9]+}/answers/{answerID:[0-9]+}/grade", guard.Attempt(policy.ActionGrade, "attemptID", attemptHandler.GradeAnswer)).Methods("POST")
+		assessmentsRouter.HandleFunc("/attempts/{attemptID:[0-9]+}/answers/{answerID:[0-9]+}/reconcile", guard.Attempt(policy.ActionGrade, "attemptID", attemptHandler.ReconcileAnswer)).Methods("POST")
+		assessmentsRouter.HandleFunc("/attempts/{attemptID:[0-9]+}/regrade-requests/{requestID:[0-9]+}/accept", guard.Attempt(policy.ActionGrade, "attemptID", attemptHandler.AcceptRegradeRequest)).Methods("POST")
+		assessmentsRouter.HandleFunc("/attempts/{attemptID:[0-9]+}/regrade-requests/{requestID:[0-9]+}/reject", guard.Attempt(policy.ActionGrade, "attemptID", attemptHandler.RejectRegradeRequest)).Methods("POST")
+
+		// Handing out manual grading: owners decide who grades, graders follow the progress
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/grading/assign", guard.Assessment(policy.ActionManageSharing, "id", attemptHandler.AssignGraders)).Methods("POST")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/grading/progress", guard.Assessment(policy.ActionViewResults, "id", attemptHandler.GetGradingProgress)).Methods("GET")
+		assessmentsRouter.HandleFunc("/{id:[0-9]+}/rescore", guard.Assessment(policy.ActionGrade, "id", attemptHandler.RescoreAssessment)).Methods("POST")
 
 		// Statistics and recent assessments
 		assessmentsRouter.HandleFunc("/recent", assessmentHandler.GetRecentAssessments).Methods("GET")
 		assessmentsRouter.HandleFunc("/statistics", assessmentHandler.GetAssessmentStatistics).Methods("GET")
 	}
 
+	// Groups (classes) of students, managed by teachers and admins
+	groupsRouter := router.PathPrefix("/groups").Subrouter()
+	groupsRouter.Use(authMiddleware.ACLMiddleware("admin", "teacher"))
+	groupsRouter.HandleFunc("", groupHandler.ListGroups).Methods("GET")
+	groupsRouter.HandleFunc("", groupHandler.CreateGroup).Methods("POST")
+	groupsRouter.HandleFunc("/{id:[0-9]+}", groupHandler.GetGroup).Methods("GET")
+	groupsRouter.HandleFunc("/{id:[0-9]+}", groupHandler.UpdateGroup).Methods("PUT")
+	groupsRouter.HandleFunc("/{id:[0-9]+}", groupHandler.DeleteGroup).Methods("DELETE")
+	groupsRouter.HandleFunc("/{id:[0-9]+}/members", groupHandler.ListMembers).Methods("GET")
+	groupsRouter.HandleFunc("/{id:[0-9]+}/members", groupHandler.EnrollMembers).Methods("POST")
+	groupsRouter.HandleFunc("/{id:[0-9]+}/members/{userId:[0-9]+}", groupHandler.RemoveMember).Methods("DELETE")
+
+	// Question bank: questions teachers keep apart from assessments and share with their department
+	bankRouter := router.PathPrefix("/bank/questions").Subrouter()
+	bankRouter.Use(authMiddleware.ACLMiddleware("admin", "teacher"))
+	bankRouter.HandleFunc("", bankHandler.ListQuestions).Methods("GET")
+	bankRouter.HandleFunc("", bankHandler.CreateQuestion).Methods("POST")
+	bankRouter.HandleFunc("/{id:[0-9]+}", bankHandler.GetQuestion).Methods("GET")
+	bankRouter.HandleFunc("/{id:[0-9]+}", bankHandler.UpdateQuestion).Methods("PUT")
+	bankRouter.HandleFunc("/{id:[0-9]+}", bankHandler.DeleteQuestion).Methods("DELETE")
+	bankRouter.HandleFunc("/{id:[0-9]+}/versions", bankHandler.GetVersions).Methods("GET")
+
+	// Manual grading: answers waiting for a grader across the assessments the user may grade
+	gradingRouter := router.PathPrefix("/grading").Subrouter()
+	gradingRouter.Use(authMiddleware.ACLMiddleware("admin", "teacher"))
+	gradingRouter.HandleFunc("/queue", attemptHandler.GetGradingQueue).Methods("GET")
+	gradingRouter.HandleFunc("/assignments", attemptHandler.GetGraderAssignments).Methods("GET")
+	gradingRouter.HandleFunc("/regrade-requests", attemptHandler.ListRegradeRequests).Methods("GET")
+
 	// Analytics
 	analyticsRouter := router.PathPrefix("/analytics").Subrouter()
 
@@ -96,6 +224,19 @@ func SetupRoutes(
 	adminRouter.HandleFunc("/attempt/grade/{attemptID:[0-9]+}", attemptHandler.GradeAttempt).Methods("POST")
 	adminRouter.HandleFunc("/attempts/{assessmentID:[0-9]+}/users/{userID:[0-9]+}", attemptHandler.GetListAttemptByUserAndAssessment).Methods("GET")
 	adminRouter.HandleFunc("/attempt/{attemptID:[0-9]+}/users/{userID:[0-9]+}", attemptHandler.GetAttemptDetail).Methods("GET")
+	adminRouter.HandleFunc("/attempts/{attemptID:[0-9]+}/grade-events", attemptHandler.GetGradeEvents).Methods("GET")
+	// Proctors pause, resume and extend live attempts; every change is kept in the attempt's time ledger
+	adminRouter.HandleFunc("/attempts/{attemptID:[0-9]+}/pause", attemptHandler.PauseAttempt).Methods("POST")
+	adminRouter.HandleFunc("/attempts/{attemptID:[0-9]+}/resume", attemptHandler.ResumeAttempt).Methods("POST")
+	adminRouter.HandleFunc("/attempts/{attemptID:[0-9]+}/extend", attemptHandler.ExtendAttempt).Methods("POST")
+	adminRouter.HandleFunc("/attempts/{attemptID:[0-9]+}/time-events", attemptHandler.GetTimeEvents).Methods("GET")
+	// Live proctoring: a dashboard of each assessment's attempts in progress, and warnings, flags and
+	// terminations sent to the students
+	adminRouter.HandleFunc("/assessments/{assessmentID:[0-9]+}/proctoring", proctoringHandler.GetDashboard).Methods("GET")
+	adminRouter.HandleFunc("/assessments/{assessmentID:[0-9]+}/proctoring/live", proctoringHandler.Stream).Methods("GET")
+	adminRouter.HandleFunc("/attempts/{attemptID:[0-9]+}/messages", attemptHandler.SendMessage).Methods("POST")
+	adminRouter.HandleFunc("/attempts/{attemptID:[0-9]+}/flag", attemptHandler.FlagAttempt).Methods("POST")
+	adminRouter.HandleFunc("/attempts/{attemptID:[0-9]+}/terminate", attemptHandler.TerminateAttempt).Methods("POST")
 	adminRouter.HandleFunc("/users/{userID:[0-9]+}/attempts", studentHandler.GetAllAttemptForUser).Methods("GET")
 	adminRouter.HandleFunc("/assessments/{assessmentID:[0-9]+}", assessmentHandler.GetAssessmentWithUserHasAttempt).Methods("GET")
 	adminRouter.HandleFunc("/activity/{userID:[0-9]+}/{attemptID:[0-9]+}", analyticsHandler.GetSuspiciousActivity).Methods("GET")
@@ -105,11 +246,17 @@ func SetupRoutes(
 	studentRouter := router.PathPrefix("/student").Subrouter()
 	studentRouter.HandleFunc("/assessments/available", studentHandler.GetAvailableAssessments).Methods("GET")
 	studentRouter.HandleFunc("/assessments/{id:[0-9]+}/start", studentHandler.StartAssessment).Methods("POST")
+	studentRouter.HandleFunc("/assessments/{id:[0-9]+}/resume", studentHandler.ResumeAssessment).Methods("POST")
 	studentRouter.HandleFunc("/assessments/{id:[0-9]+}/results", studentHandler.GetAssessmentResultsHistory).Methods("GET")
 	studentRouter.HandleFunc("/attempts/{attemptId:[0-9]+}", studentHandler.GetAttemptDetails).Methods("GET")
 	studentRouter.HandleFunc("/attempts/{attemptId:[0-9]+}/answers", studentHandler.SaveAnswer).Methods("POST")
 	studentRouter.HandleFunc("/attempts/{attemptId:[0-9]+}/submit", studentHandler.SubmitAssessment).Methods("POST")
 	studentRouter.HandleFunc("/attempts/{attemptId:[0-9]+}/monitor", studentHandler.SubmitMonitorEvent).Methods("POST")
+	// Live channel: a Server-Sent Events stream of the attempt's clock and events, and the student's messages
+	studentRouter.HandleFunc("/attempts/{attemptId:[0-9]+}/live", liveHandler.Stream).Methods("GET")
+	studentRouter.HandleFunc("/attempts/{attemptId:[0-9]+}/live", liveHandler.Send).Methods("POST")
+	studentRouter.HandleFunc("/attempts/{attemptId:[0-9]+}/regrade-requests", studentHandler.RequestRegrade).Methods("POST")
+	studentRouter.HandleFunc("/attempts/{attemptId:[0-9]+}/regrade-requests", studentHandler.GetRegradeRequests).Methods("GET")
 
 	return router
 }
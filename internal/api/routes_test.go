@@ -8,18 +8,22 @@ import (
 	// ... và các mock khác ...
 
 	// Hoặc định nghĩa mock trực tiếp ở đây cho đơn giản
+	"assessment_service/configs"
+	"assessment_service/internal/assessments/policy"
+	"assessment_service/internal/live"
+	"assessment_service/internal/middleware"
 	models "assessment_service/internal/model"
 	"assessment_service/internal/util"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 	"net/http"
 	"net/http/httptest"
-	"os" // Cần cho JWT test
 	"testing"
 	"time" // Cần cho JWT test
 
-	"github.com/golang-jwt/jwt/v5" // Cần cho việc tạo token test
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap/zaptest"
@@ -74,22 +78,52 @@ func (m *MockAssessmentService) UpdateSettings(id uint, settings *models.Assessm
 	args := m.Called(id, settings)
 	return args.Error(0)
 }
-func (m *MockAssessmentService) GetResults(id uint, params util.PaginationParams) ([]map[string]interface{}, int64, error) {
-	args := m.Called(id, params)
+func (m *MockAssessmentService) GetResults(id uint, params util.PaginationParams, hideIdentities bool) ([]map[string]interface{}, int64, error) {
+	args := m.Called(id, params, hideIdentities)
 	if args.Get(0) == nil {
 		return nil, args.Get(1).(int64), args.Error(2)
 	}
 	return args.Get(0).([]map[string]interface{}), args.Get(1).(int64), args.Error(2)
 }
-func (m *MockAssessmentService) Publish(id uint) (*models.Assessment, error) {
-	args := m.Called(id)
+func (m *MockAssessmentService) Publish(id, changedByID uint) (*models.Assessment, error) {
+	args := m.Called(id, changedByID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Assessment), args.Error(1)
+}
+
+func (m *MockAssessmentService) Close(id, changedByID uint, reason string) (*models.Assessment, error) {
+	args := m.Called(id, changedByID, reason)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.Assessment), args.Error(1)
 }
-func (m *MockAssessmentService) Duplicate(id uint, newTitle string, copyQuestions, copySettings, setAsDraft bool) (*models.Assessment, error) {
-	args := m.Called(id, newTitle, copyQuestions, copySettings, setAsDraft)
+
+func (m *MockAssessmentService) Reopen(id, changedByID uint, reason string) (*models.Assessment, error) {
+	args := m.Called(id, changedByID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Assessment), args.Error(1)
+}
+
+func (m *MockAssessmentService) Archive(id, changedByID uint, reason string) (*models.Assessment, error) {
+	args := m.Called(id, changedByID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Assessment), args.Error(1)
+}
+
+func (m *MockAssessmentService) GetStatusHistory(id uint) ([]models.AssessmentStatusTransition, error) {
+	args := m.Called(id)
+	transitions, _ := args.Get(0).([]models.AssessmentStatusTransition)
+	return transitions, args.Error(1)
+}
+func (m *MockAssessmentService) Duplicate(id, actorID uint, newTitle string, copyQuestions, copySettings bool) (*models.Assessment, error) {
+	args := m.Called(id, actorID, newTitle, copyQuestions, copySettings)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -109,6 +143,11 @@ func (m *MockAssessmentService) GetAssessmentHasAttempt(userID uint, params util
 	return assessments, total, args.Error(2)
 }
 
+func (m *MockAssessmentService) UpdateScheduledStatuses() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
 // Mock QuestionService
 type MockQuestionService struct{ mock.Mock }
 
@@ -126,8 +165,8 @@ func (m *MockQuestionService) GetQuestionsByAssessment(assessmentID uint) ([]mod
 	}
 	return args.Get(0).([]models.Question), args.Error(1)
 }
-func (m *MockQuestionService) UpdateQuestion(questionID uint, questionData map[string]interface{}) (*models.Question, error) {
-	args := m.Called(questionID, questionData)
+func (m *MockQuestionService) UpdateQuestion(questionID, editorID uint, questionData map[string]interface{}) (*models.Question, error) {
+	args := m.Called(questionID, editorID, questionData)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -206,8 +245,8 @@ func (m *MockStudentService) GetAvailableAssessments(userID uint, params util.Pa
 	}
 	return args.Get(0).([]map[string]interface{}), args.Get(1).(int64), args.Error(2)
 }
-func (m *MockStudentService) StartAssessment(userID, assessmentID uint) (*models.Attempt, []models.Question, *models.AssessmentSettings, *models.Assessment, error) {
-	args := m.Called(userID, assessmentID)
+func (m *MockStudentService) StartAssessment(userID, assessmentID uint, client models.AttemptClient) (*models.Attempt, []models.Question, *models.AttemptClock, *models.Assessment, error) {
+	args := m.Called(userID, assessmentID, client)
 	var attempt *models.Attempt
 	if args.Get(0) != nil {
 		attempt = args.Get(0).(*models.Attempt)
@@ -216,15 +255,20 @@ func (m *MockStudentService) StartAssessment(userID, assessmentID uint) (*models
 	if args.Get(1) != nil {
 		questions = args.Get(1).([]models.Question)
 	}
-	var settings *models.AssessmentSettings
+	var clock *models.AttemptClock
 	if args.Get(2) != nil {
-		settings = args.Get(2).(*models.AssessmentSettings)
+		clock = args.Get(2).(*models.AttemptClock)
 	}
 	var assessment *models.Assessment
 	if args.Get(3) != nil {
 		assessment = args.Get(3).(*models.Assessment)
 	}
-	return attempt, questions, settings, assessment, args.Error(4)
+	return attempt, questions, clock, assessment, args.Error(4)
+}
+func (m *MockStudentService) ResumeAssessment(userID, assessmentID uint, client models.AttemptClient) (*map[string]interface{}, error) {
+	args := m.Called(userID, assessmentID, client)
+	details, _ := args.Get(0).(*map[string]interface{})
+	return details, args.Error(1)
 }
 func (m *MockStudentService) GetAssessmentResultsHistory(userID, assessmentID uint) ([]map[string]interface{}, error) {
 	args := m.Called(userID, assessmentID)
@@ -233,28 +277,39 @@ func (m *MockStudentService) GetAssessmentResultsHistory(userID, assessmentID ui
 	}
 	return args.Get(0).([]map[string]interface{}), args.Error(1)
 }
-func (m *MockStudentService) GetAttemptDetails(attemptID, userID uint) (*map[string]interface{}, error) {
-	args := m.Called(attemptID, userID)
+func (m *MockStudentService) GetAttemptDetails(attemptID, userID uint, client models.AttemptClient) (*map[string]interface{}, error) {
+	args := m.Called(attemptID, userID, client)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	resMap := args.Get(0).(map[string]interface{})
 	return &resMap, args.Error(1)
 }
-func (m *MockStudentService) SaveAnswer(attemptID, questionID uint, answer string, userID uint) error {
-	args := m.Called(attemptID, questionID, answer, userID)
+func (m *MockStudentService) AttemptClock(attemptID, userID uint, client models.AttemptClient) (*models.AttemptClock, error) {
+	args := m.Called(attemptID, userID, client)
+	clock, _ := args.Get(0).(*models.AttemptClock)
+	return clock, args.Error(1)
+}
+
+func (m *MockStudentService) Heartbeat(attemptID, userID uint, client models.AttemptClient) (*models.AttemptClock, error) {
+	args := m.Called(attemptID, userID, client)
+	clock, _ := args.Get(0).(*models.AttemptClock)
+	return clock, args.Error(1)
+}
+func (m *MockStudentService) SaveAnswer(attemptID, questionID uint, answer string, userID uint, client models.AttemptClient) error {
+	args := m.Called(attemptID, questionID, answer, userID, client)
 	return args.Error(0)
 }
-func (m *MockStudentService) SubmitAssessment(attemptID, userID uint) (*map[string]interface{}, error) {
-	args := m.Called(attemptID, userID)
+func (m *MockStudentService) SubmitAssessment(attemptID, userID uint, client models.AttemptClient) (*map[string]interface{}, error) {
+	args := m.Called(attemptID, userID, client)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	resMap := args.Get(0).(map[string]interface{})
 	return &resMap, args.Error(1)
 }
-func (m *MockStudentService) SubmitMonitorEvent(attemptID uint, eventType string, details map[string]interface{}, imageData []byte, userID uint) (*map[string]interface{}, error) {
-	args := m.Called(attemptID, eventType, details, imageData, userID)
+func (m *MockStudentService) SubmitMonitorEvent(attemptID uint, eventType string, details map[string]interface{}, imageData []byte, userID uint, client models.AttemptClient) (*map[string]interface{}, error) {
+	args := m.Called(attemptID, eventType, details, imageData, userID, client)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -270,6 +325,18 @@ func (m *MockStudentService) GetAllAttemptByUserID(userID uint, params util.Pagi
 	return args.Get(0).([]models.Attempt), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockStudentService) RequestRegrade(attemptID, userID uint, request models.RegradeRequestDTO) (*models.RegradeRequest, error) {
+	args := m.Called(attemptID, userID, request)
+	regrade, _ := args.Get(0).(*models.RegradeRequest)
+	return regrade, args.Error(1)
+}
+
+func (m *MockStudentService) GetRegradeRequests(attemptID, userID uint) ([]models.RegradeRequest, error) {
+	args := m.Called(attemptID, userID)
+	requests, _ := args.Get(0).([]models.RegradeRequest)
+	return requests, args.Error(1)
+}
+
 // Mock AttemptService
 type MockAttemptService struct{ mock.Mock }
 
@@ -287,20 +354,488 @@ func (m *MockAttemptService) GetAttemptDetail(attemptID uint) (*models.Attempt,
 	}
 	return args.Get(0).(*models.Attempt), args.Error(1)
 }
-func (m *MockAttemptService) GradeAttempt(newAttempt models.AttemptUpdateDTO, attemptID uint) error {
-	args := m.Called(newAttempt, attemptID)
+func (m *MockAttemptService) GradeAttempt(newAttempt models.AttemptUpdateDTO, attemptID, graderID uint) error {
+	args := m.Called(newAttempt, attemptID, graderID)
+	return args.Error(0)
+}
+
+func (m *MockAttemptService) GetGradingQueue(params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	args := m.Called(params)
+	results, _ := args.Get(0).([]map[string]interface{})
+	count, _ := args.Get(1).(int64)
+	return results, count, args.Error(2)
+}
+
+func (m *MockAttemptService) GradeAnswer(attemptID, answerID, graderID uint, grade models.AnswerGradeInput) (*models.Attempt, error) {
+	args := m.Called(attemptID, answerID, graderID, grade)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Attempt), args.Error(1)
+}
+
+func (m *MockAttemptService) AssignGraders(assessmentID uint, request models.GraderAssignmentDTO) ([]models.GradingAssignment, error) {
+	args := m.Called(assessmentID, request)
+	assignments, _ := args.Get(0).([]models.GradingAssignment)
+	return assignments, args.Error(1)
+}
+
+func (m *MockAttemptService) GetGraderAssignments(graderID uint, params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	args := m.Called(graderID, params)
+	results, _ := args.Get(0).([]map[string]interface{})
+	count, _ := args.Get(1).(int64)
+	return results, count, args.Error(2)
+}
+
+func (m *MockAttemptService) ReconcileAnswer(attemptID, answerID, graderID uint, grade models.AnswerGradeInput) (*models.Attempt, error) {
+	args := m.Called(attemptID, answerID, graderID, grade)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Attempt), args.Error(1)
+}
+
+func (m *MockAttemptService) GetGradingProgress(assessmentID uint) (map[string]interface{}, error) {
+	args := m.Called(assessmentID)
+	progress, _ := args.Get(0).(map[string]interface{})
+	return progress, args.Error(1)
+}
+
+func (m *MockAttemptService) GetGradeEvents(attemptID uint) ([]models.GradeEvent, error) {
+	args := m.Called(attemptID)
+	events, _ := args.Get(0).([]models.GradeEvent)
+	return events, args.Error(1)
+}
+
+func (m *MockAttemptService) ListRegradeRequests(params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	args := m.Called(params)
+	requests, _ := args.Get(0).([]map[string]interface{})
+	total, _ := args.Get(1).(int64)
+	return requests, total, args.Error(2)
+}
+
+func (m *MockAttemptService) AcceptRegradeRequest(attemptID, requestID, reviewerID uint, decision models.RegradeDecisionDTO) (*models.RegradeRequest, error) {
+	args := m.Called(attemptID, requestID, reviewerID, decision)
+	request, _ := args.Get(0).(*models.RegradeRequest)
+	return request, args.Error(1)
+}
+
+func (m *MockAttemptService) RejectRegradeRequest(attemptID, requestID, reviewerID uint, response string) (*models.RegradeRequest, error) {
+	args := m.Called(attemptID, requestID, reviewerID, response)
+	request, _ := args.Get(0).(*models.RegradeRequest)
+	return request, args.Error(1)
+}
+
+func (m *MockAttemptService) RescoreAssessment(assessmentID, actorID uint, questionIDs []uint) (*models.RescoreSummary, error) {
+	args := m.Called(assessmentID, actorID, questionIDs)
+	summary, _ := args.Get(0).(*models.RescoreSummary)
+	return summary, args.Error(1)
+}
+
+func (m *MockAttemptService) RescoreAssessmentWithin(tx *gorm.DB, assessmentID, actorID uint, questionIDs []uint) (*models.RescoreSummary, error) {
+	args := m.Called(assessmentID, actorID, questionIDs)
+	summary, _ := args.Get(0).(*models.RescoreSummary)
+	return summary, args.Error(1)
+}
+
+func (m *MockAttemptService) PauseAttempt(attemptID, actorID uint, reason string) (*models.Attempt, error) {
+	args := m.Called(attemptID, actorID, reason)
+	attempt, _ := args.Get(0).(*models.Attempt)
+	return attempt, args.Error(1)
+}
+
+func (m *MockAttemptService) ResumeAttempt(attemptID, actorID uint, reason string) (*models.Attempt, error) {
+	args := m.Called(attemptID, actorID, reason)
+	attempt, _ := args.Get(0).(*models.Attempt)
+	return attempt, args.Error(1)
+}
+
+func (m *MockAttemptService) ExtendAttempt(attemptID, actorID uint, minutes int, reason string) (*models.Attempt, error) {
+	args := m.Called(attemptID, actorID, minutes, reason)
+	attempt, _ := args.Get(0).(*models.Attempt)
+	return attempt, args.Error(1)
+}
+
+func (m *MockAttemptService) GetTimeEvents(attemptID uint) ([]models.AttemptTimeEvent, error) {
+	args := m.Called(attemptID)
+	events, _ := args.Get(0).([]models.AttemptTimeEvent)
+	return events, args.Error(1)
+}
+
+func (m *MockAttemptService) SendMessage(attemptID, actorID uint, message models.ProctorMessageDTO) error {
+	args := m.Called(attemptID, actorID, message)
+	return args.Error(0)
+}
+
+func (m *MockAttemptService) GetProctoringDashboard(assessmentID uint) ([]models.ProctoredAttempt, error) {
+	args := m.Called(assessmentID)
+	dashboard, _ := args.Get(0).([]models.ProctoredAttempt)
+	return dashboard, args.Error(1)
+}
+
+func (m *MockAttemptService) FlagAttempt(attemptID, actorID uint, reason string) (*models.Attempt, error) {
+	args := m.Called(attemptID, actorID, reason)
+	attempt, _ := args.Get(0).(*models.Attempt)
+	return attempt, args.Error(1)
+}
+
+func (m *MockAttemptService) TerminateAttempt(attemptID, actorID uint, reason string) (*models.Attempt, error) {
+	args := m.Called(attemptID, actorID, reason)
+	attempt, _ := args.Get(0).(*models.Attempt)
+	return attempt, args.Error(1)
+}
+
+func (m *MockAttemptService) VoidAttempt(attemptID uint) error {
+	args := m.Called(attemptID)
+	return args.Error(0)
+}
+
+// Mock AuthService
+type MockAuthService struct{ mock.Mock }
+
+func (m *MockAuthService) Login(email, password string) (string, string, *models.User, error) {
+	args := m.Called(email, password)
+	user, _ := args.Get(2).(*models.User)
+	return args.String(0), args.String(1), user, args.Error(3)
+}
+func (m *MockAuthService) Register(user *models.User, password string) error {
+	args := m.Called(user, password)
+	return args.Error(0)
+}
+func (m *MockAuthService) RefreshToken(refreshToken string) (string, string, error) {
+	args := m.Called(refreshToken)
+	return args.String(0), args.String(1), args.Error(2)
+}
+func (m *MockAuthService) Logout(refreshToken string) error {
+	args := m.Called(refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ForgotPassword(email string) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ResetPassword(token, newPassword string) error {
+	args := m.Called(token, newPassword)
+	return args.Error(0)
+}
+
+// --- Mock UserService ---
+type MockUserService struct {
+	mock.Mock
+}
+
+func (m *MockUserService) ListUsers(params util.PaginationParams) ([]models.User, int64, error) {
+	args := m.Called(params)
+	users, _ := args.Get(0).([]models.User)
+	total, _ := args.Get(1).(int64)
+	return users, total, args.Error(2)
+}
+
+func (m *MockUserService) GetUser(id uint) (*models.User, error) {
+	args := m.Called(id)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserService) CreateUser(user *models.User, password string) error {
+	args := m.Called(user, password)
+	return args.Error(0)
+}
+
+func (m *MockUserService) UpdateUser(id uint, update models.UserUpdateDTO) (*models.User, error) {
+	args := m.Called(id, update)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserService) DeleteUser(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ChangeRole(id uint, role string) (*models.User, error) {
+	args := m.Called(id, role)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserService) SetStatus(id uint, status string) (*models.User, error) {
+	args := m.Called(id, status)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserService) ChangePassword(id uint, currentPassword, newPassword string) error {
+	args := m.Called(id, currentPassword, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockUserService) SetPassword(id uint, newPassword string) error {
+	args := m.Called(id, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockUserService) GetUserActivity(id uint, params util.PaginationParams) ([]models.Activity, int64, error) {
+	args := m.Called(id, params)
+	activities, _ := args.Get(0).([]models.Activity)
+	total, _ := args.Get(1).(int64)
+	return activities, total, args.Error(2)
+}
+
+func (m *MockUserService) GetUserAssessments(id uint, params util.PaginationParams) ([]models.Assessment, int64, error) {
+	args := m.Called(id, params)
+	assessments, _ := args.Get(0).([]models.Assessment)
+	total, _ := args.Get(1).(int64)
+	return assessments, total, args.Error(2)
+}
+
+// Mock AssessmentPolicy
+type MockAssessmentPolicy struct {
+	mock.Mock
+}
+
+func (m *MockAssessmentPolicy) Authorize(principal *middleware.Principal, assessmentID uint, action policy.Action) error {
+	args := m.Called(principal, assessmentID, action)
+	return args.Error(0)
+}
+
+func (m *MockAssessmentPolicy) AuthorizeQuestion(principal *middleware.Principal, assessmentID, questionID uint, action policy.Action) error {
+	args := m.Called(principal, assessmentID, questionID, action)
 	return args.Error(0)
 }
 
+func (m *MockAssessmentPolicy) AuthorizeAttempt(principal *middleware.Principal, attemptID uint, action policy.Action) error {
+	args := m.Called(principal, attemptID, action)
+	return args.Error(0)
+}
+
+// Mock CollaboratorService
+type MockCollaboratorService struct {
+	mock.Mock
+}
+
+func (m *MockCollaboratorService) ListCollaborators(assessmentID uint) ([]models.AssessmentCollaborator, error) {
+	args := m.Called(assessmentID)
+	collaborators, _ := args.Get(0).([]models.AssessmentCollaborator)
+	return collaborators, args.Error(1)
+}
+
+func (m *MockCollaboratorService) AddCollaborator(assessmentID uint, invite models.CollaboratorInviteDTO, invitedByID uint) (*models.AssessmentCollaborator, error) {
+	args := m.Called(assessmentID, invite, invitedByID)
+	collaborator, _ := args.Get(0).(*models.AssessmentCollaborator)
+	return collaborator, args.Error(1)
+}
+
+func (m *MockCollaboratorService) UpdateCollaboratorRole(assessmentID, userID uint, role string) (*models.AssessmentCollaborator, error) {
+	args := m.Called(assessmentID, userID, role)
+	collaborator, _ := args.Get(0).(*models.AssessmentCollaborator)
+	return collaborator, args.Error(1)
+}
+
+func (m *MockCollaboratorService) RemoveCollaborator(assessmentID, userID uint) error {
+	args := m.Called(assessmentID, userID)
+	return args.Error(0)
+}
+
+// Mock AssignmentService
+type MockAssignmentService struct {
+	mock.Mock
+}
+
+func (m *MockAssignmentService) ListAssignments(assessmentID uint) ([]models.AssessmentAssignment, error) {
+	args := m.Called(assessmentID)
+	assignments, _ := args.Get(0).([]models.AssessmentAssignment)
+	return assignments, args.Error(1)
+}
+
+func (m *MockAssignmentService) AssignAssessment(assessmentID uint, assignment models.AssignmentDTO, assignedByID uint) ([]models.AssessmentAssignment, error) {
+	args := m.Called(assessmentID, assignment, assignedByID)
+	assignments, _ := args.Get(0).([]models.AssessmentAssignment)
+	return assignments, args.Error(1)
+}
+
+func (m *MockAssignmentService) UnassignAssessment(assessmentID, assignmentID uint) error {
+	args := m.Called(assessmentID, assignmentID)
+	return args.Error(0)
+}
+
+// Mock AccommodationService
+type MockAccommodationService struct {
+	mock.Mock
+}
+
+func (m *MockAccommodationService) ListAccommodations(assessmentID uint) ([]models.AssessmentAccommodation, error) {
+	args := m.Called(assessmentID)
+	accommodations, _ := args.Get(0).([]models.AssessmentAccommodation)
+	return accommodations, args.Error(1)
+}
+
+func (m *MockAccommodationService) CreateAccommodation(assessmentID uint, accommodation models.AccommodationDTO, createdByID uint) (*models.AssessmentAccommodation, error) {
+	args := m.Called(assessmentID, accommodation, createdByID)
+	created, _ := args.Get(0).(*models.AssessmentAccommodation)
+	return created, args.Error(1)
+}
+
+func (m *MockAccommodationService) UpdateAccommodation(assessmentID, accommodationID uint, accommodation models.AccommodationDTO) (*models.AssessmentAccommodation, error) {
+	args := m.Called(assessmentID, accommodationID, accommodation)
+	updated, _ := args.Get(0).(*models.AssessmentAccommodation)
+	return updated, args.Error(1)
+}
+
+func (m *MockAccommodationService) DeleteAccommodation(assessmentID, accommodationID uint) error {
+	args := m.Called(assessmentID, accommodationID)
+	return args.Error(0)
+}
+
+// Mock GroupService
+type MockGroupService struct {
+	mock.Mock
+}
+
+func (m *MockGroupService) CreateGroup(group models.GroupDTO, createdByID uint) (*models.Group, error) {
+	args := m.Called(group, createdByID)
+	created, _ := args.Get(0).(*models.Group)
+	return created, args.Error(1)
+}
+
+func (m *MockGroupService) GetGroup(id uint) (*models.Group, error) {
+	args := m.Called(id)
+	group, _ := args.Get(0).(*models.Group)
+	return group, args.Error(1)
+}
+
+func (m *MockGroupService) UpdateGroup(id uint, group models.GroupDTO, actorID uint) (*models.Group, error) {
+	args := m.Called(id, group, actorID)
+	updated, _ := args.Get(0).(*models.Group)
+	return updated, args.Error(1)
+}
+
+func (m *MockGroupService) DeleteGroup(id, actorID uint) error {
+	args := m.Called(id, actorID)
+	return args.Error(0)
+}
+
+func (m *MockGroupService) ListGroups(params util.PaginationParams) ([]models.Group, int64, error) {
+	args := m.Called(params)
+	groups, _ := args.Get(0).([]models.Group)
+	total, _ := args.Get(1).(int64)
+	return groups, total, args.Error(2)
+}
+
+func (m *MockGroupService) ListMembers(id uint, params util.PaginationParams) ([]models.User, int64, error) {
+	args := m.Called(id, params)
+	users, _ := args.Get(0).([]models.User)
+	total, _ := args.Get(1).(int64)
+	return users, total, args.Error(2)
+}
+
+func (m *MockGroupService) EnrollMembers(id uint, enrollment models.EnrollmentDTO, actorID uint) (*models.EnrollmentResult, error) {
+	args := m.Called(id, enrollment, actorID)
+	result, _ := args.Get(0).(*models.EnrollmentResult)
+	return result, args.Error(1)
+}
+
+func (m *MockGroupService) RemoveMember(id, userID, actorID uint) error {
+	args := m.Called(id, userID, actorID)
+	return args.Error(0)
+}
+
+// Mock BankService
+type MockBankService struct {
+	mock.Mock
+}
+
+func (m *MockBankService) CreateQuestion(dto models.BankQuestionDTO, ownerID uint) (*models.BankQuestion, error) {
+	args := m.Called(dto, ownerID)
+	question, _ := args.Get(0).(*models.BankQuestion)
+	return question, args.Error(1)
+}
+
+func (m *MockBankService) GetQuestion(id, userID uint) (*models.BankQuestion, error) {
+	args := m.Called(id, userID)
+	question, _ := args.Get(0).(*models.BankQuestion)
+	return question, args.Error(1)
+}
+
+func (m *MockBankService) ListQuestions(params util.PaginationParams, userID uint) ([]models.BankQuestion, int64, error) {
+	args := m.Called(params, userID)
+	questions, _ := args.Get(0).([]models.BankQuestion)
+	total, _ := args.Get(1).(int64)
+	return questions, total, args.Error(2)
+}
+
+func (m *MockBankService) UpdateQuestion(id uint, dto models.BankQuestionDTO, userID uint) (*models.BankQuestion, error) {
+	args := m.Called(id, dto, userID)
+	question, _ := args.Get(0).(*models.BankQuestion)
+	return question, args.Error(1)
+}
+
+func (m *MockBankService) DeleteQuestion(id, userID uint) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+func (m *MockBankService) GetVersions(id, userID uint) ([]models.BankQuestionVersion, error) {
+	args := m.Called(id, userID)
+	versions, _ := args.Get(0).([]models.BankQuestionVersion)
+	return versions, args.Error(1)
+}
+
+func (m *MockBankService) AddToAssessment(assessmentID uint, dto models.BankImportDTO, userID uint) (*models.Question, error) {
+	args := m.Called(assessmentID, dto, userID)
+	question, _ := args.Get(0).(*models.Question)
+	return question, args.Error(1)
+}
+
+// Mock SectionService
+type MockSectionService struct {
+	mock.Mock
+}
+
+func (m *MockSectionService) ListSections(assessmentID uint) ([]models.AssessmentSection, error) {
+	args := m.Called(assessmentID)
+	sections, _ := args.Get(0).([]models.AssessmentSection)
+	return sections, args.Error(1)
+}
+
+func (m *MockSectionService) CreateSection(assessmentID uint, dto models.SectionDTO) (*models.AssessmentSection, error) {
+	args := m.Called(assessmentID, dto)
+	section, _ := args.Get(0).(*models.AssessmentSection)
+	return section, args.Error(1)
+}
+
+func (m *MockSectionService) UpdateSection(assessmentID, sectionID uint, dto models.SectionDTO) (*models.AssessmentSection, error) {
+	args := m.Called(assessmentID, sectionID, dto)
+	section, _ := args.Get(0).(*models.AssessmentSection)
+	return section, args.Error(1)
+}
+
+func (m *MockSectionService) DeleteSection(assessmentID, sectionID uint) error {
+	args := m.Called(assessmentID, sectionID)
+	return args.Error(0)
+}
+
+// --- Helper: Cấu hình JWT dùng chung cho router và token test ---
+func testJwtConfig(secret string) configs.AuthConfig {
+	return configs.AuthConfig{
+		JWTSecret:         secret,
+		JWTAlgorithm:      "HS256",
+		JWTKeyID:          "test",
+		JWTIssuer:         "assessment_service",
+		JWTAudience:       "assessment_service",
+		AccessTokenExpiry: 1 * time.Hour, // Token hợp lệ trong 1 giờ
+	}
+}
+
 // --- Helper: Tạo token JWT hợp lệ cho test ---
 func generateTestToken(userID string, role string, secret string) (string, error) {
-	expireTime := time.Now().Add(1 * time.Hour) // Token hợp lệ trong 1 giờ
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"userID": userID,
-		"role":   role,
-		"exp":    expireTime.Unix(),
-	})
-	return token.SignedString([]byte(secret))
+	jwtService, err := util.NewJwtImpl(testJwtConfig(secret))
+	if err != nil {
+		return "", err
+	}
+	return jwtService.GenerateToken(userID, role)
 }
 
 // --- Test Suite ---
@@ -311,21 +846,41 @@ func TestSetupRoutes(t *testing.T) {
 	mockAnalyticsService := new(MockAnalyticsService)
 	mockStudentService := new(MockStudentService)
 	mockAttemptService := new(MockAttemptService)
+	mockAuthService := new(MockAuthService)
+	mockUserService := new(MockUserService)
+	mockPolicy := new(MockAssessmentPolicy)
+	mockCollaboratorService := new(MockCollaboratorService)
+	mockAssignmentService := new(MockAssignmentService)
+	mockAccommodationService := new(MockAccommodationService)
+	mockGroupService := new(MockGroupService)
+	mockBankService := new(MockBankService)
+	mockSectionService := new(MockSectionService)
 	logger := zaptest.NewLogger(t)
 
 	// Setup JWT Secret Key cho test
 	testSecret := "test-jwt-secret-for-routes"
-	originalSecret := os.Getenv("SECRET_KEY")
-	os.Setenv("SECRET_KEY", testSecret)
-	defer os.Setenv("SECRET_KEY", originalSecret) // Khôi phục sau khi test xong
+	jwtService, err := util.NewJwtImpl(testJwtConfig(testSecret))
+	require.NoError(t, err)
 
 	// Gọi hàm cần test
 	router := SetupRoutes(
 		mockAssessmentService,
+		mockCollaboratorService,
+		mockAssignmentService,
+		mockAccommodationService,
+		mockGroupService,
 		mockQuestionService,
+		mockSectionService,
+		mockBankService,
 		mockAnalyticsService,
 		mockStudentService,
 		mockAttemptService,
+		mockAuthService,
+		mockUserService,
+		mockPolicy,
+		live.NewMemoryHub(),
+		jwtService,
+		jwtService,
 		logger,
 	)
 	require.NotNil(t, router)
@@ -333,11 +888,64 @@ func TestSetupRoutes(t *testing.T) {
 	// --- Test Cases cho các Route ---
 
 	t.Run("HealthCheckRoute", func(t *testing.T) {
-		//req := httptest.NewRequest("GET", "/health", nil)
-		//rr := httptest.NewRecorder()
-		//router.ServeHTTP(rr, req)
-		//assert.Equal(t, http.StatusOK, rr.Code)
-		//assert.Equal(t, "Welcome to the Assessment Service!", rr.Body.String())
+		req := httptest.NewRequest("GET", "/health", nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "Welcome to the Assessment Service!", rr.Body.String())
+	})
+
+	t.Run("JWKSRoute_NoAuthRequired", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var body util.JWKSet
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Empty(t, body.Keys) // HS256 không công khai khóa
+	})
+
+	t.Run("TokenFromOtherIssuer_Rejected", func(t *testing.T) {
+		config := testJwtConfig(testSecret)
+		config.JWTIssuer = "other-service"
+		otherService, err := util.NewJwtImpl(config)
+		require.NoError(t, err)
+		token, err := otherService.GenerateToken("1", "admin")
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("GET", "/assessments", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("TokenWithMalformedUserID_Unauthorized", func(t *testing.T) {
+		// Chữ ký hợp lệ nhưng userID không phải số: phải trả về 401 thay vì panic
+		token, err := generateTestToken("not-a-number", "admin", testSecret)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("GET", "/admin/dashboard/summary", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		assert.NotPanics(t, func() { router.ServeHTTP(rr, req) })
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	// --- Test Auth Routes (không cần token) ---
+	t.Run("Login_NoAuthRequired", func(t *testing.T) {
+		mockAuthService.On("Login", "john@example.com", "password123").
+			Return("access", "refresh", &models.User{ID: 1, Role: "student"}, nil).Once()
+
+		reqBody := `{"email":"john@example.com","password":"password123"}`
+		req := httptest.NewRequest("POST", "/auth/login", bytes.NewBufferString(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockAuthService.AssertCalled(t, "Login", "john@example.com", "password123")
 	})
 
 	// --- Test Assessment Routes (Yêu cầu xác thực) ---
@@ -352,7 +960,7 @@ func TestSetupRoutes(t *testing.T) {
 		// Giả lập service trả về dữ liệu
 		mockAssessmentService.On("List", mock.AnythingOfType("util.PaginationParams")).Return([]models.Assessment{}, int64(0), nil).Once()
 
-		token, err := generateTestToken("user1", "teacher", testSecret) // Role teacher được phép
+		token, err := generateTestToken("11", "teacher", testSecret) // Role teacher được phép
 		require.NoError(t, err)
 		req := httptest.NewRequest("GET", "/assessments", nil)
 		req.Header.Set("Authorization", "Bearer "+token)
@@ -385,7 +993,7 @@ func TestSetupRoutes(t *testing.T) {
 	})
 
 	t.Run("CreateAssessment_WithAuth_ForbiddenRole", func(t *testing.T) {
-		token, err := generateTestToken("student1", "student", testSecret) // Role student KHÔNG được phép
+		token, err := generateTestToken("21", "student", testSecret) // Role student KHÔNG được phép
 		require.NoError(t, err)
 		reqBody := `{"title":"Test API", "subject":"API", "duration":30, "passingScore": 70}`
 		req := httptest.NewRequest("POST", "/assessments", bytes.NewBufferString(reqBody))
@@ -417,7 +1025,7 @@ func TestSetupRoutes(t *testing.T) {
 	t.Run("GetDashboardSummary_AdminRole", func(t *testing.T) {
 		mockAnalyticsService.On("GetDashboardSummary").Return(map[string]interface{}{"users": 10.0}, nil).Once()
 
-		token, err := generateTestToken("admin1", "admin", testSecret) // Role admin được phép
+		token, err := generateTestToken("31", "admin", testSecret) // Role admin được phép
 		require.NoError(t, err)
 		req := httptest.NewRequest("GET", "/admin/dashboard/summary", nil)
 		req.Header.Set("Authorization", "Bearer "+token)
@@ -428,13 +1036,67 @@ func TestSetupRoutes(t *testing.T) {
 		mockAnalyticsService.AssertCalled(t, "GetDashboardSummary")
 	})
 
+	// --- Test User Routes ---
+	t.Run("ListUsers_AdminRole", func(t *testing.T) {
+		mockUserService.On("ListUsers", mock.AnythingOfType("util.PaginationParams")).Return([]models.User{}, int64(0), nil).Once()
+
+		token, err := generateTestToken("1", "admin", testSecret)
+		require.NoError(t, err)
+		req := httptest.NewRequest("GET", "/users?role=Student", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockUserService.AssertCalled(t, "ListUsers", mock.MatchedBy(func(p util.PaginationParams) bool {
+			return p.Filters["role"] == "student"
+		}))
+	})
+
+	t.Run("ListUsers_ForbiddenRole", func(t *testing.T) {
+		token, err := generateTestToken("2", "teacher", testSecret)
+		require.NoError(t, err)
+		req := httptest.NewRequest("GET", "/users", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("GetUser_Self", func(t *testing.T) {
+		mockUserService.On("GetUser", uint(5)).Return(&models.User{ID: 5, Role: "student"}, nil).Once()
+
+		token, err := generateTestToken("5", "student", testSecret)
+		require.NoError(t, err)
+		req := httptest.NewRequest("GET", "/users/5", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("UpdateUser_ForbiddenForSelf", func(t *testing.T) {
+		token, err := generateTestToken("5", "student", testSecret)
+		require.NoError(t, err)
+		req := httptest.NewRequest("PUT", "/users/5", bytes.NewBufferString(`{"role":"admin"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockUserService.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything)
+	})
+
 	// Thêm các test case khác cho các route quan trọng còn lại (PUT, DELETE, các route lồng nhau...)
 	// Ví dụ: Test GET /assessments/{id}/questions
 	t.Run("GetAssessmentQuestions_WithAuth", func(t *testing.T) {
 		assessmentID := uint(1)
 		mockQuestionService.On("GetQuestionsByAssessment", assessmentID).Return([]models.Question{}, nil).Once()
+		mockPolicy.On("Authorize", &middleware.Principal{UserID: 12, Role: "teacher"}, assessmentID, policy.ActionManageQuestions).Return(nil).Once()
 
-		token, err := generateTestToken("teacher2", "teacher", testSecret) // Teacher được phép
+		token, err := generateTestToken("12", "teacher", testSecret) // Teacher sở hữu bài kiểm tra
 		require.NoError(t, err)
 		req := httptest.NewRequest("GET", fmt.Sprintf("/assessments/%d/questions", assessmentID), nil)
 		req.Header.Set("Authorization", "Bearer "+token)
@@ -445,4 +1107,282 @@ func TestSetupRoutes(t *testing.T) {
 		mockQuestionService.AssertCalled(t, "GetQuestionsByAssessment", assessmentID)
 	})
 
+	t.Run("UpdateAssessment_NotOwner_Forbidden", func(t *testing.T) {
+		// Giáo viên khác không được sửa bài kiểm tra không thuộc về mình
+		mockPolicy.On("Authorize", &middleware.Principal{UserID: 13, Role: "teacher"}, uint(1), policy.ActionEdit).Return(policy.ErrForbidden).Once()
+
+		token, err := generateTestToken("13", "teacher", testSecret)
+		require.NoError(t, err)
+		req := httptest.NewRequest("PUT", "/assessments/1", bytes.NewBufferString(`{"title":"x"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockAssessmentService.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("AddCollaborator_EditorForbidden", func(t *testing.T) {
+		// Editors can change the assessment but not who it is shared with
+		mockPolicy.On("Authorize", &middleware.Principal{UserID: 14, Role: "teacher"}, uint(1), policy.ActionManageSharing).Return(policy.ErrForbidden).Once()
+
+		token, err := generateTestToken("14", "teacher", testSecret)
+		require.NoError(t, err)
+		req := httptest.NewRequest("POST", "/assessments/1/collaborators", bytes.NewBufferString(`{"userId":15,"role":"owner"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockCollaboratorService.AssertNotCalled(t, "AddCollaborator", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("EnrollGroupMembers_StudentForbidden", func(t *testing.T) {
+		token, err := generateTestToken("17", "student", testSecret)
+		require.NoError(t, err)
+		req := httptest.NewRequest("POST", "/groups/1/members", bytes.NewBufferString(`{"userIds":[17]}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockGroupService.AssertNotCalled(t, "EnrollMembers", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("AssignAssessment_GraderForbidden", func(t *testing.T) {
+		// Graders can mark attempts but not choose who takes the assessment
+		mockPolicy.On("Authorize", &middleware.Principal{UserID: 18, Role: "teacher"}, uint(1), policy.ActionAssign).Return(policy.ErrForbidden).Once()
+
+		token, err := generateTestToken("18", "teacher", testSecret)
+		require.NoError(t, err)
+		req := httptest.NewRequest("POST", "/assessments/1/assignments", bytes.NewBufferString(`{"groupIds":[1]}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockAssignmentService.AssertNotCalled(t, "AssignAssessment", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("CreateAccommodation_ViewerForbidden", func(t *testing.T) {
+		mockPolicy.On("Authorize", &middleware.Principal{UserID: 19, Role: "teacher"}, uint(1), policy.ActionAssign).Return(policy.ErrForbidden).Once()
+
+		token, err := generateTestToken("19", "teacher", testSecret)
+		require.NoError(t, err)
+		req := httptest.NewRequest("POST", "/assessments/1/accommodations", bytes.NewBufferString(`{"userId":17,"timeMultiplier":1.5}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockAccommodationService.AssertNotCalled(t, "CreateAccommodation", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ArchiveAssessment_EditorForbidden", func(t *testing.T) {
+		mockPolicy.On("Authorize", &middleware.Principal{UserID: 20, Role: "teacher"}, uint(1), policy.ActionDelete).Return(policy.ErrForbidden).Once()
+
+		token, err := generateTestToken("20", "teacher", testSecret)
+		require.NoError(t, err)
+		req := httptest.NewRequest("POST", "/assessments/1/archive", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockAssessmentService.AssertNotCalled(t, "Archive", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("DuplicateAssessment_NoAccessForbidden", func(t *testing.T) {
+		mockPolicy.On("Authorize", &middleware.Principal{UserID: 34, Role: "teacher"}, uint(1), policy.ActionView).Return(policy.ErrForbidden).Once()
+
+		token, err := generateTestToken("34", "teacher", testSecret)
+		require.NoError(t, err)
+		req := httptest.NewRequest("POST", "/assessments/1/duplicate", bytes.NewBufferString(`{"copyQuestions":true}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockAssessmentService.AssertNotCalled(t, "Duplicate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("GetAssessmentResults_GraderHidesIdentities", func(t *testing.T) {
+		grader := &middleware.Principal{UserID: 35, Role: "teacher"}
+		mockPolicy.On("Authorize", grader, uint(1), policy.ActionViewResults).Return(nil).Once()
+		mockPolicy.On("Authorize", grader, uint(1), policy.ActionViewIdentities).Return(policy.ErrForbidden).Once()
+		mockAssessmentService.On("GetResults", uint(1), mock.Anything, true).Return([]map[string]interface{}{{"id": 7, "candidate": "Candidate 1"}}, int64(1), nil).Once()
+
+		token, err := generateTestToken("35", "teacher", testSecret)
+		require.NoError(t, err)
+		req := httptest.NewRequest("GET", "/assessments/1/results", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("CloseAssessment_Owner", func(t *testing.T) {
+		mockPolicy.On("Authorize", &middleware.Principal{UserID: 21, Role: "teacher"}, uint(1), policy.ActionPublish).Return(nil).Once()
+		mockAssessmentService.On("Close", uint(1), uint(21), "").Return(&models.Assessment{ID: 1, Status: models.AssessmentClosed}, nil).Once()
+
+		token, err := generateTestToken("21", "teacher", testSecret)
+		require.NoError(t, err)
+		req := httptest.NewRequest("POST", "/assessments/1/close", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("GradeAttempt_ChecksAttemptAssessment", func(t *testing.T) {
+		mockPolicy.On("AuthorizeAttempt", &middleware.Principal{UserID: 16, Role: "teacher"}, uint(9), policy.ActionGrade).Return(policy.ErrAttemptNotFound).Once()
+
+		token, err := generateTestToken("16", "teacher", testSecret)
+		require.NoError(t, err)
+		req := httptest.NewRequest("POST", "/assessments/attempts/9/grade", bytes.NewBufferString(`{}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("AssignGraders_GraderForbidden", func(t *testing.T) {
+		// Graders mark the answers they are given but do not hand out work
+		mockPolicy.On("Authorize", &middleware.Principal{UserID: 22, Role: "teacher"}, uint(1), policy.ActionManageSharing).Return(policy.ErrForbidden).Once()
+
+		token, err := generateTestToken("22", "teacher", testSecret)
+		require.NoError(t, err)
+		req := httptest.NewRequest("POST", "/assessments/1/grading/assign", bytes.NewBufferString(`{}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockAttemptService.AssertNotCalled(t, "AssignGraders", mock.Anything, mock.Anything)
+	})
+
+	t.Run("GraderAssignments_StudentForbidden", func(t *testing.T) {
+		token, err := generateTestToken("23", "student", testSecret)
+		require.NoError(t, err)
+		req := httptest.NewRequest("GET", "/grading/assignments", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockAttemptService.AssertNotCalled(t, "GetGraderAssignments", mock.Anything, mock.Anything)
+	})
+
+	t.Run("AcceptRegradeRequest_EditorForbidden", func(t *testing.T) {
+		// Editors can change the assessment but only graders review regrade requests
+		mockPolicy.On("AuthorizeAttempt", &middleware.Principal{UserID: 24, Role: "teacher"}, uint(9), policy.ActionGrade).Return(policy.ErrForbidden).Once()
+
+		token, err := generateTestToken("24", "teacher", testSecret)
+		require.NoError(t, err)
+		req := httptest.NewRequest("POST", "/assessments/attempts/9/regrade-requests/4/accept", bytes.NewBufferString(`{"score":80}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockAttemptService.AssertNotCalled(t, "AcceptRegradeRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("GradeEvents_AdminOnly", func(t *testing.T) {
+		token, err := generateTestToken("25", "teacher", testSecret)
+		require.NoError(t, err)
+		req := httptest.NewRequest("GET", "/admin/attempts/9/grade-events", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockAttemptService.AssertNotCalled(t, "GetGradeEvents", mock.Anything)
+	})
+
+	t.Run("RequestRegrade_Student", func(t *testing.T) {
+		request := models.RegradeRequestDTO{Reason: "Question 2 was marked wrong"}
+		mockStudentService.On("RequestRegrade", uint(26), uint(26), request).Return(&models.RegradeRequest{ID: 1, AttemptID: 26}, nil).Once()
+
+		token, err := generateTestToken("26", "student", testSecret)
+		require.NoError(t, err)
+		req := httptest.NewRequest("POST", "/student/attempts/26/regrade-requests", bytes.NewBufferString(`{"reason":"Question 2 was marked wrong"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusCreated, rr.Code)
+	})
+
+	t.Run("RescoreAssessment_Grader", func(t *testing.T) {
+		// Graders can rescore, editors change questions but cannot grade
+		mockPolicy.On("Authorize", &middleware.Principal{UserID: 27, Role: "teacher"}, uint(1), policy.ActionGrade).Return(nil).Once()
+		mockPolicy.On("Authorize", &middleware.Principal{UserID: 28, Role: "teacher"}, uint(1), policy.ActionGrade).Return(policy.ErrForbidden).Once()
+		mockAttemptService.On("RescoreAssessment", uint(1), uint(27), []uint{5}).Return(&models.RescoreSummary{AssessmentID: 1}, nil).Once()
+
+		for userID, want := range map[string]int{"27": http.StatusOK, "28": http.StatusForbidden} {
+			token, err := generateTestToken(userID, "teacher", testSecret)
+			require.NoError(t, err)
+			req := httptest.NewRequest("POST", "/assessments/1/rescore", bytes.NewBufferString(`{"questionIds":[5]}`))
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+			assert.Equal(t, want, rr.Code, userID)
+		}
+		mockAttemptService.AssertNumberOfCalls(t, "RescoreAssessment", 1)
+	})
+
+	t.Run("BankQuestions_StudentForbidden", func(t *testing.T) {
+		token, err := generateTestToken("29", "student", testSecret)
+		require.NoError(t, err)
+		req := httptest.NewRequest("GET", "/bank/questions", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockBankService.AssertNotCalled(t, "ListQuestions", mock.Anything, mock.Anything)
+	})
+
+	t.Run("AddBankQuestion_ChecksAssessment", func(t *testing.T) {
+		// Thêm câu hỏi từ ngân hàng cần quyền quản lý câu hỏi của bài kiểm tra
+		mockPolicy.On("Authorize", &middleware.Principal{UserID: 30, Role: "teacher"}, uint(1), policy.ActionManageQuestions).Return(nil).Once()
+		mockPolicy.On("Authorize", &middleware.Principal{UserID: 31, Role: "teacher"}, uint(1), policy.ActionManageQuestions).Return(policy.ErrForbidden).Once()
+		mockBankService.On("AddToAssessment", uint(1), models.BankImportDTO{BankQuestionID: 4}, uint(30)).Return(&models.Question{ID: 9, AssessmentID: 1}, nil).Once()
+
+		for userID, want := range map[string]int{"30": http.StatusCreated, "31": http.StatusForbidden} {
+			token, err := generateTestToken(userID, "teacher", testSecret)
+			require.NoError(t, err)
+			req := httptest.NewRequest("POST", "/assessments/1/questions/from-bank", bytes.NewBufferString(`{"bankQuestionId":4}`))
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+			assert.Equal(t, want, rr.Code, userID)
+		}
+		mockBankService.AssertNumberOfCalls(t, "AddToAssessment", 1)
+	})
+
+	t.Run("Sections_CheckAssessment", func(t *testing.T) {
+		// Quản lý phần thi cần quyền quản lý câu hỏi của bài kiểm tra
+		mockPolicy.On("Authorize", &middleware.Principal{UserID: 32, Role: "teacher"}, uint(1), policy.ActionManageQuestions).Return(nil).Once()
+		mockPolicy.On("Authorize", &middleware.Principal{UserID: 33, Role: "teacher"}, uint(1), policy.ActionManageQuestions).Return(policy.ErrForbidden).Once()
+		mockSectionService.On("ListSections", uint(1)).Return([]models.AssessmentSection{{ID: 2, AssessmentID: 1, Title: "Part A"}}, nil).Once()
+
+		for userID, want := range map[string]int{"32": http.StatusOK, "33": http.StatusForbidden} {
+			token, err := generateTestToken(userID, "teacher", testSecret)
+			require.NoError(t, err)
+			req := httptest.NewRequest("GET", "/assessments/1/sections", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+			assert.Equal(t, want, rr.Code, userID)
+		}
+		mockSectionService.AssertNumberOfCalls(t, "ListSections", 1)
+	})
 }
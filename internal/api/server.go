@@ -4,15 +4,27 @@ import (
 	"assessment_service/configs"
 	repository5 "assessment_service/internal/activity/repository"
 	service4 "assessment_service/internal/activity/service"
+	"assessment_service/internal/assessments/policy"
 	"assessment_service/internal/assessments/repository/postgres"
 	"assessment_service/internal/assessments/service"
 	repository4 "assessment_service/internal/attempts/repository"
 	service5 "assessment_service/internal/attempts/service"
+	repository6 "assessment_service/internal/auth/repository"
+	service6 "assessment_service/internal/auth/service"
+	repository8 "assessment_service/internal/bank/repository"
+	service9 "assessment_service/internal/bank/service"
 	"assessment_service/internal/cronjob"
+	repository7 "assessment_service/internal/groups/repository"
+	service8 "assessment_service/internal/groups/service"
+	"assessment_service/internal/live"
 	repository3 "assessment_service/internal/questions/repository"
 	service2 "assessment_service/internal/questions/service"
 	service3 "assessment_service/internal/student/service"
 	"assessment_service/internal/users/repository"
+	service7 "assessment_service/internal/users/service"
+	"assessment_service/internal/util"
+	"assessment_service/pkg/mailer"
+	"assessment_service/pkg/redis"
 	"context"
 	"fmt"
 	"github.com/gorilla/handlers"
@@ -44,29 +56,81 @@ func NewServer(config *configs.Config, db *gorm.DB, log *zap.Logger) *Server {
 
 func (s *Server) Run() error {
 	// Set up services and handlers
-	// jwtUtil := util.NewJwtImpl()
+	jwtUtil, err := util.NewJwtImpl(s.config.Auth)
+	if err != nil {
+		return fmt.Errorf("failed to create jwt service: %w", err)
+	}
+
+	mailService, err := mailer.NewMailer(s.config.Mail)
+	if err != nil {
+		return fmt.Errorf("failed to create mailer: %w", err)
+	}
+
+	// Live attempt channels fan out through Redis when it is configured, so that every instance
+	// reaches the students connected to it
+	hub := live.NewMemoryHub()
+	if s.config.Redis.URL != "" {
+		redisClient, err := redis.NewRedisClient(s.config.Redis.URL)
+		if err != nil {
+			return fmt.Errorf("failed to connect to redis: %w", err)
+		}
+		defer redisClient.Close()
+
+		hubCtx, stopHub := context.WithCancel(context.Background())
+		defer stopHub()
+		hub = live.NewRedisHub(hubCtx, redisClient, s.log)
+	}
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(s.db)
 	assessmentRepo := postgres.NewAssessmentRepository(s.db)
+	collaboratorRepo := postgres.NewCollaboratorRepository(s.db)
+	assignmentRepo := postgres.NewAssignmentRepository(s.db)
+	accommodationRepo := postgres.NewAccommodationRepository(s.db)
+	groupRepo := repository7.NewGroupRepository(s.db)
 	questionRepo := repository3.NewQuestionRepository(s.db)
+	sectionRepo := repository3.NewSectionRepository(s.db)
+	bankRepo := repository8.NewBankRepository(s.db)
 	attemptRepo := repository4.NewAttemptRepository(s.db)
 	activityRepo := repository5.NewActivityRepository(s.db)
+	refreshTokenRepo := repository6.NewRefreshTokenRepository(s.db)
+	passwordResetRepo := repository6.NewPasswordResetRepository(s.db)
 
 	// Initialize services
-	assessmentService := service.NewAssessmentService(assessmentRepo, userRepo)
-	questionService := service2.NewQuestionService(questionRepo, assessmentRepo)
-	studentService := service3.NewStudentService(assessmentRepo, attemptRepo, questionRepo, userRepo, s.log)
-	analyticsService := service4.NewAnalyticsService(userRepo, assessmentRepo, attemptRepo, activityRepo, s.log)
-	attemptService := service5.NewAttemptService(attemptRepo, s.log)
+	assessmentService := service.NewAssessmentService(assessmentRepo, userRepo, s.log)
+	collaboratorService := service.NewCollaboratorService(assessmentRepo, collaboratorRepo, userRepo, s.log)
+	assignmentService := service.NewAssignmentService(assessmentRepo, assignmentRepo, groupRepo, userRepo, s.log)
+	accommodationService := service.NewAccommodationService(assessmentRepo, accommodationRepo, groupRepo, userRepo, s.log)
+	groupService := service8.NewGroupService(groupRepo, userRepo, s.log)
+	attemptService := service5.NewAttemptService(attemptRepo, assessmentRepo, questionRepo, hub, s.log)
+	questionService := service2.NewQuestionService(questionRepo, assessmentRepo, attemptService)
+	sectionService := service2.NewSectionService(sectionRepo, questionRepo, assessmentRepo, s.log)
+	bankService := service9.NewBankService(bankRepo, userRepo, assessmentRepo, questionRepo, s.log)
+	studentService := service3.NewStudentService(assessmentRepo, attemptRepo, questionRepo, userRepo, assignmentRepo, accommodationRepo, hub, s.log)
+	analyticsService := service4.NewAnalyticsService(userRepo, assessmentRepo, attemptRepo, activityRepo, hub, s.log)
+	userService := service7.NewUserService(userRepo, activityRepo, assessmentRepo, refreshTokenRepo, s.log)
+	authService := service6.NewAuthService(userRepo, refreshTokenRepo, passwordResetRepo, jwtUtil, mailService, s.config.Auth, s.log)
+	assessmentPolicy := policy.NewAssessmentPolicy(assessmentRepo, collaboratorRepo, questionRepo, attemptRepo, s.log)
 
 	// Set up routes
 	s.router = SetupRoutes(
 		assessmentService,
+		collaboratorService,
+		assignmentService,
+		accommodationService,
+		groupService,
 		questionService,
+		sectionService,
+		bankService,
 		analyticsService,
 		studentService,
 		attemptService,
+		authService,
+		userService,
+		assessmentPolicy,
+		hub,
+		jwtUtil,
+		jwtUtil,
 		s.log,
 	)
 
@@ -98,8 +162,9 @@ func (s *Server) Run() error {
 		cron.WithChain(
 			cron.Recover(cron.DefaultLogger), // Tự động phục hồi nếu có panic
 		))
-	cronJobService := cronjob.NewCronJobService(studentService, s.log, cronJob)
+	cronJobService := cronjob.NewCronJobService(studentService, assessmentService, s.log, cronJob)
 	cronJobService.StartAutoSubmit()
+	cronJobService.StartStatusUpdates()
 
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
@@ -0,0 +1,167 @@
+package rest
+
+import (
+	"assessment_service/internal/assessments/service"
+	"assessment_service/internal/middleware"
+	models "assessment_service/internal/model"
+	"assessment_service/internal/util"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type AccommodationHandler struct {
+	accommodationService service.AccommodationService
+	log                  *zap.Logger
+}
+
+func NewAccommodationHandler(accommodationService service.AccommodationService, log *zap.Logger) *AccommodationHandler {
+	return &AccommodationHandler{accommodationService: accommodationService, log: log}
+}
+
+func (h *AccommodationHandler) ListAccommodations(w http.ResponseWriter, r *http.Request) {
+	assessmentID, ok := h.parseID(w, r, "id", "Invalid assessment ID")
+	if !ok {
+		return
+	}
+
+	accommodations, err := h.accommodationService.ListAccommodations(assessmentID)
+	if err != nil {
+		h.writeError(w, "ListAccommodations", err, "Failed to list accommodations")
+		return
+	}
+
+	util.ResponseInterface(w, accommodations, http.StatusOK)
+}
+
+func (h *AccommodationHandler) CreateAccommodation(w http.ResponseWriter, r *http.Request) {
+	assessmentID, ok := h.parseID(w, r, "id", "Invalid assessment ID")
+	if !ok {
+		return
+	}
+
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "UNAUTHORIZED",
+			"message": "Unauthorized",
+		}, http.StatusUnauthorized)
+		return
+	}
+
+	var req models.AccommodationDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("[CreateAccommodation] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	accommodation, err := h.accommodationService.CreateAccommodation(assessmentID, req, principal.UserID)
+	if err != nil {
+		h.writeError(w, "CreateAccommodation", err, "Failed to create accommodation")
+		return
+	}
+
+	util.ResponseInterface(w, accommodation, http.StatusCreated)
+}
+
+func (h *AccommodationHandler) UpdateAccommodation(w http.ResponseWriter, r *http.Request) {
+	assessmentID, ok := h.parseID(w, r, "id", "Invalid assessment ID")
+	if !ok {
+		return
+	}
+
+	accommodationID, ok := h.parseID(w, r, "accommodationId", "Invalid accommodation ID")
+	if !ok {
+		return
+	}
+
+	var req models.AccommodationDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("[UpdateAccommodation] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	accommodation, err := h.accommodationService.UpdateAccommodation(assessmentID, accommodationID, req)
+	if err != nil {
+		h.writeError(w, "UpdateAccommodation", err, "Failed to update accommodation")
+		return
+	}
+
+	util.ResponseInterface(w, accommodation, http.StatusOK)
+}
+
+func (h *AccommodationHandler) DeleteAccommodation(w http.ResponseWriter, r *http.Request) {
+	assessmentID, ok := h.parseID(w, r, "id", "Invalid assessment ID")
+	if !ok {
+		return
+	}
+
+	accommodationID, ok := h.parseID(w, r, "accommodationId", "Invalid accommodation ID")
+	if !ok {
+		return
+	}
+
+	if err := h.accommodationService.DeleteAccommodation(assessmentID, accommodationID); err != nil {
+		h.writeError(w, "DeleteAccommodation", err, "Failed to delete accommodation")
+		return
+	}
+
+	util.ResponseMap(w, map[string]interface{}{
+		"message": "Accommodation deleted successfully",
+	}, http.StatusOK)
+}
+
+func (h *AccommodationHandler) parseID(w http.ResponseWriter, r *http.Request, name, message string) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)[name], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": message,
+		}, http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeError maps service errors to HTTP responses
+func (h *AccommodationHandler) writeError(w http.ResponseWriter, fn string, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrAssessmentNotFound),
+		errors.Is(err, service.ErrAccommodationNotFound),
+		errors.Is(err, service.ErrAccommodationTargetNotFound):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "NOT_FOUND",
+			"message": err.Error(),
+		}, http.StatusNotFound)
+	case errors.Is(err, service.ErrAccommodationExists):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "CONFLICT",
+			"message": err.Error(),
+		}, http.StatusConflict)
+	case errors.Is(err, service.ErrAccommodationTargetRequired),
+		errors.Is(err, service.ErrAccommodationNotStudent),
+		errors.Is(err, service.ErrInvalidAccommodation):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": err.Error(),
+		}, http.StatusBadRequest)
+	default:
+		h.log.Error("["+fn+"] "+message, zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "ERROR",
+			"message": message,
+		}, http.StatusInternalServerError)
+	}
+}
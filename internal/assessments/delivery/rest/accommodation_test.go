@@ -0,0 +1,112 @@
+package rest
+
+import (
+	"assessment_service/internal/assessments/service"
+	"assessment_service/internal/middleware"
+	models "assessment_service/internal/model"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+// --- Mock AccommodationService ---
+type MockAccommodationService struct {
+	mock.Mock
+}
+
+func (m *MockAccommodationService) ListAccommodations(assessmentID uint) ([]models.AssessmentAccommodation, error) {
+	args := m.Called(assessmentID)
+	accommodations, _ := args.Get(0).([]models.AssessmentAccommodation)
+	return accommodations, args.Error(1)
+}
+
+func (m *MockAccommodationService) CreateAccommodation(assessmentID uint, accommodation models.AccommodationDTO, createdByID uint) (*models.AssessmentAccommodation, error) {
+	args := m.Called(assessmentID, accommodation, createdByID)
+	created, _ := args.Get(0).(*models.AssessmentAccommodation)
+	return created, args.Error(1)
+}
+
+func (m *MockAccommodationService) UpdateAccommodation(assessmentID, accommodationID uint, accommodation models.AccommodationDTO) (*models.AssessmentAccommodation, error) {
+	args := m.Called(assessmentID, accommodationID, accommodation)
+	updated, _ := args.Get(0).(*models.AssessmentAccommodation)
+	return updated, args.Error(1)
+}
+
+func (m *MockAccommodationService) DeleteAccommodation(assessmentID, accommodationID uint) error {
+	args := m.Called(assessmentID, accommodationID)
+	return args.Error(0)
+}
+
+func serveAccommodations(handler *AccommodationHandler, req *http.Request) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	router.HandleFunc("/assessments/{id}/accommodations", handler.ListAccommodations).Methods(http.MethodGet)
+	router.HandleFunc("/assessments/{id}/accommodations", handler.CreateAccommodation).Methods(http.MethodPost)
+	router.HandleFunc("/assessments/{id}/accommodations/{accommodationId}", handler.UpdateAccommodation).Methods(http.MethodPut)
+	router.HandleFunc("/assessments/{id}/accommodations/{accommodationId}", handler.DeleteAccommodation).Methods(http.MethodDelete)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestAccommodationHandler_CreateAccommodation(t *testing.T) {
+	mockService := new(MockAccommodationService)
+	handler := NewAccommodationHandler(mockService, zaptest.NewLogger(t))
+
+	userID := uint(20)
+	dto := models.AccommodationDTO{UserID: &userID, TimeMultiplier: 1.5, ExtraAttempts: 1}
+	mockService.On("CreateAccommodation", uint(1), dto, uint(10)).
+		Return(&models.AssessmentAccommodation{ID: 7, AssessmentID: 1, UserID: &userID, TimeMultiplier: 1.5, ExtraAttempts: 1}, nil)
+
+	req := createRequestWithClaims(http.MethodPost, "/assessments/1/accommodations", []byte(`{"userId":20,"timeMultiplier":1.5,"extraAttempts":1}`), &middleware.Principal{UserID: 10, Role: "teacher"})
+	rr := serveAccommodations(handler, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAccommodationHandler_CreateAccommodation_Errors(t *testing.T) {
+	cases := []struct {
+		err      error
+		wantCode int
+	}{
+		{service.ErrAccommodationTargetRequired, http.StatusBadRequest},
+		{fmt.Errorf("%w: time multiplier must be between 1 and 5", service.ErrInvalidAccommodation), http.StatusBadRequest},
+		{fmt.Errorf("%w: user 20", service.ErrAccommodationNotStudent), http.StatusBadRequest},
+		{fmt.Errorf("%w: group 3", service.ErrAccommodationTargetNotFound), http.StatusNotFound},
+		{service.ErrAccommodationExists, http.StatusConflict},
+		{service.ErrAssessmentNotFound, http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			mockService := new(MockAccommodationService)
+			handler := NewAccommodationHandler(mockService, zaptest.NewLogger(t))
+
+			mockService.On("CreateAccommodation", uint(1), mock.Anything, uint(10)).Return(nil, tc.err)
+
+			req := createRequestWithClaims(http.MethodPost, "/assessments/1/accommodations", []byte(`{"userId":20}`), &middleware.Principal{UserID: 10, Role: "teacher"})
+			rr := serveAccommodations(handler, req)
+
+			assert.Equal(t, tc.wantCode, rr.Code)
+		})
+	}
+}
+
+func TestAccommodationHandler_DeleteAccommodation(t *testing.T) {
+	mockService := new(MockAccommodationService)
+	handler := NewAccommodationHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("DeleteAccommodation", uint(1), uint(5)).Return(service.ErrAccommodationNotFound)
+
+	rr := serveAccommodations(handler, httptest.NewRequest(http.MethodDelete, "/assessments/1/accommodations/5", nil))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,130 @@
+package rest
+
+import (
+	"assessment_service/internal/assessments/service"
+	"assessment_service/internal/middleware"
+	models "assessment_service/internal/model"
+	"assessment_service/internal/util"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type AssignmentHandler struct {
+	assignmentService service.AssignmentService
+	log               *zap.Logger
+}
+
+func NewAssignmentHandler(assignmentService service.AssignmentService, log *zap.Logger) *AssignmentHandler {
+	return &AssignmentHandler{assignmentService: assignmentService, log: log}
+}
+
+func (h *AssignmentHandler) ListAssignments(w http.ResponseWriter, r *http.Request) {
+	assessmentID, ok := h.parseID(w, r, "id", "Invalid assessment ID")
+	if !ok {
+		return
+	}
+
+	assignments, err := h.assignmentService.ListAssignments(assessmentID)
+	if err != nil {
+		h.writeError(w, "ListAssignments", err, "Failed to list assignments")
+		return
+	}
+
+	util.ResponseInterface(w, assignments, http.StatusOK)
+}
+
+func (h *AssignmentHandler) AssignAssessment(w http.ResponseWriter, r *http.Request) {
+	assessmentID, ok := h.parseID(w, r, "id", "Invalid assessment ID")
+	if !ok {
+		return
+	}
+
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "UNAUTHORIZED",
+			"message": "Unauthorized",
+		}, http.StatusUnauthorized)
+		return
+	}
+
+	var req models.AssignmentDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("[AssignAssessment] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	assignments, err := h.assignmentService.AssignAssessment(assessmentID, req, principal.UserID)
+	if err != nil {
+		h.writeError(w, "AssignAssessment", err, "Failed to assign assessment")
+		return
+	}
+
+	util.ResponseInterface(w, assignments, http.StatusOK)
+}
+
+func (h *AssignmentHandler) UnassignAssessment(w http.ResponseWriter, r *http.Request) {
+	assessmentID, ok := h.parseID(w, r, "id", "Invalid assessment ID")
+	if !ok {
+		return
+	}
+
+	assignmentID, ok := h.parseID(w, r, "assignmentId", "Invalid assignment ID")
+	if !ok {
+		return
+	}
+
+	if err := h.assignmentService.UnassignAssessment(assessmentID, assignmentID); err != nil {
+		h.writeError(w, "UnassignAssessment", err, "Failed to remove assignment")
+		return
+	}
+
+	util.ResponseMap(w, map[string]interface{}{
+		"message": "Assignment removed successfully",
+	}, http.StatusOK)
+}
+
+func (h *AssignmentHandler) parseID(w http.ResponseWriter, r *http.Request, name, message string) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)[name], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": message,
+		}, http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeError maps service errors to HTTP responses
+func (h *AssignmentHandler) writeError(w http.ResponseWriter, fn string, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrAssessmentNotFound),
+		errors.Is(err, service.ErrAssignmentNotFound),
+		errors.Is(err, service.ErrAssigneeNotFound):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "NOT_FOUND",
+			"message": err.Error(),
+		}, http.StatusNotFound)
+	case errors.Is(err, service.ErrNoAssigneesGiven), errors.Is(err, service.ErrAssigneeNotStudent):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": err.Error(),
+		}, http.StatusBadRequest)
+	default:
+		h.log.Error("["+fn+"] "+message, zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "ERROR",
+			"message": message,
+		}, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,102 @@
+package rest
+
+import (
+	"assessment_service/internal/assessments/service"
+	"assessment_service/internal/middleware"
+	models "assessment_service/internal/model"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+// --- Mock AssignmentService ---
+type MockAssignmentService struct {
+	mock.Mock
+}
+
+func (m *MockAssignmentService) ListAssignments(assessmentID uint) ([]models.AssessmentAssignment, error) {
+	args := m.Called(assessmentID)
+	assignments, _ := args.Get(0).([]models.AssessmentAssignment)
+	return assignments, args.Error(1)
+}
+
+func (m *MockAssignmentService) AssignAssessment(assessmentID uint, assignment models.AssignmentDTO, assignedByID uint) ([]models.AssessmentAssignment, error) {
+	args := m.Called(assessmentID, assignment, assignedByID)
+	assignments, _ := args.Get(0).([]models.AssessmentAssignment)
+	return assignments, args.Error(1)
+}
+
+func (m *MockAssignmentService) UnassignAssessment(assessmentID, assignmentID uint) error {
+	args := m.Called(assessmentID, assignmentID)
+	return args.Error(0)
+}
+
+func serveAssignments(handler *AssignmentHandler, req *http.Request) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	router.HandleFunc("/assessments/{id}/assignments", handler.ListAssignments).Methods(http.MethodGet)
+	router.HandleFunc("/assessments/{id}/assignments", handler.AssignAssessment).Methods(http.MethodPost)
+	router.HandleFunc("/assessments/{id}/assignments/{assignmentId}", handler.UnassignAssessment).Methods(http.MethodDelete)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestAssignmentHandler_AssignAssessment(t *testing.T) {
+	mockService := new(MockAssignmentService)
+	handler := NewAssignmentHandler(mockService, zaptest.NewLogger(t))
+
+	groupID := uint(3)
+	mockService.On("AssignAssessment", uint(1), models.AssignmentDTO{GroupIDs: []uint{3}}, uint(10)).
+		Return([]models.AssessmentAssignment{{ID: 1, AssessmentID: 1, GroupID: &groupID}}, nil)
+
+	req := createRequestWithClaims(http.MethodPost, "/assessments/1/assignments", []byte(`{"groupIds":[3]}`), &middleware.Principal{UserID: 10, Role: "teacher"})
+	rr := serveAssignments(handler, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAssignmentHandler_AssignAssessment_Errors(t *testing.T) {
+	cases := []struct {
+		err      error
+		wantCode int
+	}{
+		{service.ErrNoAssigneesGiven, http.StatusBadRequest},
+		{fmt.Errorf("%w: user 20", service.ErrAssigneeNotStudent), http.StatusBadRequest},
+		{fmt.Errorf("%w: group 3", service.ErrAssigneeNotFound), http.StatusNotFound},
+		{service.ErrAssessmentNotFound, http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			mockService := new(MockAssignmentService)
+			handler := NewAssignmentHandler(mockService, zaptest.NewLogger(t))
+
+			mockService.On("AssignAssessment", uint(1), mock.Anything, uint(10)).Return(nil, tc.err)
+
+			req := createRequestWithClaims(http.MethodPost, "/assessments/1/assignments", []byte(`{"userIds":[20]}`), &middleware.Principal{UserID: 10, Role: "teacher"})
+			rr := serveAssignments(handler, req)
+
+			assert.Equal(t, tc.wantCode, rr.Code)
+		})
+	}
+}
+
+func TestAssignmentHandler_UnassignAssessment(t *testing.T) {
+	mockService := new(MockAssignmentService)
+	handler := NewAssignmentHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("UnassignAssessment", uint(1), uint(5)).Return(service.ErrAssignmentNotFound)
+
+	rr := serveAssignments(handler, httptest.NewRequest(http.MethodDelete, "/assessments/1/assignments/5", nil))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,169 @@
+package rest
+
+import (
+	"assessment_service/internal/assessments/service"
+	"assessment_service/internal/middleware"
+	models "assessment_service/internal/model"
+	"assessment_service/internal/util"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type CollaboratorHandler struct {
+	collaboratorService service.CollaboratorService
+	log                 *zap.Logger
+}
+
+func NewCollaboratorHandler(collaboratorService service.CollaboratorService, log *zap.Logger) *CollaboratorHandler {
+	return &CollaboratorHandler{collaboratorService: collaboratorService, log: log}
+}
+
+func (h *CollaboratorHandler) ListCollaborators(w http.ResponseWriter, r *http.Request) {
+	assessmentID, ok := h.parseID(w, r, "id", "Invalid assessment ID")
+	if !ok {
+		return
+	}
+
+	collaborators, err := h.collaboratorService.ListCollaborators(assessmentID)
+	if err != nil {
+		h.writeError(w, "ListCollaborators", err, "Failed to list collaborators")
+		return
+	}
+
+	util.ResponseInterface(w, collaborators, http.StatusOK)
+}
+
+func (h *CollaboratorHandler) AddCollaborator(w http.ResponseWriter, r *http.Request) {
+	assessmentID, ok := h.parseID(w, r, "id", "Invalid assessment ID")
+	if !ok {
+		return
+	}
+
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "UNAUTHORIZED",
+			"message": "Unauthorized",
+		}, http.StatusUnauthorized)
+		return
+	}
+
+	var req models.CollaboratorInviteDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Role == "" {
+		h.log.Error("[AddCollaborator] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	collaborator, err := h.collaboratorService.AddCollaborator(assessmentID, req, principal.UserID)
+	if err != nil {
+		h.writeError(w, "AddCollaborator", err, "Failed to add collaborator")
+		return
+	}
+
+	util.ResponseInterface(w, collaborator, http.StatusCreated)
+}
+
+func (h *CollaboratorHandler) UpdateCollaborator(w http.ResponseWriter, r *http.Request) {
+	assessmentID, ok := h.parseID(w, r, "id", "Invalid assessment ID")
+	if !ok {
+		return
+	}
+
+	userID, ok := h.parseID(w, r, "userId", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Role == "" {
+		h.log.Error("[UpdateCollaborator] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	collaborator, err := h.collaboratorService.UpdateCollaboratorRole(assessmentID, userID, req.Role)
+	if err != nil {
+		h.writeError(w, "UpdateCollaborator", err, "Failed to update collaborator")
+		return
+	}
+
+	util.ResponseInterface(w, collaborator, http.StatusOK)
+}
+
+func (h *CollaboratorHandler) RemoveCollaborator(w http.ResponseWriter, r *http.Request) {
+	assessmentID, ok := h.parseID(w, r, "id", "Invalid assessment ID")
+	if !ok {
+		return
+	}
+
+	userID, ok := h.parseID(w, r, "userId", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	if err := h.collaboratorService.RemoveCollaborator(assessmentID, userID); err != nil {
+		h.writeError(w, "RemoveCollaborator", err, "Failed to remove collaborator")
+		return
+	}
+
+	util.ResponseMap(w, map[string]interface{}{
+		"message": "Collaborator removed successfully",
+	}, http.StatusOK)
+}
+
+func (h *CollaboratorHandler) parseID(w http.ResponseWriter, r *http.Request, name, message string) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)[name], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": message,
+		}, http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeError maps service errors to HTTP responses
+func (h *CollaboratorHandler) writeError(w http.ResponseWriter, fn string, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrAssessmentNotFound),
+		errors.Is(err, service.ErrCollaboratorNotFound),
+		errors.Is(err, service.ErrInviteeNotFound):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "NOT_FOUND",
+			"message": err.Error(),
+		}, http.StatusNotFound)
+	case errors.Is(err, service.ErrCollaboratorExists):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "CONFLICT",
+			"message": err.Error(),
+		}, http.StatusConflict)
+	case errors.Is(err, service.ErrInvalidCollaboratorRole),
+		errors.Is(err, service.ErrInviteeNotStaff),
+		errors.Is(err, service.ErrInviteeIsCreator):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": err.Error(),
+		}, http.StatusBadRequest)
+	default:
+		h.log.Error("["+fn+"] "+message, zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "ERROR",
+			"message": message,
+		}, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,172 @@
+package rest
+
+import (
+	"assessment_service/internal/assessments/service"
+	"assessment_service/internal/middleware"
+	models "assessment_service/internal/model"
+	"assessment_service/internal/util"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+// --- Mock CollaboratorService ---
+type MockCollaboratorService struct {
+	mock.Mock
+}
+
+func (m *MockCollaboratorService) ListCollaborators(assessmentID uint) ([]models.AssessmentCollaborator, error) {
+	args := m.Called(assessmentID)
+	collaborators, _ := args.Get(0).([]models.AssessmentCollaborator)
+	return collaborators, args.Error(1)
+}
+
+func (m *MockCollaboratorService) AddCollaborator(assessmentID uint, invite models.CollaboratorInviteDTO, invitedByID uint) (*models.AssessmentCollaborator, error) {
+	args := m.Called(assessmentID, invite, invitedByID)
+	collaborator, _ := args.Get(0).(*models.AssessmentCollaborator)
+	return collaborator, args.Error(1)
+}
+
+func (m *MockCollaboratorService) UpdateCollaboratorRole(assessmentID, userID uint, role string) (*models.AssessmentCollaborator, error) {
+	args := m.Called(assessmentID, userID, role)
+	collaborator, _ := args.Get(0).(*models.AssessmentCollaborator)
+	return collaborator, args.Error(1)
+}
+
+func (m *MockCollaboratorService) RemoveCollaborator(assessmentID, userID uint) error {
+	args := m.Called(assessmentID, userID)
+	return args.Error(0)
+}
+
+func serveCollaborators(handler *CollaboratorHandler, req *http.Request) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	router.HandleFunc("/assessments/{id}/collaborators", handler.ListCollaborators).Methods(http.MethodGet)
+	router.HandleFunc("/assessments/{id}/collaborators", handler.AddCollaborator).Methods(http.MethodPost)
+	router.HandleFunc("/assessments/{id}/collaborators/{userId}", handler.UpdateCollaborator).Methods(http.MethodPut)
+	router.HandleFunc("/assessments/{id}/collaborators/{userId}", handler.RemoveCollaborator).Methods(http.MethodDelete)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestCollaboratorHandler_ListCollaborators(t *testing.T) {
+	mockService := new(MockCollaboratorService)
+	handler := NewCollaboratorHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("ListCollaborators", uint(1)).Return([]models.AssessmentCollaborator{{ID: 1, AssessmentID: 1, UserID: 20, Role: "editor"}}, nil)
+
+	rr := serveCollaborators(handler, httptest.NewRequest(http.MethodGet, "/assessments/1/collaborators", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var result []models.AssessmentCollaborator
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+	assert.Len(t, result, 1)
+	mockService.AssertExpectations(t)
+}
+
+func TestCollaboratorHandler_AddCollaborator(t *testing.T) {
+	mockService := new(MockCollaboratorService)
+	handler := NewCollaboratorHandler(mockService, zaptest.NewLogger(t))
+
+	invite := models.CollaboratorInviteDTO{Email: "coauthor@example.com", Role: "grader"}
+	mockService.On("AddCollaborator", uint(1), invite, uint(10)).Return(&models.AssessmentCollaborator{ID: 3, AssessmentID: 1, UserID: 20, Role: "grader"}, nil)
+
+	body, _ := json.Marshal(invite)
+	req := createRequestWithClaims(http.MethodPost, "/assessments/1/collaborators", body, &middleware.Principal{UserID: 10, Role: "teacher"})
+	rr := serveCollaborators(handler, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCollaboratorHandler_AddCollaborator_Errors(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{"AssessmentNotFound", service.ErrAssessmentNotFound, http.StatusNotFound},
+		{"InviteeNotFound", service.ErrInviteeNotFound, http.StatusNotFound},
+		{"Exists", service.ErrCollaboratorExists, http.StatusConflict},
+		{"InvalidRole", service.ErrInvalidCollaboratorRole, http.StatusBadRequest},
+		{"NotStaff", service.ErrInviteeNotStaff, http.StatusBadRequest},
+		{"Unexpected", errors.New("db error"), http.StatusInternalServerError},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockCollaboratorService)
+			handler := NewCollaboratorHandler(mockService, zaptest.NewLogger(t))
+
+			mockService.On("AddCollaborator", uint(1), mock.Anything, uint(10)).Return(nil, tc.err)
+
+			req := createRequestWithClaims(http.MethodPost, "/assessments/1/collaborators", []byte(`{"userId":20,"role":"viewer"}`), &middleware.Principal{UserID: 10, Role: "teacher"})
+			rr := serveCollaborators(handler, req)
+
+			assert.Equal(t, tc.wantCode, rr.Code)
+		})
+	}
+}
+
+func TestCollaboratorHandler_AddCollaborator_InvalidInput(t *testing.T) {
+	mockService := new(MockCollaboratorService)
+	handler := NewCollaboratorHandler(mockService, zaptest.NewLogger(t))
+
+	req := createRequestWithClaims(http.MethodPost, "/assessments/1/collaborators", []byte(`{"userId":20}`), &middleware.Principal{UserID: 10, Role: "teacher"})
+	rr := serveCollaborators(handler, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertNotCalled(t, "AddCollaborator", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCollaboratorHandler_UpdateCollaborator(t *testing.T) {
+	mockService := new(MockCollaboratorService)
+	handler := NewCollaboratorHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("UpdateCollaboratorRole", uint(1), uint(20), "viewer").Return(&models.AssessmentCollaborator{AssessmentID: 1, UserID: 20, Role: "viewer"}, nil)
+
+	req := createRequestWithClaims(http.MethodPut, "/assessments/1/collaborators/20", []byte(`{"role":"viewer"}`), nil)
+	rr := serveCollaborators(handler, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCollaboratorHandler_RemoveCollaborator_NotFound(t *testing.T) {
+	mockService := new(MockCollaboratorService)
+	handler := NewCollaboratorHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("RemoveCollaborator", uint(1), uint(20)).Return(service.ErrCollaboratorNotFound)
+
+	rr := serveCollaborators(handler, httptest.NewRequest(http.MethodDelete, "/assessments/1/collaborators/20", nil))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAssessmentHandler_ListAssessments_TeacherSeesAccessibleOnly(t *testing.T) {
+	mockService := new(MockAssessmentService)
+	handler := NewAssessmentHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("List", mock.MatchedBy(func(p util.PaginationParams) bool {
+		return p.Filters["accessibleBy"] == uint(10)
+	})).Return([]models.Assessment{}, int64(0), nil)
+
+	req := createRequestWithClaims(http.MethodGet, "/assessments", nil, &middleware.Principal{UserID: 10, Role: "teacher"})
+	rr := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/assessments", handler.ListAssessments).Methods(http.MethodGet)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
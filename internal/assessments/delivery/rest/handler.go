@@ -1,11 +1,13 @@
 package rest
 
 import (
+	"assessment_service/internal/assessments/policy"
 	"assessment_service/internal/assessments/service"
+	"assessment_service/internal/middleware"
 	models "assessment_service/internal/model"
 	"assessment_service/internal/util"
 	"encoding/json"
-	"github.com/golang-jwt/jwt/v5"
+	"errors"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 	"net/http"
@@ -23,7 +25,7 @@ func NewAssessmentHandler(assessmentService service.AssessmentService, log *zap.
 }
 
 func (h *AssessmentHandler) CreateAssessment(w http.ResponseWriter, r *http.Request) {
-	claims, ok := r.Context().Value("user").(jwt.MapClaims)["userID"]
+	principal, ok := middleware.PrincipalFromRequest(r)
 	if !ok {
 		h.log.Error("[CreateAssessment] Failed to get user ID from token")
 		util.ResponseMap(w, map[string]interface{}{
@@ -33,26 +35,16 @@ func (h *AssessmentHandler) CreateAssessment(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// convert string to id
-	id, err := strconv.ParseUint(claims.(string), 10, 32)
-	if err != nil {
-		h.log.Error("[CreateAssessment] Failed to parse user ID", zap.Error(err))
-		util.ResponseMap(w, map[string]interface{}{
-			"status":  "UNAUTHORIZED",
-			"message": "Unauthorized",
-		}, http.StatusUnauthorized)
-		return
-	}
-
 	var req struct {
-		Title        string  `json:"title" binding:"required"`
-		Subject      string  `json:"subject" binding:"required"`
-		Description  string  `json:"description"`
-		Duration     int     `json:"duration" binding:"required"`
-		DueDate      string  `json:"dueDate"`
-		Id           uint    `json:"id"`
-		PassingScore float64 `json:"passingScore" binding:"required"`
-		Status       string  `json:"status"`
+		Title          string  `json:"title" binding:"required"`
+		Subject        string  `json:"subject" binding:"required"`
+		Description    string  `json:"description"`
+		Duration       int     `json:"duration" binding:"required"`
+		DueDate        string  `json:"dueDate"`
+		AvailableFrom  string  `json:"availableFrom"`
+		AvailableUntil string  `json:"availableUntil"`
+		Id             uint    `json:"id"`
+		PassingScore   float64 `json:"passingScore" binding:"required"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -69,9 +61,8 @@ func (h *AssessmentHandler) CreateAssessment(w http.ResponseWriter, r *http.Requ
 		Subject:      req.Subject,
 		Description:  req.Description,
 		Duration:     req.Duration,
-		CreatedByID:  uint(id),
+		CreatedByID:  principal.UserID,
 		PassingScore: req.PassingScore,
-		Status:       req.Status,
 	}
 
 	if req.DueDate != "" {
@@ -81,7 +72,38 @@ func (h *AssessmentHandler) CreateAssessment(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
-	err = h.assessmentService.Create(assessment)
+	if req.AvailableFrom != "" {
+		availableFrom, err := time.Parse(time.RFC3339, req.AvailableFrom)
+		if err != nil {
+			util.ResponseMap(w, map[string]interface{}{
+				"status":  "BAD_REQUEST",
+				"message": "availableFrom must be an RFC 3339 timestamp",
+			}, http.StatusBadRequest)
+			return
+		}
+		assessment.AvailableFrom = &availableFrom
+	}
+
+	if req.AvailableUntil != "" {
+		availableUntil, err := time.Parse(time.RFC3339, req.AvailableUntil)
+		if err != nil {
+			util.ResponseMap(w, map[string]interface{}{
+				"status":  "BAD_REQUEST",
+				"message": "availableUntil must be an RFC 3339 timestamp",
+			}, http.StatusBadRequest)
+			return
+		}
+		assessment.AvailableUntil = &availableUntil
+	}
+
+	err := h.assessmentService.Create(assessment)
+	if errors.Is(err, service.ErrInvalidSchedule) {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": err.Error(),
+		}, http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		util.ResponseMap(w, map[string]interface{}{
 			"status":  "ERROR",
@@ -129,13 +151,15 @@ func (h *AssessmentHandler) UpdateAssessment(w http.ResponseWriter, r *http.Requ
 	}
 
 	var req struct {
-		Title        string  `json:"title"`
-		Subject      string  `json:"subject"`
-		Description  string  `json:"description"`
-		Duration     float64 `json:"duration"`
-		DueDate      string  `json:"dueDate"`
-		PassingScore float64 `json:"passingScore"`
-		Status       string  `json:"status"`
+		Title          string  `json:"title"`
+		Subject        string  `json:"subject"`
+		Description    string  `json:"description"`
+		Duration       float64 `json:"duration"`
+		DueDate        string  `json:"dueDate"`
+		AvailableFrom  string  `json:"availableFrom"`
+		AvailableUntil string  `json:"availableUntil"`
+		PassingScore   float64 `json:"passingScore"`
+		Status         string  `json:"status"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -170,6 +194,14 @@ func (h *AssessmentHandler) UpdateAssessment(w http.ResponseWriter, r *http.Requ
 		assessmentData["dueDate"] = req.DueDate
 	}
 
+	if req.AvailableFrom != "" {
+		assessmentData["availableFrom"] = req.AvailableFrom
+	}
+
+	if req.AvailableUntil != "" {
+		assessmentData["availableUntil"] = req.AvailableUntil
+	}
+
 	if req.PassingScore != 0 {
 		assessmentData["passingScore"] = req.PassingScore
 	}
@@ -179,6 +211,20 @@ func (h *AssessmentHandler) UpdateAssessment(w http.ResponseWriter, r *http.Requ
 	}
 
 	assessment, err := h.assessmentService.Update(uint(id), assessmentData)
+	if errors.Is(err, service.ErrInvalidSchedule) {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": err.Error(),
+		}, http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, service.ErrInvalidTransition) {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "CONFLICT",
+			"message": err.Error(),
+		}, http.StatusConflict)
+		return
+	}
 	if err != nil {
 		h.log.Error("[UpdateAssessment] Failed to update assessment", zap.Error(err))
 		util.ResponseMap(w, map[string]interface{}{
@@ -231,6 +277,11 @@ func (h *AssessmentHandler) ListAssessments(w http.ResponseWriter, r *http.Reque
 		params.Filters["status"] = status
 	}
 
+	// Teachers only see assessments they created or collaborate on
+	if principal, ok := middleware.PrincipalFromRequest(r); ok && !principal.IsAdmin() {
+		params.Filters["accessibleBy"] = principal.UserID
+	}
+
 	// Get assessments with pagination
 	assessments, total, err := h.assessmentService.List(params)
 	if err != nil {
@@ -303,6 +354,14 @@ func (h *AssessmentHandler) UpdateSettings(w http.ResponseWriter, r *http.Reques
 	}
 
 	err = h.assessmentService.UpdateSettings(uint(id), &req)
+	if errors.Is(err, service.ErrInvalidLatePolicy) || errors.Is(err, service.ErrInvalidGradingSettings) ||
+		errors.Is(err, service.ErrInvalidResumePolicy) || errors.Is(err, service.ErrInvalidTimerSettings) {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": err.Error(),
+		}, http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		h.log.Error("[UpdateSettings] Failed to update assessment settings", zap.Error(err))
 		util.ResponseMap(w, map[string]interface{}{
@@ -338,7 +397,7 @@ func (h *AssessmentHandler) GetAssessmentResults(w http.ResponseWriter, r *http.
 	}
 
 	// Get assessment results with pagination
-	results, total, err := h.assessmentService.GetResults(uint(id), params)
+	results, total, err := h.assessmentService.GetResults(uint(id), params, !policy.Allowed(r, policy.ActionViewIdentities))
 	if err != nil {
 		h.log.Error("[GetAssessmentResults] Failed to fetch assessment results", zap.Error(err))
 		util.ResponseMap(w, map[string]interface{}{
@@ -354,31 +413,17 @@ func (h *AssessmentHandler) GetAssessmentResults(w http.ResponseWriter, r *http.
 	util.ResponseInterface(w, result, http.StatusOK)
 }
 
-func (h *AssessmentHandler) PublishAssessment(w http.ResponseWriter, r *http.Request) {
-	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
-	if err != nil {
-		h.log.Error("[PublishAssessment] Failed to parse assessment ID", zap.Error(err))
-		util.ResponseMap(w, map[string]interface{}{
-			"status":  "BAD_REQUEST",
-			"message": "Invalid assessment ID",
-		}, http.StatusBadRequest)
-		return
-	}
-
-	assessment, err := h.assessmentService.Publish(uint(id))
-	if err != nil {
-		h.log.Error("[PublishAssessment] Failed to publish assessment", zap.Error(err))
+func (h *AssessmentHandler) DuplicateAssessment(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		h.log.Error("[DuplicateAssessment] Failed to get user ID from token")
 		util.ResponseMap(w, map[string]interface{}{
-			"status":  "ERROR",
-			"message": "Failed to publish assessment",
-		}, http.StatusInternalServerError)
+			"status":  "UNAUTHORIZED",
+			"message": "Unauthorized",
+		}, http.StatusUnauthorized)
 		return
 	}
 
-	util.ResponseInterface(w, assessment, http.StatusOK)
-}
-
-func (h *AssessmentHandler) DuplicateAssessment(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
 	if err != nil {
 		h.log.Error("[DuplicateAssessment] Failed to parse assessment ID", zap.Error(err))
@@ -393,7 +438,6 @@ func (h *AssessmentHandler) DuplicateAssessment(w http.ResponseWriter, r *http.R
 		NewTitle      string `json:"newTitle"`
 		CopyQuestions bool   `json:"copyQuestions"`
 		CopySettings  bool   `json:"copySettings"`
-		SetAsDraft    bool   `json:"setAsDraft"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -405,7 +449,7 @@ func (h *AssessmentHandler) DuplicateAssessment(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	assessment, err := h.assessmentService.Duplicate(uint(id), req.NewTitle, req.CopyQuestions, req.CopySettings, req.SetAsDraft)
+	assessment, err := h.assessmentService.Duplicate(uint(id), principal.UserID, req.NewTitle, req.CopyQuestions, req.CopySettings)
 	if err != nil {
 		h.log.Error("[DuplicateAssessment] Failed to duplicate assessment", zap.Error(err))
 		util.ResponseMap(w, map[string]interface{}{
@@ -1,10 +1,11 @@
 package rest
 
 import (
+	"assessment_service/internal/assessments/service"
+	"assessment_service/internal/middleware"
 	models "assessment_service/internal/model"
 	"assessment_service/internal/util"
 	"bytes"
-	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,7 +15,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -103,8 +103,8 @@ func (m *MockAssessmentService) UpdateSettings(id uint, settings *models.Assessm
 	return args.Error(0)
 }
 
-func (m *MockAssessmentService) GetResults(id uint, params util.PaginationParams) ([]map[string]interface{}, int64, error) {
-	args := m.Called(id, params)
+func (m *MockAssessmentService) GetResults(id uint, params util.PaginationParams, hideIdentities bool) ([]map[string]interface{}, int64, error) {
+	args := m.Called(id, params, hideIdentities)
 	results, ok := args.Get(0).([]map[string]interface{})
 	if !ok && args.Get(0) != nil {
 		// Return empty slice if type assertion fails but value is not nil
@@ -121,20 +121,46 @@ func (m *MockAssessmentService) GetResults(id uint, params util.PaginationParams
 	return results, count, args.Error(2)
 }
 
-func (m *MockAssessmentService) Publish(id uint) (*models.Assessment, error) {
-	args := m.Called(id)
+func (m *MockAssessmentService) Publish(id, changedByID uint) (*models.Assessment, error) {
+	args := m.Called(id, changedByID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	assessment, ok := args.Get(0).(*models.Assessment)
-	if !ok && args.Get(0) != nil {
-		panic("Mock Publish returned non-nil value of incorrect type")
+	return args.Get(0).(*models.Assessment), args.Error(1)
+}
+
+func (m *MockAssessmentService) Close(id, changedByID uint, reason string) (*models.Assessment, error) {
+	args := m.Called(id, changedByID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
 	}
-	return assessment, args.Error(1)
+	return args.Get(0).(*models.Assessment), args.Error(1)
 }
 
-func (m *MockAssessmentService) Duplicate(id uint, newTitle string, copyQuestions, copySettings, setAsDraft bool) (*models.Assessment, error) {
-	args := m.Called(id, newTitle, copyQuestions, copySettings, setAsDraft)
+func (m *MockAssessmentService) Reopen(id, changedByID uint, reason string) (*models.Assessment, error) {
+	args := m.Called(id, changedByID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Assessment), args.Error(1)
+}
+
+func (m *MockAssessmentService) Archive(id, changedByID uint, reason string) (*models.Assessment, error) {
+	args := m.Called(id, changedByID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Assessment), args.Error(1)
+}
+
+func (m *MockAssessmentService) GetStatusHistory(id uint) ([]models.AssessmentStatusTransition, error) {
+	args := m.Called(id)
+	transitions, _ := args.Get(0).([]models.AssessmentStatusTransition)
+	return transitions, args.Error(1)
+}
+
+func (m *MockAssessmentService) Duplicate(id, actorID uint, newTitle string, copyQuestions, copySettings bool) (*models.Assessment, error) {
+	args := m.Called(id, actorID, newTitle, copyQuestions, copySettings)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -160,11 +186,16 @@ func (m *MockAssessmentService) GetAssessmentHasAttempt(userID uint, params util
 	return assessments, total, args.Error(2)
 }
 
-// Helper function to create a request with context containing JWT claims
-func createRequestWithClaims(method, url string, body []byte, claims jwt.MapClaims) *http.Request {
+func (m *MockAssessmentService) UpdateScheduledStatuses() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// Helper function to create a request with context containing the authenticated principal
+func createRequestWithClaims(method, url string, body []byte, principal *middleware.Principal) *http.Request {
 	req := httptest.NewRequest(method, url, bytes.NewBuffer(body))
-	if claims != nil {
-		ctx := context.WithValue(req.Context(), "user", claims)
+	if principal != nil {
+		ctx := middleware.WithPrincipal(req.Context(), principal)
 		req = req.WithContext(ctx)
 	}
 	return req
@@ -184,22 +215,20 @@ func TestAssessmentHandler_CreateAssessment(t *testing.T) {
 		"duration":     60,
 		"dueDate":      time.Now().Add(24 * time.Hour).Format(time.RFC3339),
 		"passingScore": 75.0, // Use float64 for JSON numbers
-		"status":       "Draft",
 	}
 	body, _ := json.Marshal(createReq)
 
 	mockService.On("Create", mock.MatchedBy(func(a *models.Assessment) bool {
 		return a.Title == createReq["title"] &&
 			a.Subject == createReq["subject"] &&
-			a.CreatedByID == uint(123) &&
-			a.Status == createReq["status"]
+			a.CreatedByID == uint(123)
 	})).Return(nil).Run(func(args mock.Arguments) {
 		assessment := args.Get(0).(*models.Assessment)
 		assessment.ID = 1
 	})
 
-	claims := jwt.MapClaims{"userID": "123"}
-	req := createRequestWithClaims(http.MethodPost, "/assessments", body, claims)
+	principal := &middleware.Principal{UserID: 123, Role: "teacher"}
+	req := createRequestWithClaims(http.MethodPost, "/assessments", body, principal)
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -223,8 +252,8 @@ func TestAssessmentHandler_CreateAssessment_InvalidInput(t *testing.T) {
 
 	invalidBody := []byte(`{"passingScore": "Missing Subject"}`)
 
-	claims := jwt.MapClaims{"userID": "123"}
-	req := createRequestWithClaims(http.MethodPost, "/assessments", invalidBody, claims)
+	principal := &middleware.Principal{UserID: 123, Role: "teacher"}
+	req := createRequestWithClaims(http.MethodPost, "/assessments", invalidBody, principal)
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -235,6 +264,31 @@ func TestAssessmentHandler_CreateAssessment_InvalidInput(t *testing.T) {
 	mockService.AssertNotCalled(t, "Create", mock.Anything)
 }
 
+func TestAssessmentHandler_CreateAssessment_InvalidSchedule(t *testing.T) {
+	mockService := new(MockAssessmentService)
+	logger := zaptest.NewLogger(t)
+	handler := NewAssessmentHandler(mockService, logger)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/assessments", handler.CreateAssessment).Methods(http.MethodPost)
+	principal := &middleware.Principal{UserID: 123, Role: "teacher"}
+
+	// Thời điểm mở không đúng định dạng RFC 3339
+	body := []byte(`{"title": "Midterm", "subject": "Math", "duration": 60, "availableFrom": "next monday"}`)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, createRequestWithClaims(http.MethodPost, "/assessments", body, principal))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertNotCalled(t, "Create", mock.Anything)
+
+	// Khoảng thời gian bị service từ chối
+	mockService.On("Create", mock.Anything).Return(fmt.Errorf("%w: availableUntil must be after availableFrom", service.ErrInvalidSchedule))
+	body = []byte(`{"title": "Midterm", "subject": "Math", "duration": 60, "availableFrom": "2025-06-02T09:00:00Z", "availableUntil": "2025-06-01T09:00:00Z"}`)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, createRequestWithClaims(http.MethodPost, "/assessments", body, principal))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
 func TestAssessmentHandler_CreateAssessment_ServiceError(t *testing.T) {
 	mockService := new(MockAssessmentService)
 	logger := zaptest.NewLogger(t)
@@ -250,8 +304,8 @@ func TestAssessmentHandler_CreateAssessment_ServiceError(t *testing.T) {
 
 	mockService.On("Create", mock.Anything).Return(errors.New("database error"))
 
-	claims := jwt.MapClaims{"userID": "123"}
-	req := createRequestWithClaims(http.MethodPost, "/assessments", body, claims)
+	principal := &middleware.Principal{UserID: 123, Role: "teacher"}
+	req := createRequestWithClaims(http.MethodPost, "/assessments", body, principal)
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -747,6 +801,27 @@ func TestAssessmentHandler_UpdateSettings_ServiceError(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestAssessmentHandler_UpdateSettings_InvalidLatePolicy(t *testing.T) {
+	mockService := new(MockAssessmentService)
+	logger := zaptest.NewLogger(t)
+	handler := NewAssessmentHandler(mockService, logger)
+
+	settingsReq := models.AssessmentSettings{LatePolicy: models.LatePolicyPenalty}
+	body, _ := json.Marshal(settingsReq)
+
+	mockService.On("UpdateSettings", uint(1), &settingsReq).Return(fmt.Errorf("%w: latePenalty must be greater than 0 and at most 100", service.ErrInvalidLatePolicy))
+
+	req := httptest.NewRequest(http.MethodPut, "/assessments/1/settings", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/assessments/{id:[0-9]+}/settings", handler.UpdateSettings).Methods(http.MethodPut)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
 func TestAssessmentHandler_GetAssessmentResults(t *testing.T) {
 	mockService := new(MockAssessmentService)
 	logger := zaptest.NewLogger(t)
@@ -767,7 +842,7 @@ func TestAssessmentHandler_GetAssessmentResults(t *testing.T) {
 		SortDir: "DESC",
 	}
 
-	mockService.On("GetResults", assessmentID, expectedParams).Return(expectedResults, expectedTotal, nil)
+	mockService.On("GetResults", assessmentID, expectedParams, true).Return(expectedResults, expectedTotal, nil)
 
 	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/assessments/%d/results?user=User1", assessmentID), nil)
 	rr := httptest.NewRecorder()
@@ -797,7 +872,7 @@ func TestAssessmentHandler_GetAssessmentResults_ServiceError(t *testing.T) {
 	assessmentID := uint(1)
 	expectedParams := util.PaginationParams{Page: 0, Limit: 10, Offset: 0, SortBy: "created_at", SortDir: "DESC", Filters: map[string]interface{}{}} // Default params
 
-	mockService.On("GetResults", assessmentID, expectedParams).Return(nil, int64(0), errors.New("results error"))
+	mockService.On("GetResults", assessmentID, expectedParams, true).Return(nil, int64(0), errors.New("results error"))
 
 	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/assessments/%d/results", assessmentID), nil)
 	rr := httptest.NewRecorder()
@@ -822,9 +897,10 @@ func TestAssessmentHandler_PublishAssessment(t *testing.T) {
 		Title:  "Published Assessment",
 	}
 
-	mockService.On("Publish", assessmentID).Return(publishedAssessment, nil)
+	mockService.On("Publish", assessmentID, uint(123)).Return(publishedAssessment, nil)
 
-	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/assessments/%d/publish", assessmentID), nil)
+	principal := &middleware.Principal{UserID: 123, Role: "teacher"}
+	req := createRequestWithClaims(http.MethodPost, fmt.Sprintf("/assessments/%d/publish", assessmentID), nil, principal)
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -849,9 +925,10 @@ func TestAssessmentHandler_PublishAssessment_NoQuestionsError(t *testing.T) {
 	assessmentID := uint(1)
 	noQuestionsError := errors.New("cannot publish assessment without questions")
 
-	mockService.On("Publish", assessmentID).Return(nil, noQuestionsError)
+	mockService.On("Publish", assessmentID, uint(123)).Return(nil, noQuestionsError)
 
-	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/assessments/%d/publish", assessmentID), nil)
+	principal := &middleware.Principal{UserID: 123, Role: "teacher"}
+	req := createRequestWithClaims(http.MethodPost, fmt.Sprintf("/assessments/%d/publish", assessmentID), nil, principal)
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -874,9 +951,10 @@ func TestAssessmentHandler_PublishAssessment_ServiceError(t *testing.T) {
 
 	assessmentID := uint(1)
 
-	mockService.On("Publish", assessmentID).Return(nil, errors.New("publish error"))
+	mockService.On("Publish", assessmentID, uint(123)).Return(nil, errors.New("publish error"))
 
-	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/assessments/%d/publish", assessmentID), nil)
+	principal := &middleware.Principal{UserID: 123, Role: "teacher"}
+	req := createRequestWithClaims(http.MethodPost, fmt.Sprintf("/assessments/%d/publish", assessmentID), nil, principal)
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -897,7 +975,6 @@ func TestAssessmentHandler_DuplicateAssessment(t *testing.T) {
 		"newTitle":      "Duplicated Title",
 		"copyQuestions": true,
 		"copySettings":  false,
-		"setAsDraft":    true,
 	}
 	body, _ := json.Marshal(duplicateReq)
 
@@ -907,9 +984,10 @@ func TestAssessmentHandler_DuplicateAssessment(t *testing.T) {
 		// ... other fields
 	}
 
-	mockService.On("Duplicate", originalID, "Duplicated Title", true, false, true).Return(duplicatedAssessment, nil)
+	// Bản sao thuộc về người gọi
+	mockService.On("Duplicate", originalID, uint(5), "Duplicated Title", true, false).Return(duplicatedAssessment, nil)
 
-	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/assessments/%d/duplicate", originalID), bytes.NewBuffer(body))
+	req := createRequestWithClaims(http.MethodPost, fmt.Sprintf("/assessments/%d/duplicate", originalID), body, &middleware.Principal{UserID: 5, Role: "teacher"})
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -934,7 +1012,7 @@ func TestAssessmentHandler_DuplicateAssessment_InvalidInput(t *testing.T) {
 	originalID := uint(1)
 	invalidBody := []byte(`{"copyQuestions": "not-a-boolean"}`)
 
-	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/assessments/%d/duplicate", originalID), bytes.NewBuffer(invalidBody))
+	req := createRequestWithClaims(http.MethodPost, fmt.Sprintf("/assessments/%d/duplicate", originalID), invalidBody, &middleware.Principal{UserID: 5, Role: "teacher"})
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -954,9 +1032,9 @@ func TestAssessmentHandler_DuplicateAssessment_ServiceError(t *testing.T) {
 	duplicateReq := map[string]interface{}{"newTitle": "Duplicated Title"}
 	body, _ := json.Marshal(duplicateReq)
 
-	mockService.On("Duplicate", originalID, "Duplicated Title", false, false, false).Return(nil, errors.New("duplicate error")) // Assuming defaults for bools if not provided
+	mockService.On("Duplicate", originalID, uint(5), "Duplicated Title", false, false).Return(nil, errors.New("duplicate error")) // Assuming defaults for bools if not provided
 
-	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/assessments/%d/duplicate", originalID), bytes.NewBuffer(body))
+	req := createRequestWithClaims(http.MethodPost, fmt.Sprintf("/assessments/%d/duplicate", originalID), body, &middleware.Principal{UserID: 5, Role: "teacher"})
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -0,0 +1,126 @@
+package rest
+
+import (
+	"assessment_service/internal/assessments/service"
+	"assessment_service/internal/middleware"
+	models "assessment_service/internal/model"
+	"assessment_service/internal/util"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// statusChange is the optional body of the publish, close, reopen and archive requests
+type statusChange struct {
+	Reason string `json:"reason"`
+}
+
+func (h *AssessmentHandler) PublishAssessment(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, "PublishAssessment", "Failed to publish assessment", func(id, userID uint, _ string) (*models.Assessment, error) {
+		return h.assessmentService.Publish(id, userID)
+	})
+}
+
+func (h *AssessmentHandler) CloseAssessment(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, "CloseAssessment", "Failed to close assessment", h.assessmentService.Close)
+}
+
+func (h *AssessmentHandler) ReopenAssessment(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, "ReopenAssessment", "Failed to reopen assessment", h.assessmentService.Reopen)
+}
+
+func (h *AssessmentHandler) ArchiveAssessment(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, "ArchiveAssessment", "Failed to archive assessment", h.assessmentService.Archive)
+}
+
+func (h *AssessmentHandler) GetStatusHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid assessment ID",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	transitions, err := h.assessmentService.GetStatusHistory(uint(id))
+	if err != nil {
+		h.writeStatusError(w, "GetStatusHistory", err, "Failed to get status history")
+		return
+	}
+
+	util.ResponseInterface(w, transitions, http.StatusOK)
+}
+
+// changeStatus parses the request shared by the status endpoints and applies change to it
+func (h *AssessmentHandler) changeStatus(w http.ResponseWriter, r *http.Request, fn, message string,
+	change func(id, changedByID uint, reason string) (*models.Assessment, error)) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid assessment ID",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "UNAUTHORIZED",
+			"message": "Unauthorized",
+		}, http.StatusUnauthorized)
+		return
+	}
+
+	// The body is optional
+	var req statusChange
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.log.Error("["+fn+"] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	assessment, err := change(uint(id), principal.UserID, req.Reason)
+	if err != nil {
+		h.writeStatusError(w, fn, err, message)
+		return
+	}
+
+	util.ResponseInterface(w, assessment, http.StatusOK)
+}
+
+// writeStatusError maps lifecycle errors to HTTP responses
+func (h *AssessmentHandler) writeStatusError(w http.ResponseWriter, fn string, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrAssessmentNotFound):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "NOT_FOUND",
+			"message": err.Error(),
+		}, http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidTransition), errors.Is(err, service.ErrNoQuestions):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "CONFLICT",
+			"message": err.Error(),
+		}, http.StatusConflict)
+	case errors.Is(err, service.ErrInvalidSchedule):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": err.Error(),
+		}, http.StatusBadRequest)
+	default:
+		h.log.Error("["+fn+"] "+message, zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "ERROR",
+			"message": message,
+		}, http.StatusInternalServerError)
+	}
+}
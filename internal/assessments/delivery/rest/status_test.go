@@ -0,0 +1,125 @@
+package rest
+
+import (
+	"assessment_service/internal/assessments/service"
+	"assessment_service/internal/middleware"
+	models "assessment_service/internal/model"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func serveStatus(handler *AssessmentHandler, method, url string, body []byte, principal *middleware.Principal) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	router.HandleFunc("/assessments/{id:[0-9]+}/close", handler.CloseAssessment).Methods(http.MethodPost)
+	router.HandleFunc("/assessments/{id:[0-9]+}/reopen", handler.ReopenAssessment).Methods(http.MethodPost)
+	router.HandleFunc("/assessments/{id:[0-9]+}/archive", handler.ArchiveAssessment).Methods(http.MethodPost)
+	router.HandleFunc("/assessments/{id:[0-9]+}/status-history", handler.GetStatusHistory).Methods(http.MethodGet)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, createRequestWithClaims(method, url, body, principal))
+	return rr
+}
+
+func TestAssessmentHandler_CloseAssessment(t *testing.T) {
+	mockService := new(MockAssessmentService)
+	handler := NewAssessmentHandler(mockService, zaptest.NewLogger(t))
+	principal := &middleware.Principal{UserID: 123, Role: "teacher"}
+
+	mockService.On("Close", uint(1), uint(123), "marking started").Return(&models.Assessment{ID: 1, Status: models.AssessmentClosed}, nil)
+
+	rr := serveStatus(handler, http.MethodPost, "/assessments/1/close", []byte(`{"reason": "marking started"}`), principal)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response models.Assessment
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, models.AssessmentClosed, response.Status)
+	mockService.AssertExpectations(t)
+}
+
+func TestAssessmentHandler_ReopenAssessment_WithoutBody(t *testing.T) {
+	mockService := new(MockAssessmentService)
+	handler := NewAssessmentHandler(mockService, zaptest.NewLogger(t))
+	principal := &middleware.Principal{UserID: 123, Role: "teacher"}
+
+	mockService.On("Reopen", uint(1), uint(123), "").Return(&models.Assessment{ID: 1, Status: models.AssessmentActive}, nil)
+
+	rr := serveStatus(handler, http.MethodPost, "/assessments/1/reopen", nil, principal)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAssessmentHandler_ArchiveAssessment_InvalidTransition(t *testing.T) {
+	mockService := new(MockAssessmentService)
+	handler := NewAssessmentHandler(mockService, zaptest.NewLogger(t))
+	principal := &middleware.Principal{UserID: 123, Role: "teacher"}
+
+	mockService.On("Archive", uint(1), uint(123), "").
+		Return(nil, fmt.Errorf("%w: a Active assessment cannot become Archived", service.ErrInvalidTransition))
+
+	rr := serveStatus(handler, http.MethodPost, "/assessments/1/archive", nil, principal)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAssessmentHandler_CloseAssessment_Errors(t *testing.T) {
+	principal := &middleware.Principal{UserID: 123, Role: "teacher"}
+
+	t.Run("not found", func(t *testing.T) {
+		mockService := new(MockAssessmentService)
+		handler := NewAssessmentHandler(mockService, zaptest.NewLogger(t))
+		mockService.On("Close", uint(9), uint(123), "").Return(nil, service.ErrAssessmentNotFound)
+
+		rr := serveStatus(handler, http.MethodPost, "/assessments/9/close", nil, principal)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		mockService := new(MockAssessmentService)
+		handler := NewAssessmentHandler(mockService, zaptest.NewLogger(t))
+
+		rr := serveStatus(handler, http.MethodPost, "/assessments/1/close", []byte(`{"reason":`), principal)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertNotCalled(t, "Close", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("no principal", func(t *testing.T) {
+		mockService := new(MockAssessmentService)
+		handler := NewAssessmentHandler(mockService, zaptest.NewLogger(t))
+
+		rr := serveStatus(handler, http.MethodPost, "/assessments/1/close", nil, nil)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestAssessmentHandler_GetStatusHistory(t *testing.T) {
+	mockService := new(MockAssessmentService)
+	handler := NewAssessmentHandler(mockService, zaptest.NewLogger(t))
+	principal := &middleware.Principal{UserID: 123, Role: "teacher"}
+
+	changedBy := uint(123)
+	history := []models.AssessmentStatusTransition{
+		{ID: 1, AssessmentID: 1, FromStatus: models.AssessmentDraft, ToStatus: models.AssessmentActive, ChangedByID: &changedBy},
+		{ID: 2, AssessmentID: 1, FromStatus: models.AssessmentActive, ToStatus: models.AssessmentClosed, Reason: "availability window ended"},
+	}
+	mockService.On("GetStatusHistory", uint(1)).Return(history, nil)
+
+	rr := serveStatus(handler, http.MethodGet, "/assessments/1/status-history", nil, principal)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response []models.AssessmentStatusTransition
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	require.Len(t, response, 2)
+	assert.Nil(t, response[1].ChangedByID)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,178 @@
+package policy
+
+import (
+	"assessment_service/internal/middleware"
+	"assessment_service/internal/util"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// Guard enforces an AssessmentPolicy on routes that carry the assessment ID in the path
+type Guard struct {
+	policy AssessmentPolicy
+	log    *zap.Logger
+}
+
+func NewGuard(policy AssessmentPolicy, log *zap.Logger) *Guard {
+	return &Guard{policy: policy, log: log}
+}
+
+// Assessment wraps next so it only runs when the caller may perform action on the assessment
+// whose ID is in the idVar path variable
+func (g *Guard) Assessment(action Action, idVar string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := middleware.PrincipalFromRequest(r)
+		if !ok {
+			util.ResponseMap(w, map[string]interface{}{
+				"status":  "UNAUTHORIZED",
+				"message": "Unauthorized",
+			}, http.StatusUnauthorized)
+			return
+		}
+
+		assessmentID, ok := g.parseID(w, r, idVar, "Invalid assessment ID")
+		if !ok {
+			return
+		}
+
+		if err := g.policy.Authorize(principal, assessmentID, action); err != nil {
+			g.writeError(w, err)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// allowedKey is the context key Check stores its decision for an action under
+type allowedKey Action
+
+// Check is like Assessment but lets the request through either way; it only records whether the
+// caller may perform action, for handlers that trim their response otherwise, see Allowed
+func (g *Guard) Check(action Action, idVar string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := middleware.PrincipalFromRequest(r)
+		if !ok {
+			util.ResponseMap(w, map[string]interface{}{
+				"status":  "UNAUTHORIZED",
+				"message": "Unauthorized",
+			}, http.StatusUnauthorized)
+			return
+		}
+
+		assessmentID, ok := g.parseID(w, r, idVar, "Invalid assessment ID")
+		if !ok {
+			return
+		}
+
+		err := g.policy.Authorize(principal, assessmentID, action)
+		if err != nil && !errors.Is(err, ErrForbidden) {
+			g.writeError(w, err)
+			return
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), allowedKey(action), err == nil)))
+	}
+}
+
+// Allowed reports whether Check let the caller perform action
+func Allowed(r *http.Request, action Action) bool {
+	allowed, _ := r.Context().Value(allowedKey(action)).(bool)
+	return allowed
+}
+
+// Question is like Assessment but also requires the question in questionVar to belong to the assessment
+func (g *Guard) Question(action Action, assessmentVar, questionVar string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := middleware.PrincipalFromRequest(r)
+		if !ok {
+			util.ResponseMap(w, map[string]interface{}{
+				"status":  "UNAUTHORIZED",
+				"message": "Unauthorized",
+			}, http.StatusUnauthorized)
+			return
+		}
+
+		assessmentID, ok := g.parseID(w, r, assessmentVar, "Invalid assessment ID")
+		if !ok {
+			return
+		}
+
+		questionID, ok := g.parseID(w, r, questionVar, "Invalid question ID")
+		if !ok {
+			return
+		}
+
+		if err := g.policy.AuthorizeQuestion(principal, assessmentID, questionID, action); err != nil {
+			g.writeError(w, err)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// Attempt wraps next so it only runs when the caller may perform action on the assessment the
+// attempt in attemptVar belongs to
+func (g *Guard) Attempt(action Action, attemptVar string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := middleware.PrincipalFromRequest(r)
+		if !ok {
+			util.ResponseMap(w, map[string]interface{}{
+				"status":  "UNAUTHORIZED",
+				"message": "Unauthorized",
+			}, http.StatusUnauthorized)
+			return
+		}
+
+		attemptID, ok := g.parseID(w, r, attemptVar, "Invalid attempt ID")
+		if !ok {
+			return
+		}
+
+		if err := g.policy.AuthorizeAttempt(principal, attemptID, action); err != nil {
+			g.writeError(w, err)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func (g *Guard) parseID(w http.ResponseWriter, r *http.Request, name, message string) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)[name], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": message,
+		}, http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (g *Guard) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrAssessmentNotFound), errors.Is(err, ErrQuestionNotFound), errors.Is(err, ErrAttemptNotFound):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "NOT_FOUND",
+			"message": err.Error(),
+		}, http.StatusNotFound)
+	case errors.Is(err, ErrForbidden):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "FORBIDDEN",
+			"message": err.Error(),
+		}, http.StatusForbidden)
+	default:
+		g.log.Error("[Guard] failed to authorize request", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "ERROR",
+			"message": "Failed to check access",
+		}, http.StatusInternalServerError)
+	}
+}
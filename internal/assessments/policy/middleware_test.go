@@ -0,0 +1,209 @@
+package policy
+
+import (
+	"assessment_service/internal/middleware"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+// --- Mock AssessmentPolicy ---
+type MockAssessmentPolicy struct {
+	mock.Mock
+}
+
+func (m *MockAssessmentPolicy) Authorize(principal *middleware.Principal, assessmentID uint, action Action) error {
+	args := m.Called(principal, assessmentID, action)
+	return args.Error(0)
+}
+
+func (m *MockAssessmentPolicy) AuthorizeQuestion(principal *middleware.Principal, assessmentID, questionID uint, action Action) error {
+	args := m.Called(principal, assessmentID, questionID, action)
+	return args.Error(0)
+}
+
+func (m *MockAssessmentPolicy) AuthorizeAttempt(principal *middleware.Principal, attemptID uint, action Action) error {
+	args := m.Called(principal, attemptID, action)
+	return args.Error(0)
+}
+
+func serveGuarded(t *testing.T, p AssessmentPolicy, principal *middleware.Principal, url string) (*httptest.ResponseRecorder, bool) {
+	guard := NewGuard(p, zaptest.NewLogger(t))
+	called := false
+	next := func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/assessments/{id}", guard.Assessment(ActionEdit, "id", next))
+	router.HandleFunc("/assessments/{assessmentId}/questions/{questionId}", guard.Question(ActionManageQuestions, "assessmentId", "questionId", next))
+	router.HandleFunc("/attempts/{attemptID}/grade", guard.Attempt(ActionGrade, "attemptID", next))
+
+	req := httptest.NewRequest(http.MethodPut, url, nil)
+	if principal != nil {
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	return rr, called
+}
+
+func TestGuard_Assessment(t *testing.T) {
+	principal := &middleware.Principal{UserID: 10, Role: "teacher"}
+
+	cases := []struct {
+		name       string
+		url        string
+		principal  *middleware.Principal
+		policyErr  error
+		expectCall bool
+		wantCode   int
+	}{
+		{"Allowed", "/assessments/5", principal, nil, true, http.StatusOK},
+		{"Forbidden", "/assessments/5", principal, ErrForbidden, true, http.StatusForbidden},
+		{"NotFound", "/assessments/5", principal, ErrAssessmentNotFound, true, http.StatusNotFound},
+		{"PolicyError", "/assessments/5", principal, errors.New("db down"), true, http.StatusInternalServerError},
+		{"NoPrincipal", "/assessments/5", nil, nil, false, http.StatusUnauthorized},
+		{"InvalidID", "/assessments/abc", principal, nil, false, http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := new(MockAssessmentPolicy)
+			if tc.expectCall {
+				p.On("Authorize", tc.principal, uint(5), ActionEdit).Return(tc.policyErr).Once()
+			}
+
+			rr, called := serveGuarded(t, p, tc.principal, tc.url)
+
+			assert.Equal(t, tc.wantCode, rr.Code)
+			assert.Equal(t, tc.wantCode == http.StatusOK, called)
+			p.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGuard_Question(t *testing.T) {
+	principal := &middleware.Principal{UserID: 10, Role: "teacher"}
+
+	t.Run("Allowed", func(t *testing.T) {
+		p := new(MockAssessmentPolicy)
+		p.On("AuthorizeQuestion", principal, uint(5), uint(7), ActionManageQuestions).Return(nil).Once()
+
+		rr, called := serveGuarded(t, p, principal, "/assessments/5/questions/7")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.True(t, called)
+	})
+
+	t.Run("QuestionOfAnotherAssessment", func(t *testing.T) {
+		p := new(MockAssessmentPolicy)
+		p.On("AuthorizeQuestion", principal, uint(5), uint(7), ActionManageQuestions).Return(ErrQuestionNotFound).Once()
+
+		rr, called := serveGuarded(t, p, principal, "/assessments/5/questions/7")
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.False(t, called)
+	})
+
+	t.Run("InvalidQuestionID", func(t *testing.T) {
+		p := new(MockAssessmentPolicy)
+
+		rr, called := serveGuarded(t, p, principal, "/assessments/5/questions/x")
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.False(t, called)
+		p.AssertNotCalled(t, "AuthorizeQuestion", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestGuard_Attempt(t *testing.T) {
+	principal := &middleware.Principal{UserID: 10, Role: "teacher"}
+
+	t.Run("Allowed", func(t *testing.T) {
+		p := new(MockAssessmentPolicy)
+		p.On("AuthorizeAttempt", principal, uint(9), ActionGrade).Return(nil).Once()
+
+		rr, called := serveGuarded(t, p, principal, "/attempts/9/grade")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.True(t, called)
+	})
+
+	t.Run("AttemptNotFound", func(t *testing.T) {
+		p := new(MockAssessmentPolicy)
+		p.On("AuthorizeAttempt", principal, uint(9), ActionGrade).Return(ErrAttemptNotFound).Once()
+
+		rr, called := serveGuarded(t, p, principal, "/attempts/9/grade")
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.False(t, called)
+	})
+}
+
+func TestGuard_Check(t *testing.T) {
+	principal := &middleware.Principal{UserID: 10, Role: "grader"}
+
+	serve := func(t *testing.T, p AssessmentPolicy, url string) (*httptest.ResponseRecorder, *bool) {
+		guard := NewGuard(p, zaptest.NewLogger(t))
+		var allowed *bool
+		next := func(w http.ResponseWriter, r *http.Request) {
+			ok := Allowed(r, ActionViewIdentities)
+			allowed = &ok
+			w.WriteHeader(http.StatusOK)
+		}
+
+		router := mux.NewRouter()
+		router.HandleFunc("/assessments/{id}/results", guard.Check(ActionViewIdentities, "id", next))
+
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		return rr, allowed
+	}
+
+	t.Run("Allowed", func(t *testing.T) {
+		p := new(MockAssessmentPolicy)
+		p.On("Authorize", principal, uint(5), ActionViewIdentities).Return(nil).Once()
+
+		rr, allowed := serve(t, p, "/assessments/5/results")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		if assert.NotNil(t, allowed) {
+			assert.True(t, *allowed)
+		}
+	})
+
+	t.Run("ForbiddenStillServed", func(t *testing.T) {
+		// Người chấm không được xem danh tính nhưng vẫn xem được kết quả
+		p := new(MockAssessmentPolicy)
+		p.On("Authorize", principal, uint(5), ActionViewIdentities).Return(ErrForbidden).Once()
+
+		rr, allowed := serve(t, p, "/assessments/5/results")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		if assert.NotNil(t, allowed) {
+			assert.False(t, *allowed)
+		}
+	})
+
+	t.Run("PolicyError", func(t *testing.T) {
+		p := new(MockAssessmentPolicy)
+		p.On("Authorize", principal, uint(5), ActionViewIdentities).Return(errors.New("db down")).Once()
+
+		rr, allowed := serve(t, p, "/assessments/5/results")
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Nil(t, allowed)
+	})
+}
@@ -0,0 +1,206 @@
+package policy
+
+import (
+	"assessment_service/internal/assessments/repository"
+	repository3 "assessment_service/internal/attempts/repository"
+	"assessment_service/internal/middleware"
+	models "assessment_service/internal/model"
+	repository2 "assessment_service/internal/questions/repository"
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrForbidden          = errors.New("you do not have access to this assessment")
+	ErrAssessmentNotFound = errors.New("assessment not found")
+	ErrQuestionNotFound   = errors.New("question not found")
+	ErrAttemptNotFound    = errors.New("attempt not found")
+)
+
+// Action is something a caller wants to do with an assessment
+type Action string
+
+const (
+	ActionView            Action = "view"
+	ActionEdit            Action = "edit"
+	ActionDelete          Action = "delete"
+	ActionPublish         Action = "publish"
+	ActionViewResults     Action = "view_results"
+	ActionManageQuestions Action = "manage_questions"
+	ActionGrade           Action = "grade"
+	ActionManageSharing   Action = "manage_sharing"
+	ActionAssign          Action = "assign"
+	// ActionViewIdentities is seeing who made the attempts of a blind graded assessment
+	ActionViewIdentities Action = "view_identities"
+)
+
+// Access levels a user can hold on an assessment. The creator is always an owner; other users get
+// one of these through the assessment_collaborators table.
+const (
+	ACCESS_NONE   = ""
+	ACCESS_OWNER  = "owner"
+	ACCESS_EDITOR = "editor"
+	ACCESS_GRADER = "grader"
+	ACCESS_VIEWER = "viewer"
+)
+
+// grants lists what each access level may do
+var grants = map[string]map[Action]bool{
+	ACCESS_OWNER: {
+		ActionView:            true,
+		ActionEdit:            true,
+		ActionDelete:          true,
+		ActionPublish:         true,
+		ActionViewResults:     true,
+		ActionManageQuestions: true,
+		ActionGrade:           true,
+		ActionManageSharing:   true,
+		ActionAssign:          true,
+		ActionViewIdentities:  true,
+	},
+	ACCESS_EDITOR: {
+		ActionView:            true,
+		ActionEdit:            true,
+		ActionViewResults:     true,
+		ActionManageQuestions: true,
+		ActionAssign:          true,
+		ActionViewIdentities:  true,
+	},
+	ACCESS_GRADER: {
+		ActionView:        true,
+		ActionViewResults: true,
+		ActionGrade:       true,
+	},
+	ACCESS_VIEWER: {
+		ActionView: true,
+	},
+}
+
+// IsCollaboratorRole reports whether role can be given to a collaborator
+func IsCollaboratorRole(role string) bool {
+	_, ok := grants[role]
+	return ok
+}
+
+// AssessmentPolicy decides whether a principal may act on a given assessment. Admins may do
+// anything; everyone else needs an access level on the assessment that grants the action.
+type AssessmentPolicy interface {
+	Authorize(principal *middleware.Principal, assessmentID uint, action Action) error
+	AuthorizeQuestion(principal *middleware.Principal, assessmentID, questionID uint, action Action) error
+	AuthorizeAttempt(principal *middleware.Principal, attemptID uint, action Action) error
+}
+
+type assessmentPolicy struct {
+	assessmentRepo   repository.AssessmentRepository
+	collaboratorRepo repository.CollaboratorRepository
+	questionRepo     repository2.QuestionRepository
+	attemptRepo      repository3.AttemptRepository
+	log              *zap.Logger
+}
+
+func NewAssessmentPolicy(
+	assessmentRepo repository.AssessmentRepository,
+	collaboratorRepo repository.CollaboratorRepository,
+	questionRepo repository2.QuestionRepository,
+	attemptRepo repository3.AttemptRepository,
+	log *zap.Logger,
+) AssessmentPolicy {
+	return &assessmentPolicy{
+		assessmentRepo:   assessmentRepo,
+		collaboratorRepo: collaboratorRepo,
+		questionRepo:     questionRepo,
+		attemptRepo:      attemptRepo,
+		log:              log,
+	}
+}
+
+func (p *assessmentPolicy) Authorize(principal *middleware.Principal, assessmentID uint, action Action) error {
+	if principal == nil {
+		return ErrForbidden
+	}
+
+	assessment, err := p.assessmentRepo.FindByID(assessmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAssessmentNotFound
+		}
+		p.log.Error("[Authorize] failed to load assessment", zap.Uint("assessmentID", assessmentID), zap.Error(err))
+		return err
+	}
+
+	if principal.IsAdmin() {
+		return nil
+	}
+
+	access, err := p.accessLevel(principal, assessment)
+	if err != nil {
+		return err
+	}
+
+	if !grants[access][action] {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// AuthorizeQuestion also checks that the question belongs to the assessment in the path, so access
+// to one assessment cannot be used to edit another assessment's questions
+func (p *assessmentPolicy) AuthorizeQuestion(principal *middleware.Principal, assessmentID, questionID uint, action Action) error {
+	if err := p.Authorize(principal, assessmentID, action); err != nil {
+		return err
+	}
+
+	question, err := p.questionRepo.FindByID(questionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrQuestionNotFound
+		}
+		p.log.Error("[AuthorizeQuestion] failed to load question", zap.Uint("questionID", questionID), zap.Error(err))
+		return err
+	}
+
+	if question.AssessmentID != assessmentID {
+		return ErrQuestionNotFound
+	}
+
+	return nil
+}
+
+// AuthorizeAttempt checks the action against the assessment the attempt was made on
+func (p *assessmentPolicy) AuthorizeAttempt(principal *middleware.Principal, attemptID uint, action Action) error {
+	if principal == nil {
+		return ErrForbidden
+	}
+
+	attempt, err := p.attemptRepo.FindByID(attemptID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAttemptNotFound
+		}
+		p.log.Error("[AuthorizeAttempt] failed to load attempt", zap.Uint("attemptID", attemptID), zap.Error(err))
+		return err
+	}
+
+	return p.Authorize(principal, attempt.AssessmentID, action)
+}
+
+func (p *assessmentPolicy) accessLevel(principal *middleware.Principal, assessment *models.Assessment) (string, error) {
+	if assessment.CreatedByID == principal.UserID {
+		return ACCESS_OWNER, nil
+	}
+
+	collaborator, err := p.collaboratorRepo.FindByAssessmentAndUser(assessment.ID, principal.UserID)
+	if err != nil {
+		p.log.Error("[Authorize] failed to load collaborator", zap.Uint("assessmentID", assessment.ID), zap.Error(err))
+		return ACCESS_NONE, err
+	}
+
+	if collaborator == nil {
+		return ACCESS_NONE, nil
+	}
+
+	return collaborator.Role, nil
+}
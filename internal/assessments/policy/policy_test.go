@@ -0,0 +1,743 @@
+package policy
+
+import (
+	repository_attempt "assessment_service/internal/attempts/repository"
+	"assessment_service/internal/middleware"
+	models "assessment_service/internal/model"
+	repository_question "assessment_service/internal/questions/repository"
+	"assessment_service/internal/util"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+// --- Mock QuestionRepository ---
+type MockQuestionRepository struct {
+	mock.Mock
+}
+
+func (m *MockQuestionRepository) Create(question *models.Question) error {
+	args := m.Called(question)
+	return args.Error(0)
+}
+func (m *MockQuestionRepository) FindByID(id uint) (*models.Question, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Question), args.Error(1)
+}
+func (m *MockQuestionRepository) FindByAssessmentID(assessmentID uint) ([]models.Question, error) {
+	args := m.Called(assessmentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Question), args.Error(1)
+}
+func (m *MockQuestionRepository) Update(question *models.Question) error {
+	args := m.Called(question)
+	return args.Error(0)
+}
+func (m *MockQuestionRepository) Delete(id uint) error { args := m.Called(id); return args.Error(0) }
+func (m *MockQuestionRepository) AddOption(option *models.QuestionOption) error {
+	args := m.Called(option)
+	return args.Error(0)
+}
+func (m *MockQuestionRepository) UpdateOption(option *models.QuestionOption) error {
+	args := m.Called(option)
+	return args.Error(0)
+}
+func (m *MockQuestionRepository) DeleteOption(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockQuestionRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return fn(nil)
+}
+
+func (m *MockQuestionRepository) WithTx(tx *gorm.DB) repository_question.QuestionRepository {
+	return m
+}
+
+// --- Mock AssessmentRepository ---
+type MockAssessmentRepository struct {
+	mock.Mock
+}
+
+// Implement AssessmentRepository interface for mock
+func (m *MockAssessmentRepository) Create(assessment *models.Assessment) error {
+	args := m.Called(assessment)
+	return args.Error(0)
+}
+
+func (m *MockAssessmentRepository) FindByID(id uint) (*models.Assessment, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	// Ensure the returned object is of the correct type
+	assessment, ok := args.Get(0).(*models.Assessment)
+	if !ok && args.Get(0) != nil {
+		// Handle cases where the mock might return something unexpected but not nil
+		panic("Mock FindByID returned non-nil value of incorrect type")
+	}
+	return assessment, args.Error(1)
+}
+
+func (m *MockAssessmentRepository) Update(assessment *models.Assessment) error {
+	args := m.Called(assessment)
+	return args.Error(0)
+}
+
+func (m *MockAssessmentRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockAssessmentRepository) List(params util.PaginationParams) ([]models.Assessment, int64, error) {
+	args := m.Called(params)
+	// Ensure correct type assertion for slice
+	assessments, ok := args.Get(0).([]models.Assessment)
+	if !ok && args.Get(0) != nil {
+		panic("Mock List returned non-nil value of incorrect type for assessments")
+	}
+	// Ensure correct type assertion for int64
+	count, ok := args.Get(1).(int64)
+	if !ok {
+		// Try converting from int if necessary, though int64 is expected
+		if intCount, okInt := args.Get(1).(int); okInt {
+			count = int64(intCount)
+		} else {
+			panic("Mock List returned value of incorrect type for count")
+		}
+	}
+	return assessments, count, args.Error(2)
+}
+
+func (m *MockAssessmentRepository) FindRecent(limit int) ([]models.Assessment, error) {
+	args := m.Called(limit)
+	assessments, ok := args.Get(0).([]models.Assessment)
+	if !ok && args.Get(0) != nil {
+		panic("Mock FindRecent returned non-nil value of incorrect type")
+	}
+	return assessments, args.Error(1)
+}
+
+func (m *MockAssessmentRepository) GetStatistics() (map[string]interface{}, error) {
+	args := m.Called()
+	stats, ok := args.Get(0).(map[string]interface{})
+	if !ok && args.Get(0) != nil {
+		panic("Mock GetStatistics returned non-nil value of incorrect type")
+	}
+	return stats, args.Error(1)
+}
+
+func (m *MockAssessmentRepository) UpdateSettings(id uint, settings *models.AssessmentSettings) error {
+	args := m.Called(id, settings)
+	return args.Error(0)
+}
+
+func (m *MockAssessmentRepository) GetResults(id uint, params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	args := m.Called(id, params)
+	results, ok := args.Get(0).([]map[string]interface{})
+	if !ok && args.Get(0) != nil {
+		panic("Mock GetResults returned non-nil value of incorrect type for results")
+	}
+	count, ok := args.Get(1).(int64)
+	if !ok {
+		if intCount, okInt := args.Get(1).(int); okInt {
+			count = int64(intCount)
+		} else {
+			panic("Mock GetResults returned value of incorrect type for count")
+		}
+	}
+	return results, count, args.Error(2)
+}
+
+func (m *MockAssessmentRepository) Duplicate(assessment *models.Assessment) error {
+	args := m.Called(assessment)
+	return args.Error(0)
+}
+
+func (m *MockAssessmentRepository) GetAssessmentHasAttemptByUser(params util.PaginationParams, userID uint) ([]models.Assessment, int64, error) {
+	args := m.Called(params, userID)
+	assessments, ok := args.Get(0).([]models.Assessment)
+	if !ok && args.Get(0) != nil {
+		panic("Mock GetAssessmentHasAttemptByUser returned non-nil value of incorrect type for assessments")
+	}
+	count, ok := args.Get(1).(int64)
+	if !ok {
+		if intCount, okInt := args.Get(1).(int); okInt {
+			count = int64(intCount)
+		} else {
+			panic("Mock GetAssessmentHasAttemptByUser returned value of incorrect type for count")
+		}
+	}
+	return assessments, count, args.Error(2)
+}
+
+func (m *MockAssessmentRepository) UpdateStatus(transition *models.AssessmentStatusTransition) (bool, error) {
+	args := m.Called(transition)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAssessmentRepository) ListStatusHistory(assessmentID uint) ([]models.AssessmentStatusTransition, error) {
+	args := m.Called(assessmentID)
+	transitions, _ := args.Get(0).([]models.AssessmentStatusTransition)
+	return transitions, args.Error(1)
+}
+
+func (m *MockAssessmentRepository) ActivateScheduled(now time.Time) (int64, error) {
+	args := m.Called(now)
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
+func (m *MockAssessmentRepository) CloseEnded(now time.Time) (int64, error) {
+	args := m.Called(now)
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
+// --- Mock CollaboratorRepository ---
+type MockCollaboratorRepository struct {
+	mock.Mock
+}
+
+func (m *MockCollaboratorRepository) Create(collaborator *models.AssessmentCollaborator) error {
+	args := m.Called(collaborator)
+	return args.Error(0)
+}
+
+func (m *MockCollaboratorRepository) FindByAssessmentAndUser(assessmentID, userID uint) (*models.AssessmentCollaborator, error) {
+	args := m.Called(assessmentID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AssessmentCollaborator), args.Error(1)
+}
+
+func (m *MockCollaboratorRepository) ListByAssessment(assessmentID uint) ([]models.AssessmentCollaborator, error) {
+	args := m.Called(assessmentID)
+	collaborators, _ := args.Get(0).([]models.AssessmentCollaborator)
+	return collaborators, args.Error(1)
+}
+
+func (m *MockCollaboratorRepository) UpdateRole(assessmentID, userID uint, role string) error {
+	args := m.Called(assessmentID, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockCollaboratorRepository) Delete(assessmentID, userID uint) (bool, error) {
+	args := m.Called(assessmentID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+// --- Mock AttemptRepository ---
+type MockAttemptRepository struct{ mock.Mock }
+
+func (m *MockAttemptRepository) Create(attempt *models.Attempt) error {
+	args := m.Called(attempt)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindByID(id uint) (*models.Attempt, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	attempt, ok := args.Get(0).(*models.Attempt)
+	if !ok && args.Get(0) != nil {
+		panic("Mock FindByID returned non-nil value of incorrect type")
+	}
+	return attempt, args.Error(1)
+}
+
+func (m *MockAttemptRepository) Update(attempt *models.Attempt) error {
+	args := m.Called(attempt)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) SaveAnswer(answer *models.Answer) error {
+	args := m.Called(answer)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) UpdateAnswer(answer *models.Answer) error {
+	args := m.Called(answer)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindAnswersByAttemptID(attemptID uint) ([]models.Answer, error) {
+	args := m.Called(attemptID)
+	answers, _ := args.Get(0).([]models.Answer)
+	return answers, args.Error(1)
+}
+
+func (m *MockAttemptRepository) SaveAnswerGrade(answer *models.Answer) error {
+	args := m.Called(answer)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindPendingManualAnswers(params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	args := m.Called(params)
+	results, _ := args.Get(0).([]map[string]interface{})
+	count, _ := args.Get(1).(int64)
+	return results, count, args.Error(2)
+}
+
+func (m *MockAttemptRepository) FindGraderIDs(assessmentID uint) ([]uint, error) {
+	args := m.Called(assessmentID)
+	ids, _ := args.Get(0).([]uint)
+	return ids, args.Error(1)
+}
+
+func (m *MockAttemptRepository) FindUnassignedManualAnswers(assessmentID uint) ([]models.Answer, error) {
+	args := m.Called(assessmentID)
+	answers, _ := args.Get(0).([]models.Answer)
+	return answers, args.Error(1)
+}
+
+func (m *MockAttemptRepository) CreateGradingAssignments(assignments []models.GradingAssignment) error {
+	args := m.Called(assignments)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindAssignmentsByAnswer(answerID uint) ([]models.GradingAssignment, error) {
+	args := m.Called(answerID)
+	assignments, _ := args.Get(0).([]models.GradingAssignment)
+	return assignments, args.Error(1)
+}
+
+func (m *MockAttemptRepository) UpdateGradingAssignment(assignment *models.GradingAssignment) error {
+	args := m.Called(assignment)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindAssignmentsByGrader(graderID uint, params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	args := m.Called(graderID, params)
+	results, _ := args.Get(0).([]map[string]interface{})
+	count, _ := args.Get(1).(int64)
+	return results, count, args.Error(2)
+}
+
+func (m *MockAttemptRepository) GetGradingProgress(assessmentID uint) (map[string]interface{}, error) {
+	args := m.Called(assessmentID)
+	progress, _ := args.Get(0).(map[string]interface{})
+	return progress, args.Error(1)
+}
+
+func (m *MockAttemptRepository) CreateGradeEvents(events []models.GradeEvent) error {
+	args := m.Called(events)
+	return args.Error(0)
+}
+func (m *MockAttemptRepository) SaveGrade(change models.GradeChange) error {
+	args := m.Called(change)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindGradeEventsByAttempt(attemptID uint) ([]models.GradeEvent, error) {
+	args := m.Called(attemptID)
+	events, _ := args.Get(0).([]models.GradeEvent)
+	return events, args.Error(1)
+}
+
+func (m *MockAttemptRepository) CreateRegradeRequest(request *models.RegradeRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindRegradeRequestByID(id uint) (*models.RegradeRequest, error) {
+	args := m.Called(id)
+	request, _ := args.Get(0).(*models.RegradeRequest)
+	return request, args.Error(1)
+}
+
+func (m *MockAttemptRepository) FindRegradeRequestsByAttempt(attemptID uint) ([]models.RegradeRequest, error) {
+	args := m.Called(attemptID)
+	requests, _ := args.Get(0).([]models.RegradeRequest)
+	return requests, args.Error(1)
+}
+
+func (m *MockAttemptRepository) FindRegradeRequests(params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	args := m.Called(params)
+	results, _ := args.Get(0).([]map[string]interface{})
+	count, _ := args.Get(1).(int64)
+	return results, count, args.Error(2)
+}
+
+func (m *MockAttemptRepository) UpdateRegradeRequest(request *models.RegradeRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindScoredAttempts(assessmentID uint) ([]models.Attempt, error) {
+	args := m.Called(assessmentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Attempt), args.Error(1)
+}
+
+func (m *MockAttemptRepository) SaveRescore(attempts []*models.Attempt, answers []*models.Answer, events []models.GradeEvent) error {
+	args := m.Called(attempts, answers, events)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindAnswerByAttemptAndQuestion(attemptID, questionID uint) (*models.Answer, error) {
+	args := m.Called(attemptID, questionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	answer, ok := args.Get(0).(*models.Answer)
+	if !ok && args.Get(0) != nil {
+		panic("Mock FindAnswerByAttemptAndQuestion returned non-nil value of incorrect type")
+	}
+	return answer, args.Error(1)
+}
+
+func (m *MockAttemptRepository) FindAvailableAssessments(userID uint, params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	args := m.Called(userID, params)
+	results, _ := args.Get(0).([]map[string]interface{})
+	count, _ := args.Get(1).(int64)
+	return results, count, args.Error(2)
+}
+
+func (m *MockAttemptRepository) HasCompletedAssessment(userID, assessmentID uint) (bool, error) {
+	args := m.Called(userID, assessmentID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAttemptRepository) CountAttemptsByUserAndAssessment(userID, assessmentID uint) (int, error) {
+	args := m.Called(userID, assessmentID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAttemptRepository) FindCompletedAttemptsByUserAndAssessment(userID, assessmentID uint) ([]map[string]interface{}, error) {
+	args := m.Called(userID, assessmentID)
+	results, _ := args.Get(0).([]map[string]interface{})
+	return results, args.Error(1)
+}
+
+func (m *MockAttemptRepository) GetAllAttemptByUserId(userID uint, params util.PaginationParams) ([]models.Attempt, int64, error) {
+	args := m.Called(userID, params)
+	attempts, _ := args.Get(0).([]models.Attempt)
+	count, _ := args.Get(1).(int64)
+	return attempts, count, args.Error(2)
+}
+
+func (m *MockAttemptRepository) ListAttemptByUserAndAssessmentID(userID uint, assessmentID uint, params util.PaginationParams) ([]models.Attempt, int64, error) {
+	args := m.Called(userID, assessmentID, params)
+	attempts, _ := args.Get(0).([]models.Attempt)
+	count, _ := args.Get(1).(int64)
+	return attempts, count, args.Error(2)
+}
+
+func (m *MockAttemptRepository) GetAssessmentCompletionRates() (map[string]interface{}, error) {
+	args := m.Called()
+	stats, _ := args.Get(0).(map[string]interface{})
+	return stats, args.Error(1)
+}
+
+func (m *MockAttemptRepository) GetScoreDistribution() (map[string]interface{}, error) {
+	args := m.Called()
+	stats, _ := args.Get(0).(map[string]interface{})
+	return stats, args.Error(1)
+}
+
+func (m *MockAttemptRepository) GetAverageTimeSpent() (map[string]interface{}, error) {
+	args := m.Called()
+	stats, _ := args.Get(0).(map[string]interface{})
+	return stats, args.Error(1)
+}
+
+func (m *MockAttemptRepository) GetMostChallengingAssessments(limit int) ([]map[string]interface{}, error) {
+	args := m.Called(limit)
+	results, _ := args.Get(0).([]map[string]interface{})
+	return results, args.Error(1)
+}
+
+func (m *MockAttemptRepository) GetMostSuccessfulAssessments(limit int) ([]map[string]interface{}, error) {
+	args := m.Called(limit)
+	results, _ := args.Get(0).([]map[string]interface{})
+	return results, args.Error(1)
+}
+
+func (m *MockAttemptRepository) GetPassRate() (float64, error) {
+	args := m.Called()
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockAttemptRepository) CountAll() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAttemptRepository) CountByPeriod(days int) (int64, error) {
+	args := m.Called(days)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAttemptRepository) SaveSuspiciousActivity(activity *models.SuspiciousActivity) error {
+	args := m.Called(activity)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) CountRecentSuspiciousActivity(hours int) (int64, error) {
+	args := m.Called(hours)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAttemptRepository) FindSuspiciousActivitiesByAttemptID(attemptID uint) ([]models.SuspiciousActivity, error) {
+	args := m.Called(attemptID)
+	activities, _ := args.Get(0).([]models.SuspiciousActivity)
+	return activities, args.Error(1)
+}
+
+func (m *MockAttemptRepository) ExpiredAttempt() ([]models.Attempt, error) {
+	args := m.Called()
+	attempts, _ := args.Get(0).([]models.Attempt)
+	return attempts, args.Error(1)
+}
+
+func (m *MockAttemptRepository) IsUserInAttempt(userID uint) (bool, error) {
+	args := m.Called(userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAttemptRepository) WithTx(tx *gorm.DB) repository_attempt.AttemptRepository {
+	return m
+}
+
+func (m *MockAttemptRepository) FindInProgressAttempt(userID, assessmentID uint) (*models.Attempt, error) {
+	args := m.Called(userID, assessmentID)
+	attempt, _ := args.Get(0).(*models.Attempt)
+	return attempt, args.Error(1)
+}
+
+func (m *MockAttemptRepository) SaveTimeEvent(attempt *models.Attempt, event *models.AttemptTimeEvent) error {
+	args := m.Called(attempt, event)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindTimeEventsByAttempt(attemptID uint) ([]models.AttemptTimeEvent, error) {
+	args := m.Called(attemptID)
+	events, _ := args.Get(0).([]models.AttemptTimeEvent)
+	return events, args.Error(1)
+}
+
+func (m *MockAttemptRepository) SaveHeartbeat(attemptID uint, at time.Time) error {
+	args := m.Called(attemptID, at)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindLiveAttempts(assessmentID uint) ([]models.Attempt, error) {
+	args := m.Called(assessmentID)
+	attempts, _ := args.Get(0).([]models.Attempt)
+	return attempts, args.Error(1)
+}
+
+func (m *MockAttemptRepository) FindSuspiciousActivitiesByAttempts(attemptIDs []uint) ([]models.SuspiciousActivity, error) {
+	args := m.Called(attemptIDs)
+	activities, _ := args.Get(0).([]models.SuspiciousActivity)
+	return activities, args.Error(1)
+}
+
+func (m *MockAttemptRepository) SaveProctorAction(attempt *models.Attempt) error {
+	args := m.Called(attempt)
+	return args.Error(0)
+}
+
+// Thêm các hàm mock còn thiếu nếu cần
+
+type testPolicyDeps struct {
+	assessmentRepo   *MockAssessmentRepository
+	collaboratorRepo *MockCollaboratorRepository
+	questionRepo     *MockQuestionRepository
+	attemptRepo      *MockAttemptRepository
+}
+
+func newTestPolicyWithDeps(t *testing.T) (AssessmentPolicy, testPolicyDeps) {
+	deps := testPolicyDeps{
+		assessmentRepo:   new(MockAssessmentRepository),
+		collaboratorRepo: new(MockCollaboratorRepository),
+		questionRepo:     new(MockQuestionRepository),
+		attemptRepo:      new(MockAttemptRepository),
+	}
+	// By default nobody besides the creator has access
+	deps.collaboratorRepo.On("FindByAssessmentAndUser", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+
+	p := NewAssessmentPolicy(deps.assessmentRepo, deps.collaboratorRepo, deps.questionRepo, deps.attemptRepo, zaptest.NewLogger(t))
+	return p, deps
+}
+
+func newTestPolicy(t *testing.T) (AssessmentPolicy, *MockAssessmentRepository, *MockQuestionRepository) {
+	p, deps := newTestPolicyWithDeps(t)
+	return p, deps.assessmentRepo, deps.questionRepo
+}
+
+var (
+	owner   = &middleware.Principal{UserID: 10, Role: "teacher"}
+	teacher = &middleware.Principal{UserID: 20, Role: "teacher"}
+	admin   = &middleware.Principal{UserID: 1, Role: "admin"}
+)
+
+func TestAuthorize_OwnerCanDoEverything(t *testing.T) {
+	p, assessmentRepo, _ := newTestPolicy(t)
+	assessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, CreatedByID: 10}, nil)
+
+	for _, action := range []Action{ActionView, ActionEdit, ActionDelete, ActionPublish, ActionViewResults, ActionManageQuestions} {
+		assert.NoError(t, p.Authorize(owner, 5, action), string(action))
+	}
+}
+
+func TestAuthorize_OtherTeacherForbidden(t *testing.T) {
+	p, assessmentRepo, _ := newTestPolicy(t)
+	assessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, CreatedByID: 10}, nil)
+
+	for _, action := range []Action{ActionEdit, ActionDelete, ActionPublish, ActionViewResults, ActionManageQuestions} {
+		assert.ErrorIs(t, p.Authorize(teacher, 5, action), ErrForbidden, string(action))
+	}
+}
+
+func TestAuthorize_AdminBypasses(t *testing.T) {
+	p, assessmentRepo, _ := newTestPolicy(t)
+	assessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, CreatedByID: 10}, nil)
+
+	assert.NoError(t, p.Authorize(admin, 5, ActionDelete))
+}
+
+func TestAuthorize_NotFound(t *testing.T) {
+	p, assessmentRepo, _ := newTestPolicy(t)
+	assessmentRepo.On("FindByID", uint(5)).Return(nil, gorm.ErrRecordNotFound)
+
+	assert.ErrorIs(t, p.Authorize(admin, 5, ActionView), ErrAssessmentNotFound)
+}
+
+func TestAuthorize_RepositoryError(t *testing.T) {
+	p, assessmentRepo, _ := newTestPolicy(t)
+	dbErr := errors.New("db down")
+	assessmentRepo.On("FindByID", uint(5)).Return(nil, dbErr)
+
+	assert.ErrorIs(t, p.Authorize(owner, 5, ActionEdit), dbErr)
+}
+
+func TestAuthorize_NilPrincipal(t *testing.T) {
+	p, assessmentRepo, _ := newTestPolicy(t)
+
+	assert.ErrorIs(t, p.Authorize(nil, 5, ActionEdit), ErrForbidden)
+	assessmentRepo.AssertNotCalled(t, "FindByID", mock.Anything)
+}
+
+func TestAuthorizeQuestion(t *testing.T) {
+	t.Run("QuestionBelongsToAssessment", func(t *testing.T) {
+		p, assessmentRepo, questionRepo := newTestPolicy(t)
+		assessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, CreatedByID: 10}, nil)
+		questionRepo.On("FindByID", uint(7)).Return(&models.Question{ID: 7, AssessmentID: 5}, nil)
+
+		assert.NoError(t, p.AuthorizeQuestion(owner, 5, 7, ActionManageQuestions))
+	})
+
+	t.Run("QuestionFromAnotherAssessment", func(t *testing.T) {
+		p, assessmentRepo, questionRepo := newTestPolicy(t)
+		assessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, CreatedByID: 10}, nil)
+		questionRepo.On("FindByID", uint(7)).Return(&models.Question{ID: 7, AssessmentID: 6}, nil)
+
+		assert.ErrorIs(t, p.AuthorizeQuestion(owner, 5, 7, ActionManageQuestions), ErrQuestionNotFound)
+	})
+
+	t.Run("QuestionMissing", func(t *testing.T) {
+		p, assessmentRepo, questionRepo := newTestPolicy(t)
+		assessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, CreatedByID: 10}, nil)
+		questionRepo.On("FindByID", uint(7)).Return(nil, gorm.ErrRecordNotFound)
+
+		assert.ErrorIs(t, p.AuthorizeQuestion(owner, 5, 7, ActionManageQuestions), ErrQuestionNotFound)
+	})
+
+	t.Run("NotOwnerSkipsQuestionLookup", func(t *testing.T) {
+		p, assessmentRepo, questionRepo := newTestPolicy(t)
+		assessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, CreatedByID: 10}, nil)
+
+		assert.ErrorIs(t, p.AuthorizeQuestion(teacher, 5, 7, ActionManageQuestions), ErrForbidden)
+		questionRepo.AssertNotCalled(t, "FindByID", mock.Anything)
+	})
+}
+
+func TestAuthorize_CollaboratorRoles(t *testing.T) {
+	allActions := []Action{ActionView, ActionEdit, ActionDelete, ActionPublish, ActionViewResults, ActionManageQuestions, ActionGrade, ActionManageSharing, ActionAssign, ActionViewIdentities}
+
+	cases := map[string][]Action{
+		ACCESS_OWNER:  allActions,
+		ACCESS_EDITOR: {ActionView, ActionEdit, ActionViewResults, ActionManageQuestions, ActionAssign, ActionViewIdentities},
+		ACCESS_GRADER: {ActionView, ActionViewResults, ActionGrade},
+		ACCESS_VIEWER: {ActionView},
+	}
+
+	for role, allowed := range cases {
+		t.Run(role, func(t *testing.T) {
+			assessmentRepo := new(MockAssessmentRepository)
+			collaboratorRepo := new(MockCollaboratorRepository)
+			p := NewAssessmentPolicy(assessmentRepo, collaboratorRepo, new(MockQuestionRepository), new(MockAttemptRepository), zaptest.NewLogger(t))
+
+			assessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, CreatedByID: 10}, nil)
+			collaboratorRepo.On("FindByAssessmentAndUser", uint(5), uint(20)).
+				Return(&models.AssessmentCollaborator{AssessmentID: 5, UserID: 20, Role: role}, nil)
+
+			isAllowed := map[Action]bool{}
+			for _, action := range allowed {
+				isAllowed[action] = true
+			}
+
+			for _, action := range allActions {
+				err := p.Authorize(teacher, 5, action)
+				if isAllowed[action] {
+					assert.NoError(t, err, string(action))
+				} else {
+					assert.ErrorIs(t, err, ErrForbidden, string(action))
+				}
+			}
+		})
+	}
+}
+
+func TestAuthorize_CollaboratorLookupError(t *testing.T) {
+	assessmentRepo := new(MockAssessmentRepository)
+	collaboratorRepo := new(MockCollaboratorRepository)
+	p := NewAssessmentPolicy(assessmentRepo, collaboratorRepo, new(MockQuestionRepository), new(MockAttemptRepository), zaptest.NewLogger(t))
+
+	dbErr := errors.New("db down")
+	assessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, CreatedByID: 10}, nil)
+	collaboratorRepo.On("FindByAssessmentAndUser", uint(5), uint(20)).Return(nil, dbErr)
+
+	assert.ErrorIs(t, p.Authorize(teacher, 5, ActionView), dbErr)
+}
+
+func TestAuthorizeAttempt(t *testing.T) {
+	t.Run("UsesAttemptAssessment", func(t *testing.T) {
+		p, deps := newTestPolicyWithDeps(t)
+		deps.attemptRepo.On("FindByID", uint(9)).Return(&models.Attempt{ID: 9, AssessmentID: 5}, nil)
+		deps.assessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, CreatedByID: 10}, nil)
+
+		assert.NoError(t, p.AuthorizeAttempt(owner, 9, ActionGrade))
+		assert.ErrorIs(t, p.AuthorizeAttempt(teacher, 9, ActionGrade), ErrForbidden)
+	})
+
+	t.Run("AttemptMissing", func(t *testing.T) {
+		p, deps := newTestPolicyWithDeps(t)
+		deps.attemptRepo.On("FindByID", uint(9)).Return(nil, fmt.Errorf("attempt with ID 9 not found: %w", gorm.ErrRecordNotFound))
+
+		assert.ErrorIs(t, p.AuthorizeAttempt(owner, 9, ActionGrade), ErrAttemptNotFound)
+	})
+}
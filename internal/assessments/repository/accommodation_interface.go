@@ -0,0 +1,20 @@
+package repository
+
+import (
+	models "assessment_service/internal/model"
+)
+
+type AccommodationRepository interface {
+	Create(accommodation *models.AssessmentAccommodation) error
+	// FindByID returns nil, nil when the assessment has no such accommodation
+	FindByID(assessmentID, accommodationID uint) (*models.AssessmentAccommodation, error)
+	// FindByTarget returns nil, nil when the group or user has no accommodation for the assessment
+	FindByTarget(assessmentID uint, groupID, userID *uint) (*models.AssessmentAccommodation, error)
+	ListByAssessment(assessmentID uint) ([]models.AssessmentAccommodation, error)
+	Update(accommodation *models.AssessmentAccommodation) error
+	// Delete reports whether an accommodation was removed
+	Delete(assessmentID, accommodationID uint) (bool, error)
+	// FindForUser returns the accommodations that apply to the user for the given assessments, either
+	// directly or through one of their groups
+	FindForUser(userID uint, assessmentIDs []uint) ([]models.AssessmentAccommodation, error)
+}
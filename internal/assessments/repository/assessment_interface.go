@@ -3,6 +3,7 @@ package repository
 import (
 	models "assessment_service/internal/model"
 	"assessment_service/internal/util"
+	"time"
 )
 
 type AssessmentRepository interface {
@@ -15,7 +16,16 @@ type AssessmentRepository interface {
 	GetStatistics() (map[string]interface{}, error)
 	UpdateSettings(id uint, settings *models.AssessmentSettings) error
 	GetResults(id uint, params util.PaginationParams) ([]map[string]interface{}, int64, error)
-	Publish(id uint) error
 	Duplicate(assessment *models.Assessment) error
 	GetAssessmentHasAttemptByUser(params util.PaginationParams, userID uint) ([]models.Assessment, int64, error)
+	// UpdateStatus moves an assessment from transition.FromStatus to transition.ToStatus and records the
+	// transition. It returns false when the assessment is no longer in FromStatus.
+	UpdateStatus(transition *models.AssessmentStatusTransition) (bool, error)
+	ListStatusHistory(assessmentID uint) ([]models.AssessmentStatusTransition, error)
+	// ActivateScheduled moves scheduled assessments whose window has opened to Active and returns how
+	// many were changed
+	ActivateScheduled(now time.Time) (int64, error)
+	// CloseEnded moves active assessments that no longer accept work from anyone to Closed and returns
+	// how many were changed
+	CloseEnded(now time.Time) (int64, error)
 }
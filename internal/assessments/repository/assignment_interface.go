@@ -0,0 +1,15 @@
+package repository
+
+import (
+	models "assessment_service/internal/model"
+)
+
+type AssignmentRepository interface {
+	// Create stores the assignments, skipping any the assessment already has
+	Create(assignments []models.AssessmentAssignment) error
+	ListByAssessment(assessmentID uint) ([]models.AssessmentAssignment, error)
+	// Delete reports whether an assignment was removed
+	Delete(assessmentID, assignmentID uint) (bool, error)
+	// IsAssigned reports whether the assessment is assigned to the user directly or through one of their groups
+	IsAssigned(assessmentID, userID uint) (bool, error)
+}
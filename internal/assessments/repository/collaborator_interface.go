@@ -0,0 +1,15 @@
+package repository
+
+import (
+	models "assessment_service/internal/model"
+)
+
+type CollaboratorRepository interface {
+	Create(collaborator *models.AssessmentCollaborator) error
+	// FindByAssessmentAndUser returns nil, nil when the user is not a collaborator
+	FindByAssessmentAndUser(assessmentID, userID uint) (*models.AssessmentCollaborator, error)
+	ListByAssessment(assessmentID uint) ([]models.AssessmentCollaborator, error)
+	UpdateRole(assessmentID, userID uint, role string) error
+	// Delete reports whether a collaborator was removed
+	Delete(assessmentID, userID uint) (bool, error)
+}
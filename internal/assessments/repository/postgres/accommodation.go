@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"assessment_service/internal/assessments/repository"
+	models "assessment_service/internal/model"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type accommodationRepository struct {
+	db *gorm.DB
+}
+
+func NewAccommodationRepository(db *gorm.DB) repository.AccommodationRepository {
+	return &accommodationRepository{db: db}
+}
+
+func (r *accommodationRepository) Create(accommodation *models.AssessmentAccommodation) error {
+	if err := r.db.Create(accommodation).Error; err != nil {
+		return fmt.Errorf("failed to create accommodation: %w", err)
+	}
+	return nil
+}
+
+func (r *accommodationRepository) FindByID(assessmentID, accommodationID uint) (*models.AssessmentAccommodation, error) {
+	var accommodation models.AssessmentAccommodation
+	err := r.db.Preload("Group").Preload("User").
+		Where("id = ? AND assessment_id = ?", accommodationID, assessmentID).
+		First(&accommodation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find accommodation: %w", err)
+	}
+	return &accommodation, nil
+}
+
+func (r *accommodationRepository) FindByTarget(assessmentID uint, groupID, userID *uint) (*models.AssessmentAccommodation, error) {
+	query := r.db.Where("assessment_id = ?", assessmentID)
+	if groupID != nil {
+		query = query.Where("group_id = ?", *groupID)
+	} else {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var accommodation models.AssessmentAccommodation
+	if err := query.First(&accommodation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find accommodation: %w", err)
+	}
+	return &accommodation, nil
+}
+
+func (r *accommodationRepository) ListByAssessment(assessmentID uint) ([]models.AssessmentAccommodation, error) {
+	var accommodations []models.AssessmentAccommodation
+	err := r.db.Preload("Group").Preload("User").
+		Where("assessment_id = ?", assessmentID).
+		Order("id ASC").
+		Find(&accommodations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accommodations: %w", err)
+	}
+	return accommodations, nil
+}
+
+func (r *accommodationRepository) Update(accommodation *models.AssessmentAccommodation) error {
+	err := r.db.Model(accommodation).
+		Select("TimeMultiplier", "ExtraMinutes", "ExtraAttempts", "DueDate", "Reason").
+		Updates(accommodation).Error
+	if err != nil {
+		return fmt.Errorf("failed to update accommodation: %w", err)
+	}
+	return nil
+}
+
+func (r *accommodationRepository) Delete(assessmentID, accommodationID uint) (bool, error) {
+	result := r.db.Where("id = ? AND assessment_id = ?", accommodationID, assessmentID).
+		Delete(&models.AssessmentAccommodation{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete accommodation: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *accommodationRepository) FindForUser(userID uint, assessmentIDs []uint) ([]models.AssessmentAccommodation, error) {
+	if len(assessmentIDs) == 0 {
+		return nil, nil
+	}
+
+	var accommodations []models.AssessmentAccommodation
+	err := r.db.Where("assessment_id IN ?", assessmentIDs).
+		Where("user_id = ? OR group_id IN (?)",
+			userID,
+			r.db.Model(&models.GroupMember{}).Select("group_id").Where("user_id = ?", userID),
+		).
+		Find(&accommodations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find accommodations for user: %w", err)
+	}
+	return accommodations, nil
+}
@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	models "assessment_service/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccommodationRepository_SQLite(t *testing.T) {
+	db := setupTestSQLiteDatabase(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	repo := NewAccommodationRepository(db)
+
+	teacher := models.User{Name: "Teacher", Email: "teacher@example.com", Password: "password", Role: "teacher", Status: "Active"}
+	inGroup := models.User{Name: "In Group", Email: "ingroup@example.com", Password: "password", Role: "student", Status: "Active"}
+	direct := models.User{Name: "Direct", Email: "direct@example.com", Password: "password", Role: "student", Status: "Active"}
+	require.NoError(t, db.Create(&teacher).Error)
+	require.NoError(t, db.Create(&inGroup).Error)
+	require.NoError(t, db.Create(&direct).Error)
+
+	assessment := models.Assessment{Title: "Final", Subject: "Math", Duration: 60, CreatedByID: teacher.ID}
+	other := models.Assessment{Title: "Quiz", Subject: "Math", Duration: 15, CreatedByID: teacher.ID}
+	require.NoError(t, db.Create(&assessment).Error)
+	require.NoError(t, db.Create(&other).Error)
+
+	class := models.Group{Name: "Extended time", CreatedByID: teacher.ID}
+	require.NoError(t, db.Create(&class).Error)
+	require.NoError(t, db.Create(&models.GroupMember{GroupID: class.ID, UserID: inGroup.ID}).Error)
+	require.NoError(t, db.Create(&models.GroupMember{GroupID: class.ID, UserID: direct.ID}).Error)
+
+	groupAccommodation := &models.AssessmentAccommodation{AssessmentID: assessment.ID, GroupID: &class.ID, TimeMultiplier: 1.5, CreatedByID: teacher.ID}
+	userAccommodation := &models.AssessmentAccommodation{AssessmentID: assessment.ID, UserID: &direct.ID, TimeMultiplier: 1, ExtraAttempts: 1, CreatedByID: teacher.ID}
+
+	t.Run("TestCreateAndList", func(t *testing.T) {
+		require.NoError(t, repo.Create(groupAccommodation))
+		require.NoError(t, repo.Create(userAccommodation))
+		require.NoError(t, repo.Create(&models.AssessmentAccommodation{AssessmentID: other.ID, UserID: &direct.ID, TimeMultiplier: 2}))
+
+		listed, err := repo.ListByAssessment(assessment.ID)
+		require.NoError(t, err)
+		require.Len(t, listed, 2)
+		require.NotNil(t, listed[0].Group)
+		assert.Equal(t, "Extended time", listed[0].Group.Name)
+		require.NotNil(t, listed[1].User)
+		assert.Equal(t, direct.Email, listed[1].User.Email)
+	})
+
+	t.Run("TestFindByTarget", func(t *testing.T) {
+		found, err := repo.FindByTarget(assessment.ID, nil, &direct.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, userAccommodation.ID, found.ID)
+
+		found, err = repo.FindByTarget(assessment.ID, &class.ID, nil)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, groupAccommodation.ID, found.ID)
+
+		found, err = repo.FindByTarget(assessment.ID, nil, &inGroup.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("TestFindForUser", func(t *testing.T) {
+		records, err := repo.FindForUser(direct.ID, []uint{assessment.ID})
+		require.NoError(t, err)
+		assert.Len(t, records, 2, "own accommodation and the group's")
+
+		records, err = repo.FindForUser(inGroup.ID, []uint{assessment.ID, other.ID})
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, groupAccommodation.ID, records[0].ID)
+
+		records, err = repo.FindForUser(teacher.ID, []uint{assessment.ID})
+		require.NoError(t, err)
+		assert.Empty(t, records)
+	})
+
+	t.Run("TestUpdate", func(t *testing.T) {
+		dueDate := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+		userAccommodation.ExtraMinutes = 20
+		userAccommodation.ExtraAttempts = 0
+		userAccommodation.DueDate = &dueDate
+		require.NoError(t, repo.Update(userAccommodation))
+
+		found, err := repo.FindByID(assessment.ID, userAccommodation.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, 20, found.ExtraMinutes)
+		assert.Equal(t, 0, found.ExtraAttempts)
+		require.NotNil(t, found.DueDate)
+		assert.True(t, dueDate.Equal(*found.DueDate))
+	})
+
+	t.Run("TestDelete", func(t *testing.T) {
+		removed, err := repo.Delete(other.ID, groupAccommodation.ID)
+		require.NoError(t, err)
+		assert.False(t, removed, "accommodation of another assessment")
+
+		removed, err = repo.Delete(assessment.ID, groupAccommodation.ID)
+		require.NoError(t, err)
+		assert.True(t, removed)
+
+		found, err := repo.FindByID(assessment.ID, groupAccommodation.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+}
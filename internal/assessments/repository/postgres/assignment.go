@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"assessment_service/internal/assessments/repository"
+	models "assessment_service/internal/model"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type assignmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAssignmentRepository(db *gorm.DB) repository.AssignmentRepository {
+	return &assignmentRepository{db: db}
+}
+
+func (r *assignmentRepository) Create(assignments []models.AssessmentAssignment) error {
+	if len(assignments) == 0 {
+		return nil
+	}
+
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&assignments).Error; err != nil {
+		return fmt.Errorf("failed to create assignments: %w", err)
+	}
+	return nil
+}
+
+func (r *assignmentRepository) ListByAssessment(assessmentID uint) ([]models.AssessmentAssignment, error) {
+	var assignments []models.AssessmentAssignment
+	err := r.db.Preload("Group").Preload("User").
+		Where("assessment_id = ?", assessmentID).
+		Order("id ASC").
+		Find(&assignments).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assignments: %w", err)
+	}
+	return assignments, nil
+}
+
+func (r *assignmentRepository) Delete(assessmentID, assignmentID uint) (bool, error) {
+	result := r.db.Where("id = ? AND assessment_id = ?", assignmentID, assessmentID).
+		Delete(&models.AssessmentAssignment{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete assignment: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *assignmentRepository) IsAssigned(assessmentID, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.AssessmentAssignment{}).
+		Where("assessment_id = ?", assessmentID).
+		Where(assignedToUser(r.db, userID)).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check assignment: %w", err)
+	}
+	return count > 0, nil
+}
+
+// assignedToUser matches assessment_assignments rows that target the user directly or one of the
+// groups they belong to
+func assignedToUser(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Where("assessment_assignments.user_id = ? OR assessment_assignments.group_id IN (?)",
+		userID,
+		db.Model(&models.GroupMember{}).Select("group_id").Where("user_id = ?", userID),
+	)
+}
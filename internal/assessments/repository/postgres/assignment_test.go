@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"testing"
+
+	models "assessment_service/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssignmentRepository_SQLite(t *testing.T) {
+	db := setupTestSQLiteDatabase(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	repo := NewAssignmentRepository(db)
+
+	teacher := models.User{Name: "Teacher", Email: "teacher@example.com", Password: "password", Role: "teacher", Status: "Active"}
+	inGroup := models.User{Name: "In Group", Email: "ingroup@example.com", Password: "password", Role: "student", Status: "Active"}
+	direct := models.User{Name: "Direct", Email: "direct@example.com", Password: "password", Role: "student", Status: "Active"}
+	outsider := models.User{Name: "Outsider", Email: "outsider@example.com", Password: "password", Role: "student", Status: "Active"}
+	require.NoError(t, db.Create(&teacher).Error)
+	require.NoError(t, db.Create(&inGroup).Error)
+	require.NoError(t, db.Create(&direct).Error)
+	require.NoError(t, db.Create(&outsider).Error)
+
+	assessment := models.Assessment{Title: "Midterm", Subject: "Math", Duration: 60, CreatedByID: teacher.ID}
+	require.NoError(t, db.Create(&assessment).Error)
+
+	class := models.Group{Name: "Class 10A", CreatedByID: teacher.ID}
+	require.NoError(t, db.Create(&class).Error)
+	require.NoError(t, db.Create(&models.GroupMember{GroupID: class.ID, UserID: inGroup.ID}).Error)
+
+	t.Run("TestCreate_SkipsDuplicates", func(t *testing.T) {
+		assignments := []models.AssessmentAssignment{
+			{AssessmentID: assessment.ID, GroupID: &class.ID, AssignedByID: teacher.ID},
+			{AssessmentID: assessment.ID, UserID: &direct.ID, AssignedByID: teacher.ID},
+		}
+		require.NoError(t, repo.Create(assignments))
+		require.NoError(t, repo.Create([]models.AssessmentAssignment{{AssessmentID: assessment.ID, GroupID: &class.ID}}))
+
+		listed, err := repo.ListByAssessment(assessment.ID)
+		require.NoError(t, err)
+		require.Len(t, listed, 2)
+		require.NotNil(t, listed[0].Group)
+		assert.Equal(t, "Class 10A", listed[0].Group.Name)
+		require.NotNil(t, listed[1].User)
+		assert.Equal(t, direct.Email, listed[1].User.Email)
+	})
+
+	t.Run("TestIsAssigned", func(t *testing.T) {
+		assigned, err := repo.IsAssigned(assessment.ID, inGroup.ID)
+		require.NoError(t, err)
+		assert.True(t, assigned, "group members see group assignments")
+
+		assigned, err = repo.IsAssigned(assessment.ID, direct.ID)
+		require.NoError(t, err)
+		assert.True(t, assigned, "direct assignments count")
+
+		assigned, err = repo.IsAssigned(assessment.ID, outsider.ID)
+		require.NoError(t, err)
+		assert.False(t, assigned)
+	})
+
+	t.Run("TestDelete", func(t *testing.T) {
+		listed, err := repo.ListByAssessment(assessment.ID)
+		require.NoError(t, err)
+
+		removed, err := repo.Delete(assessment.ID+1, listed[0].ID)
+		require.NoError(t, err)
+		assert.False(t, removed, "assignment of another assessment")
+
+		removed, err = repo.Delete(assessment.ID, listed[0].ID)
+		require.NoError(t, err)
+		assert.True(t, removed)
+
+		assigned, err := repo.IsAssigned(assessment.ID, inGroup.ID)
+		require.NoError(t, err)
+		assert.False(t, assigned)
+	})
+}
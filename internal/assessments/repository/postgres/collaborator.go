@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"assessment_service/internal/assessments/repository"
+	models "assessment_service/internal/model"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type collaboratorRepository struct {
+	db *gorm.DB
+}
+
+func NewCollaboratorRepository(db *gorm.DB) repository.CollaboratorRepository {
+	return &collaboratorRepository{db: db}
+}
+
+func (r *collaboratorRepository) Create(collaborator *models.AssessmentCollaborator) error {
+	if err := r.db.Create(collaborator).Error; err != nil {
+		return fmt.Errorf("failed to create collaborator: %w", err)
+	}
+	return nil
+}
+
+func (r *collaboratorRepository) FindByAssessmentAndUser(assessmentID, userID uint) (*models.AssessmentCollaborator, error) {
+	var collaborator models.AssessmentCollaborator
+	err := r.db.Where("assessment_id = ? AND user_id = ?", assessmentID, userID).First(&collaborator).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find collaborator: %w", err)
+	}
+	return &collaborator, nil
+}
+
+func (r *collaboratorRepository) ListByAssessment(assessmentID uint) ([]models.AssessmentCollaborator, error) {
+	var collaborators []models.AssessmentCollaborator
+	err := r.db.Preload("User").
+		Where("assessment_id = ?", assessmentID).
+		Order("created_at ASC").
+		Find(&collaborators).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collaborators: %w", err)
+	}
+	return collaborators, nil
+}
+
+func (r *collaboratorRepository) UpdateRole(assessmentID, userID uint, role string) error {
+	err := r.db.Model(&models.AssessmentCollaborator{}).
+		Where("assessment_id = ? AND user_id = ?", assessmentID, userID).
+		Update("role", role).Error
+	if err != nil {
+		return fmt.Errorf("failed to update collaborator role: %w", err)
+	}
+	return nil
+}
+
+func (r *collaboratorRepository) Delete(assessmentID, userID uint) (bool, error) {
+	result := r.db.Where("assessment_id = ? AND user_id = ?", assessmentID, userID).
+		Delete(&models.AssessmentCollaborator{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete collaborator: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
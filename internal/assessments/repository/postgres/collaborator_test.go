@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"testing"
+
+	models "assessment_service/internal/model"
+	"assessment_service/internal/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollaboratorRepository_SQLite(t *testing.T) {
+	db := setupTestSQLiteDatabase(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	repo := NewCollaboratorRepository(db)
+	assessmentRepo := NewAssessmentRepository(db)
+
+	author := models.User{Name: "Author", Email: "author@example.com", Password: "password", Role: "teacher", Status: "Active"}
+	coAuthor := models.User{Name: "Co Author", Email: "coauthor@example.com", Password: "password", Role: "teacher", Status: "Active"}
+	outsider := models.User{Name: "Outsider", Email: "outsider@example.com", Password: "password", Role: "teacher", Status: "Active"}
+	require.NoError(t, db.Create(&author).Error)
+	require.NoError(t, db.Create(&coAuthor).Error)
+	require.NoError(t, db.Create(&outsider).Error)
+
+	shared := models.Assessment{Title: "Shared Exam", Subject: "Math", Duration: 60, CreatedByID: author.ID}
+	private := models.Assessment{Title: "Private Exam", Subject: "Math", Duration: 60, CreatedByID: author.ID}
+	other := models.Assessment{Title: "Co Author Exam", Subject: "Physics", Duration: 30, CreatedByID: coAuthor.ID}
+	require.NoError(t, db.Create(&shared).Error)
+	require.NoError(t, db.Create(&private).Error)
+	require.NoError(t, db.Create(&other).Error)
+
+	t.Run("TestCreateAndFind", func(t *testing.T) {
+		collaborator := &models.AssessmentCollaborator{AssessmentID: shared.ID, UserID: coAuthor.ID, Role: "editor", InvitedByID: author.ID}
+		require.NoError(t, repo.Create(collaborator))
+		assert.NotZero(t, collaborator.ID)
+
+		found, err := repo.FindByAssessmentAndUser(shared.ID, coAuthor.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "editor", found.Role)
+	})
+
+	t.Run("TestFind_NotCollaborator", func(t *testing.T) {
+		found, err := repo.FindByAssessmentAndUser(shared.ID, outsider.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("TestCreate_Duplicate", func(t *testing.T) {
+		err := repo.Create(&models.AssessmentCollaborator{AssessmentID: shared.ID, UserID: coAuthor.ID, Role: "viewer"})
+		assert.Error(t, err)
+	})
+
+	t.Run("TestListByAssessment", func(t *testing.T) {
+		collaborators, err := repo.ListByAssessment(shared.ID)
+		require.NoError(t, err)
+		require.Len(t, collaborators, 1)
+		assert.Equal(t, coAuthor.Email, collaborators[0].User.Email)
+
+		collaborators, err = repo.ListByAssessment(private.ID)
+		require.NoError(t, err)
+		assert.Empty(t, collaborators)
+	})
+
+	t.Run("TestUpdateRole", func(t *testing.T) {
+		require.NoError(t, repo.UpdateRole(shared.ID, coAuthor.ID, "grader"))
+
+		found, err := repo.FindByAssessmentAndUser(shared.ID, coAuthor.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "grader", found.Role)
+	})
+
+	t.Run("TestList_AccessibleBy", func(t *testing.T) {
+		params := util.PaginationParams{Page: 1, Limit: 10, SortBy: "assessments.id", SortDir: "ASC", Filters: map[string]interface{}{"accessibleBy": coAuthor.ID}}
+
+		assessments, total, err := assessmentRepo.List(params)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total) // Own assessment and the shared one
+		require.Len(t, assessments, 2)
+		assert.Equal(t, shared.ID, assessments[0].ID)
+		assert.Equal(t, other.ID, assessments[1].ID)
+
+		params.Filters["accessibleBy"] = outsider.ID
+		_, total, err = assessmentRepo.List(params)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), total)
+	})
+
+	t.Run("TestDelete", func(t *testing.T) {
+		removed, err := repo.Delete(shared.ID, coAuthor.ID)
+		require.NoError(t, err)
+		assert.True(t, removed)
+
+		removed, err = repo.Delete(shared.ID, coAuthor.ID)
+		require.NoError(t, err)
+		assert.False(t, removed)
+	})
+}
@@ -5,8 +5,23 @@ import (
 	models "assessment_service/internal/model"
 	"assessment_service/internal/util"
 	"errors"
+	"fmt"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// closesAtSQL and acceptsUntilSQL mirror models.Assessment.ClosesAt and AcceptsUntil. They expect
+// assessment_settings to be joined; acceptsUntilSQL is NULL when work is accepted indefinitely.
+const (
+	closesAtSQL     = "COALESCE(assessments.available_until, assessments.due_date)"
+	acceptsUntilSQL = "CASE" +
+		" WHEN " + closesAtSQL + " IS NULL THEN NULL" +
+		" WHEN assessment_settings.late_policy = 'penalty' AND assessment_settings.late_cutoff IS NULL THEN NULL" +
+		" WHEN assessment_settings.late_policy IN ('penalty', 'cutoff') AND assessment_settings.late_cutoff > " + closesAtSQL +
+		" THEN assessment_settings.late_cutoff" +
+		" ELSE " + closesAtSQL + " END"
 )
 
 type assessmentRepository struct {
@@ -20,6 +35,9 @@ func (a assessmentRepository) Create(assessment *models.Assessment) error {
 func (a assessmentRepository) FindByID(id uint) (*models.Assessment, error) {
 	var assessment models.Assessment
 	err := a.db.Preload("Questions.Options").
+		Preload("Sections", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position, id")
+		}).
 		Preload("Settings").
 		Preload("CreatedBy").First(&assessment, id).Error
 	if err != nil {
@@ -44,6 +62,11 @@ func (a assessmentRepository) Delete(id uint) error {
 			return err
 		}
 
+		// delete sections
+		if err := tx.Where("assessment_id = ?", id).Delete(&models.AssessmentSection{}).Error; err != nil {
+			return err
+		}
+
 		// Delete the assessment
 		if err := tx.Delete(&models.Assessment{}, id).Error; err != nil {
 			return err
@@ -71,6 +94,15 @@ func (a assessmentRepository) List(params util.PaginationParams) ([]models.Asses
 		if val, ok := params.Filters["status"]; ok {
 			query = query.Where("status = ?", val)
 		}
+
+		// Restrict to assessments the user created or collaborates on
+		if val, ok := params.Filters["accessibleBy"]; ok {
+			query = query.Where(
+				"assessments.created_by_id = ? OR assessments.id IN (?)",
+				val,
+				a.db.Model(&models.AssessmentCollaborator{}).Select("assessment_id").Where("user_id = ?", val),
+			)
+		}
 	}
 
 	if err := query.Count(&count).Error; err != nil {
@@ -115,7 +147,7 @@ func (a assessmentRepository) FindRecent(limit int) ([]models.Assessment, error)
 }
 
 func (a assessmentRepository) GetStatistics() (map[string]interface{}, error) {
-	var totalAssessments, activeAssessments, draftAssessments, expiredAssessments int64
+	var totalAssessments, activeAssessments, draftAssessments, scheduledAssessments, closedAssessments, archivedAssessments int64
 	var totalAttempts int64
 	// var averageScore float64
 
@@ -124,15 +156,23 @@ func (a assessmentRepository) GetStatistics() (map[string]interface{}, error) {
 		return nil, err
 	}
 
-	if err := a.db.Model(&models.Assessment{}).Where("status = ?", "Active").Count(&activeAssessments).Error; err != nil {
+	if err := a.db.Model(&models.Assessment{}).Where("status = ?", models.AssessmentActive).Count(&activeAssessments).Error; err != nil {
 		return nil, err
 	}
 
-	if err := a.db.Model(&models.Assessment{}).Where("status = ?", "Draft").Count(&draftAssessments).Error; err != nil {
+	if err := a.db.Model(&models.Assessment{}).Where("status = ?", models.AssessmentDraft).Count(&draftAssessments).Error; err != nil {
 		return nil, err
 	}
 
-	if err := a.db.Model(&models.Assessment{}).Where("status = ?", "Expired").Count(&expiredAssessments).Error; err != nil {
+	if err := a.db.Model(&models.Assessment{}).Where("status = ?", models.AssessmentScheduled).Count(&scheduledAssessments).Error; err != nil {
+		return nil, err
+	}
+
+	if err := a.db.Model(&models.Assessment{}).Where("status = ?", models.AssessmentClosed).Count(&closedAssessments).Error; err != nil {
+		return nil, err
+	}
+
+	if err := a.db.Model(&models.Assessment{}).Where("status = ?", models.AssessmentArchived).Count(&archivedAssessments).Error; err != nil {
 		return nil, err
 	}
 
@@ -148,8 +188,8 @@ func (a assessmentRepository) GetStatistics() (map[string]interface{}, error) {
 	}
 
 	err := a.db.Model(&models.Attempt{}).
-		Select("AVG(score) as avg_score, SUM(CASE WHEN status = 'Passed' THEN 1 ELSE 0 END) * 100.0 / COUNT(*) as pass_rate").
-		Where("score IS NOT NULL").
+		Select("AVG(score) as avg_score, SUM(CASE WHEN passed THEN 1 ELSE 0 END) * 100.0 / COUNT(*) as pass_rate").
+		Where("status = ? AND score IS NOT NULL", models.AttemptGraded).
 		Scan(&scoreResult).Error
 
 	if err != nil {
@@ -174,14 +214,16 @@ func (a assessmentRepository) GetStatistics() (map[string]interface{}, error) {
 	}
 
 	return map[string]interface{}{
-		"totalAssessments":   totalAssessments,
-		"activeAssessments":  activeAssessments,
-		"draftAssessments":   draftAssessments,
-		"expiredAssessments": expiredAssessments,
-		"totalAttempts":      totalAttempts,
-		"passRate":           scoreResult.PassRate,
-		"averageScore":       scoreResult.AvgScore,
-		"bySubject":          subjectCounts,
+		"totalAssessments":     totalAssessments,
+		"activeAssessments":    activeAssessments,
+		"draftAssessments":     draftAssessments,
+		"scheduledAssessments": scheduledAssessments,
+		"closedAssessments":    closedAssessments,
+		"archivedAssessments":  archivedAssessments,
+		"totalAttempts":        totalAttempts,
+		"passRate":             scoreResult.PassRate,
+		"averageScore":         scoreResult.AvgScore,
+		"bySubject":            subjectCounts,
 	}, nil
 }
 
@@ -197,13 +239,22 @@ func (a assessmentRepository) UpdateSettings(id uint, settings *models.Assessmen
 	}
 
 	currentSettings.RandomizeQuestions = settings.RandomizeQuestions
+	currentSettings.ShuffleOptions = settings.ShuffleOptions
+	currentSettings.ResumePolicy = settings.ResumePolicy
 	currentSettings.ShowResults = settings.ShowResults
 	currentSettings.AllowRetake = settings.AllowRetake
 	currentSettings.MaxAttempts = settings.MaxAttempts
 	currentSettings.TimeLimitEnforced = settings.TimeLimitEnforced
+	currentSettings.GracePeriod = settings.GracePeriod
 	currentSettings.RequireWebcam = settings.RequireWebcam
 	currentSettings.PreventTabSwitching = settings.PreventTabSwitching
 	currentSettings.RequireIdentityVerification = settings.RequireIdentityVerification
+	currentSettings.LatePolicy = settings.LatePolicy
+	currentSettings.LatePenalty = settings.LatePenalty
+	currentSettings.LateCutoff = settings.LateCutoff
+	currentSettings.BlindGrading = settings.BlindGrading
+	currentSettings.DoubleMarking = settings.DoubleMarking
+	currentSettings.ReconciliationThreshold = settings.ReconciliationThreshold
 
 	return a.db.Save(&currentSettings).Error
 }
@@ -227,7 +278,9 @@ func (a assessmentRepository) GetResults(id uint, params util.PaginationParams)
 					attempts.score, 
 					attempts.duration, 
 					attempts.status,
-					attempts.submitted_at`).
+					attempts.passed,
+					attempts.submitted_at,
+					(SELECT COUNT(*) FROM attempts AS numbered WHERE numbered.assessment_id = attempts.assessment_id AND numbered.id <= attempts.id) AS candidate_number`).
 		Where("attempts.assessment_id = ? AND attempts.submitted_at IS NOT NULL", id)
 
 	// apply filter
@@ -258,22 +311,53 @@ func (a assessmentRepository) GetResults(id uint, params util.PaginationParams)
 	return result, total, nil
 }
 
-func (a assessmentRepository) Publish(id uint) error {
-	return a.db.Model(&models.Assessment{}).Where("id = ?", id).Update("status", "Active").Error
+func (a assessmentRepository) UpdateStatus(transition *models.AssessmentStatusTransition) (bool, error) {
+	changed := false
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Assessment{}).
+			Where("id = ? AND status = ?", transition.AssessmentID, transition.FromStatus).
+			Update("status", transition.ToStatus)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		changed = true
+		return tx.Create(transition).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to update assessment status: %w", err)
+	}
+	return changed, nil
+}
+
+func (a assessmentRepository) ListStatusHistory(assessmentID uint) ([]models.AssessmentStatusTransition, error) {
+	var transitions []models.AssessmentStatusTransition
+	if err := a.db.Preload("ChangedBy").
+		Where("assessment_id = ?", assessmentID).
+		Order("created_at, id").
+		Find(&transitions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list status history: %w", err)
+	}
+	return transitions, nil
 }
 
 func (a assessmentRepository) Duplicate(assessment *models.Assessment) error {
 	return a.db.Transaction(func(tx *gorm.DB) error {
 		// Create a copy of the assessment
 		assessmentCopy := models.Assessment{
-			Title:        assessment.Title,
-			Subject:      assessment.Subject,
-			Description:  assessment.Description,
-			Duration:     assessment.Duration,
-			Status:       assessment.Status,
-			DueDate:      assessment.DueDate,
-			CreatedByID:  assessment.CreatedByID,
-			PassingScore: assessment.PassingScore,
+			Title:          assessment.Title,
+			Subject:        assessment.Subject,
+			Description:    assessment.Description,
+			Duration:       assessment.Duration,
+			Status:         models.AssessmentDraft,
+			DueDate:        assessment.DueDate,
+			AvailableFrom:  assessment.AvailableFrom,
+			AvailableUntil: assessment.AvailableUntil,
+			CreatedByID:    assessment.CreatedByID,
+			PassingScore:   assessment.PassingScore,
 		}
 
 		if err := tx.Create(&assessmentCopy).Error; err != nil {
@@ -285,13 +369,22 @@ func (a assessmentRepository) Duplicate(assessment *models.Assessment) error {
 			settingsCopy := models.AssessmentSettings{
 				AssessmentID:                assessmentCopy.ID,
 				RandomizeQuestions:          assessment.Settings.RandomizeQuestions,
+				ShuffleOptions:              assessment.Settings.ShuffleOptions,
+				ResumePolicy:                assessment.Settings.ResumePolicy,
 				ShowResults:                 assessment.Settings.ShowResults,
 				AllowRetake:                 assessment.Settings.AllowRetake,
 				MaxAttempts:                 assessment.Settings.MaxAttempts,
 				TimeLimitEnforced:           assessment.Settings.TimeLimitEnforced,
+				GracePeriod:                 assessment.Settings.GracePeriod,
 				RequireWebcam:               assessment.Settings.RequireWebcam,
 				PreventTabSwitching:         assessment.Settings.PreventTabSwitching,
 				RequireIdentityVerification: assessment.Settings.RequireIdentityVerification,
+				LatePolicy:                  assessment.Settings.LatePolicy,
+				LatePenalty:                 assessment.Settings.LatePenalty,
+				LateCutoff:                  assessment.Settings.LateCutoff,
+				BlindGrading:                assessment.Settings.BlindGrading,
+				DoubleMarking:               assessment.Settings.DoubleMarking,
+				ReconciliationThreshold:     assessment.Settings.ReconciliationThreshold,
 			}
 
 			if err := tx.Create(&settingsCopy).Error; err != nil {
@@ -302,11 +395,20 @@ func (a assessmentRepository) Duplicate(assessment *models.Assessment) error {
 		// Copy the questions if needed
 		for _, question := range assessment.Questions {
 			questionCopy := models.Question{
-				AssessmentID:  assessmentCopy.ID,
-				Type:          question.Type,
-				Text:          question.Text,
-				CorrectAnswer: question.CorrectAnswer,
-				Points:        question.Points,
+				AssessmentID:   assessmentCopy.ID,
+				BankQuestionID: question.BankQuestionID,
+				BankVersion:    question.BankVersion,
+				Type:           question.Type,
+				Topic:          question.Topic,
+				Difficulty:     question.Difficulty,
+				Tags:           question.Tags,
+				Text:           question.Text,
+				CorrectAnswer:  question.CorrectAnswer,
+				Config:         question.Config,
+				Points:         question.Points,
+				PartialCredit:  question.PartialCredit,
+				Penalty:        question.Penalty,
+				AllowNegative:  question.AllowNegative,
 			}
 
 			if err := tx.Create(&questionCopy).Error; err != nil {
@@ -327,6 +429,22 @@ func (a assessmentRepository) Duplicate(assessment *models.Assessment) error {
 			}
 		}
 
+		// Copy the sections, which draw from the copied questions by topic, tag and difficulty
+		for _, section := range assessment.Sections {
+			sectionCopy := models.AssessmentSection{
+				AssessmentID: assessmentCopy.ID,
+				Title:        section.Title,
+				Position:     section.Position,
+				Topic:        section.Topic,
+				Tag:          section.Tag,
+				Draws:        section.Draws,
+			}
+
+			if err := tx.Create(&sectionCopy).Error; err != nil {
+				return err
+			}
+		}
+
 		return nil
 	})
 }
@@ -367,3 +485,81 @@ func (a assessmentRepository) GetAssessmentHasAttemptByUser(params util.Paginati
 func NewAssessmentRepository(db *gorm.DB) repository.AssessmentRepository {
 	return &assessmentRepository{db: db}
 }
+
+func (a assessmentRepository) ActivateScheduled(now time.Time) (int64, error) {
+	opened := a.db.Table("assessments").
+		Select("assessments.id").
+		Where("assessments.status = ? AND assessments.deleted_at IS NULL", models.AssessmentScheduled).
+		Where("assessments.available_from IS NULL OR assessments.available_from <= ?", now)
+
+	count, err := a.transitionAll(opened, models.AssessmentScheduled, models.AssessmentActive, "availability window opened")
+	if err != nil {
+		return 0, fmt.Errorf("failed to activate scheduled assessments: %w", err)
+	}
+	return count, nil
+}
+
+func (a assessmentRepository) CloseEnded(now time.Time) (int64, error) {
+	ended := a.db.Table("assessments").
+		Select("assessments.id").
+		Joins("LEFT JOIN assessment_settings ON assessment_settings.assessment_id = assessments.id").
+		Where("assessments.status = ? AND assessments.deleted_at IS NULL", models.AssessmentActive).
+		Where(closesAtSQL+" IS NOT NULL").
+		Where(acceptsUntilSQL+" < ?", now).
+		Where("NOT EXISTS (?)", a.extendedAccommodations(now))
+
+	count, err := a.transitionAll(ended, models.AssessmentActive, models.AssessmentClosed, "availability window ended")
+	if err != nil {
+		return 0, fmt.Errorf("failed to close ended assessments: %w", err)
+	}
+	return count, nil
+}
+
+// transitionAll moves every assessment selected by ids from one status to another and records a
+// transition without a user for each of them. Assessments whose status changed in the meantime are
+// left alone and get no transition.
+func (a assessmentRepository) transitionAll(ids *gorm.DB, from, to models.AssessmentStatus, reason string) (int64, error) {
+	var assessmentIDs []uint
+	if err := ids.Pluck("assessments.id", &assessmentIDs).Error; err != nil {
+		return 0, err
+	}
+	if len(assessmentIDs) == 0 {
+		return 0, nil
+	}
+
+	var updated []models.Assessment
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&updated).
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+			Where("id IN ? AND status = ?", assessmentIDs, from).
+			Update("status", to).Error
+		if err != nil {
+			return err
+		}
+		if len(updated) == 0 {
+			return nil
+		}
+
+		transitions := make([]models.AssessmentStatusTransition, 0, len(updated))
+		for _, assessment := range updated {
+			transitions = append(transitions, models.AssessmentStatusTransition{
+				AssessmentID: assessment.ID,
+				FromStatus:   from,
+				ToStatus:     to,
+				Reason:       reason,
+			})
+		}
+		return tx.Create(&transitions).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(updated)), nil
+}
+
+// extendedAccommodations matches accommodations that still give a student time on the assessment
+func (a assessmentRepository) extendedAccommodations(now time.Time) *gorm.DB {
+	return a.db.Model(&models.AssessmentAccommodation{}).
+		Select("1").
+		Where("assessment_accommodations.assessment_id = assessments.id AND assessment_accommodations.due_date >= ?", now)
+}
@@ -35,10 +35,18 @@ func setupTestSQLiteDatabase(t *testing.T) *gorm.DB {
 		&models.AssessmentSettings{},
 		&models.Question{},
 		&models.QuestionOption{},
+		&models.AssessmentSection{},
 		&models.Attempt{},
+		&models.AttemptQuestion{},
 		&models.Answer{},
 		&models.Activity{},
 		&models.SuspiciousActivity{},
+		&models.AssessmentCollaborator{},
+		&models.Group{},
+		&models.GroupMember{},
+		&models.AssessmentAssignment{},
+		&models.AssessmentAccommodation{},
+		&models.AssessmentStatusTransition{},
 	)
 	require.NoError(t, err, "Failed to run migrations on SQLite")
 
@@ -157,7 +165,7 @@ func TestAssessmentRepository_SQLite(t *testing.T) {
 		assert.NoError(t, err)
 		require.NotNil(t, updatedAssessment)
 		assert.Equal(t, "Updated SQLite Test Title", updatedAssessment.Title)
-		assert.Equal(t, "Active", updatedAssessment.Status)
+		assert.Equal(t, models.AssessmentActive, updatedAssessment.Status)
 		assert.Equal(t, "Updated Subject", updatedAssessment.Subject)
 	})
 
@@ -245,9 +253,10 @@ func TestAssessmentRepository_SQLite(t *testing.T) {
 		now := time.Now()
 		score1, score2, score3 := 80.0, 45.0, 60.0
 		duration1, duration2, duration3 := 30, 40, 55
-		attempt1 := models.Attempt{UserID: testUser2.ID, AssessmentID: assessmentForStatsID, StartedAt: now.Add(-1 * time.Hour), SubmittedAt: &now, Score: &score1, Duration: &duration1, Status: "Passed"}
-		attempt2 := models.Attempt{UserID: testUser3.ID, AssessmentID: assessmentForStatsID, StartedAt: now.Add(-2 * time.Hour), SubmittedAt: &now, Score: &score2, Duration: &duration2, Status: "Failed"}
-		attempt3 := models.Attempt{UserID: testUser2.ID, AssessmentID: assessmentForAttemptsID, StartedAt: now.Add(-3 * time.Hour), SubmittedAt: &now, Score: &score3, Duration: &duration3, Status: "Passed"}
+		passed, failed := true, false
+		attempt1 := models.Attempt{UserID: testUser2.ID, AssessmentID: assessmentForStatsID, StartedAt: now.Add(-1 * time.Hour), SubmittedAt: &now, Score: &score1, Duration: &duration1, Status: models.AttemptGraded, Passed: &passed}
+		attempt2 := models.Attempt{UserID: testUser3.ID, AssessmentID: assessmentForStatsID, StartedAt: now.Add(-2 * time.Hour), SubmittedAt: &now, Score: &score2, Duration: &duration2, Status: models.AttemptGraded, Passed: &failed}
+		attempt3 := models.Attempt{UserID: testUser2.ID, AssessmentID: assessmentForAttemptsID, StartedAt: now.Add(-3 * time.Hour), SubmittedAt: &now, Score: &score3, Duration: &duration3, Status: models.AttemptGraded, Passed: &passed}
 		// Xóa attempt cũ nếu cần (quan trọng khi không dùng cache=shared)
 		db.Where("assessment_id = ?", assessmentForStatsID).Delete(&models.Attempt{})
 		db.Where("assessment_id = ?", assessmentForAttemptsID).Delete(&models.Attempt{})
@@ -273,9 +282,9 @@ func TestAssessmentRepository_SQLite(t *testing.T) {
 		db.Model(&models.Assessment{}).Where("status = ?", "Draft").Count(&draftAssessmentsCount)
 		assert.Equal(t, draftAssessmentsCount, stats["draftAssessments"])
 
-		expiredAssessmentsCount := int64(0)
-		db.Model(&models.Assessment{}).Where("status = ?", "Expired").Count(&expiredAssessmentsCount) // Sẽ là 0 vì chưa tạo Expired
-		assert.Equal(t, expiredAssessmentsCount, stats["expiredAssessments"])
+		closedAssessmentsCount := int64(0)
+		db.Model(&models.Assessment{}).Where("status = ?", "Closed").Count(&closedAssessmentsCount) // Sẽ là 0 vì chưa tạo Closed
+		assert.Equal(t, closedAssessmentsCount, stats["closedAssessments"])
 
 		assert.Equal(t, int64(3), stats["totalAttempts"])
 
@@ -310,6 +319,8 @@ func TestAssessmentRepository_SQLite(t *testing.T) {
 			ShowResults:        true,
 			MaxAttempts:        5,
 			RequireWebcam:      true,
+			ShuffleOptions:     true,
+			GracePeriod:        90,
 		}
 		err = repo.UpdateSettings(assessmentForSettingsID, updatedSettings)
 		assert.NoError(t, err)
@@ -321,6 +332,8 @@ func TestAssessmentRepository_SQLite(t *testing.T) {
 		assert.True(t, loadedSettingsAfterUpdate.ShowResults)
 		assert.Equal(t, 5, loadedSettingsAfterUpdate.MaxAttempts)
 		assert.True(t, loadedSettingsAfterUpdate.RequireWebcam)
+		assert.True(t, loadedSettingsAfterUpdate.ShuffleOptions)
+		assert.Equal(t, 90, loadedSettingsAfterUpdate.GracePeriod)
 	})
 
 	t.Run("TestGetResults", func(t *testing.T) {
@@ -331,8 +344,8 @@ func TestAssessmentRepository_SQLite(t *testing.T) {
 		//scoreUser3 := 60.0
 		//durationUser2 := 40
 		//durationUser3 := 48
-		//attemptUser2 := models.Attempt{UserID: testUser2.ID, AssessmentID: assessmentForResultsID, StartedAt: submittedTime1.Add(-time.Duration(durationUser2) * time.Minute), SubmittedAt: &submittedTime1, Score: &scoreUser2, Duration: &durationUser2, Status: "Passed"}
-		//attemptUser3 := models.Attempt{UserID: testUser3.ID, AssessmentID: assessmentForResultsID, StartedAt: submittedTime2.Add(-time.Duration(durationUser3) * time.Minute), SubmittedAt: &submittedTime2, Score: &scoreUser3, Duration: &durationUser3, Status: "Failed"}
+		//attemptUser2 := models.Attempt{UserID: testUser2.ID, AssessmentID: assessmentForResultsID, StartedAt: submittedTime1.Add(-time.Duration(durationUser2) * time.Minute), SubmittedAt: &submittedTime1, Score: &scoreUser2, Duration: &durationUser2, Status: models.AttemptGraded}
+		//attemptUser3 := models.Attempt{UserID: testUser3.ID, AssessmentID: assessmentForResultsID, StartedAt: submittedTime2.Add(-time.Duration(durationUser3) * time.Minute), SubmittedAt: &submittedTime2, Score: &scoreUser3, Duration: &durationUser3, Status: models.AttemptGraded}
 		//// Xóa attempt cũ nếu cần
 		//db.Where("assessment_id = ?", assessmentForResultsID).Delete(&models.Attempt{})
 		//require.NoError(t, db.Create(&attemptUser2).Error)
@@ -386,14 +399,37 @@ func TestAssessmentRepository_SQLite(t *testing.T) {
 		//assert.Equal(t, testUser3.Name, resultsPage2[0]["user"]) // User 3 submit cũ hơn
 	})
 
-	t.Run("TestPublish", func(t *testing.T) {
-		err := repo.Publish(assessmentForPublishID)
+	t.Run("TestUpdateStatus", func(t *testing.T) {
+		changed, err := repo.UpdateStatus(&models.AssessmentStatusTransition{
+			AssessmentID: assessmentForPublishID,
+			FromStatus:   models.AssessmentDraft,
+			ToStatus:     models.AssessmentActive,
+			ChangedByID:  &testUser1.ID,
+		})
 		assert.NoError(t, err)
+		assert.True(t, changed)
 
 		publishedAssessment, err := repo.FindByID(assessmentForPublishID)
 		require.NoError(t, err)
 		require.NotNil(t, publishedAssessment)
-		assert.Equal(t, "Active", publishedAssessment.Status)
+		assert.Equal(t, models.AssessmentActive, publishedAssessment.Status)
+
+		// Trạng thái hiện tại không còn là Draft nên không đổi và không ghi lịch sử
+		changed, err = repo.UpdateStatus(&models.AssessmentStatusTransition{
+			AssessmentID: assessmentForPublishID,
+			FromStatus:   models.AssessmentDraft,
+			ToStatus:     models.AssessmentArchived,
+		})
+		assert.NoError(t, err)
+		assert.False(t, changed)
+
+		history, err := repo.ListStatusHistory(assessmentForPublishID)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, models.AssessmentDraft, history[0].FromStatus)
+		assert.Equal(t, models.AssessmentActive, history[0].ToStatus)
+		require.NotNil(t, history[0].ChangedBy)
+		assert.Equal(t, testUser1.Email, history[0].ChangedBy.Email)
 	})
 
 	t.Run("TestDuplicate", func(t *testing.T) {
@@ -520,3 +556,99 @@ func TestAssessmentRepository_SQLite(t *testing.T) {
 		//assert.True(t, errors.Is(errSetting, gorm.ErrRecordNotFound))
 	})
 }
+
+func TestAssessmentRepository_ScheduledStatuses(t *testing.T) {
+	db := setupTestSQLiteDatabase(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	repo := NewAssessmentRepository(db)
+
+	teacher := models.User{Name: "Scheduler", Email: "scheduler@example.com", Password: "password", Role: "teacher", Status: "Active"}
+	require.NoError(t, db.Create(&teacher).Error)
+
+	now := time.Now().UTC()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	create := func(title string, status models.AssessmentStatus, from, until *time.Time, settings models.AssessmentSettings) uint {
+		assessment := models.Assessment{Title: title, Subject: "Schedule", Duration: 30, Status: status, AvailableFrom: from, AvailableUntil: until, CreatedByID: teacher.ID}
+		require.NoError(t, db.Create(&assessment).Error)
+		settings.AssessmentID = assessment.ID
+		require.NoError(t, db.Create(&settings).Error)
+		return assessment.ID
+	}
+
+	ended := create("Ended", models.AssessmentActive, nil, &past, models.AssessmentSettings{LatePolicy: models.LatePolicyReject})
+	open := create("Open", models.AssessmentActive, nil, &future, models.AssessmentSettings{LatePolicy: models.LatePolicyReject})
+	acceptsLate := create("Accepts Late", models.AssessmentActive, nil, &past, models.AssessmentSettings{LatePolicy: models.LatePolicyPenalty, LatePenalty: 10})
+	cutoffPassed := create("Cutoff Passed", models.AssessmentActive, nil, &past, models.AssessmentSettings{LatePolicy: models.LatePolicyCutoff, LateCutoff: &past})
+	extended := create("Extended", models.AssessmentActive, nil, &past, models.AssessmentSettings{LatePolicy: models.LatePolicyReject})
+	opening := create("Opening", models.AssessmentScheduled, &past, &future, models.AssessmentSettings{LatePolicy: models.LatePolicyReject})
+	notYet := create("Not Yet", models.AssessmentScheduled, &future, nil, models.AssessmentSettings{LatePolicy: models.LatePolicyReject})
+	// Đã đóng thủ công thì scheduler không được mở lại
+	closedByHand := create("Closed By Hand", models.AssessmentClosed, nil, &future, models.AssessmentSettings{LatePolicy: models.LatePolicyReject})
+
+	student := models.User{Name: "Extended Student", Email: "extended@example.com", Password: "password", Role: "student", Status: "Active"}
+	require.NoError(t, db.Create(&student).Error)
+	require.NoError(t, db.Create(&models.AssessmentAccommodation{AssessmentID: extended, UserID: &student.ID, TimeMultiplier: 1, DueDate: &future}).Error)
+
+	statusOf := func(id uint) models.AssessmentStatus {
+		var assessment models.Assessment
+		require.NoError(t, db.First(&assessment, id).Error)
+		return assessment.Status
+	}
+
+	t.Run("TestActivateScheduled", func(t *testing.T) {
+		activated, err := repo.ActivateScheduled(now)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), activated)
+
+		assert.Equal(t, models.AssessmentActive, statusOf(opening))
+		assert.Equal(t, models.AssessmentScheduled, statusOf(notYet))
+
+		history, err := repo.ListStatusHistory(opening)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Nil(t, history[0].ChangedByID)
+		assert.Equal(t, models.AssessmentScheduled, history[0].FromStatus)
+	})
+
+	t.Run("TestCloseEnded", func(t *testing.T) {
+		closed, err := repo.CloseEnded(now)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), closed)
+
+		assert.Equal(t, models.AssessmentClosed, statusOf(ended))
+		assert.Equal(t, models.AssessmentClosed, statusOf(cutoffPassed))
+		assert.Equal(t, models.AssessmentActive, statusOf(open))
+		assert.Equal(t, models.AssessmentActive, statusOf(acceptsLate))
+		assert.Equal(t, models.AssessmentActive, statusOf(extended))
+		assert.Equal(t, models.AssessmentActive, statusOf(opening))
+		assert.Equal(t, models.AssessmentClosed, statusOf(closedByHand))
+
+		history, err := repo.ListStatusHistory(ended)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, models.AssessmentClosed, history[0].ToStatus)
+	})
+
+	t.Run("TestTransitionAll_StatusChangedMeanwhile", func(t *testing.T) {
+		// Bài "Open" được chọn nhưng đã bị đóng trước khi cập nhật: chỉ "Extended" được chuyển
+		selected := db.Table("assessments").Select("assessments.id").Where("assessments.id IN ?", []uint{open, extended})
+		require.NoError(t, db.Model(&models.Assessment{}).Where("id = ?", open).Update("status", models.AssessmentClosed).Error)
+
+		count, err := repo.(*assessmentRepository).transitionAll(selected, models.AssessmentActive, models.AssessmentClosed, "test")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+		assert.Equal(t, models.AssessmentClosed, statusOf(extended))
+
+		history, err := repo.ListStatusHistory(open)
+		require.NoError(t, err)
+		assert.Empty(t, history)
+		history, err = repo.ListStatusHistory(extended)
+		require.NoError(t, err)
+		assert.Len(t, history, 1)
+	})
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"assessment_service/internal/assessments/repository"
+	repository3 "assessment_service/internal/groups/repository"
+	models "assessment_service/internal/model"
+	repository2 "assessment_service/internal/users/repository"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// MaxTimeMultiplier caps the time multiplier so a typo such as 150 instead of 1.5 is rejected
+const MaxTimeMultiplier = 5.0
+
+var (
+	ErrAccommodationNotFound       = errors.New("accommodation not found")
+	ErrAccommodationExists         = errors.New("the group or student already has an accommodation for this assessment")
+	ErrAccommodationTargetRequired = errors.New("exactly one of group ID and user ID is required")
+	ErrAccommodationTargetNotFound = errors.New("group or user for the accommodation not found")
+	ErrAccommodationNotStudent     = errors.New("accommodations can only be given to students")
+	ErrInvalidAccommodation        = errors.New("invalid accommodation")
+)
+
+type AccommodationService interface {
+	ListAccommodations(assessmentID uint) ([]models.AssessmentAccommodation, error)
+	CreateAccommodation(assessmentID uint, accommodation models.AccommodationDTO, createdByID uint) (*models.AssessmentAccommodation, error)
+	UpdateAccommodation(assessmentID, accommodationID uint, accommodation models.AccommodationDTO) (*models.AssessmentAccommodation, error)
+	DeleteAccommodation(assessmentID, accommodationID uint) error
+}
+
+type accommodationService struct {
+	assessmentRepo    repository.AssessmentRepository
+	accommodationRepo repository.AccommodationRepository
+	groupRepo         repository3.GroupRepository
+	userRepo          repository2.UserRepository
+	log               *zap.Logger
+}
+
+func NewAccommodationService(
+	assessmentRepo repository.AssessmentRepository,
+	accommodationRepo repository.AccommodationRepository,
+	groupRepo repository3.GroupRepository,
+	userRepo repository2.UserRepository,
+	log *zap.Logger,
+) AccommodationService {
+	return &accommodationService{
+		assessmentRepo:    assessmentRepo,
+		accommodationRepo: accommodationRepo,
+		groupRepo:         groupRepo,
+		userRepo:          userRepo,
+		log:               log,
+	}
+}
+
+func (s *accommodationService) ListAccommodations(assessmentID uint) ([]models.AssessmentAccommodation, error) {
+	if err := s.ensureAssessment(assessmentID); err != nil {
+		return nil, err
+	}
+
+	accommodations, err := s.accommodationRepo.ListByAssessment(assessmentID)
+	if err != nil {
+		s.log.Error("[ListAccommodations] failed to list accommodations", zap.Error(err))
+		return nil, err
+	}
+
+	return accommodations, nil
+}
+
+func (s *accommodationService) CreateAccommodation(assessmentID uint, dto models.AccommodationDTO, createdByID uint) (*models.AssessmentAccommodation, error) {
+	if (dto.GroupID == nil) == (dto.UserID == nil) {
+		return nil, ErrAccommodationTargetRequired
+	}
+
+	if err := validateAccommodation(&dto); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureAssessment(assessmentID); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureTarget(dto.GroupID, dto.UserID); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.accommodationRepo.FindByTarget(assessmentID, dto.GroupID, dto.UserID)
+	if err != nil {
+		s.log.Error("[CreateAccommodation] failed to check existing accommodation", zap.Error(err))
+		return nil, err
+	}
+
+	if existing != nil {
+		return nil, ErrAccommodationExists
+	}
+
+	accommodation := &models.AssessmentAccommodation{
+		AssessmentID:   assessmentID,
+		GroupID:        dto.GroupID,
+		UserID:         dto.UserID,
+		TimeMultiplier: dto.TimeMultiplier,
+		ExtraMinutes:   dto.ExtraMinutes,
+		ExtraAttempts:  dto.ExtraAttempts,
+		DueDate:        dto.DueDate,
+		Reason:         strings.TrimSpace(dto.Reason),
+		CreatedByID:    createdByID,
+	}
+
+	if err := s.accommodationRepo.Create(accommodation); err != nil {
+		s.log.Error("[CreateAccommodation] failed to create accommodation", zap.Error(err))
+		return nil, err
+	}
+
+	return s.accommodationRepo.FindByID(assessmentID, accommodation.ID)
+}
+
+func (s *accommodationService) UpdateAccommodation(assessmentID, accommodationID uint, dto models.AccommodationDTO) (*models.AssessmentAccommodation, error) {
+	if err := validateAccommodation(&dto); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureAssessment(assessmentID); err != nil {
+		return nil, err
+	}
+
+	accommodation, err := s.accommodationRepo.FindByID(assessmentID, accommodationID)
+	if err != nil {
+		s.log.Error("[UpdateAccommodation] failed to find accommodation", zap.Error(err))
+		return nil, err
+	}
+
+	if accommodation == nil {
+		return nil, ErrAccommodationNotFound
+	}
+
+	accommodation.TimeMultiplier = dto.TimeMultiplier
+	accommodation.ExtraMinutes = dto.ExtraMinutes
+	accommodation.ExtraAttempts = dto.ExtraAttempts
+	accommodation.DueDate = dto.DueDate
+	accommodation.Reason = strings.TrimSpace(dto.Reason)
+
+	if err := s.accommodationRepo.Update(accommodation); err != nil {
+		s.log.Error("[UpdateAccommodation] failed to update accommodation", zap.Error(err))
+		return nil, err
+	}
+
+	return accommodation, nil
+}
+
+func (s *accommodationService) DeleteAccommodation(assessmentID, accommodationID uint) error {
+	if err := s.ensureAssessment(assessmentID); err != nil {
+		return err
+	}
+
+	removed, err := s.accommodationRepo.Delete(assessmentID, accommodationID)
+	if err != nil {
+		s.log.Error("[DeleteAccommodation] failed to delete accommodation", zap.Error(err))
+		return err
+	}
+
+	if !removed {
+		return ErrAccommodationNotFound
+	}
+
+	return nil
+}
+
+// validateAccommodation checks the adjustments and defaults a missing time multiplier to 1
+func validateAccommodation(dto *models.AccommodationDTO) error {
+	if dto.TimeMultiplier == 0 {
+		dto.TimeMultiplier = 1
+	}
+
+	if dto.TimeMultiplier < 1 || dto.TimeMultiplier > MaxTimeMultiplier {
+		return fmt.Errorf("%w: time multiplier must be between 1 and %.0f", ErrInvalidAccommodation, MaxTimeMultiplier)
+	}
+
+	if dto.ExtraMinutes < 0 || dto.ExtraAttempts < 0 {
+		return fmt.Errorf("%w: extra minutes and extra attempts cannot be negative", ErrInvalidAccommodation)
+	}
+
+	return nil
+}
+
+func (s *accommodationService) ensureTarget(groupID, userID *uint) error {
+	if groupID != nil {
+		if _, err := s.groupRepo.FindByID(*groupID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: group %d", ErrAccommodationTargetNotFound, *groupID)
+			}
+			s.log.Error("failed to find group", zap.Error(err))
+			return err
+		}
+		return nil
+	}
+
+	user, err := s.userRepo.FindByID(*userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: user %d", ErrAccommodationTargetNotFound, *userID)
+		}
+		s.log.Error("failed to find user", zap.Error(err))
+		return err
+	}
+
+	if user.Role != "student" {
+		return fmt.Errorf("%w: user %d", ErrAccommodationNotStudent, *userID)
+	}
+
+	return nil
+}
+
+func (s *accommodationService) ensureAssessment(assessmentID uint) error {
+	if _, err := s.assessmentRepo.FindByID(assessmentID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAssessmentNotFound
+		}
+		s.log.Error("failed to find assessment", zap.Error(err))
+		return err
+	}
+	return nil
+}
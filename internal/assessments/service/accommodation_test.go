@@ -0,0 +1,189 @@
+package service
+
+import (
+	models "assessment_service/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+// --- Mock AccommodationRepository ---
+type MockAccommodationRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccommodationRepository) Create(accommodation *models.AssessmentAccommodation) error {
+	args := m.Called(accommodation)
+	return args.Error(0)
+}
+
+func (m *MockAccommodationRepository) FindByID(assessmentID, accommodationID uint) (*models.AssessmentAccommodation, error) {
+	args := m.Called(assessmentID, accommodationID)
+	accommodation, _ := args.Get(0).(*models.AssessmentAccommodation)
+	return accommodation, args.Error(1)
+}
+
+func (m *MockAccommodationRepository) FindByTarget(assessmentID uint, groupID, userID *uint) (*models.AssessmentAccommodation, error) {
+	args := m.Called(assessmentID, groupID, userID)
+	accommodation, _ := args.Get(0).(*models.AssessmentAccommodation)
+	return accommodation, args.Error(1)
+}
+
+func (m *MockAccommodationRepository) ListByAssessment(assessmentID uint) ([]models.AssessmentAccommodation, error) {
+	args := m.Called(assessmentID)
+	accommodations, _ := args.Get(0).([]models.AssessmentAccommodation)
+	return accommodations, args.Error(1)
+}
+
+func (m *MockAccommodationRepository) Update(accommodation *models.AssessmentAccommodation) error {
+	args := m.Called(accommodation)
+	return args.Error(0)
+}
+
+func (m *MockAccommodationRepository) Delete(assessmentID, accommodationID uint) (bool, error) {
+	args := m.Called(assessmentID, accommodationID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAccommodationRepository) FindForUser(userID uint, assessmentIDs []uint) ([]models.AssessmentAccommodation, error) {
+	args := m.Called(userID, assessmentIDs)
+	accommodations, _ := args.Get(0).([]models.AssessmentAccommodation)
+	return accommodations, args.Error(1)
+}
+
+func newTestAccommodationService(t *testing.T) (AccommodationService, *MockAssessmentRepository, *MockAccommodationRepository, *MockGroupRepository, *MockUserRepository) {
+	assessmentRepo := new(MockAssessmentRepository)
+	accommodationRepo := new(MockAccommodationRepository)
+	groupRepo := new(MockGroupRepository)
+	userRepo := new(MockUserRepository)
+	return NewAccommodationService(assessmentRepo, accommodationRepo, groupRepo, userRepo, zaptest.NewLogger(t)), assessmentRepo, accommodationRepo, groupRepo, userRepo
+}
+
+func TestCreateAccommodation(t *testing.T) {
+	service, assessmentRepo, accommodationRepo, _, userRepo := newTestAccommodationService(t)
+
+	userID := uint(20)
+	assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+	userRepo.On("FindByID", userID).Return(&models.User{ID: userID, Role: "student"}, nil)
+	accommodationRepo.On("FindByTarget", uint(1), (*uint)(nil), &userID).Return(nil, nil)
+	accommodationRepo.On("Create", mock.MatchedBy(func(a *models.AssessmentAccommodation) bool {
+		// Hệ số thời gian mặc định là 1 khi không truyền
+		return a.AssessmentID == 1 && *a.UserID == userID && a.TimeMultiplier == 1 && a.ExtraMinutes == 15 && a.CreatedByID == 10
+	})).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.AssessmentAccommodation).ID = 7
+	})
+	accommodationRepo.On("FindByID", uint(1), uint(7)).Return(&models.AssessmentAccommodation{ID: 7}, nil)
+
+	accommodation, err := service.CreateAccommodation(1, models.AccommodationDTO{UserID: &userID, ExtraMinutes: 15}, 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(7), accommodation.ID)
+	accommodationRepo.AssertExpectations(t)
+}
+
+func TestCreateAccommodation_Rejected(t *testing.T) {
+	userID := uint(20)
+	groupID := uint(3)
+
+	t.Run("NoTarget", func(t *testing.T) {
+		service, _, _, _, _ := newTestAccommodationService(t)
+
+		_, err := service.CreateAccommodation(1, models.AccommodationDTO{ExtraMinutes: 15}, 10)
+
+		assert.ErrorIs(t, err, ErrAccommodationTargetRequired)
+	})
+
+	t.Run("BothTargets", func(t *testing.T) {
+		service, _, _, _, _ := newTestAccommodationService(t)
+
+		_, err := service.CreateAccommodation(1, models.AccommodationDTO{UserID: &userID, GroupID: &groupID}, 10)
+
+		assert.ErrorIs(t, err, ErrAccommodationTargetRequired)
+	})
+
+	t.Run("InvalidMultiplier", func(t *testing.T) {
+		service, _, _, _, _ := newTestAccommodationService(t)
+
+		_, err := service.CreateAccommodation(1, models.AccommodationDTO{UserID: &userID, TimeMultiplier: 0.5}, 10)
+		assert.ErrorIs(t, err, ErrInvalidAccommodation)
+
+		_, err = service.CreateAccommodation(1, models.AccommodationDTO{UserID: &userID, TimeMultiplier: 150}, 10)
+		assert.ErrorIs(t, err, ErrInvalidAccommodation)
+	})
+
+	t.Run("NegativeExtraAttempts", func(t *testing.T) {
+		service, _, _, _, _ := newTestAccommodationService(t)
+
+		_, err := service.CreateAccommodation(1, models.AccommodationDTO{UserID: &userID, ExtraAttempts: -1}, 10)
+
+		assert.ErrorIs(t, err, ErrInvalidAccommodation)
+	})
+
+	t.Run("UnknownGroup", func(t *testing.T) {
+		service, assessmentRepo, accommodationRepo, groupRepo, _ := newTestAccommodationService(t)
+		assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+		groupRepo.On("FindByID", groupID).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := service.CreateAccommodation(1, models.AccommodationDTO{GroupID: &groupID}, 10)
+
+		assert.ErrorIs(t, err, ErrAccommodationTargetNotFound)
+		accommodationRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Teacher", func(t *testing.T) {
+		service, assessmentRepo, accommodationRepo, _, userRepo := newTestAccommodationService(t)
+		assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+		userRepo.On("FindByID", userID).Return(&models.User{ID: userID, Role: "teacher"}, nil)
+
+		_, err := service.CreateAccommodation(1, models.AccommodationDTO{UserID: &userID}, 10)
+
+		assert.ErrorIs(t, err, ErrAccommodationNotStudent)
+		accommodationRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("AlreadyExists", func(t *testing.T) {
+		service, assessmentRepo, accommodationRepo, groupRepo, _ := newTestAccommodationService(t)
+		assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+		groupRepo.On("FindByID", groupID).Return(&models.Group{ID: groupID}, nil)
+		accommodationRepo.On("FindByTarget", uint(1), &groupID, (*uint)(nil)).Return(&models.AssessmentAccommodation{ID: 2}, nil)
+
+		_, err := service.CreateAccommodation(1, models.AccommodationDTO{GroupID: &groupID}, 10)
+
+		assert.ErrorIs(t, err, ErrAccommodationExists)
+		accommodationRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestUpdateAccommodation(t *testing.T) {
+	service, assessmentRepo, accommodationRepo, _, _ := newTestAccommodationService(t)
+
+	userID := uint(20)
+	otherUserID := uint(21)
+	assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+	accommodationRepo.On("FindByID", uint(1), uint(7)).Return(&models.AssessmentAccommodation{ID: 7, AssessmentID: 1, UserID: &userID, TimeMultiplier: 1}, nil)
+	accommodationRepo.On("Update", mock.Anything).Return(nil)
+
+	// Không đổi được đối tượng của accommodation khi cập nhật
+	accommodation, err := service.UpdateAccommodation(1, 7, models.AccommodationDTO{UserID: &otherUserID, TimeMultiplier: 2, ExtraAttempts: 1})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, accommodation.TimeMultiplier)
+	assert.Equal(t, 1, accommodation.ExtraAttempts)
+	assert.Equal(t, userID, *accommodation.UserID)
+	accommodationRepo.AssertExpectations(t)
+}
+
+func TestDeleteAccommodation_NotFound(t *testing.T) {
+	service, assessmentRepo, accommodationRepo, _, _ := newTestAccommodationService(t)
+
+	assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+	accommodationRepo.On("Delete", uint(1), uint(5)).Return(false, nil)
+
+	err := service.DeleteAccommodation(1, 5)
+
+	assert.ErrorIs(t, err, ErrAccommodationNotFound)
+}
@@ -6,10 +6,24 @@ import (
 	repository2 "assessment_service/internal/users/repository"
 	"assessment_service/internal/util"
 	"errors"
+	"fmt"
 	"go.uber.org/zap"
 	"time"
 )
 
+var (
+	ErrInvalidSchedule   = errors.New("invalid availability window")
+	ErrInvalidLatePolicy = errors.New("invalid late policy")
+	// ErrInvalidGradingSettings is returned for a negative reconciliation threshold
+	ErrInvalidGradingSettings = errors.New("invalid grading settings")
+	ErrInvalidTransition      = errors.New("invalid status transition")
+	ErrNoQuestions            = errors.New("cannot publish assessment without questions")
+	// ErrInvalidResumePolicy is returned for a resume policy other than any, same_ip or same_device
+	ErrInvalidResumePolicy = errors.New("invalid resume policy")
+	// ErrInvalidTimerSettings is returned for a negative grace period
+	ErrInvalidTimerSettings = errors.New("invalid timer settings")
+)
+
 type AssessmentService interface {
 	Create(assessment *models.Assessment) error
 	GetByID(id uint) (*models.Assessment, error)
@@ -19,11 +33,21 @@ type AssessmentService interface {
 	GetRecentAssessments(limit int) ([]models.Assessment, error)
 	GetStatistics() (map[string]interface{}, error)
 	UpdateSettings(id uint, settings *models.AssessmentSettings) error
-	GetResults(id uint, params util.PaginationParams) ([]map[string]interface{}, int64, error)
-	Publish(id uint) (*models.Assessment, error)
-	Duplicate(id uint, newTitle string, copyQuestions, copySettings, setAsDraft bool) (*models.Assessment, error)
+	// GetResults leaves out who made each attempt when hideIdentities is set and the assessment is
+	// graded blind
+	GetResults(id uint, params util.PaginationParams, hideIdentities bool) ([]map[string]interface{}, int64, error)
+	// Publish makes a draft Active, or Scheduled when its window opens in the future
+	Publish(id, changedByID uint) (*models.Assessment, error)
+	Close(id, changedByID uint, reason string) (*models.Assessment, error)
+	Reopen(id, changedByID uint, reason string) (*models.Assessment, error)
+	Archive(id, changedByID uint, reason string) (*models.Assessment, error)
+	GetStatusHistory(id uint) ([]models.AssessmentStatusTransition, error)
+	Duplicate(id, actorID uint, newTitle string, copyQuestions, copySettings bool) (*models.Assessment, error)
 	GetAssessmentDetailWithUser(assessmentID uint, params util.PaginationParams) (*models.Assessment, []models.User, int64, error)
 	GetAssessmentHasAttempt(userID uint, params util.PaginationParams) ([]models.Assessment, int64, error)
+	// UpdateScheduledStatuses activates scheduled assessments whose window has opened and closes active
+	// ones whose window has ended
+	UpdateScheduledStatuses() error
 }
 
 type assessmentService struct {
@@ -35,29 +59,37 @@ type assessmentService struct {
 func NewAssessmentService(
 	assessmentRepo repository.AssessmentRepository,
 	userRepo repository2.UserRepository,
+	log *zap.Logger,
 ) AssessmentService {
 	return &assessmentService{
 		assessmentRepo: assessmentRepo,
 		userRepo:       userRepo,
+		log:            log,
 	}
 }
 
 func (s *assessmentService) Create(assessment *models.Assessment) error {
-	// Set default status
-	if assessment.Status == "" {
-		assessment.Status = "Draft"
+	if err := validateSchedule(assessment); err != nil {
+		return err
 	}
 
+	// New assessments always start as drafts; Publish moves them on
+	assessment.Status = models.AssessmentDraft
+
 	// Create default settings
 	assessment.Settings = models.AssessmentSettings{
 		RandomizeQuestions:          false,
+		ShuffleOptions:              false,
 		ShowResults:                 true,
 		AllowRetake:                 false,
 		MaxAttempts:                 1,
 		TimeLimitEnforced:           true,
+		GracePeriod:                 0,
 		RequireWebcam:               false,
 		PreventTabSwitching:         false,
 		RequireIdentityVerification: false,
+		LatePolicy:                  models.LatePolicyReject,
+		ResumePolicy:                models.ResumeAnywhere,
 	}
 
 	return s.assessmentRepo.Create(assessment)
@@ -90,8 +122,8 @@ func (s *assessmentService) Update(id uint, assessmentData map[string]interface{
 		assessment.Duration = int(duration)
 	}
 
-	if status, ok := assessmentData["status"].(string); ok {
-		assessment.Status = status
+	if status, ok := assessmentData["status"].(string); ok && models.AssessmentStatus(status) != assessment.Status {
+		return nil, fmt.Errorf("%w: use publish, close, reopen or archive to change the status", ErrInvalidTransition)
 	}
 
 	if dueDateStr, ok := assessmentData["dueDate"].(string); ok && dueDateStr != "" {
@@ -105,6 +137,26 @@ func (s *assessmentService) Update(id uint, assessmentData map[string]interface{
 		assessment.PassingScore = passingScore
 	}
 
+	if availableFromStr, ok := assessmentData["availableFrom"].(string); ok && availableFromStr != "" {
+		availableFrom, err := time.Parse(time.RFC3339, availableFromStr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: availableFrom must be an RFC 3339 timestamp", ErrInvalidSchedule)
+		}
+		assessment.AvailableFrom = &availableFrom
+	}
+
+	if availableUntilStr, ok := assessmentData["availableUntil"].(string); ok && availableUntilStr != "" {
+		availableUntil, err := time.Parse(time.RFC3339, availableUntilStr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: availableUntil must be an RFC 3339 timestamp", ErrInvalidSchedule)
+		}
+		assessment.AvailableUntil = &availableUntil
+	}
+
+	if err := validateSchedule(assessment); err != nil {
+		return nil, err
+	}
+
 	// Update assessment
 	err = s.assessmentRepo.Update(assessment)
 	if err != nil {
@@ -138,6 +190,19 @@ func (s *assessmentService) GetStatistics() (map[string]interface{}, error) {
 }
 
 func (s *assessmentService) UpdateSettings(id uint, settings *models.AssessmentSettings) error {
+	if err := validateLatePolicy(settings); err != nil {
+		return err
+	}
+	if settings.ReconciliationThreshold < 0 {
+		return fmt.Errorf("%w: reconciliationThreshold cannot be negative", ErrInvalidGradingSettings)
+	}
+	if err := validateResumePolicy(settings); err != nil {
+		return err
+	}
+	if settings.GracePeriod < 0 {
+		return fmt.Errorf("%w: gracePeriod cannot be negative", ErrInvalidTimerSettings)
+	}
+
 	// Check if assessment exists
 	_, err := s.assessmentRepo.FindByID(id)
 	if err != nil {
@@ -147,33 +212,41 @@ func (s *assessmentService) UpdateSettings(id uint, settings *models.AssessmentS
 	return s.assessmentRepo.UpdateSettings(id, settings)
 }
 
-func (s *assessmentService) GetResults(id uint, params util.PaginationParams) ([]map[string]interface{}, int64, error) {
-	return s.assessmentRepo.GetResults(id, params)
-}
-
-func (s *assessmentService) Publish(id uint) (*models.Assessment, error) {
-	// Check if assessment exists
-	assessment, err := s.assessmentRepo.FindByID(id)
-	if err != nil {
-		return nil, err
+func (s *assessmentService) GetResults(id uint, params util.PaginationParams, hideIdentities bool) ([]map[string]interface{}, int64, error) {
+	blind := false
+	if hideIdentities {
+		assessment, err := s.assessmentRepo.FindByID(id)
+		if err != nil {
+			return nil, 0, err
+		}
+		blind = assessment.Settings.BlindGrading
 	}
 
-	// Check if assessment has questions
-	if len(assessment.Questions) == 0 {
-		return nil, errors.New("cannot publish assessment without questions")
+	// Searching by name or email would tell who made the attempts that match
+	if blind {
+		delete(params.Filters, "user")
 	}
 
-	// Update status
-	err = s.assessmentRepo.Publish(id)
+	results, total, err := s.assessmentRepo.GetResults(id, params)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	// Refresh the assessment
-	return s.assessmentRepo.FindByID(id)
+	for _, row := range results {
+		if blind {
+			delete(row, "user")
+			delete(row, "user_id")
+			row["candidate"] = fmt.Sprintf("Candidate %v", row["candidate_number"])
+		}
+		delete(row, "candidate_number")
+	}
+
+	return results, total, nil
 }
 
-func (s *assessmentService) Duplicate(id uint, newTitle string, copyQuestions, copySettings, setAsDraft bool) (*models.Assessment, error) {
+// Duplicate copies an assessment for actorID, who owns the copy. The copy always starts as a
+// draft, so it goes through Publish like any new assessment
+func (s *assessmentService) Duplicate(id, actorID uint, newTitle string, copyQuestions, copySettings bool) (*models.Assessment, error) {
 	// Check if assessment exists
 	originalAssessment, err := s.assessmentRepo.FindByID(id)
 	if err != nil {
@@ -196,9 +269,9 @@ func (s *assessmentService) Duplicate(id uint, newTitle string, copyQuestions, c
 		originalAssessment.Title = originalAssessment.Title + " (Copy)"
 	}
 
-	if setAsDraft {
-		originalAssessment.Status = "draft"
-	}
+	originalAssessment.Status = models.AssessmentDraft
+
+	originalAssessment.CreatedByID = actorID
 
 	// Create duplicate
 	err = s.assessmentRepo.Duplicate(originalAssessment)
@@ -248,3 +321,45 @@ func (s *assessmentService) GetAssessmentHasAttempt(userID uint, params util.Pag
 
 	return assessments, total, nil
 }
+
+// validateSchedule checks that the availability window, when both ends are set, is not empty
+func validateSchedule(assessment *models.Assessment) error {
+	if assessment.AvailableFrom != nil && assessment.AvailableUntil != nil &&
+		!assessment.AvailableUntil.After(*assessment.AvailableFrom) {
+		return fmt.Errorf("%w: availableUntil must be after availableFrom", ErrInvalidSchedule)
+	}
+	return nil
+}
+
+// validateLatePolicy checks the late submission settings and defaults an empty policy to reject
+func validateLatePolicy(settings *models.AssessmentSettings) error {
+	switch settings.LatePolicy {
+	case "":
+		settings.LatePolicy = models.LatePolicyReject
+	case models.LatePolicyReject:
+	case models.LatePolicyPenalty:
+		if settings.LatePenalty <= 0 || settings.LatePenalty > 100 {
+			return fmt.Errorf("%w: latePenalty must be greater than 0 and at most 100", ErrInvalidLatePolicy)
+		}
+	case models.LatePolicyCutoff:
+		if settings.LateCutoff == nil {
+			return fmt.Errorf("%w: lateCutoff is required for the cutoff policy", ErrInvalidLatePolicy)
+		}
+	default:
+		return fmt.Errorf("%w: latePolicy must be one of reject, penalty, cutoff", ErrInvalidLatePolicy)
+	}
+	return nil
+}
+
+// validateResumePolicy checks where students may resume attempts from and defaults an empty policy
+// to anywhere
+func validateResumePolicy(settings *models.AssessmentSettings) error {
+	switch settings.ResumePolicy {
+	case "":
+		settings.ResumePolicy = models.ResumeAnywhere
+	case models.ResumeAnywhere, models.ResumeSameIP, models.ResumeSameDevice:
+	default:
+		return fmt.Errorf("%w: resumePolicy must be one of any, same_ip, same_device", ErrInvalidResumePolicy)
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
 )
 
 // Mock repository implementations
@@ -106,11 +107,6 @@ func (m *MockAssessmentRepository) GetResults(id uint, params util.PaginationPar
 	return results, count, args.Error(2)
 }
 
-func (m *MockAssessmentRepository) Publish(id uint) error {
-	args := m.Called(id)
-	return args.Error(0)
-}
-
 func (m *MockAssessmentRepository) Duplicate(assessment *models.Assessment) error {
 	args := m.Called(assessment)
 	return args.Error(0)
@@ -133,6 +129,29 @@ func (m *MockAssessmentRepository) GetAssessmentHasAttemptByUser(params util.Pag
 	return assessments, count, args.Error(2)
 }
 
+func (m *MockAssessmentRepository) UpdateStatus(transition *models.AssessmentStatusTransition) (bool, error) {
+	args := m.Called(transition)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAssessmentRepository) ListStatusHistory(assessmentID uint) ([]models.AssessmentStatusTransition, error) {
+	args := m.Called(assessmentID)
+	transitions, _ := args.Get(0).([]models.AssessmentStatusTransition)
+	return transitions, args.Error(1)
+}
+
+func (m *MockAssessmentRepository) ActivateScheduled(now time.Time) (int64, error) {
+	args := m.Called(now)
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
+func (m *MockAssessmentRepository) CloseEnded(now time.Time) (int64, error) {
+	args := m.Called(now)
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
 // Mock UserRepository implementation (assuming it exists based on service dependencies)
 type MockUserRepository struct {
 	mock.Mock
@@ -249,7 +268,7 @@ func (m *MockUserRepository) GetListUserByAssessment(params util.PaginationParam
 func TestCreateAssessment(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockUserRepo := new(MockUserRepository)
-	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo)
+	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo, zaptest.NewLogger(t))
 
 	assessment := &models.Assessment{
 		Title:        "Test Assessment",
@@ -268,8 +287,8 @@ func TestCreateAssessment(t *testing.T) {
 	err := service.Create(assessment)
 
 	assert.NoError(t, err)
-	assert.Equal(t, "Draft", assessment.Status) // Verify default status
-	assert.NotNil(t, assessment.Settings)       // Verify default settings
+	assert.Equal(t, models.AssessmentDraft, assessment.Status) // Verify default status
+	assert.NotNil(t, assessment.Settings)                      // Verify default settings
 	// Check default settings values
 	assert.False(t, assessment.Settings.RandomizeQuestions)
 	assert.True(t, assessment.Settings.ShowResults)
@@ -283,7 +302,7 @@ func TestCreateAssessment(t *testing.T) {
 func TestGetByID(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockUserRepo := new(MockUserRepository)
-	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo)
+	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo, zaptest.NewLogger(t))
 
 	expectedAssessment := &models.Assessment{
 		ID:           1,
@@ -308,7 +327,7 @@ func TestGetByID(t *testing.T) {
 func TestGetByID_NotFound(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockUserRepo := new(MockUserRepository)
-	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo)
+	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo, zaptest.NewLogger(t))
 
 	mockAssessmentRepo.On("FindByID", uint(99)).Return(nil, errors.New("record not found"))
 
@@ -323,7 +342,7 @@ func TestGetByID_NotFound(t *testing.T) {
 func TestUpdateAssessment(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockUserRepo := new(MockUserRepository)
-	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo)
+	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo, zaptest.NewLogger(t))
 
 	now := time.Now()
 	existingAssessment := &models.Assessment{
@@ -343,7 +362,7 @@ func TestUpdateAssessment(t *testing.T) {
 		"description":  "New description",
 		"duration":     float64(90), // JSON numbers are often float64
 		"passingScore": float64(80),
-		"status":       "Active",
+		"status":       "Draft", // Gửi lại trạng thái hiện tại thì không bị từ chối
 		"dueDate":      now.Format(time.RFC3339),
 	}
 
@@ -355,7 +374,7 @@ func TestUpdateAssessment(t *testing.T) {
 			a.Description == "New description" &&
 			a.Duration == 90 &&
 			a.PassingScore == 80 &&
-			a.Status == "Active" &&
+			a.Status == models.AssessmentDraft &&
 			a.DueDate != nil && a.DueDate.Format(time.RFC3339) == now.Format(time.RFC3339)
 	})).Return(nil)
 
@@ -366,16 +385,68 @@ func TestUpdateAssessment(t *testing.T) {
 	assert.Equal(t, "New description", result.Description)
 	assert.Equal(t, 90, result.Duration)
 	assert.Equal(t, float64(80), result.PassingScore)
-	assert.Equal(t, "Active", result.Status)
+	assert.Equal(t, models.AssessmentDraft, result.Status)
 	assert.NotNil(t, result.DueDate)
 	assert.Equal(t, now.Format(time.RFC3339), result.DueDate.Format(time.RFC3339))
 	mockAssessmentRepo.AssertExpectations(t)
 }
 
+func TestUpdateAssessment_InvalidSchedule(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	service := NewAssessmentService(mockAssessmentRepo, new(MockUserRepository), zaptest.NewLogger(t))
+
+	mockAssessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+
+	// Thời điểm đóng trước thời điểm mở
+	_, err := service.Update(1, map[string]interface{}{
+		"availableFrom":  "2025-06-02T09:00:00Z",
+		"availableUntil": "2025-06-01T09:00:00Z",
+	})
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+
+	_, err = service.Update(1, map[string]interface{}{"availableFrom": "tomorrow"})
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+
+	mockAssessmentRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestUpdateAssessment_StatusChangeRejected(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	service := NewAssessmentService(mockAssessmentRepo, new(MockUserRepository), zaptest.NewLogger(t))
+
+	mockAssessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1, Status: models.AssessmentDraft}, nil)
+
+	_, err := service.Update(1, map[string]interface{}{"status": "Active"})
+
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+	mockAssessmentRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestUpdateScheduledStatuses(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	service := NewAssessmentService(mockAssessmentRepo, new(MockUserRepository), zaptest.NewLogger(t))
+
+	mockAssessmentRepo.On("ActivateScheduled", mock.AnythingOfType("time.Time")).Return(int64(1), nil)
+	mockAssessmentRepo.On("CloseEnded", mock.AnythingOfType("time.Time")).Return(int64(2), nil)
+
+	assert.NoError(t, service.UpdateScheduledStatuses())
+	mockAssessmentRepo.AssertExpectations(t)
+}
+
+func TestUpdateScheduledStatuses_ActivateFails(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	service := NewAssessmentService(mockAssessmentRepo, new(MockUserRepository), zaptest.NewLogger(t))
+
+	mockAssessmentRepo.On("ActivateScheduled", mock.AnythingOfType("time.Time")).Return(int64(0), errors.New("db error"))
+
+	assert.Error(t, service.UpdateScheduledStatuses())
+	mockAssessmentRepo.AssertNotCalled(t, "CloseEnded", mock.Anything)
+}
+
 func TestUpdateAssessment_NotFound(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockUserRepo := new(MockUserRepository)
-	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo)
+	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo, zaptest.NewLogger(t))
 
 	updateData := map[string]interface{}{"title": "New Title"}
 
@@ -394,7 +465,7 @@ func TestUpdateAssessment_NotFound(t *testing.T) {
 func TestDeleteAssessment(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockUserRepo := new(MockUserRepository)
-	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo)
+	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo, zaptest.NewLogger(t))
 
 	existingAssessment := &models.Assessment{ID: 1}
 
@@ -410,7 +481,7 @@ func TestDeleteAssessment(t *testing.T) {
 func TestDeleteAssessment_NotFound(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockUserRepo := new(MockUserRepository)
-	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo)
+	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo, zaptest.NewLogger(t))
 
 	mockAssessmentRepo.On("FindByID", uint(99)).Return(nil, errors.New("record not found"))
 
@@ -425,50 +496,88 @@ func TestDeleteAssessment_NotFound(t *testing.T) {
 func TestPublishAssessment(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockUserRepo := new(MockUserRepository)
-	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo)
+	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo, zaptest.NewLogger(t))
 
 	existingAssessment := &models.Assessment{
 		ID:        1,
 		Status:    "Draft",
 		Questions: []models.Question{{ID: 1, Text: "Test question"}}, // Has questions
 	}
-	publishedAssessment := &models.Assessment{
-		ID:        1,
-		Status:    "Active", // Status after publish
-		Questions: []models.Question{{ID: 1, Text: "Test question"}},
-	}
 
-	mockAssessmentRepo.On("FindByID", uint(1)).Return(existingAssessment, nil).Once() // First call to check
-	mockAssessmentRepo.On("Publish", uint(1)).Return(nil)
-	mockAssessmentRepo.On("FindByID", uint(1)).Return(publishedAssessment, nil).Once() // Second call to return updated
+	mockAssessmentRepo.On("FindByID", uint(1)).Return(existingAssessment, nil)
+	mockAssessmentRepo.On("UpdateStatus", mock.MatchedBy(func(tr *models.AssessmentStatusTransition) bool {
+		return tr.AssessmentID == 1 && tr.FromStatus == models.AssessmentDraft && tr.ToStatus == models.AssessmentActive &&
+			tr.ChangedByID != nil && *tr.ChangedByID == 7
+	})).Return(true, nil)
 
-	result, err := service.Publish(1)
+	result, err := service.Publish(1, 7)
 
 	assert.NoError(t, err)
-	assert.Equal(t, "Active", result.Status)
+	assert.Equal(t, models.AssessmentActive, result.Status)
 	mockAssessmentRepo.AssertExpectations(t)
 }
 
+func TestPublishAssessment_FutureWindowIsScheduled(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	service := NewAssessmentService(mockAssessmentRepo, new(MockUserRepository), zaptest.NewLogger(t))
+
+	opensAt := time.Now().Add(24 * time.Hour)
+	existingAssessment := &models.Assessment{
+		ID:            1,
+		Status:        models.AssessmentDraft,
+		AvailableFrom: &opensAt,
+		Questions:     []models.Question{{ID: 1}},
+	}
+
+	mockAssessmentRepo.On("FindByID", uint(1)).Return(existingAssessment, nil)
+	mockAssessmentRepo.On("UpdateStatus", mock.MatchedBy(func(tr *models.AssessmentStatusTransition) bool {
+		return tr.ToStatus == models.AssessmentScheduled
+	})).Return(true, nil)
+
+	result, err := service.Publish(1, 7)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.AssessmentScheduled, result.Status)
+}
+
+func TestPublishAssessment_WindowEnded(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	service := NewAssessmentService(mockAssessmentRepo, new(MockUserRepository), zaptest.NewLogger(t))
+
+	closedAt := time.Now().Add(-time.Hour)
+	mockAssessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{
+		ID:             1,
+		Status:         models.AssessmentDraft,
+		AvailableUntil: &closedAt,
+		Questions:      []models.Question{{ID: 1}},
+	}, nil)
+
+	_, err := service.Publish(1, 7)
+
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+	mockAssessmentRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything)
+}
+
 func TestPublishAssessment_NotFound(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockUserRepo := new(MockUserRepository)
-	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo)
+	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo, zaptest.NewLogger(t))
 
 	mockAssessmentRepo.On("FindByID", uint(99)).Return(nil, errors.New("record not found"))
 
-	result, err := service.Publish(99)
+	result, err := service.Publish(99, 7)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "record not found")
 	mockAssessmentRepo.AssertExpectations(t)
-	mockAssessmentRepo.AssertNotCalled(t, "Publish", uint(99))
+	mockAssessmentRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything)
 }
 
 func TestPublishAssessmentWithoutQuestions(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockUserRepo := new(MockUserRepository)
-	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo)
+	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo, zaptest.NewLogger(t))
 
 	existingAssessment := &models.Assessment{
 		ID:        1,
@@ -478,12 +587,12 @@ func TestPublishAssessmentWithoutQuestions(t *testing.T) {
 
 	mockAssessmentRepo.On("FindByID", uint(1)).Return(existingAssessment, nil)
 
-	_, err := service.Publish(1)
+	_, err := service.Publish(1, 7)
 
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, ErrNoQuestions)
 	assert.Contains(t, err.Error(), "cannot publish assessment without questions")
 	mockAssessmentRepo.AssertExpectations(t)
-	mockAssessmentRepo.AssertNotCalled(t, "Publish", uint(1)) // Publish should not be called
+	mockAssessmentRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything) // Status should not change
 }
 
 // --- New Tests ---
@@ -491,7 +600,7 @@ func TestPublishAssessmentWithoutQuestions(t *testing.T) {
 func TestListAssessments(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockUserRepo := new(MockUserRepository)
-	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo)
+	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo, zaptest.NewLogger(t))
 
 	params := util.PaginationParams{Page: 0, Limit: 10, Offset: 0}
 	expectedAssessments := []models.Assessment{
@@ -513,7 +622,7 @@ func TestListAssessments(t *testing.T) {
 func TestGetRecentAssessments(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockUserRepo := new(MockUserRepository)
-	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo)
+	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo, zaptest.NewLogger(t))
 
 	limit := 5
 	expectedAssessments := []models.Assessment{
@@ -533,16 +642,16 @@ func TestGetRecentAssessments(t *testing.T) {
 func TestGetStatistics(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockUserRepo := new(MockUserRepository)
-	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo)
+	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo, zaptest.NewLogger(t))
 
 	expectedStats := map[string]interface{}{
-		"totalAssessments":   int64(10),
-		"activeAssessments":  int64(5),
-		"draftAssessments":   int64(3),
-		"expiredAssessments": int64(2),
-		"totalAttempts":      int64(150),
-		"passRate":           75.5,
-		"averageScore":       82.1,
+		"totalAssessments":  int64(10),
+		"activeAssessments": int64(5),
+		"draftAssessments":  int64(3),
+		"closedAssessments": int64(2),
+		"totalAttempts":     int64(150),
+		"passRate":          75.5,
+		"averageScore":      82.1,
 		"bySubject": []map[string]interface{}{
 			{"subject": "Math", "count": 4},
 			{"subject": "Science", "count": 6},
@@ -561,7 +670,7 @@ func TestGetStatistics(t *testing.T) {
 func TestUpdateSettings(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockUserRepo := new(MockUserRepository)
-	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo)
+	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo, zaptest.NewLogger(t))
 
 	assessmentID := uint(1)
 	existingAssessment := &models.Assessment{ID: assessmentID} // Need something for FindByID
@@ -583,7 +692,7 @@ func TestUpdateSettings(t *testing.T) {
 func TestUpdateSettings_AssessmentNotFound(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockUserRepo := new(MockUserRepository)
-	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo)
+	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo, zaptest.NewLogger(t))
 
 	assessmentID := uint(99)
 	newSettings := &models.AssessmentSettings{}
@@ -598,10 +707,90 @@ func TestUpdateSettings_AssessmentNotFound(t *testing.T) {
 	mockAssessmentRepo.AssertNotCalled(t, "UpdateSettings", assessmentID, newSettings)
 }
 
+func TestUpdateSettings_InvalidLatePolicy(t *testing.T) {
+	cutoff := time.Now().Add(24 * time.Hour)
+
+	tests := []struct {
+		name     string
+		settings *models.AssessmentSettings
+		wantErr  bool
+	}{
+		{name: "empty defaults to reject", settings: &models.AssessmentSettings{}},
+		{name: "penalty", settings: &models.AssessmentSettings{LatePolicy: models.LatePolicyPenalty, LatePenalty: 10}},
+		{name: "penalty without percentage", settings: &models.AssessmentSettings{LatePolicy: models.LatePolicyPenalty}, wantErr: true},
+		{name: "penalty over 100", settings: &models.AssessmentSettings{LatePolicy: models.LatePolicyPenalty, LatePenalty: 120}, wantErr: true},
+		{name: "cutoff", settings: &models.AssessmentSettings{LatePolicy: models.LatePolicyCutoff, LateCutoff: &cutoff}},
+		{name: "cutoff without time", settings: &models.AssessmentSettings{LatePolicy: models.LatePolicyCutoff}, wantErr: true},
+		{name: "unknown policy", settings: &models.AssessmentSettings{LatePolicy: "forgive"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAssessmentRepo := new(MockAssessmentRepository)
+			service := NewAssessmentService(mockAssessmentRepo, new(MockUserRepository), zaptest.NewLogger(t))
+
+			mockAssessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+			mockAssessmentRepo.On("UpdateSettings", uint(1), tt.settings).Return(nil)
+
+			err := service.UpdateSettings(1, tt.settings)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidLatePolicy)
+				mockAssessmentRepo.AssertNotCalled(t, "UpdateSettings", uint(1), tt.settings)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotEmpty(t, tt.settings.LatePolicy)
+		})
+	}
+}
+
+func TestUpdateSettings_InvalidReconciliationThreshold(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	service := NewAssessmentService(mockAssessmentRepo, new(MockUserRepository), zaptest.NewLogger(t))
+
+	settings := &models.AssessmentSettings{DoubleMarking: true, ReconciliationThreshold: -1}
+	err := service.UpdateSettings(1, settings)
+
+	assert.ErrorIs(t, err, ErrInvalidGradingSettings)
+	mockAssessmentRepo.AssertNotCalled(t, "UpdateSettings", uint(1), settings)
+}
+
+func TestUpdateSettings_ResumePolicy(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	service := NewAssessmentService(mockAssessmentRepo, new(MockUserRepository), zaptest.NewLogger(t))
+	mockAssessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+	mockAssessmentRepo.On("UpdateSettings", uint(1), mock.Anything).Return(nil)
+
+	// Mặc định cho phép tiếp tục bài từ bất kỳ thiết bị nào
+	settings := &models.AssessmentSettings{}
+	assert.NoError(t, service.UpdateSettings(1, settings))
+	assert.Equal(t, models.ResumeAnywhere, settings.ResumePolicy)
+
+	assert.NoError(t, service.UpdateSettings(1, &models.AssessmentSettings{ResumePolicy: models.ResumeSameDevice}))
+
+	settings = &models.AssessmentSettings{ResumePolicy: "same_city"}
+	assert.ErrorIs(t, service.UpdateSettings(1, settings), ErrInvalidResumePolicy)
+	mockAssessmentRepo.AssertNotCalled(t, "UpdateSettings", uint(1), settings)
+}
+
+func TestUpdateSettings_GracePeriod(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	service := NewAssessmentService(mockAssessmentRepo, new(MockUserRepository), zaptest.NewLogger(t))
+	mockAssessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+	mockAssessmentRepo.On("UpdateSettings", uint(1), mock.Anything).Return(nil)
+
+	assert.NoError(t, service.UpdateSettings(1, &models.AssessmentSettings{TimeLimitEnforced: true, GracePeriod: 60}))
+
+	settings := &models.AssessmentSettings{GracePeriod: -1}
+	assert.ErrorIs(t, service.UpdateSettings(1, settings), ErrInvalidTimerSettings)
+	mockAssessmentRepo.AssertNotCalled(t, "UpdateSettings", uint(1), settings)
+}
+
 func TestGetResults(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockUserRepo := new(MockUserRepository)
-	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo)
+	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo, zaptest.NewLogger(t))
 
 	assessmentID := uint(1)
 	params := util.PaginationParams{Page: 0, Limit: 10}
@@ -613,7 +802,7 @@ func TestGetResults(t *testing.T) {
 
 	mockAssessmentRepo.On("GetResults", assessmentID, params).Return(expectedResults, expectedTotal, nil)
 
-	results, total, err := service.GetResults(assessmentID, params)
+	results, total, err := service.GetResults(assessmentID, params, false)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedResults, results)
@@ -621,10 +810,60 @@ func TestGetResults(t *testing.T) {
 	mockAssessmentRepo.AssertExpectations(t)
 }
 
+func TestGetResults_HideIdentities(t *testing.T) {
+	results := func() []map[string]interface{} {
+		return []map[string]interface{}{
+			{"id": uint(7), "user": "Alice", "user_id": uint(8), "score": 90.0, "candidate_number": int64(2)},
+		}
+	}
+
+	t.Run("BlindGrading", func(t *testing.T) {
+		mockAssessmentRepo := new(MockAssessmentRepository)
+		service := NewAssessmentService(mockAssessmentRepo, new(MockUserRepository), zaptest.NewLogger(t))
+
+		// Bộ lọc theo tên bị bỏ để người chấm không dò ra ai làm bài
+		mockAssessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1, Settings: models.AssessmentSettings{BlindGrading: true}}, nil)
+		mockAssessmentRepo.On("GetResults", uint(1), util.PaginationParams{Limit: 10, Filters: map[string]interface{}{}}).Return(results(), int64(1), nil)
+
+		rows, total, err := service.GetResults(1, util.PaginationParams{Limit: 10, Filters: map[string]interface{}{"user": "Alice"}}, true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, []map[string]interface{}{{"id": uint(7), "score": 90.0, "candidate": "Candidate 2"}}, rows)
+	})
+
+	t.Run("NotBlind", func(t *testing.T) {
+		mockAssessmentRepo := new(MockAssessmentRepository)
+		service := NewAssessmentService(mockAssessmentRepo, new(MockUserRepository), zaptest.NewLogger(t))
+
+		params := util.PaginationParams{Limit: 10, Filters: map[string]interface{}{"user": "Alice"}}
+		mockAssessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+		mockAssessmentRepo.On("GetResults", uint(1), params).Return(results(), int64(1), nil)
+
+		rows, _, err := service.GetResults(1, params, true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []map[string]interface{}{{"id": uint(7), "user": "Alice", "user_id": uint(8), "score": 90.0}}, rows)
+	})
+
+	t.Run("AssessmentNotFound", func(t *testing.T) {
+		mockAssessmentRepo := new(MockAssessmentRepository)
+		service := NewAssessmentService(mockAssessmentRepo, new(MockUserRepository), zaptest.NewLogger(t))
+
+		notFound := errors.New("record not found")
+		mockAssessmentRepo.On("FindByID", uint(1)).Return(nil, notFound)
+
+		_, _, err := service.GetResults(1, util.PaginationParams{Limit: 10}, true)
+
+		assert.ErrorIs(t, err, notFound)
+		mockAssessmentRepo.AssertNotCalled(t, "GetResults", mock.Anything, mock.Anything)
+	})
+}
+
 func TestDuplicateAssessment(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockUserRepo := new(MockUserRepository)
-	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo)
+	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo, zaptest.NewLogger(t))
 
 	originalID := uint(1)
 	originalAssessment := &models.Assessment{
@@ -647,9 +886,9 @@ func TestDuplicateAssessment(t *testing.T) {
 		Subject:      "Science",
 		Description:  "Original Desc",
 		Duration:     30,
-		CreatedByID:  2,
+		CreatedByID:  5, // The caller owns the copy
 		PassingScore: 65,
-		Status:       "draft",                                                    // setAsDraft is true
+		Status:       models.AssessmentDraft,                                     // The copy always starts as a draft
 		Questions:    []models.Question{{ID: 10, Text: "Q1"}},                    // copyQuestions is true
 		Settings:     models.AssessmentSettings{ID: 1, RandomizeQuestions: true}, // copySettings is true
 	}
@@ -661,9 +900,9 @@ func TestDuplicateAssessment(t *testing.T) {
 		Subject:      "Science",
 		Description:  "Original Desc",
 		Duration:     30,
-		CreatedByID:  2,
+		CreatedByID:  5,
 		PassingScore: 65,
-		Status:       "draft",
+		Status:       models.AssessmentDraft,
 		// Questions and Settings might or might not be loaded by List depending on repo implementation
 	}
 
@@ -672,6 +911,7 @@ func TestDuplicateAssessment(t *testing.T) {
 		// Check the state passed to the Duplicate repository method
 		return a.Title == expectedDuplicateInput.Title &&
 			a.Subject == expectedDuplicateInput.Subject &&
+			a.CreatedByID == expectedDuplicateInput.CreatedByID &&
 			a.Status == expectedDuplicateInput.Status &&
 			len(a.Questions) == len(expectedDuplicateInput.Questions) && // Check questions are copied
 			a.Settings.RandomizeQuestions == expectedDuplicateInput.Settings.RandomizeQuestions // Check settings are copied
@@ -692,21 +932,21 @@ func TestDuplicateAssessment(t *testing.T) {
 	newTitle := "New Copy Title"
 	copyQuestions := true
 	copySettings := true
-	setAsDraft := true
-	result, err := service.Duplicate(originalID, newTitle, copyQuestions, copySettings, setAsDraft)
+	result, err := service.Duplicate(originalID, 5, newTitle, copyQuestions, copySettings)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Equal(t, duplicatedAssessmentResult.ID, result.ID) // Check if the correct new assessment is returned
 	assert.Equal(t, newTitle, result.Title)
-	assert.Equal(t, "draft", result.Status)
+	assert.Equal(t, models.AssessmentDraft, result.Status)
+	assert.Equal(t, uint(5), result.CreatedByID)
 	mockAssessmentRepo.AssertExpectations(t)
 }
 
 func TestDuplicateAssessment_Defaults(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockUserRepo := new(MockUserRepository)
-	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo)
+	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo, zaptest.NewLogger(t))
 
 	originalID := uint(1)
 	originalAssessment := &models.Assessment{
@@ -730,7 +970,7 @@ func TestDuplicateAssessment_Defaults(t *testing.T) {
 		Duration:     30,
 		CreatedByID:  2,
 		PassingScore: 65,
-		Status:       "Active",                    // Default status (not setAsDraft)
+		Status:       models.AssessmentDraft,      // An active assessment is copied as a draft
 		Questions:    []models.Question{},         // Default: Don't copy questions
 		Settings:     models.AssessmentSettings{}, // Default: Don't copy settings
 	}
@@ -762,21 +1002,19 @@ func TestDuplicateAssessment_Defaults(t *testing.T) {
 	mockAssessmentRepo.On("List", listParams).Return([]models.Assessment{duplicatedAssessmentResult}, int64(1), nil)
 
 	// Call the service method with defaults
-	result, err := service.Duplicate(originalID, "", false, false, false)
+	result, err := service.Duplicate(originalID, 2, "", false, false)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Equal(t, duplicatedAssessmentResult.ID, result.ID)
 	assert.Equal(t, "Original Title (Copy)", result.Title)
-	// Status should match the original if setAsDraft is false
-	// assert.Equal(t, originalAssessment.Status, result.Status)
 	mockAssessmentRepo.AssertExpectations(t)
 }
 
 func TestGetAssessmentHasAttempt(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockUserRepo := new(MockUserRepository)
-	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo)
+	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo, zaptest.NewLogger(t))
 
 	userID := uint(1)
 	params := util.PaginationParams{Page: 0, Limit: 5}
@@ -799,7 +1037,7 @@ func TestGetAssessmentHasAttempt(t *testing.T) {
 func TestGetAssessmentDetailWithUser(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockUserRepo := new(MockUserRepository)
-	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo)
+	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo, zaptest.NewLogger(t))
 
 	assessmentID := uint(1)
 	params := util.PaginationParams{Page: 0, Limit: 10}
@@ -826,7 +1064,7 @@ func TestGetAssessmentDetailWithUser(t *testing.T) {
 func TestGetAssessmentDetailWithUser_AssessmentNotFound(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockUserRepo := new(MockUserRepository)
-	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo)
+	service := NewAssessmentService(mockAssessmentRepo, mockUserRepo, zaptest.NewLogger(t))
 
 	assessmentID := uint(99)
 	params := util.PaginationParams{}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"assessment_service/internal/assessments/repository"
+	repository3 "assessment_service/internal/groups/repository"
+	models "assessment_service/internal/model"
+	repository2 "assessment_service/internal/users/repository"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAssignmentNotFound = errors.New("assignment not found")
+	ErrNoAssigneesGiven   = errors.New("at least one group ID or user ID is required")
+	ErrAssigneeNotFound   = errors.New("group or user to assign not found")
+	ErrAssigneeNotStudent = errors.New("assessments can only be assigned to students")
+)
+
+type AssignmentService interface {
+	ListAssignments(assessmentID uint) ([]models.AssessmentAssignment, error)
+	AssignAssessment(assessmentID uint, assignment models.AssignmentDTO, assignedByID uint) ([]models.AssessmentAssignment, error)
+	UnassignAssessment(assessmentID, assignmentID uint) error
+}
+
+type assignmentService struct {
+	assessmentRepo repository.AssessmentRepository
+	assignmentRepo repository.AssignmentRepository
+	groupRepo      repository3.GroupRepository
+	userRepo       repository2.UserRepository
+	log            *zap.Logger
+}
+
+func NewAssignmentService(
+	assessmentRepo repository.AssessmentRepository,
+	assignmentRepo repository.AssignmentRepository,
+	groupRepo repository3.GroupRepository,
+	userRepo repository2.UserRepository,
+	log *zap.Logger,
+) AssignmentService {
+	return &assignmentService{
+		assessmentRepo: assessmentRepo,
+		assignmentRepo: assignmentRepo,
+		groupRepo:      groupRepo,
+		userRepo:       userRepo,
+		log:            log,
+	}
+}
+
+func (s *assignmentService) ListAssignments(assessmentID uint) ([]models.AssessmentAssignment, error) {
+	if err := s.ensureAssessment(assessmentID); err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.assignmentRepo.ListByAssessment(assessmentID)
+	if err != nil {
+		s.log.Error("[ListAssignments] failed to list assignments", zap.Error(err))
+		return nil, err
+	}
+
+	return assignments, nil
+}
+
+// AssignAssessment validates every group and student first so a bad ID does not leave the
+// assessment half assigned. Targets that are already assigned are skipped.
+func (s *assignmentService) AssignAssessment(assessmentID uint, dto models.AssignmentDTO, assignedByID uint) ([]models.AssessmentAssignment, error) {
+	if len(dto.GroupIDs) == 0 && len(dto.UserIDs) == 0 {
+		return nil, ErrNoAssigneesGiven
+	}
+
+	if err := s.ensureAssessment(assessmentID); err != nil {
+		return nil, err
+	}
+
+	var assignments []models.AssessmentAssignment
+
+	for _, groupID := range dto.GroupIDs {
+		if _, err := s.groupRepo.FindByID(groupID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: group %d", ErrAssigneeNotFound, groupID)
+			}
+			s.log.Error("[AssignAssessment] failed to find group", zap.Error(err))
+			return nil, err
+		}
+
+		assignments = append(assignments, models.AssessmentAssignment{
+			AssessmentID: assessmentID,
+			GroupID:      &groupID,
+			AssignedByID: assignedByID,
+		})
+	}
+
+	for _, userID := range dto.UserIDs {
+		user, err := s.userRepo.FindByID(userID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: user %d", ErrAssigneeNotFound, userID)
+			}
+			s.log.Error("[AssignAssessment] failed to find user", zap.Error(err))
+			return nil, err
+		}
+
+		if user.Role != "student" {
+			return nil, fmt.Errorf("%w: user %d", ErrAssigneeNotStudent, userID)
+		}
+
+		assignments = append(assignments, models.AssessmentAssignment{
+			AssessmentID: assessmentID,
+			UserID:       &userID,
+			AssignedByID: assignedByID,
+		})
+	}
+
+	if err := s.assignmentRepo.Create(assignments); err != nil {
+		s.log.Error("[AssignAssessment] failed to create assignments", zap.Error(err))
+		return nil, err
+	}
+
+	return s.assignmentRepo.ListByAssessment(assessmentID)
+}
+
+func (s *assignmentService) UnassignAssessment(assessmentID, assignmentID uint) error {
+	if err := s.ensureAssessment(assessmentID); err != nil {
+		return err
+	}
+
+	removed, err := s.assignmentRepo.Delete(assessmentID, assignmentID)
+	if err != nil {
+		s.log.Error("[UnassignAssessment] failed to delete assignment", zap.Error(err))
+		return err
+	}
+
+	if !removed {
+		return ErrAssignmentNotFound
+	}
+
+	return nil
+}
+
+func (s *assignmentService) ensureAssessment(assessmentID uint) error {
+	if _, err := s.assessmentRepo.FindByID(assessmentID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAssessmentNotFound
+		}
+		s.log.Error("failed to find assessment", zap.Error(err))
+		return err
+	}
+	return nil
+}
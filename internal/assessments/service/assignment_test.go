@@ -0,0 +1,167 @@
+package service
+
+import (
+	models "assessment_service/internal/model"
+	"assessment_service/internal/util"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+// --- Mock AssignmentRepository ---
+type MockAssignmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAssignmentRepository) Create(assignments []models.AssessmentAssignment) error {
+	args := m.Called(assignments)
+	return args.Error(0)
+}
+
+func (m *MockAssignmentRepository) ListByAssessment(assessmentID uint) ([]models.AssessmentAssignment, error) {
+	args := m.Called(assessmentID)
+	assignments, _ := args.Get(0).([]models.AssessmentAssignment)
+	return assignments, args.Error(1)
+}
+
+func (m *MockAssignmentRepository) Delete(assessmentID, assignmentID uint) (bool, error) {
+	args := m.Called(assessmentID, assignmentID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAssignmentRepository) IsAssigned(assessmentID, userID uint) (bool, error) {
+	args := m.Called(assessmentID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+// --- Mock GroupRepository ---
+type MockGroupRepository struct {
+	mock.Mock
+}
+
+func (m *MockGroupRepository) Create(group *models.Group) error {
+	args := m.Called(group)
+	return args.Error(0)
+}
+
+func (m *MockGroupRepository) FindByID(id uint) (*models.Group, error) {
+	args := m.Called(id)
+	group, _ := args.Get(0).(*models.Group)
+	return group, args.Error(1)
+}
+
+func (m *MockGroupRepository) Update(group *models.Group) error {
+	args := m.Called(group)
+	return args.Error(0)
+}
+
+func (m *MockGroupRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockGroupRepository) List(params util.PaginationParams) ([]models.Group, int64, error) {
+	args := m.Called(params)
+	groups, _ := args.Get(0).([]models.Group)
+	count, _ := args.Get(1).(int64)
+	return groups, count, args.Error(2)
+}
+
+func (m *MockGroupRepository) ListMembers(groupID uint, params util.PaginationParams) ([]models.User, int64, error) {
+	args := m.Called(groupID, params)
+	users, _ := args.Get(0).([]models.User)
+	count, _ := args.Get(1).(int64)
+	return users, count, args.Error(2)
+}
+
+func (m *MockGroupRepository) FindMemberIDs(groupID uint, userIDs []uint) ([]uint, error) {
+	args := m.Called(groupID, userIDs)
+	ids, _ := args.Get(0).([]uint)
+	return ids, args.Error(1)
+}
+
+func (m *MockGroupRepository) AddMembers(groupID uint, userIDs []uint) error {
+	args := m.Called(groupID, userIDs)
+	return args.Error(0)
+}
+
+func (m *MockGroupRepository) RemoveMember(groupID, userID uint) (bool, error) {
+	args := m.Called(groupID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func newTestAssignmentService(t *testing.T) (AssignmentService, *MockAssessmentRepository, *MockAssignmentRepository, *MockGroupRepository, *MockUserRepository) {
+	assessmentRepo := new(MockAssessmentRepository)
+	assignmentRepo := new(MockAssignmentRepository)
+	groupRepo := new(MockGroupRepository)
+	userRepo := new(MockUserRepository)
+	return NewAssignmentService(assessmentRepo, assignmentRepo, groupRepo, userRepo, zaptest.NewLogger(t)), assessmentRepo, assignmentRepo, groupRepo, userRepo
+}
+
+func TestAssignAssessment(t *testing.T) {
+	service, assessmentRepo, assignmentRepo, groupRepo, userRepo := newTestAssignmentService(t)
+
+	assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+	groupRepo.On("FindByID", uint(3)).Return(&models.Group{ID: 3}, nil)
+	userRepo.On("FindByID", uint(20)).Return(&models.User{ID: 20, Role: "student"}, nil)
+	assignmentRepo.On("Create", mock.MatchedBy(func(a []models.AssessmentAssignment) bool {
+		return len(a) == 2 &&
+			*a[0].GroupID == 3 && a[0].UserID == nil &&
+			*a[1].UserID == 20 && a[1].GroupID == nil &&
+			a[0].AssignedByID == 10
+	})).Return(nil)
+	assignmentRepo.On("ListByAssessment", uint(1)).Return([]models.AssessmentAssignment{{ID: 1}, {ID: 2}}, nil)
+
+	assignments, err := service.AssignAssessment(1, models.AssignmentDTO{GroupIDs: []uint{3}, UserIDs: []uint{20}}, 10)
+
+	require.NoError(t, err)
+	assert.Len(t, assignments, 2)
+	assignmentRepo.AssertExpectations(t)
+}
+
+func TestAssignAssessment_Rejected(t *testing.T) {
+	t.Run("NoAssignees", func(t *testing.T) {
+		service, _, _, _, _ := newTestAssignmentService(t)
+
+		_, err := service.AssignAssessment(1, models.AssignmentDTO{}, 10)
+
+		assert.ErrorIs(t, err, ErrNoAssigneesGiven)
+	})
+
+	t.Run("UnknownGroup", func(t *testing.T) {
+		service, assessmentRepo, assignmentRepo, groupRepo, _ := newTestAssignmentService(t)
+		assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+		groupRepo.On("FindByID", uint(3)).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := service.AssignAssessment(1, models.AssignmentDTO{GroupIDs: []uint{3}}, 10)
+
+		assert.ErrorIs(t, err, ErrAssigneeNotFound)
+		assignmentRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Teacher", func(t *testing.T) {
+		service, assessmentRepo, assignmentRepo, _, userRepo := newTestAssignmentService(t)
+		assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+		userRepo.On("FindByID", uint(20)).Return(&models.User{ID: 20, Role: "teacher"}, nil)
+
+		_, err := service.AssignAssessment(1, models.AssignmentDTO{UserIDs: []uint{20}}, 10)
+
+		assert.ErrorIs(t, err, ErrAssigneeNotStudent)
+		assignmentRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestUnassignAssessment_NotFound(t *testing.T) {
+	service, assessmentRepo, assignmentRepo, _, _ := newTestAssignmentService(t)
+
+	assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+	assignmentRepo.On("Delete", uint(1), uint(5)).Return(false, nil)
+
+	err := service.UnassignAssessment(1, 5)
+
+	assert.ErrorIs(t, err, ErrAssignmentNotFound)
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"assessment_service/internal/assessments/policy"
+	"assessment_service/internal/assessments/repository"
+	models "assessment_service/internal/model"
+	repository2 "assessment_service/internal/users/repository"
+	"errors"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAssessmentNotFound      = errors.New("assessment not found")
+	ErrCollaboratorNotFound    = errors.New("collaborator not found")
+	ErrCollaboratorExists      = errors.New("user is already a collaborator on this assessment")
+	ErrInvalidCollaboratorRole = errors.New("role must be one of owner, editor, grader, viewer")
+	ErrInviteeNotFound         = errors.New("invited user not found")
+	ErrInviteeNotStaff         = errors.New("only teachers and admins can collaborate on assessments")
+	ErrInviteeIsCreator        = errors.New("the creator of an assessment is always its owner")
+)
+
+type CollaboratorService interface {
+	ListCollaborators(assessmentID uint) ([]models.AssessmentCollaborator, error)
+	AddCollaborator(assessmentID uint, invite models.CollaboratorInviteDTO, invitedByID uint) (*models.AssessmentCollaborator, error)
+	UpdateCollaboratorRole(assessmentID, userID uint, role string) (*models.AssessmentCollaborator, error)
+	RemoveCollaborator(assessmentID, userID uint) error
+}
+
+type collaboratorService struct {
+	assessmentRepo   repository.AssessmentRepository
+	collaboratorRepo repository.CollaboratorRepository
+	userRepo         repository2.UserRepository
+	log              *zap.Logger
+}
+
+func NewCollaboratorService(
+	assessmentRepo repository.AssessmentRepository,
+	collaboratorRepo repository.CollaboratorRepository,
+	userRepo repository2.UserRepository,
+	log *zap.Logger,
+) CollaboratorService {
+	return &collaboratorService{
+		assessmentRepo:   assessmentRepo,
+		collaboratorRepo: collaboratorRepo,
+		userRepo:         userRepo,
+		log:              log,
+	}
+}
+
+func (s *collaboratorService) ListCollaborators(assessmentID uint) ([]models.AssessmentCollaborator, error) {
+	if _, err := s.findAssessment(assessmentID); err != nil {
+		return nil, err
+	}
+
+	collaborators, err := s.collaboratorRepo.ListByAssessment(assessmentID)
+	if err != nil {
+		s.log.Error("[ListCollaborators] failed to list collaborators", zap.Error(err))
+		return nil, err
+	}
+
+	return collaborators, nil
+}
+
+func (s *collaboratorService) AddCollaborator(assessmentID uint, invite models.CollaboratorInviteDTO, invitedByID uint) (*models.AssessmentCollaborator, error) {
+	role := strings.ToLower(strings.TrimSpace(invite.Role))
+	if !policy.IsCollaboratorRole(role) {
+		return nil, ErrInvalidCollaboratorRole
+	}
+
+	assessment, err := s.findAssessment(assessmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.findInvitee(invite)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.ID == assessment.CreatedByID {
+		return nil, ErrInviteeIsCreator
+	}
+
+	if user.Role != "teacher" && user.Role != "admin" {
+		return nil, ErrInviteeNotStaff
+	}
+
+	existing, err := s.collaboratorRepo.FindByAssessmentAndUser(assessmentID, user.ID)
+	if err != nil {
+		s.log.Error("[AddCollaborator] failed to check collaborator", zap.Error(err))
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrCollaboratorExists
+	}
+
+	collaborator := &models.AssessmentCollaborator{
+		AssessmentID: assessmentID,
+		UserID:       user.ID,
+		User:         *user,
+		Role:         role,
+		InvitedByID:  invitedByID,
+	}
+
+	if err := s.collaboratorRepo.Create(collaborator); err != nil {
+		s.log.Error("[AddCollaborator] failed to add collaborator", zap.Error(err))
+		return nil, err
+	}
+
+	return collaborator, nil
+}
+
+func (s *collaboratorService) UpdateCollaboratorRole(assessmentID, userID uint, role string) (*models.AssessmentCollaborator, error) {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if !policy.IsCollaboratorRole(role) {
+		return nil, ErrInvalidCollaboratorRole
+	}
+
+	collaborator, err := s.findCollaborator(assessmentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.collaboratorRepo.UpdateRole(assessmentID, userID, role); err != nil {
+		s.log.Error("[UpdateCollaboratorRole] failed to update role", zap.Error(err))
+		return nil, err
+	}
+
+	collaborator.Role = role
+	return collaborator, nil
+}
+
+func (s *collaboratorService) RemoveCollaborator(assessmentID, userID uint) error {
+	if _, err := s.findAssessment(assessmentID); err != nil {
+		return err
+	}
+
+	removed, err := s.collaboratorRepo.Delete(assessmentID, userID)
+	if err != nil {
+		s.log.Error("[RemoveCollaborator] failed to remove collaborator", zap.Error(err))
+		return err
+	}
+
+	if !removed {
+		return ErrCollaboratorNotFound
+	}
+
+	return nil
+}
+
+func (s *collaboratorService) findAssessment(assessmentID uint) (*models.Assessment, error) {
+	assessment, err := s.assessmentRepo.FindByID(assessmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAssessmentNotFound
+		}
+		s.log.Error("failed to find assessment", zap.Error(err))
+		return nil, err
+	}
+
+	return assessment, nil
+}
+
+func (s *collaboratorService) findCollaborator(assessmentID, userID uint) (*models.AssessmentCollaborator, error) {
+	if _, err := s.findAssessment(assessmentID); err != nil {
+		return nil, err
+	}
+
+	collaborator, err := s.collaboratorRepo.FindByAssessmentAndUser(assessmentID, userID)
+	if err != nil {
+		s.log.Error("failed to find collaborator", zap.Error(err))
+		return nil, err
+	}
+
+	if collaborator == nil {
+		return nil, ErrCollaboratorNotFound
+	}
+
+	return collaborator, nil
+}
+
+func (s *collaboratorService) findInvitee(invite models.CollaboratorInviteDTO) (*models.User, error) {
+	var (
+		user *models.User
+		err  error
+	)
+
+	switch {
+	case invite.UserID != 0:
+		user, err = s.userRepo.FindByID(invite.UserID)
+	case strings.TrimSpace(invite.Email) != "":
+		user, err = s.userRepo.FindByEmail(strings.ToLower(strings.TrimSpace(invite.Email)))
+	default:
+		return nil, ErrInviteeNotFound
+	}
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInviteeNotFound
+		}
+		s.log.Error("failed to find invited user", zap.Error(err))
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, ErrInviteeNotFound
+	}
+
+	return user, nil
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	models "assessment_service/internal/model"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+// --- Mock CollaboratorRepository ---
+type MockCollaboratorRepository struct {
+	mock.Mock
+}
+
+func (m *MockCollaboratorRepository) Create(collaborator *models.AssessmentCollaborator) error {
+	args := m.Called(collaborator)
+	return args.Error(0)
+}
+
+func (m *MockCollaboratorRepository) FindByAssessmentAndUser(assessmentID, userID uint) (*models.AssessmentCollaborator, error) {
+	args := m.Called(assessmentID, userID)
+	collaborator, _ := args.Get(0).(*models.AssessmentCollaborator)
+	return collaborator, args.Error(1)
+}
+
+func (m *MockCollaboratorRepository) ListByAssessment(assessmentID uint) ([]models.AssessmentCollaborator, error) {
+	args := m.Called(assessmentID)
+	collaborators, _ := args.Get(0).([]models.AssessmentCollaborator)
+	return collaborators, args.Error(1)
+}
+
+func (m *MockCollaboratorRepository) UpdateRole(assessmentID, userID uint, role string) error {
+	args := m.Called(assessmentID, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockCollaboratorRepository) Delete(assessmentID, userID uint) (bool, error) {
+	args := m.Called(assessmentID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func newTestCollaboratorService(t *testing.T) (CollaboratorService, *MockAssessmentRepository, *MockCollaboratorRepository, *MockUserRepository) {
+	assessmentRepo := new(MockAssessmentRepository)
+	collaboratorRepo := new(MockCollaboratorRepository)
+	userRepo := new(MockUserRepository)
+	return NewCollaboratorService(assessmentRepo, collaboratorRepo, userRepo, zaptest.NewLogger(t)), assessmentRepo, collaboratorRepo, userRepo
+}
+
+func TestAddCollaborator_ByEmail(t *testing.T) {
+	service, assessmentRepo, collaboratorRepo, userRepo := newTestCollaboratorService(t)
+	invitee := &models.User{ID: 20, Email: "coauthor@example.com", Role: "teacher"}
+
+	assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1, CreatedByID: 10}, nil)
+	userRepo.On("FindByEmail", "coauthor@example.com").Return(invitee, nil)
+	collaboratorRepo.On("FindByAssessmentAndUser", uint(1), uint(20)).Return(nil, nil)
+	collaboratorRepo.On("Create", mock.MatchedBy(func(c *models.AssessmentCollaborator) bool {
+		return c.AssessmentID == 1 && c.UserID == 20 && c.Role == "editor" && c.InvitedByID == 10
+	})).Return(nil)
+
+	collaborator, err := service.AddCollaborator(1, models.CollaboratorInviteDTO{Email: " CoAuthor@example.com ", Role: "Editor"}, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "editor", collaborator.Role)
+	assessmentRepo.AssertExpectations(t)
+	userRepo.AssertExpectations(t)
+	collaboratorRepo.AssertExpectations(t)
+}
+
+func TestAddCollaborator_Rejected(t *testing.T) {
+	cases := []struct {
+		name    string
+		invite  models.CollaboratorInviteDTO
+		invitee *models.User
+		existed bool
+		wantErr error
+	}{
+		{"InvalidRole", models.CollaboratorInviteDTO{UserID: 20, Role: "superuser"}, nil, false, ErrInvalidCollaboratorRole},
+		{"NoInvitee", models.CollaboratorInviteDTO{Role: "viewer"}, nil, false, ErrInviteeNotFound},
+		{"Student", models.CollaboratorInviteDTO{UserID: 20, Role: "viewer"}, &models.User{ID: 20, Role: "student"}, false, ErrInviteeNotStaff},
+		{"Creator", models.CollaboratorInviteDTO{UserID: 10, Role: "viewer"}, &models.User{ID: 10, Role: "teacher"}, false, ErrInviteeIsCreator},
+		{"AlreadyCollaborator", models.CollaboratorInviteDTO{UserID: 20, Role: "viewer"}, &models.User{ID: 20, Role: "teacher"}, true, ErrCollaboratorExists},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, assessmentRepo, collaboratorRepo, userRepo := newTestCollaboratorService(t)
+
+			assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1, CreatedByID: 10}, nil).Maybe()
+			if tc.invitee != nil {
+				userRepo.On("FindByID", tc.invitee.ID).Return(tc.invitee, nil)
+			}
+			if tc.existed {
+				collaboratorRepo.On("FindByAssessmentAndUser", uint(1), uint(20)).Return(&models.AssessmentCollaborator{Role: "viewer"}, nil)
+			}
+
+			collaborator, err := service.AddCollaborator(1, tc.invite, 10)
+
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Nil(t, collaborator)
+			collaboratorRepo.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+}
+
+func TestAddCollaborator_InviteeNotFound(t *testing.T) {
+	service, assessmentRepo, _, userRepo := newTestCollaboratorService(t)
+
+	assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1, CreatedByID: 10}, nil)
+	userRepo.On("FindByID", uint(99)).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := service.AddCollaborator(1, models.CollaboratorInviteDTO{UserID: 99, Role: "viewer"}, 10)
+
+	assert.ErrorIs(t, err, ErrInviteeNotFound)
+}
+
+func TestListCollaborators_AssessmentNotFound(t *testing.T) {
+	service, assessmentRepo, collaboratorRepo, _ := newTestCollaboratorService(t)
+
+	assessmentRepo.On("FindByID", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := service.ListCollaborators(1)
+
+	assert.ErrorIs(t, err, ErrAssessmentNotFound)
+	collaboratorRepo.AssertNotCalled(t, "ListByAssessment", mock.Anything)
+}
+
+func TestUpdateCollaboratorRole(t *testing.T) {
+	service, assessmentRepo, collaboratorRepo, _ := newTestCollaboratorService(t)
+
+	assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1, CreatedByID: 10}, nil)
+	collaboratorRepo.On("FindByAssessmentAndUser", uint(1), uint(20)).Return(&models.AssessmentCollaborator{AssessmentID: 1, UserID: 20, Role: "viewer"}, nil)
+	collaboratorRepo.On("UpdateRole", uint(1), uint(20), "grader").Return(nil)
+
+	collaborator, err := service.UpdateCollaboratorRole(1, 20, "grader")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "grader", collaborator.Role)
+	collaboratorRepo.AssertExpectations(t)
+}
+
+func TestUpdateCollaboratorRole_NotCollaborator(t *testing.T) {
+	service, assessmentRepo, collaboratorRepo, _ := newTestCollaboratorService(t)
+
+	assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1, CreatedByID: 10}, nil)
+	collaboratorRepo.On("FindByAssessmentAndUser", uint(1), uint(20)).Return(nil, nil)
+
+	_, err := service.UpdateCollaboratorRole(1, 20, "grader")
+
+	assert.ErrorIs(t, err, ErrCollaboratorNotFound)
+	collaboratorRepo.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRemoveCollaborator(t *testing.T) {
+	service, assessmentRepo, collaboratorRepo, _ := newTestCollaboratorService(t)
+
+	assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1, CreatedByID: 10}, nil)
+	collaboratorRepo.On("Delete", uint(1), uint(20)).Return(true, nil).Once()
+	collaboratorRepo.On("Delete", uint(1), uint(21)).Return(false, nil).Once()
+	collaboratorRepo.On("Delete", uint(1), uint(22)).Return(false, errors.New("db error")).Once()
+
+	assert.NoError(t, service.RemoveCollaborator(1, 20))
+	assert.ErrorIs(t, service.RemoveCollaborator(1, 21), ErrCollaboratorNotFound)
+	assert.EqualError(t, service.RemoveCollaborator(1, 22), "db error")
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	models "assessment_service/internal/model"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func (s *assessmentService) Publish(id, changedByID uint) (*models.Assessment, error) {
+	assessment, err := s.findAssessment(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Closed assessments also move to Active, but that is Reopen
+	if assessment.Status != models.AssessmentDraft {
+		return nil, fmt.Errorf("%w: only drafts can be published", ErrInvalidTransition)
+	}
+
+	if len(assessment.Questions) == 0 {
+		return nil, ErrNoQuestions
+	}
+
+	if err := checkWindowOpen(assessment, time.Now()); err != nil {
+		return nil, err
+	}
+
+	to := models.AssessmentActive
+	if assessment.AvailableFrom != nil && assessment.AvailableFrom.After(time.Now()) {
+		to = models.AssessmentScheduled
+	}
+
+	return s.transition(assessment, to, changedByID, "")
+}
+
+func (s *assessmentService) Close(id, changedByID uint, reason string) (*models.Assessment, error) {
+	assessment, err := s.findAssessment(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.transition(assessment, models.AssessmentClosed, changedByID, reason)
+}
+
+// Reopen makes a closed assessment Active again. It stays open only while its window, or an
+// accommodation, still lets someone submit; after that the scheduler closes it again.
+func (s *assessmentService) Reopen(id, changedByID uint, reason string) (*models.Assessment, error) {
+	assessment, err := s.findAssessment(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.transition(assessment, models.AssessmentActive, changedByID, reason)
+}
+
+func (s *assessmentService) Archive(id, changedByID uint, reason string) (*models.Assessment, error) {
+	assessment, err := s.findAssessment(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.transition(assessment, models.AssessmentArchived, changedByID, reason)
+}
+
+func (s *assessmentService) GetStatusHistory(id uint) ([]models.AssessmentStatusTransition, error) {
+	if _, err := s.findAssessment(id); err != nil {
+		return nil, err
+	}
+
+	transitions, err := s.assessmentRepo.ListStatusHistory(id)
+	if err != nil {
+		s.log.Error("[GetStatusHistory] failed to list status history", zap.Error(err))
+		return nil, err
+	}
+
+	return transitions, nil
+}
+
+func (s *assessmentService) UpdateScheduledStatuses() error {
+	now := time.Now()
+
+	activated, err := s.assessmentRepo.ActivateScheduled(now)
+	if err != nil {
+		s.log.Error("[UpdateScheduledStatuses] failed to activate scheduled assessments", zap.Error(err))
+		return err
+	}
+
+	closed, err := s.assessmentRepo.CloseEnded(now)
+	if err != nil {
+		s.log.Error("[UpdateScheduledStatuses] failed to close ended assessments", zap.Error(err))
+		return err
+	}
+
+	if activated > 0 || closed > 0 {
+		s.log.Info("[UpdateScheduledStatuses] assessment statuses updated",
+			zap.Int64("activated", activated), zap.Int64("closed", closed))
+	}
+
+	return nil
+}
+
+// transition moves the assessment to status to and records who did it
+func (s *assessmentService) transition(assessment *models.Assessment, to models.AssessmentStatus, changedByID uint, reason string) (*models.Assessment, error) {
+	from := assessment.Status
+	if !from.CanTransitionTo(to) {
+		return nil, fmt.Errorf("%w: a %s assessment cannot become %s", ErrInvalidTransition, from, to)
+	}
+
+	changed, err := s.assessmentRepo.UpdateStatus(&models.AssessmentStatusTransition{
+		AssessmentID: assessment.ID,
+		FromStatus:   from,
+		ToStatus:     to,
+		ChangedByID:  &changedByID,
+		Reason:       reason,
+	})
+	if err != nil {
+		s.log.Error("[transition] failed to update assessment status", zap.Error(err))
+		return nil, err
+	}
+
+	// Someone else changed the status since we read it
+	if !changed {
+		return nil, fmt.Errorf("%w: the assessment is no longer %s", ErrInvalidTransition, from)
+	}
+
+	assessment.Status = to
+	return assessment, nil
+}
+
+func (s *assessmentService) findAssessment(id uint) (*models.Assessment, error) {
+	assessment, err := s.assessmentRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAssessmentNotFound
+		}
+		return nil, err
+	}
+	return assessment, nil
+}
+
+// checkWindowOpen rejects publishing an assessment that has already stopped accepting work
+func checkWindowOpen(assessment *models.Assessment, now time.Time) error {
+	if acceptsUntil := assessment.AcceptsUntil(); acceptsUntil != nil && acceptsUntil.Before(now) {
+		return fmt.Errorf("%w: the availability window has already ended", ErrInvalidSchedule)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	models "assessment_service/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+func TestAssessmentStatusTransitions(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    models.AssessmentStatus
+		change  func(s AssessmentService) (*models.Assessment, error)
+		to      models.AssessmentStatus
+		wantErr bool
+	}{
+		{name: "close active", from: models.AssessmentActive, to: models.AssessmentClosed,
+			change: func(s AssessmentService) (*models.Assessment, error) { return s.Close(1, 7, "done early") }},
+		{name: "close scheduled", from: models.AssessmentScheduled, to: models.AssessmentClosed,
+			change: func(s AssessmentService) (*models.Assessment, error) { return s.Close(1, 7, "") }},
+		{name: "reopen closed", from: models.AssessmentClosed, to: models.AssessmentActive,
+			change: func(s AssessmentService) (*models.Assessment, error) { return s.Reopen(1, 7, "") }},
+		{name: "archive closed", from: models.AssessmentClosed, to: models.AssessmentArchived,
+			change: func(s AssessmentService) (*models.Assessment, error) { return s.Archive(1, 7, "") }},
+		{name: "archive draft", from: models.AssessmentDraft, to: models.AssessmentArchived,
+			change: func(s AssessmentService) (*models.Assessment, error) { return s.Archive(1, 7, "") }},
+		{name: "close draft", from: models.AssessmentDraft, wantErr: true,
+			change: func(s AssessmentService) (*models.Assessment, error) { return s.Close(1, 7, "") }},
+		{name: "reopen active", from: models.AssessmentActive, wantErr: true,
+			change: func(s AssessmentService) (*models.Assessment, error) { return s.Reopen(1, 7, "") }},
+		{name: "archive active", from: models.AssessmentActive, wantErr: true,
+			change: func(s AssessmentService) (*models.Assessment, error) { return s.Archive(1, 7, "") }},
+		{name: "reopen archived", from: models.AssessmentArchived, wantErr: true,
+			change: func(s AssessmentService) (*models.Assessment, error) { return s.Reopen(1, 7, "") }},
+		{name: "publish closed", from: models.AssessmentClosed, wantErr: true,
+			change: func(s AssessmentService) (*models.Assessment, error) { return s.Publish(1, 7) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAssessmentRepo := new(MockAssessmentRepository)
+			service := NewAssessmentService(mockAssessmentRepo, new(MockUserRepository), zaptest.NewLogger(t))
+
+			mockAssessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1, Status: tt.from, Questions: []models.Question{{ID: 1}}}, nil)
+			mockAssessmentRepo.On("UpdateStatus", mock.MatchedBy(func(tr *models.AssessmentStatusTransition) bool {
+				return tr.FromStatus == tt.from && tr.ToStatus == tt.to && *tr.ChangedByID == 7
+			})).Return(true, nil)
+
+			assessment, err := tt.change(service)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidTransition)
+				mockAssessmentRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.to, assessment.Status)
+			mockAssessmentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCloseAssessment_RecordsReason(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	service := NewAssessmentService(mockAssessmentRepo, new(MockUserRepository), zaptest.NewLogger(t))
+
+	mockAssessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1, Status: models.AssessmentActive}, nil)
+	mockAssessmentRepo.On("UpdateStatus", mock.MatchedBy(func(tr *models.AssessmentStatusTransition) bool {
+		return tr.Reason == "exam leaked"
+	})).Return(true, nil)
+
+	_, err := service.Close(1, 7, "exam leaked")
+
+	assert.NoError(t, err)
+	mockAssessmentRepo.AssertExpectations(t)
+}
+
+func TestCloseAssessment_ChangedConcurrently(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	service := NewAssessmentService(mockAssessmentRepo, new(MockUserRepository), zaptest.NewLogger(t))
+
+	mockAssessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1, Status: models.AssessmentActive}, nil)
+	// Scheduler đã đóng bài trước khi cập nhật
+	mockAssessmentRepo.On("UpdateStatus", mock.Anything).Return(false, nil)
+
+	_, err := service.Close(1, 7, "")
+
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+}
+
+func TestCloseAssessment_NotFound(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	service := NewAssessmentService(mockAssessmentRepo, new(MockUserRepository), zaptest.NewLogger(t))
+
+	mockAssessmentRepo.On("FindByID", uint(99)).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := service.Close(99, 7, "")
+
+	assert.ErrorIs(t, err, ErrAssessmentNotFound)
+}
+
+func TestGetStatusHistory(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	service := NewAssessmentService(mockAssessmentRepo, new(MockUserRepository), zaptest.NewLogger(t))
+
+	history := []models.AssessmentStatusTransition{
+		{ID: 1, AssessmentID: 1, FromStatus: models.AssessmentDraft, ToStatus: models.AssessmentActive},
+		{ID: 2, AssessmentID: 1, FromStatus: models.AssessmentActive, ToStatus: models.AssessmentClosed},
+	}
+	mockAssessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+	mockAssessmentRepo.On("ListStatusHistory", uint(1)).Return(history, nil)
+
+	result, err := service.GetStatusHistory(1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, history, result)
+
+	mockAssessmentRepo.On("FindByID", uint(2)).Return(&models.Assessment{ID: 2}, nil)
+	mockAssessmentRepo.On("ListStatusHistory", uint(2)).Return(nil, errors.New("db error"))
+
+	_, err = service.GetStatusHistory(2)
+	assert.Error(t, err)
+}
@@ -2,11 +2,14 @@ package delivery
 
 import (
 	"assessment_service/internal/attempts/service"
+	"assessment_service/internal/middleware"
 	models "assessment_service/internal/model"
 	"assessment_service/internal/util"
 	"encoding/json"
+	"errors"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
+	"io"
 	"net/http"
 	"strconv"
 )
@@ -86,6 +89,15 @@ func (h *AttemptHandler) GetAttemptDetail(w http.ResponseWriter, r *http.Request
 }
 
 func (h *AttemptHandler) GradeAttempt(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "UNAUTHORIZED",
+			"message": "User ID not found in context",
+		}, http.StatusUnauthorized)
+		return
+	}
+
 	// Get attempt ID from path
 	id, err := strconv.ParseUint(mux.Vars(r)["attemptID"], 10, 32)
 	if err != nil {
@@ -107,18 +119,707 @@ func (h *AttemptHandler) GradeAttempt(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.attemptService.GradeAttempt(newAttempt, uint(id))
+	err = h.attemptService.GradeAttempt(newAttempt, uint(id), principal.UserID)
+	if err != nil {
+		h.writeError(w, "GradeAttempt", err, "Failed to grade attempt")
+		return
+	}
+
+	util.ResponseMap(w, map[string]interface{}{
+		"status":  "OK",
+		"message": "Successfully graded attempt",
+	}, http.StatusOK)
+}
+
+func (h *AttemptHandler) VoidAttempt(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["attemptID"], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid attempt ID",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.attemptService.VoidAttempt(uint(id)); err != nil {
+		h.writeError(w, "VoidAttempt", err, "Failed to void attempt")
+		return
+	}
+
+	util.ResponseMap(w, map[string]interface{}{
+		"status":  "OK",
+		"message": "Successfully voided attempt",
+	}, http.StatusOK)
+}
+
+// GetGradingQueue lists answers waiting for a grader. Teachers only see answers to assessments they
+// may grade.
+func (h *AttemptHandler) GetGradingQueue(w http.ResponseWriter, r *http.Request) {
+	params := util.GetPaginationParams(r)
+
+	if assessmentID := r.URL.Query().Get("assessmentId"); assessmentID != "" {
+		id, err := strconv.ParseUint(assessmentID, 10, 32)
+		if err != nil {
+			util.ResponseMap(w, map[string]interface{}{
+				"status":  "BAD_REQUEST",
+				"message": "Invalid assessment ID",
+			}, http.StatusBadRequest)
+			return
+		}
+		params.Filters["assessmentId"] = uint(id)
+	}
+
+	if principal, ok := middleware.PrincipalFromRequest(r); ok && !principal.IsAdmin() {
+		params.Filters["gradableBy"] = principal.UserID
+	}
+
+	answers, total, err := h.attemptService.GetGradingQueue(params)
+	if err != nil {
+		h.log.Error("[GetGradingQueue] failed to get grading queue", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "ERROR",
+			"message": "Failed to get grading queue",
+		}, http.StatusInternalServerError)
+		return
+	}
+
+	util.ResponseInterface(w, util.CreatePaginationResponse(answers, total, params), http.StatusOK)
+}
+
+func (h *AttemptHandler) GradeAnswer(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "UNAUTHORIZED",
+			"message": "User ID not found in context",
+		}, http.StatusUnauthorized)
+		return
+	}
+
+	attemptID, err := strconv.ParseUint(mux.Vars(r)["attemptID"], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid attempt ID",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	answerID, err := strconv.ParseUint(mux.Vars(r)["answerID"], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid answer ID",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	var grade models.AnswerGradeInput
+	if err := json.NewDecoder(r.Body).Decode(&grade); err != nil {
+		h.log.Error("[GradeAnswer] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	attempt, err := h.attemptService.GradeAnswer(uint(attemptID), uint(answerID), principal.UserID, grade)
+	if err != nil {
+		h.writeError(w, "GradeAnswer", err, "Failed to grade answer")
+		return
+	}
+
+	util.ResponseInterface(w, attempt, http.StatusOK)
+}
+
+// ReconcileAnswer settles a double-marked answer with a final mark
+func (h *AttemptHandler) ReconcileAnswer(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "UNAUTHORIZED",
+			"message": "User ID not found in context",
+		}, http.StatusUnauthorized)
+		return
+	}
+
+	attemptID, err := strconv.ParseUint(mux.Vars(r)["attemptID"], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid attempt ID",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	answerID, err := strconv.ParseUint(mux.Vars(r)["answerID"], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid answer ID",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	var grade models.AnswerGradeInput
+	if err := json.NewDecoder(r.Body).Decode(&grade); err != nil {
+		h.log.Error("[ReconcileAnswer] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	attempt, err := h.attemptService.ReconcileAnswer(uint(attemptID), uint(answerID), principal.UserID, grade)
+	if err != nil {
+		h.writeError(w, "ReconcileAnswer", err, "Failed to reconcile answer")
+		return
+	}
+
+	util.ResponseInterface(w, attempt, http.StatusOK)
+}
+
+// AssignGraders hands out the ungraded answers of an assessment to graders
+func (h *AttemptHandler) AssignGraders(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid assessment ID",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	var request models.GraderAssignmentDTO
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.log.Error("[AssignGraders] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	assignments, err := h.attemptService.AssignGraders(uint(id), request)
+	if err != nil {
+		h.writeError(w, "AssignGraders", err, "Failed to assign graders")
+		return
+	}
+
+	util.ResponseInterface(w, map[string]interface{}{
+		"assigned":    len(assignments),
+		"assignments": assignments,
+	}, http.StatusCreated)
+}
+
+// GetGraderAssignments lists the answers handed out to the current user. The "status" query
+// parameter limits the list to assigned or completed work.
+func (h *AttemptHandler) GetGraderAssignments(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "UNAUTHORIZED",
+			"message": "User ID not found in context",
+		}, http.StatusUnauthorized)
+		return
+	}
+
+	params := util.GetPaginationParams(r)
+
+	switch status := models.GradingAssignmentStatus(r.URL.Query().Get("status")); status {
+	case "":
+	case models.GradingAssigned, models.GradingCompleted:
+		params.Filters["status"] = status
+	default:
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "status must be one of assigned, completed",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	if assessmentID := r.URL.Query().Get("assessmentId"); assessmentID != "" {
+		id, err := strconv.ParseUint(assessmentID, 10, 32)
+		if err != nil {
+			util.ResponseMap(w, map[string]interface{}{
+				"status":  "BAD_REQUEST",
+				"message": "Invalid assessment ID",
+			}, http.StatusBadRequest)
+			return
+		}
+		params.Filters["assessmentId"] = uint(id)
+	}
+
+	assignments, total, err := h.attemptService.GetGraderAssignments(principal.UserID, params)
+	if err != nil {
+		h.log.Error("[GetGraderAssignments] failed to get grading assignments", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "ERROR",
+			"message": "Failed to get grading assignments",
+		}, http.StatusInternalServerError)
+		return
+	}
+
+	util.ResponseInterface(w, util.CreatePaginationResponse(assignments, total, params), http.StatusOK)
+}
+
+func (h *AttemptHandler) GetGradingProgress(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid assessment ID",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	progress, err := h.attemptService.GetGradingProgress(uint(id))
+	if err != nil {
+		h.writeError(w, "GetGradingProgress", err, "Failed to get grading progress")
+		return
+	}
+
+	util.ResponseInterface(w, progress, http.StatusOK)
+}
+
+// RescoreAssessment scores the submitted answers of an assessment again and reports which attempts
+// changed. The body may name the questions to score again, otherwise every question is.
+func (h *AttemptHandler) RescoreAssessment(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "UNAUTHORIZED",
+			"message": "User ID not found in context",
+		}, http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid assessment ID",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	var request models.RescoreDTO
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		h.log.Error("[RescoreAssessment] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	summary, err := h.attemptService.RescoreAssessment(uint(id), principal.UserID, request.QuestionIDs)
+	if err != nil {
+		h.writeError(w, "RescoreAssessment", err, "Failed to rescore assessment")
+		return
+	}
+
+	util.ResponseInterface(w, summary, http.StatusOK)
+}
+
+// GetGradeEvents returns the grade history of an attempt
+func (h *AttemptHandler) GetGradeEvents(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["attemptID"], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid attempt ID",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	events, err := h.attemptService.GetGradeEvents(uint(id))
+	if err != nil {
+		h.writeError(w, "GetGradeEvents", err, "Failed to get grade history")
+		return
+	}
+
+	util.ResponseInterface(w, events, http.StatusOK)
+}
+
+// ListRegradeRequests lists students' regrade requests, pending ones unless the "status" query
+// parameter asks for another status. Teachers only see requests for assessments they may grade.
+func (h *AttemptHandler) ListRegradeRequests(w http.ResponseWriter, r *http.Request) {
+	params := util.GetPaginationParams(r)
+
+	switch status := models.RegradeRequestStatus(r.URL.Query().Get("status")); status {
+	case "":
+		params.Filters["status"] = models.RegradePending
+	case models.RegradePending, models.RegradeAccepted, models.RegradeRejected:
+		params.Filters["status"] = status
+	default:
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "status must be one of pending, accepted, rejected",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	if assessmentID := r.URL.Query().Get("assessmentId"); assessmentID != "" {
+		id, err := strconv.ParseUint(assessmentID, 10, 32)
+		if err != nil {
+			util.ResponseMap(w, map[string]interface{}{
+				"status":  "BAD_REQUEST",
+				"message": "Invalid assessment ID",
+			}, http.StatusBadRequest)
+			return
+		}
+		params.Filters["assessmentId"] = uint(id)
+	}
+
+	if principal, ok := middleware.PrincipalFromRequest(r); ok && !principal.IsAdmin() {
+		params.Filters["gradableBy"] = principal.UserID
+	}
+
+	requests, total, err := h.attemptService.ListRegradeRequests(params)
 	if err != nil {
-		h.log.Error("[GradeAttempt] failed to grade attempt", zap.Error(err))
+		h.log.Error("[ListRegradeRequests] failed to get regrade requests", zap.Error(err))
 		util.ResponseMap(w, map[string]interface{}{
 			"status":  "ERROR",
-			"message": "Failed to grade attempt",
+			"message": "Failed to get regrade requests",
 		}, http.StatusInternalServerError)
 		return
 	}
 
+	util.ResponseInterface(w, util.CreatePaginationResponse(requests, total, params), http.StatusOK)
+}
+
+// AcceptRegradeRequest regrades an attempt in answer to a student's regrade request
+func (h *AttemptHandler) AcceptRegradeRequest(w http.ResponseWriter, r *http.Request) {
+	principal, attemptID, requestID, ok := h.regradeRequestVars(w, r)
+	if !ok {
+		return
+	}
+
+	var decision models.RegradeDecisionDTO
+	if err := json.NewDecoder(r.Body).Decode(&decision); err != nil {
+		h.log.Error("[AcceptRegradeRequest] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	request, err := h.attemptService.AcceptRegradeRequest(attemptID, requestID, principal.UserID, decision)
+	if err != nil {
+		h.writeError(w, "AcceptRegradeRequest", err, "Failed to accept regrade request")
+		return
+	}
+
+	util.ResponseInterface(w, request, http.StatusOK)
+}
+
+// RejectRegradeRequest closes a student's regrade request without changing the grade
+func (h *AttemptHandler) RejectRegradeRequest(w http.ResponseWriter, r *http.Request) {
+	principal, attemptID, requestID, ok := h.regradeRequestVars(w, r)
+	if !ok {
+		return
+	}
+
+	var decision models.RegradeDecisionDTO
+	if err := json.NewDecoder(r.Body).Decode(&decision); err != nil {
+		h.log.Error("[RejectRegradeRequest] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	request, err := h.attemptService.RejectRegradeRequest(attemptID, requestID, principal.UserID, decision.Response)
+	if err != nil {
+		h.writeError(w, "RejectRegradeRequest", err, "Failed to reject regrade request")
+		return
+	}
+
+	util.ResponseInterface(w, request, http.StatusOK)
+}
+
+// regradeRequestVars reads the reviewer and the IDs of a regrade request route, writing the error
+// response when one is missing or invalid
+func (h *AttemptHandler) regradeRequestVars(w http.ResponseWriter, r *http.Request) (*middleware.Principal, uint, uint, bool) {
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "UNAUTHORIZED",
+			"message": "User ID not found in context",
+		}, http.StatusUnauthorized)
+		return nil, 0, 0, false
+	}
+
+	attemptID, err := strconv.ParseUint(mux.Vars(r)["attemptID"], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid attempt ID",
+		}, http.StatusBadRequest)
+		return nil, 0, 0, false
+	}
+
+	requestID, err := strconv.ParseUint(mux.Vars(r)["requestID"], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid regrade request ID",
+		}, http.StatusBadRequest)
+		return nil, 0, 0, false
+	}
+
+	return principal, uint(attemptID), uint(requestID), true
+}
+
+// PauseAttempt stops the clock of a student's attempt in progress
+func (h *AttemptHandler) PauseAttempt(w http.ResponseWriter, r *http.Request) {
+	principal, attemptID, request, ok := h.timeChangeVars(w, r, "PauseAttempt")
+	if !ok {
+		return
+	}
+
+	attempt, err := h.attemptService.PauseAttempt(attemptID, principal.UserID, request.Reason)
+	if err != nil {
+		h.writeError(w, "PauseAttempt", err, "Failed to pause attempt")
+		return
+	}
+
+	util.ResponseInterface(w, attempt, http.StatusOK)
+}
+
+// ResumeAttempt starts the clock of a paused attempt again
+func (h *AttemptHandler) ResumeAttempt(w http.ResponseWriter, r *http.Request) {
+	principal, attemptID, request, ok := h.timeChangeVars(w, r, "ResumeAttempt")
+	if !ok {
+		return
+	}
+
+	attempt, err := h.attemptService.ResumeAttempt(attemptID, principal.UserID, request.Reason)
+	if err != nil {
+		h.writeError(w, "ResumeAttempt", err, "Failed to resume attempt")
+		return
+	}
+
+	util.ResponseInterface(w, attempt, http.StatusOK)
+}
+
+// ExtendAttempt gives a student's attempt in progress more minutes
+func (h *AttemptHandler) ExtendAttempt(w http.ResponseWriter, r *http.Request) {
+	principal, attemptID, request, ok := h.timeChangeVars(w, r, "ExtendAttempt")
+	if !ok {
+		return
+	}
+
+	attempt, err := h.attemptService.ExtendAttempt(attemptID, principal.UserID, request.Minutes, request.Reason)
+	if err != nil {
+		h.writeError(w, "ExtendAttempt", err, "Failed to extend attempt")
+		return
+	}
+
+	util.ResponseInterface(w, attempt, http.StatusOK)
+}
+
+// GetTimeEvents returns the pauses, resumes and extensions of an attempt
+func (h *AttemptHandler) GetTimeEvents(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["attemptID"], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid attempt ID",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	events, err := h.attemptService.GetTimeEvents(uint(id))
+	if err != nil {
+		h.writeError(w, "GetTimeEvents", err, "Failed to get attempt time events")
+		return
+	}
+
+	util.ResponseInterface(w, events, http.StatusOK)
+}
+
+// SendMessage sends a proctor's message to the student taking an attempt
+func (h *AttemptHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "UNAUTHORIZED",
+			"message": "User ID not found in context",
+		}, http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseUint(mux.Vars(r)["attemptID"], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid attempt ID",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	var request models.ProctorMessageDTO
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.log.Error("[SendMessage] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.attemptService.SendMessage(uint(id), principal.UserID, request); err != nil {
+		h.writeError(w, "SendMessage", err, "Failed to send message")
+		return
+	}
+
 	util.ResponseMap(w, map[string]interface{}{
 		"status":  "OK",
-		"message": "Successfully graded attempt",
+		"message": "Message sent",
 	}, http.StatusOK)
 }
+
+// FlagAttempt marks an attempt for review with the optional reason in the body
+func (h *AttemptHandler) FlagAttempt(w http.ResponseWriter, r *http.Request) {
+	principal, attemptID, request, ok := h.proctorActionVars(w, r, "FlagAttempt")
+	if !ok {
+		return
+	}
+
+	attempt, err := h.attemptService.FlagAttempt(attemptID, principal.UserID, request.Reason)
+	if err != nil {
+		h.writeError(w, "FlagAttempt", err, "Failed to flag attempt")
+		return
+	}
+
+	util.ResponseInterface(w, attempt, http.StatusOK)
+}
+
+// TerminateAttempt ends an attempt in progress for the reason in the body
+func (h *AttemptHandler) TerminateAttempt(w http.ResponseWriter, r *http.Request) {
+	principal, attemptID, request, ok := h.proctorActionVars(w, r, "TerminateAttempt")
+	if !ok {
+		return
+	}
+
+	attempt, err := h.attemptService.TerminateAttempt(attemptID, principal.UserID, request.Reason)
+	if err != nil {
+		h.writeError(w, "TerminateAttempt", err, "Failed to terminate attempt")
+		return
+	}
+
+	util.ResponseInterface(w, attempt, http.StatusOK)
+}
+
+// proctorActionVars reads the proctor, the attempt ID and the optional body of a flag or terminate
+// route, writing the error response when one is missing or invalid
+func (h *AttemptHandler) proctorActionVars(w http.ResponseWriter, r *http.Request, fn string) (*middleware.Principal, uint, models.ProctorActionDTO, bool) {
+	var request models.ProctorActionDTO
+
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "UNAUTHORIZED",
+			"message": "User ID not found in context",
+		}, http.StatusUnauthorized)
+		return nil, 0, request, false
+	}
+
+	attemptID, err := strconv.ParseUint(mux.Vars(r)["attemptID"], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid attempt ID",
+		}, http.StatusBadRequest)
+		return nil, 0, request, false
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		h.log.Error("["+fn+"] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return nil, 0, request, false
+	}
+
+	return principal, uint(attemptID), request, true
+}
+
+// timeChangeVars reads the proctor, the attempt ID and the optional body of a time change route,
+// writing the error response when one is missing or invalid
+func (h *AttemptHandler) timeChangeVars(w http.ResponseWriter, r *http.Request, fn string) (*middleware.Principal, uint, models.AttemptTimeDTO, bool) {
+	var request models.AttemptTimeDTO
+
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "UNAUTHORIZED",
+			"message": "User ID not found in context",
+		}, http.StatusUnauthorized)
+		return nil, 0, request, false
+	}
+
+	attemptID, err := strconv.ParseUint(mux.Vars(r)["attemptID"], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid attempt ID",
+		}, http.StatusBadRequest)
+		return nil, 0, request, false
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		h.log.Error("["+fn+"] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return nil, 0, request, false
+	}
+
+	return principal, uint(attemptID), request, true
+}
+
+// writeError maps service errors to HTTP responses
+func (h *AttemptHandler) writeError(w http.ResponseWriter, fn string, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrAttemptNotFound), errors.Is(err, service.ErrAnswerNotFound), errors.Is(err, service.ErrAssessmentNotFound),
+		errors.Is(err, service.ErrRegradeRequestNotFound):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "NOT_FOUND",
+			"message": err.Error(),
+		}, http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidGrade), errors.Is(err, service.ErrNotManuallyGraded), errors.Is(err, service.ErrInvalidGraderAssignment),
+		errors.Is(err, service.ErrInvalidRegradeDecision), errors.Is(err, service.ErrInvalidRescore), errors.Is(err, service.ErrInvalidExtension),
+		errors.Is(err, service.ErrInvalidMessage), errors.Is(err, service.ErrInvalidProctorAction):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": err.Error(),
+		}, http.StatusBadRequest)
+	case errors.Is(err, service.ErrNotAssignedGrader):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "FORBIDDEN",
+			"message": err.Error(),
+		}, http.StatusForbidden)
+	case errors.Is(err, service.ErrAttemptNotGradable), errors.Is(err, models.ErrInvalidAttemptTransition),
+		errors.Is(err, service.ErrMarksFinal), errors.Is(err, service.ErrNotReconcilable), errors.Is(err, service.ErrRegradeNotPending),
+		errors.Is(err, service.ErrAttemptNotLive), errors.Is(err, models.ErrInvalidTimeEvent):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "CONFLICT",
+			"message": err.Error(),
+		}, http.StatusConflict)
+	default:
+		h.log.Error("["+fn+"] "+message, zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "ERROR",
+			"message": message,
+		}, http.StatusInternalServerError)
+	}
+}
@@ -1,6 +1,8 @@
 package delivery
 
 import (
+	"assessment_service/internal/attempts/service"
+	"assessment_service/internal/middleware"
 	models "assessment_service/internal/model"
 	"assessment_service/internal/util"
 	"bytes"
@@ -10,11 +12,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
 )
 
 // --- Mock AttemptService ---
@@ -41,8 +46,139 @@ func (m *MockAttemptService) GetAttemptDetail(attemptID uint) (*models.Attempt,
 	return attempt, args.Error(1)
 }
 
-func (m *MockAttemptService) GradeAttempt(newAttempt models.AttemptUpdateDTO, attemptID uint) error {
-	args := m.Called(newAttempt, attemptID)
+func (m *MockAttemptService) GradeAttempt(newAttempt models.AttemptUpdateDTO, attemptID, graderID uint) error {
+	args := m.Called(newAttempt, attemptID, graderID)
+	return args.Error(0)
+}
+
+func (m *MockAttemptService) GetGradingQueue(params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	args := m.Called(params)
+	results, _ := args.Get(0).([]map[string]interface{})
+	count, _ := args.Get(1).(int64)
+	return results, count, args.Error(2)
+}
+
+func (m *MockAttemptService) GradeAnswer(attemptID, answerID, graderID uint, grade models.AnswerGradeInput) (*models.Attempt, error) {
+	args := m.Called(attemptID, answerID, graderID, grade)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Attempt), args.Error(1)
+}
+
+func (m *MockAttemptService) AssignGraders(assessmentID uint, request models.GraderAssignmentDTO) ([]models.GradingAssignment, error) {
+	args := m.Called(assessmentID, request)
+	assignments, _ := args.Get(0).([]models.GradingAssignment)
+	return assignments, args.Error(1)
+}
+
+func (m *MockAttemptService) GetGraderAssignments(graderID uint, params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	args := m.Called(graderID, params)
+	results, _ := args.Get(0).([]map[string]interface{})
+	count, _ := args.Get(1).(int64)
+	return results, count, args.Error(2)
+}
+
+func (m *MockAttemptService) ReconcileAnswer(attemptID, answerID, graderID uint, grade models.AnswerGradeInput) (*models.Attempt, error) {
+	args := m.Called(attemptID, answerID, graderID, grade)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Attempt), args.Error(1)
+}
+
+func (m *MockAttemptService) GetGradingProgress(assessmentID uint) (map[string]interface{}, error) {
+	args := m.Called(assessmentID)
+	progress, _ := args.Get(0).(map[string]interface{})
+	return progress, args.Error(1)
+}
+
+func (m *MockAttemptService) GetGradeEvents(attemptID uint) ([]models.GradeEvent, error) {
+	args := m.Called(attemptID)
+	events, _ := args.Get(0).([]models.GradeEvent)
+	return events, args.Error(1)
+}
+
+func (m *MockAttemptService) ListRegradeRequests(params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	args := m.Called(params)
+	requests, _ := args.Get(0).([]map[string]interface{})
+	total, _ := args.Get(1).(int64)
+	return requests, total, args.Error(2)
+}
+
+func (m *MockAttemptService) AcceptRegradeRequest(attemptID, requestID, reviewerID uint, decision models.RegradeDecisionDTO) (*models.RegradeRequest, error) {
+	args := m.Called(attemptID, requestID, reviewerID, decision)
+	request, _ := args.Get(0).(*models.RegradeRequest)
+	return request, args.Error(1)
+}
+
+func (m *MockAttemptService) RejectRegradeRequest(attemptID, requestID, reviewerID uint, response string) (*models.RegradeRequest, error) {
+	args := m.Called(attemptID, requestID, reviewerID, response)
+	request, _ := args.Get(0).(*models.RegradeRequest)
+	return request, args.Error(1)
+}
+
+func (m *MockAttemptService) RescoreAssessment(assessmentID, actorID uint, questionIDs []uint) (*models.RescoreSummary, error) {
+	args := m.Called(assessmentID, actorID, questionIDs)
+	summary, _ := args.Get(0).(*models.RescoreSummary)
+	return summary, args.Error(1)
+}
+
+func (m *MockAttemptService) RescoreAssessmentWithin(tx *gorm.DB, assessmentID, actorID uint, questionIDs []uint) (*models.RescoreSummary, error) {
+	args := m.Called(assessmentID, actorID, questionIDs)
+	summary, _ := args.Get(0).(*models.RescoreSummary)
+	return summary, args.Error(1)
+}
+
+func (m *MockAttemptService) PauseAttempt(attemptID, actorID uint, reason string) (*models.Attempt, error) {
+	args := m.Called(attemptID, actorID, reason)
+	attempt, _ := args.Get(0).(*models.Attempt)
+	return attempt, args.Error(1)
+}
+
+func (m *MockAttemptService) ResumeAttempt(attemptID, actorID uint, reason string) (*models.Attempt, error) {
+	args := m.Called(attemptID, actorID, reason)
+	attempt, _ := args.Get(0).(*models.Attempt)
+	return attempt, args.Error(1)
+}
+
+func (m *MockAttemptService) ExtendAttempt(attemptID, actorID uint, minutes int, reason string) (*models.Attempt, error) {
+	args := m.Called(attemptID, actorID, minutes, reason)
+	attempt, _ := args.Get(0).(*models.Attempt)
+	return attempt, args.Error(1)
+}
+
+func (m *MockAttemptService) GetTimeEvents(attemptID uint) ([]models.AttemptTimeEvent, error) {
+	args := m.Called(attemptID)
+	events, _ := args.Get(0).([]models.AttemptTimeEvent)
+	return events, args.Error(1)
+}
+
+func (m *MockAttemptService) SendMessage(attemptID, actorID uint, message models.ProctorMessageDTO) error {
+	args := m.Called(attemptID, actorID, message)
+	return args.Error(0)
+}
+
+func (m *MockAttemptService) GetProctoringDashboard(assessmentID uint) ([]models.ProctoredAttempt, error) {
+	args := m.Called(assessmentID)
+	dashboard, _ := args.Get(0).([]models.ProctoredAttempt)
+	return dashboard, args.Error(1)
+}
+
+func (m *MockAttemptService) FlagAttempt(attemptID, actorID uint, reason string) (*models.Attempt, error) {
+	args := m.Called(attemptID, actorID, reason)
+	attempt, _ := args.Get(0).(*models.Attempt)
+	return attempt, args.Error(1)
+}
+
+func (m *MockAttemptService) TerminateAttempt(attemptID, actorID uint, reason string) (*models.Attempt, error) {
+	args := m.Called(attemptID, actorID, reason)
+	attempt, _ := args.Get(0).(*models.Attempt)
+	return attempt, args.Error(1)
+}
+
+func (m *MockAttemptService) VoidAttempt(attemptID uint) error {
+	args := m.Called(attemptID)
 	return args.Error(0)
 }
 
@@ -206,16 +342,14 @@ func TestAttemptHandler_GradeAttempt(t *testing.T) {
 	gradeReq := models.AttemptUpdateDTO{
 		Score:    95.0,
 		Feedback: "Excellent work",
-		Answers: []struct {
-			ID        uint `json:"id"`
-			IsCorrect bool `json:"isCorrect"`
-		}{{ID: 10, IsCorrect: true}},
+		Answers:  []models.AnswerGradeDTO{{ID: 10, IsCorrect: true}},
 	}
 	body, _ := json.Marshal(gradeReq)
 
-	mockService.On("GradeAttempt", gradeReq, attemptID).Return(nil)
+	mockService.On("GradeAttempt", gradeReq, attemptID, uint(3)).Return(nil)
 
 	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/admin/attempt/grade/%d", attemptID), bytes.NewBuffer(body))
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: 3, Role: "admin"}))
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -241,6 +375,7 @@ func TestAttemptHandler_GradeAttempt_InvalidAttemptID(t *testing.T) {
 	body, _ := json.Marshal(gradeReq)
 
 	req := httptest.NewRequest(http.MethodPost, "/admin/attempt/grade/invalid", bytes.NewBuffer(body))
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: 3, Role: "admin"}))
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -248,7 +383,7 @@ func TestAttemptHandler_GradeAttempt_InvalidAttemptID(t *testing.T) {
 	router.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusBadRequest, rr.Code)
-	mockService.AssertNotCalled(t, "GradeAttempt", mock.Anything, mock.Anything)
+	mockService.AssertNotCalled(t, "GradeAttempt", mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestAttemptHandler_GradeAttempt_InvalidBody(t *testing.T) {
@@ -260,6 +395,7 @@ func TestAttemptHandler_GradeAttempt_InvalidBody(t *testing.T) {
 	invalidBody := []byte(`{"score": "not-a-number"}`)
 
 	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/admin/attempt/grade/%d", attemptID), bytes.NewBuffer(invalidBody))
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: 3, Role: "admin"}))
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -267,7 +403,7 @@ func TestAttemptHandler_GradeAttempt_InvalidBody(t *testing.T) {
 	router.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusBadRequest, rr.Code)
-	mockService.AssertNotCalled(t, "GradeAttempt", mock.Anything, mock.Anything)
+	mockService.AssertNotCalled(t, "GradeAttempt", mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestAttemptHandler_GradeAttempt_ServiceError(t *testing.T) {
@@ -279,9 +415,10 @@ func TestAttemptHandler_GradeAttempt_ServiceError(t *testing.T) {
 	gradeReq := models.AttemptUpdateDTO{Score: 90.0}
 	body, _ := json.Marshal(gradeReq)
 
-	mockService.On("GradeAttempt", gradeReq, attemptID).Return(errors.New("grading failed"))
+	mockService.On("GradeAttempt", gradeReq, attemptID, uint(3)).Return(errors.New("grading failed"))
 
 	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/admin/attempt/grade/%d", attemptID), bytes.NewBuffer(body))
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: 3, Role: "admin"}))
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -291,3 +428,605 @@ func TestAttemptHandler_GradeAttempt_ServiceError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, rr.Code)
 	mockService.AssertExpectations(t)
 }
+
+func TestAttemptHandler_GradeAttempt_NotGradable(t *testing.T) {
+	mockService := new(MockAttemptService)
+	handler := NewAttemptHandler(mockService, zaptest.NewLogger(t))
+
+	gradeReq := models.AttemptUpdateDTO{Score: 90.0}
+	body, _ := json.Marshal(gradeReq)
+
+	mockService.On("GradeAttempt", gradeReq, uint(1), uint(3)).Return(fmt.Errorf("%w: attempt is in_progress", service.ErrAttemptNotGradable))
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/attempt/grade/1", bytes.NewBuffer(body))
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: 3, Role: "admin"}))
+	rr := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/admin/attempt/grade/{attemptID:[0-9]+}", handler.GradeAttempt).Methods(http.MethodPost)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+}
+
+func TestAttemptHandler_GradeAttempt_InvalidGrade(t *testing.T) {
+	mockService := new(MockAttemptService)
+	handler := NewAttemptHandler(mockService, zaptest.NewLogger(t))
+
+	points := 20.0
+	gradeReq := models.AttemptUpdateDTO{Answers: []models.AnswerGradeDTO{{ID: 10, AwardedPoints: &points}}}
+	body, _ := json.Marshal(gradeReq)
+
+	mockService.On("GradeAttempt", mock.Anything, uint(1), uint(3)).Return(fmt.Errorf("%w: answer 10 cannot get more than 15 points", service.ErrInvalidGrade))
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/attempt/grade/1", bytes.NewBuffer(body))
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: 3, Role: "admin"}))
+	rr := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/admin/attempt/grade/{attemptID:[0-9]+}", handler.GradeAttempt).Methods(http.MethodPost)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "cannot get more than 15 points")
+}
+
+func TestAttemptHandler_GetGradingQueue(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *middleware.Principal
+		query     string
+		filters   map[string]interface{}
+	}{
+		{"Teacher sees assessments they grade", &middleware.Principal{UserID: 3, Role: "teacher"}, "", map[string]interface{}{"gradableBy": uint(3)}},
+		{"Admin sees every assessment", &middleware.Principal{UserID: 1, Role: "admin"}, "?assessmentId=5", map[string]interface{}{"assessmentId": uint(5)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAttemptService)
+			handler := NewAttemptHandler(mockService, zaptest.NewLogger(t))
+
+			pending := []map[string]interface{}{{"answer_id": float64(11)}}
+			mockService.On("GetGradingQueue", mock.MatchedBy(func(p util.PaginationParams) bool {
+				return assert.ObjectsAreEqual(tt.filters, p.Filters)
+			})).Return(pending, int64(1), nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/grading/queue"+tt.query, nil)
+			req = req.WithContext(middleware.WithPrincipal(req.Context(), tt.principal))
+			rr := httptest.NewRecorder()
+			handler.GetGradingQueue(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			var resp map[string]interface{}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			assert.Equal(t, float64(1), resp["totalElements"])
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAttemptHandler_GetGradingQueue_InvalidAssessmentID(t *testing.T) {
+	mockService := new(MockAttemptService)
+	handler := NewAttemptHandler(mockService, zaptest.NewLogger(t))
+
+	req := httptest.NewRequest(http.MethodGet, "/grading/queue?assessmentId=abc", nil)
+	rr := httptest.NewRecorder()
+	handler.GetGradingQueue(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertNotCalled(t, "GetGradingQueue", mock.Anything)
+}
+
+func TestAttemptHandler_GradeAnswer(t *testing.T) {
+	points := 4.0
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"Success", nil, http.StatusOK},
+		{"AnswerNotFound", service.ErrAnswerNotFound, http.StatusNotFound},
+		{"InvalidGrade", fmt.Errorf("%w: cannot give more than 3 points", service.ErrInvalidGrade), http.StatusBadRequest},
+		{"NotManuallyGraded", fmt.Errorf("%w: multiple-choice questions are graded automatically", service.ErrNotManuallyGraded), http.StatusBadRequest},
+		{"NotGradable", fmt.Errorf("%w: attempt is in_progress", service.ErrAttemptNotGradable), http.StatusConflict},
+		{"NotAssignedGrader", service.ErrNotAssignedGrader, http.StatusForbidden},
+		{"MarksFinal", service.ErrMarksFinal, http.StatusConflict},
+		{"ServiceError", errors.New("db error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAttemptService)
+			handler := NewAttemptHandler(mockService, zaptest.NewLogger(t))
+
+			grade := models.AnswerGradeInput{Points: &points, Comment: "Well argued"}
+			body, _ := json.Marshal(grade)
+			if tt.err == nil {
+				mockService.On("GradeAnswer", uint(1), uint(11), uint(3), grade).Return(&models.Attempt{ID: 1, Status: models.AttemptGraded}, nil)
+			} else {
+				mockService.On("GradeAnswer", uint(1), uint(11), uint(3), grade).Return(nil, tt.err)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/assessments/attempts/1/answers/11/grade", bytes.NewBuffer(body))
+			req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: 3, Role: "teacher"}))
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/assessments/attempts/{attemptID:[0-9]+}/answers/{answerID:[0-9]+}/grade", handler.GradeAnswer).Methods(http.MethodPost)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAttemptHandler_ReconcileAnswer(t *testing.T) {
+	points := 7.0
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"Success", nil, http.StatusOK},
+		{"NotReconcilable", fmt.Errorf("%w: both graders must mark the answer first", service.ErrNotReconcilable), http.StatusConflict},
+		{"InvalidGrade", fmt.Errorf("%w: points are required", service.ErrInvalidGrade), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAttemptService)
+			handler := NewAttemptHandler(mockService, zaptest.NewLogger(t))
+
+			grade := models.AnswerGradeInput{Points: &points}
+			if tt.err == nil {
+				mockService.On("ReconcileAnswer", uint(1), uint(11), uint(2), grade).Return(&models.Attempt{ID: 1}, nil)
+			} else {
+				mockService.On("ReconcileAnswer", uint(1), uint(11), uint(2), grade).Return(nil, tt.err)
+			}
+
+			body, _ := json.Marshal(grade)
+			req := httptest.NewRequest(http.MethodPost, "/assessments/attempts/1/answers/11/reconcile", bytes.NewBuffer(body))
+			req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: 2, Role: "teacher"}))
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/assessments/attempts/{attemptID:[0-9]+}/answers/{answerID:[0-9]+}/reconcile", handler.ReconcileAnswer).Methods(http.MethodPost)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAttemptHandler_AssignGraders(t *testing.T) {
+	request := models.GraderAssignmentDTO{Strategy: models.GradingStrategyRoundRobin, GraderIDs: []uint{2, 3}}
+
+	tests := []struct {
+		name        string
+		assignments []models.GradingAssignment
+		err         error
+		wantStatus  int
+	}{
+		{"Success", []models.GradingAssignment{{AnswerID: 11, GraderID: 2}, {AnswerID: 21, GraderID: 3}}, nil, http.StatusCreated},
+		{"InvalidAssignment", nil, fmt.Errorf("%w: user 3 cannot grade this assessment", service.ErrInvalidGraderAssignment), http.StatusBadRequest},
+		{"AssessmentNotFound", nil, service.ErrAssessmentNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAttemptService)
+			handler := NewAttemptHandler(mockService, zaptest.NewLogger(t))
+			mockService.On("AssignGraders", uint(5), request).Return(tt.assignments, tt.err)
+
+			body, _ := json.Marshal(request)
+			req := httptest.NewRequest(http.MethodPost, "/assessments/5/grading/assign", bytes.NewBuffer(body))
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/assessments/{id:[0-9]+}/grading/assign", handler.AssignGraders).Methods(http.MethodPost)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if tt.err == nil {
+				var resp map[string]interface{}
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				assert.Equal(t, float64(2), resp["assigned"])
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAttemptHandler_GetGraderAssignments(t *testing.T) {
+	mockService := new(MockAttemptService)
+	handler := NewAttemptHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("GetGraderAssignments", uint(3), mock.MatchedBy(func(p util.PaginationParams) bool {
+		return p.Filters["status"] == models.GradingAssigned && p.Filters["assessmentId"] == uint(5)
+	})).Return([]map[string]interface{}{{"id": float64(1), "candidate": "Candidate 7"}}, int64(1), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/grading/assignments?status=assigned&assessmentId=5", nil)
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: 3, Role: "teacher"}))
+	rr := httptest.NewRecorder()
+	handler.GetGraderAssignments(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+
+	// Trạng thái không hợp lệ
+	req = httptest.NewRequest(http.MethodGet, "/grading/assignments?status=late", nil)
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: 3, Role: "teacher"}))
+	rr = httptest.NewRecorder()
+	handler.GetGraderAssignments(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestAttemptHandler_GetGradingProgress(t *testing.T) {
+	mockService := new(MockAttemptService)
+	handler := NewAttemptHandler(mockService, zaptest.NewLogger(t))
+	mockService.On("GetGradingProgress", uint(5)).Return(map[string]interface{}{"answersPending": 3}, nil)
+	mockService.On("GetGradingProgress", uint(6)).Return(nil, service.ErrAssessmentNotFound)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/assessments/{id:[0-9]+}/grading/progress", handler.GetGradingProgress).Methods(http.MethodGet)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/assessments/5/grading/progress", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"answersPending":3}`, rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/assessments/6/grading/progress", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestAttemptHandler_RescoreAssessment(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		questionIDs []uint
+		err         error
+		wantCode    int
+	}{
+		{"every question", "", nil, nil, http.StatusOK},
+		{"some questions", `{"questionIds":[101]}`, []uint{101}, nil, http.StatusOK},
+		{"unknown question", `{"questionIds":[999]}`, []uint{999}, service.ErrInvalidRescore, http.StatusBadRequest},
+		{"invalid input", `{"questionIds":"all"}`, nil, nil, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAttemptService)
+			handler := NewAttemptHandler(mockService, zaptest.NewLogger(t))
+			if tt.wantCode == http.StatusOK {
+				mockService.On("RescoreAssessment", uint(5), uint(3), tt.questionIDs).Return(&models.RescoreSummary{AssessmentID: 5, AttemptsChecked: 4, AttemptsChanged: 1}, nil)
+			} else if tt.err != nil {
+				mockService.On("RescoreAssessment", uint(5), uint(3), tt.questionIDs).Return(nil, tt.err)
+			}
+
+			router := mux.NewRouter()
+			router.HandleFunc("/assessments/{id:[0-9]+}/rescore", handler.RescoreAssessment).Methods(http.MethodPost)
+
+			req := httptest.NewRequest(http.MethodPost, "/assessments/5/rescore", bytes.NewBufferString(tt.body))
+			req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: 3, Role: "teacher"}))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			if tt.wantCode == http.StatusOK {
+				assert.Contains(t, rr.Body.String(), `"attemptsChanged":1`)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAttemptHandler_GetGradeEvents(t *testing.T) {
+	mockService := new(MockAttemptService)
+	handler := NewAttemptHandler(mockService, zaptest.NewLogger(t))
+	score := 80.0
+	mockService.On("GetGradeEvents", uint(1)).Return([]models.GradeEvent{{ID: 1, AttemptID: 1, Kind: models.GradeEventAuto, NewValue: &score}}, nil)
+	mockService.On("GetGradeEvents", uint(2)).Return(nil, service.ErrAttemptNotFound)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/admin/attempts/{attemptID:[0-9]+}/grade-events", handler.GetGradeEvents).Methods(http.MethodGet)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/attempts/1/grade-events", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"kind":"auto"`)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/attempts/2/grade-events", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestAttemptHandler_ListRegradeRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		principal  *middleware.Principal
+		wantStatus models.RegradeRequestStatus
+	}{
+		// Mặc định chỉ liệt kê yêu cầu đang chờ
+		{"teacher sees pending requests they may grade", "", &middleware.Principal{UserID: 3, Role: "teacher"}, models.RegradePending},
+		{"admin filters by status", "?status=accepted", &middleware.Principal{UserID: 1, Role: "admin"}, models.RegradeAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAttemptService)
+			handler := NewAttemptHandler(mockService, zaptest.NewLogger(t))
+
+			mockService.On("ListRegradeRequests", mock.MatchedBy(func(p util.PaginationParams) bool {
+				_, scoped := p.Filters["gradableBy"]
+				return p.Filters["status"] == tt.wantStatus && scoped == !tt.principal.IsAdmin()
+			})).Return([]map[string]interface{}{{"id": float64(4)}}, int64(1), nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/grading/regrade-requests"+tt.query, nil)
+			req = req.WithContext(middleware.WithPrincipal(req.Context(), tt.principal))
+			rr := httptest.NewRecorder()
+			handler.ListRegradeRequests(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+
+	handler := NewAttemptHandler(new(MockAttemptService), zaptest.NewLogger(t))
+	rr := httptest.NewRecorder()
+	handler.ListRegradeRequests(rr, httptest.NewRequest(http.MethodGet, "/grading/regrade-requests?status=open", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestAttemptHandler_ReviewRegradeRequest(t *testing.T) {
+	score := 75.0
+	decision := models.RegradeDecisionDTO{Score: &score, Response: "Question 3 was marked wrong"}
+
+	tests := []struct {
+		name       string
+		action     string
+		err        error
+		wantStatus int
+	}{
+		{"Accept", "accept", nil, http.StatusOK},
+		{"Reject", "reject", nil, http.StatusOK},
+		{"NotFound", "accept", service.ErrRegradeRequestNotFound, http.StatusNotFound},
+		{"NotPending", "reject", fmt.Errorf("%w: request is accepted", service.ErrRegradeNotPending), http.StatusConflict},
+		{"InvalidDecision", "accept", fmt.Errorf("%w: accepting a regrade request needs a new score or answer grades", service.ErrInvalidRegradeDecision), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAttemptService)
+			handler := NewAttemptHandler(mockService, zaptest.NewLogger(t))
+
+			var result *models.RegradeRequest
+			if tt.err == nil {
+				result = &models.RegradeRequest{ID: 4, AttemptID: 1}
+			}
+			if tt.action == "accept" {
+				mockService.On("AcceptRegradeRequest", uint(1), uint(4), uint(3), decision).Return(result, tt.err)
+			} else {
+				mockService.On("RejectRegradeRequest", uint(1), uint(4), uint(3), decision.Response).Return(result, tt.err)
+			}
+
+			body, _ := json.Marshal(decision)
+			req := httptest.NewRequest(http.MethodPost, "/assessments/attempts/1/regrade-requests/4/"+tt.action, bytes.NewBuffer(body))
+			req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: 3, Role: "teacher"}))
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/assessments/attempts/{attemptID:[0-9]+}/regrade-requests/{requestID:[0-9]+}/accept", handler.AcceptRegradeRequest).Methods(http.MethodPost)
+			router.HandleFunc("/assessments/attempts/{attemptID:[0-9]+}/regrade-requests/{requestID:[0-9]+}/reject", handler.RejectRegradeRequest).Methods(http.MethodPost)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAttemptHandler_VoidAttempt(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"Success", nil, http.StatusOK},
+		{"NotFound", service.ErrAttemptNotFound, http.StatusNotFound},
+		{"AlreadyVoided", fmt.Errorf("%w: voided to voided", models.ErrInvalidAttemptTransition), http.StatusConflict},
+		{"ServiceError", errors.New("db error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAttemptService)
+			handler := NewAttemptHandler(mockService, zaptest.NewLogger(t))
+
+			mockService.On("VoidAttempt", uint(7)).Return(tt.err)
+
+			req := httptest.NewRequest(http.MethodPost, "/assessments/attempts/7/void", nil)
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/assessments/attempts/{attemptID:[0-9]+}/void", handler.VoidAttempt).Methods(http.MethodPost)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAttemptHandler_ExtendAttempt(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{"Success", `{"minutes":10,"reason":"Network outage"}`, nil, http.StatusOK},
+		{"InvalidExtension", `{"minutes":0}`, service.ErrInvalidExtension, http.StatusBadRequest},
+		{"NotLive", `{"minutes":10}`, service.ErrAttemptNotLive, http.StatusConflict},
+		{"NotFound", `{"minutes":10}`, service.ErrAttemptNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAttemptService)
+			handler := NewAttemptHandler(mockService, zaptest.NewLogger(t))
+
+			var request models.AttemptTimeDTO
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &request))
+			mockService.On("ExtendAttempt", uint(7), uint(3), request.Minutes, request.Reason).Return(&models.Attempt{ID: 7}, tt.err)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/attempts/7/extend", bytes.NewBufferString(tt.body))
+			req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: 3, Role: "admin"}))
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/admin/attempts/{attemptID:[0-9]+}/extend", handler.ExtendAttempt).Methods(http.MethodPost)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAttemptHandler_SendMessage(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{"Success", `{"message":"Eyes on your screen","severity":"WARNING"}`, nil, http.StatusOK},
+		{"InvalidMessage", `{"message":""}`, service.ErrInvalidMessage, http.StatusBadRequest},
+		{"NotLive", `{"message":"Hi"}`, service.ErrAttemptNotLive, http.StatusConflict},
+		{"NotFound", `{"message":"Hi"}`, service.ErrAttemptNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAttemptService)
+			handler := NewAttemptHandler(mockService, zaptest.NewLogger(t))
+
+			var request models.ProctorMessageDTO
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &request))
+			mockService.On("SendMessage", uint(7), uint(3), request).Return(tt.err)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/attempts/7/messages", bytes.NewBufferString(tt.body))
+			req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: 3, Role: "admin"}))
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/admin/attempts/{attemptID:[0-9]+}/messages", handler.SendMessage).Methods(http.MethodPost)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAttemptHandler_FlagAttempt(t *testing.T) {
+	mockService := new(MockAttemptService)
+	handler := NewAttemptHandler(mockService, zaptest.NewLogger(t))
+
+	flaggedAt := time.Now()
+	mockService.On("FlagAttempt", uint(7), uint(3), "").Return(&models.Attempt{ID: 7, FlaggedAt: &flaggedAt}, nil)
+	mockService.On("FlagAttempt", uint(9), uint(3), "Second screen").Return(nil, service.ErrAttemptNotFound)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/admin/attempts/{attemptID:[0-9]+}/flag", handler.FlagAttempt).Methods(http.MethodPost)
+
+	// Lý do không bắt buộc
+	req := httptest.NewRequest(http.MethodPost, "/admin/attempts/7/flag", nil)
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: 3, Role: "admin"}))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"flaggedAt"`)
+
+	req = httptest.NewRequest(http.MethodPost, "/admin/attempts/9/flag", bytes.NewBufferString(`{"reason":"Second screen"}`))
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: 3, Role: "admin"}))
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestAttemptHandler_TerminateAttempt(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{"Success", `{"reason":"Phone in hand"}`, nil, http.StatusOK},
+		{"MissingReason", `{}`, service.ErrInvalidProctorAction, http.StatusBadRequest},
+		{"NotLive", `{"reason":"Phone in hand"}`, service.ErrAttemptNotLive, http.StatusConflict},
+		{"NotFound", `{"reason":"Phone in hand"}`, service.ErrAttemptNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAttemptService)
+			handler := NewAttemptHandler(mockService, zaptest.NewLogger(t))
+
+			var request models.ProctorActionDTO
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &request))
+			if tt.err != nil {
+				mockService.On("TerminateAttempt", uint(7), uint(3), request.Reason).Return(nil, tt.err)
+			} else {
+				mockService.On("TerminateAttempt", uint(7), uint(3), request.Reason).Return(&models.Attempt{ID: 7, Status: models.AttemptVoided}, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/attempts/7/terminate", bytes.NewBufferString(tt.body))
+			req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: 3, Role: "admin"}))
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/admin/attempts/{attemptID:[0-9]+}/terminate", handler.TerminateAttempt).Methods(http.MethodPost)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAttemptHandler_PauseAttempt(t *testing.T) {
+	mockService := new(MockAttemptService)
+	handler := NewAttemptHandler(mockService, zaptest.NewLogger(t))
+
+	pausedAt := time.Now()
+	mockService.On("PauseAttempt", uint(7), uint(3), "").Return(&models.Attempt{ID: 7, PausedAt: &pausedAt}, nil)
+	mockService.On("PauseAttempt", uint(8), uint(3), "").Return(nil, fmt.Errorf("%w: attempt is already paused", models.ErrInvalidTimeEvent))
+
+	router := mux.NewRouter()
+	router.HandleFunc("/admin/attempts/{attemptID:[0-9]+}/pause", handler.PauseAttempt).Methods(http.MethodPost)
+
+	// Không cần body
+	req := httptest.NewRequest(http.MethodPost, "/admin/attempts/7/pause", nil)
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: 3, Role: "admin"}))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"pausedAt"`)
+
+	req = httptest.NewRequest(http.MethodPost, "/admin/attempts/8/pause", nil)
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: 3, Role: "admin"}))
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,142 @@
+package delivery
+
+import (
+	"assessment_service/internal/attempts/service"
+	"assessment_service/internal/live"
+	models "assessment_service/internal/model"
+	"assessment_service/internal/util"
+	"errors"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// snapshotInterval is how often the proctoring feed sends the whole dashboard again
+const snapshotInterval = 30 * time.Second
+
+// ProctoringHandler serves the live proctoring dashboard of an assessment
+type ProctoringHandler struct {
+	attemptService service.AttemptService
+	hub            live.Hub
+	log            *zap.Logger
+	interval       time.Duration
+}
+
+func NewProctoringHandler(attemptService service.AttemptService, hub live.Hub, log *zap.Logger) *ProctoringHandler {
+	return &ProctoringHandler{attemptService: attemptService, hub: hub, log: log, interval: snapshotInterval}
+}
+
+// GetDashboard lists the attempts of an assessment in progress with their last heartbeat, answered
+// count and recent suspicious activity
+func (h *ProctoringHandler) GetDashboard(w http.ResponseWriter, r *http.Request) {
+	assessmentID, ok := h.assessmentID(w, r)
+	if !ok {
+		return
+	}
+
+	dashboard, err := h.attemptService.GetProctoringDashboard(assessmentID)
+	if err != nil {
+		h.writeError(w, "GetDashboard", err)
+		return
+	}
+
+	util.ResponseInterface(w, dashboard, http.StatusOK)
+}
+
+// Stream is a Server-Sent Events feed of the dashboard. It sends the dashboard when it opens and
+// every interval after, and the events of the assessment's attempts as they happen: attempts
+// started, heartbeats, answers saved, suspicious activity, proctor actions and submissions.
+func (h *ProctoringHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	assessmentID, ok := h.assessmentID(w, r)
+	if !ok {
+		return
+	}
+
+	dashboard, err := h.attemptService.GetProctoringDashboard(assessmentID)
+	if err != nil {
+		h.writeError(w, "Stream", err)
+		return
+	}
+
+	events, unsubscribe := h.hub.SubscribeAssessment(assessmentID)
+	defer unsubscribe()
+
+	rc := live.OpenStream(w, h.log)
+	if err := live.WriteEvent(w, rc, snapshot(assessmentID, dashboard)); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if !h.refresh(w, rc, assessmentID) {
+				return
+			}
+		case event, open := <-events:
+			if !open {
+				return
+			}
+			// A proctor changed an attempt's clock, which the dashboard shows as paused or not
+			if event.Type == models.LiveEventTime && event.Data == nil {
+				if !h.refresh(w, rc, assessmentID) {
+					return
+				}
+				continue
+			}
+			if err := live.WriteEvent(w, rc, event); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// refresh sends the dashboard again, reporting whether the feed should go on
+func (h *ProctoringHandler) refresh(w http.ResponseWriter, rc *http.ResponseController, assessmentID uint) bool {
+	dashboard, err := h.attemptService.GetProctoringDashboard(assessmentID)
+	if err != nil {
+		h.log.Error("[Stream] failed to get proctoring dashboard", zap.Error(err))
+		return false
+	}
+	return live.WriteEvent(w, rc, snapshot(assessmentID, dashboard)) == nil
+}
+
+func snapshot(assessmentID uint, dashboard []models.ProctoredAttempt) models.LiveEvent {
+	event := models.NewLiveEvent(0, models.LiveEventSnapshot, dashboard)
+	event.AssessmentID = assessmentID
+	return event
+}
+
+func (h *ProctoringHandler) assessmentID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)["assessmentID"], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid assessment ID",
+		}, http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h *ProctoringHandler) writeError(w http.ResponseWriter, fn string, err error) {
+	if errors.Is(err, service.ErrAssessmentNotFound) {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "NOT_FOUND",
+			"message": err.Error(),
+		}, http.StatusNotFound)
+		return
+	}
+
+	h.log.Error("["+fn+"] failed to get proctoring dashboard", zap.Error(err))
+	util.ResponseMap(w, map[string]interface{}{
+		"status":  "ERROR",
+		"message": "Failed to get proctoring dashboard",
+	}, http.StatusInternalServerError)
+}
@@ -0,0 +1,168 @@
+package delivery
+
+import (
+	"assessment_service/internal/attempts/service"
+	"assessment_service/internal/live"
+	models "assessment_service/internal/model"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// proctoringServer phục vụ bảng giám sát qua một server thật để có thể đọc luồng SSE
+func proctoringServer(t *testing.T, handler *ProctoringHandler) *httptest.Server {
+	router := mux.NewRouter()
+	router.HandleFunc("/admin/assessments/{assessmentID:[0-9]+}/proctoring/live", handler.Stream).Methods(http.MethodGet)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// readEvent đọc một sự kiện SSE, trả về false khi luồng đã đóng
+func readEvent(t *testing.T, reader *bufio.Reader) (models.LiveEvent, bool) {
+	var event models.LiveEvent
+	var name string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return event, false
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+		case line == "":
+			assert.Equal(t, string(event.Type), name)
+			return event, true
+		}
+	}
+}
+
+func TestProctoringHandler_GetDashboard(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		setup      func(m *MockAttemptService)
+		wantStatus int
+	}{
+		{
+			name: "Success",
+			url:  "/admin/assessments/5/proctoring",
+			setup: func(m *MockAttemptService) {
+				m.On("GetProctoringDashboard", uint(5)).Return([]models.ProctoredAttempt{{AttemptID: 1, Name: "Student A", Answered: 2, Total: 3}}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "NotFound",
+			url:  "/admin/assessments/5/proctoring",
+			setup: func(m *MockAttemptService) {
+				m.On("GetProctoringDashboard", uint(5)).Return(nil, service.ErrAssessmentNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "ServiceError",
+			url:  "/admin/assessments/5/proctoring",
+			setup: func(m *MockAttemptService) {
+				m.On("GetProctoringDashboard", uint(5)).Return(nil, errors.New("db down"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "InvalidAssessmentID",
+			url:        "/admin/assessments/99999999999/proctoring",
+			setup:      func(m *MockAttemptService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAttemptService)
+			tt.setup(mockService)
+			handler := NewProctoringHandler(mockService, live.NewMemoryHub(), zaptest.NewLogger(t))
+
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			rr := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/admin/assessments/{assessmentID:[0-9]+}/proctoring", handler.GetDashboard).Methods(http.MethodGet)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Contains(t, rr.Body.String(), `"name":"Student A"`)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProctoringHandler_Stream(t *testing.T) {
+	mockService := new(MockAttemptService)
+	hub := live.NewMemoryHub()
+	handler := NewProctoringHandler(mockService, hub, zaptest.NewLogger(t))
+	handler.interval = time.Hour // Không để bảng tự gửi lại trong test
+	server := proctoringServer(t, handler)
+
+	mockService.On("GetProctoringDashboard", uint(5)).Return([]models.ProctoredAttempt{{AttemptID: 1}}, nil).Once()
+	mockService.On("GetProctoringDashboard", uint(5)).Return([]models.ProctoredAttempt{{AttemptID: 1, Paused: true}}, nil).Once()
+
+	resp, err := http.Get(server.URL + "/admin/assessments/5/proctoring/live")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	reader := bufio.NewReader(resp.Body)
+
+	// Bảng giám sát được gửi ngay khi mở kênh
+	event, ok := readEvent(t, reader)
+	require.True(t, ok)
+	assert.Equal(t, models.LiveEventSnapshot, event.Type)
+	assert.Equal(t, uint(5), event.AssessmentID)
+	require.Len(t, event.Data, 1)
+
+	attempt := &models.Attempt{ID: 1, AssessmentID: 5}
+
+	// Giám thị thay đổi đồng hồ: bảng được gửi lại
+	require.NoError(t, hub.Publish(models.NewAttemptEvent(attempt, models.LiveEventTime, nil)))
+	event, ok = readEvent(t, reader)
+	require.True(t, ok)
+	assert.Equal(t, models.LiveEventSnapshot, event.Type)
+	assert.Equal(t, true, event.Data.([]interface{})[0].(map[string]interface{})["paused"])
+
+	// Sự kiện của bài kiểm tra khác không được gửi, sự kiện của bài này thì được chuyển tiếp
+	require.NoError(t, hub.Publish(models.NewAttemptEvent(&models.Attempt{ID: 2, AssessmentID: 6}, models.LiveEventHeartbeat, nil)))
+	require.NoError(t, hub.Publish(models.NewAttemptEvent(attempt, models.LiveEventMonitor, map[string]interface{}{"type": "TAB_SWITCH"})))
+	event, ok = readEvent(t, reader)
+	require.True(t, ok)
+	assert.Equal(t, models.LiveEventMonitor, event.Type)
+	assert.Equal(t, uint(1), event.AttemptID)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestProctoringHandler_Stream_NotFound(t *testing.T) {
+	mockService := new(MockAttemptService)
+	handler := NewProctoringHandler(mockService, live.NewMemoryHub(), zaptest.NewLogger(t))
+	server := proctoringServer(t, handler)
+
+	mockService.On("GetProctoringDashboard", uint(5)).Return(nil, service.ErrAssessmentNotFound)
+
+	resp, err := http.Get(server.URL + "/admin/assessments/5/proctoring/live")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
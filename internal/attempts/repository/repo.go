@@ -24,10 +24,54 @@ type AttemptRepository interface {
 	UpdateAnswer(answer *models.Answer) error
 	FindAnswersByAttemptID(attemptID uint) ([]models.Answer, error)
 	FindAnswerByAttemptAndQuestion(attemptID, questionID uint) (*models.Answer, error)
+	SaveAnswerGrade(answer *models.Answer) error
+
+	// Manual grading
+	FindPendingManualAnswers(params util.PaginationParams) ([]map[string]interface{}, int64, error)
+	FindGraderIDs(assessmentID uint) ([]uint, error)
+	FindUnassignedManualAnswers(assessmentID uint) ([]models.Answer, error)
+	CreateGradingAssignments(assignments []models.GradingAssignment) error
+	FindAssignmentsByAnswer(answerID uint) ([]models.GradingAssignment, error)
+	UpdateGradingAssignment(assignment *models.GradingAssignment) error
+	FindAssignmentsByGrader(graderID uint, params util.PaginationParams) ([]map[string]interface{}, int64, error)
+	GetGradingProgress(assessmentID uint) (map[string]interface{}, error)
+
+	// Grade history and regrade requests
+	CreateGradeEvents(events []models.GradeEvent) error
+	// SaveGrade stores everything a grading step changed with its grade events, all or nothing
+	SaveGrade(change models.GradeChange) error
+	FindGradeEventsByAttempt(attemptID uint) ([]models.GradeEvent, error)
+	CreateRegradeRequest(request *models.RegradeRequest) error
+	FindRegradeRequestByID(id uint) (*models.RegradeRequest, error)
+	FindRegradeRequestsByAttempt(attemptID uint) ([]models.RegradeRequest, error)
+	FindRegradeRequests(params util.PaginationParams) ([]map[string]interface{}, int64, error)
+	UpdateRegradeRequest(request *models.RegradeRequest) error
+
+	// Proctor time changes
+	// SaveTimeEvent stores the attempt's paused and extra time together with the event that changed it
+	SaveTimeEvent(attempt *models.Attempt, event *models.AttemptTimeEvent) error
+	FindTimeEventsByAttempt(attemptID uint) ([]models.AttemptTimeEvent, error)
+
+	// Live proctoring
+	SaveHeartbeat(attemptID uint, at time.Time) error
+	// FindLiveAttempts returns the attempts of the assessment in progress with their students,
+	// answers and the questions they were given, oldest first
+	FindLiveAttempts(assessmentID uint) ([]models.Attempt, error)
+	// FindSuspiciousActivitiesByAttempts returns the suspicious activities of the attempts, newest first
+	FindSuspiciousActivitiesByAttempts(attemptIDs []uint) ([]models.SuspiciousActivity, error)
+	// SaveProctorAction stores the flag and the status a proctor gave the attempt
+	SaveProctorAction(attempt *models.Attempt) error
+
+	// Rescoring
+	FindScoredAttempts(assessmentID uint) ([]models.Attempt, error)
+	SaveRescore(attempts []*models.Attempt, answers []*models.Answer, events []models.GradeEvent) error
 
 	// Student assessment interactions
 	FindAvailableAssessments(userID uint, params util.PaginationParams) ([]map[string]interface{}, int64, error)
 	HasCompletedAssessment(userID, assessmentID uint) (bool, error)
+	// FindInProgressAttempt returns the student's attempt of the assessment that is still in progress,
+	// or nil when there is none
+	FindInProgressAttempt(userID, assessmentID uint) (*models.Attempt, error)
 	CountAttemptsByUserAndAssessment(userID, assessmentID uint) (int, error)
 	FindCompletedAttemptsByUserAndAssessment(userID, assessmentID uint) ([]map[string]interface{}, error)
 	GetAllAttemptByUserId(userID uint, params util.PaginationParams) ([]models.Attempt, int64, error)
@@ -53,6 +97,9 @@ type AttemptRepository interface {
 	// Check status of student
 	ExpiredAttempt() ([]models.Attempt, error)
 	IsUserInAttempt(userID uint) (bool, error)
+
+	// WithTx returns the repository working inside the transaction tx
+	WithTx(tx *gorm.DB) AttemptRepository
 }
 
 type attemptRepository struct {
@@ -64,6 +111,11 @@ func NewAttemptRepository(db *gorm.DB) AttemptRepository {
 	return &attemptRepository{db: db}
 }
 
+// WithTx returns the repository working inside the transaction tx
+func (r *attemptRepository) WithTx(tx *gorm.DB) AttemptRepository {
+	return &attemptRepository{db: tx}
+}
+
 // Create inserts a new attempt record into the database
 func (r *attemptRepository) Create(attempt *models.Attempt) error {
 	now := time.Now()
@@ -85,18 +137,25 @@ func (r *attemptRepository) FindByID(id uint) (*models.Attempt, error) {
 	result := r.db.Where("id = ? AND deleted_at IS NULL", id).First(&attempt)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("attempt with ID %d not found", id)
+			return nil, fmt.Errorf("attempt with ID %d not found: %w", id, gorm.ErrRecordNotFound)
 		}
 		return nil, fmt.Errorf("failed to find attempt: %w", result.Error)
 	}
 
 	// Load answers for this attempt
 	var answers []models.Answer
-	if err := r.db.Where("attempt_id = ?", id).Find(&answers).Error; err != nil {
+	if err := r.db.Preload("RubricScores").Where("attempt_id = ?", id).Find(&answers).Error; err != nil {
 		return nil, fmt.Errorf("failed to load answers: %w", err)
 	}
 	attempt.Answers = answers
 
+	// Load the questions the attempt was given
+	var selection []models.AttemptQuestion
+	if err := r.db.Where("attempt_id = ?", id).Order("position").Find(&selection).Error; err != nil {
+		return nil, fmt.Errorf("failed to load selection: %w", err)
+	}
+	attempt.Selection = selection
+
 	return &attempt, nil
 }
 
@@ -148,6 +207,37 @@ func (r *attemptRepository) UpdateAnswer(answer *models.Answer) error {
 	return nil
 }
 
+// SaveAnswerGrade stores a grader's mark for an answer, replacing its earlier rubric scores
+func (r *attemptRepository) SaveAnswerGrade(answer *models.Answer) error {
+	answer.UpdatedAt = time.Now()
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("answer_id = ?", answer.ID).Delete(&models.RubricScore{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Omit("RubricScores").Save(answer).Error; err != nil {
+			return err
+		}
+
+		for i := range answer.RubricScores {
+			answer.RubricScores[i].ID = 0
+			answer.RubricScores[i].AnswerID = answer.ID
+		}
+		if len(answer.RubricScores) > 0 {
+			if err := tx.Create(&answer.RubricScores).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save answer grade: %w", err)
+	}
+
+	return nil
+}
+
 // FindAnswersByAttemptID retrieves all answers for a given attempt
 func (r *attemptRepository) FindAnswersByAttemptID(attemptID uint) ([]models.Answer, error) {
 	var answers []models.Answer
@@ -196,8 +286,25 @@ func (r *attemptRepository) FindAvailableAssessments(userID uint, params util.Pa
 			"(?) AS attempt_count", attemptCountSubquery).
 		Joins("JOIN users ON assessments.created_by_id = users.id").
 		Joins("LEFT JOIN assessment_settings ON assessments.id = assessment_settings.assessment_id").
-		Where("assessments.status = ? AND assessments.created_at <= ? AND (assessments.due_date IS NULL OR assessments.due_date >= ?)",
-			"active", time.Now().UTC(), time.Now().UTC())
+		Where("assessments.status = ?", models.AssessmentActive).
+		Where("assessments.available_from IS NULL OR assessments.available_from <= ?", time.Now().UTC()).
+		// Past the close only while late work is still accepted or an accommodation gives the user a later one
+		Where("COALESCE(assessments.available_until, assessments.due_date) IS NULL OR "+
+			"COALESCE(assessments.available_until, assessments.due_date) >= ? OR "+
+			"(COALESCE(assessment_settings.late_policy, 'reject') <> 'reject' AND "+
+			"(assessment_settings.late_cutoff IS NULL AND assessment_settings.late_policy = 'penalty' OR assessment_settings.late_cutoff >= ?)) OR "+
+			"assessments.id IN (?)",
+			time.Now().UTC(), time.Now().UTC(),
+			r.db.Model(&models.AssessmentAccommodation{}).
+				Select("assessment_id").
+				Where("due_date >= ?", time.Now().UTC()).
+				Where("user_id = ? OR group_id IN (?)", userID,
+					r.db.Model(&models.GroupMember{}).Select("group_id").Where("user_id = ?", userID))).
+		// Only assessments assigned to the user directly or through one of their groups
+		Where("assessments.id IN (?)", r.db.Model(&models.AssessmentAssignment{}).
+			Select("assessment_id").
+			Where("user_id = ? OR group_id IN (?)", userID,
+				r.db.Model(&models.GroupMember{}).Select("group_id").Where("user_id = ?", userID)))
 
 	// Apply search filter if provided
 	if search, ok := params.Filters["search"].(string); ok && search != "" {
@@ -284,7 +391,7 @@ func (r *attemptRepository) HasCompletedAssessment(userID, assessmentID uint) (b
 	var count int64
 
 	result := r.db.Model(&models.Attempt{}).
-		Where("user_id = ? AND assessment_id = ? AND status = ?", userID, assessmentID, "completed").
+		Where("user_id = ? AND assessment_id = ? AND status IN ?", userID, assessmentID, models.SubmittedAttemptStatuses).
 		Count(&count)
 
 	if result.Error != nil {
@@ -312,16 +419,16 @@ func (r *attemptRepository) CountAttemptsByUserAndAssessment(userID, assessmentI
 // FindCompletedAttemptsByUserAndAssessment finds all completed attempts by a user for a specific assessment
 func (r *attemptRepository) FindCompletedAttemptsByUserAndAssessment(userID, assessmentID uint) ([]map[string]interface{}, error) {
 	type Result struct {
-		AssessmentID uint      `gorm:"column:assessment_id" json:"assessmentId"`
-		ID           uint      `gorm:"column:id" json:"attemptId"`
-		StartedAt    time.Time `gorm:"column:started_at" json:"startedAt"`
-		SubmittedAt  time.Time `gorm:"column:submitted_at" json:"submittedAt"`
-		Score        float64   `gorm:"column:score"`
-		Duration     int       `gorm:"column:duration"`
-		Status       string    `gorm:"column:status"`
-		Title        string    `gorm:"column:title"`
-		PassingScore float64   `gorm:"column:passing_score" json:"passingScore"`
-		Feedback     string    `gorm:"column:feedback" json:"feedback"`
+		AssessmentID uint                 `gorm:"column:assessment_id" json:"assessmentId"`
+		ID           uint                 `gorm:"column:id" json:"attemptId"`
+		StartedAt    time.Time            `gorm:"column:started_at" json:"startedAt"`
+		SubmittedAt  time.Time            `gorm:"column:submitted_at" json:"submittedAt"`
+		Score        float64              `gorm:"column:score"`
+		Duration     int                  `gorm:"column:duration"`
+		Status       models.AttemptStatus `gorm:"column:status"`
+		Title        string               `gorm:"column:title"`
+		PassingScore float64              `gorm:"column:passing_score" json:"passingScore"`
+		Feedback     string               `gorm:"column:feedback" json:"feedback"`
 	}
 
 	var results []Result
@@ -329,7 +436,7 @@ func (r *attemptRepository) FindCompletedAttemptsByUserAndAssessment(userID, ass
 	err := r.db.Table("attempts").
 		Select("attempts.id, attempts.assessment_id, attempts.started_at, attempts.submitted_at, attempts.score, attempts.duration, attempts.status, assessments.title, assessments.passing_score, attempts.feedback").
 		Joins("JOIN assessments ON attempts.assessment_id = assessments.id").
-		Where("attempts.user_id = ? AND attempts.assessment_id = ? AND attempts.deleted_at IS NULL", userID, assessmentID).
+		Where("attempts.user_id = ? AND attempts.assessment_id = ? AND attempts.status IN ? AND attempts.deleted_at IS NULL", userID, assessmentID, models.SubmittedAttemptStatuses).
 		Order("attempts.submitted_at DESC").
 		Scan(&results).Error
 
@@ -377,10 +484,10 @@ func (r *attemptRepository) GetAssessmentCompletionRates() (map[string]interface
 			SELECT 
 				a.id, a.title,
 				COUNT(DISTINCT att.id) as total_attempts,
-				COUNT(DISTINCT CASE WHEN att.status = 'Completed' THEN att.id END) as completed_attempts,
+				COUNT(DISTINCT CASE WHEN att.status IN ? THEN att.id END) as completed_attempts,
 				COUNT(DISTINCT att.user_id) as total_users
 			FROM assessments a
-			LEFT JOIN attempts att ON a.id = att.assessment_id
+			LEFT JOIN attempts att ON a.id = att.assessment_id AND att.status <> ?
 			GROUP BY a.id, a.title
 		)
 		SELECT 
@@ -393,7 +500,7 @@ func (r *attemptRepository) GetAssessmentCompletionRates() (map[string]interface
 		FROM assessment_stats
 		ORDER BY total_attempts DESC
 		LIMIT 10
-	`).Scan(&results).Error
+	`, models.SubmittedAttemptStatuses, models.AttemptVoided).Scan(&results).Error
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch assessment completion rates: %w", err)
@@ -485,11 +592,11 @@ func (r *attemptRepository) GetAverageTimeSpent() (map[string]interface{}, error
 			a.duration as expected_minutes
 		FROM assessments a
 		JOIN attempts att ON a.id = att.assessment_id
-		WHERE att.status = 'Completed'
+		WHERE att.status IN ?
 		GROUP BY a.id, a.title, a.duration
 		ORDER BY avg_minutes DESC
 		LIMIT 10
-	`).Scan(&results).Error
+	`, models.SubmittedAttemptStatuses).Scan(&results).Error
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch average time spent: %w", err)
@@ -529,12 +636,12 @@ func (r *attemptRepository) GetMostChallengingAssessments(limit int) ([]map[stri
 			SELECT 
 				a.id, a.title,
 				COUNT(DISTINCT att.id) as total_attempts,
-				COUNT(DISTINCT CASE WHEN att.score >= a.passing_score THEN att.id END) as passed_attempts,
+				COUNT(DISTINCT CASE WHEN att.passed THEN att.id END) as passed_attempts,
 				AVG(att.score) as avg_score
 			FROM assessments a
 			JOIN attempts att ON a.id = att.assessment_id
-			WHERE att.status = 'Completed' AND att.score IS NOT NULL
-			GROUP BY a.id, a.title, a.passing_score
+			WHERE att.status = ? AND att.score IS NOT NULL
+			GROUP BY a.id, a.title
 			HAVING COUNT(att.id) >= 5
 		)
 		SELECT 
@@ -545,7 +652,7 @@ func (r *attemptRepository) GetMostChallengingAssessments(limit int) ([]map[stri
 		FROM assessment_stats
 		ORDER BY pass_rate ASC
 		LIMIT ?
-	`, limit).Scan(&results).Error
+	`, models.AttemptGraded, limit).Scan(&results).Error
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch challenging assessments: %w", err)
@@ -585,12 +692,12 @@ func (r *attemptRepository) GetMostSuccessfulAssessments(limit int) ([]map[strin
 			SELECT 
 				a.id, a.title,
 				COUNT(DISTINCT att.id) as total_attempts,
-				COUNT(DISTINCT CASE WHEN att.score >= a.passing_score THEN att.id END) as passed_attempts,
+				COUNT(DISTINCT CASE WHEN att.passed THEN att.id END) as passed_attempts,
 				AVG(att.score) as avg_score
 			FROM assessments a
 			JOIN attempts att ON a.id = att.assessment_id
-			WHERE att.status = 'Completed' AND att.score IS NOT NULL
-			GROUP BY a.id, a.title, a.passing_score
+			WHERE att.status = ? AND att.score IS NOT NULL
+			GROUP BY a.id, a.title
 			HAVING COUNT(att.id) >= 5
 		)
 		SELECT 
@@ -601,7 +708,7 @@ func (r *attemptRepository) GetMostSuccessfulAssessments(limit int) ([]map[strin
 		FROM assessment_stats
 		ORDER BY pass_rate DESC
 		LIMIT ?
-	`, limit).Scan(&results).Error
+	`, models.AttemptGraded, limit).Scan(&results).Error
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch successful assessments: %w", err)
@@ -635,14 +742,13 @@ func (r *attemptRepository) GetPassRate() (float64, error) {
 		SELECT
 			CASE WHEN COUNT(*) > 0 THEN
 				ROUND(
-					(COUNT(CASE WHEN att.score >= a.passing_score THEN 1 END)::numeric / COUNT(*)::numeric) * 100,
+					(COUNT(CASE WHEN att.passed THEN 1 END)::numeric / COUNT(*)::numeric) * 100,
 					2
 				)
 			ELSE 0 END as pass_rate
 		FROM attempts att
-		JOIN assessments a ON att.assessment_id = a.id
-		WHERE att.status = 'Completed' AND att.score IS NOT NULL
-	`).Scan(&result).Error
+		WHERE att.status = ? AND att.score IS NOT NULL
+	`, models.AttemptGraded).Scan(&result).Error
 
 	if err != nil {
 		return 0, fmt.Errorf("failed to calculate pass rate: %w", err)
@@ -725,11 +831,17 @@ func (r *attemptRepository) FindSuspiciousActivitiesByAttemptID(attemptID uint)
 	return activities, nil
 }
 
+// ExpiredAttempt returns the attempts in progress with their answers and the questions they were
+// given, for the scheduler to submit the ones whose time ran out
 func (r *attemptRepository) ExpiredAttempt() ([]models.Attempt, error) {
 	// check if any attempt is in progress
 	var attempts []models.Attempt
 
-	err := r.db.Model(&models.Attempt{}).Where("status = ?", "In Progress").Find(&attempts).Error
+	err := r.db.Model(&models.Attempt{}).
+		Preload("Answers").
+		Preload("Selection").
+		Where("status = ?", models.AttemptInProgress).
+		Find(&attempts).Error
 	if err != nil {
 		return nil, err
 	}
@@ -740,7 +852,7 @@ func (r *attemptRepository) ExpiredAttempt() ([]models.Attempt, error) {
 func (r *attemptRepository) IsUserInAttempt(userID uint) (bool, error) {
 	var count int64
 
-	err := r.db.Model(&models.Attempt{}).Where("status = ? AND user_id = ?", "In Progress", userID).Count(&count).Error
+	err := r.db.Model(&models.Attempt{}).Where("status = ? AND user_id = ?", models.AttemptInProgress, userID).Count(&count).Error
 	if err != nil {
 		return false, err
 	}
@@ -748,6 +860,21 @@ func (r *attemptRepository) IsUserInAttempt(userID uint) (bool, error) {
 	return count > 0, nil
 }
 
+func (r *attemptRepository) FindInProgressAttempt(userID, assessmentID uint) (*models.Attempt, error) {
+	var attempt models.Attempt
+	err := r.db.Where("user_id = ? AND assessment_id = ? AND status = ?", userID, assessmentID, models.AttemptInProgress).
+		Order("started_at DESC").
+		First(&attempt).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find attempt in progress: %w", err)
+	}
+
+	return r.FindByID(attempt.ID)
+}
+
 func (r *attemptRepository) GetAllAttemptByUserId(userID uint, params util.PaginationParams) ([]models.Attempt, int64, error) {
 	var attempts []models.Attempt
 	var total int64
@@ -816,3 +943,561 @@ func (r *attemptRepository) ListAttemptByUserAndAssessmentID(userID uint, assess
 
 	return attempts, total, nil
 }
+
+// FindPendingManualAnswers lists answers that wait for a grader, oldest submission first. Filters:
+// "assessmentId" limits the list to one assessment and "gradableBy" to assessments the user created
+// or may grade as a collaborator.
+func (r *attemptRepository) FindPendingManualAnswers(params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	var results []map[string]interface{}
+	var total int64
+
+	query := r.db.Table("answers").
+		Joins("JOIN attempts ON attempts.id = answers.attempt_id").
+		Joins("JOIN assessments ON assessments.id = attempts.assessment_id").
+		Joins("JOIN questions ON questions.id = answers.question_id").
+		Joins("JOIN users ON users.id = attempts.user_id").
+		Joins("LEFT JOIN assessment_settings ON assessment_settings.assessment_id = assessments.id").
+		Where("answers.awarded_points IS NULL AND attempts.status = ? AND attempts.deleted_at IS NULL", models.AttemptPendingManualGrading)
+
+	if params.Filters != nil {
+		if val, ok := params.Filters["assessmentId"]; ok {
+			query = query.Where("attempts.assessment_id = ?", val)
+		}
+
+		// Owners and graders may grade, see policy.grants
+		if val, ok := params.Filters["gradableBy"]; ok {
+			query = query.Where(
+				"assessments.created_by_id = ? OR assessments.id IN (?)",
+				val,
+				r.db.Model(&models.AssessmentCollaborator{}).Select("assessment_id").Where("user_id = ? AND role IN ?", val, []string{"owner", "grader"}),
+			)
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count pending answers: %w", err)
+	}
+
+	err := query.Select(`answers.id AS answer_id, answers.attempt_id, answers.answer,
+			attempts.assessment_id, assessments.title AS assessment_title,
+			answers.question_id, questions.text AS question_text, questions.points,
+			attempts.user_id, users.name AS user_name, attempts.submitted_at,
+			assessment_settings.blind_grading, (SELECT COUNT(*) FROM attempts AS numbered WHERE numbered.assessment_id = attempts.assessment_id AND numbered.id <= attempts.id) AS candidate_number,
+			(SELECT COUNT(*) FROM grading_assignments WHERE grading_assignments.answer_id = answers.id) AS graders`).
+		Order("attempts.submitted_at ASC, answers.id ASC").
+		Offset(params.Offset).Limit(params.Limit).
+		Find(&results).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find pending answers: %w", err)
+	}
+
+	return results, total, nil
+}
+
+// FindGraderIDs returns the users who may grade an assessment: its creator and its owner and grader
+// collaborators, see policy.grants
+func (r *attemptRepository) FindGraderIDs(assessmentID uint) ([]uint, error) {
+	var assessment models.Assessment
+	if err := r.db.Select("id", "created_by_id").First(&assessment, assessmentID).Error; err != nil {
+		return nil, fmt.Errorf("failed to find assessment: %w", err)
+	}
+
+	var collaborators []uint
+	err := r.db.Model(&models.AssessmentCollaborator{}).
+		Where("assessment_id = ? AND role IN ? AND user_id <> ?", assessmentID, []string{"owner", "grader"}, assessment.CreatedByID).
+		Order("id ASC").
+		Pluck("user_id", &collaborators).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find graders: %w", err)
+	}
+
+	return append([]uint{assessment.CreatedByID}, collaborators...), nil
+}
+
+// FindUnassignedManualAnswers returns the answers of an assessment that wait for a grader and have
+// not been given to one, oldest submission first
+func (r *attemptRepository) FindUnassignedManualAnswers(assessmentID uint) ([]models.Answer, error) {
+	var answers []models.Answer
+
+	err := r.db.Joins("JOIN attempts ON attempts.id = answers.attempt_id").
+		Where("attempts.assessment_id = ? AND attempts.status = ? AND attempts.deleted_at IS NULL", assessmentID, models.AttemptPendingManualGrading).
+		Where("answers.awarded_points IS NULL").
+		Where("NOT EXISTS (SELECT 1 FROM grading_assignments WHERE grading_assignments.answer_id = answers.id)").
+		Order("attempts.submitted_at ASC, answers.attempt_id ASC, answers.id ASC").
+		Find(&answers).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find unassigned answers: %w", err)
+	}
+
+	return answers, nil
+}
+
+// CreateGradingAssignments stores the assignments of one hand-out together
+func (r *attemptRepository) CreateGradingAssignments(assignments []models.GradingAssignment) error {
+	if len(assignments) == 0 {
+		return nil
+	}
+
+	if err := r.db.Create(&assignments).Error; err != nil {
+		return fmt.Errorf("failed to create grading assignments: %w", err)
+	}
+
+	return nil
+}
+
+// FindAssignmentsByAnswer returns the graders' assignments for an answer in the order they were made
+func (r *attemptRepository) FindAssignmentsByAnswer(answerID uint) ([]models.GradingAssignment, error) {
+	var assignments []models.GradingAssignment
+
+	if err := r.db.Where("answer_id = ?", answerID).Order("id ASC").Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("failed to find grading assignments: %w", err)
+	}
+
+	return assignments, nil
+}
+
+// UpdateGradingAssignment stores a grader's mark on their assignment
+func (r *attemptRepository) UpdateGradingAssignment(assignment *models.GradingAssignment) error {
+	assignment.UpdatedAt = time.Now()
+
+	if err := r.db.Omit("Grader").Save(assignment).Error; err != nil {
+		return fmt.Errorf("failed to update grading assignment: %w", err)
+	}
+
+	return nil
+}
+
+// FindAssignmentsByGrader lists a grader's assignments, the ones still to mark first. Filters:
+// "status" limits the list to assigned or completed work and "assessmentId" to one assessment.
+func (r *attemptRepository) FindAssignmentsByGrader(graderID uint, params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	var results []map[string]interface{}
+	var total int64
+
+	query := r.db.Table("grading_assignments").
+		Joins("JOIN answers ON answers.id = grading_assignments.answer_id").
+		Joins("JOIN attempts ON attempts.id = grading_assignments.attempt_id").
+		Joins("JOIN assessments ON assessments.id = grading_assignments.assessment_id").
+		Joins("JOIN questions ON questions.id = answers.question_id").
+		Joins("JOIN users ON users.id = attempts.user_id").
+		Joins("LEFT JOIN assessment_settings ON assessment_settings.assessment_id = assessments.id").
+		Where("grading_assignments.grader_id = ? AND attempts.deleted_at IS NULL AND attempts.status <> ?", graderID, models.AttemptVoided)
+
+	if params.Filters != nil {
+		if val, ok := params.Filters["status"]; ok {
+			query = query.Where("grading_assignments.status = ?", val)
+		}
+		if val, ok := params.Filters["assessmentId"]; ok {
+			query = query.Where("grading_assignments.assessment_id = ?", val)
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count grading assignments: %w", err)
+	}
+
+	err := query.Select(`grading_assignments.id, grading_assignments.status, grading_assignments.points AS marked_points,
+			grading_assignments.completed_at, grading_assignments.answer_id, grading_assignments.attempt_id, answers.answer,
+			grading_assignments.assessment_id, assessments.title AS assessment_title,
+			answers.question_id, questions.text AS question_text, questions.points,
+			attempts.user_id, users.name AS user_name, attempts.submitted_at,
+			assessment_settings.blind_grading,
+			(SELECT COUNT(*) FROM attempts AS numbered WHERE numbered.assessment_id = attempts.assessment_id AND numbered.id <= attempts.id) AS candidate_number`).
+		Order("CASE WHEN grading_assignments.status = 'assigned' THEN 0 ELSE 1 END, attempts.submitted_at ASC, grading_assignments.id ASC").
+		Offset(params.Offset).Limit(params.Limit).
+		Find(&results).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find grading assignments: %w", err)
+	}
+
+	return results, total, nil
+}
+
+// GetGradingProgress counts how far the manual grading of an assessment has come, overall and per
+// grader
+func (r *attemptRepository) GetGradingProgress(assessmentID uint) (map[string]interface{}, error) {
+	var attempts struct {
+		Pending int64
+		Graded  int64
+	}
+	err := r.db.Model(&models.Attempt{}).
+		Select("COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS pending, COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS graded",
+			models.AttemptPendingManualGrading, models.AttemptGraded).
+		Where("assessment_id = ?", assessmentID).
+		Scan(&attempts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count attempts: %w", err)
+	}
+
+	// Answers waiting for a grader, and of those the ones nobody was given and the ones whose two
+	// marks are both in but too far apart
+	var answers struct {
+		Pending      int64
+		Unassigned   int64
+		Reconcile    int64
+		ManualGraded int64
+	}
+	completed := "(SELECT COUNT(*) FROM grading_assignments WHERE grading_assignments.answer_id = answers.id AND grading_assignments.status = 'completed')"
+	assigned := "(SELECT COUNT(*) FROM grading_assignments WHERE grading_assignments.answer_id = answers.id)"
+	err = r.db.Model(&models.Answer{}).
+		Joins("JOIN attempts ON attempts.id = answers.attempt_id").
+		Select(`COALESCE(SUM(CASE WHEN answers.awarded_points IS NULL THEN 1 ELSE 0 END), 0) AS pending,
+				COALESCE(SUM(CASE WHEN answers.awarded_points IS NULL AND `+assigned+` = 0 THEN 1 ELSE 0 END), 0) AS unassigned,
+				COALESCE(SUM(CASE WHEN answers.awarded_points IS NULL AND `+completed+` >= 2 THEN 1 ELSE 0 END), 0) AS reconcile,
+				COALESCE(SUM(CASE WHEN answers.graded_by_id IS NOT NULL THEN 1 ELSE 0 END), 0) AS manual_graded`).
+		Where("attempts.assessment_id = ? AND attempts.deleted_at IS NULL AND attempts.status IN ?",
+			assessmentID, []models.AttemptStatus{models.AttemptPendingManualGrading, models.AttemptGraded}).
+		Scan(&answers).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count answers: %w", err)
+	}
+
+	type graderProgress struct {
+		GraderID  uint   `json:"graderId"`
+		Name      string `json:"name"`
+		Assigned  int64  `json:"assigned"`
+		Completed int64  `json:"completed"`
+	}
+	graders := make([]graderProgress, 0)
+	err = r.db.Table("grading_assignments").
+		Joins("JOIN users ON users.id = grading_assignments.grader_id").
+		Joins("JOIN attempts ON attempts.id = grading_assignments.attempt_id").
+		Select(`grading_assignments.grader_id, users.name, COUNT(*) AS assigned,
+				COALESCE(SUM(CASE WHEN grading_assignments.status = 'completed' THEN 1 ELSE 0 END), 0) AS completed`).
+		Where("grading_assignments.assessment_id = ? AND attempts.deleted_at IS NULL AND attempts.status <> ?", assessmentID, models.AttemptVoided).
+		Group("grading_assignments.grader_id, users.name").
+		Order("grading_assignments.grader_id ASC").
+		Scan(&graders).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count grader assignments: %w", err)
+	}
+
+	percentComplete := 0.0
+	if total := answers.ManualGraded + answers.Pending; total > 0 {
+		percentComplete = float64(answers.ManualGraded) / float64(total) * 100
+	}
+
+	return map[string]interface{}{
+		"assessmentId":       assessmentID,
+		"attemptsPending":    attempts.Pending,
+		"attemptsGraded":     attempts.Graded,
+		"answersPending":     answers.Pending,
+		"answersUnassigned":  answers.Unassigned,
+		"answersToReconcile": answers.Reconcile,
+		"answersGraded":      answers.ManualGraded,
+		"percentComplete":    percentComplete,
+		"graders":            graders,
+	}, nil
+}
+
+// CreateGradeEvents adds events to the grade history of their attempts
+func (r *attemptRepository) CreateGradeEvents(events []models.GradeEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	if err := r.db.Omit("Actor").Create(&events).Error; err != nil {
+		return fmt.Errorf("failed to create grade events: %w", err)
+	}
+
+	return nil
+}
+
+// SaveGrade stores the grading assignment, answer, attempt and regrade request the change names, and
+// its grade events, all or nothing
+func (r *attemptRepository) SaveGrade(change models.GradeChange) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		repo := &attemptRepository{db: tx}
+		if change.Assignment != nil {
+			if err := repo.UpdateGradingAssignment(change.Assignment); err != nil {
+				return err
+			}
+		}
+		if change.Answer != nil {
+			if err := repo.SaveAnswerGrade(change.Answer); err != nil {
+				return err
+			}
+		}
+		if change.Attempt != nil {
+			if err := repo.Update(change.Attempt); err != nil {
+				return err
+			}
+		}
+		if change.Request != nil {
+			if err := repo.UpdateRegradeRequest(change.Request); err != nil {
+				return err
+			}
+		}
+		return repo.CreateGradeEvents(change.Events)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save grade: %w", err)
+	}
+
+	return nil
+}
+
+// FindGradeEventsByAttempt returns the grade history of an attempt, oldest first
+func (r *attemptRepository) FindGradeEventsByAttempt(attemptID uint) ([]models.GradeEvent, error) {
+	var events []models.GradeEvent
+
+	err := r.db.Preload("Actor").
+		Where("attempt_id = ?", attemptID).
+		Order("created_at ASC, id ASC").
+		Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find grade events: %w", err)
+	}
+
+	return events, nil
+}
+
+func (r *attemptRepository) SaveTimeEvent(attempt *models.Attempt, event *models.AttemptTimeEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		attempt.UpdatedAt = time.Now()
+		err := tx.Model(&models.Attempt{}).
+			Where("id = ?", attempt.ID).
+			Select("paused_at", "paused_seconds", "extra_seconds", "updated_at").
+			Updates(attempt).Error
+		if err != nil {
+			return fmt.Errorf("failed to update attempt time: %w", err)
+		}
+
+		if err := tx.Create(event).Error; err != nil {
+			return fmt.Errorf("failed to create time event: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// FindTimeEventsByAttempt returns the pauses, resumes and extensions of an attempt, oldest first
+func (r *attemptRepository) FindTimeEventsByAttempt(attemptID uint) ([]models.AttemptTimeEvent, error) {
+	var events []models.AttemptTimeEvent
+
+	err := r.db.Where("attempt_id = ?", attemptID).
+		Order("created_at ASC, id ASC").
+		Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find time events: %w", err)
+	}
+
+	return events, nil
+}
+
+// SaveHeartbeat records when the student's client last reported in on the attempt's live channel
+func (r *attemptRepository) SaveHeartbeat(attemptID uint, at time.Time) error {
+	err := r.db.Model(&models.Attempt{}).
+		Where("id = ?", attemptID).
+		Update("heartbeat_at", at).Error
+	if err != nil {
+		return fmt.Errorf("failed to save heartbeat: %w", err)
+	}
+
+	return nil
+}
+
+func (r *attemptRepository) FindLiveAttempts(assessmentID uint) ([]models.Attempt, error) {
+	var attempts []models.Attempt
+
+	err := r.db.Preload("User").
+		Preload("Answers").
+		Preload("Selection").
+		Where("assessment_id = ? AND status = ?", assessmentID, models.AttemptInProgress).
+		Order("started_at ASC, id ASC").
+		Find(&attempts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find live attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+func (r *attemptRepository) FindSuspiciousActivitiesByAttempts(attemptIDs []uint) ([]models.SuspiciousActivity, error) {
+	var activities []models.SuspiciousActivity
+	if len(attemptIDs) == 0 {
+		return activities, nil
+	}
+
+	err := r.db.Where("attempt_id IN ?", attemptIDs).
+		Order("timestamp DESC, id DESC").
+		Find(&activities).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find suspicious activities: %w", err)
+	}
+
+	return activities, nil
+}
+
+func (r *attemptRepository) SaveProctorAction(attempt *models.Attempt) error {
+	attempt.UpdatedAt = time.Now()
+
+	err := r.db.Model(&models.Attempt{}).
+		Where("id = ?", attempt.ID).
+		Select("status", "ended_at", "flagged_at", "flagged_by", "flag_reason", "updated_at").
+		Updates(attempt).Error
+	if err != nil {
+		return fmt.Errorf("failed to save proctor action: %w", err)
+	}
+
+	return nil
+}
+
+// CreateRegradeRequest stores a student's regrade request
+func (r *attemptRepository) CreateRegradeRequest(request *models.RegradeRequest) error {
+	if err := r.db.Create(request).Error; err != nil {
+		return fmt.Errorf("failed to create regrade request: %w", err)
+	}
+
+	return nil
+}
+
+// FindRegradeRequestByID finds a regrade request by its ID
+func (r *attemptRepository) FindRegradeRequestByID(id uint) (*models.RegradeRequest, error) {
+	var request models.RegradeRequest
+
+	if err := r.db.First(&request, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("regrade request with ID %d not found: %w", id, gorm.ErrRecordNotFound)
+		}
+		return nil, fmt.Errorf("failed to find regrade request: %w", err)
+	}
+
+	return &request, nil
+}
+
+// FindRegradeRequestsByAttempt returns the regrade requests of an attempt, newest first
+func (r *attemptRepository) FindRegradeRequestsByAttempt(attemptID uint) ([]models.RegradeRequest, error) {
+	var requests []models.RegradeRequest
+
+	if err := r.db.Where("attempt_id = ?", attemptID).Order("created_at DESC, id DESC").Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to find regrade requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+// FindRegradeRequests lists regrade requests, oldest first. Filters: "status" limits the list to
+// one status, "assessmentId" to one assessment and "gradableBy" to assessments the user created or
+// may grade as a collaborator.
+func (r *attemptRepository) FindRegradeRequests(params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	var results []map[string]interface{}
+	var total int64
+
+	query := r.db.Table("regrade_requests").
+		Joins("JOIN attempts ON attempts.id = regrade_requests.attempt_id").
+		Joins("JOIN assessments ON assessments.id = attempts.assessment_id").
+		Joins("JOIN users ON users.id = regrade_requests.user_id").
+		Joins("LEFT JOIN assessment_settings ON assessment_settings.assessment_id = assessments.id").
+		Where("attempts.deleted_at IS NULL")
+
+	if params.Filters != nil {
+		if val, ok := params.Filters["status"]; ok {
+			query = query.Where("regrade_requests.status = ?", val)
+		}
+		if val, ok := params.Filters["assessmentId"]; ok {
+			query = query.Where("attempts.assessment_id = ?", val)
+		}
+		if val, ok := params.Filters["gradableBy"]; ok {
+			query = query.Where(
+				"assessments.created_by_id = ? OR assessments.id IN (?)",
+				val,
+				r.db.Model(&models.AssessmentCollaborator{}).Select("assessment_id").Where("user_id = ? AND role IN ?", val, []string{"owner", "grader"}),
+			)
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count regrade requests: %w", err)
+	}
+
+	err := query.Select(`regrade_requests.id, regrade_requests.attempt_id, regrade_requests.answer_id,
+			regrade_requests.reason, regrade_requests.status, regrade_requests.response, regrade_requests.created_at,
+			attempts.assessment_id, assessments.title AS assessment_title, attempts.score,
+			regrade_requests.user_id, users.name AS user_name, assessment_settings.blind_grading,
+			(SELECT COUNT(*) FROM attempts AS numbered WHERE numbered.assessment_id = attempts.assessment_id AND numbered.id <= attempts.id) AS candidate_number`).
+		Order("regrade_requests.created_at ASC, regrade_requests.id ASC").
+		Offset(params.Offset).Limit(params.Limit).
+		Find(&results).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find regrade requests: %w", err)
+	}
+
+	return results, total, nil
+}
+
+// UpdateRegradeRequest stores the review of a regrade request
+func (r *attemptRepository) UpdateRegradeRequest(request *models.RegradeRequest) error {
+	request.UpdatedAt = time.Now()
+
+	if err := r.db.Save(request).Error; err != nil {
+		return fmt.Errorf("failed to update regrade request: %w", err)
+	}
+
+	return nil
+}
+
+// FindScoredAttempts returns the submitted attempts of an assessment with their answers, leaving out
+// attempts in progress and voided attempts
+func (r *attemptRepository) FindScoredAttempts(assessmentID uint) ([]models.Attempt, error) {
+	var attempts []models.Attempt
+
+	err := r.db.Preload("Answers").
+		Preload("Selection").
+		Where("assessment_id = ? AND status IN ?", assessmentID, []models.AttemptStatus{
+			models.AttemptSubmitted,
+			models.AttemptAutoSubmitted,
+			models.AttemptPendingManualGrading,
+			models.AttemptGraded,
+		}).
+		Order("id ASC").
+		Find(&attempts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find scored attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// SaveRescore stores the new points of answers and the new scores and outcomes of attempts with
+// their grade events, all or nothing
+func (r *attemptRepository) SaveRescore(attempts []*models.Attempt, answers []*models.Answer, events []models.GradeEvent) error {
+	now := time.Now()
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, answer := range answers {
+			answer.UpdatedAt = now
+			err := tx.Model(&models.Answer{}).Where("id = ?", answer.ID).Updates(map[string]interface{}{
+				"is_correct":     answer.IsCorrect,
+				"awarded_points": answer.AwardedPoints,
+				"updated_at":     now,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, attempt := range attempts {
+			attempt.UpdatedAt = now
+			err := tx.Model(&models.Attempt{}).Where("id = ?", attempt.ID).Updates(map[string]interface{}{
+				"score":      attempt.Score,
+				"passed":     attempt.Passed,
+				"updated_at": now,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(events) > 0 {
+			if err := tx.Omit("Actor").Create(&events).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save rescore: %w", err)
+	}
+
+	return nil
+}
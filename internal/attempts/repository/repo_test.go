@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv" // Import strconv
@@ -32,10 +33,23 @@ func setupTestSQLiteDatabase(t *testing.T) *gorm.DB {
 		&models.AssessmentSettings{},
 		&models.Question{},
 		&models.QuestionOption{},
+		&models.AssessmentSection{},
 		&models.Attempt{},
+		&models.AttemptQuestion{},
+		&models.AttemptTimeEvent{},
 		&models.Answer{},
+		&models.RubricScore{},
+		&models.GradingAssignment{},
+		&models.GradeEvent{},
+		&models.RegradeRequest{},
+		&models.AssessmentCollaborator{},
 		&models.Activity{},
 		&models.SuspiciousActivity{},
+		&models.Group{},
+		&models.GroupMember{},
+		&models.AssessmentAssignment{},
+		&models.AssessmentAccommodation{},
+		&models.AssessmentStatusTransition{},
 	)
 	require.NoError(t, err, "Failed to run migrations on SQLite")
 
@@ -59,9 +73,9 @@ func TestAttemptRepository_SQLite(t *testing.T) {
 	require.NoError(t, db.Create(&user2).Error)
 	require.NoError(t, db.Create(&teacher).Error)
 
-	assessment1 := models.Assessment{Title: "Math Quiz", Subject: "Math", Duration: 30, CreatedByID: teacher.ID, Status: "active", PassingScore: 70, CreatedAt: time.Now().UTC().AddDate(0, 0, -1)}
-	assessment2 := models.Assessment{Title: "Science Test", Subject: "Science", Duration: 60, CreatedByID: teacher.ID, Status: "active", PassingScore: 60, CreatedAt: time.Now().UTC().AddDate(0, 0, -1)}
-	assessmentDraft := models.Assessment{Title: "Draft Quiz", Subject: "Draft", Duration: 10, CreatedByID: teacher.ID, Status: "draft", PassingScore: 50}
+	assessment1 := models.Assessment{Title: "Math Quiz", Subject: "Math", Duration: 30, CreatedByID: teacher.ID, Status: models.AssessmentActive, PassingScore: 70, CreatedAt: time.Now().UTC().AddDate(0, 0, -1)}
+	assessment2 := models.Assessment{Title: "Science Test", Subject: "Science", Duration: 60, CreatedByID: teacher.ID, Status: models.AssessmentActive, PassingScore: 60, CreatedAt: time.Now().UTC().AddDate(0, 0, -1)}
+	assessmentDraft := models.Assessment{Title: "Draft Quiz", Subject: "Draft", Duration: 10, CreatedByID: teacher.ID, Status: models.AssessmentDraft, PassingScore: 50}
 	require.NoError(t, db.Create(&assessment1).Error)
 	require.NoError(t, db.Create(&assessment2).Error)
 	require.NoError(t, db.Create(&assessmentDraft).Error)
@@ -82,6 +96,16 @@ func TestAttemptRepository_SQLite(t *testing.T) {
 	require.NoError(t, db.Create(&setting1).Error)
 	require.NoError(t, db.Create(&assessmentSettings2).Error)
 
+	// Assessment 1 được giao trực tiếp cho user1, assessment 2 được giao qua lớp của user1
+	class := models.Group{Name: "Class 10A", CreatedByID: teacher.ID}
+	require.NoError(t, db.Create(&class).Error)
+	require.NoError(t, db.Create(&models.GroupMember{GroupID: class.ID, UserID: user1.ID}).Error)
+	require.NoError(t, db.Create(&[]models.AssessmentAssignment{
+		{AssessmentID: assessment1.ID, UserID: &user1.ID},
+		{AssessmentID: assessment2.ID, GroupID: &class.ID},
+		{AssessmentID: assessmentDraft.ID, GroupID: &class.ID},
+	}).Error)
+
 	question1_1 := models.Question{AssessmentID: assessment1.ID, Type: "true-false", Text: "2+2=4?", CorrectAnswer: "true", Points: 5}
 	question1_2 := models.Question{AssessmentID: assessment1.ID, Type: "essay", Text: "Describe Pi", Points: 10}
 	require.NoError(t, db.Create(&question1_1).Error)
@@ -102,7 +126,7 @@ func TestAttemptRepository_SQLite(t *testing.T) {
 			UserID:       user1.ID,
 			AssessmentID: assessment1.ID,
 			StartedAt:    now,
-			Status:       "In Progress", // Tạo attempt này là In Progress ban đầu
+			Status:       models.AttemptInProgress, // Tạo attempt này là In Progress ban đầu
 		}
 		err := repo.Create(attempt)
 		assert.NoError(t, err)
@@ -116,14 +140,14 @@ func TestAttemptRepository_SQLite(t *testing.T) {
 		assert.NoError(t, errFetch)
 		assert.Equal(t, user1.ID, fetchedAttempt.UserID)
 		assert.Equal(t, assessment1.ID, fetchedAttempt.AssessmentID)
-		assert.Equal(t, "In Progress", fetchedAttempt.Status)
+		assert.Equal(t, models.AttemptInProgress, fetchedAttempt.Status)
 		assert.WithinDuration(t, now, fetchedAttempt.StartedAt, time.Second)
 	})
 	require.NotZero(t, createdAttemptID)
 
 	// Tạo Attempt "In Progress" cho user2 để test IsUserInAttempt và ExpiredAttempt
 	// Tạo sau TestCreate để đảm bảo createdAttemptID có giá trị
-	inProgressAttempt = &models.Attempt{UserID: user2.ID, AssessmentID: assessment2.ID, StartedAt: time.Now(), Status: "In Progress"}
+	inProgressAttempt = &models.Attempt{UserID: user2.ID, AssessmentID: assessment2.ID, StartedAt: time.Now(), Status: models.AttemptInProgress}
 	require.NoError(t, repo.Create(inProgressAttempt), "Failed to create in-progress attempt for user 2")
 	require.NotZero(t, inProgressAttempt.ID, "In-progress attempt ID should not be zero")
 
@@ -154,7 +178,7 @@ func TestAttemptRepository_SQLite(t *testing.T) {
 		submitted := now.Add(-1 * time.Minute)
 		score := 85.5
 		duration := 25
-		attemptToUpdate.Status = "completed" // Cập nhật status
+		attemptToUpdate.Status = models.AttemptGraded // Cập nhật status
 		attemptToUpdate.SubmittedAt = &submitted
 		attemptToUpdate.EndedAt = &now
 		attemptToUpdate.Score = &score
@@ -167,7 +191,7 @@ func TestAttemptRepository_SQLite(t *testing.T) {
 		updatedAttempt, err := repo.FindByID(createdAttemptID)
 		assert.NoError(t, err)
 		require.NotNil(t, updatedAttempt)
-		assert.Equal(t, "completed", updatedAttempt.Status) // Kiểm tra status đã cập nhật
+		assert.Equal(t, models.AttemptGraded, updatedAttempt.Status) // Kiểm tra status đã cập nhật
 		require.NotNil(t, updatedAttempt.SubmittedAt)
 		assert.WithinDuration(t, submitted, *updatedAttempt.SubmittedAt, time.Second)
 		require.NotNil(t, updatedAttempt.Score)
@@ -338,6 +362,57 @@ func TestAttemptRepository_SQLite(t *testing.T) {
 		assert.True(t, foundAssessment1, "Assessment 1 should be available (retake allowed)")
 		assert.True(t, foundAssessment2, "Assessment 2 should be available")
 		assert.False(t, foundDraft, "Draft assessment should not be available")
+
+		// User 2 không được giao bài nào
+		available, total, err = repo.FindAvailableAssessments(user2.ID, params)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), total)
+		assert.Empty(t, available)
+
+		// Bài đã quá hạn chỉ hiện với user có accommodation gia hạn
+		yesterday := time.Now().UTC().AddDate(0, 0, -1)
+		tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+		overdue := models.Assessment{Title: "Overdue Essay", Subject: "English", Duration: 30, CreatedByID: teacher.ID, Status: models.AssessmentActive, PassingScore: 50, DueDate: &yesterday}
+		require.NoError(t, db.Create(&overdue).Error)
+		require.NoError(t, db.Create(&models.AssessmentSettings{AssessmentID: overdue.ID, MaxAttempts: 1}).Error)
+		require.NoError(t, db.Create(&[]models.AssessmentAssignment{
+			{AssessmentID: overdue.ID, UserID: &user1.ID},
+			{AssessmentID: overdue.ID, UserID: &user2.ID},
+		}).Error)
+		require.NoError(t, db.Create(&models.AssessmentAccommodation{AssessmentID: overdue.ID, UserID: &user2.ID, TimeMultiplier: 1, DueDate: &tomorrow}).Error)
+
+		available, _, err = repo.FindAvailableAssessments(user1.ID, params)
+		assert.NoError(t, err)
+		for _, item := range available {
+			assert.NotEqual(t, "Overdue Essay", item["title"])
+		}
+
+		available, total, err = repo.FindAvailableAssessments(user2.ID, params)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, available, 1)
+		assert.Equal(t, "Overdue Essay", available[0]["title"])
+
+		// Bài chưa mở không hiện, bài đã đóng nhưng còn nhận nộp muộn vẫn hiện
+		lateLab := models.Assessment{Title: "Late Lab", Subject: "Physics", Duration: 30, CreatedByID: teacher.ID, Status: models.AssessmentActive, PassingScore: 50, AvailableUntil: &yesterday}
+		futureExam := models.Assessment{Title: "Future Exam", Subject: "Physics", Duration: 30, CreatedByID: teacher.ID, Status: models.AssessmentActive, PassingScore: 50, AvailableFrom: &tomorrow}
+		require.NoError(t, db.Create(&lateLab).Error)
+		require.NoError(t, db.Create(&futureExam).Error)
+		require.NoError(t, db.Create(&models.AssessmentSettings{AssessmentID: lateLab.ID, MaxAttempts: 1, LatePolicy: models.LatePolicyPenalty, LatePenalty: 10}).Error)
+		require.NoError(t, db.Create(&models.AssessmentSettings{AssessmentID: futureExam.ID, MaxAttempts: 1}).Error)
+		require.NoError(t, db.Create(&[]models.AssessmentAssignment{
+			{AssessmentID: lateLab.ID, UserID: &user2.ID},
+			{AssessmentID: futureExam.ID, UserID: &user2.ID},
+		}).Error)
+
+		available, total, err = repo.FindAvailableAssessments(user2.ID, params)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		var titles []interface{}
+		for _, item := range available {
+			titles = append(titles, item["title"])
+		}
+		assert.ElementsMatch(t, []interface{}{"Overdue Essay", "Late Lab"}, titles)
 	})
 
 	t.Run("TestHasCompletedAssessment", func(t *testing.T) {
@@ -380,7 +455,7 @@ func TestAttemptRepository_SQLite(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Len(t, results, 1)
 		assert.Equal(t, float64(85.5), results[0]["score"]) // Điểm đã update
-		assert.Equal(t, "completed", results[0]["status"])
+		assert.Equal(t, models.AttemptGraded, results[0]["status"])
 
 		// User 2 không có attempt completed nào cho assessment 1
 		results, err = repo.FindCompletedAttemptsByUserAndAssessment(user2.ID, assessment1.ID)
@@ -483,6 +558,113 @@ func TestAttemptRepository_SQLite(t *testing.T) {
 
 	})
 
+	t.Run("TestFindInProgressAttempt", func(t *testing.T) {
+		// User 2 tiếp tục attempt đang diễn ra của bài kiểm tra 2
+		found, err := repo.FindInProgressAttempt(user2.ID, assessment2.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, inProgressAttempt.ID, found.ID)
+
+		// Không có attempt đang diễn ra thì trả về nil
+		found, err = repo.FindInProgressAttempt(user2.ID, assessment1.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+
+		found, err = repo.FindInProgressAttempt(user1.ID, assessment1.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("TestSaveTimeEvent", func(t *testing.T) {
+		pausedAt := time.Now().Add(-time.Minute)
+		live := &models.Attempt{ID: inProgressAttempt.ID, PausedAt: &pausedAt, PausedSeconds: 30, ExtraSeconds: 300}
+		require.NoError(t, repo.SaveTimeEvent(live, &models.AttemptTimeEvent{AttemptID: live.ID, Kind: models.TimeEventExtend, Seconds: 300, ActorID: user1.ID, Reason: "Network outage"}))
+		require.NoError(t, repo.SaveTimeEvent(live, &models.AttemptTimeEvent{AttemptID: live.ID, Kind: models.TimeEventPause, ActorID: user1.ID}))
+
+		// Chỉ các cột đồng hồ được cập nhật
+		found, err := repo.FindByID(live.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found.PausedAt)
+		assert.WithinDuration(t, pausedAt, *found.PausedAt, time.Second)
+		assert.Equal(t, 30, found.PausedSeconds)
+		assert.Equal(t, 300, found.ExtraSeconds)
+		assert.Equal(t, models.AttemptInProgress, found.Status)
+		assert.Equal(t, user2.ID, found.UserID)
+
+		events, err := repo.FindTimeEventsByAttempt(live.ID)
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, models.TimeEventExtend, events[0].Kind)
+		assert.Equal(t, "Network outage", events[0].Reason)
+		assert.Equal(t, models.TimeEventPause, events[1].Kind)
+
+		// Đặt lại để các test sau không bị ảnh hưởng
+		live.PausedAt = nil
+		live.PausedSeconds = 0
+		live.ExtraSeconds = 0
+		require.NoError(t, repo.SaveTimeEvent(live, &models.AttemptTimeEvent{AttemptID: live.ID, Kind: models.TimeEventResume, ActorID: user1.ID}))
+	})
+
+	t.Run("TestLiveProctoring", func(t *testing.T) {
+		heartbeat := time.Now().Add(-10 * time.Second)
+		require.NoError(t, repo.SaveHeartbeat(inProgressAttempt.ID, heartbeat))
+
+		// Một bài làm khác của bài kiểm tra 2, sẽ bị đình chỉ
+		other := &models.Attempt{UserID: user1.ID, AssessmentID: assessment2.ID, StartedAt: time.Now().Add(time.Minute), Status: models.AttemptInProgress}
+		require.NoError(t, repo.Create(other))
+
+		attempts, err := repo.FindLiveAttempts(assessment2.ID)
+		require.NoError(t, err)
+		require.Len(t, attempts, 2)
+		assert.Equal(t, inProgressAttempt.ID, attempts[0].ID)
+		assert.Equal(t, user2.Name, attempts[0].User.Name)
+		require.NotNil(t, attempts[0].HeartbeatAt)
+		assert.WithinDuration(t, heartbeat, *attempts[0].HeartbeatAt, time.Second)
+		assert.Equal(t, other.ID, attempts[1].ID)
+		assert.Nil(t, attempts[1].HeartbeatAt)
+
+		// Hoạt động đáng ngờ mới nhất đứng đầu
+		older := &models.SuspiciousActivity{AttemptID: inProgressAttempt.ID, UserID: user2.ID, AssessmentID: assessment2.ID, Type: "TAB_SWITCH", Timestamp: time.Now().Add(-time.Minute)}
+		newer := &models.SuspiciousActivity{AttemptID: other.ID, UserID: user1.ID, AssessmentID: assessment2.ID, Type: "FACE_MISSING", Timestamp: time.Now()}
+		require.NoError(t, repo.SaveSuspiciousActivity(older))
+		require.NoError(t, repo.SaveSuspiciousActivity(newer))
+
+		activities, err := repo.FindSuspiciousActivitiesByAttempts([]uint{inProgressAttempt.ID, other.ID})
+		require.NoError(t, err)
+		require.Len(t, activities, 2)
+		assert.Equal(t, newer.ID, activities[0].ID)
+		assert.Equal(t, older.ID, activities[1].ID)
+
+		activities, err = repo.FindSuspiciousActivitiesByAttempts(nil)
+		require.NoError(t, err)
+		assert.Empty(t, activities)
+
+		// Đình chỉ chỉ cập nhật trạng thái, thời điểm kết thúc và đánh dấu
+		endedAt := time.Now()
+		require.NoError(t, other.TransitionTo(models.AttemptVoided))
+		other.EndedAt = &endedAt
+		other.Flag(teacher.ID, "Phone in hand", endedAt)
+		score := 99.0
+		other.Score = &score
+		require.NoError(t, repo.SaveProctorAction(other))
+
+		found, err := repo.FindByID(other.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.AttemptVoided, found.Status)
+		require.NotNil(t, found.EndedAt)
+		require.NotNil(t, found.FlaggedAt)
+		require.NotNil(t, found.FlaggedBy)
+		assert.Equal(t, teacher.ID, *found.FlaggedBy)
+		assert.Equal(t, "Phone in hand", found.FlagReason)
+		assert.Nil(t, found.Score)
+
+		// Bài đã bị đình chỉ không còn trên bảng giám sát
+		attempts, err = repo.FindLiveAttempts(assessment2.ID)
+		require.NoError(t, err)
+		require.Len(t, attempts, 1)
+		assert.Equal(t, inProgressAttempt.ID, attempts[0].ID)
+	})
+
 	t.Run("TestExpiredAttempt", func(t *testing.T) {
 		// Sử dụng attempt đang diễn ra của user2 đã tạo ở test trước
 		expired, err := repo.ExpiredAttempt() // Hàm này chỉ lấy các attempt "In Progress"
@@ -493,7 +675,7 @@ func TestAttemptRepository_SQLite(t *testing.T) {
 		for _, att := range expired {
 			if att.ID == inProgressAttempt.ID { // Kiểm tra đúng ID của attempt đang chạy
 				foundUser2Attempt = true
-				assert.Equal(t, "In Progress", att.Status)
+				assert.Equal(t, models.AttemptInProgress, att.Status)
 				break
 			}
 		}
@@ -528,4 +710,328 @@ func TestAttemptRepository_SQLite(t *testing.T) {
 		assert.True(t, deletedAttempt.DeletedAt.Valid) // Kiểm tra cờ Valid của gorm.DeletedAt
 	})
 
+	t.Run("TestManualGrading", func(t *testing.T) {
+		// Bài của user2 có một câu tự chấm và một bài luận chờ chấm
+		submittedAt := time.Now().Add(-time.Hour)
+		pendingAttempt := &models.Attempt{UserID: user2.ID, AssessmentID: assessment1.ID, StartedAt: submittedAt.Add(-time.Hour), SubmittedAt: &submittedAt, Status: models.AttemptPendingManualGrading}
+		require.NoError(t, repo.Create(pendingAttempt))
+		awarded := 5.0
+		require.NoError(t, repo.SaveAnswer(&models.Answer{AttemptID: pendingAttempt.ID, QuestionID: question1_1.ID, Answer: "true", AwardedPoints: &awarded}))
+		essay := &models.Answer{AttemptID: pendingAttempt.ID, QuestionID: question1_2.ID, Answer: "Pi is a ratio"}
+		require.NoError(t, repo.SaveAnswer(essay))
+
+		grader := models.User{Name: "Grader", Email: "grader@test.com", Password: "pw", Role: "teacher", Status: "Active"}
+		editor := models.User{Name: "Editor", Email: "editor@test.com", Password: "pw", Role: "teacher", Status: "Active"}
+		require.NoError(t, db.Create(&grader).Error)
+		require.NoError(t, db.Create(&editor).Error)
+		require.NoError(t, db.Create(&[]models.AssessmentCollaborator{
+			{AssessmentID: assessment1.ID, UserID: grader.ID, Role: "grader"},
+			{AssessmentID: assessment1.ID, UserID: editor.ID, Role: "editor"},
+		}).Error)
+
+		// Chỉ bài luận nằm trong hàng đợi
+		queue, total, err := repo.FindPendingManualAnswers(util.PaginationParams{Page: 1, Limit: 10, Offset: 0, Filters: map[string]interface{}{}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, queue, 1)
+		assert.EqualValues(t, essay.ID, queue[0]["answer_id"])
+		assert.Equal(t, "Math Quiz", queue[0]["assessment_title"])
+		assert.Equal(t, "Describe Pi", queue[0]["question_text"])
+		assert.Equal(t, "Student User 2", queue[0]["user_name"])
+
+		for _, tc := range []struct {
+			filters map[string]interface{}
+			want    int64
+		}{
+			{map[string]interface{}{"gradableBy": teacher.ID}, 1},
+			{map[string]interface{}{"gradableBy": grader.ID}, 1},
+			{map[string]interface{}{"gradableBy": editor.ID}, 0}, // Editor không được chấm bài
+			{map[string]interface{}{"assessmentId": assessment2.ID}, 0},
+		} {
+			_, total, err := repo.FindPendingManualAnswers(util.PaginationParams{Page: 1, Limit: 10, Filters: tc.filters})
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, total, tc.filters)
+		}
+
+		// Chấm bài luận theo rubric, chấm lại thì điểm rubric cũ bị thay thế
+		points := 8.0
+		essay.AwardedPoints = &points
+		essay.Comment = "Good"
+		essay.RubricScores = []models.RubricScore{{CriterionID: "accuracy", Points: 5}, {CriterionID: "clarity", Points: 3}}
+		require.NoError(t, repo.SaveAnswerGrade(essay))
+		essay.RubricScores = []models.RubricScore{{CriterionID: "accuracy", Points: 5}, {CriterionID: "clarity", Points: 3, Comment: "Regraded"}}
+		require.NoError(t, repo.SaveAnswerGrade(essay))
+
+		graded, err := repo.FindByID(pendingAttempt.ID)
+		require.NoError(t, err)
+		for _, answer := range graded.Answers {
+			if answer.ID == essay.ID {
+				require.NotNil(t, answer.AwardedPoints)
+				assert.Equal(t, 8.0, *answer.AwardedPoints)
+				assert.Equal(t, "Good", answer.Comment)
+				require.Len(t, answer.RubricScores, 2)
+			}
+		}
+
+		_, total, err = repo.FindPendingManualAnswers(util.PaginationParams{Page: 1, Limit: 10, Filters: map[string]interface{}{}})
+		require.NoError(t, err)
+		assert.Zero(t, total)
+	})
+
+	t.Run("TestGraderAssignments", func(t *testing.T) {
+		// Hai bài luận của assessment 2 chờ chấm, có một người chấm ngoài giáo viên tạo bài
+		question := models.Question{AssessmentID: assessment2.ID, Type: "essay", Text: "Explain gravity", Points: 10}
+		require.NoError(t, db.Create(&question).Error)
+		marker := models.User{Name: "Marker", Email: "marker@test.com", Password: "pw", Role: "teacher", Status: "Active"}
+		require.NoError(t, db.Create(&marker).Error)
+		require.NoError(t, db.Create(&models.AssessmentCollaborator{AssessmentID: assessment2.ID, UserID: marker.ID, Role: "grader"}).Error)
+
+		var essays []*models.Answer
+		for _, student := range []models.User{user1, user2} {
+			submittedAt := time.Now().Add(-time.Hour)
+			attempt := &models.Attempt{UserID: student.ID, AssessmentID: assessment2.ID, StartedAt: submittedAt.Add(-time.Hour), SubmittedAt: &submittedAt, Status: models.AttemptPendingManualGrading}
+			require.NoError(t, repo.Create(attempt))
+			essay := &models.Answer{AttemptID: attempt.ID, QuestionID: question.ID, Answer: "Things fall"}
+			require.NoError(t, repo.SaveAnswer(essay))
+			essays = append(essays, essay)
+		}
+
+		graders, err := repo.FindGraderIDs(assessment2.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []uint{teacher.ID, marker.ID}, graders)
+
+		unassigned, err := repo.FindUnassignedManualAnswers(assessment2.ID)
+		require.NoError(t, err)
+		require.Len(t, unassigned, 2)
+
+		// Bài đầu được chấm hai lần, bài sau chỉ người chấm
+		require.NoError(t, repo.CreateGradingAssignments([]models.GradingAssignment{
+			{AssessmentID: assessment2.ID, AttemptID: essays[0].AttemptID, AnswerID: essays[0].ID, GraderID: teacher.ID, Status: models.GradingAssigned},
+			{AssessmentID: assessment2.ID, AttemptID: essays[0].AttemptID, AnswerID: essays[0].ID, GraderID: marker.ID, Status: models.GradingAssigned},
+			{AssessmentID: assessment2.ID, AttemptID: essays[1].AttemptID, AnswerID: essays[1].ID, GraderID: marker.ID, Status: models.GradingAssigned},
+		}))
+		unassigned, err = repo.FindUnassignedManualAnswers(assessment2.ID)
+		require.NoError(t, err)
+		assert.Empty(t, unassigned)
+
+		// Chấm ẩn danh được bật cho assessment 2
+		require.NoError(t, db.Model(&models.AssessmentSettings{}).Where("assessment_id = ?", assessment2.ID).Update("blind_grading", true).Error)
+
+		mine, total, err := repo.FindAssignmentsByGrader(marker.ID, util.PaginationParams{Limit: 10, Filters: map[string]interface{}{}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		require.Len(t, mine, 2)
+		assert.Equal(t, "Science Test", mine[0]["assessment_title"])
+		assert.Equal(t, "Explain gravity", mine[0]["question_text"])
+		assert.NotNil(t, mine[0]["blind_grading"])
+		assert.NotNil(t, mine[0]["candidate_number"])
+		assert.NotEqual(t, mine[0]["candidate_number"], mine[1]["candidate_number"])
+
+		assignments, err := repo.FindAssignmentsByAnswer(essays[0].ID)
+		require.NoError(t, err)
+		require.Len(t, assignments, 2)
+		assert.Equal(t, teacher.ID, assignments[0].GraderID)
+
+		points := 6.0
+		now := time.Now()
+		assignments[0].Status = models.GradingCompleted
+		assignments[0].Points = &points
+		assignments[0].RubricScores = models.RubricScoreList{{CriterionID: "physics", Points: 6, Comment: "Solid"}}
+		assignments[0].CompletedAt = &now
+		require.NoError(t, repo.UpdateGradingAssignment(&assignments[0]))
+
+		reloaded, err := repo.FindAssignmentsByAnswer(essays[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.GradingCompleted, reloaded[0].Status)
+		require.Len(t, reloaded[0].RubricScores, 1)
+		assert.Equal(t, "Solid", reloaded[0].RubricScores[0].Comment)
+		assert.Nil(t, reloaded[1].RubricScores)
+
+		_, total, err = repo.FindAssignmentsByGrader(teacher.ID, util.PaginationParams{Limit: 10, Filters: map[string]interface{}{"status": models.GradingCompleted}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+
+		progress, err := repo.GetGradingProgress(assessment2.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, progress["attemptsPending"])
+		assert.EqualValues(t, 2, progress["answersPending"])
+		assert.EqualValues(t, 0, progress["answersUnassigned"])
+		assert.EqualValues(t, 0, progress["answersToReconcile"])
+
+		// Người chấm thứ hai chấm xong, hai điểm chưa được hoà giải
+		assignments[1].Status = models.GradingCompleted
+		assignments[1].Points = &points
+		require.NoError(t, repo.UpdateGradingAssignment(&assignments[1]))
+
+		progress, err = repo.GetGradingProgress(assessment2.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, progress["answersToReconcile"])
+		assert.Zero(t, progress["percentComplete"])
+		graderProgress, err := json.Marshal(progress["graders"])
+		require.NoError(t, err)
+		assert.JSONEq(t, fmt.Sprintf(`[
+			{"graderId":%d,"name":"Teacher User","assigned":1,"completed":1},
+			{"graderId":%d,"name":"Marker","assigned":2,"completed":1}
+		]`, teacher.ID, marker.ID), string(graderProgress))
+	})
+
+	t.Run("TestGradeEventsAndRegradeRequests", func(t *testing.T) {
+		score := 80.0
+		attempt := &models.Attempt{UserID: user2.ID, AssessmentID: assessment1.ID, StartedAt: time.Now().Add(-time.Hour), Status: models.AttemptGraded, Score: &score}
+		require.NoError(t, repo.Create(attempt))
+
+		regraded := 90.0
+		require.NoError(t, repo.CreateGradeEvents([]models.GradeEvent{
+			{AttemptID: attempt.ID, Kind: models.GradeEventAuto, NewValue: &score},
+			{AttemptID: attempt.ID, Kind: models.GradeEventRegrade, ActorID: &teacher.ID, OldValue: &score, NewValue: &regraded, Reason: "Marking error"},
+		}))
+		require.NoError(t, repo.CreateGradeEvents(nil))
+
+		events, err := repo.FindGradeEventsByAttempt(attempt.ID)
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, models.GradeEventAuto, events[0].Kind)
+		assert.Nil(t, events[0].Actor)
+		require.NotNil(t, events[1].Actor)
+		assert.Equal(t, "Teacher User", events[1].Actor.Name)
+		assert.Equal(t, 80.0, *events[1].OldValue)
+
+		// Lịch sử điểm không thể bị sửa hay xoá
+		assert.ErrorIs(t, db.Model(&events[1]).Update("reason", "Changed").Error, models.ErrGradeEventImmutable)
+		assert.ErrorIs(t, db.Delete(&events[0]).Error, models.ErrGradeEventImmutable)
+
+		request := &models.RegradeRequest{AttemptID: attempt.ID, UserID: user2.ID, Reason: "Please check question 2", Status: models.RegradePending}
+		require.NoError(t, repo.CreateRegradeRequest(request))
+
+		found, err := repo.FindRegradeRequestByID(request.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.RegradePending, found.Status)
+
+		_, err = repo.FindRegradeRequestByID(9999)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		pending, total, err := repo.FindRegradeRequests(util.PaginationParams{Limit: 10, Filters: map[string]interface{}{
+			"status": models.RegradePending, "gradableBy": teacher.ID,
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, pending, 1)
+		assert.Equal(t, "Math Quiz", pending[0]["assessment_title"])
+		assert.Equal(t, "Student User 2", pending[0]["user_name"])
+
+		// Người không chấm bài này không thấy yêu cầu
+		_, total, err = repo.FindRegradeRequests(util.PaginationParams{Limit: 10, Filters: map[string]interface{}{"gradableBy": user1.ID}})
+		require.NoError(t, err)
+		assert.Zero(t, total)
+
+		now := time.Now()
+		found.Status = models.RegradeAccepted
+		found.Response = "Fixed"
+		found.ReviewedByID = &teacher.ID
+		found.ReviewedAt = &now
+		require.NoError(t, repo.UpdateRegradeRequest(found))
+
+		requests, err := repo.FindRegradeRequestsByAttempt(attempt.ID)
+		require.NoError(t, err)
+		require.Len(t, requests, 1)
+		assert.Equal(t, models.RegradeAccepted, requests[0].Status)
+		assert.Equal(t, "Fixed", requests[0].Response)
+	})
+
+	t.Run("TestRescore", func(t *testing.T) {
+		quiz := models.Assessment{Title: "Rescore Quiz", Subject: "Math", Duration: 10, CreatedByID: teacher.ID, Status: models.AssessmentActive, PassingScore: 50}
+		require.NoError(t, db.Create(&quiz).Error)
+		question := models.Question{AssessmentID: quiz.ID, Type: "true-false", Text: "1+1=3?", CorrectAnswer: "false", Points: 10}
+		require.NoError(t, db.Create(&question).Error)
+
+		// Chỉ bài đã nộp được chấm lại, bài đang làm và bài bị huỷ thì không
+		score, passed, correct := 0.0, false, false
+		graded := &models.Attempt{UserID: user1.ID, AssessmentID: quiz.ID, StartedAt: time.Now().Add(-time.Hour), Status: models.AttemptGraded, Score: &score, Passed: &passed}
+		inProgress := &models.Attempt{UserID: user2.ID, AssessmentID: quiz.ID, StartedAt: time.Now(), Status: models.AttemptInProgress}
+		voided := &models.Attempt{UserID: user2.ID, AssessmentID: quiz.ID, StartedAt: time.Now().Add(-time.Hour), Status: models.AttemptVoided}
+		for _, attempt := range []*models.Attempt{graded, inProgress, voided} {
+			require.NoError(t, repo.Create(attempt))
+		}
+		awarded := 0.0
+		require.NoError(t, repo.SaveAnswer(&models.Answer{AttemptID: graded.ID, QuestionID: question.ID, Answer: "true", IsCorrect: &correct, AwardedPoints: &awarded}))
+
+		attempts, err := repo.FindScoredAttempts(quiz.ID)
+		require.NoError(t, err)
+		require.Len(t, attempts, 1)
+		assert.Equal(t, graded.ID, attempts[0].ID)
+		require.Len(t, attempts[0].Answers, 1)
+
+		attempt := &attempts[0]
+		answer := &attempt.Answers[0]
+		newScore, newPassed, newCorrect, newPoints := 100.0, true, true, 10.0
+		attempt.Score, attempt.Passed = &newScore, &newPassed
+		answer.IsCorrect, answer.AwardedPoints = &newCorrect, &newPoints
+		require.NoError(t, repo.SaveRescore([]*models.Attempt{attempt}, []*models.Answer{answer}, []models.GradeEvent{
+			{AttemptID: attempt.ID, AnswerID: &answer.ID, Kind: models.GradeEventRegrade, ActorID: &teacher.ID, OldValue: &awarded, NewValue: &newPoints, Reason: "Rescored"},
+			{AttemptID: attempt.ID, Kind: models.GradeEventRegrade, ActorID: &teacher.ID, OldValue: &score, NewValue: &newScore, Reason: "Rescored"},
+		}))
+
+		saved, err := repo.FindByID(graded.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 100.0, *saved.Score)
+		assert.True(t, *saved.Passed)
+		assert.Equal(t, models.AttemptGraded, saved.Status)
+		require.Len(t, saved.Answers, 1)
+		assert.True(t, *saved.Answers[0].IsCorrect)
+		assert.Equal(t, 10.0, *saved.Answers[0].AwardedPoints)
+
+		events, err := repo.FindGradeEventsByAttempt(graded.ID)
+		require.NoError(t, err)
+		assert.Len(t, events, 2)
+	})
+
+	t.Run("TestSaveGrade", func(t *testing.T) {
+		score := 50.0
+		attempt := &models.Attempt{UserID: user1.ID, AssessmentID: assessment1.ID, StartedAt: time.Now().Add(-time.Hour), Status: models.AttemptGraded, Score: &score}
+		require.NoError(t, repo.Create(attempt))
+		request := &models.RegradeRequest{AttemptID: attempt.ID, UserID: user1.ID, Reason: "Please check question 1", Status: models.RegradePending}
+		require.NoError(t, repo.CreateRegradeRequest(request))
+		auto := []models.GradeEvent{{AttemptID: attempt.ID, Kind: models.GradeEventAuto, NewValue: &score}}
+		require.NoError(t, repo.CreateGradeEvents(auto))
+
+		regrade := func() models.GradeChange {
+			stored, err := repo.FindByID(attempt.ID)
+			require.NoError(t, err)
+			found, err := repo.FindRegradeRequestByID(request.ID)
+			require.NoError(t, err)
+
+			newScore, now := 70.0, time.Now()
+			stored.Score = &newScore
+			found.Status = models.RegradeAccepted
+			found.ReviewedByID = &teacher.ID
+			found.ReviewedAt = &now
+			return models.GradeChange{Attempt: stored, Request: found, Events: []models.GradeEvent{
+				{AttemptID: attempt.ID, Kind: models.GradeEventRegrade, ActorID: &teacher.ID, OldValue: &score, NewValue: &newScore},
+			}}
+		}
+
+		// Không ghi được lịch sử điểm thì điểm và yêu cầu cũng không đổi
+		failing := regrade()
+		failing.Events[0].ID = auto[0].ID
+		assert.Error(t, repo.SaveGrade(failing))
+
+		saved, err := repo.FindByID(attempt.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 50.0, *saved.Score)
+		found, err := repo.FindRegradeRequestByID(request.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.RegradePending, found.Status)
+
+		require.NoError(t, repo.SaveGrade(regrade()))
+
+		saved, err = repo.FindByID(attempt.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 70.0, *saved.Score)
+		found, err = repo.FindRegradeRequestByID(request.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.RegradeAccepted, found.Status)
+		events, err := repo.FindGradeEventsByAttempt(attempt.ID)
+		require.NoError(t, err)
+		assert.Len(t, events, 2)
+	})
 }
@@ -1,30 +1,117 @@
 package service
 
 import (
+	"assessment_service/internal/assessments/repository"
 	repository2 "assessment_service/internal/attempts/repository"
+	"assessment_service/internal/live"
 	models "assessment_service/internal/model"
+	repository3 "assessment_service/internal/questions/repository"
+	"assessment_service/internal/questions/types"
 	"assessment_service/internal/util"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
 	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAttemptNotFound = errors.New("attempt not found")
+	// ErrAttemptNotGradable is returned for attempts that are still in progress or were voided
+	ErrAttemptNotGradable = errors.New("attempt cannot be graded")
+	// ErrInvalidGrade is returned when an answer is given points its question does not allow
+	ErrInvalidGrade   = errors.New("invalid grade")
+	ErrAnswerNotFound = errors.New("answer not found")
+	// ErrNotManuallyGraded is returned when a grader marks an answer that was scored automatically
+	ErrNotManuallyGraded  = errors.New("answer is not graded manually")
+	ErrAssessmentNotFound = errors.New("assessment not found")
+	// ErrInvalidGraderAssignment is returned when answers cannot be handed out to the requested graders
+	ErrInvalidGraderAssignment = errors.New("invalid grader assignment")
+	// ErrNotAssignedGrader is returned when a grader marks an answer that was given to other graders
+	ErrNotAssignedGrader = errors.New("answer is assigned to other graders")
+	// ErrMarksFinal is returned when a double-marked answer that already has its points is marked again
+	ErrMarksFinal = errors.New("double-marked answer already has its points, reconcile it instead")
+	// ErrNotReconcilable is returned when an answer is reconciled before both graders marked it
+	ErrNotReconcilable        = errors.New("answer cannot be reconciled")
+	ErrRegradeRequestNotFound = errors.New("regrade request not found")
+	// ErrRegradeNotPending is returned when a regrade request that was already reviewed is reviewed again
+	ErrRegradeNotPending = errors.New("regrade request was already reviewed")
+	// ErrInvalidRegradeDecision is returned when a regrade request is accepted without a new grade or
+	// rejected without telling the student why
+	ErrInvalidRegradeDecision = errors.New("invalid regrade decision")
+	// ErrInvalidRescore is returned when rescoring names questions that are not part of the assessment
+	ErrInvalidRescore = errors.New("invalid rescore request")
+	// ErrAttemptNotLive is returned when the clock of an attempt that is no longer in progress is changed
+	ErrAttemptNotLive = errors.New("attempt is not in progress")
+	// ErrInvalidExtension is returned when an attempt is extended by no time
+	ErrInvalidExtension = errors.New("invalid extension")
+	// ErrInvalidMessage is returned when a proctor message is empty or has an unknown severity
+	ErrInvalidMessage = errors.New("invalid proctor message")
+	// ErrInvalidProctorAction is returned when an attempt is terminated without a reason
+	ErrInvalidProctorAction = errors.New("invalid proctor action")
 )
 
+// recentActivityLimit is how many suspicious activities of each attempt the proctoring dashboard shows
+const recentActivityLimit = 5
+
 type AttemptService interface {
 	GetListAttemptByUserAndAssessment(userID, assessmentID uint, params util.PaginationParams) ([]models.Attempt, int64, error)
 	GetAttemptDetail(attemptID uint) (*models.Attempt, error)
-	GradeAttempt(newAttempt models.AttemptUpdateDTO, attemptID uint) error
+	GradeAttempt(newAttempt models.AttemptUpdateDTO, attemptID, graderID uint) error
+	VoidAttempt(attemptID uint) error
+	GetGradingQueue(params util.PaginationParams) ([]map[string]interface{}, int64, error)
+	GradeAnswer(attemptID, answerID, graderID uint, grade models.AnswerGradeInput) (*models.Attempt, error)
+	AssignGraders(assessmentID uint, request models.GraderAssignmentDTO) ([]models.GradingAssignment, error)
+	GetGraderAssignments(graderID uint, params util.PaginationParams) ([]map[string]interface{}, int64, error)
+	ReconcileAnswer(attemptID, answerID, graderID uint, grade models.AnswerGradeInput) (*models.Attempt, error)
+	GetGradingProgress(assessmentID uint) (map[string]interface{}, error)
+	GetGradeEvents(attemptID uint) ([]models.GradeEvent, error)
+	ListRegradeRequests(params util.PaginationParams) ([]map[string]interface{}, int64, error)
+	AcceptRegradeRequest(attemptID, requestID, reviewerID uint, decision models.RegradeDecisionDTO) (*models.RegradeRequest, error)
+	RejectRegradeRequest(attemptID, requestID, reviewerID uint, response string) (*models.RegradeRequest, error)
+	RescoreAssessment(assessmentID, actorID uint, questionIDs []uint) (*models.RescoreSummary, error)
+	// RescoreAssessmentWithin rescores inside the transaction tx, so the new scores are saved with the
+	// change that caused them or not at all
+	RescoreAssessmentWithin(tx *gorm.DB, assessmentID, actorID uint, questionIDs []uint) (*models.RescoreSummary, error)
+	// PauseAttempt, ResumeAttempt and ExtendAttempt change the clock of an attempt in progress and
+	// record the change in the attempt's time ledger
+	PauseAttempt(attemptID, actorID uint, reason string) (*models.Attempt, error)
+	ResumeAttempt(attemptID, actorID uint, reason string) (*models.Attempt, error)
+	ExtendAttempt(attemptID, actorID uint, minutes int, reason string) (*models.Attempt, error)
+	GetTimeEvents(attemptID uint) ([]models.AttemptTimeEvent, error)
+	// SendMessage sends a proctor's message to the live channel of an attempt in progress
+	SendMessage(attemptID, actorID uint, message models.ProctorMessageDTO) error
+	// GetProctoringDashboard lists the attempts of the assessment in progress for its proctors
+	GetProctoringDashboard(assessmentID uint) ([]models.ProctoredAttempt, error)
+	// FlagAttempt marks an attempt for review, TerminateAttempt ends an attempt in progress and voids it
+	FlagAttempt(attemptID, actorID uint, reason string) (*models.Attempt, error)
+	TerminateAttempt(attemptID, actorID uint, reason string) (*models.Attempt, error)
 }
 
 type attemptService struct {
-	attemptRepo repository2.AttemptRepository
-	log         *zap.Logger
+	attemptRepo    repository2.AttemptRepository
+	assessmentRepo repository.AssessmentRepository
+	questionRepo   repository3.QuestionRepository
+	hub            live.Publisher
+	log            *zap.Logger
 }
 
 func NewAttemptService(
 	attemptRepo repository2.AttemptRepository,
+	assessmentRepo repository.AssessmentRepository,
+	questionRepo repository3.QuestionRepository,
+	hub live.Publisher,
 	log *zap.Logger,
 ) AttemptService {
 	return &attemptService{
-		attemptRepo: attemptRepo,
-		log:         log,
+		attemptRepo:    attemptRepo,
+		assessmentRepo: assessmentRepo,
+		questionRepo:   questionRepo,
+		hub:            hub,
+		log:            log,
 	}
 }
 
@@ -39,6 +126,7 @@ func (s *attemptService) GetListAttemptByUserAndAssessment(userID, assessmentID
 	return attempts, total, nil
 }
 
+// GetAttemptDetail returns an attempt with the order its questions and their options were shown in
 func (s *attemptService) GetAttemptDetail(attemptID uint) (*models.Attempt, error) {
 	attempt, err := s.attemptRepo.FindByID(attemptID)
 	if err != nil {
@@ -46,34 +134,1195 @@ func (s *attemptService) GetAttemptDetail(attemptID uint) (*models.Attempt, erro
 		return nil, err
 	}
 
+	questions, err := s.questionRepo.FindByAssessmentID(attempt.AssessmentID)
+	if err != nil {
+		s.log.Error("[GetAttemptDetail] Failed to find questions", zap.Error(err))
+		return nil, err
+	}
+
+	given := attempt.GivenQuestions(questions)
+	attempt.Presented = make([]models.PresentedQuestion, len(given))
+	for i, question := range given {
+		attempt.Presented[i] = models.PresentedQuestion{
+			QuestionID: question.ID,
+			Position:   i,
+			Options:    types.OptionOrder(question, attempt.ShuffleOptions, attempt.OptionSeed(question.ID)),
+		}
+	}
+
 	return attempt, nil
 }
 
-func (s *attemptService) GradeAttempt(newAttempt models.AttemptUpdateDTO, attemptID uint) error {
+// GradeAttempt records a teacher's grade for a submitted attempt and moves it to graded. When
+// answers are graded the score is worked out again from the points of every answer, otherwise the
+// given score is used. A graded attempt can be graded again, which replaces its score and outcome.
+// Every change is kept in the grade history.
+func (s *attemptService) GradeAttempt(newAttempt models.AttemptUpdateDTO, attemptID, graderID uint) error {
 	// update some columns in attempt
-	attempt, err := s.attemptRepo.FindByID(attemptID)
+	attempt, err := s.findAttempt(attemptID)
 	if err != nil {
-		s.log.Error("[GradeAttempt] Failed to find attempt", zap.Error(err))
 		return err
 	}
 
+	return s.gradeAttempt(attempt, newAttempt, graderID, models.GradeEventManual, nil)
+}
+
+// gradeAttempt gives the attempt its new grade. The regrade request it answers, if any, is saved
+// with it.
+func (s *attemptService) gradeAttempt(attempt *models.Attempt, newAttempt models.AttemptUpdateDTO, graderID uint, kind models.GradeEventKind, request *models.RegradeRequest) error {
+	before := snapshotGrades(attempt)
+	if attempt.Status != models.AttemptGraded {
+		if err := attempt.TransitionTo(models.AttemptGraded); err != nil {
+			return fmt.Errorf("%w: attempt is %s", ErrAttemptNotGradable, attempt.Status)
+		}
+	}
+
+	assessment, err := s.assessmentRepo.FindByID(attempt.AssessmentID)
+	if err != nil {
+		s.log.Error("[GradeAttempt] Failed to find assessment", zap.Error(err))
+		return err
+	}
+
+	score := newAttempt.Score
+	if len(newAttempt.Answers) > 0 {
+		questions, err := s.questionRepo.FindByAssessmentID(attempt.AssessmentID)
+		if err != nil {
+			s.log.Error("[GradeAttempt] Failed to find questions", zap.Error(err))
+			return err
+		}
+
+		if err := gradeAnswers(attempt.Answers, newAttempt.Answers, questions); err != nil {
+			return err
+		}
+
+		// The late penalty applies to the new score just as it did when the attempt was submitted
+		score = types.Percentage(attempt.GivenQuestions(questions), attempt.Answers)
+		score -= score * attempt.LatePenalty / 100
+	}
+
 	// update attempt with new values
-	attempt.Score = &newAttempt.Score
+	passed := score >= assessment.PassingScore
+	attempt.Score = &score
+	attempt.Passed = &passed
 	attempt.Feedback = newAttempt.Feedback
 
-	for i := range attempt.Answers {
-		for j := range newAttempt.Answers {
-			if attempt.Answers[i].ID == newAttempt.Answers[j].ID {
-				attempt.Answers[i].IsCorrect = &newAttempt.Answers[j].IsCorrect
+	return s.saveGrade(models.GradeChange{
+		Attempt: attempt,
+		Request: request,
+		Events:  before.events(attempt, kind, graderID, newAttempt.Reason),
+	})
+}
+
+// gradeAnswers applies a teacher's grades to the answers they name. An answer given no points gets
+// the question's points when correct and is marked down by the question's penalty when not.
+func gradeAnswers(answers []models.Answer, grades []models.AnswerGradeDTO, questions []models.Question) error {
+	questionsByID := make(map[uint]*models.Question, len(questions))
+	for i := range questions {
+		questionsByID[questions[i].ID] = &questions[i]
+	}
+
+	for i := range answers {
+		for _, grade := range grades {
+			if answers[i].ID != grade.ID {
+				continue
+			}
+
+			question, ok := questionsByID[answers[i].QuestionID]
+			if !ok {
+				return fmt.Errorf("%w: answer %d is not for a question of this assessment", ErrInvalidGrade, grade.ID)
+			}
+
+			isCorrect := grade.IsCorrect
+			var points float64
+			switch {
+			case grade.AwardedPoints != nil:
+				points = *grade.AwardedPoints
+				if points > question.Points {
+					return fmt.Errorf("%w: answer %d cannot get more than %g points", ErrInvalidGrade, grade.ID, question.Points)
+				}
+				if points < 0 && !question.AllowNegative {
+					return fmt.Errorf("%w: answer %d cannot get negative points", ErrInvalidGrade, grade.ID)
+				}
+			case isCorrect:
+				points = types.Points(question, 1, 0)
+			default:
+				points = types.Points(question, 0, 1)
 			}
+
+			answers[i].IsCorrect = &isCorrect
+			answers[i].AwardedPoints = &points
+		}
+	}
+
+	return nil
+}
+
+// GetGradingQueue lists answers waiting for a grader across assessments
+func (s *attemptService) GetGradingQueue(params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	answers, total, err := s.attemptRepo.FindPendingManualAnswers(params)
+	if err != nil {
+		s.log.Error("[GetGradingQueue] Failed to get pending answers", zap.Error(err))
+		return nil, 0, err
+	}
+
+	anonymize(answers)
+	return answers, total, nil
+}
+
+// GradeAnswer records a grader's mark for an answer that is graded manually, such as an essay. An
+// answer that was handed out can only be marked by its graders. With double marking the answer gets
+// the average of both marks once they are in and close enough, otherwise it waits to be reconciled.
+// Once no answers of the attempt wait for a grader, its score and outcome are worked out from the
+// points of every answer and it moves to graded.
+func (s *attemptService) GradeAnswer(attemptID, answerID, graderID uint, grade models.AnswerGradeInput) (*models.Attempt, error) {
+	attempt, answer, question, questions, err := s.findManualAnswer(attemptID, answerID)
+	if err != nil {
+		return nil, err
+	}
+	before := snapshotGrades(attempt)
+
+	points, err := manualPoints(question, answer.Answer, grade)
+	if err != nil {
+		return nil, err
+	}
+
+	assessment, err := s.findAssessment(attempt.AssessmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.attemptRepo.FindAssignmentsByAnswer(answerID)
+	if err != nil {
+		s.log.Error("[GradeAnswer] Failed to find grading assignments", zap.Error(err))
+		return nil, err
+	}
+
+	change := models.GradeChange{Answer: answer}
+	if len(assignments) == 0 {
+		applyGrade(answer, question, points, grade, graderID)
+	} else if change.Assignment, err = markAssignment(assessment, answer, question, assignments, points, grade, graderID); err != nil {
+		return nil, err
+	}
+
+	return s.completeGrading(change, attempt, assessment, questions, before, graderID, grade.Reason)
+}
+
+// markAssignment records a mark on the grader's assignment and works out what the answer gets from
+// the marks of all its graders. It returns the grader's assignment to save.
+func markAssignment(
+	assessment *models.Assessment,
+	answer *models.Answer,
+	question *models.Question,
+	assignments []models.GradingAssignment,
+	points float64,
+	grade models.AnswerGradeInput,
+	graderID uint,
+) (*models.GradingAssignment, error) {
+	var own *models.GradingAssignment
+	for i := range assignments {
+		if assignments[i].GraderID == graderID {
+			own = &assignments[i]
+		}
+	}
+	if own == nil {
+		return nil, ErrNotAssignedGrader
+	}
+	if len(assignments) > 1 && answer.AwardedPoints != nil {
+		return nil, ErrMarksFinal
+	}
+
+	now := time.Now()
+	own.Status = models.GradingCompleted
+	own.Points = &points
+	own.RubricScores = grade.RubricScores
+	own.Comment = grade.Comment
+	own.CompletedAt = &now
+
+	if len(assignments) == 1 {
+		applyGrade(answer, question, points, grade, graderID)
+		return own, nil
+	}
+
+	total := 0.0
+	lowest, highest := points, points
+	var comments []string
+	for _, assignment := range assignments {
+		if assignment.Status != models.GradingCompleted {
+			// The other grader has not marked the answer yet
+			return own, nil
+		}
+		total += *assignment.Points
+		lowest = math.Min(lowest, *assignment.Points)
+		highest = math.Max(highest, *assignment.Points)
+		if assignment.Comment != "" {
+			comments = append(comments, assignment.Comment)
+		}
+	}
+
+	if highest-lowest > assessment.Settings.ReconciliationThreshold {
+		// Too far apart, the answer waits to be reconciled
+		return own, nil
+	}
+
+	average := total / float64(len(assignments))
+	applyGrade(answer, question, average, models.AnswerGradeInput{Comment: strings.Join(comments, "\n\n")}, graderID)
+	return own, nil
+}
+
+// ReconcileAnswer settles a double-marked answer once both graders have marked it, usually because
+// their marks were too far apart. The reconciled mark replaces whatever the answer had.
+func (s *attemptService) ReconcileAnswer(attemptID, answerID, graderID uint, grade models.AnswerGradeInput) (*models.Attempt, error) {
+	attempt, answer, question, questions, err := s.findManualAnswer(attemptID, answerID)
+	if err != nil {
+		return nil, err
+	}
+	before := snapshotGrades(attempt)
+
+	assignments, err := s.attemptRepo.FindAssignmentsByAnswer(answerID)
+	if err != nil {
+		s.log.Error("[ReconcileAnswer] Failed to find grading assignments", zap.Error(err))
+		return nil, err
+	}
+	if len(assignments) < 2 {
+		return nil, fmt.Errorf("%w: the answer is not double-marked", ErrNotReconcilable)
+	}
+	for _, assignment := range assignments {
+		if assignment.Status != models.GradingCompleted {
+			return nil, fmt.Errorf("%w: both graders must mark the answer first", ErrNotReconcilable)
 		}
 	}
 
-	err = s.attemptRepo.Update(attempt)
+	points, err := manualPoints(question, answer.Answer, grade)
+	if err != nil {
+		return nil, err
+	}
+
+	assessment, err := s.findAssessment(attempt.AssessmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	applyGrade(answer, question, points, grade, graderID)
+
+	return s.completeGrading(models.GradeChange{Answer: answer}, attempt, assessment, questions, before, graderID, grade.Reason)
+}
+
+// findManualAnswer loads an answer of an attempt that can be graded, with the questions of its
+// assessment
+func (s *attemptService) findManualAnswer(attemptID, answerID uint) (*models.Attempt, *models.Answer, *models.Question, []models.Question, error) {
+	attempt, err := s.findAttempt(attemptID)
 	if err != nil {
-		s.log.Error("[GradeAttempt] Failed to update attempt grade", zap.Error(err))
+		return nil, nil, nil, nil, err
+	}
+
+	if attempt.Status != models.AttemptPendingManualGrading && attempt.Status != models.AttemptGraded {
+		return nil, nil, nil, nil, fmt.Errorf("%w: attempt is %s", ErrAttemptNotGradable, attempt.Status)
+	}
+
+	var answer *models.Answer
+	for i := range attempt.Answers {
+		if attempt.Answers[i].ID == answerID {
+			answer = &attempt.Answers[i]
+		}
+	}
+	if answer == nil {
+		return nil, nil, nil, nil, ErrAnswerNotFound
+	}
+
+	questions, err := s.questionRepo.FindByAssessmentID(attempt.AssessmentID)
+	if err != nil {
+		s.log.Error("[findManualAnswer] Failed to find questions", zap.Error(err))
+		return nil, nil, nil, nil, err
+	}
+
+	var question *models.Question
+	for i := range questions {
+		if questions[i].ID == answer.QuestionID {
+			question = &questions[i]
+		}
+	}
+	if question == nil {
+		return nil, nil, nil, nil, fmt.Errorf("%w: answer %d is not for a question of this assessment", ErrInvalidGrade, answerID)
+	}
+
+	return attempt, answer, question, questions, nil
+}
+
+// applyGrade gives an answer the points of a grader's mark
+func applyGrade(answer *models.Answer, question *models.Question, points float64, grade models.AnswerGradeInput, graderID uint) {
+	now := time.Now()
+	isCorrect := points >= question.Points
+	answer.IsCorrect = &isCorrect
+	answer.AwardedPoints = &points
+	answer.Comment = grade.Comment
+	answer.RubricScores = grade.RubricScores
+	answer.GradedByID = &graderID
+	answer.GradedAt = &now
+}
+
+// completeGrading finishes grading the attempt when none of its answers wait for a grader and saves
+// the change with what the grader changed in the grade history. Graders of a blind assessment are
+// not told whose attempt it is.
+func (s *attemptService) completeGrading(
+	change models.GradeChange,
+	attempt *models.Attempt,
+	assessment *models.Assessment,
+	questions []models.Question,
+	before gradeSnapshot,
+	graderID uint,
+	reason string,
+) (*models.Attempt, error) {
+	pending := false
+	for _, a := range attempt.Answers {
+		if a.AwardedPoints == nil {
+			pending = true
+		}
+	}
+
+	if !pending {
+		if err := finishGrading(attempt, assessment, questions); err != nil {
+			return nil, err
+		}
+		change.Attempt = attempt
+	}
+
+	change.Events = before.events(attempt, models.GradeEventManual, graderID, reason)
+	if err := s.saveGrade(change); err != nil {
+		return nil, err
+	}
+
+	if assessment.Settings.BlindGrading {
+		attempt.UserID = 0
+	}
+	return attempt, nil
+}
+
+// manualPoints checks a grader's mark against the question and returns the points it gives
+func manualPoints(question *models.Question, answer string, grade models.AnswerGradeInput) (float64, error) {
+	questionType, err := types.Lookup(question.Type)
+	if err != nil {
+		return 0, err
+	}
+	if questionType.Grade(question, answer) != nil {
+		return 0, fmt.Errorf("%w: %s questions are graded automatically", ErrNotManuallyGraded, question.Type)
+	}
+
+	rubric, err := types.Rubric(question)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(rubric) > 0 {
+		if grade.Points != nil {
+			return 0, fmt.Errorf("%w: the question has a rubric, give rubric scores instead of points", ErrInvalidGrade)
+		}
+		points, err := types.ScoreRubric(rubric, grade.RubricScores)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrInvalidGrade, err)
+		}
+		return points, nil
+	}
+
+	if len(grade.RubricScores) > 0 {
+		return 0, fmt.Errorf("%w: the question has no rubric", ErrInvalidGrade)
+	}
+	if grade.Points == nil {
+		return 0, fmt.Errorf("%w: points are required", ErrInvalidGrade)
+	}
+	if *grade.Points > question.Points {
+		return 0, fmt.Errorf("%w: cannot give more than %g points", ErrInvalidGrade, question.Points)
+	}
+	if *grade.Points < 0 && !question.AllowNegative {
+		return 0, fmt.Errorf("%w: cannot give negative points", ErrInvalidGrade)
+	}
+	return *grade.Points, nil
+}
+
+// finishGrading works out the score and outcome of an attempt whose answers all have points, and
+// moves it to graded
+func finishGrading(attempt *models.Attempt, assessment *models.Assessment, questions []models.Question) error {
+	if attempt.Status != models.AttemptGraded {
+		if err := attempt.TransitionTo(models.AttemptGraded); err != nil {
+			return err
+		}
+	}
+
+	score := types.Percentage(attempt.GivenQuestions(questions), attempt.Answers)
+	score -= score * attempt.LatePenalty / 100
+	passed := score >= assessment.PassingScore
+	attempt.Score = &score
+	attempt.Passed = &passed
+	return nil
+}
+
+// AssignGraders hands out the answers of an assessment that wait for a grader and were not handed
+// out yet. Round robin gives each attempt to the next grader in turn, the assessment strategy gives
+// every attempt to the first grader. With double marking each answer goes to two graders. All
+// answers of an attempt go to the same graders.
+func (s *attemptService) AssignGraders(assessmentID uint, request models.GraderAssignmentDTO) ([]models.GradingAssignment, error) {
+	assessment, err := s.findAssessment(assessmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	eligible, err := s.attemptRepo.FindGraderIDs(assessmentID)
+	if err != nil {
+		s.log.Error("[AssignGraders] Failed to find graders", zap.Error(err))
+		return nil, err
+	}
+
+	graders := request.GraderIDs
+	if len(graders) == 0 {
+		graders = eligible
+	}
+	if err := validateGraders(graders, eligible); err != nil {
+		return nil, err
+	}
+
+	markers := 1
+	if assessment.Settings.DoubleMarking {
+		markers = 2
+	}
+	if len(graders) < markers {
+		return nil, fmt.Errorf("%w: double marking needs at least two graders", ErrInvalidGraderAssignment)
+	}
+
+	switch request.Strategy {
+	case "", models.GradingStrategyRoundRobin:
+	case models.GradingStrategyAssessment:
+		graders = graders[:markers]
+	default:
+		return nil, fmt.Errorf("%w: strategy must be one of round_robin, assessment", ErrInvalidGraderAssignment)
+	}
+
+	answers, err := s.attemptRepo.FindUnassignedManualAnswers(assessmentID)
+	if err != nil {
+		s.log.Error("[AssignGraders] Failed to find unassigned answers", zap.Error(err))
+		return nil, err
+	}
+
+	assignments := make([]models.GradingAssignment, 0, len(answers)*markers)
+	turn := -1
+	var lastAttemptID uint
+	for _, answer := range answers {
+		if turn < 0 || answer.AttemptID != lastAttemptID {
+			turn++
+			lastAttemptID = answer.AttemptID
+		}
+		for marker := 0; marker < markers; marker++ {
+			assignments = append(assignments, models.GradingAssignment{
+				AssessmentID: assessmentID,
+				AttemptID:    answer.AttemptID,
+				AnswerID:     answer.ID,
+				GraderID:     graders[(turn*markers+marker)%len(graders)],
+				Status:       models.GradingAssigned,
+			})
+		}
+	}
+
+	if err := s.attemptRepo.CreateGradingAssignments(assignments); err != nil {
+		s.log.Error("[AssignGraders] Failed to create grading assignments", zap.Error(err))
+		return nil, err
+	}
+
+	return assignments, nil
+}
+
+// validateGraders checks that every grader may grade the assessment and is named once
+func validateGraders(graders, eligible []uint) error {
+	allowed := make(map[uint]bool, len(eligible))
+	for _, id := range eligible {
+		allowed[id] = true
+	}
+
+	seen := make(map[uint]bool, len(graders))
+	for _, id := range graders {
+		if !allowed[id] {
+			return fmt.Errorf("%w: user %d cannot grade this assessment", ErrInvalidGraderAssignment, id)
+		}
+		if seen[id] {
+			return fmt.Errorf("%w: user %d is named twice", ErrInvalidGraderAssignment, id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// GetGraderAssignments lists the answers handed out to a grader
+func (s *attemptService) GetGraderAssignments(graderID uint, params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	assignments, total, err := s.attemptRepo.FindAssignmentsByGrader(graderID, params)
+	if err != nil {
+		s.log.Error("[GetGraderAssignments] Failed to get grading assignments", zap.Error(err))
+		return nil, 0, err
+	}
+
+	anonymize(assignments)
+	return assignments, total, nil
+}
+
+// GetGradingProgress reports how far the manual grading of an assessment has come
+func (s *attemptService) GetGradingProgress(assessmentID uint) (map[string]interface{}, error) {
+	if _, err := s.findAssessment(assessmentID); err != nil {
+		return nil, err
+	}
+
+	progress, err := s.attemptRepo.GetGradingProgress(assessmentID)
+	if err != nil {
+		s.log.Error("[GetGradingProgress] Failed to get grading progress", zap.Error(err))
+		return nil, err
+	}
+
+	return progress, nil
+}
+
+// anonymize replaces the student of rows from blind assessments with a candidate label. The label
+// numbers the attempts of each assessment in start order so it cannot be matched to an attempt ID
+// elsewhere
+func anonymize(rows []map[string]interface{}) {
+	for _, row := range rows {
+		blind := false
+		switch v := row["blind_grading"].(type) {
+		case bool:
+			blind = v
+		case int64:
+			blind = v != 0
+		case float64:
+			blind = v != 0
+		}
+
+		if blind {
+			delete(row, "user_id")
+			delete(row, "user_name")
+			row["candidate"] = fmt.Sprintf("Candidate %v", row["candidate_number"])
+		}
+		delete(row, "blind_grading")
+		delete(row, "candidate_number")
+	}
+}
+
+// gradeSnapshot is what an attempt's grades were before a grader changed them
+type gradeSnapshot struct {
+	graded bool
+	score  *float64
+	points map[uint]*float64
+}
+
+func snapshotGrades(attempt *models.Attempt) gradeSnapshot {
+	snapshot := gradeSnapshot{
+		graded: attempt.Status == models.AttemptGraded,
+		score:  copyFloat(attempt.Score),
+		points: make(map[uint]*float64, len(attempt.Answers)),
+	}
+	for _, answer := range attempt.Answers {
+		snapshot.points[answer.ID] = copyFloat(answer.AwardedPoints)
+	}
+	return snapshot
+}
+
+// events describes how the attempt's grades changed since the snapshot. A manual change to a grade
+// that was already given is a regrade.
+func (g gradeSnapshot) events(attempt *models.Attempt, kind models.GradeEventKind, actorID uint, reason string) []models.GradeEvent {
+	var events []models.GradeEvent
+	for _, answer := range attempt.Answers {
+		old := g.points[answer.ID]
+		answerKind := kind
+		if kind == models.GradeEventManual && old != nil {
+			answerKind = models.GradeEventRegrade
+		}
+		if event, ok := answer.PointsEvent(old, answerKind, &actorID, reason); ok {
+			events = append(events, event)
+		}
+	}
+
+	attemptKind := kind
+	if kind == models.GradeEventManual && g.graded {
+		attemptKind = models.GradeEventRegrade
+	}
+	if event, ok := attempt.ScoreEvent(g.score, attemptKind, &actorID, reason); ok {
+		events = append(events, event)
+	}
+	return events
+}
+
+func copyFloat(f *float64) *float64 {
+	if f == nil {
+		return nil
+	}
+	value := *f
+	return &value
+}
+
+func (s *attemptService) saveGrade(change models.GradeChange) error {
+	if err := s.attemptRepo.SaveGrade(change); err != nil {
+		s.log.Error("[saveGrade] Failed to save grade", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetGradeEvents returns the grade history of an attempt, oldest first
+func (s *attemptService) GetGradeEvents(attemptID uint) ([]models.GradeEvent, error) {
+	if _, err := s.findAttempt(attemptID); err != nil {
+		return nil, err
+	}
+
+	events, err := s.attemptRepo.FindGradeEventsByAttempt(attemptID)
+	if err != nil {
+		s.log.Error("[GetGradeEvents] Failed to find grade events", zap.Error(err))
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// ListRegradeRequests lists students' regrade requests across assessments
+func (s *attemptService) ListRegradeRequests(params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	requests, total, err := s.attemptRepo.FindRegradeRequests(params)
+	if err != nil {
+		s.log.Error("[ListRegradeRequests] Failed to find regrade requests", zap.Error(err))
+		return nil, 0, err
+	}
+
+	anonymize(requests)
+	return requests, total, nil
+}
+
+// AcceptRegradeRequest regrades the attempt with the grader's new score or answer grades and closes
+// the request. The attempt keeps its feedback, and the change is kept in the grade history as a
+// regrade that points at the request.
+func (s *attemptService) AcceptRegradeRequest(attemptID, requestID, reviewerID uint, decision models.RegradeDecisionDTO) (*models.RegradeRequest, error) {
+	if decision.Score == nil && len(decision.Answers) == 0 {
+		return nil, fmt.Errorf("%w: accepting a regrade request needs a new score or answer grades", ErrInvalidRegradeDecision)
+	}
+
+	request, err := s.findPendingRegradeRequest(attemptID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt, err := s.findAttempt(attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	grade := models.AttemptUpdateDTO{
+		Feedback: attempt.Feedback,
+		Answers:  decision.Answers,
+		Reason:   regradeReason(request, decision.Response),
+	}
+	if decision.Score != nil {
+		grade.Score = *decision.Score
+	}
+	reviewRegradeRequest(request, models.RegradeAccepted, reviewerID, decision.Response)
+	if err := s.gradeAttempt(attempt, grade, reviewerID, models.GradeEventRegrade, request); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// RejectRegradeRequest closes a regrade request without changing the grade. The student is told why.
+func (s *attemptService) RejectRegradeRequest(attemptID, requestID, reviewerID uint, response string) (*models.RegradeRequest, error) {
+	if strings.TrimSpace(response) == "" {
+		return nil, fmt.Errorf("%w: tell the student why the request is rejected", ErrInvalidRegradeDecision)
+	}
+
+	request, err := s.findPendingRegradeRequest(attemptID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewRegradeRequest(request, models.RegradeRejected, reviewerID, response)
+	if err := s.attemptRepo.UpdateRegradeRequest(request); err != nil {
+		s.log.Error("[RejectRegradeRequest] Failed to update regrade request", zap.Error(err))
+		return nil, err
+	}
+
+	return request, nil
+}
+
+func (s *attemptService) findPendingRegradeRequest(attemptID, requestID uint) (*models.RegradeRequest, error) {
+	request, err := s.attemptRepo.FindRegradeRequestByID(requestID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRegradeRequestNotFound
+		}
+		s.log.Error("[findPendingRegradeRequest] Failed to find regrade request", zap.Error(err))
+		return nil, err
+	}
+	if request.AttemptID != attemptID {
+		return nil, ErrRegradeRequestNotFound
+	}
+	if request.Status != models.RegradePending {
+		return nil, fmt.Errorf("%w: request is %s", ErrRegradeNotPending, request.Status)
+	}
+
+	return request, nil
+}
+
+// reviewRegradeRequest closes the request with the reviewer's decision
+func reviewRegradeRequest(request *models.RegradeRequest, status models.RegradeRequestStatus, reviewerID uint, response string) {
+	now := time.Now()
+	request.Status = status
+	request.Response = response
+	request.ReviewedByID = &reviewerID
+	request.ReviewedAt = &now
+}
+
+func regradeReason(request *models.RegradeRequest, response string) string {
+	if response == "" {
+		return fmt.Sprintf("Regrade request #%d", request.ID)
+	}
+	return fmt.Sprintf("Regrade request #%d: %s", request.ID, response)
+}
+
+// RescoreAssessment scores the answers of submitted attempts again after questions of the assessment
+// changed, for the given questions or for every question. Answers graded by a teacher keep their
+// points. Scores are worked out again, graded attempts get a new outcome, and every change is kept
+// in the grade history as a regrade. All changes are saved together.
+func (s *attemptService) RescoreAssessment(assessmentID, actorID uint, questionIDs []uint) (*models.RescoreSummary, error) {
+	assessment, err := s.findAssessment(assessmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := s.questionRepo.FindByAssessmentID(assessmentID)
+	if err != nil {
+		s.log.Error("[RescoreAssessment] Failed to find questions", zap.Error(err))
+		return nil, err
+	}
+
+	scope, err := rescoreScope(questions, questionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts, err := s.attemptRepo.FindScoredAttempts(assessmentID)
+	if err != nil {
+		s.log.Error("[RescoreAssessment] Failed to find attempts", zap.Error(err))
+		return nil, err
+	}
+
+	summary := &models.RescoreSummary{
+		AssessmentID:    assessmentID,
+		AttemptsChecked: len(attempts),
+		Changes:         []models.RescoreChange{},
+	}
+	reason := rescoreReason(questionIDs)
+
+	var changedAttempts []*models.Attempt
+	var changedAnswers []*models.Answer
+	var events []models.GradeEvent
+	for i := range attempts {
+		attempt := &attempts[i]
+		before := snapshotGrades(attempt)
+		oldPassed := attempt.Passed
+
+		answers, err := rescoreAnswers(attempt, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		score := types.Percentage(attempt.GivenQuestions(questions), attempt.Answers)
+		score -= score * attempt.LatePenalty / 100
+		attempt.Score = &score
+		if attempt.Status == models.AttemptGraded {
+			passed := score >= assessment.PassingScore
+			attempt.Passed = &passed
+		}
+
+		attemptEvents := before.events(attempt, models.GradeEventRegrade, actorID, reason)
+		if len(answers) == 0 && len(attemptEvents) == 0 && sameOutcome(oldPassed, attempt.Passed) {
+			continue
+		}
+
+		change := models.RescoreChange{
+			AttemptID:      attempt.ID,
+			UserID:         attempt.UserID,
+			OldScore:       before.score,
+			NewScore:       attempt.Score,
+			OldPassed:      oldPassed,
+			NewPassed:      attempt.Passed,
+			AnswersChanged: len(answers),
+		}
+		if assessment.Settings.BlindGrading {
+			change.UserID = 0
+		}
+		summary.Changes = append(summary.Changes, change)
+		summary.AnswersChanged += len(answers)
+
+		changedAttempts = append(changedAttempts, attempt)
+		changedAnswers = append(changedAnswers, answers...)
+		events = append(events, attemptEvents...)
+	}
+	summary.AttemptsChanged = len(summary.Changes)
+
+	if len(changedAttempts) == 0 {
+		return summary, nil
+	}
+
+	if err := s.attemptRepo.SaveRescore(changedAttempts, changedAnswers, events); err != nil {
+		s.log.Error("[RescoreAssessment] Failed to save rescore", zap.Error(err))
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+func (s *attemptService) RescoreAssessmentWithin(tx *gorm.DB, assessmentID, actorID uint, questionIDs []uint) (*models.RescoreSummary, error) {
+	// Questions are read inside the transaction too, so the rescore sees the change being made
+	scoped := *s
+	scoped.attemptRepo = s.attemptRepo.WithTx(tx)
+	scoped.questionRepo = s.questionRepo.WithTx(tx)
+	return scoped.RescoreAssessment(assessmentID, actorID, questionIDs)
+}
+
+// rescoreScope returns the questions to score again by ID. Every question is scored again when none
+// are named.
+func rescoreScope(questions []models.Question, questionIDs []uint) (map[uint]*models.Question, error) {
+	byID := make(map[uint]*models.Question, len(questions))
+	for i := range questions {
+		byID[questions[i].ID] = &questions[i]
+	}
+	if len(questionIDs) == 0 {
+		return byID, nil
+	}
+
+	scope := make(map[uint]*models.Question, len(questionIDs))
+	for _, id := range questionIDs {
+		question, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: question %d is not part of this assessment", ErrInvalidRescore, id)
+		}
+		scope[id] = question
+	}
+	return scope, nil
+}
+
+// rescoreAnswers scores the attempt's answers to the given questions again and returns the answers
+// that changed. Answers a teacher has to grade are left alone.
+func rescoreAnswers(attempt *models.Attempt, scope map[uint]*models.Question) ([]*models.Answer, error) {
+	var changed []*models.Answer
+	for i := range attempt.Answers {
+		answer := &attempt.Answers[i]
+		question, ok := scope[answer.QuestionID]
+		if !ok {
+			continue
+		}
+
+		questionType, err := types.Lookup(question.Type)
+		if err != nil {
+			return nil, err
+		}
+		isCorrect, points := types.Score(questionType, question, answer.Answer)
+		if isCorrect == nil {
+			continue
+		}
+
+		if sameOutcome(answer.IsCorrect, isCorrect) && answer.AwardedPoints != nil && *answer.AwardedPoints == *points {
+			continue
+		}
+		answer.IsCorrect = isCorrect
+		answer.AwardedPoints = points
+		changed = append(changed, answer)
+	}
+	return changed, nil
+}
+
+func sameOutcome(a, b *bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func rescoreReason(questionIDs []uint) string {
+	if len(questionIDs) == 0 {
+		return "Rescored after the questions changed"
+	}
+
+	ids := make([]string, len(questionIDs))
+	for i, id := range questionIDs {
+		ids[i] = fmt.Sprintf("#%d", id)
+	}
+	if len(ids) == 1 {
+		return fmt.Sprintf("Rescored after question %s changed", ids[0])
+	}
+	return fmt.Sprintf("Rescored after questions %s changed", strings.Join(ids, ", "))
+}
+
+// VoidAttempt stops an attempt from counting towards results. Voiding is final.
+func (s *attemptService) VoidAttempt(attemptID uint) error {
+	attempt, err := s.findAttempt(attemptID)
+	if err != nil {
+		return err
+	}
+
+	if err := attempt.TransitionTo(models.AttemptVoided); err != nil {
+		return err
+	}
+
+	if err := s.attemptRepo.Update(attempt); err != nil {
+		s.log.Error("[VoidAttempt] Failed to void attempt", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// PauseAttempt stops the clock of an attempt in progress. The student cannot save answers or submit
+// until the attempt is resumed, and the time it is paused for is added to its time limit.
+func (s *attemptService) PauseAttempt(attemptID, actorID uint, reason string) (*models.Attempt, error) {
+	return s.changeTime(attemptID, "PauseAttempt", func(attempt *models.Attempt, now time.Time) (*models.AttemptTimeEvent, error) {
+		if err := attempt.Pause(now); err != nil {
+			return nil, err
+		}
+		return &models.AttemptTimeEvent{AttemptID: attempt.ID, Kind: models.TimeEventPause, ActorID: actorID, Reason: reason}, nil
+	})
+}
+
+// ResumeAttempt starts the clock of a paused attempt again
+func (s *attemptService) ResumeAttempt(attemptID, actorID uint, reason string) (*models.Attempt, error) {
+	return s.changeTime(attemptID, "ResumeAttempt", func(attempt *models.Attempt, now time.Time) (*models.AttemptTimeEvent, error) {
+		seconds, err := attempt.Resume(now)
+		if err != nil {
+			return nil, err
+		}
+		return &models.AttemptTimeEvent{AttemptID: attempt.ID, Kind: models.TimeEventResume, Seconds: seconds, ActorID: actorID, Reason: reason}, nil
+	})
+}
+
+// ExtendAttempt gives an attempt in progress more time, paused or not
+func (s *attemptService) ExtendAttempt(attemptID, actorID uint, minutes int, reason string) (*models.Attempt, error) {
+	if minutes <= 0 {
+		return nil, fmt.Errorf("%w: minutes must be positive", ErrInvalidExtension)
+	}
+
+	return s.changeTime(attemptID, "ExtendAttempt", func(attempt *models.Attempt, now time.Time) (*models.AttemptTimeEvent, error) {
+		attempt.ExtraSeconds += minutes * 60
+		return &models.AttemptTimeEvent{AttemptID: attempt.ID, Kind: models.TimeEventExtend, Seconds: minutes * 60, ActorID: actorID, Reason: reason}, nil
+	})
+}
+
+// changeTime applies change to the clock of an attempt in progress and stores it with the event
+// change returns
+func (s *attemptService) changeTime(attemptID uint, fn string, change func(*models.Attempt, time.Time) (*models.AttemptTimeEvent, error)) (*models.Attempt, error) {
+	attempt, err := s.findAttempt(attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	if attempt.Status != models.AttemptInProgress {
+		return nil, fmt.Errorf("%w: attempt is %s", ErrAttemptNotLive, attempt.Status)
+	}
+
+	event, err := change(attempt, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attemptRepo.SaveTimeEvent(attempt, event); err != nil {
+		s.log.Error("["+fn+"] Failed to save attempt time", zap.Error(err))
+		return nil, err
+	}
+
+	// The student's live channels and the proctoring feeds send the changed clock
+	live.Send(s.hub, models.NewAttemptEvent(attempt, models.LiveEventTime, nil), s.log)
+
+	return attempt, nil
+}
+
+func (s *attemptService) SendMessage(attemptID, actorID uint, message models.ProctorMessageDTO) error {
+	text := strings.TrimSpace(message.Message)
+	if text == "" {
+		return fmt.Errorf("%w: message is required", ErrInvalidMessage)
+	}
+
+	severity := strings.ToUpper(strings.TrimSpace(message.Severity))
+	switch severity {
+	case "":
+		severity = models.ProctorSeverityInfo
+	case models.ProctorSeverityInfo, models.ProctorSeverityWarning, models.ProctorSeverityCritical:
+	default:
+		return fmt.Errorf("%w: unknown severity %q", ErrInvalidMessage, message.Severity)
+	}
+
+	attempt, err := s.findAttempt(attemptID)
+	if err != nil {
+		return err
+	}
+
+	if attempt.Status != models.AttemptInProgress {
+		return fmt.Errorf("%w: attempt is %s", ErrAttemptNotLive, attempt.Status)
+	}
+
+	if s.hub == nil {
+		return nil
+	}
+	if err := s.hub.Publish(models.NewAttemptEvent(attempt, models.LiveEventMessage, map[string]interface{}{
+		"message":  text,
+		"severity": severity,
+		"from":     actorID,
+	})); err != nil {
+		s.log.Error("[SendMessage] Failed to publish proctor message", zap.Error(err))
 		return err
 	}
 
 	return nil
 }
+
+func (s *attemptService) GetProctoringDashboard(assessmentID uint) ([]models.ProctoredAttempt, error) {
+	if _, err := s.findAssessment(assessmentID); err != nil {
+		return nil, err
+	}
+
+	attempts, err := s.attemptRepo.FindLiveAttempts(assessmentID)
+	if err != nil {
+		s.log.Error("[GetProctoringDashboard] Failed to find live attempts", zap.Error(err))
+		return nil, err
+	}
+
+	questions, err := s.questionRepo.FindByAssessmentID(assessmentID)
+	if err != nil {
+		s.log.Error("[GetProctoringDashboard] Failed to find questions", zap.Error(err))
+		return nil, err
+	}
+
+	ids := make([]uint, len(attempts))
+	for i, attempt := range attempts {
+		ids[i] = attempt.ID
+	}
+	activities, err := s.attemptRepo.FindSuspiciousActivitiesByAttempts(ids)
+	if err != nil {
+		s.log.Error("[GetProctoringDashboard] Failed to find suspicious activities", zap.Error(err))
+		return nil, err
+	}
+	recent := make(map[uint][]models.SuspiciousActivity)
+	for _, activity := range activities {
+		if len(recent[activity.AttemptID]) < recentActivityLimit {
+			recent[activity.AttemptID] = append(recent[activity.AttemptID], activity)
+		}
+	}
+
+	dashboard := make([]models.ProctoredAttempt, len(attempts))
+	for i := range attempts {
+		attempt := &attempts[i]
+		dashboard[i] = models.ProctoredAttempt{
+			AttemptID:       attempt.ID,
+			UserID:          attempt.UserID,
+			Name:            attempt.User.Name,
+			Email:           attempt.User.Email,
+			StartedAt:       attempt.StartedAt,
+			LastHeartbeatAt: attempt.HeartbeatAt,
+			Answered:        attempt.AnsweredCount(),
+			Total:           len(attempt.GivenQuestions(questions)),
+			Paused:          attempt.IsPaused(),
+			FlaggedAt:       attempt.FlaggedAt,
+			FlagReason:      attempt.FlagReason,
+			RecentActivity:  recent[attempt.ID],
+		}
+		if dashboard[i].RecentActivity == nil {
+			dashboard[i].RecentActivity = []models.SuspiciousActivity{}
+		}
+	}
+
+	return dashboard, nil
+}
+
+// FlagAttempt marks an attempt for review. Flagging an attempt again replaces the reason.
+func (s *attemptService) FlagAttempt(attemptID, actorID uint, reason string) (*models.Attempt, error) {
+	attempt, err := s.findAttempt(attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt.Flag(actorID, strings.TrimSpace(reason), time.Now())
+	if err := s.attemptRepo.SaveProctorAction(attempt); err != nil {
+		s.log.Error("[FlagAttempt] Failed to flag attempt", zap.Error(err))
+		return nil, err
+	}
+
+	live.Send(s.hub, models.NewAttemptEvent(attempt, models.LiveEventFlagged, map[string]interface{}{
+		"reason": attempt.FlagReason,
+		"by":     actorID,
+	}), s.log)
+
+	return attempt, nil
+}
+
+// TerminateAttempt ends an attempt in progress for misconduct. The attempt is flagged with the
+// reason and voided, so it no longer counts towards results.
+func (s *attemptService) TerminateAttempt(attemptID, actorID uint, reason string) (*models.Attempt, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidProctorAction)
+	}
+
+	attempt, err := s.findAttempt(attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	if attempt.Status != models.AttemptInProgress {
+		return nil, fmt.Errorf("%w: attempt is %s", ErrAttemptNotLive, attempt.Status)
+	}
+
+	now := time.Now()
+	if err := attempt.TransitionTo(models.AttemptVoided); err != nil {
+		return nil, err
+	}
+	attempt.EndedAt = &now
+	attempt.Flag(actorID, reason, now)
+
+	if err := s.attemptRepo.SaveProctorAction(attempt); err != nil {
+		s.log.Error("[TerminateAttempt] Failed to terminate attempt", zap.Error(err))
+		return nil, err
+	}
+
+	live.Send(s.hub, models.NewAttemptEvent(attempt, models.LiveEventTerminated, map[string]interface{}{
+		"reason": reason,
+	}), s.log)
+
+	return attempt, nil
+}
+
+// GetTimeEvents returns the time ledger of an attempt, oldest first
+func (s *attemptService) GetTimeEvents(attemptID uint) ([]models.AttemptTimeEvent, error) {
+	if _, err := s.findAttempt(attemptID); err != nil {
+		return nil, err
+	}
+
+	events, err := s.attemptRepo.FindTimeEventsByAttempt(attemptID)
+	if err != nil {
+		s.log.Error("[GetTimeEvents] Failed to find time events", zap.Error(err))
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (s *attemptService) findAssessment(assessmentID uint) (*models.Assessment, error) {
+	assessment, err := s.assessmentRepo.FindByID(assessmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAssessmentNotFound
+		}
+		s.log.Error("Failed to find assessment", zap.Error(err))
+		return nil, err
+	}
+
+	return assessment, nil
+}
+
+func (s *attemptService) findAttempt(attemptID uint) (*models.Attempt, error) {
+	attempt, err := s.attemptRepo.FindByID(attemptID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttemptNotFound
+		}
+		s.log.Error("Failed to find attempt", zap.Error(err))
+		return nil, err
+	}
+
+	return attempt, nil
+}
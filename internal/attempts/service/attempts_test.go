@@ -1,13 +1,20 @@
 package service
 
 import (
+	repository_attempt "assessment_service/internal/attempts/repository"
 	models "assessment_service/internal/model"
+	repository_question "assessment_service/internal/questions/repository"
+	"assessment_service/internal/questions/types"
 	"assessment_service/internal/util"
 	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
 	"testing"
+	"time"
 )
 
 // --- Mock AttemptRepository ---
@@ -59,6 +66,116 @@ func (m *MockAttemptRepository) FindAnswersByAttemptID(attemptID uint) ([]models
 	return answers, args.Error(1)
 }
 
+func (m *MockAttemptRepository) SaveAnswerGrade(answer *models.Answer) error {
+	args := m.Called(answer)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindPendingManualAnswers(params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	args := m.Called(params)
+	results, _ := args.Get(0).([]map[string]interface{})
+	count, _ := args.Get(1).(int64)
+	return results, count, args.Error(2)
+}
+
+func (m *MockAttemptRepository) FindGraderIDs(assessmentID uint) ([]uint, error) {
+	args := m.Called(assessmentID)
+	ids, _ := args.Get(0).([]uint)
+	return ids, args.Error(1)
+}
+
+func (m *MockAttemptRepository) FindUnassignedManualAnswers(assessmentID uint) ([]models.Answer, error) {
+	args := m.Called(assessmentID)
+	answers, _ := args.Get(0).([]models.Answer)
+	return answers, args.Error(1)
+}
+
+func (m *MockAttemptRepository) CreateGradingAssignments(assignments []models.GradingAssignment) error {
+	args := m.Called(assignments)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindAssignmentsByAnswer(answerID uint) ([]models.GradingAssignment, error) {
+	args := m.Called(answerID)
+	assignments, _ := args.Get(0).([]models.GradingAssignment)
+	return assignments, args.Error(1)
+}
+
+func (m *MockAttemptRepository) UpdateGradingAssignment(assignment *models.GradingAssignment) error {
+	args := m.Called(assignment)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindAssignmentsByGrader(graderID uint, params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	args := m.Called(graderID, params)
+	results, _ := args.Get(0).([]map[string]interface{})
+	count, _ := args.Get(1).(int64)
+	return results, count, args.Error(2)
+}
+
+func (m *MockAttemptRepository) GetGradingProgress(assessmentID uint) (map[string]interface{}, error) {
+	args := m.Called(assessmentID)
+	progress, _ := args.Get(0).(map[string]interface{})
+	return progress, args.Error(1)
+}
+
+func (m *MockAttemptRepository) CreateGradeEvents(events []models.GradeEvent) error {
+	args := m.Called(events)
+	return args.Error(0)
+}
+func (m *MockAttemptRepository) SaveGrade(change models.GradeChange) error {
+	args := m.Called(change)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindGradeEventsByAttempt(attemptID uint) ([]models.GradeEvent, error) {
+	args := m.Called(attemptID)
+	events, _ := args.Get(0).([]models.GradeEvent)
+	return events, args.Error(1)
+}
+
+func (m *MockAttemptRepository) CreateRegradeRequest(request *models.RegradeRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindRegradeRequestByID(id uint) (*models.RegradeRequest, error) {
+	args := m.Called(id)
+	request, _ := args.Get(0).(*models.RegradeRequest)
+	return request, args.Error(1)
+}
+
+func (m *MockAttemptRepository) FindRegradeRequestsByAttempt(attemptID uint) ([]models.RegradeRequest, error) {
+	args := m.Called(attemptID)
+	requests, _ := args.Get(0).([]models.RegradeRequest)
+	return requests, args.Error(1)
+}
+
+func (m *MockAttemptRepository) FindRegradeRequests(params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	args := m.Called(params)
+	results, _ := args.Get(0).([]map[string]interface{})
+	count, _ := args.Get(1).(int64)
+	return results, count, args.Error(2)
+}
+
+func (m *MockAttemptRepository) UpdateRegradeRequest(request *models.RegradeRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindScoredAttempts(assessmentID uint) ([]models.Attempt, error) {
+	args := m.Called(assessmentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Attempt), args.Error(1)
+}
+
+func (m *MockAttemptRepository) SaveRescore(attempts []*models.Attempt, answers []*models.Answer, events []models.GradeEvent) error {
+	args := m.Called(attempts, answers, events)
+	return args.Error(0)
+}
+
 func (m *MockAttemptRepository) FindAnswerByAttemptAndQuestion(attemptID, questionID uint) (*models.Answer, error) {
 	args := m.Called(attemptID, questionID)
 	if args.Get(0) == nil {
@@ -180,12 +297,189 @@ func (m *MockAttemptRepository) IsUserInAttempt(userID uint) (bool, error) {
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockAttemptRepository) WithTx(tx *gorm.DB) repository_attempt.AttemptRepository {
+	return m
+}
+
+func (m *MockAttemptRepository) FindInProgressAttempt(userID, assessmentID uint) (*models.Attempt, error) {
+	args := m.Called(userID, assessmentID)
+	attempt, _ := args.Get(0).(*models.Attempt)
+	return attempt, args.Error(1)
+}
+
+func (m *MockAttemptRepository) SaveTimeEvent(attempt *models.Attempt, event *models.AttemptTimeEvent) error {
+	args := m.Called(attempt, event)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindTimeEventsByAttempt(attemptID uint) ([]models.AttemptTimeEvent, error) {
+	args := m.Called(attemptID)
+	events, _ := args.Get(0).([]models.AttemptTimeEvent)
+	return events, args.Error(1)
+}
+
+func (m *MockAttemptRepository) SaveHeartbeat(attemptID uint, at time.Time) error {
+	args := m.Called(attemptID, at)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindLiveAttempts(assessmentID uint) ([]models.Attempt, error) {
+	args := m.Called(assessmentID)
+	attempts, _ := args.Get(0).([]models.Attempt)
+	return attempts, args.Error(1)
+}
+
+func (m *MockAttemptRepository) FindSuspiciousActivitiesByAttempts(attemptIDs []uint) ([]models.SuspiciousActivity, error) {
+	args := m.Called(attemptIDs)
+	activities, _ := args.Get(0).([]models.SuspiciousActivity)
+	return activities, args.Error(1)
+}
+
+func (m *MockAttemptRepository) SaveProctorAction(attempt *models.Attempt) error {
+	args := m.Called(attempt)
+	return args.Error(0)
+}
+
+// --- Mock AssessmentRepository ---
+type MockAssessmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAssessmentRepository) Create(assessment *models.Assessment) error {
+	args := m.Called(assessment)
+	return args.Error(0)
+}
+func (m *MockAssessmentRepository) FindByID(id uint) (*models.Assessment, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Assessment), args.Error(1)
+}
+func (m *MockAssessmentRepository) Update(assessment *models.Assessment) error {
+	args := m.Called(assessment)
+	return args.Error(0)
+}
+func (m *MockAssessmentRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+func (m *MockAssessmentRepository) List(params util.PaginationParams) ([]models.Assessment, int64, error) {
+	args := m.Called(params)
+	return args.Get(0).([]models.Assessment), args.Get(1).(int64), args.Error(2)
+}
+func (m *MockAssessmentRepository) FindRecent(limit int) ([]models.Assessment, error) {
+	args := m.Called(limit)
+	return args.Get(0).([]models.Assessment), args.Error(1)
+}
+func (m *MockAssessmentRepository) GetStatistics() (map[string]interface{}, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]interface{}), args.Error(1)
+}
+func (m *MockAssessmentRepository) UpdateSettings(id uint, settings *models.AssessmentSettings) error {
+	args := m.Called(id, settings)
+	return args.Error(0)
+}
+func (m *MockAssessmentRepository) GetResults(id uint, params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	args := m.Called(id, params)
+	return args.Get(0).([]map[string]interface{}), args.Get(1).(int64), args.Error(2)
+}
+func (m *MockAssessmentRepository) Duplicate(assessment *models.Assessment) error {
+	args := m.Called(assessment)
+	return args.Error(0)
+}
+func (m *MockAssessmentRepository) GetAssessmentHasAttemptByUser(params util.PaginationParams, userID uint) ([]models.Assessment, int64, error) {
+	args := m.Called(params, userID)
+	return args.Get(0).([]models.Assessment), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockAssessmentRepository) UpdateStatus(transition *models.AssessmentStatusTransition) (bool, error) {
+	args := m.Called(transition)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAssessmentRepository) ListStatusHistory(assessmentID uint) ([]models.AssessmentStatusTransition, error) {
+	args := m.Called(assessmentID)
+	transitions, _ := args.Get(0).([]models.AssessmentStatusTransition)
+	return transitions, args.Error(1)
+}
+
+func (m *MockAssessmentRepository) ActivateScheduled(now time.Time) (int64, error) {
+	args := m.Called(now)
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
+func (m *MockAssessmentRepository) CloseEnded(now time.Time) (int64, error) {
+	args := m.Called(now)
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
 // --- Test Cases ---
 
+// --- Mock QuestionRepository ---
+type MockQuestionRepository struct{ mock.Mock }
+
+func (m *MockQuestionRepository) Create(question *models.Question) error {
+	args := m.Called(question)
+	return args.Error(0)
+}
+func (m *MockQuestionRepository) FindByID(id uint) (*models.Question, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Question), args.Error(1)
+}
+func (m *MockQuestionRepository) FindByAssessmentID(assessmentID uint) ([]models.Question, error) {
+	args := m.Called(assessmentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Question), args.Error(1)
+}
+func (m *MockQuestionRepository) Update(question *models.Question) error {
+	args := m.Called(question)
+	return args.Error(0)
+}
+func (m *MockQuestionRepository) Delete(id uint) error { args := m.Called(id); return args.Error(0) }
+func (m *MockQuestionRepository) AddOption(option *models.QuestionOption) error {
+	args := m.Called(option)
+	return args.Error(0)
+}
+func (m *MockQuestionRepository) UpdateOption(option *models.QuestionOption) error {
+	args := m.Called(option)
+	return args.Error(0)
+}
+func (m *MockQuestionRepository) DeleteOption(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockQuestionRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return fn(nil)
+}
+
+func (m *MockQuestionRepository) WithTx(tx *gorm.DB) repository_question.QuestionRepository {
+	return m
+}
+
+// --- Mock Publisher ---
+type MockPublisher struct{ mock.Mock }
+
+func (m *MockPublisher) Publish(event models.LiveEvent) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
 func TestAttemptService_GetListAttemptByUserAndAssessment(t *testing.T) {
 	mockRepo := new(MockAttemptRepository)
 	logger := zaptest.NewLogger(t)
-	service := NewAttemptService(mockRepo, logger)
+	service := NewAttemptService(mockRepo, new(MockAssessmentRepository), new(MockQuestionRepository), nil, logger)
 
 	userID := uint(1)
 	assessmentID := uint(10)
@@ -206,7 +500,7 @@ func TestAttemptService_GetListAttemptByUserAndAssessment(t *testing.T) {
 func TestAttemptService_GetListAttemptByUserAndAssessment_RepoError(t *testing.T) {
 	mockRepo := new(MockAttemptRepository)
 	logger := zaptest.NewLogger(t)
-	service := NewAttemptService(mockRepo, logger)
+	service := NewAttemptService(mockRepo, new(MockAssessmentRepository), new(MockQuestionRepository), nil, logger)
 
 	userID := uint(1)
 	assessmentID := uint(10)
@@ -226,25 +520,39 @@ func TestAttemptService_GetListAttemptByUserAndAssessment_RepoError(t *testing.T
 
 func TestAttemptService_GetAttemptDetail(t *testing.T) {
 	mockRepo := new(MockAttemptRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
 	logger := zaptest.NewLogger(t)
-	service := NewAttemptService(mockRepo, logger)
+	service := NewAttemptService(mockRepo, new(MockAssessmentRepository), mockQuestionRepo, nil, logger)
 
 	attemptID := uint(5)
-	expectedAttempt := &models.Attempt{ID: attemptID, Status: "Completed"}
+	options := []models.QuestionOption{{ID: 1, OptionID: "a"}, {ID: 2, OptionID: "b"}, {ID: 3, OptionID: "c"}, {ID: 4, OptionID: "d"}}
+	questions := []models.Question{
+		{ID: 11, AssessmentID: 2, Type: "multiple-choice", Options: options},
+		{ID: 12, AssessmentID: 2, Type: "multiple-choice", Options: options},
+	}
+	// Lượt làm bài nhận câu 12 trước câu 11, và các lựa chọn được xáo trộn lúc bắt đầu
+	expectedAttempt := &models.Attempt{ID: attemptID, AssessmentID: 2, Status: models.AttemptGraded, Seed: 42, ShuffleOptions: true,
+		Selection: []models.AttemptQuestion{{QuestionID: 12, Position: 0}, {QuestionID: 11, Position: 1}}}
 
 	mockRepo.On("FindByID", attemptID).Return(expectedAttempt, nil)
+	mockQuestionRepo.On("FindByAssessmentID", uint(2)).Return(questions, nil)
 
 	attempt, err := service.GetAttemptDetail(attemptID)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedAttempt, attempt)
+	require.Len(t, attempt.Presented, 2)
+	assert.Equal(t, uint(12), attempt.Presented[0].QuestionID)
+	assert.Equal(t, 1, attempt.Presented[1].Position)
+	assert.Equal(t, types.OptionOrder(questions[1], true, attempt.OptionSeed(12)), attempt.Presented[0].Options)
+	assert.ElementsMatch(t, []string{"a", "b", "c", "d"}, attempt.Presented[1].Options)
 	mockRepo.AssertExpectations(t)
 }
 
 func TestAttemptService_GetAttemptDetail_NotFound(t *testing.T) {
 	mockRepo := new(MockAttemptRepository)
 	logger := zaptest.NewLogger(t)
-	service := NewAttemptService(mockRepo, logger)
+	service := NewAttemptService(mockRepo, new(MockAssessmentRepository), new(MockQuestionRepository), nil, logger)
 
 	attemptID := uint(99)
 	repoError := errors.New("record not found")
@@ -261,101 +569,1171 @@ func TestAttemptService_GetAttemptDetail_NotFound(t *testing.T) {
 
 func TestAttemptService_GradeAttempt(t *testing.T) {
 	mockRepo := new(MockAttemptRepository)
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
 	logger := zaptest.NewLogger(t)
-	service := NewAttemptService(mockRepo, logger)
+	service := NewAttemptService(mockRepo, mockAssessmentRepo, mockQuestionRepo, nil, logger)
 
 	attemptID := uint(1)
-	newScore := 85.5
 	newFeedback := "Good job!"
 
 	gradeData := models.AttemptUpdateDTO{
-		Score:    newScore,
+		Score:    85.5, // Bỏ qua vì điểm được tính lại từ các câu trả lời
 		Feedback: newFeedback,
-		Answers: []struct {
-			ID        uint `json:"id"`
-			IsCorrect bool `json:"isCorrect"`
-		}{
+		Answers: []models.AnswerGradeDTO{
 			{ID: 10, IsCorrect: true},
 			{ID: 11, IsCorrect: false},
 		},
+		Reason: "First marking",
 	}
 
 	existingAttempt := &models.Attempt{
-		ID:       attemptID,
-		Score:    nil, // Chưa có điểm
-		Feedback: "",  // Chưa có feedback
+		ID:           attemptID,
+		AssessmentID: 5,
+		Status:       models.AttemptPendingManualGrading,
+		Score:        nil, // Chưa có điểm
+		Feedback:     "",  // Chưa có feedback
 		Answers: []models.Answer{
 			{ID: 10, AttemptID: attemptID, QuestionID: 101, IsCorrect: nil}, // Chưa chấm
 			{ID: 11, AttemptID: attemptID, QuestionID: 102, IsCorrect: nil}, // Chưa chấm
 			{ID: 12, AttemptID: attemptID, QuestionID: 103, IsCorrect: nil}, // Câu này không được chấm lại
 		},
 	}
+	questions := []models.Question{
+		{ID: 101, AssessmentID: 5, Type: "essay", Points: 10},
+		{ID: 102, AssessmentID: 5, Type: "essay", Points: 10, Penalty: 0.5, AllowNegative: true},
+		{ID: 103, AssessmentID: 5, Type: "essay", Points: 20},
+	}
 
 	// Expect FindByID to be called
 	mockRepo.On("FindByID", attemptID).Return(existingAttempt, nil)
-
-	// Expect Update to be called with the modified attempt
-	mockRepo.On("Update", mock.MatchedBy(func(a *models.Attempt) bool {
-		// Kiểm tra điểm và feedback
-		scoreMatch := a.Score != nil && *a.Score == newScore
+	mockAssessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, PassingScore: 70}, nil)
+	mockQuestionRepo.On("FindByAssessmentID", uint(5)).Return(questions, nil)
+
+	// Expect SaveGrade to be called with the modified attempt and its grade events
+	var events []models.GradeEvent
+	mockRepo.On("SaveGrade", mock.MatchedBy(func(change models.GradeChange) bool {
+		a := change.Attempt
+		// Câu 101 được 10 điểm, câu 102 bị trừ 5 điểm: (10 - 5) / 40 = 12.5%
+		scoreMatch := a.Score != nil && *a.Score == 12.5
 		feedbackMatch := a.Feedback == newFeedback
-		// Kiểm tra các câu trả lời đã được cập nhật isCorrect
+		// Kiểm tra các câu trả lời đã được cập nhật isCorrect và điểm
 		answer10Correct := false
 		answer11Correct := false
 		answer12Unchanged := false
 		for _, ans := range a.Answers {
-			if ans.ID == 10 && ans.IsCorrect != nil && *ans.IsCorrect == true {
+			if ans.ID == 10 && ans.IsCorrect != nil && *ans.IsCorrect && ans.AwardedPoints != nil && *ans.AwardedPoints == 10 {
 				answer10Correct = true
 			}
-			if ans.ID == 11 && ans.IsCorrect != nil && *ans.IsCorrect == false {
+			if ans.ID == 11 && ans.IsCorrect != nil && !*ans.IsCorrect && ans.AwardedPoints != nil && *ans.AwardedPoints == -5 {
 				answer11Correct = true
 			}
-			if ans.ID == 12 && ans.IsCorrect == nil { // Đảm bảo câu 12 không bị thay đổi
+			if ans.ID == 12 && ans.IsCorrect == nil && ans.AwardedPoints == nil { // Đảm bảo câu 12 không bị thay đổi
 				answer12Unchanged = true
 			}
 		}
-		return scoreMatch && feedbackMatch && answer10Correct && answer11Correct && answer12Unchanged
+		// Điểm 12.5 < 70 nên bài bị đánh dấu là trượt và chuyển sang graded
+		graded := a.Status == models.AttemptGraded && a.Passed != nil && !*a.Passed
+		return scoreMatch && feedbackMatch && answer10Correct && answer11Correct && answer12Unchanged && graded && change.Request == nil
+	})).Run(func(args mock.Arguments) {
+		// Lịch sử điểm ghi lại điểm của từng câu và của cả bài, do ai chấm và vì sao
+		events = args.Get(0).(models.GradeChange).Events
+	}).Return(nil)
+
+	err := service.GradeAttempt(gradeData, attemptID, 7)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockQuestionRepo.AssertExpectations(t)
+
+	require.Len(t, events, 3)
+	for _, event := range events {
+		assert.Equal(t, attemptID, event.AttemptID)
+		assert.Equal(t, models.GradeEventManual, event.Kind)
+		assert.Equal(t, uint(7), *event.ActorID)
+		assert.Equal(t, "First marking", event.Reason)
+		assert.Nil(t, event.OldValue)
+	}
+	assert.Equal(t, uint(10), *events[0].AnswerID)
+	assert.Equal(t, 10.0, *events[0].NewValue)
+	assert.Equal(t, uint(11), *events[1].AnswerID)
+	assert.Equal(t, -5.0, *events[1].NewValue)
+	assert.Nil(t, events[2].AnswerID)
+	assert.Equal(t, 12.5, *events[2].NewValue)
+}
+
+func TestAttemptService_GradeAttempt_Regrade(t *testing.T) {
+	mockRepo := new(MockAttemptRepository)
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	service := NewAttemptService(mockRepo, mockAssessmentRepo, new(MockQuestionRepository), nil, zaptest.NewLogger(t))
+
+	score := 60.0
+	mockRepo.On("FindByID", uint(1)).Return(&models.Attempt{ID: 1, AssessmentID: 5, Status: models.AttemptGraded, Score: &score}, nil)
+	mockAssessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, PassingScore: 70}, nil)
+	// Bài đã có điểm nên lần chấm này là chấm lại
+	mockRepo.On("SaveGrade", mock.MatchedBy(func(change models.GradeChange) bool {
+		events := change.Events
+		return change.Attempt != nil && len(events) == 1 && events[0].Kind == models.GradeEventRegrade &&
+			*events[0].OldValue == 60 && *events[0].NewValue == 75 && events[0].Reason == "Marking error"
+	})).Return(nil)
+
+	err := service.GradeAttempt(models.AttemptUpdateDTO{Score: 75, Reason: "Marking error"}, 1, 7)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAttemptService_GradeAttempt_AwardedPoints(t *testing.T) {
+	mockRepo := new(MockAttemptRepository)
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	service := NewAttemptService(mockRepo, mockAssessmentRepo, mockQuestionRepo, nil, zaptest.NewLogger(t))
+
+	auto := 5.0
+	partial := 7.5
+	existingAttempt := &models.Attempt{
+		ID:           1,
+		AssessmentID: 5,
+		Status:       models.AttemptPendingManualGrading,
+		LatePenalty:  10,
+		Answers: []models.Answer{
+			{ID: 10, QuestionID: 101, IsCorrect: &[]bool{true}[0], AwardedPoints: &auto}, // Đã được chấm tự động
+			{ID: 11, QuestionID: 102}, // Bài luận chờ chấm
+		},
+	}
+
+	mockRepo.On("FindByID", uint(1)).Return(existingAttempt, nil)
+	mockAssessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, PassingScore: 70}, nil)
+	mockQuestionRepo.On("FindByAssessmentID", uint(5)).Return([]models.Question{
+		{ID: 101, Type: "true-false", Points: 5},
+		{ID: 102, Type: "essay", Points: 15},
+	}, nil)
+	// (5 + 7.5) / 20 = 62.5%, trừ 10% nộp muộn còn 56.25%
+	mockRepo.On("SaveGrade", mock.MatchedBy(func(change models.GradeChange) bool {
+		a := change.Attempt
+		return a.Score != nil && *a.Score == 56.25 && a.Passed != nil && !*a.Passed &&
+			a.Answers[1].AwardedPoints != nil && *a.Answers[1].AwardedPoints == partial
 	})).Return(nil)
 
-	err := service.GradeAttempt(gradeData, attemptID)
+	err := service.GradeAttempt(models.AttemptUpdateDTO{
+		Answers: []models.AnswerGradeDTO{{ID: 11, IsCorrect: false, AwardedPoints: &partial}},
+	}, 1, 7)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestAttemptService_GradeAttempt_InvalidAwardedPoints(t *testing.T) {
+	tests := []struct {
+		name   string
+		points float64
+	}{
+		{"more than the question", 16},
+		{"negative", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAttemptRepository)
+			mockAssessmentRepo := new(MockAssessmentRepository)
+			mockQuestionRepo := new(MockQuestionRepository)
+			service := NewAttemptService(mockRepo, mockAssessmentRepo, mockQuestionRepo, nil, zaptest.NewLogger(t))
+
+			mockRepo.On("FindByID", uint(1)).Return(&models.Attempt{
+				ID: 1, AssessmentID: 5, Status: models.AttemptPendingManualGrading,
+				Answers: []models.Answer{{ID: 11, QuestionID: 102}},
+			}, nil)
+			mockAssessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, PassingScore: 70}, nil)
+			mockQuestionRepo.On("FindByAssessmentID", uint(5)).Return([]models.Question{{ID: 102, Type: "essay", Points: 15}}, nil)
+
+			err := service.GradeAttempt(models.AttemptUpdateDTO{
+				Answers: []models.AnswerGradeDTO{{ID: 11, AwardedPoints: &tt.points}},
+			}, 1, 7)
+
+			assert.ErrorIs(t, err, ErrInvalidGrade)
+			mockRepo.AssertNotCalled(t, "SaveGrade", mock.Anything)
+		})
+	}
+}
+
 func TestAttemptService_GradeAttempt_AttemptNotFound(t *testing.T) {
 	mockRepo := new(MockAttemptRepository)
 	logger := zaptest.NewLogger(t)
-	service := NewAttemptService(mockRepo, logger)
+	service := NewAttemptService(mockRepo, new(MockAssessmentRepository), new(MockQuestionRepository), nil, logger)
 
 	attemptID := uint(99)
 	gradeData := models.AttemptUpdateDTO{} // Dữ liệu không quan trọng vì sẽ lỗi trước đó
 
-	mockRepo.On("FindByID", attemptID).Return(nil, errors.New("record not found"))
+	mockRepo.On("FindByID", attemptID).Return(nil, fmt.Errorf("attempt with ID %d not found: %w", attemptID, gorm.ErrRecordNotFound))
 
-	err := service.GradeAttempt(gradeData, attemptID)
+	err := service.GradeAttempt(gradeData, attemptID, 7)
 
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "record not found")
+	assert.ErrorIs(t, err, ErrAttemptNotFound)
 	mockRepo.AssertExpectations(t)
-	mockRepo.AssertNotCalled(t, "Update", mock.Anything) // Đảm bảo Update không được gọi
+	mockRepo.AssertNotCalled(t, "SaveGrade", mock.Anything) // Đảm bảo SaveGrade không được gọi
 }
 
 func TestAttemptService_GradeAttempt_UpdateError(t *testing.T) {
 	mockRepo := new(MockAttemptRepository)
+	mockAssessmentRepo := new(MockAssessmentRepository)
 	logger := zaptest.NewLogger(t)
-	service := NewAttemptService(mockRepo, logger)
+	service := NewAttemptService(mockRepo, mockAssessmentRepo, new(MockQuestionRepository), nil, logger)
 
 	attemptID := uint(1)
 	gradeData := models.AttemptUpdateDTO{Score: 90.0}
-	existingAttempt := &models.Attempt{ID: attemptID, Answers: []models.Answer{}} // Attempt rỗng để đơn giản
+	existingAttempt := &models.Attempt{ID: attemptID, AssessmentID: 5, Status: models.AttemptGraded, Answers: []models.Answer{}} // Attempt rỗng để đơn giản
 
 	mockRepo.On("FindByID", attemptID).Return(existingAttempt, nil)
-	mockRepo.On("Update", mock.Anything).Return(errors.New("db update error")) // Giả lập lỗi khi Update
+	mockAssessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, PassingScore: 70}, nil)
+	mockRepo.On("SaveGrade", mock.Anything).Return(errors.New("db update error")) // Giả lập lỗi khi lưu điểm
 
-	err := service.GradeAttempt(gradeData, attemptID)
+	err := service.GradeAttempt(gradeData, attemptID, 7)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "db update error")
-	mockRepo.AssertExpectations(t) // Cả FindByID và Update đều được gọi
+	mockRepo.AssertExpectations(t) // Cả FindByID và SaveGrade đều được gọi
+}
+
+func TestAttemptService_GradeAttempt_NotGradable(t *testing.T) {
+	for _, status := range []models.AttemptStatus{models.AttemptInProgress, models.AttemptVoided} {
+		t.Run(string(status), func(t *testing.T) {
+			mockRepo := new(MockAttemptRepository)
+			service := NewAttemptService(mockRepo, new(MockAssessmentRepository), new(MockQuestionRepository), nil, zaptest.NewLogger(t))
+
+			mockRepo.On("FindByID", uint(1)).Return(&models.Attempt{ID: 1, Status: status}, nil)
+
+			err := service.GradeAttempt(models.AttemptUpdateDTO{Score: 90}, 1, 7)
+
+			assert.ErrorIs(t, err, ErrAttemptNotGradable)
+			mockRepo.AssertNotCalled(t, "SaveGrade", mock.Anything)
+		})
+	}
+}
+
+const essayRubric = `{"rubric":[
+	{"id":"argument","title":"Argument","levels":[{"points":0},{"points":3},{"points":6}]},
+	{"id":"style","title":"Style","levels":[{"points":0},{"points":4}]}
+]}`
+
+func TestAttemptService_GetGradingQueue(t *testing.T) {
+	mockRepo := new(MockAttemptRepository)
+	service := NewAttemptService(mockRepo, new(MockAssessmentRepository), new(MockQuestionRepository), nil, zaptest.NewLogger(t))
+
+	params := util.PaginationParams{Page: 1, Limit: 10, Filters: map[string]interface{}{"gradableBy": uint(3)}}
+	pending := []map[string]interface{}{{"answer_id": uint(11), "attempt_id": uint(1)}}
+	mockRepo.On("FindPendingManualAnswers", params).Return(pending, int64(1), nil)
+
+	answers, total, err := service.GetGradingQueue(params)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, pending, answers)
+}
+
+func TestAttemptService_GradeAnswer_CompletesGrading(t *testing.T) {
+	mockRepo := new(MockAttemptRepository)
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	service := NewAttemptService(mockRepo, mockAssessmentRepo, mockQuestionRepo, nil, zaptest.NewLogger(t))
+
+	auto := 10.0
+	attempt := &models.Attempt{
+		ID:           1,
+		AssessmentID: 5,
+		Status:       models.AttemptPendingManualGrading,
+		Answers: []models.Answer{
+			{ID: 10, QuestionID: 101, Answer: "true", IsCorrect: &[]bool{true}[0], AwardedPoints: &auto},
+			{ID: 11, QuestionID: 102, Answer: "My essay"},
+		},
+	}
+	questions := []models.Question{
+		{ID: 101, Type: "true-false", CorrectAnswer: "true", Points: 10},
+		{ID: 102, Type: "essay", Points: 10, Config: models.QuestionConfig(essayRubric)},
+	}
+	grade := models.AnswerGradeInput{
+		RubricScores: []models.RubricScore{{CriterionID: "argument", Points: 6}, {CriterionID: "style", Points: 0, Comment: "Too informal"}},
+		Comment:      "Good argument",
+	}
+
+	mockRepo.On("FindByID", uint(1)).Return(attempt, nil)
+	mockQuestionRepo.On("FindByAssessmentID", uint(5)).Return(questions, nil)
+	mockRepo.On("FindAssignmentsByAnswer", uint(11)).Return(nil, nil)
+	mockAssessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, PassingScore: 70}, nil)
+	// Điểm của câu, của bài và lịch sử điểm được lưu cùng nhau
+	mockRepo.On("SaveGrade", mock.MatchedBy(func(change models.GradeChange) bool {
+		a := change.Answer
+		answerMatch := a != nil && a.ID == 11 && a.AwardedPoints != nil && *a.AwardedPoints == 6 && a.IsCorrect != nil && !*a.IsCorrect &&
+			a.Comment == "Good argument" && len(a.RubricScores) == 2 &&
+			a.GradedByID != nil && *a.GradedByID == 3 && a.GradedAt != nil
+		// Bài luận được chấm xong: (10 + 6) / 20 = 80%, đạt
+		at := change.Attempt
+		attemptMatch := at != nil && at.Status == models.AttemptGraded && at.Score != nil && *at.Score == 80 && at.Passed != nil && *at.Passed
+		events := change.Events
+		return answerMatch && attemptMatch && change.Assignment == nil && len(events) == 2 &&
+			*events[0].AnswerID == 11 && events[0].Kind == models.GradeEventManual && *events[0].NewValue == 6 && *events[0].ActorID == 3 &&
+			events[1].AnswerID == nil && events[1].Kind == models.GradeEventManual && *events[1].NewValue == 80
+	})).Return(nil)
+
+	result, err := service.GradeAnswer(1, 11, 3, grade)
+
+	require.NoError(t, err)
+	assert.Equal(t, models.AttemptGraded, result.Status)
+	mockRepo.AssertExpectations(t)
+	mockAssessmentRepo.AssertExpectations(t)
+}
+
+func TestAttemptService_GradeAnswer_OtherEssaysPending(t *testing.T) {
+	mockRepo := new(MockAttemptRepository)
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	service := NewAttemptService(mockRepo, mockAssessmentRepo, mockQuestionRepo, nil, zaptest.NewLogger(t))
+
+	attempt := &models.Attempt{
+		ID:           1,
+		UserID:       8,
+		AssessmentID: 5,
+		Status:       models.AttemptPendingManualGrading,
+		Answers: []models.Answer{
+			{ID: 11, QuestionID: 102, Answer: "First essay"},
+			{ID: 12, QuestionID: 103, Answer: "Second essay"},
+		},
+	}
+	points := 4.0
+
+	mockRepo.On("FindByID", uint(1)).Return(attempt, nil)
+	mockQuestionRepo.On("FindByAssessmentID", uint(5)).Return([]models.Question{
+		{ID: 102, Type: "essay", Points: 5},
+		{ID: 103, Type: "essay", Points: 5},
+	}, nil)
+	mockAssessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, Settings: models.AssessmentSettings{BlindGrading: true}}, nil)
+	mockRepo.On("FindAssignmentsByAnswer", uint(11)).Return(nil, nil)
+	// Câu 12 vẫn chờ chấm nên chỉ lưu điểm của câu, không lưu bài
+	mockRepo.On("SaveGrade", mock.MatchedBy(func(change models.GradeChange) bool {
+		return change.Answer != nil && change.Answer.ID == 11 && change.Attempt == nil
+	})).Return(nil)
+
+	result, err := service.GradeAnswer(1, 11, 3, models.AnswerGradeInput{Points: &points})
+
+	require.NoError(t, err)
+	// Câu 12 vẫn chờ chấm nên bài chưa có điểm cuối cùng
+	assert.Equal(t, models.AttemptPendingManualGrading, result.Status)
+	assert.Nil(t, result.Passed)
+	// Chấm ẩn danh: người chấm không biết bài của ai
+	assert.Zero(t, result.UserID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAttemptService_GradeAnswer_Errors(t *testing.T) {
+	five := 5.0
+	six := 6.0
+	negative := -1.0
+
+	tests := []struct {
+		name     string
+		status   models.AttemptStatus
+		answerID uint
+		grade    models.AnswerGradeInput
+		wantErr  error
+	}{
+		{"attempt in progress", models.AttemptInProgress, 11, models.AnswerGradeInput{Points: &five}, ErrAttemptNotGradable},
+		{"answer not in attempt", models.AttemptPendingManualGrading, 99, models.AnswerGradeInput{Points: &five}, ErrAnswerNotFound},
+		{"automatically graded answer", models.AttemptPendingManualGrading, 10, models.AnswerGradeInput{Points: &five}, ErrNotManuallyGraded},
+		{"points above the question", models.AttemptPendingManualGrading, 11, models.AnswerGradeInput{Points: &six}, ErrInvalidGrade},
+		{"negative points", models.AttemptPendingManualGrading, 11, models.AnswerGradeInput{Points: &negative}, ErrInvalidGrade},
+		{"no points", models.AttemptPendingManualGrading, 11, models.AnswerGradeInput{Comment: "?"}, ErrInvalidGrade},
+		{"rubric scores without a rubric", models.AttemptPendingManualGrading, 11, models.AnswerGradeInput{RubricScores: []models.RubricScore{{CriterionID: "style", Points: 4}}}, ErrInvalidGrade},
+		{"points for a rubric question", models.AttemptPendingManualGrading, 12, models.AnswerGradeInput{Points: &five}, ErrInvalidGrade},
+		{"incomplete rubric scores", models.AttemptPendingManualGrading, 12, models.AnswerGradeInput{RubricScores: []models.RubricScore{{CriterionID: "style", Points: 4}}}, ErrInvalidGrade},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAttemptRepository)
+			mockQuestionRepo := new(MockQuestionRepository)
+			service := NewAttemptService(mockRepo, new(MockAssessmentRepository), mockQuestionRepo, nil, zaptest.NewLogger(t))
+
+			mockRepo.On("FindByID", uint(1)).Return(&models.Attempt{
+				ID: 1, AssessmentID: 5, Status: tt.status,
+				Answers: []models.Answer{
+					{ID: 10, QuestionID: 101, Answer: "a"},
+					{ID: 11, QuestionID: 102, Answer: "Essay"},
+					{ID: 12, QuestionID: 103, Answer: "Essay with rubric"},
+				},
+			}, nil)
+			mockQuestionRepo.On("FindByAssessmentID", uint(5)).Return([]models.Question{
+				{ID: 101, Type: "multiple-choice", CorrectAnswer: "a", Points: 5, Options: []models.QuestionOption{{OptionID: "a"}}},
+				{ID: 102, Type: "essay", Points: 5},
+				{ID: 103, Type: "essay", Points: 10, Config: models.QuestionConfig(essayRubric)},
+			}, nil)
+
+			_, err := service.GradeAnswer(1, tt.answerID, 3, tt.grade)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			mockRepo.AssertNotCalled(t, "SaveGrade", mock.Anything)
+		})
+	}
+}
+
+// gradingFixture là một bài có hai câu tự luận 10 điểm đã được giao cho người chấm
+func gradingFixture(t *testing.T, settings models.AssessmentSettings) (AttemptService, *MockAttemptRepository, *models.Attempt) {
+	mockRepo := new(MockAttemptRepository)
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	service := NewAttemptService(mockRepo, mockAssessmentRepo, mockQuestionRepo, nil, zaptest.NewLogger(t))
+
+	attempt := &models.Attempt{
+		ID:           1,
+		UserID:       8,
+		AssessmentID: 5,
+		Status:       models.AttemptPendingManualGrading,
+		Answers: []models.Answer{
+			{ID: 11, QuestionID: 102, Answer: "First essay"},
+			{ID: 12, QuestionID: 103, Answer: "Second essay"},
+		},
+	}
+
+	mockRepo.On("FindByID", uint(1)).Return(attempt, nil)
+	mockQuestionRepo.On("FindByAssessmentID", uint(5)).Return([]models.Question{
+		{ID: 102, Type: "essay", Points: 10},
+		{ID: 103, Type: "essay", Points: 10},
+	}, nil)
+	mockAssessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, PassingScore: 50, Settings: settings}, nil)
+
+	return service, mockRepo, attempt
+}
+
+func TestAttemptService_GradeAnswer_AssignedGrader(t *testing.T) {
+	service, mockRepo, _ := gradingFixture(t, models.AssessmentSettings{})
+	points := 7.0
+
+	mockRepo.On("FindAssignmentsByAnswer", uint(11)).Return([]models.GradingAssignment{
+		{ID: 1, AnswerID: 11, GraderID: 4, Status: models.GradingAssigned},
+	}, nil)
+
+	// Bài đã được giao cho người chấm khác
+	_, err := service.GradeAnswer(1, 11, 3, models.AnswerGradeInput{Points: &points})
+	assert.ErrorIs(t, err, ErrNotAssignedGrader)
+	mockRepo.AssertNotCalled(t, "SaveGrade", mock.Anything)
+
+	// Bài được giao và điểm của câu được lưu cùng nhau
+	mockRepo.On("SaveGrade", mock.MatchedBy(func(change models.GradeChange) bool {
+		assignment, a := change.Assignment, change.Answer
+		return assignment != nil && assignment.ID == 1 && assignment.Status == models.GradingCompleted &&
+			assignment.Points != nil && *assignment.Points == 7 && assignment.CompletedAt != nil &&
+			a != nil && a.ID == 11 && a.AwardedPoints != nil && *a.AwardedPoints == 7 && *a.GradedByID == 4
+	})).Return(nil)
+
+	result, err := service.GradeAnswer(1, 11, 4, models.AnswerGradeInput{Points: &points})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.AttemptPendingManualGrading, result.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAttemptService_GradeAnswer_DoubleMarking(t *testing.T) {
+	six := 6.0
+	nine := 9.0
+
+	tests := []struct {
+		name        string
+		other       models.GradingAssignment
+		points      float64
+		wantAwarded *float64
+		wantComment string
+	}{
+		{"other grader has not marked", models.GradingAssignment{ID: 2, GraderID: 4, Status: models.GradingAssigned}, 7, nil, ""},
+		{"marks within the threshold are averaged", models.GradingAssignment{ID: 2, GraderID: 4, Status: models.GradingCompleted, Points: &six, Comment: "Fair"}, 7, floatPtr(6.5), "Good\n\nFair"},
+		{"marks too far apart wait for reconciliation", models.GradingAssignment{ID: 2, GraderID: 4, Status: models.GradingCompleted, Points: &nine}, 6, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockRepo, _ := gradingFixture(t, models.AssessmentSettings{DoubleMarking: true, ReconciliationThreshold: 2})
+			tt.other.AnswerID = 11
+
+			mockRepo.On("FindAssignmentsByAnswer", uint(11)).Return([]models.GradingAssignment{
+				{ID: 1, AnswerID: 11, GraderID: 3, Status: models.GradingAssigned},
+				tt.other,
+			}, nil)
+			mockRepo.On("SaveGrade", mock.MatchedBy(func(change models.GradeChange) bool {
+				assignment, a := change.Assignment, change.Answer
+				if assignment == nil || assignment.ID != 1 || *assignment.Points != tt.points || assignment.Comment != "Good" {
+					return false
+				}
+				if tt.wantAwarded == nil {
+					return a.AwardedPoints == nil && len(change.Events) == 0
+				}
+				return a.AwardedPoints != nil && *a.AwardedPoints == *tt.wantAwarded && a.Comment == tt.wantComment
+			})).Return(nil)
+
+			_, err := service.GradeAnswer(1, 11, 3, models.AnswerGradeInput{Points: &tt.points, Comment: "Good"})
+
+			require.NoError(t, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("marks are final once combined", func(t *testing.T) {
+		service, mockRepo, attempt := gradingFixture(t, models.AssessmentSettings{DoubleMarking: true})
+		attempt.Answers[0].AwardedPoints = &six
+
+		mockRepo.On("FindAssignmentsByAnswer", uint(11)).Return([]models.GradingAssignment{
+			{ID: 1, AnswerID: 11, GraderID: 3, Status: models.GradingCompleted, Points: &six},
+			{ID: 2, AnswerID: 11, GraderID: 4, Status: models.GradingCompleted, Points: &six},
+		}, nil)
+
+		_, err := service.GradeAnswer(1, 11, 3, models.AnswerGradeInput{Points: &nine})
+
+		assert.ErrorIs(t, err, ErrMarksFinal)
+		mockRepo.AssertNotCalled(t, "SaveGrade", mock.Anything)
+	})
+}
+
+func TestAttemptService_ReconcileAnswer(t *testing.T) {
+	six := 6.0
+	nine := 9.0
+	eight := 8.0
+
+	service, mockRepo, attempt := gradingFixture(t, models.AssessmentSettings{DoubleMarking: true, ReconciliationThreshold: 1})
+	attempt.Answers[1].AwardedPoints = &six
+
+	mockRepo.On("FindAssignmentsByAnswer", uint(11)).Return([]models.GradingAssignment{
+		{ID: 1, AnswerID: 11, GraderID: 3, Status: models.GradingCompleted, Points: &six},
+		{ID: 2, AnswerID: 11, GraderID: 4, Status: models.GradingCompleted, Points: &nine},
+	}, nil)
+	mockRepo.On("SaveGrade", mock.MatchedBy(func(change models.GradeChange) bool {
+		a, at, events := change.Answer, change.Attempt, change.Events
+		answerMatch := a.ID == 11 && *a.AwardedPoints == 8 && *a.GradedByID == 2 && a.Comment == "Settled"
+		// Câu cuối cùng đã có điểm: (8 + 6) / 20 = 70%
+		attemptMatch := at != nil && at.Status == models.AttemptGraded && *at.Score == 70 && *at.Passed
+		return answerMatch && attemptMatch &&
+			len(events) == 2 && *events[0].AnswerID == 11 && *events[0].NewValue == 8 && *events[0].ActorID == 2
+	})).Return(nil)
+
+	result, err := service.ReconcileAnswer(1, 11, 2, models.AnswerGradeInput{Points: &eight, Comment: "Settled"})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.AttemptGraded, result.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAttemptService_GradeAnswer_Regrade(t *testing.T) {
+	service, mockRepo, attempt := gradingFixture(t, models.AssessmentSettings{})
+	five, seven, score := 5.0, 7.0, 50.0
+	attempt.Status = models.AttemptGraded
+	attempt.Score = &score
+	attempt.Answers[0].AwardedPoints = &five
+	attempt.Answers[1].AwardedPoints = &five
+
+	mockRepo.On("FindAssignmentsByAnswer", uint(11)).Return(nil, nil)
+	// Câu và bài đã có điểm nên cả hai thay đổi đều là chấm lại, kèm điểm cũ và lý do
+	mockRepo.On("SaveGrade", mock.MatchedBy(func(change models.GradeChange) bool {
+		events := change.Events
+		return change.Answer != nil && change.Attempt != nil && len(events) == 2 &&
+			events[0].Kind == models.GradeEventRegrade && *events[0].OldValue == 5 && *events[0].NewValue == 7 && events[0].Reason == "Missed a page" &&
+			events[1].Kind == models.GradeEventRegrade && *events[1].OldValue == 50 && *events[1].NewValue == 60
+	})).Return(nil)
+
+	_, err := service.GradeAnswer(1, 11, 3, models.AnswerGradeInput{Points: &seven, Reason: "Missed a page"})
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAttemptService_ReconcileAnswer_NotReconcilable(t *testing.T) {
+	six := 6.0
+
+	tests := []struct {
+		name        string
+		assignments []models.GradingAssignment
+	}{
+		{"not double-marked", []models.GradingAssignment{{ID: 1, GraderID: 3, Status: models.GradingCompleted, Points: &six}}},
+		{"second mark missing", []models.GradingAssignment{
+			{ID: 1, GraderID: 3, Status: models.GradingCompleted, Points: &six},
+			{ID: 2, GraderID: 4, Status: models.GradingAssigned},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockRepo, _ := gradingFixture(t, models.AssessmentSettings{DoubleMarking: true})
+			mockRepo.On("FindAssignmentsByAnswer", uint(11)).Return(tt.assignments, nil)
+
+			_, err := service.ReconcileAnswer(1, 11, 2, models.AnswerGradeInput{Points: &six})
+
+			assert.ErrorIs(t, err, ErrNotReconcilable)
+			mockRepo.AssertNotCalled(t, "SaveGrade", mock.Anything)
+		})
+	}
+}
+
+func TestAttemptService_AssignGraders(t *testing.T) {
+	// Ba bài, bài 1 có hai câu tự luận
+	unassigned := []models.Answer{
+		{ID: 11, AttemptID: 1},
+		{ID: 12, AttemptID: 1},
+		{ID: 21, AttemptID: 2},
+		{ID: 31, AttemptID: 3},
+	}
+
+	tests := []struct {
+		name          string
+		doubleMarking bool
+		request       models.GraderAssignmentDTO
+		want          map[uint][]uint // answer ID -> graders
+	}{
+		{"round robin over every grader", false, models.GraderAssignmentDTO{},
+			map[uint][]uint{11: {2}, 12: {2}, 21: {3}, 31: {4}}},
+		{"round robin over named graders", false, models.GraderAssignmentDTO{Strategy: "round_robin", GraderIDs: []uint{4, 3}},
+			map[uint][]uint{11: {4}, 12: {4}, 21: {3}, 31: {4}}},
+		{"whole assessment to one grader", false, models.GraderAssignmentDTO{Strategy: "assessment", GraderIDs: []uint{3}},
+			map[uint][]uint{11: {3}, 12: {3}, 21: {3}, 31: {3}}},
+		{"double marking rotates pairs", true, models.GraderAssignmentDTO{},
+			map[uint][]uint{11: {2, 3}, 12: {2, 3}, 21: {4, 2}, 31: {3, 4}}},
+		{"double marking whole assessment", true, models.GraderAssignmentDTO{Strategy: "assessment"},
+			map[uint][]uint{11: {2, 3}, 12: {2, 3}, 21: {2, 3}, 31: {2, 3}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAttemptRepository)
+			mockAssessmentRepo := new(MockAssessmentRepository)
+			service := NewAttemptService(mockRepo, mockAssessmentRepo, new(MockQuestionRepository), nil, zaptest.NewLogger(t))
+
+			mockAssessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, Settings: models.AssessmentSettings{DoubleMarking: tt.doubleMarking}}, nil)
+			mockRepo.On("FindGraderIDs", uint(5)).Return([]uint{2, 3, 4}, nil)
+			mockRepo.On("FindUnassignedManualAnswers", uint(5)).Return(unassigned, nil)
+			mockRepo.On("CreateGradingAssignments", mock.Anything).Return(nil)
+
+			assignments, err := service.AssignGraders(5, tt.request)
+
+			require.NoError(t, err)
+			got := make(map[uint][]uint)
+			for _, a := range assignments {
+				assert.Equal(t, uint(5), a.AssessmentID)
+				assert.Equal(t, models.GradingAssigned, a.Status)
+				got[a.AnswerID] = append(got[a.AnswerID], a.GraderID)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAttemptService_AssignGraders_Invalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		doubleMarking bool
+		request       models.GraderAssignmentDTO
+		message       string
+	}{
+		{"grader without access", false, models.GraderAssignmentDTO{GraderIDs: []uint{2, 9}}, "user 9 cannot grade this assessment"},
+		{"grader named twice", false, models.GraderAssignmentDTO{GraderIDs: []uint{2, 2}}, "user 2 is named twice"},
+		{"one grader for double marking", true, models.GraderAssignmentDTO{GraderIDs: []uint{2}}, "needs at least two graders"},
+		{"unknown strategy", false, models.GraderAssignmentDTO{Strategy: "random"}, "strategy must be one of"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAttemptRepository)
+			mockAssessmentRepo := new(MockAssessmentRepository)
+			service := NewAttemptService(mockRepo, mockAssessmentRepo, new(MockQuestionRepository), nil, zaptest.NewLogger(t))
+
+			mockAssessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, Settings: models.AssessmentSettings{DoubleMarking: tt.doubleMarking}}, nil)
+			mockRepo.On("FindGraderIDs", uint(5)).Return([]uint{2, 3}, nil)
+
+			_, err := service.AssignGraders(5, tt.request)
+
+			assert.ErrorIs(t, err, ErrInvalidGraderAssignment)
+			assert.Contains(t, err.Error(), tt.message)
+			mockRepo.AssertNotCalled(t, "CreateGradingAssignments", mock.Anything)
+		})
+	}
+
+	t.Run("assessment not found", func(t *testing.T) {
+		mockAssessmentRepo := new(MockAssessmentRepository)
+		service := NewAttemptService(new(MockAttemptRepository), mockAssessmentRepo, new(MockQuestionRepository), nil, zaptest.NewLogger(t))
+		mockAssessmentRepo.On("FindByID", uint(5)).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := service.AssignGraders(5, models.GraderAssignmentDTO{})
+
+		assert.ErrorIs(t, err, ErrAssessmentNotFound)
+	})
+}
+
+func TestAttemptService_GetGraderAssignments_Blind(t *testing.T) {
+	mockRepo := new(MockAttemptRepository)
+	service := NewAttemptService(mockRepo, new(MockAssessmentRepository), new(MockQuestionRepository), nil, zaptest.NewLogger(t))
+
+	params := util.PaginationParams{Limit: 10}
+	mockRepo.On("FindAssignmentsByGrader", uint(3), params).Return([]map[string]interface{}{
+		{"id": uint(1), "attempt_id": uint(7), "user_id": uint(8), "user_name": "Alice", "blind_grading": true, "candidate_number": int64(2)},
+		{"id": uint(2), "attempt_id": uint(9), "user_id": uint(10), "user_name": "Bob", "blind_grading": int64(0), "candidate_number": int64(3)},
+	}, int64(2), nil)
+
+	assignments, total, err := service.GetGraderAssignments(3, params)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, map[string]interface{}{"id": uint(1), "attempt_id": uint(7), "candidate": "Candidate 2"}, assignments[0])
+	assert.Equal(t, map[string]interface{}{"id": uint(2), "attempt_id": uint(9), "user_id": uint(10), "user_name": "Bob"}, assignments[1])
+}
+
+func TestAttemptService_GetGradingProgress(t *testing.T) {
+	mockRepo := new(MockAttemptRepository)
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	service := NewAttemptService(mockRepo, mockAssessmentRepo, new(MockQuestionRepository), nil, zaptest.NewLogger(t))
+
+	progress := map[string]interface{}{"answersPending": int64(3)}
+	mockAssessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5}, nil)
+	mockRepo.On("GetGradingProgress", uint(5)).Return(progress, nil)
+
+	result, err := service.GetGradingProgress(5)
+
+	require.NoError(t, err)
+	assert.Equal(t, progress, result)
+}
+
+func TestAttemptService_VoidAttempt(t *testing.T) {
+	mockRepo := new(MockAttemptRepository)
+	service := NewAttemptService(mockRepo, new(MockAssessmentRepository), new(MockQuestionRepository), nil, zaptest.NewLogger(t))
+
+	mockRepo.On("FindByID", uint(1)).Return(&models.Attempt{ID: 1, Status: models.AttemptGraded}, nil)
+	mockRepo.On("Update", mock.MatchedBy(func(a *models.Attempt) bool {
+		return a.Status == models.AttemptVoided
+	})).Return(nil)
+
+	assert.NoError(t, service.VoidAttempt(1))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAttemptService_VoidAttempt_AlreadyVoided(t *testing.T) {
+	mockRepo := new(MockAttemptRepository)
+	service := NewAttemptService(mockRepo, new(MockAssessmentRepository), new(MockQuestionRepository), nil, zaptest.NewLogger(t))
+
+	mockRepo.On("FindByID", uint(1)).Return(&models.Attempt{ID: 1, Status: models.AttemptVoided}, nil)
+
+	err := service.VoidAttempt(1)
+
+	assert.ErrorIs(t, err, models.ErrInvalidAttemptTransition)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestAttemptService_PauseAndResumeAttempt(t *testing.T) {
+	mockRepo := new(MockAttemptRepository)
+	service := NewAttemptService(mockRepo, new(MockAssessmentRepository), new(MockQuestionRepository), nil, zaptest.NewLogger(t))
+
+	attempt := &models.Attempt{ID: 1, Status: models.AttemptInProgress, PausedSeconds: 30}
+	mockRepo.On("FindByID", uint(1)).Return(attempt, nil)
+	mockRepo.On("SaveTimeEvent", attempt, mock.AnythingOfType("*models.AttemptTimeEvent")).Return(nil)
+
+	paused, err := service.PauseAttempt(1, 3, "Fire alarm")
+	require.NoError(t, err)
+	assert.True(t, paused.IsPaused())
+
+	// Tạm dừng hai lần là lỗi
+	_, err = service.PauseAttempt(1, 3, "")
+	assert.ErrorIs(t, err, models.ErrInvalidTimeEvent)
+
+	// Thời gian tạm dừng được cộng dồn khi tiếp tục
+	*attempt.PausedAt = attempt.PausedAt.Add(-2 * time.Minute)
+	resumed, err := service.ResumeAttempt(1, 3, "All clear")
+	require.NoError(t, err)
+	assert.False(t, resumed.IsPaused())
+	assert.InDelta(t, 150, resumed.PausedSeconds, 1)
+
+	mockRepo.AssertNumberOfCalls(t, "SaveTimeEvent", 2)
+	pause := mockRepo.Calls[1].Arguments.Get(1).(*models.AttemptTimeEvent)
+	assert.Equal(t, models.AttemptTimeEvent{AttemptID: 1, Kind: models.TimeEventPause, ActorID: 3, Reason: "Fire alarm"}, *pause)
+	resume := mockRepo.Calls[4].Arguments.Get(1).(*models.AttemptTimeEvent)
+	assert.Equal(t, models.TimeEventResume, resume.Kind)
+	assert.InDelta(t, 120, resume.Seconds, 1)
+}
+
+func TestAttemptService_ExtendAttempt(t *testing.T) {
+	mockRepo := new(MockAttemptRepository)
+	mockHub := new(MockPublisher)
+	service := NewAttemptService(mockRepo, new(MockAssessmentRepository), new(MockQuestionRepository), mockHub, zaptest.NewLogger(t))
+
+	mockRepo.On("FindByID", uint(1)).Return(&models.Attempt{ID: 1, Status: models.AttemptInProgress, ExtraSeconds: 60}, nil)
+	mockRepo.On("SaveTimeEvent", mock.MatchedBy(func(a *models.Attempt) bool {
+		return a.ExtraSeconds == 660
+	}), mock.MatchedBy(func(e *models.AttemptTimeEvent) bool {
+		return e.Kind == models.TimeEventExtend && e.Seconds == 600 && e.ActorID == 3 && e.Reason == "Network outage"
+	})).Return(nil)
+	// Kênh trực tiếp của học sinh được yêu cầu gửi lại đồng hồ
+	mockHub.On("Publish", mock.MatchedBy(func(event models.LiveEvent) bool {
+		return event.AttemptID == 1 && event.Type == models.LiveEventTime && event.Data == nil
+	})).Return(nil)
+
+	attempt, err := service.ExtendAttempt(1, 3, 10, "Network outage")
+
+	require.NoError(t, err)
+	assert.Equal(t, 660, attempt.ExtraSeconds)
+	mockRepo.AssertExpectations(t)
+	mockHub.AssertExpectations(t)
+}
+
+func TestAttemptService_ChangeTime_Errors(t *testing.T) {
+	mockRepo := new(MockAttemptRepository)
+	service := NewAttemptService(mockRepo, new(MockAssessmentRepository), new(MockQuestionRepository), nil, zaptest.NewLogger(t))
+
+	mockRepo.On("FindByID", uint(1)).Return(&models.Attempt{ID: 1, Status: models.AttemptGraded}, nil)
+	mockRepo.On("FindByID", uint(2)).Return(&models.Attempt{ID: 2, Status: models.AttemptInProgress}, nil)
+
+	_, err := service.PauseAttempt(1, 3, "")
+	assert.ErrorIs(t, err, ErrAttemptNotLive)
+
+	_, err = service.ResumeAttempt(2, 3, "")
+	assert.ErrorIs(t, err, models.ErrInvalidTimeEvent)
+
+	_, err = service.ExtendAttempt(2, 3, 0, "")
+	assert.ErrorIs(t, err, ErrInvalidExtension)
+
+	mockRepo.AssertNotCalled(t, "SaveTimeEvent", mock.Anything, mock.Anything)
+}
+
+func TestAttemptService_SendMessage(t *testing.T) {
+	mockRepo := new(MockAttemptRepository)
+	mockHub := new(MockPublisher)
+	service := NewAttemptService(mockRepo, new(MockAssessmentRepository), new(MockQuestionRepository), mockHub, zaptest.NewLogger(t))
+
+	mockRepo.On("FindByID", uint(1)).Return(&models.Attempt{ID: 1, Status: models.AttemptInProgress}, nil)
+	mockRepo.On("FindByID", uint(2)).Return(&models.Attempt{ID: 2, Status: models.AttemptSubmitted}, nil)
+	mockRepo.On("FindByID", uint(9)).Return(nil, gorm.ErrRecordNotFound)
+	mockHub.On("Publish", mock.MatchedBy(func(event models.LiveEvent) bool {
+		data := event.Data.(map[string]interface{})
+		return event.AttemptID == 1 && event.Type == models.LiveEventMessage &&
+			data["message"] == "Eyes on your screen" && data["severity"] == models.ProctorSeverityWarning && data["from"] == uint(3)
+	})).Return(nil).Once()
+	mockHub.On("Publish", mock.MatchedBy(func(event models.LiveEvent) bool {
+		return event.Type == models.LiveEventMessage && event.Data.(map[string]interface{})["severity"] == models.ProctorSeverityInfo
+	})).Return(nil).Once()
+
+	require.NoError(t, service.SendMessage(1, 3, models.ProctorMessageDTO{Message: " Eyes on your screen ", Severity: "warning"}))
+	// Mức độ mặc định là INFO
+	require.NoError(t, service.SendMessage(1, 3, models.ProctorMessageDTO{Message: "Ten minutes left"}))
+
+	assert.ErrorIs(t, service.SendMessage(1, 3, models.ProctorMessageDTO{Message: "  "}), ErrInvalidMessage)
+	assert.ErrorIs(t, service.SendMessage(1, 3, models.ProctorMessageDTO{Message: "Hi", Severity: "LOUD"}), ErrInvalidMessage)
+	assert.ErrorIs(t, service.SendMessage(2, 3, models.ProctorMessageDTO{Message: "Hi"}), ErrAttemptNotLive)
+	assert.ErrorIs(t, service.SendMessage(9, 3, models.ProctorMessageDTO{Message: "Hi"}), ErrAttemptNotFound)
+
+	mockHub.AssertExpectations(t)
+	mockHub.AssertNumberOfCalls(t, "Publish", 2)
+}
+
+func TestAttemptService_GetProctoringDashboard(t *testing.T) {
+	mockRepo := new(MockAttemptRepository)
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	service := NewAttemptService(mockRepo, mockAssessmentRepo, mockQuestionRepo, nil, zaptest.NewLogger(t))
+
+	heartbeat := time.Now().Add(-15 * time.Second)
+	pausedAt := time.Now()
+	attempts := []models.Attempt{
+		{
+			ID: 1, UserID: 7, AssessmentID: 5, Status: models.AttemptInProgress, HeartbeatAt: &heartbeat,
+			User:    models.User{ID: 7, Name: "Student A", Email: "a@test.com"},
+			Answers: []models.Answer{{QuestionID: 10}, {QuestionID: 11}},
+		},
+		{
+			// Bài làm chỉ được phát hai câu và một câu trả lời không thuộc số đó
+			ID: 2, UserID: 8, AssessmentID: 5, Status: models.AttemptInProgress, PausedAt: &pausedAt,
+			User:      models.User{ID: 8, Name: "Student B", Email: "b@test.com"},
+			Selection: []models.AttemptQuestion{{QuestionID: 10, Position: 0}, {QuestionID: 12, Position: 1}},
+			Answers:   []models.Answer{{QuestionID: 10}, {QuestionID: 11}},
+		},
+	}
+	var activities []models.SuspiciousActivity
+	for i := 0; i < 7; i++ {
+		activities = append(activities, models.SuspiciousActivity{ID: uint(20 - i), AttemptID: 1, Type: "TAB_SWITCH"})
+	}
+
+	mockAssessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5}, nil)
+	mockAssessmentRepo.On("FindByID", uint(9)).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("FindLiveAttempts", uint(5)).Return(attempts, nil)
+	mockQuestionRepo.On("FindByAssessmentID", uint(5)).Return([]models.Question{{ID: 10}, {ID: 11}, {ID: 12}}, nil)
+	mockRepo.On("FindSuspiciousActivitiesByAttempts", []uint{1, 2}).Return(activities, nil)
+
+	dashboard, err := service.GetProctoringDashboard(5)
+
+	require.NoError(t, err)
+	require.Len(t, dashboard, 2)
+	assert.Equal(t, "Student A", dashboard[0].Name)
+	assert.Equal(t, &heartbeat, dashboard[0].LastHeartbeatAt)
+	assert.Equal(t, 2, dashboard[0].Answered)
+	assert.Equal(t, 3, dashboard[0].Total)
+	// Chỉ giữ các hoạt động đáng ngờ gần nhất
+	require.Len(t, dashboard[0].RecentActivity, recentActivityLimit)
+	assert.Equal(t, uint(20), dashboard[0].RecentActivity[0].ID)
+	assert.Equal(t, 1, dashboard[1].Answered)
+	assert.Equal(t, 2, dashboard[1].Total)
+	assert.True(t, dashboard[1].Paused)
+	assert.NotNil(t, dashboard[1].RecentActivity)
+	assert.Empty(t, dashboard[1].RecentActivity)
+
+	_, err = service.GetProctoringDashboard(9)
+	assert.ErrorIs(t, err, ErrAssessmentNotFound)
+}
+
+func TestAttemptService_FlagAttempt(t *testing.T) {
+	mockRepo := new(MockAttemptRepository)
+	mockHub := new(MockPublisher)
+	service := NewAttemptService(mockRepo, new(MockAssessmentRepository), new(MockQuestionRepository), mockHub, zaptest.NewLogger(t))
+
+	mockRepo.On("FindByID", uint(1)).Return(&models.Attempt{ID: 1, AssessmentID: 5, Status: models.AttemptInProgress}, nil)
+	mockRepo.On("FindByID", uint(9)).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("SaveProctorAction", mock.MatchedBy(func(a *models.Attempt) bool {
+		return a.Status == models.AttemptInProgress && a.FlaggedAt != nil && *a.FlaggedBy == 3 && a.FlagReason == "Second screen"
+	})).Return(nil).Once()
+	mockHub.On("Publish", mock.MatchedBy(func(event models.LiveEvent) bool {
+		data := event.Data.(map[string]interface{})
+		return event.AttemptID == 1 && event.AssessmentID == 5 && event.Type == models.LiveEventFlagged &&
+			data["reason"] == "Second screen" && data["by"] == uint(3)
+	})).Return(nil).Once()
+
+	attempt, err := service.FlagAttempt(1, 3, " Second screen ")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Second screen", attempt.FlagReason)
+	_, err = service.FlagAttempt(9, 3, "")
+	assert.ErrorIs(t, err, ErrAttemptNotFound)
+	mockRepo.AssertExpectations(t)
+	mockHub.AssertExpectations(t)
+}
+
+func TestAttemptService_TerminateAttempt(t *testing.T) {
+	mockRepo := new(MockAttemptRepository)
+	mockHub := new(MockPublisher)
+	service := NewAttemptService(mockRepo, new(MockAssessmentRepository), new(MockQuestionRepository), mockHub, zaptest.NewLogger(t))
+
+	mockRepo.On("FindByID", uint(1)).Return(&models.Attempt{ID: 1, AssessmentID: 5, Status: models.AttemptInProgress}, nil)
+	mockRepo.On("FindByID", uint(2)).Return(&models.Attempt{ID: 2, AssessmentID: 5, Status: models.AttemptSubmitted}, nil)
+	mockRepo.On("SaveProctorAction", mock.MatchedBy(func(a *models.Attempt) bool {
+		return a.Status == models.AttemptVoided && a.EndedAt != nil && *a.FlaggedBy == 3 && a.FlagReason == "Phone in hand"
+	})).Return(nil).Once()
+	mockHub.On("Publish", mock.MatchedBy(func(event models.LiveEvent) bool {
+		return event.AttemptID == 1 && event.AssessmentID == 5 && event.Type == models.LiveEventTerminated &&
+			event.Data.(map[string]interface{})["reason"] == "Phone in hand"
+	})).Return(nil).Once()
+
+	attempt, err := service.TerminateAttempt(1, 3, "Phone in hand")
+
+	require.NoError(t, err)
+	assert.Equal(t, models.AttemptVoided, attempt.Status)
+
+	// Phải có lý do, và chỉ bài đang làm mới bị đình chỉ
+	_, err = service.TerminateAttempt(1, 3, "  ")
+	assert.ErrorIs(t, err, ErrInvalidProctorAction)
+	_, err = service.TerminateAttempt(2, 3, "Phone in hand")
+	assert.ErrorIs(t, err, ErrAttemptNotLive)
+
+	mockRepo.AssertExpectations(t)
+	mockHub.AssertExpectations(t)
+}
+
+func TestAttemptService_AcceptRegradeRequest(t *testing.T) {
+	mockRepo := new(MockAttemptRepository)
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	service := NewAttemptService(mockRepo, mockAssessmentRepo, new(MockQuestionRepository), nil, zaptest.NewLogger(t))
+
+	score := 60.0
+	mockRepo.On("FindRegradeRequestByID", uint(4)).Return(&models.RegradeRequest{ID: 4, AttemptID: 1, Status: models.RegradePending}, nil)
+	mockRepo.On("FindByID", uint(1)).Return(&models.Attempt{ID: 1, AssessmentID: 5, Status: models.AttemptGraded, Score: &score, Feedback: "Well done"}, nil)
+	mockAssessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, PassingScore: 70}, nil)
+	// Điểm mới, lịch sử điểm và yêu cầu đã đóng được lưu cùng nhau
+	mockRepo.On("SaveGrade", mock.MatchedBy(func(change models.GradeChange) bool {
+		a, r, events := change.Attempt, change.Request, change.Events
+		// Nhận xét cũ được giữ lại
+		attemptMatch := *a.Score == 72 && *a.Passed && a.Feedback == "Well done"
+		requestMatch := r != nil && r.Status == models.RegradeAccepted && *r.ReviewedByID == 3 && r.ReviewedAt != nil
+		return attemptMatch && requestMatch &&
+			len(events) == 1 && events[0].Kind == models.GradeEventRegrade && *events[0].ActorID == 3 &&
+			*events[0].OldValue == 60 && *events[0].NewValue == 72 && events[0].Reason == "Regrade request #4: Question 3 was marked wrong"
+	})).Return(nil)
+
+	request, err := service.AcceptRegradeRequest(1, 4, 3, models.RegradeDecisionDTO{Score: floatPtr(72), Response: "Question 3 was marked wrong"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Question 3 was marked wrong", request.Response)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "UpdateRegradeRequest", mock.Anything)
+}
+
+func TestAttemptService_RegradeRequest_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  *models.RegradeRequest
+		findErr  error
+		decision models.RegradeDecisionDTO
+		accept   bool
+		wantErr  error
+	}{
+		{"accept without a grade", &models.RegradeRequest{ID: 4, AttemptID: 1, Status: models.RegradePending}, nil, models.RegradeDecisionDTO{Response: "Fine"}, true, ErrInvalidRegradeDecision},
+		{"reject without a response", &models.RegradeRequest{ID: 4, AttemptID: 1, Status: models.RegradePending}, nil, models.RegradeDecisionDTO{}, false, ErrInvalidRegradeDecision},
+		{"request not found", nil, gorm.ErrRecordNotFound, models.RegradeDecisionDTO{Response: "No"}, false, ErrRegradeRequestNotFound},
+		{"request of another attempt", &models.RegradeRequest{ID: 4, AttemptID: 2, Status: models.RegradePending}, nil, models.RegradeDecisionDTO{Response: "No"}, false, ErrRegradeRequestNotFound},
+		{"already reviewed", &models.RegradeRequest{ID: 4, AttemptID: 1, Status: models.RegradeRejected}, nil, models.RegradeDecisionDTO{Score: floatPtr(80)}, true, ErrRegradeNotPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAttemptRepository)
+			service := NewAttemptService(mockRepo, new(MockAssessmentRepository), new(MockQuestionRepository), nil, zaptest.NewLogger(t))
+			mockRepo.On("FindRegradeRequestByID", uint(4)).Return(tt.request, tt.findErr)
+
+			var err error
+			if tt.accept {
+				_, err = service.AcceptRegradeRequest(1, 4, 3, tt.decision)
+			} else {
+				_, err = service.RejectRegradeRequest(1, 4, 3, tt.decision.Response)
+			}
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			mockRepo.AssertNotCalled(t, "UpdateRegradeRequest", mock.Anything)
+			mockRepo.AssertNotCalled(t, "SaveGrade", mock.Anything)
+		})
+	}
+}
+
+func TestAttemptService_RejectRegradeRequest(t *testing.T) {
+	mockRepo := new(MockAttemptRepository)
+	service := NewAttemptService(mockRepo, new(MockAssessmentRepository), new(MockQuestionRepository), nil, zaptest.NewLogger(t))
+
+	mockRepo.On("FindRegradeRequestByID", uint(4)).Return(&models.RegradeRequest{ID: 4, AttemptID: 1, Status: models.RegradePending}, nil)
+	mockRepo.On("UpdateRegradeRequest", mock.Anything).Return(nil)
+
+	request, err := service.RejectRegradeRequest(1, 4, 3, "The rubric was applied correctly")
+
+	require.NoError(t, err)
+	assert.Equal(t, models.RegradeRejected, request.Status)
+	assert.Equal(t, "The rubric was applied correctly", request.Response)
+	// Từ chối không làm thay đổi điểm
+	mockRepo.AssertNotCalled(t, "SaveGrade", mock.Anything)
+}
+
+func TestAttemptService_GetGradeEvents(t *testing.T) {
+	mockRepo := new(MockAttemptRepository)
+	service := NewAttemptService(mockRepo, new(MockAssessmentRepository), new(MockQuestionRepository), nil, zaptest.NewLogger(t))
+
+	events := []models.GradeEvent{{ID: 1, AttemptID: 1, Kind: models.GradeEventAuto, NewValue: floatPtr(40)}}
+	mockRepo.On("FindByID", uint(1)).Return(&models.Attempt{ID: 1}, nil)
+	mockRepo.On("FindGradeEventsByAttempt", uint(1)).Return(events, nil)
+
+	result, err := service.GetGradeEvents(1)
+
+	require.NoError(t, err)
+	assert.Equal(t, events, result)
+
+	mockRepo.On("FindByID", uint(9)).Return(nil, gorm.ErrRecordNotFound)
+	_, err = service.GetGradeEvents(9)
+	assert.ErrorIs(t, err, ErrAttemptNotFound)
+}
+
+func TestAttemptService_RescoreAssessment(t *testing.T) {
+	mockRepo := new(MockAttemptRepository)
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	service := NewAttemptService(mockRepo, mockAssessmentRepo, mockQuestionRepo, nil, zaptest.NewLogger(t))
+
+	yes, no := true, false
+	// Đáp án đúng của câu 101 đổi từ "a" sang "b"
+	questions := []models.Question{
+		{ID: 101, Type: "multiple-choice", CorrectAnswer: "b", Points: 10,
+			Options: []models.QuestionOption{{OptionID: "a"}, {OptionID: "b"}, {OptionID: "c"}}},
+		{ID: 102, Type: "essay", Points: 10},
+	}
+	attempts := []models.Attempt{
+		{ID: 1, UserID: 7, Status: models.AttemptGraded, Score: floatPtr(90), Passed: &yes, Answers: []models.Answer{
+			{ID: 10, QuestionID: 101, Answer: "a", IsCorrect: &yes, AwardedPoints: floatPtr(10)},
+			{ID: 11, QuestionID: 102, Answer: "Essay", AwardedPoints: floatPtr(8)},
+		}},
+		// Nộp muộn bị trừ 20%
+		{ID: 2, UserID: 8, Status: models.AttemptGraded, LatePenalty: 20, Score: floatPtr(20), Passed: &no, Answers: []models.Answer{
+			{ID: 20, QuestionID: 101, Answer: "b", IsCorrect: &no, AwardedPoints: floatPtr(0)},
+			{ID: 21, QuestionID: 102, Answer: "Essay", AwardedPoints: floatPtr(5)},
+		}},
+		// Chưa chấm xong thì chưa có kết quả đạt hay không
+		{ID: 3, UserID: 9, Status: models.AttemptPendingManualGrading, Score: floatPtr(0), Answers: []models.Answer{
+			{ID: 30, QuestionID: 101, Answer: "b", IsCorrect: &no, AwardedPoints: floatPtr(0)},
+			{ID: 31, QuestionID: 102, Answer: "Essay"},
+		}},
+		// Vẫn sai, không thay đổi
+		{ID: 4, UserID: 10, Status: models.AttemptGraded, Score: floatPtr(50), Passed: &no, Answers: []models.Answer{
+			{ID: 40, QuestionID: 101, Answer: "c", IsCorrect: &no, AwardedPoints: floatPtr(0)},
+			{ID: 41, QuestionID: 102, Answer: "Essay", AwardedPoints: floatPtr(10)},
+		}},
+	}
+
+	mockAssessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, PassingScore: 70}, nil)
+	mockQuestionRepo.On("FindByAssessmentID", uint(5)).Return(questions, nil)
+	mockRepo.On("FindScoredAttempts", uint(5)).Return(attempts, nil)
+	mockRepo.On("SaveRescore",
+		mock.MatchedBy(func(changed []*models.Attempt) bool {
+			return len(changed) == 3 &&
+				*changed[0].Score == 40 && !*changed[0].Passed &&
+				*changed[1].Score == 60 && !*changed[1].Passed &&
+				*changed[2].Score == 50 && changed[2].Passed == nil
+		}),
+		mock.MatchedBy(func(answers []*models.Answer) bool {
+			return len(answers) == 3 &&
+				answers[0].ID == 10 && !*answers[0].IsCorrect && *answers[0].AwardedPoints == 0 &&
+				answers[1].ID == 20 && *answers[1].IsCorrect && *answers[1].AwardedPoints == 10 &&
+				answers[2].ID == 30 && *answers[2].AwardedPoints == 10
+		}),
+		mock.MatchedBy(func(events []models.GradeEvent) bool {
+			for _, event := range events {
+				if event.Kind != models.GradeEventRegrade || *event.ActorID != 3 || event.Reason != "Rescored after question #101 changed" {
+					return false
+				}
+			}
+			return len(events) == 6
+		}),
+	).Return(nil)
+
+	summary, err := service.RescoreAssessment(5, 3, []uint{101})
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, summary.AttemptsChecked)
+	assert.Equal(t, 3, summary.AttemptsChanged)
+	assert.Equal(t, 3, summary.AnswersChanged)
+	require.Len(t, summary.Changes, 3)
+	assert.Equal(t, models.RescoreChange{
+		AttemptID: 1, UserID: 7, OldScore: floatPtr(90), NewScore: floatPtr(40), OldPassed: &yes, NewPassed: &no, AnswersChanged: 1,
+	}, summary.Changes[0])
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAttemptService_RescoreAssessment_Errors(t *testing.T) {
+	mockRepo := new(MockAttemptRepository)
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	service := NewAttemptService(mockRepo, mockAssessmentRepo, mockQuestionRepo, nil, zaptest.NewLogger(t))
+
+	mockAssessmentRepo.On("FindByID", uint(9)).Return(nil, gorm.ErrRecordNotFound)
+	_, err := service.RescoreAssessment(9, 3, nil)
+	assert.ErrorIs(t, err, ErrAssessmentNotFound)
+
+	mockAssessmentRepo.On("FindByID", uint(5)).Return(&models.Assessment{ID: 5, PassingScore: 70}, nil)
+	mockQuestionRepo.On("FindByAssessmentID", uint(5)).Return([]models.Question{{ID: 101, Type: "true-false", CorrectAnswer: "true", Points: 10}}, nil)
+	_, err = service.RescoreAssessment(5, 3, []uint{999})
+	assert.ErrorIs(t, err, ErrInvalidRescore)
+
+	// Không có gì thay đổi thì không lưu
+	yes := true
+	mockRepo.On("FindScoredAttempts", uint(5)).Return([]models.Attempt{
+		{ID: 1, Status: models.AttemptGraded, Score: floatPtr(100), Passed: &yes, Answers: []models.Answer{
+			{ID: 10, QuestionID: 101, Answer: "true", IsCorrect: &yes, AwardedPoints: floatPtr(10)},
+		}},
+	}, nil)
+	summary, err := service.RescoreAssessment(5, 3, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.AttemptsChecked)
+	assert.Zero(t, summary.AttemptsChanged)
+	assert.Empty(t, summary.Changes)
+	mockRepo.AssertNotCalled(t, "SaveRescore", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
 }
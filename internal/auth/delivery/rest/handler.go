@@ -0,0 +1,264 @@
+package rest
+
+import (
+	"assessment_service/internal/auth/service"
+	models "assessment_service/internal/model"
+	"assessment_service/internal/util"
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+type AuthHandler struct {
+	authService service.AuthService
+	log         *zap.Logger
+}
+
+func NewAuthHandler(authService service.AuthService, log *zap.Logger) *AuthHandler {
+	return &AuthHandler{authService: authService, log: log}
+}
+
+func userSummary(user *models.User) map[string]interface{} {
+	return map[string]interface{}{
+		"id":    user.ID,
+		"name":  user.Name,
+		"email": user.Email,
+		"role":  user.Role,
+	}
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, message string) {
+	util.ResponseMap(w, map[string]interface{}{
+		"status":    "UNAUTHORIZED",
+		"timestamp": time.Now(),
+		"message":   message,
+		"path":      r.URL.Path,
+	}, http.StatusUnauthorized)
+}
+
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email    string `json:"email" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" || req.Password == "" {
+		h.log.Error("[Login] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	token, refreshToken, user, err := h.authService.Login(req.Email, req.Password)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCredentials) || errors.Is(err, service.ErrInactiveAccount) {
+			unauthorized(w, r, err.Error())
+			return
+		}
+		h.log.Error("[Login] failed to login", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "ERROR",
+			"message": "Failed to login",
+		}, http.StatusInternalServerError)
+		return
+	}
+
+	util.ResponseMap(w, map[string]interface{}{
+		"token":        token,
+		"refreshToken": refreshToken,
+		"user":         userSummary(user),
+	}, http.StatusOK)
+}
+
+func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name     string `json:"name" binding:"required"`
+		Email    string `json:"email" binding:"required"`
+		Password string `json:"password" binding:"required"`
+		Role     string `json:"role" binding:"required"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" || req.Email == "" || req.Password == "" || req.Role == "" {
+		h.log.Error("[Register] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	user := &models.User{
+		Name:  req.Name,
+		Email: req.Email,
+		Role:  req.Role,
+	}
+
+	err := h.authService.Register(user, req.Password)
+	if err != nil {
+		if errors.Is(err, service.ErrEmailAlreadyExists) ||
+			errors.Is(err, service.ErrInvalidRole) ||
+			errors.Is(err, util.ErrPasswordTooShort) {
+			util.ResponseMap(w, map[string]interface{}{
+				"status":  "BAD_REQUEST",
+				"message": err.Error(),
+			}, http.StatusBadRequest)
+			return
+		}
+		h.log.Error("[Register] failed to register user", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "ERROR",
+			"message": "Failed to register user",
+		}, http.StatusInternalServerError)
+		return
+	}
+
+	util.ResponseMap(w, map[string]interface{}{
+		"message": "User registered successfully",
+		"user":    userSummary(user),
+	}, http.StatusCreated)
+}
+
+func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RefreshToken string `json:"refreshToken" binding:"required"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		h.log.Error("[RefreshToken] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	token, refreshToken, err := h.authService.RefreshToken(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) || errors.Is(err, service.ErrInactiveAccount) {
+			unauthorized(w, r, err.Error())
+			return
+		}
+		h.log.Error("[RefreshToken] failed to refresh token", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "ERROR",
+			"message": "Failed to refresh token",
+		}, http.StatusInternalServerError)
+		return
+	}
+
+	util.ResponseMap(w, map[string]interface{}{
+		"token":        token,
+		"refreshToken": refreshToken,
+	}, http.StatusOK)
+}
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RefreshToken string `json:"refreshToken" binding:"required"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		h.log.Error("[Logout] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	err := h.authService.Logout(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) {
+			unauthorized(w, r, err.Error())
+			return
+		}
+		h.log.Error("[Logout] failed to logout", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "ERROR",
+			"message": "Failed to logout",
+		}, http.StatusInternalServerError)
+		return
+	}
+
+	util.ResponseMap(w, map[string]interface{}{
+		"message": "Logged out successfully",
+	}, http.StatusOK)
+}
+
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email string `json:"email" binding:"required"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		h.log.Error("[ForgotPassword] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.authService.ForgotPassword(req.Email); err != nil {
+		h.log.Error("[ForgotPassword] failed to send reset email", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "ERROR",
+			"message": "Failed to process password reset request",
+		}, http.StatusInternalServerError)
+		return
+	}
+
+	// Same answer whether or not the email exists
+	util.ResponseMap(w, map[string]interface{}{
+		"message": "If the email is registered, a password reset link has been sent",
+	}, http.StatusOK)
+}
+
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" || req.Password == "" {
+		h.log.Error("[ResetPassword] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	err := h.authService.ResetPassword(req.Token, req.Password)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidResetToken) || errors.Is(err, util.ErrPasswordTooShort) {
+			util.ResponseMap(w, map[string]interface{}{
+				"status":  "BAD_REQUEST",
+				"message": err.Error(),
+			}, http.StatusBadRequest)
+			return
+		}
+		h.log.Error("[ResetPassword] failed to reset password", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "ERROR",
+			"message": "Failed to reset password",
+		}, http.StatusInternalServerError)
+		return
+	}
+
+	util.ResponseMap(w, map[string]interface{}{
+		"message": "Password has been reset successfully",
+	}, http.StatusOK)
+}
+
+// JWKS serves the public keys used to verify access tokens so other services can validate them
+func JWKS(keySet util.KeySet) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		util.ResponseInterface(w, keySet.JWKS(), http.StatusOK)
+	}
+}
@@ -0,0 +1,218 @@
+package rest
+
+import (
+	"assessment_service/internal/auth/service"
+	models "assessment_service/internal/model"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// --- Mock AuthService ---
+type MockAuthService struct {
+	mock.Mock
+}
+
+func (m *MockAuthService) Login(email, password string) (string, string, *models.User, error) {
+	args := m.Called(email, password)
+	user, _ := args.Get(2).(*models.User)
+	return args.String(0), args.String(1), user, args.Error(3)
+}
+
+func (m *MockAuthService) Register(user *models.User, password string) error {
+	args := m.Called(user, password)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) RefreshToken(refreshToken string) (string, string, error) {
+	args := m.Called(refreshToken)
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *MockAuthService) Logout(refreshToken string) error {
+	args := m.Called(refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ForgotPassword(email string) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ResetPassword(token, newPassword string) error {
+	args := m.Called(token, newPassword)
+	return args.Error(0)
+}
+
+func postJSON(handler http.HandlerFunc, url string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	handler(rr, req)
+	return rr
+}
+
+func TestAuthHandler_Login(t *testing.T) {
+	mockService := new(MockAuthService)
+	handler := NewAuthHandler(mockService, zaptest.NewLogger(t))
+
+	user := &models.User{ID: 1, Name: "John", Email: "john@example.com", Role: "student"}
+	mockService.On("Login", "john@example.com", "password123").Return("access", "refresh", user, nil)
+
+	rr := postJSON(handler.Login, "/auth/login", `{"email":"john@example.com","password":"password123"}`)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, "access", body["token"])
+	assert.Equal(t, "refresh", body["refreshToken"])
+	assert.Equal(t, "john@example.com", body["user"].(map[string]interface{})["email"])
+	mockService.AssertExpectations(t)
+}
+
+func TestAuthHandler_Login_InvalidCredentials(t *testing.T) {
+	mockService := new(MockAuthService)
+	handler := NewAuthHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("Login", "john@example.com", "bad").Return("", "", nil, service.ErrInvalidCredentials)
+
+	rr := postJSON(handler.Login, "/auth/login", `{"email":"john@example.com","password":"bad"}`)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestAuthHandler_Login_BadRequest(t *testing.T) {
+	mockService := new(MockAuthService)
+	handler := NewAuthHandler(mockService, zaptest.NewLogger(t))
+
+	rr := postJSON(handler.Login, "/auth/login", `{"email":""}`)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertNotCalled(t, "Login", mock.Anything, mock.Anything)
+}
+
+func TestAuthHandler_Register(t *testing.T) {
+	mockService := new(MockAuthService)
+	handler := NewAuthHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("Register", mock.MatchedBy(func(u *models.User) bool {
+		return u.Email == "new@example.com" && u.Role == "Student"
+	}), "password123").Return(nil)
+
+	rr := postJSON(handler.Register, "/auth/register", `{"name":"New","email":"new@example.com","password":"password123","role":"Student"}`)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAuthHandler_Register_EmailTaken(t *testing.T) {
+	mockService := new(MockAuthService)
+	handler := NewAuthHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("Register", mock.Anything, "password123").Return(service.ErrEmailAlreadyExists)
+
+	rr := postJSON(handler.Register, "/auth/register", `{"name":"New","email":"new@example.com","password":"password123","role":"Student"}`)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestAuthHandler_RefreshToken(t *testing.T) {
+	mockService := new(MockAuthService)
+	handler := NewAuthHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("RefreshToken", "old").Return("access", "new", nil)
+
+	rr := postJSON(handler.RefreshToken, "/auth/refresh-token", `{"refreshToken":"old"}`)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, "new", body["refreshToken"])
+}
+
+func TestAuthHandler_RefreshToken_Invalid(t *testing.T) {
+	mockService := new(MockAuthService)
+	handler := NewAuthHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("RefreshToken", "old").Return("", "", service.ErrInvalidRefreshToken)
+
+	rr := postJSON(handler.RefreshToken, "/auth/refresh-token", `{"refreshToken":"old"}`)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestAuthHandler_Logout(t *testing.T) {
+	mockService := new(MockAuthService)
+	handler := NewAuthHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("Logout", "token").Return(nil)
+
+	rr := postJSON(handler.Logout, "/auth/logout", `{"refreshToken":"token"}`)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAuthHandler_Logout_ServiceError(t *testing.T) {
+	mockService := new(MockAuthService)
+	handler := NewAuthHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("Logout", "token").Return(errors.New("db error"))
+
+	rr := postJSON(handler.Logout, "/auth/logout", `{"refreshToken":"token"}`)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+}
+
+func TestAuthHandler_ForgotPassword(t *testing.T) {
+	mockService := new(MockAuthService)
+	handler := NewAuthHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("ForgotPassword", "john@example.com").Return(nil)
+
+	rr := postJSON(handler.ForgotPassword, "/auth/forgot-password", `{"email":"john@example.com"}`)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAuthHandler_ForgotPassword_BadRequest(t *testing.T) {
+	mockService := new(MockAuthService)
+	handler := NewAuthHandler(mockService, zaptest.NewLogger(t))
+
+	rr := postJSON(handler.ForgotPassword, "/auth/forgot-password", `{}`)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertNotCalled(t, "ForgotPassword", mock.Anything)
+}
+
+func TestAuthHandler_ResetPassword(t *testing.T) {
+	mockService := new(MockAuthService)
+	handler := NewAuthHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("ResetPassword", "token", "new-password").Return(nil)
+
+	rr := postJSON(handler.ResetPassword, "/auth/reset-password", `{"token":"token","password":"new-password"}`)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAuthHandler_ResetPassword_InvalidToken(t *testing.T) {
+	mockService := new(MockAuthService)
+	handler := NewAuthHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("ResetPassword", "token", "new-password").Return(service.ErrInvalidResetToken)
+
+	rr := postJSON(handler.ResetPassword, "/auth/reset-password", `{"token":"token","password":"new-password"}`)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	models "assessment_service/internal/model"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PasswordResetRepository manages the single-use tokens sent by the forgot password flow
+type PasswordResetRepository interface {
+	Create(token *models.PasswordResetToken) error
+	FindByToken(tokenHash string) (*models.PasswordResetToken, error)
+	MarkUsed(id uint) (bool, error)
+	InvalidateAllByUserID(userID uint) error
+}
+
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordResetRepository creates a new instance of PasswordResetRepository
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
+
+// Create stores a new password reset token
+func (r *passwordResetRepository) Create(token *models.PasswordResetToken) error {
+	if err := r.db.Create(token).Error; err != nil {
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+
+	return nil
+}
+
+// FindByToken finds a password reset token by its hash, including used ones
+func (r *passwordResetRepository) FindByToken(tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+
+	err := r.db.Where("token = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find password reset token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// MarkUsed consumes a token; it returns false when the token was already used
+func (r *passwordResetRepository) MarkUsed(id uint) (bool, error) {
+	result := r.db.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to mark password reset token as used: %w", result.Error)
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// InvalidateAllByUserID marks every outstanding reset token of a user as used
+func (r *passwordResetRepository) InvalidateAllByUserID(userID uint) error {
+	err := r.db.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("failed to invalidate password reset tokens: %w", err)
+	}
+
+	return nil
+}
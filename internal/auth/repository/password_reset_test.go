@@ -0,0 +1,63 @@
+package repository
+
+import (
+	models "assessment_service/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordResetRepository_SQLite(t *testing.T) {
+	db := setupTestSQLiteDatabase(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	repo := NewPasswordResetRepository(db)
+
+	user := models.User{Name: "Reset User", Email: "reset@test.com", Password: "pw", Role: "student"}
+	require.NoError(t, db.Create(&user).Error)
+
+	first := &models.PasswordResetToken{UserID: user.ID, Token: "reset-hash-1", ExpiresAt: time.Now().Add(time.Hour)}
+	second := &models.PasswordResetToken{UserID: user.ID, Token: "reset-hash-2", ExpiresAt: time.Now().Add(time.Hour)}
+
+	t.Run("TestCreate", func(t *testing.T) {
+		require.NoError(t, repo.Create(first))
+		require.NoError(t, repo.Create(second))
+		assert.NotZero(t, first.ID)
+	})
+
+	t.Run("TestFindByToken", func(t *testing.T) {
+		found, err := repo.FindByToken("reset-hash-1")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, user.ID, found.UserID)
+		assert.Nil(t, found.UsedAt)
+	})
+
+	t.Run("TestFindByToken_NotFound", func(t *testing.T) {
+		found, err := repo.FindByToken("missing")
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("TestMarkUsed_SingleUse", func(t *testing.T) {
+		used, err := repo.MarkUsed(first.ID)
+		require.NoError(t, err)
+		assert.True(t, used)
+
+		used, err = repo.MarkUsed(first.ID)
+		require.NoError(t, err)
+		assert.False(t, used, "A token can only be consumed once")
+	})
+
+	t.Run("TestInvalidateAllByUserID", func(t *testing.T) {
+		require.NoError(t, repo.InvalidateAllByUserID(user.ID))
+
+		found, err := repo.FindByToken("reset-hash-2")
+		require.NoError(t, err)
+		assert.NotNil(t, found.UsedAt)
+	})
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	models "assessment_service/internal/model"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshTokenRepository manages the refresh tokens issued on login
+type RefreshTokenRepository interface {
+	Create(token *models.RefreshToken) error
+	FindByToken(tokenHash string) (*models.RefreshToken, error)
+	Revoke(id uint) (bool, error)
+	RevokeAllByUserID(userID uint) error
+}
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository creates a new instance of RefreshTokenRepository
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+// Create stores a new refresh token
+func (r *refreshTokenRepository) Create(token *models.RefreshToken) error {
+	if err := r.db.Create(token).Error; err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// FindByToken finds a refresh token by its hash, including revoked ones
+func (r *refreshTokenRepository) FindByToken(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+
+	err := r.db.Where("token = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find refresh token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// Revoke marks a single refresh token as revoked; it returns false when the token was already revoked
+func (r *refreshTokenRepository) Revoke(id uint) (bool, error) {
+	result := r.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to revoke refresh token: %w", result.Error)
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// RevokeAllByUserID revokes every active refresh token of a user
+func (r *refreshTokenRepository) RevokeAllByUserID(userID uint) error {
+	err := r.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	models "assessment_service/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestSQLiteDatabase initializes an in-memory SQLite database for the repository tests
+func setupTestSQLiteDatabase(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err, "Failed to connect to in-memory SQLite")
+
+	err = db.AutoMigrate(
+		&models.User{},
+		&models.RefreshToken{},
+		&models.PasswordResetToken{},
+	)
+	require.NoError(t, err, "Failed to run migrations on SQLite")
+
+	return db
+}
+
+func TestRefreshTokenRepository_SQLite(t *testing.T) {
+	db := setupTestSQLiteDatabase(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	repo := NewRefreshTokenRepository(db)
+
+	user := models.User{Name: "Token User", Email: "token@test.com", Password: "pw", Role: "student"}
+	require.NoError(t, db.Create(&user).Error)
+
+	first := &models.RefreshToken{UserID: user.ID, Token: "hash-1", ExpiresAt: time.Now().Add(time.Hour)}
+	second := &models.RefreshToken{UserID: user.ID, Token: "hash-2", ExpiresAt: time.Now().Add(time.Hour)}
+
+	t.Run("TestCreate", func(t *testing.T) {
+		require.NoError(t, repo.Create(first))
+		require.NoError(t, repo.Create(second))
+		assert.NotZero(t, first.ID)
+		assert.NotZero(t, second.ID)
+	})
+
+	t.Run("TestCreate_DuplicateToken", func(t *testing.T) {
+		err := repo.Create(&models.RefreshToken{UserID: user.ID, Token: "hash-1", ExpiresAt: time.Now()})
+		assert.Error(t, err)
+	})
+
+	t.Run("TestFindByToken", func(t *testing.T) {
+		found, err := repo.FindByToken("hash-1")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, first.ID, found.ID)
+		assert.Nil(t, found.RevokedAt)
+	})
+
+	t.Run("TestFindByToken_NotFound", func(t *testing.T) {
+		found, err := repo.FindByToken("missing")
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("TestRevoke", func(t *testing.T) {
+		revoked, err := repo.Revoke(first.ID)
+		require.NoError(t, err)
+		assert.True(t, revoked)
+
+		// Chỉ lần thu hồi đầu tiên có hiệu lực
+		revoked, err = repo.Revoke(first.ID)
+		require.NoError(t, err)
+		assert.False(t, revoked)
+
+		found, err := repo.FindByToken("hash-1")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.NotNil(t, found.RevokedAt)
+
+		other, err := repo.FindByToken("hash-2")
+		require.NoError(t, err)
+		assert.Nil(t, other.RevokedAt, "Other tokens must not be revoked")
+	})
+
+	t.Run("TestRevokeAllByUserID", func(t *testing.T) {
+		require.NoError(t, repo.RevokeAllByUserID(user.ID))
+
+		found, err := repo.FindByToken("hash-2")
+		require.NoError(t, err)
+		assert.NotNil(t, found.RevokedAt)
+	})
+}
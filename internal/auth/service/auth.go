@@ -0,0 +1,338 @@
+package service
+
+import (
+	"assessment_service/configs"
+	"assessment_service/internal/auth/repository"
+	models "assessment_service/internal/model"
+	repository2 "assessment_service/internal/users/repository"
+	"assessment_service/internal/util"
+	"assessment_service/pkg/mailer"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCredentials  = errors.New("invalid email or password")
+	ErrInactiveAccount     = errors.New("account is inactive")
+	ErrEmailAlreadyExists  = errors.New("email already registered")
+	ErrInvalidRole         = errors.New("role is not allowed for registration")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrInvalidResetToken   = errors.New("invalid or expired password reset token")
+)
+
+const passwordResetTemplate = "reset_password.html"
+
+// Roles a user can pick when registering on their own; admins are created through user management
+var registrableRoles = map[string]bool{
+	"student": true,
+	"teacher": true,
+}
+
+type AuthService interface {
+	Login(email, password string) (string, string, *models.User, error)
+	Register(user *models.User, password string) error
+	RefreshToken(refreshToken string) (string, string, error)
+	Logout(refreshToken string) error
+	ForgotPassword(email string) error
+	ResetPassword(token, newPassword string) error
+}
+
+type authService struct {
+	userRepo          repository2.UserRepository
+	refreshTokenRepo  repository.RefreshTokenRepository
+	passwordResetRepo repository.PasswordResetRepository
+	jwtService        util.Jwt
+	mailer            mailer.Mailer
+	config            configs.AuthConfig
+	log               *zap.Logger
+}
+
+func NewAuthService(
+	userRepo repository2.UserRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	passwordResetRepo repository.PasswordResetRepository,
+	jwtService util.Jwt,
+	mailer mailer.Mailer,
+	config configs.AuthConfig,
+	log *zap.Logger,
+) AuthService {
+	return &authService{
+		userRepo:          userRepo,
+		refreshTokenRepo:  refreshTokenRepo,
+		passwordResetRepo: passwordResetRepo,
+		jwtService:        jwtService,
+		mailer:            mailer,
+		config:            config,
+		log:               log,
+	}
+}
+
+func (s *authService) Login(email, password string) (string, string, *models.User, error) {
+	user, err := s.userRepo.FindByEmail(strings.TrimSpace(strings.ToLower(email)))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", "", nil, ErrInvalidCredentials
+		}
+		s.log.Error("[Login] failed to find user", zap.Error(err))
+		return "", "", nil, err
+	}
+
+	if !util.CheckPassword(user.Password, password) {
+		return "", "", nil, ErrInvalidCredentials
+	}
+
+	if strings.EqualFold(user.Status, "inactive") {
+		return "", "", nil, ErrInactiveAccount
+	}
+
+	accessToken, refreshToken, err := s.issueTokens(user)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	if err := s.userRepo.UpdateLastLogin(user.ID); err != nil {
+		// Not fatal for the login itself
+		s.log.Error("[Login] failed to update last login", zap.Error(err))
+	}
+
+	return accessToken, refreshToken, user, nil
+}
+
+func (s *authService) Register(user *models.User, password string) error {
+	user.Email = strings.TrimSpace(strings.ToLower(user.Email))
+	user.Role = strings.ToLower(user.Role)
+
+	if !registrableRoles[user.Role] {
+		return ErrInvalidRole
+	}
+
+	// Check if email is already taken
+	existing, err := s.userRepo.FindByEmail(user.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.log.Error("[Register] failed to check email", zap.Error(err))
+		return err
+	}
+
+	if existing != nil {
+		return ErrEmailAlreadyExists
+	}
+
+	hashed, err := util.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	user.Password = hashed
+	if user.Status == "" {
+		user.Status = "Active"
+	}
+
+	return s.userRepo.Create(user)
+}
+
+func (s *authService) RefreshToken(refreshToken string) (string, string, error) {
+	stored, err := s.refreshTokenRepo.FindByToken(util.HashToken(refreshToken))
+	if err != nil {
+		s.log.Error("[RefreshToken] failed to find refresh token", zap.Error(err))
+		return "", "", err
+	}
+
+	if stored == nil {
+		return "", "", ErrInvalidRefreshToken
+	}
+
+	// A revoked token being presented again means it was leaked, so kill the whole session family
+	if stored.RevokedAt != nil {
+		s.log.Warn("[RefreshToken] revoked refresh token reused", zap.Uint("userID", stored.UserID))
+		if err := s.refreshTokenRepo.RevokeAllByUserID(stored.UserID); err != nil {
+			s.log.Error("[RefreshToken] failed to revoke refresh tokens", zap.Error(err))
+		}
+		return "", "", ErrInvalidRefreshToken
+	}
+
+	if stored.ExpiresAt.Before(time.Now()) {
+		return "", "", ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.FindByID(stored.UserID)
+	if err != nil {
+		return "", "", ErrInvalidRefreshToken
+	}
+
+	if strings.EqualFold(user.Status, "inactive") {
+		return "", "", ErrInactiveAccount
+	}
+
+	// Rotate: the presented token can only be used once, so of two concurrent refreshes only the
+	// one that revokes it wins and the other is treated as reuse
+	revoked, err := s.refreshTokenRepo.Revoke(stored.ID)
+	if err != nil {
+		s.log.Error("[RefreshToken] failed to revoke refresh token", zap.Error(err))
+		return "", "", err
+	}
+
+	if !revoked {
+		s.log.Warn("[RefreshToken] refresh token used concurrently", zap.Uint("userID", stored.UserID))
+		if err := s.refreshTokenRepo.RevokeAllByUserID(stored.UserID); err != nil {
+			s.log.Error("[RefreshToken] failed to revoke refresh tokens", zap.Error(err))
+		}
+		return "", "", ErrInvalidRefreshToken
+	}
+
+	return s.issueTokens(user)
+}
+
+func (s *authService) Logout(refreshToken string) error {
+	stored, err := s.refreshTokenRepo.FindByToken(util.HashToken(refreshToken))
+	if err != nil {
+		s.log.Error("[Logout] failed to find refresh token", zap.Error(err))
+		return err
+	}
+
+	if stored == nil {
+		return ErrInvalidRefreshToken
+	}
+
+	_, err = s.refreshTokenRepo.Revoke(stored.ID)
+	return err
+}
+
+// ForgotPassword emails a reset link to the user. Unknown emails are ignored so the endpoint
+// cannot be used to find out which accounts exist.
+func (s *authService) ForgotPassword(email string) error {
+	user, err := s.userRepo.FindByEmail(strings.TrimSpace(strings.ToLower(email)))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		s.log.Error("[ForgotPassword] failed to find user", zap.Error(err))
+		return err
+	}
+
+	if strings.EqualFold(user.Status, "inactive") {
+		return nil
+	}
+
+	// Only the latest link is valid
+	if err := s.passwordResetRepo.InvalidateAllByUserID(user.ID); err != nil {
+		s.log.Error("[ForgotPassword] failed to invalidate old reset tokens", zap.Error(err))
+		return err
+	}
+
+	token, err := util.GenerateOpaqueToken()
+	if err != nil {
+		s.log.Error("[ForgotPassword] failed to generate reset token", zap.Error(err))
+		return err
+	}
+
+	expiresAt := time.Now().Add(s.config.PasswordResetExpiry)
+	err = s.passwordResetRepo.Create(&models.PasswordResetToken{
+		UserID:    user.ID,
+		Token:     util.HashToken(token),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		s.log.Error("[ForgotPassword] failed to store reset token", zap.Error(err))
+		return err
+	}
+
+	data := map[string]interface{}{
+		"Name":      user.Name,
+		"ResetLink": s.config.PasswordResetURL + "?token=" + url.QueryEscape(token),
+		"ExpiresAt": expiresAt.Format("15:04 02/01/2006"),
+	}
+	if err := s.mailer.Send([]string{user.Email}, "Đặt lại mật khẩu", passwordResetTemplate, data); err != nil {
+		s.log.Error("[ForgotPassword] failed to send reset email", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// ResetPassword consumes a reset token, sets the new password and logs the user out everywhere
+func (s *authService) ResetPassword(token, newPassword string) error {
+	stored, err := s.passwordResetRepo.FindByToken(util.HashToken(token))
+	if err != nil {
+		s.log.Error("[ResetPassword] failed to find reset token", zap.Error(err))
+		return err
+	}
+
+	if stored == nil || stored.UsedAt != nil || stored.ExpiresAt.Before(time.Now()) {
+		return ErrInvalidResetToken
+	}
+
+	if len(newPassword) < util.MIN_PASSWORD_LENGTH {
+		return util.ErrPasswordTooShort
+	}
+
+	user, err := s.userRepo.FindByID(stored.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		s.log.Error("[ResetPassword] failed to find user", zap.Error(err))
+		return err
+	}
+
+	// Consume the token first so two concurrent requests cannot both succeed
+	used, err := s.passwordResetRepo.MarkUsed(stored.ID)
+	if err != nil {
+		s.log.Error("[ResetPassword] failed to mark reset token as used", zap.Error(err))
+		return err
+	}
+
+	if !used {
+		return ErrInvalidResetToken
+	}
+
+	hashed, err := util.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	user.Password = hashed
+	if err := s.userRepo.Update(user); err != nil {
+		s.log.Error("[ResetPassword] failed to update password", zap.Error(err))
+		return err
+	}
+
+	if err := s.refreshTokenRepo.RevokeAllByUserID(user.ID); err != nil {
+		s.log.Error("[ResetPassword] failed to revoke refresh tokens", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// issueTokens creates a new access token and persists a new refresh token for the user
+func (s *authService) issueTokens(user *models.User) (string, string, error) {
+	accessToken, err := s.jwtService.GenerateToken(strconv.FormatUint(uint64(user.ID), 10), user.Role)
+	if err != nil {
+		s.log.Error("failed to generate access token", zap.Error(err))
+		return "", "", err
+	}
+
+	refreshToken, err := util.GenerateOpaqueToken()
+	if err != nil {
+		s.log.Error("failed to generate refresh token", zap.Error(err))
+		return "", "", err
+	}
+
+	err = s.refreshTokenRepo.Create(&models.RefreshToken{
+		UserID:    user.ID,
+		Token:     util.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(s.config.RefreshTokenExpiry),
+	})
+	if err != nil {
+		s.log.Error("failed to store refresh token", zap.Error(err))
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
+}
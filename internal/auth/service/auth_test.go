@@ -0,0 +1,472 @@
+package service
+
+import (
+	"assessment_service/configs"
+	models "assessment_service/internal/model"
+	"assessment_service/internal/util"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+// --- Mock UserRepository ---
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) Create(user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindByID(id uint) (*models.User, error) {
+	args := m.Called(id)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserRepository) FindByEmail(email string) (*models.User, error) {
+	args := m.Called(email)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserRepository) Update(user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) List(params util.PaginationParams) ([]models.User, int64, error) {
+	args := m.Called(params)
+	users, _ := args.Get(0).([]models.User)
+	count, _ := args.Get(1).(int64)
+	return users, count, args.Error(2)
+}
+
+func (m *MockUserRepository) UpdateLastLogin(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUserStats() (int64, int64, error) {
+	args := m.Called()
+	active, _ := args.Get(0).(int64)
+	inactive, _ := args.Get(1).(int64)
+	return active, inactive, args.Error(2)
+}
+
+func (m *MockUserRepository) CountAll() (int64, error) {
+	args := m.Called()
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
+func (m *MockUserRepository) GetNewUsersCount(days int) (int64, error) {
+	args := m.Called(days)
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
+func (m *MockUserRepository) GetListUserByAssessment(params util.PaginationParams, assessmentID uint) ([]models.User, int64, error) {
+	args := m.Called(params, assessmentID)
+	users, _ := args.Get(0).([]models.User)
+	count, _ := args.Get(1).(int64)
+	return users, count, args.Error(2)
+}
+
+// --- Mock RefreshTokenRepository ---
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefreshTokenRepository) Create(token *models.RefreshToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) FindByToken(tokenHash string) (*models.RefreshToken, error) {
+	args := m.Called(tokenHash)
+	token, _ := args.Get(0).(*models.RefreshToken)
+	return token, args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) Revoke(id uint) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) RevokeAllByUserID(userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+// --- Mock PasswordResetRepository ---
+type MockPasswordResetRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasswordResetRepository) Create(token *models.PasswordResetToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetRepository) FindByToken(tokenHash string) (*models.PasswordResetToken, error) {
+	args := m.Called(tokenHash)
+	token, _ := args.Get(0).(*models.PasswordResetToken)
+	return token, args.Error(1)
+}
+
+func (m *MockPasswordResetRepository) MarkUsed(id uint) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPasswordResetRepository) InvalidateAllByUserID(userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+// --- Mock Mailer ---
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Send(to []string, subject, templateName string, data interface{}) error {
+	args := m.Called(to, subject, templateName, data)
+	return args.Error(0)
+}
+
+// --- Mock Jwt ---
+type MockJwt struct {
+	mock.Mock
+}
+
+func (m *MockJwt) GenerateToken(userID string, role string) (string, error) {
+	args := m.Called(userID, role)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockJwt) ValidateToken(token string) (jwt.MapClaims, error) {
+	args := m.Called(token)
+	claims, _ := args.Get(0).(jwt.MapClaims)
+	return claims, args.Error(1)
+}
+
+var testAuthConfig = configs.AuthConfig{
+	RefreshTokenExpiry:  time.Hour,
+	PasswordResetExpiry: 30 * time.Minute,
+	PasswordResetURL:    "http://localhost:3000/reset-password",
+}
+
+func newTestAuthService(t *testing.T) (AuthService, *MockUserRepository, *MockRefreshTokenRepository, *MockJwt) {
+	svc, userRepo, tokenRepo, _, _, jwtService := newTestAuthServiceWithReset(t)
+	return svc, userRepo, tokenRepo, jwtService
+}
+
+func newTestAuthServiceWithReset(t *testing.T) (AuthService, *MockUserRepository, *MockRefreshTokenRepository, *MockPasswordResetRepository, *MockMailer, *MockJwt) {
+	userRepo := new(MockUserRepository)
+	tokenRepo := new(MockRefreshTokenRepository)
+	resetRepo := new(MockPasswordResetRepository)
+	mailService := new(MockMailer)
+	jwtService := new(MockJwt)
+	svc := NewAuthService(userRepo, tokenRepo, resetRepo, jwtService, mailService, testAuthConfig, zaptest.NewLogger(t))
+	return svc, userRepo, tokenRepo, resetRepo, mailService, jwtService
+}
+
+func TestAuthService_Login_Success(t *testing.T) {
+	svc, userRepo, tokenRepo, jwtService := newTestAuthService(t)
+
+	hashed, err := util.HashPassword("password123")
+	require.NoError(t, err)
+	user := &models.User{ID: 7, Email: "john@example.com", Password: hashed, Role: "student", Status: "Active"}
+
+	userRepo.On("FindByEmail", "john@example.com").Return(user, nil)
+	jwtService.On("GenerateToken", "7", "student").Return("access-token", nil)
+	tokenRepo.On("Create", mock.MatchedBy(func(tk *models.RefreshToken) bool {
+		return tk.UserID == 7 && tk.Token != "" && tk.ExpiresAt.After(time.Now())
+	})).Return(nil)
+	userRepo.On("UpdateLastLogin", uint(7)).Return(nil)
+
+	access, refresh, loggedIn, err := svc.Login(" John@Example.com ", "password123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "access-token", access)
+	assert.NotEmpty(t, refresh)
+	assert.Equal(t, user, loggedIn)
+	// Only the hash of the refresh token is persisted
+	tokenRepo.AssertCalled(t, "Create", mock.MatchedBy(func(tk *models.RefreshToken) bool {
+		return tk.Token == util.HashToken(refresh)
+	}))
+	userRepo.AssertExpectations(t)
+	jwtService.AssertExpectations(t)
+}
+
+func TestAuthService_Login_WrongPassword(t *testing.T) {
+	svc, userRepo, tokenRepo, _ := newTestAuthService(t)
+
+	hashed, err := util.HashPassword("password123")
+	require.NoError(t, err)
+	userRepo.On("FindByEmail", "john@example.com").Return(&models.User{ID: 7, Password: hashed}, nil)
+
+	_, _, _, err = svc.Login("john@example.com", "nope-nope")
+
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	tokenRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestAuthService_Login_UnknownEmail(t *testing.T) {
+	svc, userRepo, _, _ := newTestAuthService(t)
+
+	userRepo.On("FindByEmail", "ghost@example.com").Return(nil, gorm.ErrRecordNotFound)
+
+	_, _, _, err := svc.Login("ghost@example.com", "password123")
+
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestAuthService_Login_Inactive(t *testing.T) {
+	svc, userRepo, _, _ := newTestAuthService(t)
+
+	hashed, err := util.HashPassword("password123")
+	require.NoError(t, err)
+	userRepo.On("FindByEmail", "john@example.com").Return(&models.User{ID: 7, Password: hashed, Status: "Inactive"}, nil)
+
+	_, _, _, err = svc.Login("john@example.com", "password123")
+
+	assert.ErrorIs(t, err, ErrInactiveAccount)
+}
+
+func TestAuthService_Register(t *testing.T) {
+	svc, userRepo, _, _ := newTestAuthService(t)
+
+	userRepo.On("FindByEmail", "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+	userRepo.On("Create", mock.MatchedBy(func(u *models.User) bool {
+		return u.Email == "new@example.com" && u.Role == "student" && util.CheckPassword(u.Password, "password123")
+	})).Return(nil)
+
+	user := &models.User{Name: "New", Email: "New@example.com", Role: "Student"}
+	err := svc.Register(user, "password123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Active", user.Status)
+	userRepo.AssertExpectations(t)
+}
+
+func TestAuthService_Register_Errors(t *testing.T) {
+	t.Run("AdminNotAllowed", func(t *testing.T) {
+		svc, userRepo, _, _ := newTestAuthService(t)
+		err := svc.Register(&models.User{Email: "a@example.com", Role: "Admin"}, "password123")
+		assert.ErrorIs(t, err, ErrInvalidRole)
+		userRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("EmailTaken", func(t *testing.T) {
+		svc, userRepo, _, _ := newTestAuthService(t)
+		userRepo.On("FindByEmail", "a@example.com").Return(&models.User{ID: 1}, nil)
+		err := svc.Register(&models.User{Email: "a@example.com", Role: "student"}, "password123")
+		assert.ErrorIs(t, err, ErrEmailAlreadyExists)
+	})
+
+	t.Run("PasswordTooShort", func(t *testing.T) {
+		svc, userRepo, _, _ := newTestAuthService(t)
+		userRepo.On("FindByEmail", "a@example.com").Return(nil, gorm.ErrRecordNotFound)
+		err := svc.Register(&models.User{Email: "a@example.com", Role: "student"}, "short")
+		assert.ErrorIs(t, err, util.ErrPasswordTooShort)
+	})
+}
+
+func TestAuthService_RefreshToken_Rotates(t *testing.T) {
+	svc, userRepo, tokenRepo, jwtService := newTestAuthService(t)
+
+	stored := &models.RefreshToken{ID: 3, UserID: 7, ExpiresAt: time.Now().Add(time.Hour)}
+	tokenRepo.On("FindByToken", util.HashToken("old-token")).Return(stored, nil)
+	userRepo.On("FindByID", uint(7)).Return(&models.User{ID: 7, Role: "teacher", Status: "Active"}, nil)
+	tokenRepo.On("Revoke", uint(3)).Return(true, nil)
+	jwtService.On("GenerateToken", "7", "teacher").Return("new-access", nil)
+	tokenRepo.On("Create", mock.Anything).Return(nil)
+
+	access, refresh, err := svc.RefreshToken("old-token")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "new-access", access)
+	assert.NotEqual(t, "old-token", refresh)
+	tokenRepo.AssertExpectations(t)
+}
+
+func TestAuthService_RefreshToken_Invalid(t *testing.T) {
+	t.Run("NotFound", func(t *testing.T) {
+		svc, _, tokenRepo, _ := newTestAuthService(t)
+		tokenRepo.On("FindByToken", util.HashToken("missing")).Return(nil, nil)
+		_, _, err := svc.RefreshToken("missing")
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		svc, _, tokenRepo, _ := newTestAuthService(t)
+		tokenRepo.On("FindByToken", util.HashToken("expired")).Return(&models.RefreshToken{ID: 1, UserID: 7, ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+		_, _, err := svc.RefreshToken("expired")
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	})
+
+	t.Run("ReusedAfterRevocation", func(t *testing.T) {
+		svc, _, tokenRepo, _ := newTestAuthService(t)
+		revokedAt := time.Now().Add(-time.Minute)
+		tokenRepo.On("FindByToken", util.HashToken("reused")).Return(&models.RefreshToken{ID: 1, UserID: 7, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}, nil)
+		tokenRepo.On("RevokeAllByUserID", uint(7)).Return(nil)
+
+		_, _, err := svc.RefreshToken("reused")
+
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+		tokenRepo.AssertCalled(t, "RevokeAllByUserID", uint(7))
+	})
+
+	t.Run("ConcurrentRefresh", func(t *testing.T) {
+		svc, userRepo, tokenRepo, jwtService := newTestAuthService(t)
+		// Một yêu cầu khác đã thu hồi token giữa lúc tìm và lúc thu hồi
+		tokenRepo.On("FindByToken", util.HashToken("raced")).Return(&models.RefreshToken{ID: 1, UserID: 7, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		userRepo.On("FindByID", uint(7)).Return(&models.User{ID: 7, Role: "teacher", Status: "Active"}, nil)
+		tokenRepo.On("Revoke", uint(1)).Return(false, nil)
+		tokenRepo.On("RevokeAllByUserID", uint(7)).Return(nil)
+
+		_, _, err := svc.RefreshToken("raced")
+
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+		tokenRepo.AssertCalled(t, "RevokeAllByUserID", uint(7))
+		jwtService.AssertNotCalled(t, "GenerateToken", mock.Anything, mock.Anything)
+		tokenRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	svc, _, tokenRepo, _ := newTestAuthService(t)
+
+	tokenRepo.On("FindByToken", util.HashToken("token")).Return(&models.RefreshToken{ID: 5}, nil)
+	tokenRepo.On("Revoke", uint(5)).Return(true, nil)
+
+	assert.NoError(t, svc.Logout("token"))
+	tokenRepo.AssertExpectations(t)
+}
+
+func TestAuthService_Logout_Error(t *testing.T) {
+	svc, _, tokenRepo, _ := newTestAuthService(t)
+
+	tokenRepo.On("FindByToken", util.HashToken("token")).Return(nil, errors.New("db error"))
+
+	assert.Error(t, svc.Logout("token"))
+}
+
+func TestAuthService_ForgotPassword(t *testing.T) {
+	svc, userRepo, _, resetRepo, mailService, _ := newTestAuthServiceWithReset(t)
+
+	user := &models.User{ID: 7, Name: "John", Email: "john@example.com", Status: "Active"}
+	userRepo.On("FindByEmail", "john@example.com").Return(user, nil)
+	resetRepo.On("InvalidateAllByUserID", uint(7)).Return(nil)
+
+	var stored *models.PasswordResetToken
+	resetRepo.On("Create", mock.MatchedBy(func(tk *models.PasswordResetToken) bool {
+		stored = tk
+		return tk.UserID == 7 && tk.ExpiresAt.After(time.Now().Add(29*time.Minute))
+	})).Return(nil)
+
+	var link string
+	mailService.On("Send", []string{"john@example.com"}, mock.Anything, "reset_password.html", mock.MatchedBy(func(data interface{}) bool {
+		link, _ = data.(map[string]interface{})["ResetLink"].(string)
+		return true
+	})).Return(nil)
+
+	err := svc.ForgotPassword("John@example.com")
+
+	require.NoError(t, err)
+	mailService.AssertExpectations(t)
+	resetRepo.AssertExpectations(t)
+
+	// The emailed token is only stored as a hash
+	require.Contains(t, link, "http://localhost:3000/reset-password?token=")
+	token := link[len("http://localhost:3000/reset-password?token="):]
+	assert.Equal(t, util.HashToken(token), stored.Token)
+}
+
+func TestAuthService_ForgotPassword_UnknownEmail(t *testing.T) {
+	svc, userRepo, _, resetRepo, mailService, _ := newTestAuthServiceWithReset(t)
+
+	userRepo.On("FindByEmail", "ghost@example.com").Return(nil, gorm.ErrRecordNotFound)
+
+	assert.NoError(t, svc.ForgotPassword("ghost@example.com"))
+	resetRepo.AssertNotCalled(t, "Create", mock.Anything)
+	mailService.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAuthService_ResetPassword(t *testing.T) {
+	svc, userRepo, tokenRepo, resetRepo, _, _ := newTestAuthServiceWithReset(t)
+
+	resetRepo.On("FindByToken", util.HashToken("reset-token")).Return(&models.PasswordResetToken{ID: 4, UserID: 7, ExpiresAt: time.Now().Add(time.Minute)}, nil)
+	userRepo.On("FindByID", uint(7)).Return(&models.User{ID: 7, Password: "old-hash"}, nil)
+	resetRepo.On("MarkUsed", uint(4)).Return(true, nil)
+	userRepo.On("Update", mock.MatchedBy(func(u *models.User) bool {
+		return util.CheckPassword(u.Password, "new-password")
+	})).Return(nil)
+	tokenRepo.On("RevokeAllByUserID", uint(7)).Return(nil)
+
+	err := svc.ResetPassword("reset-token", "new-password")
+
+	assert.NoError(t, err)
+	userRepo.AssertExpectations(t)
+	tokenRepo.AssertExpectations(t)
+	resetRepo.AssertExpectations(t)
+}
+
+func TestAuthService_ResetPassword_Invalid(t *testing.T) {
+	usedAt := time.Now().Add(-time.Minute)
+	cases := map[string]*models.PasswordResetToken{
+		"NotFound": nil,
+		"Used":     {ID: 1, UserID: 7, ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt},
+		"Expired":  {ID: 1, UserID: 7, ExpiresAt: time.Now().Add(-time.Minute)},
+	}
+
+	for name, stored := range cases {
+		t.Run(name, func(t *testing.T) {
+			svc, userRepo, tokenRepo, resetRepo, _, _ := newTestAuthServiceWithReset(t)
+			resetRepo.On("FindByToken", util.HashToken("token")).Return(stored, nil)
+
+			err := svc.ResetPassword("token", "new-password")
+
+			assert.ErrorIs(t, err, ErrInvalidResetToken)
+			userRepo.AssertNotCalled(t, "Update", mock.Anything)
+			tokenRepo.AssertNotCalled(t, "RevokeAllByUserID", mock.Anything)
+		})
+	}
+
+	t.Run("ConsumedConcurrently", func(t *testing.T) {
+		svc, userRepo, _, resetRepo, _, _ := newTestAuthServiceWithReset(t)
+		resetRepo.On("FindByToken", util.HashToken("token")).Return(&models.PasswordResetToken{ID: 1, UserID: 7, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		userRepo.On("FindByID", uint(7)).Return(&models.User{ID: 7}, nil)
+		resetRepo.On("MarkUsed", uint(1)).Return(false, nil)
+
+		err := svc.ResetPassword("token", "new-password")
+
+		assert.ErrorIs(t, err, ErrInvalidResetToken)
+		userRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("PasswordTooShort", func(t *testing.T) {
+		svc, _, _, resetRepo, _, _ := newTestAuthServiceWithReset(t)
+		resetRepo.On("FindByToken", util.HashToken("token")).Return(&models.PasswordResetToken{ID: 1, UserID: 7, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+		err := svc.ResetPassword("token", "short")
+
+		assert.ErrorIs(t, err, util.ErrPasswordTooShort)
+		resetRepo.AssertNotCalled(t, "MarkUsed", mock.Anything)
+	})
+}
@@ -0,0 +1,250 @@
+package rest
+
+import (
+	"assessment_service/internal/bank/service"
+	"assessment_service/internal/middleware"
+	models "assessment_service/internal/model"
+	"assessment_service/internal/questions/types"
+	"assessment_service/internal/util"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type BankHandler struct {
+	bankService service.BankService
+	log         *zap.Logger
+}
+
+func NewBankHandler(bankService service.BankService, log *zap.Logger) *BankHandler {
+	return &BankHandler{bankService: bankService, log: log}
+}
+
+// ListQuestions searches the question bank. The "search" query parameter matches the question text;
+// "subject", "topic", "difficulty", "tag" and "type" narrow the list, and "mine=true" keeps only the
+// current user's own questions.
+func (h *BankHandler) ListQuestions(w http.ResponseWriter, r *http.Request) {
+	principal, ok := h.principal(w, r)
+	if !ok {
+		return
+	}
+
+	params := util.GetPaginationParams(r)
+	query := r.URL.Query()
+	for _, filter := range []string{"subject", "topic", "difficulty", "tag", "type"} {
+		if value := query.Get(filter); value != "" {
+			params.Filters[filter] = value
+		}
+	}
+	if query.Get("mine") == "true" {
+		params.Filters["owner"] = principal.UserID
+	}
+
+	questions, total, err := h.bankService.ListQuestions(params, principal.UserID)
+	if err != nil {
+		h.writeError(w, "ListQuestions", err, "Failed to list bank questions")
+		return
+	}
+
+	util.ResponseInterface(w, util.CreatePaginationResponse(questions, total, params), http.StatusOK)
+}
+
+func (h *BankHandler) CreateQuestion(w http.ResponseWriter, r *http.Request) {
+	principal, ok := h.principal(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.BankQuestionDTO
+	if !h.decode(w, r, "CreateQuestion", &req) {
+		return
+	}
+
+	question, err := h.bankService.CreateQuestion(req, principal.UserID)
+	if err != nil {
+		h.writeError(w, "CreateQuestion", err, "Failed to create bank question")
+		return
+	}
+
+	util.ResponseInterface(w, question, http.StatusCreated)
+}
+
+func (h *BankHandler) GetQuestion(w http.ResponseWriter, r *http.Request) {
+	principal, ok := h.principal(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := h.parseID(w, r, "id", "Invalid bank question ID")
+	if !ok {
+		return
+	}
+
+	question, err := h.bankService.GetQuestion(id, principal.UserID)
+	if err != nil {
+		h.writeError(w, "GetQuestion", err, "Failed to get bank question")
+		return
+	}
+
+	util.ResponseInterface(w, question, http.StatusOK)
+}
+
+func (h *BankHandler) UpdateQuestion(w http.ResponseWriter, r *http.Request) {
+	principal, ok := h.principal(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := h.parseID(w, r, "id", "Invalid bank question ID")
+	if !ok {
+		return
+	}
+
+	var req models.BankQuestionDTO
+	if !h.decode(w, r, "UpdateQuestion", &req) {
+		return
+	}
+
+	question, err := h.bankService.UpdateQuestion(id, req, principal.UserID)
+	if err != nil {
+		h.writeError(w, "UpdateQuestion", err, "Failed to update bank question")
+		return
+	}
+
+	util.ResponseInterface(w, question, http.StatusOK)
+}
+
+func (h *BankHandler) DeleteQuestion(w http.ResponseWriter, r *http.Request) {
+	principal, ok := h.principal(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := h.parseID(w, r, "id", "Invalid bank question ID")
+	if !ok {
+		return
+	}
+
+	if err := h.bankService.DeleteQuestion(id, principal.UserID); err != nil {
+		h.writeError(w, "DeleteQuestion", err, "Failed to delete bank question")
+		return
+	}
+
+	util.ResponseMap(w, map[string]interface{}{
+		"message": "Bank question deleted successfully",
+	}, http.StatusOK)
+}
+
+func (h *BankHandler) GetVersions(w http.ResponseWriter, r *http.Request) {
+	principal, ok := h.principal(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := h.parseID(w, r, "id", "Invalid bank question ID")
+	if !ok {
+		return
+	}
+
+	versions, err := h.bankService.GetVersions(id, principal.UserID)
+	if err != nil {
+		h.writeError(w, "GetVersions", err, "Failed to get bank question versions")
+		return
+	}
+
+	util.ResponseInterface(w, versions, http.StatusOK)
+}
+
+// AddToAssessment copies a bank question into the assessment
+func (h *BankHandler) AddToAssessment(w http.ResponseWriter, r *http.Request) {
+	principal, ok := h.principal(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := h.parseID(w, r, "id", "Invalid assessment ID")
+	if !ok {
+		return
+	}
+
+	var req models.BankImportDTO
+	if !h.decode(w, r, "AddToAssessment", &req) {
+		return
+	}
+
+	question, err := h.bankService.AddToAssessment(id, req, principal.UserID)
+	if err != nil {
+		h.writeError(w, "AddToAssessment", err, "Failed to add bank question")
+		return
+	}
+
+	util.ResponseInterface(w, question, http.StatusCreated)
+}
+
+func (h *BankHandler) principal(w http.ResponseWriter, r *http.Request) (*middleware.Principal, bool) {
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "UNAUTHORIZED",
+			"message": "User ID not found in context",
+		}, http.StatusUnauthorized)
+		return nil, false
+	}
+	return principal, true
+}
+
+func (h *BankHandler) decode(w http.ResponseWriter, r *http.Request, fn string, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		h.log.Error("["+fn+"] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func (h *BankHandler) parseID(w http.ResponseWriter, r *http.Request, name, message string) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)[name], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": message,
+		}, http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeError maps service errors to HTTP responses
+func (h *BankHandler) writeError(w http.ResponseWriter, fn string, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrBankQuestionNotFound), errors.Is(err, service.ErrBankVersionNotFound),
+		errors.Is(err, service.ErrAssessmentNotFound), errors.Is(err, service.ErrUserNotFound):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "NOT_FOUND",
+			"message": err.Error(),
+		}, http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidBankQuestion), errors.Is(err, types.ErrInvalidDefinition), errors.Is(err, types.ErrUnknownType):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": err.Error(),
+		}, http.StatusBadRequest)
+	case errors.Is(err, service.ErrBankAccessDenied):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "FORBIDDEN",
+			"message": err.Error(),
+		}, http.StatusForbidden)
+	default:
+		h.log.Error("["+fn+"] "+message, zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "ERROR",
+			"message": message,
+		}, http.StatusInternalServerError)
+	}
+}
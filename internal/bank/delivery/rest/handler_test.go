@@ -0,0 +1,234 @@
+package rest
+
+import (
+	"assessment_service/internal/bank/service"
+	"assessment_service/internal/middleware"
+	models "assessment_service/internal/model"
+	"assessment_service/internal/questions/types"
+	"assessment_service/internal/util"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// --- Mock BankService ---
+type MockBankService struct {
+	mock.Mock
+}
+
+func (m *MockBankService) CreateQuestion(dto models.BankQuestionDTO, ownerID uint) (*models.BankQuestion, error) {
+	args := m.Called(dto, ownerID)
+	question, _ := args.Get(0).(*models.BankQuestion)
+	return question, args.Error(1)
+}
+
+func (m *MockBankService) GetQuestion(id, userID uint) (*models.BankQuestion, error) {
+	args := m.Called(id, userID)
+	question, _ := args.Get(0).(*models.BankQuestion)
+	return question, args.Error(1)
+}
+
+func (m *MockBankService) ListQuestions(params util.PaginationParams, userID uint) ([]models.BankQuestion, int64, error) {
+	args := m.Called(params, userID)
+	questions, _ := args.Get(0).([]models.BankQuestion)
+	total, _ := args.Get(1).(int64)
+	return questions, total, args.Error(2)
+}
+
+func (m *MockBankService) UpdateQuestion(id uint, dto models.BankQuestionDTO, userID uint) (*models.BankQuestion, error) {
+	args := m.Called(id, dto, userID)
+	question, _ := args.Get(0).(*models.BankQuestion)
+	return question, args.Error(1)
+}
+
+func (m *MockBankService) DeleteQuestion(id, userID uint) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+func (m *MockBankService) GetVersions(id, userID uint) ([]models.BankQuestionVersion, error) {
+	args := m.Called(id, userID)
+	versions, _ := args.Get(0).([]models.BankQuestionVersion)
+	return versions, args.Error(1)
+}
+
+func (m *MockBankService) AddToAssessment(assessmentID uint, dto models.BankImportDTO, userID uint) (*models.Question, error) {
+	args := m.Called(assessmentID, dto, userID)
+	question, _ := args.Get(0).(*models.Question)
+	return question, args.Error(1)
+}
+
+func serveBank(handler *BankHandler, req *http.Request) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	router.HandleFunc("/bank/questions", handler.ListQuestions).Methods(http.MethodGet)
+	router.HandleFunc("/bank/questions", handler.CreateQuestion).Methods(http.MethodPost)
+	router.HandleFunc("/bank/questions/{id}", handler.GetQuestion).Methods(http.MethodGet)
+	router.HandleFunc("/bank/questions/{id}", handler.UpdateQuestion).Methods(http.MethodPut)
+	router.HandleFunc("/bank/questions/{id}", handler.DeleteQuestion).Methods(http.MethodDelete)
+	router.HandleFunc("/bank/questions/{id}/versions", handler.GetVersions).Methods(http.MethodGet)
+	router.HandleFunc("/assessments/{id}/questions/from-bank", handler.AddToAssessment).Methods(http.MethodPost)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func newRequest(method, url string, body string, principal *middleware.Principal) *http.Request {
+	req := httptest.NewRequest(method, url, bytes.NewBufferString(body))
+	if principal != nil {
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+	}
+	return req
+}
+
+var teacher = &middleware.Principal{UserID: 5, Role: "teacher"}
+
+func TestBankHandler_ListQuestions(t *testing.T) {
+	mockService := new(MockBankService)
+	handler := NewBankHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("ListQuestions", mock.MatchedBy(func(p util.PaginationParams) bool {
+		return p.Search == "equation" && p.Filters["subject"] == "Algebra" && p.Filters["tag"] == "linear" && p.Filters["owner"] == uint(5)
+	}), uint(5)).Return([]models.BankQuestion{{ID: 3}}, int64(1), nil)
+
+	rr := serveBank(handler, newRequest(http.MethodGet, "/bank/questions?search=equation&subject=Algebra&tag=linear&mine=true", "", teacher))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+	assert.Equal(t, float64(1), result["totalElements"])
+	mockService.AssertExpectations(t)
+}
+
+func TestBankHandler_ListQuestions_Unauthorized(t *testing.T) {
+	mockService := new(MockBankService)
+	handler := NewBankHandler(mockService, zaptest.NewLogger(t))
+
+	rr := serveBank(handler, newRequest(http.MethodGet, "/bank/questions", "", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	mockService.AssertNotCalled(t, "ListQuestions", mock.Anything, mock.Anything)
+}
+
+func TestBankHandler_CreateQuestion(t *testing.T) {
+	mockService := new(MockBankService)
+	handler := NewBankHandler(mockService, zaptest.NewLogger(t))
+
+	dto := models.BankQuestionDTO{Subject: "Algebra", Type: "true-false", Text: "1 + 1 = 2", CorrectAnswer: true, Points: 1}
+	mockService.On("CreateQuestion", dto, uint(5)).Return(&models.BankQuestion{ID: 3, OwnerID: 5, CurrentVersion: 1}, nil)
+
+	rr := serveBank(handler, newRequest(http.MethodPost, "/bank/questions", `{"subject":"Algebra","type":"true-false","text":"1 + 1 = 2","correctAnswer":true,"points":1}`, teacher))
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestBankHandler_CreateQuestion_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"invalid question", fmt.Errorf("%w: text is required", service.ErrInvalidBankQuestion), http.StatusBadRequest},
+		{"invalid definition", fmt.Errorf("%w: correct answer must match one of the option IDs", types.ErrInvalidDefinition), http.StatusBadRequest},
+		{"unknown type", types.ErrUnknownType, http.StatusBadRequest},
+		{"user not found", service.ErrUserNotFound, http.StatusNotFound},
+		{"unexpected", fmt.Errorf("db error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockBankService)
+			handler := NewBankHandler(mockService, zaptest.NewLogger(t))
+			mockService.On("CreateQuestion", mock.Anything, uint(5)).Return(nil, tt.err)
+
+			rr := serveBank(handler, newRequest(http.MethodPost, "/bank/questions", `{"text":""}`, teacher))
+
+			assert.Equal(t, tt.code, rr.Code)
+		})
+	}
+}
+
+func TestBankHandler_GetQuestion_AccessDenied(t *testing.T) {
+	mockService := new(MockBankService)
+	handler := NewBankHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("GetQuestion", uint(3), uint(5)).Return(nil, service.ErrBankAccessDenied)
+
+	rr := serveBank(handler, newRequest(http.MethodGet, "/bank/questions/3", "", teacher))
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func TestBankHandler_UpdateQuestion_InvalidInput(t *testing.T) {
+	mockService := new(MockBankService)
+	handler := NewBankHandler(mockService, zaptest.NewLogger(t))
+
+	rr := serveBank(handler, newRequest(http.MethodPut, "/bank/questions/3", `{"text":`, teacher))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertNotCalled(t, "UpdateQuestion", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBankHandler_DeleteQuestion_NotFound(t *testing.T) {
+	mockService := new(MockBankService)
+	handler := NewBankHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("DeleteQuestion", uint(3), uint(5)).Return(service.ErrBankQuestionNotFound)
+
+	rr := serveBank(handler, newRequest(http.MethodDelete, "/bank/questions/3", "", teacher))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestBankHandler_GetVersions(t *testing.T) {
+	mockService := new(MockBankService)
+	handler := NewBankHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("GetVersions", uint(3), uint(5)).Return([]models.BankQuestionVersion{{Version: 2}, {Version: 1}}, nil)
+
+	rr := serveBank(handler, newRequest(http.MethodGet, "/bank/questions/3/versions", "", teacher))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var versions []models.BankQuestionVersion
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &versions))
+	assert.Len(t, versions, 2)
+}
+
+func TestBankHandler_AddToAssessment(t *testing.T) {
+	mockService := new(MockBankService)
+	handler := NewBankHandler(mockService, zaptest.NewLogger(t))
+
+	version := 1
+	bankQuestionID := uint(3)
+	mockService.On("AddToAssessment", uint(9), models.BankImportDTO{BankQuestionID: 3, Version: &version}, uint(5)).
+		Return(&models.Question{ID: 40, AssessmentID: 9, BankQuestionID: &bankQuestionID, BankVersion: &version}, nil)
+
+	rr := serveBank(handler, newRequest(http.MethodPost, "/assessments/9/questions/from-bank", `{"bankQuestionId":3,"version":1}`, teacher))
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var question models.Question
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &question))
+	require.NotNil(t, question.BankVersion)
+	assert.Equal(t, 1, *question.BankVersion)
+}
+
+func TestBankHandler_AddToAssessment_VersionNotFound(t *testing.T) {
+	mockService := new(MockBankService)
+	handler := NewBankHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("AddToAssessment", uint(9), mock.Anything, uint(5)).Return(nil, service.ErrBankVersionNotFound)
+
+	rr := serveBank(handler, newRequest(http.MethodPost, "/assessments/9/questions/from-bank", `{"bankQuestionId":3,"version":7}`, teacher))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
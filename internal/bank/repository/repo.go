@@ -0,0 +1,222 @@
+package repository
+
+import (
+	models "assessment_service/internal/model"
+	"assessment_service/internal/util"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type BankRepository interface {
+	// Create stores a bank question with its first version
+	Create(question *models.BankQuestion, version *models.BankQuestionVersion) error
+	// FindByID returns a bank question with its owner and current version
+	FindByID(id uint) (*models.BankQuestion, error)
+	// Update saves a bank question and, when given, the version it moved to
+	Update(question *models.BankQuestion, version *models.BankQuestionVersion) error
+	Delete(id uint) error
+	List(params util.PaginationParams) ([]models.BankQuestion, int64, error)
+	FindVersions(bankQuestionID uint) ([]models.BankQuestionVersion, error)
+	FindVersion(bankQuestionID uint, version int) (*models.BankQuestionVersion, error)
+}
+
+type bankRepository struct {
+	db *gorm.DB
+}
+
+func NewBankRepository(db *gorm.DB) BankRepository {
+	return &bankRepository{db: db}
+}
+
+// sortColumns are the columns bank questions can be sorted by
+var sortColumns = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"subject":    true,
+	"topic":      true,
+	"difficulty": true,
+}
+
+func (r *bankRepository) Create(question *models.BankQuestion, version *models.BankQuestionVersion) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Owner").Create(question).Error; err != nil {
+			return err
+		}
+
+		version.BankQuestionID = question.ID
+		version.Version = question.CurrentVersion
+		return tx.Create(version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create bank question: %w", err)
+	}
+
+	question.Latest = version
+	return nil
+}
+
+func (r *bankRepository) FindByID(id uint) (*models.BankQuestion, error) {
+	var question models.BankQuestion
+	if err := r.db.Preload("Owner").First(&question, id).Error; err != nil {
+		return nil, err
+	}
+
+	latest, err := r.FindVersion(question.ID, question.CurrentVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find current version: %w", err)
+	}
+	question.Latest = latest
+
+	return &question, nil
+}
+
+func (r *bankRepository) Update(question *models.BankQuestion, version *models.BankQuestionVersion) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if version != nil {
+			version.BankQuestionID = question.ID
+			version.Version = question.CurrentVersion
+			if err := tx.Create(version).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Omit("Owner").Save(question).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update bank question: %w", err)
+	}
+
+	if version != nil {
+		question.Latest = version
+	}
+	return nil
+}
+
+// Delete removes the question from the bank. Its versions stay, as assessments may use them.
+func (r *bankRepository) Delete(id uint) error {
+	if err := r.db.Delete(&models.BankQuestion{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete bank question: %w", err)
+	}
+	return nil
+}
+
+// List searches bank questions by the text of their current version. Filters narrow the list to
+// questions a user may see ("visibleTo" with "department"), to an owner, and by subject, topic,
+// difficulty, tag and question type.
+func (r *bankRepository) List(params util.PaginationParams) ([]models.BankQuestion, int64, error) {
+	var questions []models.BankQuestion
+	var total int64
+
+	query := r.db.Model(&models.BankQuestion{}).
+		Joins("JOIN bank_question_versions ON bank_question_versions.bank_question_id = bank_questions.id AND bank_question_versions.version = bank_questions.current_version")
+
+	if params.Search != "" {
+		query = query.Where("bank_question_versions.text LIKE ?", "%"+params.Search+"%")
+	}
+
+	if params.Filters != nil {
+		if userID, ok := params.Filters["visibleTo"]; ok {
+			department, _ := params.Filters["department"].(string)
+			query = query.Where("(bank_questions.owner_id = ? OR (bank_questions.department <> '' AND bank_questions.department = ?))", userID, department)
+		}
+		if val, ok := params.Filters["owner"]; ok {
+			query = query.Where("bank_questions.owner_id = ?", val)
+		}
+		if val, ok := params.Filters["subject"]; ok {
+			query = query.Where("bank_questions.subject = ?", val)
+		}
+		if val, ok := params.Filters["topic"]; ok {
+			query = query.Where("bank_questions.topic = ?", val)
+		}
+		if val, ok := params.Filters["difficulty"]; ok {
+			query = query.Where("bank_questions.difficulty = ?", val)
+		}
+		if val, ok := params.Filters["tag"].(string); ok {
+			// Tags are kept as a JSON list of lower case strings
+			query = query.Where("bank_questions.tags LIKE ?", `%"`+strings.ToLower(val)+`"%`)
+		}
+		if val, ok := params.Filters["type"]; ok {
+			query = query.Where("bank_question_versions.type = ?", val)
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count bank questions: %w", err)
+	}
+
+	if sortColumns[params.SortBy] {
+		query = query.Order("bank_questions." + params.SortBy + " " + params.SortDir)
+	} else {
+		query = query.Order("bank_questions.updated_at DESC")
+	}
+
+	err := query.Select("bank_questions.*").
+		Preload("Owner").
+		Offset(params.Offset).Limit(params.Limit).
+		Find(&questions).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list bank questions: %w", err)
+	}
+
+	if err := r.loadLatest(questions); err != nil {
+		return nil, 0, err
+	}
+
+	return questions, total, nil
+}
+
+// loadLatest fills in the current version of each question
+func (r *bankRepository) loadLatest(questions []models.BankQuestion) error {
+	if len(questions) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(questions))
+	for i, question := range questions {
+		ids[i] = question.ID
+	}
+
+	var versions []models.BankQuestionVersion
+	err := r.db.Where("bank_question_id IN ?", ids).
+		Where("version = (SELECT current_version FROM bank_questions WHERE bank_questions.id = bank_question_versions.bank_question_id)").
+		Find(&versions).Error
+	if err != nil {
+		return fmt.Errorf("failed to find current versions: %w", err)
+	}
+
+	byQuestion := make(map[uint]*models.BankQuestionVersion, len(versions))
+	for i := range versions {
+		byQuestion[versions[i].BankQuestionID] = &versions[i]
+	}
+	for i := range questions {
+		questions[i].Latest = byQuestion[questions[i].ID]
+	}
+	return nil
+}
+
+// FindVersions returns every version of a bank question, newest first
+func (r *bankRepository) FindVersions(bankQuestionID uint) ([]models.BankQuestionVersion, error) {
+	var versions []models.BankQuestionVersion
+
+	err := r.db.Where("bank_question_id = ?", bankQuestionID).
+		Order("version DESC").
+		Find(&versions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bank question versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+func (r *bankRepository) FindVersion(bankQuestionID uint, version int) (*models.BankQuestionVersion, error) {
+	var found models.BankQuestionVersion
+
+	err := r.db.Where("bank_question_id = ? AND version = ?", bankQuestionID, version).First(&found).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &found, nil
+}
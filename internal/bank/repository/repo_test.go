@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"testing"
+
+	models "assessment_service/internal/model"
+	"assessment_service/internal/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestSQLiteDatabase khởi tạo database SQLite in-memory và trả về *gorm.DB
+func setupTestSQLiteDatabase(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err, "Failed to connect to in-memory SQLite")
+
+	err = db.AutoMigrate(
+		&models.User{},
+		&models.BankQuestion{},
+		&models.BankQuestionVersion{},
+	)
+	require.NoError(t, err, "Failed to run migrations on SQLite")
+
+	return db
+}
+
+func listParams(filters map[string]interface{}) util.PaginationParams {
+	return util.PaginationParams{Limit: 10, SortBy: "created_at", SortDir: "asc", Filters: filters}
+}
+
+// TestBankRepository_SQLite là hàm test chính cho repository với SQLite
+func TestBankRepository_SQLite(t *testing.T) {
+	db := setupTestSQLiteDatabase(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	repo := NewBankRepository(db)
+
+	alice := models.User{Name: "Alice", Email: "alice@test.com", Password: "pw", Role: "teacher", Status: "Active", Department: "Mathematics"}
+	bob := models.User{Name: "Bob", Email: "bob@test.com", Password: "pw", Role: "teacher", Status: "Active", Department: "Physics"}
+	require.NoError(t, db.Create(&alice).Error)
+	require.NoError(t, db.Create(&bob).Error)
+
+	private := &models.BankQuestion{OwnerID: alice.ID, Subject: "Algebra", Topic: "Equations", Difficulty: models.DifficultyEasy, Tags: models.QuestionTags{"linear"}, CurrentVersion: 1}
+	shared := &models.BankQuestion{OwnerID: alice.ID, Department: "Mathematics", Subject: "Geometry", Difficulty: models.DifficultyHard, CurrentVersion: 1}
+	physics := &models.BankQuestion{OwnerID: bob.ID, Department: "Physics", Subject: "Mechanics", CurrentVersion: 1}
+
+	t.Run("TestCreate", func(t *testing.T) {
+		version := &models.BankQuestionVersion{
+			Type: "multiple-choice", Text: "Solve x + 1 = 2", CorrectAnswer: "b", Points: 1, CreatedByID: alice.ID,
+			Options: models.BankOptions{{OptionID: "a", Text: "0"}, {OptionID: "b", Text: "1"}},
+		}
+		require.NoError(t, repo.Create(private, version))
+		assert.NotZero(t, private.ID)
+		assert.Equal(t, private.ID, version.BankQuestionID)
+		assert.Equal(t, 1, version.Version)
+		assert.Same(t, version, private.Latest)
+
+		require.NoError(t, repo.Create(shared, &models.BankQuestionVersion{Type: "true-false", Text: "A square is a rectangle", CorrectAnswer: "true", Points: 1, CreatedByID: alice.ID}))
+		require.NoError(t, repo.Create(physics, &models.BankQuestionVersion{Type: "essay", Text: "Explain inertia", Points: 5, CreatedByID: bob.ID}))
+	})
+
+	t.Run("TestFindByID", func(t *testing.T) {
+		found, err := repo.FindByID(private.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found.Owner)
+		assert.Equal(t, "Alice", found.Owner.Name)
+		assert.Equal(t, models.QuestionTags{"linear"}, found.Tags)
+		require.NotNil(t, found.Latest)
+		assert.Equal(t, "Solve x + 1 = 2", found.Latest.Text)
+		assert.Equal(t, models.BankOptions{{OptionID: "a", Text: "0"}, {OptionID: "b", Text: "1"}}, found.Latest.Options)
+
+		_, err = repo.FindByID(9999)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("TestUpdate", func(t *testing.T) {
+		// Đổi thông tin phân loại không tạo phiên bản mới
+		private.Topic = "Linear equations"
+		require.NoError(t, repo.Update(private, nil))
+
+		private.CurrentVersion = 2
+		version := &models.BankQuestionVersion{Type: "short-answer", Text: "Solve x + 2 = 4", CorrectAnswer: "2", Points: 2, CreatedByID: alice.ID}
+		require.NoError(t, repo.Update(private, version))
+		assert.Equal(t, 2, version.Version)
+
+		found, err := repo.FindByID(private.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Linear equations", found.Topic)
+		assert.Equal(t, "Solve x + 2 = 4", found.Latest.Text)
+
+		versions, err := repo.FindVersions(private.ID)
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		assert.Equal(t, 2, versions[0].Version)
+		assert.Equal(t, 1, versions[1].Version)
+
+		first, err := repo.FindVersion(private.ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Solve x + 1 = 2", first.Text)
+
+		_, err = repo.FindVersion(private.ID, 3)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("TestVersionsAreImmutable", func(t *testing.T) {
+		version, err := repo.FindVersion(private.ID, 1)
+		require.NoError(t, err)
+
+		version.Text = "Changed"
+		assert.ErrorIs(t, db.Save(version).Error, models.ErrBankVersionImmutable)
+		assert.ErrorIs(t, db.Delete(version).Error, models.ErrBankVersionImmutable)
+
+		unchanged, err := repo.FindVersion(private.ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Solve x + 1 = 2", unchanged.Text)
+	})
+
+	t.Run("TestList", func(t *testing.T) {
+		// Admin thấy tất cả câu hỏi
+		questions, total, err := repo.List(listParams(map[string]interface{}{}))
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		require.Len(t, questions, 3)
+		for _, question := range questions {
+			require.NotNil(t, question.Latest)
+			assert.Equal(t, question.CurrentVersion, question.Latest.Version)
+		}
+
+		// Giáo viên thấy câu hỏi của mình và của bộ môn
+		questions, total, err = repo.List(listParams(map[string]interface{}{"visibleTo": bob.ID, "department": "Mathematics"}))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Equal(t, shared.ID, questions[0].ID)
+		assert.Equal(t, physics.ID, questions[1].ID)
+
+		// Người không thuộc bộ môn nào chỉ thấy câu hỏi của mình
+		_, total, err = repo.List(listParams(map[string]interface{}{"visibleTo": bob.ID, "department": ""}))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+
+		tests := []struct {
+			name    string
+			filters map[string]interface{}
+			search  string
+			want    []uint
+		}{
+			{"search current version", nil, "x + 2", []uint{private.ID}},
+			{"older version not searched", nil, "x + 1", nil},
+			{"owner", map[string]interface{}{"owner": bob.ID}, "", []uint{physics.ID}},
+			{"subject", map[string]interface{}{"subject": "Geometry"}, "", []uint{shared.ID}},
+			{"topic", map[string]interface{}{"topic": "Linear equations"}, "", []uint{private.ID}},
+			{"difficulty", map[string]interface{}{"difficulty": "hard"}, "", []uint{shared.ID}},
+			{"tag", map[string]interface{}{"tag": "Linear"}, "", []uint{private.ID}},
+			{"type", map[string]interface{}{"type": "essay"}, "", []uint{physics.ID}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				params := listParams(tt.filters)
+				params.Search = tt.search
+				questions, total, err := repo.List(params)
+				require.NoError(t, err)
+				assert.Equal(t, int64(len(tt.want)), total)
+				var ids []uint
+				for _, question := range questions {
+					ids = append(ids, question.ID)
+				}
+				assert.Equal(t, tt.want, ids)
+			})
+		}
+	})
+
+	t.Run("TestDelete", func(t *testing.T) {
+		require.NoError(t, repo.Delete(physics.ID))
+
+		_, err := repo.FindByID(physics.ID)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		// Các phiên bản vẫn còn cho những bài kiểm tra đã dùng
+		versions, err := repo.FindVersions(physics.ID)
+		require.NoError(t, err)
+		assert.Len(t, versions, 1)
+
+		_, total, err := repo.List(listParams(map[string]interface{}{}))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+	})
+}
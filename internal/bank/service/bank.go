@@ -0,0 +1,344 @@
+package service
+
+import (
+	repository_assessment "assessment_service/internal/assessments/repository"
+	"assessment_service/internal/bank/repository"
+	models "assessment_service/internal/model"
+	repository_question "assessment_service/internal/questions/repository"
+	"assessment_service/internal/questions/types"
+	repository_user "assessment_service/internal/users/repository"
+	"assessment_service/internal/util"
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBankQuestionNotFound = errors.New("bank question not found")
+	ErrBankVersionNotFound  = errors.New("bank question version not found")
+	ErrAssessmentNotFound   = errors.New("assessment not found")
+	ErrUserNotFound         = errors.New("user not found")
+	// ErrInvalidBankQuestion is returned when a bank question's details or content are not valid
+	ErrInvalidBankQuestion = errors.New("invalid bank question")
+	// ErrBankAccessDenied is returned when a teacher uses a question that is neither theirs nor
+	// shared with their department
+	ErrBankAccessDenied = errors.New("bank question is not shared with you")
+)
+
+type BankService interface {
+	CreateQuestion(dto models.BankQuestionDTO, ownerID uint) (*models.BankQuestion, error)
+	GetQuestion(id, userID uint) (*models.BankQuestion, error)
+	ListQuestions(params util.PaginationParams, userID uint) ([]models.BankQuestion, int64, error)
+	UpdateQuestion(id uint, dto models.BankQuestionDTO, userID uint) (*models.BankQuestion, error)
+	DeleteQuestion(id, userID uint) error
+	GetVersions(id, userID uint) ([]models.BankQuestionVersion, error)
+	AddToAssessment(assessmentID uint, dto models.BankImportDTO, userID uint) (*models.Question, error)
+}
+
+type bankService struct {
+	bankRepo       repository.BankRepository
+	userRepo       repository_user.UserRepository
+	assessmentRepo repository_assessment.AssessmentRepository
+	questionRepo   repository_question.QuestionRepository
+	log            *zap.Logger
+}
+
+func NewBankService(
+	bankRepo repository.BankRepository,
+	userRepo repository_user.UserRepository,
+	assessmentRepo repository_assessment.AssessmentRepository,
+	questionRepo repository_question.QuestionRepository,
+	log *zap.Logger,
+) BankService {
+	return &bankService{
+		bankRepo:       bankRepo,
+		userRepo:       userRepo,
+		assessmentRepo: assessmentRepo,
+		questionRepo:   questionRepo,
+		log:            log,
+	}
+}
+
+// CreateQuestion adds a question to the owner's bank, or to their department's bank when the
+// question names their department
+func (s *bankService) CreateQuestion(dto models.BankQuestionDTO, ownerID uint) (*models.BankQuestion, error) {
+	owner, err := s.findUser(ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	question := &models.BankQuestion{OwnerID: ownerID, CurrentVersion: 1}
+	if err := applyDetails(question, dto, owner); err != nil {
+		return nil, err
+	}
+
+	version, err := newVersion(dto, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.bankRepo.Create(question, version); err != nil {
+		s.log.Error("[CreateQuestion] failed to create bank question", zap.Error(err))
+		return nil, err
+	}
+
+	return question, nil
+}
+
+// GetQuestion returns a bank question with its current version
+func (s *bankService) GetQuestion(id, userID uint) (*models.BankQuestion, error) {
+	question, _, err := s.findUsable(id, userID)
+	return question, err
+}
+
+// ListQuestions searches the questions a user may use. Admins see every bank.
+func (s *bankService) ListQuestions(params util.PaginationParams, userID uint) ([]models.BankQuestion, int64, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if user.Role != "admin" {
+		params.Filters["visibleTo"] = user.ID
+		params.Filters["department"] = user.Department
+	}
+
+	questions, total, err := s.bankRepo.List(params)
+	if err != nil {
+		s.log.Error("[ListQuestions] failed to list bank questions", zap.Error(err))
+		return nil, 0, err
+	}
+
+	return questions, total, nil
+}
+
+// UpdateQuestion changes a bank question. When its content changed it moves to a new version;
+// assessments keep the version they were given.
+func (s *bankService) UpdateQuestion(id uint, dto models.BankQuestionDTO, userID uint) (*models.BankQuestion, error) {
+	question, user, err := s.findUsable(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyDetails(question, dto, user); err != nil {
+		return nil, err
+	}
+
+	version, err := newVersion(dto, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if sameContent(question.Latest, version) {
+		version = nil
+	} else {
+		question.CurrentVersion++
+	}
+
+	if err := s.bankRepo.Update(question, version); err != nil {
+		s.log.Error("[UpdateQuestion] failed to update bank question", zap.Error(err))
+		return nil, err
+	}
+
+	return question, nil
+}
+
+// DeleteQuestion removes a question from the bank. Assessments keep their copies.
+func (s *bankService) DeleteQuestion(id, userID uint) error {
+	if _, _, err := s.findUsable(id, userID); err != nil {
+		return err
+	}
+
+	if err := s.bankRepo.Delete(id); err != nil {
+		s.log.Error("[DeleteQuestion] failed to delete bank question", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetVersions returns every version of a bank question, newest first
+func (s *bankService) GetVersions(id, userID uint) ([]models.BankQuestionVersion, error) {
+	if _, _, err := s.findUsable(id, userID); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.bankRepo.FindVersions(id)
+	if err != nil {
+		s.log.Error("[GetVersions] failed to find versions", zap.Error(err))
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// AddToAssessment copies a version of a bank question into an assessment. The copy remembers the
+// version, and later edits in the bank leave it as it is.
+func (s *bankService) AddToAssessment(assessmentID uint, dto models.BankImportDTO, userID uint) (*models.Question, error) {
+	if _, err := s.assessmentRepo.FindByID(assessmentID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAssessmentNotFound
+		}
+		s.log.Error("[AddToAssessment] failed to find assessment", zap.Error(err))
+		return nil, err
+	}
+
+	bankQuestion, _, err := s.findUsable(dto.BankQuestionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	version := bankQuestion.Latest
+	if dto.Version != nil && *dto.Version != bankQuestion.CurrentVersion {
+		version, err = s.bankRepo.FindVersion(bankQuestion.ID, *dto.Version)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrBankVersionNotFound
+			}
+			s.log.Error("[AddToAssessment] failed to find version", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	question := version.Question(assessmentID)
+	question.Topic = bankQuestion.Topic
+	question.Difficulty = bankQuestion.Difficulty
+	question.Tags = bankQuestion.Tags
+	if err := s.questionRepo.Create(&question); err != nil {
+		s.log.Error("[AddToAssessment] failed to create question", zap.Error(err))
+		return nil, err
+	}
+
+	return s.questionRepo.FindByID(question.ID)
+}
+
+// findUsable loads a bank question the user may use: their own, one of their department's, or any
+// question for admins
+func (s *bankService) findUsable(id, userID uint) (*models.BankQuestion, *models.User, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	question, err := s.bankRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrBankQuestionNotFound
+		}
+		s.log.Error("failed to find bank question", zap.Error(err))
+		return nil, nil, err
+	}
+
+	shared := question.Department != "" && question.Department == user.Department
+	if user.Role != "admin" && question.OwnerID != user.ID && !shared {
+		return nil, nil, ErrBankAccessDenied
+	}
+
+	return question, user, nil
+}
+
+func (s *bankService) findUser(id uint) (*models.User, error) {
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		s.log.Error("failed to find user", zap.Error(err))
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// applyDetails sets how a bank question is filed. Teachers can only share questions with their own
+// department.
+func applyDetails(question *models.BankQuestion, dto models.BankQuestionDTO, user *models.User) error {
+	department := strings.TrimSpace(dto.Department)
+	if department != "" && department != question.Department && department != user.Department && user.Role != "admin" {
+		return fmt.Errorf("%w: questions can only be shared with your own department", ErrInvalidBankQuestion)
+	}
+
+	difficulty := models.QuestionDifficulty(strings.ToLower(strings.TrimSpace(string(dto.Difficulty))))
+	if !difficulty.Valid() {
+		return fmt.Errorf("%w: difficulty must be easy, medium or hard", ErrInvalidBankQuestion)
+	}
+
+	question.Department = department
+	question.Subject = strings.TrimSpace(dto.Subject)
+	question.Topic = strings.TrimSpace(dto.Topic)
+	question.Difficulty = difficulty
+	question.Tags = models.QuestionTags(dto.Tags).Normalized()
+	return nil
+}
+
+// newVersion builds the content of a bank question from a teacher's input and checks it against the
+// question type and scoring rules
+func newVersion(dto models.BankQuestionDTO, createdByID uint) (*models.BankQuestionVersion, error) {
+	if strings.TrimSpace(dto.Text) == "" {
+		return nil, fmt.Errorf("%w: text is required", ErrInvalidBankQuestion)
+	}
+
+	var correctAnswer string
+	switch v := dto.CorrectAnswer.(type) {
+	case nil:
+	case string:
+		correctAnswer = v
+	case bool:
+		// true-false answers may be sent as a boolean
+		correctAnswer = strconv.FormatBool(v)
+	default:
+		return nil, fmt.Errorf("%w: correct answer must be a string", types.ErrInvalidDefinition)
+	}
+
+	version := &models.BankQuestionVersion{
+		Type:          dto.Type,
+		Text:          dto.Text,
+		Options:       dto.Options,
+		CorrectAnswer: correctAnswer,
+		Config:        dto.Config,
+		Points:        dto.Points,
+		PartialCredit: dto.PartialCredit,
+		Penalty:       dto.Penalty,
+		AllowNegative: dto.AllowNegative,
+		CreatedByID:   createdByID,
+	}
+
+	// Validate the content as the question assessments will get, and keep what validation tidied up
+	question := version.Question(0)
+	if err := types.Validate(&question); err != nil {
+		return nil, err
+	}
+
+	version.Options = nil
+	for _, option := range question.Options {
+		version.Options = append(version.Options, models.BankOption{OptionID: option.OptionID, Text: option.Text})
+	}
+	version.CorrectAnswer = question.CorrectAnswer
+	version.Config = question.Config
+	return version, nil
+}
+
+// sameContent reports whether a new version would have the same content as the current one
+func sameContent(current, next *models.BankQuestionVersion) bool {
+	if current == nil {
+		return false
+	}
+	if current.Type != next.Type || current.Text != next.Text || current.CorrectAnswer != next.CorrectAnswer ||
+		current.Points != next.Points || current.PartialCredit != next.PartialCredit ||
+		current.Penalty != next.Penalty || current.AllowNegative != next.AllowNegative ||
+		!bytes.Equal(current.Config, next.Config) || len(current.Options) != len(next.Options) {
+		return false
+	}
+
+	for i := range current.Options {
+		if current.Options[i] != next.Options[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,650 @@
+package service
+
+import (
+	models "assessment_service/internal/model"
+	repository_question "assessment_service/internal/questions/repository"
+	"assessment_service/internal/questions/types"
+	"assessment_service/internal/util"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+// --- Mock BankRepository ---
+type MockBankRepository struct {
+	mock.Mock
+}
+
+func (m *MockBankRepository) Create(question *models.BankQuestion, version *models.BankQuestionVersion) error {
+	args := m.Called(question, version)
+	return args.Error(0)
+}
+
+func (m *MockBankRepository) FindByID(id uint) (*models.BankQuestion, error) {
+	args := m.Called(id)
+	question, _ := args.Get(0).(*models.BankQuestion)
+	return question, args.Error(1)
+}
+
+func (m *MockBankRepository) Update(question *models.BankQuestion, version *models.BankQuestionVersion) error {
+	args := m.Called(question, version)
+	return args.Error(0)
+}
+
+func (m *MockBankRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockBankRepository) List(params util.PaginationParams) ([]models.BankQuestion, int64, error) {
+	args := m.Called(params)
+	questions, _ := args.Get(0).([]models.BankQuestion)
+	count, _ := args.Get(1).(int64)
+	return questions, count, args.Error(2)
+}
+
+func (m *MockBankRepository) FindVersions(bankQuestionID uint) ([]models.BankQuestionVersion, error) {
+	args := m.Called(bankQuestionID)
+	versions, _ := args.Get(0).([]models.BankQuestionVersion)
+	return versions, args.Error(1)
+}
+
+func (m *MockBankRepository) FindVersion(bankQuestionID uint, version int) (*models.BankQuestionVersion, error) {
+	args := m.Called(bankQuestionID, version)
+	found, _ := args.Get(0).(*models.BankQuestionVersion)
+	return found, args.Error(1)
+}
+
+// --- Mock QuestionRepository ---
+type MockQuestionRepository struct {
+	mock.Mock
+}
+
+func (m *MockQuestionRepository) Create(question *models.Question) error {
+	args := m.Called(question)
+	return args.Error(0)
+}
+func (m *MockQuestionRepository) FindByID(id uint) (*models.Question, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Question), args.Error(1)
+}
+func (m *MockQuestionRepository) FindByAssessmentID(assessmentID uint) ([]models.Question, error) {
+	args := m.Called(assessmentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Question), args.Error(1)
+}
+func (m *MockQuestionRepository) Update(question *models.Question) error {
+	args := m.Called(question)
+	return args.Error(0)
+}
+func (m *MockQuestionRepository) Delete(id uint) error { args := m.Called(id); return args.Error(0) }
+func (m *MockQuestionRepository) AddOption(option *models.QuestionOption) error {
+	args := m.Called(option)
+	return args.Error(0)
+}
+func (m *MockQuestionRepository) UpdateOption(option *models.QuestionOption) error {
+	args := m.Called(option)
+	return args.Error(0)
+}
+func (m *MockQuestionRepository) DeleteOption(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockQuestionRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return fn(nil)
+}
+
+func (m *MockQuestionRepository) WithTx(tx *gorm.DB) repository_question.QuestionRepository {
+	return m
+}
+
+// --- Mock AssessmentRepository ---
+type MockAssessmentRepository struct {
+	mock.Mock
+}
+
+// Implement AssessmentRepository interface for mock
+func (m *MockAssessmentRepository) Create(assessment *models.Assessment) error {
+	args := m.Called(assessment)
+	return args.Error(0)
+}
+
+func (m *MockAssessmentRepository) FindByID(id uint) (*models.Assessment, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	// Ensure the returned object is of the correct type
+	assessment, ok := args.Get(0).(*models.Assessment)
+	if !ok && args.Get(0) != nil {
+		// Handle cases where the mock might return something unexpected but not nil
+		panic("Mock FindByID returned non-nil value of incorrect type")
+	}
+	return assessment, args.Error(1)
+}
+
+func (m *MockAssessmentRepository) Update(assessment *models.Assessment) error {
+	args := m.Called(assessment)
+	return args.Error(0)
+}
+
+func (m *MockAssessmentRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockAssessmentRepository) List(params util.PaginationParams) ([]models.Assessment, int64, error) {
+	args := m.Called(params)
+	// Ensure correct type assertion for slice
+	assessments, ok := args.Get(0).([]models.Assessment)
+	if !ok && args.Get(0) != nil {
+		panic("Mock List returned non-nil value of incorrect type for assessments")
+	}
+	// Ensure correct type assertion for int64
+	count, ok := args.Get(1).(int64)
+	if !ok {
+		// Try converting from int if necessary, though int64 is expected
+		if intCount, okInt := args.Get(1).(int); okInt {
+			count = int64(intCount)
+		} else {
+			panic("Mock List returned value of incorrect type for count")
+		}
+	}
+	return assessments, count, args.Error(2)
+}
+
+func (m *MockAssessmentRepository) FindRecent(limit int) ([]models.Assessment, error) {
+	args := m.Called(limit)
+	assessments, ok := args.Get(0).([]models.Assessment)
+	if !ok && args.Get(0) != nil {
+		panic("Mock FindRecent returned non-nil value of incorrect type")
+	}
+	return assessments, args.Error(1)
+}
+
+func (m *MockAssessmentRepository) GetStatistics() (map[string]interface{}, error) {
+	args := m.Called()
+	stats, ok := args.Get(0).(map[string]interface{})
+	if !ok && args.Get(0) != nil {
+		panic("Mock GetStatistics returned non-nil value of incorrect type")
+	}
+	return stats, args.Error(1)
+}
+
+func (m *MockAssessmentRepository) UpdateSettings(id uint, settings *models.AssessmentSettings) error {
+	args := m.Called(id, settings)
+	return args.Error(0)
+}
+
+func (m *MockAssessmentRepository) GetResults(id uint, params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	args := m.Called(id, params)
+	results, ok := args.Get(0).([]map[string]interface{})
+	if !ok && args.Get(0) != nil {
+		panic("Mock GetResults returned non-nil value of incorrect type for results")
+	}
+	count, ok := args.Get(1).(int64)
+	if !ok {
+		if intCount, okInt := args.Get(1).(int); okInt {
+			count = int64(intCount)
+		} else {
+			panic("Mock GetResults returned value of incorrect type for count")
+		}
+	}
+	return results, count, args.Error(2)
+}
+
+func (m *MockAssessmentRepository) Duplicate(assessment *models.Assessment) error {
+	args := m.Called(assessment)
+	return args.Error(0)
+}
+
+func (m *MockAssessmentRepository) GetAssessmentHasAttemptByUser(params util.PaginationParams, userID uint) ([]models.Assessment, int64, error) {
+	args := m.Called(params, userID)
+	assessments, ok := args.Get(0).([]models.Assessment)
+	if !ok && args.Get(0) != nil {
+		panic("Mock GetAssessmentHasAttemptByUser returned non-nil value of incorrect type for assessments")
+	}
+	count, ok := args.Get(1).(int64)
+	if !ok {
+		if intCount, okInt := args.Get(1).(int); okInt {
+			count = int64(intCount)
+		} else {
+			panic("Mock GetAssessmentHasAttemptByUser returned value of incorrect type for count")
+		}
+	}
+	return assessments, count, args.Error(2)
+}
+
+func (m *MockAssessmentRepository) UpdateStatus(transition *models.AssessmentStatusTransition) (bool, error) {
+	args := m.Called(transition)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAssessmentRepository) ListStatusHistory(assessmentID uint) ([]models.AssessmentStatusTransition, error) {
+	args := m.Called(assessmentID)
+	transitions, _ := args.Get(0).([]models.AssessmentStatusTransition)
+	return transitions, args.Error(1)
+}
+
+func (m *MockAssessmentRepository) ActivateScheduled(now time.Time) (int64, error) {
+	args := m.Called(now)
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
+func (m *MockAssessmentRepository) CloseEnded(now time.Time) (int64, error) {
+	args := m.Called(now)
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
+// --- Mock UserRepository ---
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) Create(user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindByID(id uint) (*models.User, error) {
+	args := m.Called(id)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserRepository) FindByEmail(email string) (*models.User, error) {
+	args := m.Called(email)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserRepository) Update(user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) List(params util.PaginationParams) ([]models.User, int64, error) {
+	args := m.Called(params)
+	users, _ := args.Get(0).([]models.User)
+	count, _ := args.Get(1).(int64)
+	return users, count, args.Error(2)
+}
+
+func (m *MockUserRepository) UpdateLastLogin(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUserStats() (int64, int64, error) {
+	args := m.Called()
+	active, _ := args.Get(0).(int64)
+	inactive, _ := args.Get(1).(int64)
+	return active, inactive, args.Error(2)
+}
+
+func (m *MockUserRepository) CountAll() (int64, error) {
+	args := m.Called()
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
+func (m *MockUserRepository) GetNewUsersCount(days int) (int64, error) {
+	args := m.Called(days)
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
+func (m *MockUserRepository) GetListUserByAssessment(params util.PaginationParams, assessmentID uint) ([]models.User, int64, error) {
+	args := m.Called(params, assessmentID)
+	users, _ := args.Get(0).([]models.User)
+	count, _ := args.Get(1).(int64)
+	return users, count, args.Error(2)
+}
+
+type testBankService struct {
+	BankService
+	bankRepo       *MockBankRepository
+	userRepo       *MockUserRepository
+	assessmentRepo *MockAssessmentRepository
+	questionRepo   *MockQuestionRepository
+}
+
+func newTestBankService(t *testing.T) testBankService {
+	bankRepo := new(MockBankRepository)
+	userRepo := new(MockUserRepository)
+	assessmentRepo := new(MockAssessmentRepository)
+	questionRepo := new(MockQuestionRepository)
+	return testBankService{
+		BankService:    NewBankService(bankRepo, userRepo, assessmentRepo, questionRepo, zaptest.NewLogger(t)),
+		bankRepo:       bankRepo,
+		userRepo:       userRepo,
+		assessmentRepo: assessmentRepo,
+		questionRepo:   questionRepo,
+	}
+}
+
+var (
+	mathTeacher    = &models.User{ID: 5, Role: "teacher", Department: "Mathematics"}
+	mathColleague  = &models.User{ID: 6, Role: "teacher", Department: "Mathematics"}
+	physicsTeacher = &models.User{ID: 7, Role: "teacher", Department: "Physics"}
+	admin          = &models.User{ID: 1, Role: "admin"}
+)
+
+func choiceDTO() models.BankQuestionDTO {
+	return models.BankQuestionDTO{
+		Department:    "Mathematics",
+		Subject:       " Algebra ",
+		Difficulty:    "Easy",
+		Tags:          []string{"Linear", " linear ", ""},
+		Type:          "multiple-choice",
+		Text:          "Solve x + 1 = 2",
+		Options:       []models.BankOption{{OptionID: "a", Text: "0"}, {OptionID: "b", Text: "1"}},
+		CorrectAnswer: "b",
+		Points:        1,
+	}
+}
+
+// bankQuestion là câu hỏi của bộ môn Toán ở phiên bản 2
+func bankQuestion() *models.BankQuestion {
+	return &models.BankQuestion{
+		ID: 3, OwnerID: 5, Department: "Mathematics", Subject: "Algebra", Difficulty: models.DifficultyEasy,
+		Tags: models.QuestionTags{"linear"}, CurrentVersion: 2,
+		Latest: &models.BankQuestionVersion{
+			BankQuestionID: 3, Version: 2, Type: "multiple-choice", Text: "Solve x + 1 = 2", CorrectAnswer: "b", Points: 1,
+			Options: models.BankOptions{{OptionID: "a", Text: "0"}, {OptionID: "b", Text: "1"}},
+		},
+	}
+}
+
+// --- Test Cases ---
+
+func TestBankService_CreateQuestion(t *testing.T) {
+	svc := newTestBankService(t)
+
+	svc.userRepo.On("FindByID", uint(5)).Return(mathTeacher, nil)
+	svc.bankRepo.On("Create", mock.MatchedBy(func(q *models.BankQuestion) bool {
+		return q.OwnerID == 5 && q.CurrentVersion == 1 && q.Department == "Mathematics" && q.Subject == "Algebra" &&
+			q.Difficulty == models.DifficultyEasy && assert.ObjectsAreEqual(models.QuestionTags{"linear"}, q.Tags)
+	}), mock.MatchedBy(func(v *models.BankQuestionVersion) bool {
+		return v.Text == "Solve x + 1 = 2" && v.CorrectAnswer == "b" && len(v.Options) == 2 && v.CreatedByID == 5
+	})).Return(nil)
+
+	question, err := svc.CreateQuestion(choiceDTO(), 5)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Algebra", question.Subject)
+	svc.bankRepo.AssertExpectations(t)
+}
+
+func TestBankService_CreateQuestion_TrueFalse(t *testing.T) {
+	svc := newTestBankService(t)
+
+	svc.userRepo.On("FindByID", uint(5)).Return(mathTeacher, nil)
+	svc.bankRepo.On("Create", mock.Anything, mock.MatchedBy(func(v *models.BankQuestionVersion) bool {
+		// Câu đúng/sai không giữ lựa chọn nào
+		return v.CorrectAnswer == "true" && v.Options == nil
+	})).Return(nil)
+
+	_, err := svc.CreateQuestion(models.BankQuestionDTO{
+		Type: "true-false", Text: "A square is a rectangle", CorrectAnswer: true, Points: 1,
+		Options: []models.BankOption{{OptionID: "a", Text: "ignored"}},
+	}, 5)
+
+	require.NoError(t, err)
+	svc.bankRepo.AssertExpectations(t)
+}
+
+func TestBankService_CreateQuestion_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(dto *models.BankQuestionDTO)
+		err    error
+	}{
+		{"other department", func(dto *models.BankQuestionDTO) { dto.Department = "Physics" }, ErrInvalidBankQuestion},
+		{"unknown difficulty", func(dto *models.BankQuestionDTO) { dto.Difficulty = "extreme" }, ErrInvalidBankQuestion},
+		{"no text", func(dto *models.BankQuestionDTO) { dto.Text = " " }, ErrInvalidBankQuestion},
+		{"unknown type", func(dto *models.BankQuestionDTO) { dto.Type = "drawing" }, types.ErrUnknownType},
+		{"wrong correct answer", func(dto *models.BankQuestionDTO) { dto.CorrectAnswer = "c" }, types.ErrInvalidDefinition},
+		{"correct answer not a string", func(dto *models.BankQuestionDTO) { dto.CorrectAnswer = 2.0 }, types.ErrInvalidDefinition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestBankService(t)
+			svc.userRepo.On("FindByID", uint(5)).Return(mathTeacher, nil)
+
+			dto := choiceDTO()
+			tt.modify(&dto)
+			_, err := svc.CreateQuestion(dto, 5)
+
+			assert.ErrorIs(t, err, tt.err)
+			svc.bankRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestBankService_ListQuestions(t *testing.T) {
+	svc := newTestBankService(t)
+
+	svc.userRepo.On("FindByID", uint(7)).Return(physicsTeacher, nil)
+	svc.userRepo.On("FindByID", uint(1)).Return(admin, nil)
+	svc.bankRepo.On("List", mock.MatchedBy(func(p util.PaginationParams) bool {
+		return p.Filters["visibleTo"] == uint(7) && p.Filters["department"] == "Physics"
+	})).Return([]models.BankQuestion{{ID: 4}}, int64(1), nil).Once()
+	svc.bankRepo.On("List", mock.MatchedBy(func(p util.PaginationParams) bool {
+		_, filtered := p.Filters["visibleTo"]
+		return !filtered
+	})).Return([]models.BankQuestion{{ID: 3}, {ID: 4}}, int64(2), nil).Once()
+
+	// Giáo viên chỉ thấy câu hỏi của mình và của bộ môn
+	questions, total, err := svc.ListQuestions(util.PaginationParams{Filters: map[string]interface{}{}}, 7)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, questions, 1)
+
+	// Admin thấy mọi ngân hàng câu hỏi
+	_, total, err = svc.ListQuestions(util.PaginationParams{Filters: map[string]interface{}{}}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	svc.bankRepo.AssertExpectations(t)
+}
+
+func TestBankService_GetQuestion_Access(t *testing.T) {
+	tests := []struct {
+		name string
+		user *models.User
+		err  error
+	}{
+		{"owner", mathTeacher, nil},
+		{"same department", mathColleague, nil},
+		{"admin", admin, nil},
+		{"other department", physicsTeacher, ErrBankAccessDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestBankService(t)
+			svc.userRepo.On("FindByID", tt.user.ID).Return(tt.user, nil)
+			svc.bankRepo.On("FindByID", uint(3)).Return(bankQuestion(), nil)
+
+			_, err := svc.GetQuestion(3, tt.user.ID)
+
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+
+	t.Run("private question", func(t *testing.T) {
+		svc := newTestBankService(t)
+		private := bankQuestion()
+		private.Department = ""
+		svc.userRepo.On("FindByID", uint(6)).Return(mathColleague, nil)
+		svc.bankRepo.On("FindByID", uint(3)).Return(private, nil)
+
+		_, err := svc.GetQuestion(3, 6)
+
+		assert.ErrorIs(t, err, ErrBankAccessDenied)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		svc := newTestBankService(t)
+		svc.userRepo.On("FindByID", uint(5)).Return(mathTeacher, nil)
+		svc.bankRepo.On("FindByID", uint(3)).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := svc.GetQuestion(3, 5)
+
+		assert.ErrorIs(t, err, ErrBankQuestionNotFound)
+	})
+}
+
+func TestBankService_UpdateQuestion_NewVersion(t *testing.T) {
+	svc := newTestBankService(t)
+
+	dto := choiceDTO()
+	dto.Text = "Solve x + 2 = 3"
+	svc.userRepo.On("FindByID", uint(6)).Return(mathColleague, nil)
+	svc.bankRepo.On("FindByID", uint(3)).Return(bankQuestion(), nil)
+	svc.bankRepo.On("Update", mock.MatchedBy(func(q *models.BankQuestion) bool {
+		return q.CurrentVersion == 3
+	}), mock.MatchedBy(func(v *models.BankQuestionVersion) bool {
+		return v != nil && v.Text == "Solve x + 2 = 3" && v.CreatedByID == 6
+	})).Return(nil)
+
+	question, err := svc.UpdateQuestion(3, dto, 6)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, question.CurrentVersion)
+	svc.bankRepo.AssertExpectations(t)
+}
+
+func TestBankService_UpdateQuestion_SameContent(t *testing.T) {
+	svc := newTestBankService(t)
+
+	// Chỉ đổi chủ đề thì không tạo phiên bản mới
+	dto := choiceDTO()
+	dto.Topic = "Equations"
+	svc.userRepo.On("FindByID", uint(5)).Return(mathTeacher, nil)
+	svc.bankRepo.On("FindByID", uint(3)).Return(bankQuestion(), nil)
+	svc.bankRepo.On("Update", mock.MatchedBy(func(q *models.BankQuestion) bool {
+		return q.CurrentVersion == 2 && q.Topic == "Equations"
+	}), (*models.BankQuestionVersion)(nil)).Return(nil)
+
+	question, err := svc.UpdateQuestion(3, dto, 5)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, question.CurrentVersion)
+	svc.bankRepo.AssertExpectations(t)
+}
+
+func TestBankService_DeleteQuestion_AccessDenied(t *testing.T) {
+	svc := newTestBankService(t)
+
+	svc.userRepo.On("FindByID", uint(7)).Return(physicsTeacher, nil)
+	svc.bankRepo.On("FindByID", uint(3)).Return(bankQuestion(), nil)
+
+	err := svc.DeleteQuestion(3, 7)
+
+	assert.ErrorIs(t, err, ErrBankAccessDenied)
+	svc.bankRepo.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestBankService_AddToAssessment(t *testing.T) {
+	svc := newTestBankService(t)
+
+	svc.assessmentRepo.On("FindByID", uint(9)).Return(&models.Assessment{ID: 9}, nil)
+	svc.userRepo.On("FindByID", uint(6)).Return(mathColleague, nil)
+	svc.bankRepo.On("FindByID", uint(3)).Return(bankQuestion(), nil)
+	svc.questionRepo.On("Create", mock.MatchedBy(func(q *models.Question) bool {
+		return q.AssessmentID == 9 && *q.BankQuestionID == 3 && *q.BankVersion == 2 && q.Text == "Solve x + 1 = 2" && len(q.Options) == 2
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Question).ID = 40
+	}).Return(nil)
+	svc.questionRepo.On("FindByID", uint(40)).Return(&models.Question{ID: 40, AssessmentID: 9}, nil)
+
+	question, err := svc.AddToAssessment(9, models.BankImportDTO{BankQuestionID: 3}, 6)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(40), question.ID)
+	svc.bankRepo.AssertNotCalled(t, "FindVersion", mock.Anything, mock.Anything)
+	svc.questionRepo.AssertExpectations(t)
+}
+
+func TestBankService_AddToAssessment_OlderVersion(t *testing.T) {
+	svc := newTestBankService(t)
+
+	version := 1
+	svc.assessmentRepo.On("FindByID", uint(9)).Return(&models.Assessment{ID: 9}, nil)
+	svc.userRepo.On("FindByID", uint(5)).Return(mathTeacher, nil)
+	svc.bankRepo.On("FindByID", uint(3)).Return(bankQuestion(), nil)
+	svc.bankRepo.On("FindVersion", uint(3), 1).Return(&models.BankQuestionVersion{
+		BankQuestionID: 3, Version: 1, Type: "true-false", Text: "1 + 1 = 2", CorrectAnswer: "true", Points: 1,
+	}, nil)
+	svc.questionRepo.On("Create", mock.MatchedBy(func(q *models.Question) bool {
+		return *q.BankVersion == 1 && q.Type == "true-false"
+	})).Return(nil)
+	svc.questionRepo.On("FindByID", uint(0)).Return(&models.Question{AssessmentID: 9}, nil)
+
+	_, err := svc.AddToAssessment(9, models.BankImportDTO{BankQuestionID: 3, Version: &version}, 5)
+
+	require.NoError(t, err)
+	svc.questionRepo.AssertExpectations(t)
+}
+
+func TestBankService_AddToAssessment_Errors(t *testing.T) {
+	t.Run("assessment not found", func(t *testing.T) {
+		svc := newTestBankService(t)
+		svc.assessmentRepo.On("FindByID", uint(9)).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := svc.AddToAssessment(9, models.BankImportDTO{BankQuestionID: 3}, 5)
+
+		assert.ErrorIs(t, err, ErrAssessmentNotFound)
+	})
+
+	t.Run("version not found", func(t *testing.T) {
+		svc := newTestBankService(t)
+		version := 7
+		svc.assessmentRepo.On("FindByID", uint(9)).Return(&models.Assessment{ID: 9}, nil)
+		svc.userRepo.On("FindByID", uint(5)).Return(mathTeacher, nil)
+		svc.bankRepo.On("FindByID", uint(3)).Return(bankQuestion(), nil)
+		svc.bankRepo.On("FindVersion", uint(3), 7).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := svc.AddToAssessment(9, models.BankImportDTO{BankQuestionID: 3, Version: &version}, 5)
+
+		assert.ErrorIs(t, err, ErrBankVersionNotFound)
+		svc.questionRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("create error", func(t *testing.T) {
+		svc := newTestBankService(t)
+		svc.assessmentRepo.On("FindByID", uint(9)).Return(&models.Assessment{ID: 9}, nil)
+		svc.userRepo.On("FindByID", uint(5)).Return(mathTeacher, nil)
+		svc.bankRepo.On("FindByID", uint(3)).Return(bankQuestion(), nil)
+		svc.questionRepo.On("Create", mock.Anything).Return(errors.New("db error"))
+
+		_, err := svc.AddToAssessment(9, models.BankImportDTO{BankQuestionID: 3}, 5)
+
+		assert.EqualError(t, err, "db error")
+	})
+}
@@ -0,0 +1,23 @@
+package cronjob
+
+import (
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+// StartStatusUpdates keeps assessment statuses in line with their availability windows
+func (c *CronJobService) StartStatusUpdates() {
+	job, err := c.cron.AddJob("* * * * *", cron.FuncJob(func() {
+		if err := c.assessment.UpdateScheduledStatuses(); err != nil {
+			c.log.Error("Failed to run assessment status job", zap.Error(err))
+		}
+	}))
+	if err != nil {
+		c.log.Error("Failed to schedule assessment status job", zap.Error(err))
+		return
+	}
+
+	c.cron.Start()
+	c.log.Info(fmt.Sprintf("Assessment status job started with ID: %d", job))
+}
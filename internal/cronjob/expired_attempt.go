@@ -1,6 +1,7 @@
 package cronjob
 
 import (
+	service2 "assessment_service/internal/assessments/service"
 	"assessment_service/internal/student/service"
 	"fmt"
 	"github.com/robfig/cron/v3"
@@ -8,13 +9,14 @@ import (
 )
 
 type CronJobService struct {
-	student service.StudentService
-	log     *zap.Logger
-	cron    *cron.Cron
+	student    service.StudentService
+	assessment service2.AssessmentService
+	log        *zap.Logger
+	cron       *cron.Cron
 }
 
-func NewCronJobService(student service.StudentService, log *zap.Logger, cron *cron.Cron) *CronJobService {
-	return &CronJobService{student: student, log: log, cron: cron}
+func NewCronJobService(student service.StudentService, assessment service2.AssessmentService, log *zap.Logger, cron *cron.Cron) *CronJobService {
+	return &CronJobService{student: student, assessment: assessment, log: log, cron: cron}
 }
 
 func (c *CronJobService) StartAutoSubmit() {
@@ -0,0 +1,258 @@
+package rest
+
+import (
+	"assessment_service/internal/groups/service"
+	"assessment_service/internal/middleware"
+	models "assessment_service/internal/model"
+	"assessment_service/internal/util"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type GroupHandler struct {
+	groupService service.GroupService
+	log          *zap.Logger
+}
+
+func NewGroupHandler(groupService service.GroupService, log *zap.Logger) *GroupHandler {
+	return &GroupHandler{groupService: groupService, log: log}
+}
+
+func (h *GroupHandler) ListGroups(w http.ResponseWriter, r *http.Request) {
+	params := util.GetPaginationParams(r)
+
+	if createdBy := r.URL.Query().Get("createdBy"); createdBy != "" {
+		if id, err := strconv.ParseUint(createdBy, 10, 32); err == nil {
+			params.Filters["createdBy"] = uint(id)
+		}
+	}
+
+	groups, total, err := h.groupService.ListGroups(params)
+	if err != nil {
+		h.writeError(w, "ListGroups", err, "Failed to list groups")
+		return
+	}
+
+	util.ResponseInterface(w, util.CreatePaginationResponse(groups, total, params), http.StatusOK)
+}
+
+func (h *GroupHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	principal, ok := h.principal(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.GroupDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("[CreateGroup] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	group, err := h.groupService.CreateGroup(req, principal.UserID)
+	if err != nil {
+		h.writeError(w, "CreateGroup", err, "Failed to create group")
+		return
+	}
+
+	util.ResponseInterface(w, group, http.StatusCreated)
+}
+
+func (h *GroupHandler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.parseID(w, r, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	group, err := h.groupService.GetGroup(id)
+	if err != nil {
+		h.writeError(w, "GetGroup", err, "Failed to get group")
+		return
+	}
+
+	util.ResponseInterface(w, group, http.StatusOK)
+}
+
+func (h *GroupHandler) UpdateGroup(w http.ResponseWriter, r *http.Request) {
+	principal, ok := h.principal(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := h.parseID(w, r, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	var req models.GroupDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("[UpdateGroup] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	group, err := h.groupService.UpdateGroup(id, req, principal.UserID)
+	if err != nil {
+		h.writeError(w, "UpdateGroup", err, "Failed to update group")
+		return
+	}
+
+	util.ResponseInterface(w, group, http.StatusOK)
+}
+
+func (h *GroupHandler) DeleteGroup(w http.ResponseWriter, r *http.Request) {
+	principal, ok := h.principal(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := h.parseID(w, r, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	if err := h.groupService.DeleteGroup(id, principal.UserID); err != nil {
+		h.writeError(w, "DeleteGroup", err, "Failed to delete group")
+		return
+	}
+
+	util.ResponseMap(w, map[string]interface{}{
+		"message": "Group deleted successfully",
+	}, http.StatusOK)
+}
+
+func (h *GroupHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.parseID(w, r, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	params := util.GetPaginationParams(r)
+
+	users, total, err := h.groupService.ListMembers(id, params)
+	if err != nil {
+		h.writeError(w, "ListMembers", err, "Failed to list group members")
+		return
+	}
+
+	util.ResponseInterface(w, util.CreatePaginationResponse(users, total, params), http.StatusOK)
+}
+
+// EnrollMembers adds one or many students to the group in a single request
+func (h *GroupHandler) EnrollMembers(w http.ResponseWriter, r *http.Request) {
+	principal, ok := h.principal(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := h.parseID(w, r, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	var req models.EnrollmentDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("[EnrollMembers] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.groupService.EnrollMembers(id, req, principal.UserID)
+	if err != nil {
+		h.writeError(w, "EnrollMembers", err, "Failed to enroll members")
+		return
+	}
+
+	util.ResponseInterface(w, result, http.StatusOK)
+}
+
+func (h *GroupHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	principal, ok := h.principal(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := h.parseID(w, r, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	userID, ok := h.parseID(w, r, "userId", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	if err := h.groupService.RemoveMember(id, userID, principal.UserID); err != nil {
+		h.writeError(w, "RemoveMember", err, "Failed to remove member")
+		return
+	}
+
+	util.ResponseMap(w, map[string]interface{}{
+		"message": "Member removed successfully",
+	}, http.StatusOK)
+}
+
+func (h *GroupHandler) principal(w http.ResponseWriter, r *http.Request) (*middleware.Principal, bool) {
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "UNAUTHORIZED",
+			"message": "Unauthorized",
+		}, http.StatusUnauthorized)
+		return nil, false
+	}
+	return principal, true
+}
+
+func (h *GroupHandler) parseID(w http.ResponseWriter, r *http.Request, name, message string) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)[name], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": message,
+		}, http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeError maps service errors to HTTP responses
+func (h *GroupHandler) writeError(w http.ResponseWriter, fn string, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrGroupNotFound), errors.Is(err, service.ErrMemberNotFound):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "NOT_FOUND",
+			"message": err.Error(),
+		}, http.StatusNotFound)
+	case errors.Is(err, service.ErrGroupNameRequired), errors.Is(err, service.ErrNoStudentsGiven):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": err.Error(),
+		}, http.StatusBadRequest)
+	case errors.Is(err, service.ErrGroupAccessDenied):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "FORBIDDEN",
+			"message": err.Error(),
+		}, http.StatusForbidden)
+	default:
+		h.log.Error("["+fn+"] "+message, zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "ERROR",
+			"message": message,
+		}, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,225 @@
+package rest
+
+import (
+	"assessment_service/internal/groups/service"
+	"assessment_service/internal/middleware"
+	models "assessment_service/internal/model"
+	"assessment_service/internal/util"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// --- Mock GroupService ---
+type MockGroupService struct {
+	mock.Mock
+}
+
+func (m *MockGroupService) CreateGroup(group models.GroupDTO, createdByID uint) (*models.Group, error) {
+	args := m.Called(group, createdByID)
+	created, _ := args.Get(0).(*models.Group)
+	return created, args.Error(1)
+}
+
+func (m *MockGroupService) GetGroup(id uint) (*models.Group, error) {
+	args := m.Called(id)
+	group, _ := args.Get(0).(*models.Group)
+	return group, args.Error(1)
+}
+
+func (m *MockGroupService) UpdateGroup(id uint, group models.GroupDTO, actorID uint) (*models.Group, error) {
+	args := m.Called(id, group, actorID)
+	updated, _ := args.Get(0).(*models.Group)
+	return updated, args.Error(1)
+}
+
+func (m *MockGroupService) DeleteGroup(id, actorID uint) error {
+	args := m.Called(id, actorID)
+	return args.Error(0)
+}
+
+func (m *MockGroupService) ListGroups(params util.PaginationParams) ([]models.Group, int64, error) {
+	args := m.Called(params)
+	groups, _ := args.Get(0).([]models.Group)
+	total, _ := args.Get(1).(int64)
+	return groups, total, args.Error(2)
+}
+
+func (m *MockGroupService) ListMembers(id uint, params util.PaginationParams) ([]models.User, int64, error) {
+	args := m.Called(id, params)
+	users, _ := args.Get(0).([]models.User)
+	total, _ := args.Get(1).(int64)
+	return users, total, args.Error(2)
+}
+
+func (m *MockGroupService) EnrollMembers(id uint, enrollment models.EnrollmentDTO, actorID uint) (*models.EnrollmentResult, error) {
+	args := m.Called(id, enrollment, actorID)
+	result, _ := args.Get(0).(*models.EnrollmentResult)
+	return result, args.Error(1)
+}
+
+func (m *MockGroupService) RemoveMember(id, userID, actorID uint) error {
+	args := m.Called(id, userID, actorID)
+	return args.Error(0)
+}
+
+func serveGroups(handler *GroupHandler, req *http.Request) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	router.HandleFunc("/groups", handler.ListGroups).Methods(http.MethodGet)
+	router.HandleFunc("/groups", handler.CreateGroup).Methods(http.MethodPost)
+	router.HandleFunc("/groups/{id}", handler.GetGroup).Methods(http.MethodGet)
+	router.HandleFunc("/groups/{id}", handler.UpdateGroup).Methods(http.MethodPut)
+	router.HandleFunc("/groups/{id}", handler.DeleteGroup).Methods(http.MethodDelete)
+	router.HandleFunc("/groups/{id}/members", handler.ListMembers).Methods(http.MethodGet)
+	router.HandleFunc("/groups/{id}/members", handler.EnrollMembers).Methods(http.MethodPost)
+	router.HandleFunc("/groups/{id}/members/{userId}", handler.RemoveMember).Methods(http.MethodDelete)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func newRequest(method, url string, body string, principal *middleware.Principal) *http.Request {
+	req := httptest.NewRequest(method, url, bytes.NewBufferString(body))
+	if principal != nil {
+		req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+	}
+	return req
+}
+
+// teacher là người gọi các route thay đổi nhóm trong test
+var teacher = &middleware.Principal{UserID: 5, Role: "teacher"}
+
+func TestGroupHandler_CreateGroup(t *testing.T) {
+	mockService := new(MockGroupService)
+	handler := NewGroupHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("CreateGroup", models.GroupDTO{Name: "Class 10A"}, uint(7)).Return(&models.Group{ID: 1, Name: "Class 10A", CreatedByID: 7}, nil)
+
+	rr := serveGroups(handler, newRequest(http.MethodPost, "/groups", `{"name":"Class 10A"}`, &middleware.Principal{UserID: 7, Role: "teacher"}))
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGroupHandler_CreateGroup_NameRequired(t *testing.T) {
+	mockService := new(MockGroupService)
+	handler := NewGroupHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("CreateGroup", models.GroupDTO{}, uint(7)).Return(nil, service.ErrGroupNameRequired)
+
+	rr := serveGroups(handler, newRequest(http.MethodPost, "/groups", `{}`, &middleware.Principal{UserID: 7, Role: "teacher"}))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestGroupHandler_ListGroups(t *testing.T) {
+	mockService := new(MockGroupService)
+	handler := NewGroupHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("ListGroups", mock.MatchedBy(func(p util.PaginationParams) bool {
+		return p.Filters["createdBy"] == uint(7)
+	})).Return([]models.Group{{ID: 1, Name: "Class 10A"}}, int64(1), nil)
+
+	rr := serveGroups(handler, newRequest(http.MethodGet, "/groups?createdBy=7", "", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+	assert.Equal(t, float64(1), result["totalElements"])
+}
+
+func TestGroupHandler_GetGroup_NotFound(t *testing.T) {
+	mockService := new(MockGroupService)
+	handler := NewGroupHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("GetGroup", uint(3)).Return(nil, service.ErrGroupNotFound)
+
+	rr := serveGroups(handler, newRequest(http.MethodGet, "/groups/3", "", nil))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestGroupHandler_EnrollMembers(t *testing.T) {
+	mockService := new(MockGroupService)
+	handler := NewGroupHandler(mockService, zaptest.NewLogger(t))
+
+	enrollment := models.EnrollmentDTO{UserIDs: []uint{10, 11}, Emails: []string{"carol@example.com"}}
+	mockService.On("EnrollMembers", uint(1), enrollment, uint(5)).Return(&models.EnrollmentResult{
+		Enrolled:        []uint{10, 14},
+		AlreadyEnrolled: []uint{11},
+		NotFound:        []string{},
+		NotStudents:     []uint{},
+	}, nil)
+
+	rr := serveGroups(handler, newRequest(http.MethodPost, "/groups/1/members", `{"userIds":[10,11],"emails":["carol@example.com"]}`, teacher))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var result models.EnrollmentResult
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+	assert.Equal(t, []uint{10, 14}, result.Enrolled)
+	mockService.AssertExpectations(t)
+}
+
+func TestGroupHandler_EnrollMembers_InvalidInput(t *testing.T) {
+	mockService := new(MockGroupService)
+	handler := NewGroupHandler(mockService, zaptest.NewLogger(t))
+
+	rr := serveGroups(handler, newRequest(http.MethodPost, "/groups/1/members", `{"userIds":"x"}`, teacher))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertNotCalled(t, "EnrollMembers", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGroupHandler_RemoveMember(t *testing.T) {
+	mockService := new(MockGroupService)
+	handler := NewGroupHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("RemoveMember", uint(1), uint(10), uint(5)).Return(nil).Once()
+	mockService.On("RemoveMember", uint(1), uint(11), uint(5)).Return(service.ErrMemberNotFound).Once()
+
+	rr := serveGroups(handler, newRequest(http.MethodDelete, "/groups/1/members/10", "", teacher))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = serveGroups(handler, newRequest(http.MethodDelete, "/groups/1/members/11", "", teacher))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestGroupHandler_UpdateGroup_NotCreator(t *testing.T) {
+	mockService := new(MockGroupService)
+	handler := NewGroupHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("UpdateGroup", uint(1), models.GroupDTO{Name: "Renamed"}, uint(5)).Return(nil, service.ErrGroupAccessDenied)
+
+	rr := serveGroups(handler, newRequest(http.MethodPut, "/groups/1", `{"name":"Renamed"}`, teacher))
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGroupHandler_DeleteGroup(t *testing.T) {
+	mockService := new(MockGroupService)
+	handler := NewGroupHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("DeleteGroup", uint(1), uint(5)).Return(nil).Once()
+	mockService.On("DeleteGroup", uint(2), uint(5)).Return(service.ErrGroupAccessDenied).Once()
+
+	rr := serveGroups(handler, newRequest(http.MethodDelete, "/groups/1", "", teacher))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = serveGroups(handler, newRequest(http.MethodDelete, "/groups/2", "", teacher))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	// Không có người gọi
+	rr = serveGroups(handler, newRequest(http.MethodDelete, "/groups/1", "", nil))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	models "assessment_service/internal/model"
+	"assessment_service/internal/util"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GroupRepository interface {
+	Create(group *models.Group) error
+	FindByID(id uint) (*models.Group, error)
+	Update(group *models.Group) error
+	Delete(id uint) error
+	List(params util.PaginationParams) ([]models.Group, int64, error)
+	ListMembers(groupID uint, params util.PaginationParams) ([]models.User, int64, error)
+	// FindMemberIDs returns which of userIDs are already members of the group
+	FindMemberIDs(groupID uint, userIDs []uint) ([]uint, error)
+	// AddMembers enrolls the users, skipping any that are already members
+	AddMembers(groupID uint, userIDs []uint) error
+	// RemoveMember reports whether a membership was removed
+	RemoveMember(groupID, userID uint) (bool, error)
+}
+
+type groupRepository struct {
+	db *gorm.DB
+}
+
+func NewGroupRepository(db *gorm.DB) GroupRepository {
+	return &groupRepository{db: db}
+}
+
+func (r *groupRepository) Create(group *models.Group) error {
+	if err := r.db.Create(group).Error; err != nil {
+		return fmt.Errorf("failed to create group: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) FindByID(id uint) (*models.Group, error) {
+	var group models.Group
+	if err := r.db.First(&group, id).Error; err != nil {
+		return nil, err
+	}
+
+	if err := r.db.Model(&models.GroupMember{}).Where("group_id = ?", id).Count(&group.MemberCount).Error; err != nil {
+		return nil, fmt.Errorf("failed to count group members: %w", err)
+	}
+
+	return &group, nil
+}
+
+func (r *groupRepository) Update(group *models.Group) error {
+	if err := r.db.Save(group).Error; err != nil {
+		return fmt.Errorf("failed to update group: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the group together with its memberships and assessment assignments
+func (r *groupRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", id).Delete(&models.GroupMember{}).Error; err != nil {
+			return fmt.Errorf("failed to delete group members: %w", err)
+		}
+
+		if err := tx.Where("group_id = ?", id).Delete(&models.AssessmentAssignment{}).Error; err != nil {
+			return fmt.Errorf("failed to delete group assignments: %w", err)
+		}
+
+		if err := tx.Delete(&models.Group{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete group: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *groupRepository) List(params util.PaginationParams) ([]models.Group, int64, error) {
+	var groups []models.Group
+	var total int64
+
+	query := r.db.Model(&models.Group{})
+
+	if params.Search != "" {
+		query = query.Where("name LIKE ? OR description LIKE ?", "%"+params.Search+"%", "%"+params.Search+"%")
+	}
+
+	if params.Filters != nil {
+		if val, ok := params.Filters["createdBy"]; ok {
+			query = query.Where("created_by_id = ?", val)
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count groups: %w", err)
+	}
+
+	if params.SortBy != "" {
+		query = query.Order(params.SortBy + " " + params.SortDir)
+	} else {
+		query = query.Order("created_at DESC")
+	}
+
+	err := query.Select("groups.*, (?) AS member_count",
+		r.db.Model(&models.GroupMember{}).Select("COUNT(*)").Where("group_members.group_id = groups.id"),
+	).Offset(params.Offset).Limit(params.Limit).Find(&groups).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list groups: %w", err)
+	}
+
+	return groups, total, nil
+}
+
+func (r *groupRepository) ListMembers(groupID uint, params util.PaginationParams) ([]models.User, int64, error) {
+	var users []models.User
+	var total int64
+
+	query := r.db.Model(&models.User{}).
+		Joins("JOIN group_members ON group_members.user_id = users.id").
+		Where("group_members.group_id = ?", groupID)
+
+	if params.Search != "" {
+		query = query.Where("users.name LIKE ? OR users.email LIKE ?", "%"+params.Search+"%", "%"+params.Search+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count group members: %w", err)
+	}
+
+	err := query.Order("users.name ASC").
+		Offset(params.Offset).Limit(params.Limit).
+		Find(&users).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list group members: %w", err)
+	}
+
+	return users, total, nil
+}
+
+func (r *groupRepository) FindMemberIDs(groupID uint, userIDs []uint) ([]uint, error) {
+	var ids []uint
+	if len(userIDs) == 0 {
+		return ids, nil
+	}
+
+	err := r.db.Model(&models.GroupMember{}).
+		Where("group_id = ? AND user_id IN ?", groupID, userIDs).
+		Pluck("user_id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find group members: %w", err)
+	}
+	return ids, nil
+}
+
+func (r *groupRepository) AddMembers(groupID uint, userIDs []uint) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	members := make([]models.GroupMember, len(userIDs))
+	for i, userID := range userIDs {
+		members[i] = models.GroupMember{GroupID: groupID, UserID: userID}
+	}
+
+	err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error
+	if err != nil {
+		return fmt.Errorf("failed to add group members: %w", err)
+	}
+	return nil
+}
+
+func (r *groupRepository) RemoveMember(groupID, userID uint) (bool, error) {
+	result := r.db.Where("group_id = ? AND user_id = ?", groupID, userID).Delete(&models.GroupMember{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to remove group member: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"testing"
+
+	models "assessment_service/internal/model"
+	"assessment_service/internal/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestSQLiteDatabase khởi tạo database SQLite in-memory và trả về *gorm.DB
+func setupTestSQLiteDatabase(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err, "Failed to connect to in-memory SQLite")
+
+	err = db.AutoMigrate(
+		&models.User{},
+		&models.Assessment{},
+		&models.Group{},
+		&models.GroupMember{},
+		&models.AssessmentAssignment{},
+	)
+	require.NoError(t, err, "Failed to run migrations on SQLite")
+
+	return db
+}
+
+// TestGroupRepository_SQLite là hàm test chính cho repository với SQLite
+func TestGroupRepository_SQLite(t *testing.T) {
+	db := setupTestSQLiteDatabase(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	repo := NewGroupRepository(db)
+
+	teacher := models.User{Name: "Teacher", Email: "teacher@test.com", Password: "pw", Role: "teacher", Status: "Active"}
+	alice := models.User{Name: "Alice", Email: "alice@test.com", Password: "pw", Role: "student", Status: "Active"}
+	bob := models.User{Name: "Bob", Email: "bob@test.com", Password: "pw", Role: "student", Status: "Active"}
+	require.NoError(t, db.Create(&teacher).Error)
+	require.NoError(t, db.Create(&alice).Error)
+	require.NoError(t, db.Create(&bob).Error)
+
+	group := &models.Group{Name: "Class 10A", Description: "Morning class", CreatedByID: teacher.ID}
+
+	t.Run("TestCreate", func(t *testing.T) {
+		require.NoError(t, repo.Create(group))
+		assert.NotZero(t, group.ID)
+	})
+
+	t.Run("TestAddMembers", func(t *testing.T) {
+		require.NoError(t, repo.AddMembers(group.ID, []uint{alice.ID, bob.ID}))
+		// Thêm lại một thành viên đã có không được báo lỗi
+		require.NoError(t, repo.AddMembers(group.ID, []uint{alice.ID}))
+
+		ids, err := repo.FindMemberIDs(group.ID, []uint{alice.ID, teacher.ID})
+		require.NoError(t, err)
+		assert.Equal(t, []uint{alice.ID}, ids)
+	})
+
+	t.Run("TestFindByID", func(t *testing.T) {
+		found, err := repo.FindByID(group.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Class 10A", found.Name)
+		assert.Equal(t, int64(2), found.MemberCount)
+
+		_, err = repo.FindByID(9999)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("TestList", func(t *testing.T) {
+		other := &models.Group{Name: "Class 11B", CreatedByID: teacher.ID}
+		require.NoError(t, repo.Create(other))
+
+		params := util.PaginationParams{Page: 0, Limit: 10, SortBy: "name", SortDir: "ASC", Search: "Class"}
+		groups, total, err := repo.List(params)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		require.Len(t, groups, 2)
+		assert.Equal(t, "Class 10A", groups[0].Name)
+		assert.Equal(t, int64(2), groups[0].MemberCount)
+		assert.Equal(t, int64(0), groups[1].MemberCount)
+	})
+
+	t.Run("TestListMembers", func(t *testing.T) {
+		users, total, err := repo.ListMembers(group.ID, util.PaginationParams{Page: 0, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		require.Len(t, users, 2)
+		assert.Equal(t, "Alice", users[0].Name)
+	})
+
+	t.Run("TestRemoveMember", func(t *testing.T) {
+		removed, err := repo.RemoveMember(group.ID, bob.ID)
+		require.NoError(t, err)
+		assert.True(t, removed)
+
+		removed, err = repo.RemoveMember(group.ID, bob.ID)
+		require.NoError(t, err)
+		assert.False(t, removed)
+	})
+
+	t.Run("TestDelete", func(t *testing.T) {
+		require.NoError(t, db.Create(&models.AssessmentAssignment{AssessmentID: 1, GroupID: &group.ID}).Error)
+
+		require.NoError(t, repo.Delete(group.ID))
+
+		_, err := repo.FindByID(group.ID)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		var members, assignments int64
+		db.Model(&models.GroupMember{}).Where("group_id = ?", group.ID).Count(&members)
+		db.Model(&models.AssessmentAssignment{}).Where("group_id = ?", group.ID).Count(&assignments)
+		assert.Zero(t, members)
+		assert.Zero(t, assignments)
+	})
+}
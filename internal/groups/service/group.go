@@ -0,0 +1,277 @@
+package service
+
+import (
+	"assessment_service/internal/groups/repository"
+	models "assessment_service/internal/model"
+	repository2 "assessment_service/internal/users/repository"
+	"assessment_service/internal/util"
+	"errors"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrGroupNotFound     = errors.New("group not found")
+	ErrGroupNameRequired = errors.New("group name is required")
+	ErrMemberNotFound    = errors.New("user is not a member of this group")
+	ErrNoStudentsGiven   = errors.New("at least one user ID or email is required")
+	// ErrGroupAccessDenied is returned when a teacher changes a group someone else created
+	ErrGroupAccessDenied = errors.New("only the group's creator or an admin can change it")
+)
+
+type GroupService interface {
+	CreateGroup(group models.GroupDTO, createdByID uint) (*models.Group, error)
+	GetGroup(id uint) (*models.Group, error)
+	UpdateGroup(id uint, group models.GroupDTO, actorID uint) (*models.Group, error)
+	DeleteGroup(id, actorID uint) error
+	ListGroups(params util.PaginationParams) ([]models.Group, int64, error)
+	ListMembers(id uint, params util.PaginationParams) ([]models.User, int64, error)
+	EnrollMembers(id uint, enrollment models.EnrollmentDTO, actorID uint) (*models.EnrollmentResult, error)
+	RemoveMember(id, userID, actorID uint) error
+}
+
+type groupService struct {
+	groupRepo repository.GroupRepository
+	userRepo  repository2.UserRepository
+	log       *zap.Logger
+}
+
+func NewGroupService(groupRepo repository.GroupRepository, userRepo repository2.UserRepository, log *zap.Logger) GroupService {
+	return &groupService{
+		groupRepo: groupRepo,
+		userRepo:  userRepo,
+		log:       log,
+	}
+}
+
+func (s *groupService) CreateGroup(dto models.GroupDTO, createdByID uint) (*models.Group, error) {
+	name := strings.TrimSpace(dto.Name)
+	if name == "" {
+		return nil, ErrGroupNameRequired
+	}
+
+	group := &models.Group{
+		Name:        name,
+		Description: dto.Description,
+		CreatedByID: createdByID,
+	}
+
+	if err := s.groupRepo.Create(group); err != nil {
+		s.log.Error("[CreateGroup] failed to create group", zap.Error(err))
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (s *groupService) GetGroup(id uint) (*models.Group, error) {
+	group, err := s.groupRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGroupNotFound
+		}
+		s.log.Error("[GetGroup] failed to find group", zap.Error(err))
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (s *groupService) UpdateGroup(id uint, dto models.GroupDTO, actorID uint) (*models.Group, error) {
+	name := strings.TrimSpace(dto.Name)
+	if name == "" {
+		return nil, ErrGroupNameRequired
+	}
+
+	group, err := s.findManagedGroup(id, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	group.Name = name
+	group.Description = dto.Description
+
+	if err := s.groupRepo.Update(group); err != nil {
+		s.log.Error("[UpdateGroup] failed to update group", zap.Error(err))
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (s *groupService) DeleteGroup(id, actorID uint) error {
+	if _, err := s.findManagedGroup(id, actorID); err != nil {
+		return err
+	}
+
+	if err := s.groupRepo.Delete(id); err != nil {
+		s.log.Error("[DeleteGroup] failed to delete group", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (s *groupService) ListGroups(params util.PaginationParams) ([]models.Group, int64, error) {
+	groups, total, err := s.groupRepo.List(params)
+	if err != nil {
+		s.log.Error("[ListGroups] failed to list groups", zap.Error(err))
+		return nil, 0, err
+	}
+
+	return groups, total, nil
+}
+
+func (s *groupService) ListMembers(id uint, params util.PaginationParams) ([]models.User, int64, error) {
+	if _, err := s.GetGroup(id); err != nil {
+		return nil, 0, err
+	}
+
+	users, total, err := s.groupRepo.ListMembers(id, params)
+	if err != nil {
+		s.log.Error("[ListMembers] failed to list group members", zap.Error(err))
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// EnrollMembers adds every listed student to the group. Unknown users and non-students are reported
+// back instead of failing the whole request, so a class list can be imported in one call.
+func (s *groupService) EnrollMembers(id uint, dto models.EnrollmentDTO, actorID uint) (*models.EnrollmentResult, error) {
+	if len(dto.UserIDs) == 0 && len(dto.Emails) == 0 {
+		return nil, ErrNoStudentsGiven
+	}
+
+	if _, err := s.findManagedGroup(id, actorID); err != nil {
+		return nil, err
+	}
+
+	result := &models.EnrollmentResult{
+		Enrolled:        []uint{},
+		AlreadyEnrolled: []uint{},
+		NotFound:        []string{},
+		NotStudents:     []uint{},
+	}
+
+	var candidates []uint
+	seen := make(map[uint]bool)
+	addCandidate := func(user *models.User) {
+		if seen[user.ID] {
+			return
+		}
+		seen[user.ID] = true
+
+		if user.Role != "student" {
+			result.NotStudents = append(result.NotStudents, user.ID)
+			return
+		}
+		candidates = append(candidates, user.ID)
+	}
+
+	for _, userID := range dto.UserIDs {
+		user, err := s.userRepo.FindByID(userID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				result.NotFound = append(result.NotFound, strconv.FormatUint(uint64(userID), 10))
+				continue
+			}
+			s.log.Error("[EnrollMembers] failed to find user", zap.Error(err))
+			return nil, err
+		}
+		addCandidate(user)
+	}
+
+	for _, email := range dto.Emails {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if email == "" {
+			continue
+		}
+
+		user, err := s.userRepo.FindByEmail(email)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				result.NotFound = append(result.NotFound, email)
+				continue
+			}
+			s.log.Error("[EnrollMembers] failed to find user", zap.Error(err))
+			return nil, err
+		}
+		addCandidate(user)
+	}
+
+	existing, err := s.groupRepo.FindMemberIDs(id, candidates)
+	if err != nil {
+		s.log.Error("[EnrollMembers] failed to check existing members", zap.Error(err))
+		return nil, err
+	}
+
+	isMember := make(map[uint]bool, len(existing))
+	for _, userID := range existing {
+		isMember[userID] = true
+	}
+
+	for _, userID := range candidates {
+		if isMember[userID] {
+			result.AlreadyEnrolled = append(result.AlreadyEnrolled, userID)
+		} else {
+			result.Enrolled = append(result.Enrolled, userID)
+		}
+	}
+
+	if err := s.groupRepo.AddMembers(id, result.Enrolled); err != nil {
+		s.log.Error("[EnrollMembers] failed to add members", zap.Error(err))
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *groupService) RemoveMember(id, userID, actorID uint) error {
+	if _, err := s.findManagedGroup(id, actorID); err != nil {
+		return err
+	}
+
+	removed, err := s.groupRepo.RemoveMember(id, userID)
+	if err != nil {
+		s.log.Error("[RemoveMember] failed to remove member", zap.Error(err))
+		return err
+	}
+
+	if !removed {
+		return ErrMemberNotFound
+	}
+
+	return nil
+}
+
+// findManagedGroup finds a group the actor may change: admins may change any group, teachers only
+// the groups they created
+func (s *groupService) findManagedGroup(id, actorID uint) (*models.Group, error) {
+	group, err := s.GetGroup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if group.CreatedByID == actorID {
+		return group, nil
+	}
+
+	actor, err := s.userRepo.FindByID(actorID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGroupAccessDenied
+		}
+		s.log.Error("failed to find user", zap.Error(err))
+		return nil, err
+	}
+
+	if actor.Role != "admin" {
+		return nil, ErrGroupAccessDenied
+	}
+
+	return group, nil
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	models "assessment_service/internal/model"
+	"assessment_service/internal/util"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+// --- Mock GroupRepository ---
+type MockGroupRepository struct {
+	mock.Mock
+}
+
+func (m *MockGroupRepository) Create(group *models.Group) error {
+	args := m.Called(group)
+	return args.Error(0)
+}
+
+func (m *MockGroupRepository) FindByID(id uint) (*models.Group, error) {
+	args := m.Called(id)
+	group, _ := args.Get(0).(*models.Group)
+	return group, args.Error(1)
+}
+
+func (m *MockGroupRepository) Update(group *models.Group) error {
+	args := m.Called(group)
+	return args.Error(0)
+}
+
+func (m *MockGroupRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockGroupRepository) List(params util.PaginationParams) ([]models.Group, int64, error) {
+	args := m.Called(params)
+	groups, _ := args.Get(0).([]models.Group)
+	count, _ := args.Get(1).(int64)
+	return groups, count, args.Error(2)
+}
+
+func (m *MockGroupRepository) ListMembers(groupID uint, params util.PaginationParams) ([]models.User, int64, error) {
+	args := m.Called(groupID, params)
+	users, _ := args.Get(0).([]models.User)
+	count, _ := args.Get(1).(int64)
+	return users, count, args.Error(2)
+}
+
+func (m *MockGroupRepository) FindMemberIDs(groupID uint, userIDs []uint) ([]uint, error) {
+	args := m.Called(groupID, userIDs)
+	ids, _ := args.Get(0).([]uint)
+	return ids, args.Error(1)
+}
+
+func (m *MockGroupRepository) AddMembers(groupID uint, userIDs []uint) error {
+	args := m.Called(groupID, userIDs)
+	return args.Error(0)
+}
+
+func (m *MockGroupRepository) RemoveMember(groupID, userID uint) (bool, error) {
+	args := m.Called(groupID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+// --- Mock UserRepository ---
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) Create(user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindByID(id uint) (*models.User, error) {
+	args := m.Called(id)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserRepository) FindByEmail(email string) (*models.User, error) {
+	args := m.Called(email)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserRepository) Update(user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) List(params util.PaginationParams) ([]models.User, int64, error) {
+	args := m.Called(params)
+	users, _ := args.Get(0).([]models.User)
+	count, _ := args.Get(1).(int64)
+	return users, count, args.Error(2)
+}
+
+func (m *MockUserRepository) UpdateLastLogin(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUserStats() (int64, int64, error) {
+	args := m.Called()
+	active, _ := args.Get(0).(int64)
+	inactive, _ := args.Get(1).(int64)
+	return active, inactive, args.Error(2)
+}
+
+func (m *MockUserRepository) CountAll() (int64, error) {
+	args := m.Called()
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
+func (m *MockUserRepository) GetNewUsersCount(days int) (int64, error) {
+	args := m.Called(days)
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
+func (m *MockUserRepository) GetListUserByAssessment(params util.PaginationParams, assessmentID uint) ([]models.User, int64, error) {
+	args := m.Called(params, assessmentID)
+	users, _ := args.Get(0).([]models.User)
+	count, _ := args.Get(1).(int64)
+	return users, count, args.Error(2)
+}
+
+type testGroupService struct {
+	GroupService
+	groupRepo *MockGroupRepository
+	userRepo  *MockUserRepository
+}
+
+func newTestGroupService(t *testing.T) testGroupService {
+	groupRepo := new(MockGroupRepository)
+	userRepo := new(MockUserRepository)
+	return testGroupService{
+		GroupService: NewGroupService(groupRepo, userRepo, zaptest.NewLogger(t)),
+		groupRepo:    groupRepo,
+		userRepo:     userRepo,
+	}
+}
+
+// --- Test Cases ---
+
+func TestGroupService_CreateGroup(t *testing.T) {
+	svc := newTestGroupService(t)
+
+	svc.groupRepo.On("Create", mock.MatchedBy(func(g *models.Group) bool {
+		return g.Name == "Class 10A" && g.CreatedByID == 5
+	})).Return(nil)
+
+	group, err := svc.CreateGroup(models.GroupDTO{Name: "  Class 10A "}, 5)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Class 10A", group.Name)
+	svc.groupRepo.AssertExpectations(t)
+}
+
+func TestGroupService_CreateGroup_NameRequired(t *testing.T) {
+	svc := newTestGroupService(t)
+
+	_, err := svc.CreateGroup(models.GroupDTO{Name: " "}, 5)
+
+	assert.ErrorIs(t, err, ErrGroupNameRequired)
+	svc.groupRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestGroupService_GetGroup_NotFound(t *testing.T) {
+	svc := newTestGroupService(t)
+
+	svc.groupRepo.On("FindByID", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := svc.GetGroup(1)
+
+	assert.ErrorIs(t, err, ErrGroupNotFound)
+}
+
+func TestGroupService_EnrollMembers(t *testing.T) {
+	svc := newTestGroupService(t)
+
+	svc.groupRepo.On("FindByID", uint(1)).Return(&models.Group{ID: 1, CreatedByID: 5}, nil)
+	svc.userRepo.On("FindByID", uint(10)).Return(&models.User{ID: 10, Role: "student"}, nil)
+	svc.userRepo.On("FindByID", uint(11)).Return(&models.User{ID: 11, Role: "student"}, nil)
+	svc.userRepo.On("FindByID", uint(12)).Return(&models.User{ID: 12, Role: "teacher"}, nil)
+	svc.userRepo.On("FindByID", uint(13)).Return(nil, gorm.ErrRecordNotFound)
+	svc.userRepo.On("FindByEmail", "carol@example.com").Return(&models.User{ID: 14, Role: "student"}, nil)
+	svc.userRepo.On("FindByEmail", "ghost@example.com").Return(nil, gorm.ErrRecordNotFound)
+	svc.groupRepo.On("FindMemberIDs", uint(1), []uint{10, 11, 14}).Return([]uint{11}, nil)
+	svc.groupRepo.On("AddMembers", uint(1), []uint{10, 14}).Return(nil)
+
+	result, err := svc.EnrollMembers(1, models.EnrollmentDTO{
+		UserIDs: []uint{10, 11, 12, 13, 10},
+		Emails:  []string{"Carol@example.com", "ghost@example.com", ""},
+	}, 5)
+
+	require.NoError(t, err)
+	assert.Equal(t, []uint{10, 14}, result.Enrolled)
+	assert.Equal(t, []uint{11}, result.AlreadyEnrolled)
+	assert.Equal(t, []uint{12}, result.NotStudents)
+	assert.Equal(t, []string{"13", "ghost@example.com"}, result.NotFound)
+	svc.groupRepo.AssertExpectations(t)
+}
+
+func TestGroupService_EnrollMembers_Empty(t *testing.T) {
+	svc := newTestGroupService(t)
+
+	_, err := svc.EnrollMembers(1, models.EnrollmentDTO{}, 5)
+
+	assert.ErrorIs(t, err, ErrNoStudentsGiven)
+}
+
+func TestGroupService_EnrollMembers_LookupError(t *testing.T) {
+	svc := newTestGroupService(t)
+
+	svc.groupRepo.On("FindByID", uint(1)).Return(&models.Group{ID: 1, CreatedByID: 5}, nil)
+	svc.userRepo.On("FindByID", uint(10)).Return(nil, errors.New("db error"))
+
+	_, err := svc.EnrollMembers(1, models.EnrollmentDTO{UserIDs: []uint{10}}, 5)
+
+	assert.EqualError(t, err, "db error")
+	svc.groupRepo.AssertNotCalled(t, "AddMembers", mock.Anything, mock.Anything)
+}
+
+func TestGroupService_RemoveMember_NotMember(t *testing.T) {
+	svc := newTestGroupService(t)
+
+	svc.groupRepo.On("FindByID", uint(1)).Return(&models.Group{ID: 1, CreatedByID: 5}, nil)
+	svc.groupRepo.On("RemoveMember", uint(1), uint(10)).Return(false, nil)
+
+	err := svc.RemoveMember(1, 10, 5)
+
+	assert.ErrorIs(t, err, ErrMemberNotFound)
+}
+
+func TestGroupService_ManageGroup_Access(t *testing.T) {
+	svc := newTestGroupService(t)
+
+	// Nhóm 1 do giáo viên 5 tạo
+	svc.groupRepo.On("FindByID", uint(1)).Return(&models.Group{ID: 1, Name: "Class 10A", CreatedByID: 5}, nil)
+	svc.userRepo.On("FindByID", uint(6)).Return(&models.User{ID: 6, Role: "teacher"}, nil)
+	svc.userRepo.On("FindByID", uint(7)).Return(&models.User{ID: 7, Role: "admin"}, nil)
+	svc.groupRepo.On("Update", mock.Anything).Return(nil)
+	svc.groupRepo.On("Delete", uint(1)).Return(nil).Once()
+
+	// Giáo viên khác không được sửa, xóa hay thay đổi thành viên
+	_, err := svc.UpdateGroup(1, models.GroupDTO{Name: "Renamed"}, 6)
+	assert.ErrorIs(t, err, ErrGroupAccessDenied)
+	assert.ErrorIs(t, svc.DeleteGroup(1, 6), ErrGroupAccessDenied)
+	_, err = svc.EnrollMembers(1, models.EnrollmentDTO{UserIDs: []uint{10}}, 6)
+	assert.ErrorIs(t, err, ErrGroupAccessDenied)
+	assert.ErrorIs(t, svc.RemoveMember(1, 10, 6), ErrGroupAccessDenied)
+	svc.groupRepo.AssertNotCalled(t, "Update", mock.Anything)
+	svc.groupRepo.AssertNotCalled(t, "Delete", mock.Anything)
+	svc.groupRepo.AssertNotCalled(t, "AddMembers", mock.Anything, mock.Anything)
+	svc.groupRepo.AssertNotCalled(t, "RemoveMember", mock.Anything, mock.Anything)
+
+	// Người tạo và admin thì được
+	group, err := svc.UpdateGroup(1, models.GroupDTO{Name: "Renamed"}, 5)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", group.Name)
+	require.NoError(t, svc.DeleteGroup(1, 7))
+	svc.groupRepo.AssertExpectations(t)
+}
@@ -0,0 +1,110 @@
+package live
+
+import (
+	models "assessment_service/internal/model"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// subscriberBuffer is how many events a slow subscriber may fall behind before events are dropped
+// for it
+const subscriberBuffer = 16
+
+// Publisher sends events to the live channels of attempts
+type Publisher interface {
+	Publish(event models.LiveEvent) error
+}
+
+// Hub fans the events of an attempt out to every open live channel of the attempt, and to the
+// proctoring feeds of its assessment
+type Hub interface {
+	Publisher
+	// Subscribe returns the events published for the attempt from now on, and the function that
+	// stops them and closes the channel
+	Subscribe(attemptID uint) (<-chan models.LiveEvent, func())
+	// SubscribeAssessment returns the events published for every attempt of the assessment from now
+	// on, and the function that stops them and closes the channel
+	SubscribeAssessment(assessmentID uint) (<-chan models.LiveEvent, func())
+}
+
+// Send publishes the event when there is a publisher. The live channel is best effort, so a failure
+// is logged and never fails the request that caused the event.
+func Send(publisher Publisher, event models.LiveEvent, log *zap.Logger) {
+	if publisher == nil {
+		return
+	}
+	if err := publisher.Publish(event); err != nil {
+		log.Warn("failed to publish live event", zap.Uint("attemptId", event.AttemptID),
+			zap.String("type", string(event.Type)), zap.Error(err))
+	}
+}
+
+// subscribers are the open channels of each attempt, or of each assessment
+type subscribers map[uint]map[chan models.LiveEvent]struct{}
+
+type memoryHub struct {
+	mu          sync.RWMutex
+	attempts    subscribers
+	assessments subscribers
+}
+
+// NewMemoryHub creates a hub that fans events out to the live channels open on this instance only
+func NewMemoryHub() Hub {
+	return &memoryHub{attempts: make(subscribers), assessments: make(subscribers)}
+}
+
+func (h *memoryHub) Publish(event models.LiveEvent) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if !event.Type.ProctorOnly() {
+		send(h.attempts[event.AttemptID], event)
+	}
+	if event.AssessmentID != 0 {
+		send(h.assessments[event.AssessmentID], event)
+	}
+	return nil
+}
+
+func send(channels map[chan models.LiveEvent]struct{}, event models.LiveEvent) {
+	for ch := range channels {
+		select {
+		case ch <- event:
+		default:
+			// A subscriber that stopped reading must not hold up the others
+		}
+	}
+}
+
+func (h *memoryHub) Subscribe(attemptID uint) (<-chan models.LiveEvent, func()) {
+	return h.subscribe(h.attempts, attemptID)
+}
+
+func (h *memoryHub) SubscribeAssessment(assessmentID uint) (<-chan models.LiveEvent, func()) {
+	return h.subscribe(h.assessments, assessmentID)
+}
+
+func (h *memoryHub) subscribe(topic subscribers, id uint) (<-chan models.LiveEvent, func()) {
+	ch := make(chan models.LiveEvent, subscriberBuffer)
+
+	h.mu.Lock()
+	if topic[id] == nil {
+		topic[id] = make(map[chan models.LiveEvent]struct{})
+	}
+	topic[id][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			delete(topic[id], ch)
+			if len(topic[id]) == 0 {
+				delete(topic, id)
+			}
+			close(ch)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package live
+
+import (
+	models "assessment_service/internal/model"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestMemoryHub(t *testing.T) {
+	hub := NewMemoryHub()
+
+	first, unsubscribeFirst := hub.Subscribe(1)
+	second, unsubscribeSecond := hub.Subscribe(1)
+	other, unsubscribeOther := hub.Subscribe(2)
+	defer unsubscribeSecond()
+	defer unsubscribeOther()
+
+	// Mọi kênh của bài làm nhận sự kiện, kênh của bài khác thì không
+	require.NoError(t, hub.Publish(models.NewLiveEvent(1, models.LiveEventMessage, "hello")))
+	assert.Equal(t, "hello", (<-first).Data)
+	assert.Equal(t, "hello", (<-second).Data)
+	assert.Empty(t, other)
+
+	// Hủy đăng ký thì kênh đóng, gọi lại không lỗi
+	unsubscribeFirst()
+	unsubscribeFirst()
+	_, open := <-first
+	assert.False(t, open)
+	require.NoError(t, hub.Publish(models.NewLiveEvent(1, models.LiveEventMessage, "again")))
+	assert.Equal(t, "again", (<-second).Data)
+}
+
+func TestMemoryHub_SubscribeAssessment(t *testing.T) {
+	hub := NewMemoryHub()
+
+	dashboard, unsubscribeDashboard := hub.SubscribeAssessment(3)
+	other, unsubscribeOther := hub.SubscribeAssessment(4)
+	student, unsubscribeStudent := hub.Subscribe(1)
+	defer unsubscribeOther()
+	defer unsubscribeStudent()
+
+	attempt := &models.Attempt{ID: 1, AssessmentID: 3}
+
+	// Sự kiện của bài làm tới cả kênh của bài làm lẫn bảng giám sát của bài kiểm tra
+	require.NoError(t, hub.Publish(models.NewAttemptEvent(attempt, models.LiveEventMessage, "hello")))
+	assert.Equal(t, "hello", (<-student).Data)
+	event := <-dashboard
+	assert.Equal(t, uint(1), event.AttemptID)
+	assert.Equal(t, uint(3), event.AssessmentID)
+	assert.Empty(t, other)
+
+	// Sự kiện chỉ dành cho giám thị không tới học sinh
+	require.NoError(t, hub.Publish(models.NewAttemptEvent(attempt, models.LiveEventHeartbeat, nil)))
+	assert.Equal(t, models.LiveEventHeartbeat, (<-dashboard).Type)
+	assert.Empty(t, student)
+
+	// Sự kiện không có bài kiểm tra chỉ tới kênh của bài làm
+	require.NoError(t, hub.Publish(models.NewLiveEvent(1, models.LiveEventTime, nil)))
+	assert.Equal(t, models.LiveEventTime, (<-student).Type)
+	assert.Empty(t, dashboard)
+
+	unsubscribeDashboard()
+	_, open := <-dashboard
+	assert.False(t, open)
+}
+
+func TestMemoryHub_SlowSubscriber(t *testing.T) {
+	hub := NewMemoryHub()
+	events, unsubscribe := hub.Subscribe(1)
+	defer unsubscribe()
+
+	// Người nhận không đọc thì sự kiện bị bỏ thay vì chặn người gửi
+	for i := 0; i < subscriberBuffer+5; i++ {
+		require.NoError(t, hub.Publish(models.NewLiveEvent(1, models.LiveEventTime, nil)))
+	}
+	assert.Len(t, events, subscriberBuffer)
+}
+
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(models.LiveEvent) error { return errors.New("redis down") }
+
+func TestSend(t *testing.T) {
+	log := zaptest.NewLogger(t)
+
+	// Không có hub hoặc hub lỗi đều không làm hỏng yêu cầu
+	Send(nil, models.NewLiveEvent(1, models.LiveEventTime, nil), log)
+	Send(failingPublisher{}, models.NewLiveEvent(1, models.LiveEventTime, nil), log)
+
+	hub := NewMemoryHub()
+	events, unsubscribe := hub.Subscribe(1)
+	defer unsubscribe()
+	Send(hub, models.NewLiveEvent(1, models.LiveEventSubmitted, nil), log)
+	assert.Equal(t, models.LiveEventSubmitted, (<-events).Type)
+}
+
+func TestDecodeEvent(t *testing.T) {
+	event, err := decodeEvent(channelName(42), `{"type":"message","attemptId":7,"data":{"message":"hi"}}`)
+	require.NoError(t, err)
+	assert.Equal(t, models.LiveEventMessage, event.Type)
+	assert.Equal(t, uint(42), event.AttemptID) // Kênh quyết định bài làm
+	assert.Equal(t, map[string]interface{}{"message": "hi"}, event.Data)
+
+	_, err = decodeEvent("other:42", `{}`)
+	assert.Error(t, err)
+
+	_, err = decodeEvent(channelName(42), `not json`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,99 @@
+package live
+
+import (
+	models "assessment_service/internal/model"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// channelPrefix names the Redis channel each attempt's events are published on
+const channelPrefix = "attempts:live:"
+
+type redisHub struct {
+	client *redis.Client
+	local  Hub
+	log    *zap.Logger
+}
+
+// NewRedisHub creates a hub that publishes events through Redis, so that every instance of the
+// service sends them to the live channels open on it. It listens for events until ctx is done.
+func NewRedisHub(ctx context.Context, client *redis.Client, log *zap.Logger) Hub {
+	hub := &redisHub{client: client, local: NewMemoryHub(), log: log}
+
+	pubsub := client.PSubscribe(ctx, channelPrefix+"*")
+	go hub.listen(ctx, pubsub)
+
+	return hub
+}
+
+func (h *redisHub) Publish(event models.LiveEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode live event: %w", err)
+	}
+
+	if err := h.client.Publish(context.Background(), channelName(event.AttemptID), data).Err(); err != nil {
+		return fmt.Errorf("failed to publish live event: %w", err)
+	}
+
+	return nil
+}
+
+func (h *redisHub) Subscribe(attemptID uint) (<-chan models.LiveEvent, func()) {
+	return h.local.Subscribe(attemptID)
+}
+
+func (h *redisHub) SubscribeAssessment(assessmentID uint) (<-chan models.LiveEvent, func()) {
+	return h.local.SubscribeAssessment(assessmentID)
+}
+
+// listen hands the events published by every instance to the live channels open on this one
+func (h *redisHub) listen(ctx context.Context, pubsub *redis.PubSub) {
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			event, err := decodeEvent(message.Channel, message.Payload)
+			if err != nil {
+				h.log.Warn("[RedisHub] dropped live event", zap.String("channel", message.Channel), zap.Error(err))
+				continue
+			}
+			_ = h.local.Publish(event)
+		}
+	}
+}
+
+func channelName(attemptID uint) string {
+	return channelPrefix + strconv.FormatUint(uint64(attemptID), 10)
+}
+
+// decodeEvent reads an event published on an attempt's channel. The channel decides the attempt.
+func decodeEvent(channel, payload string) (models.LiveEvent, error) {
+	var event models.LiveEvent
+
+	attemptID, err := strconv.ParseUint(strings.TrimPrefix(channel, channelPrefix), 10, 32)
+	if err != nil || !strings.HasPrefix(channel, channelPrefix) {
+		return event, fmt.Errorf("not an attempt channel: %s", channel)
+	}
+
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		return event, fmt.Errorf("failed to decode live event: %w", err)
+	}
+	event.AttemptID = uint(attemptID)
+
+	return event, nil
+}
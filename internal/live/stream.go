@@ -0,0 +1,41 @@
+package live
+
+import (
+	models "assessment_service/internal/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// OpenStream starts a Server-Sent Events response and returns the controller that flushes its events
+func OpenStream(w http.ResponseWriter, log *zap.Logger) *http.ResponseController {
+	// The stream stays open longer than the server's write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Warn("failed to clear write deadline of live stream", zap.Error(err))
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	return rc
+}
+
+// WriteEvent writes an event in the Server-Sent Events format and flushes it to the client
+func WriteEvent(w http.ResponseWriter, rc *http.ResponseController, event models.LiveEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
@@ -2,8 +2,6 @@ package middleware
 
 import (
 	"assessment_service/internal/util"
-	"context"
-	"github.com/golang-jwt/jwt/v5"
 	"net/http"
 	"strings"
 )
@@ -16,9 +14,18 @@ func NewAuthMiddleware(jwtService util.Jwt) *AuthMiddleware {
 	return &AuthMiddleware{jwtService: jwtService}
 }
 
-func (auth *AuthMiddleware) AuthMiddleware() func(next http.Handler) http.Handler {
+// AuthMiddleware validates the bearer token of every request except those whose path
+// starts with one of publicPaths (e.g. "/auth/", "/health")
+func (auth *AuthMiddleware) AuthMiddleware(publicPaths ...string) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, path := range publicPaths {
+				if strings.HasPrefix(r.URL.Path, path) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
 			token := r.Header.Get("Authorization")
 			if token == "" {
 				util.ResponseError(w, util.Response{
@@ -51,8 +58,18 @@ func (auth *AuthMiddleware) AuthMiddleware() func(next http.Handler) http.Handle
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), "user", claims)
-			next.ServeHTTP(w, r.WithContext(ctx))
+			// A signed token whose claims don't describe a user is still unauthorized
+			principal, err := PrincipalFromClaims(claims)
+			if err != nil {
+				util.ResponseError(w, util.Response{
+					StatusCode: http.StatusUnauthorized,
+					Message:    "unauthorized",
+					Data:       nil,
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
 		})
 	}
 }
@@ -60,31 +77,16 @@ func (auth *AuthMiddleware) AuthMiddleware() func(next http.Handler) http.Handle
 func (auth *AuthMiddleware) ACLMiddleware(allowRoles ...string) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			claims := r.Context().Value("user").(jwt.MapClaims)
-			role := claims["role"].(string)
-			for _, allowRole := range allowRoles {
-				if role == allowRole {
-					next.ServeHTTP(w, r)
-					return
-				}
+			principal, ok := PrincipalFromRequest(r)
+			if !ok {
+				util.ResponseError(w, util.Response{
+					StatusCode: http.StatusUnauthorized,
+					Message:    "unauthorized",
+					Data:       nil,
+				})
+				return
 			}
-			util.ResponseError(w, util.Response{
-				StatusCode: http.StatusForbidden,
-				Message:    "forbidden",
-				Data:       nil,
-			})
-		})
-	}
-}
-
-func (auth *AuthMiddleware) OwnerMiddleware() func(next http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			claims := r.Context().Value("user").(jwt.MapClaims)
-			role := claims["role"].(string)
-			user := claims["userID"].(string)
-			if role == "admin" ||
-				(role == "user" && (r.URL.Query().Get("id") == "" || user == r.URL.Query().Get("id"))) {
+			if principal.HasRole(allowRoles...) {
 				next.ServeHTTP(w, r)
 				return
 			}
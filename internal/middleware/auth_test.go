@@ -61,7 +61,7 @@ func TestAuthMiddleware_ValidToken(t *testing.T) {
 
 	validToken := "valid.jwt.token"
 	expectedClaims := jwt.MapClaims{
-		"userID": "user123",
+		"userID": "123",
 		"role":   "student",
 		"exp":    float64(time.Now().Add(time.Hour).Unix()), // Phải là float64
 	}
@@ -83,10 +83,38 @@ func TestAuthMiddleware_ValidToken(t *testing.T) {
 
 	// Kiểm tra context
 	require.NotNil(t, nextHandler.Ctx, "Context should not be nil in next handler")
-	userClaims, ok := nextHandler.Ctx.Value("user").(jwt.MapClaims)
-	require.True(t, ok, "User claims should be in context")
-	assert.Equal(t, expectedClaims["userID"], userClaims["userID"])
-	assert.Equal(t, expectedClaims["role"], userClaims["role"])
+	principal, ok := PrincipalFromContext(nextHandler.Ctx)
+	require.True(t, ok, "Principal should be in context")
+	assert.Equal(t, uint(123), principal.UserID)
+	assert.Equal(t, "student", principal.Role)
+}
+
+func TestAuthMiddleware_MalformedClaims(t *testing.T) {
+	cases := map[string]jwt.MapClaims{
+		"NonNumericUserID": {"userID": "user123", "role": "student"},
+		"MissingUserID":    {"role": "student"},
+		"RoleNotString":    {"userID": "123", "role": 42},
+		"MissingRole":      {"userID": "123"},
+	}
+
+	for name, claims := range cases {
+		t.Run(name, func(t *testing.T) {
+			mockJwt := new(MockJwtService)
+			authMiddleware := NewAuthMiddleware(mockJwt)
+			nextHandler := &MockNextHandler{}
+
+			mockJwt.On("ValidateToken", "signed.but.odd").Return(claims, nil)
+
+			req := httptest.NewRequest("GET", "/protected", nil)
+			req.Header.Set("Authorization", "Bearer signed.but.odd")
+			rr := httptest.NewRecorder()
+
+			authMiddleware.AuthMiddleware()(nextHandler).ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusUnauthorized, rr.Code)
+			assert.False(t, nextHandler.Called)
+		})
+	}
 }
 
 func TestAuthMiddleware_MissingHeader(t *testing.T) {
@@ -105,6 +133,22 @@ func TestAuthMiddleware_MissingHeader(t *testing.T) {
 	mockJwt.AssertNotCalled(t, "ValidateToken", mock.Anything) // ValidateToken không được gọi
 }
 
+func TestAuthMiddleware_PublicPath(t *testing.T) {
+	mockJwt := new(MockJwtService)
+	authMiddleware := NewAuthMiddleware(mockJwt)
+	nextHandler := &MockNextHandler{}
+
+	req := httptest.NewRequest("POST", "/auth/login", nil) // No Authorization header
+	rr := httptest.NewRecorder()
+
+	middlewareChain := authMiddleware.AuthMiddleware("/auth/", "/health")(nextHandler)
+	middlewareChain.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, nextHandler.Called, "Public paths should bypass authentication")
+	mockJwt.AssertNotCalled(t, "ValidateToken", mock.Anything)
+}
+
 func TestAuthMiddleware_InvalidHeaderFormat_NoBearer(t *testing.T) {
 	mockJwt := new(MockJwtService)
 	authMiddleware := NewAuthMiddleware(mockJwt)
@@ -170,11 +214,11 @@ func TestACLMiddleware_AllowedRole(t *testing.T) {
 	nextHandler := &MockNextHandler{}
 
 	allowedRoles := []string{"admin", "teacher"}
-	userClaims := jwt.MapClaims{"role": "teacher"} // Role được phép
+	principal := &Principal{Role: "teacher"} // Role được phép
 
 	req := httptest.NewRequest("GET", "/admin/resource", nil)
-	// Tạo context với claims giả lập (như AuthMiddleware đã làm)
-	ctx := context.WithValue(req.Context(), "user", userClaims)
+	// Tạo context với Principal giả lập (như AuthMiddleware đã làm)
+	ctx := WithPrincipal(req.Context(), principal)
 	req = req.WithContext(ctx)
 	rr := httptest.NewRecorder()
 
@@ -191,10 +235,10 @@ func TestACLMiddleware_ForbiddenRole(t *testing.T) {
 	nextHandler := &MockNextHandler{}
 
 	allowedRoles := []string{"admin", "teacher"}
-	userClaims := jwt.MapClaims{"role": "student"} // Role không được phép
+	principal := &Principal{Role: "student"} // Role không được phép
 
 	req := httptest.NewRequest("GET", "/admin/resource", nil)
-	ctx := context.WithValue(req.Context(), "user", userClaims)
+	ctx := WithPrincipal(req.Context(), principal)
 	req = req.WithContext(ctx)
 	rr := httptest.NewRecorder()
 
@@ -212,122 +256,15 @@ func TestACLMiddleware_NoUserInContext(t *testing.T) {
 
 	allowedRoles := []string{"admin"}
 
-	req := httptest.NewRequest("GET", "/admin/resource", nil) // Context không có key "user"
+	req := httptest.NewRequest("GET", "/admin/resource", nil) // Context không có Principal
 	rr := httptest.NewRecorder()
 
 	middlewareChain := authMiddleware.ACLMiddleware(allowedRoles...)(nextHandler)
 
-	// Middleware sẽ panic nếu context không có "user" và không được ép kiểu jwt.MapClaims
-	// Chúng ta cần bắt panic này hoặc đảm bảo AuthMiddleware luôn chạy trước.
-	// Trong thực tế, nếu AuthMiddleware chạy trước, trường hợp này sẽ không xảy ra.
-	// Để test an toàn, ta có thể kiểm tra panic.
-	assert.Panics(t, func() {
+	// Không có Principal trong context thì trả về 401 thay vì panic
+	assert.NotPanics(t, func() {
 		middlewareChain.ServeHTTP(rr, req)
-	}, "ACLMiddleware should panic if 'user' claim is missing or not MapClaims")
-
-	// Hoặc, nếu bạn muốn nó trả về lỗi thay vì panic:
-	// middlewareChain.ServeHTTP(rr, req)
-	// assert.Equal(t, http.StatusInternalServerError, rr.Code) // Hoặc Forbidden
-	// assert.False(t, nextHandler.Called)
-}
-
-// --- Test OwnerMiddleware ---
-
-func TestOwnerMiddleware_AdminRole(t *testing.T) {
-	mockJwt := new(MockJwtService)
-	authMiddleware := NewAuthMiddleware(mockJwt)
-	nextHandler := &MockNextHandler{}
-
-	userClaims := jwt.MapClaims{"role": "admin", "userID": "admin1"}
-
-	req := httptest.NewRequest("GET", "/users/otherUser/data", nil) // Admin truy cập tài nguyên của người khác
-	ctx := context.WithValue(req.Context(), "user", userClaims)
-	req = req.WithContext(ctx)
-	rr := httptest.NewRecorder()
-
-	middlewareChain := authMiddleware.OwnerMiddleware()(nextHandler)
-	middlewareChain.ServeHTTP(rr, req)
-
-	assert.Equal(t, http.StatusOK, rr.Code)
-	assert.True(t, nextHandler.Called)
-}
-
-func TestOwnerMiddleware_UserAccessOwnData_WithMatchingID(t *testing.T) {
-	mockJwt := new(MockJwtService)
-	authMiddleware := NewAuthMiddleware(mockJwt)
-	nextHandler := &MockNextHandler{}
-
-	userID := "user123"
-	userClaims := jwt.MapClaims{"role": "user", "userID": userID}
-
-	req := httptest.NewRequest("GET", "/users/data?id="+userID, nil) // User truy cập data với id khớp
-	ctx := context.WithValue(req.Context(), "user", userClaims)
-	req = req.WithContext(ctx)
-	rr := httptest.NewRecorder()
-
-	middlewareChain := authMiddleware.OwnerMiddleware()(nextHandler)
-	middlewareChain.ServeHTTP(rr, req)
-
-	assert.Equal(t, http.StatusOK, rr.Code)
-	assert.True(t, nextHandler.Called)
-}
-
-func TestOwnerMiddleware_UserAccessOwnData_WithoutIDParam(t *testing.T) {
-	mockJwt := new(MockJwtService)
-	authMiddleware := NewAuthMiddleware(mockJwt)
-	nextHandler := &MockNextHandler{}
-
-	userID := "user123"
-	userClaims := jwt.MapClaims{"role": "user", "userID": userID}
-
-	req := httptest.NewRequest("GET", "/users/mydata", nil) // User truy cập endpoint không yêu cầu ID param
-	ctx := context.WithValue(req.Context(), "user", userClaims)
-	req = req.WithContext(ctx)
-	rr := httptest.NewRecorder()
-
-	middlewareChain := authMiddleware.OwnerMiddleware()(nextHandler)
-	middlewareChain.ServeHTTP(rr, req)
-
-	assert.Equal(t, http.StatusOK, rr.Code)
-	assert.True(t, nextHandler.Called)
-}
-
-func TestOwnerMiddleware_UserAccessOtherData_Forbidden(t *testing.T) {
-	mockJwt := new(MockJwtService)
-	authMiddleware := NewAuthMiddleware(mockJwt)
-	nextHandler := &MockNextHandler{}
-
-	userID := "user123"
-	otherUserID := "user456"
-	userClaims := jwt.MapClaims{"role": "user", "userID": userID}
-
-	req := httptest.NewRequest("GET", "/users/otherdata?id="+otherUserID, nil) // User cố truy cập data của người khác
-	ctx := context.WithValue(req.Context(), "user", userClaims)
-	req = req.WithContext(ctx)
-	rr := httptest.NewRecorder()
-
-	middlewareChain := authMiddleware.OwnerMiddleware()(nextHandler)
-	middlewareChain.ServeHTTP(rr, req)
-
-	assert.Equal(t, http.StatusForbidden, rr.Code)
-	assert.False(t, nextHandler.Called)
-}
-
-func TestOwnerMiddleware_OtherRole_Forbidden(t *testing.T) {
-	mockJwt := new(MockJwtService)
-	authMiddleware := NewAuthMiddleware(mockJwt)
-	nextHandler := &MockNextHandler{}
-
-	userClaims := jwt.MapClaims{"role": "guest", "userID": "guest1"} // Role không phải admin/user
-
-	req := httptest.NewRequest("GET", "/users/data?id=guest1", nil)
-	ctx := context.WithValue(req.Context(), "user", userClaims)
-	req = req.WithContext(ctx)
-	rr := httptest.NewRecorder()
-
-	middlewareChain := authMiddleware.OwnerMiddleware()(nextHandler)
-	middlewareChain.ServeHTTP(rr, req)
-
-	assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
 	assert.False(t, nextHandler.Called)
 }
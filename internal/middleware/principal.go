@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidPrincipal = errors.New("token does not carry a valid principal")
+
+// Principal is the authenticated caller, built once from the token claims by AuthMiddleware
+type Principal struct {
+	UserID uint
+	Role   string
+	Tenant string
+	Scopes []string
+}
+
+// HasRole reports whether the principal has one of the given roles
+func (p *Principal) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if p.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope reports whether the token was granted the given scope
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Principal) IsAdmin() bool {
+	return p.Role == "admin"
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal set by AuthMiddleware, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	if !ok || principal == nil {
+		return nil, false
+	}
+	return principal, true
+}
+
+// PrincipalFromRequest is a shorthand for PrincipalFromContext(r.Context())
+func PrincipalFromRequest(r *http.Request) (*Principal, bool) {
+	return PrincipalFromContext(r.Context())
+}
+
+// PrincipalFromClaims converts validated token claims into a Principal. The user ID must be a
+// positive integer (as a string or number) and the role a non-empty string. Tenant ("tenant") and
+// scopes ("scope" as a space-separated string, or "scopes" as a list) are optional.
+func PrincipalFromClaims(claims jwt.MapClaims) (*Principal, error) {
+	userID, err := parseUserID(claims["userID"])
+	if err != nil {
+		return nil, err
+	}
+
+	role, ok := claims["role"].(string)
+	if !ok || role == "" {
+		return nil, ErrInvalidPrincipal
+	}
+
+	principal := &Principal{
+		UserID: userID,
+		Role:   strings.ToLower(role),
+	}
+
+	if tenant, ok := claims["tenant"].(string); ok {
+		principal.Tenant = tenant
+	}
+
+	if scope, ok := claims["scope"].(string); ok {
+		principal.Scopes = strings.Fields(scope)
+	} else if scopes, ok := claims["scopes"].([]interface{}); ok {
+		for _, s := range scopes {
+			if str, ok := s.(string); ok {
+				principal.Scopes = append(principal.Scopes, str)
+			}
+		}
+	}
+
+	return principal, nil
+}
+
+func parseUserID(value interface{}) (uint, error) {
+	switch v := value.(type) {
+	case string:
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || id == 0 {
+			return 0, ErrInvalidPrincipal
+		}
+		return uint(id), nil
+	case float64:
+		if v <= 0 || v != float64(uint32(v)) {
+			return 0, ErrInvalidPrincipal
+		}
+		return uint(v), nil
+	default:
+		return 0, ErrInvalidPrincipal
+	}
+}
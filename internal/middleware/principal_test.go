@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrincipalFromClaims_Valid(t *testing.T) {
+	claims := jwt.MapClaims{
+		"userID": "42",
+		"role":   "Teacher",
+		"tenant": "school-a",
+		"scope":  "assessments:read assessments:write",
+	}
+
+	principal, err := PrincipalFromClaims(claims)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), principal.UserID)
+	assert.Equal(t, "teacher", principal.Role) // Role được chuẩn hóa về chữ thường
+	assert.Equal(t, "school-a", principal.Tenant)
+	assert.True(t, principal.HasScope("assessments:write"))
+	assert.False(t, principal.HasScope("users:write"))
+	assert.True(t, principal.HasRole("admin", "teacher"))
+	assert.False(t, principal.IsAdmin())
+}
+
+func TestPrincipalFromClaims_NumericUserIDAndScopeList(t *testing.T) {
+	claims := jwt.MapClaims{
+		"userID": float64(7), // JSON numbers are decoded as float64
+		"role":   "admin",
+		"scopes": []interface{}{"a", "b"},
+	}
+
+	principal, err := PrincipalFromClaims(claims)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(7), principal.UserID)
+	assert.Equal(t, []string{"a", "b"}, principal.Scopes)
+	assert.True(t, principal.IsAdmin())
+}
+
+func TestPrincipalFromClaims_Invalid(t *testing.T) {
+	cases := map[string]jwt.MapClaims{
+		"MissingUserID":    {"role": "student"},
+		"NonNumericUserID": {"userID": "abc", "role": "student"},
+		"ZeroUserID":       {"userID": "0", "role": "student"},
+		"NegativeUserID":   {"userID": float64(-1), "role": "student"},
+		"FractionalUserID": {"userID": 1.5, "role": "student"},
+		"UserIDWrongType":  {"userID": true, "role": "student"},
+		"MissingRole":      {"userID": "1"},
+		"EmptyRole":        {"userID": "1", "role": ""},
+		"RoleWrongType":    {"userID": "1", "role": []string{"admin"}},
+	}
+
+	for name, claims := range cases {
+		t.Run(name, func(t *testing.T) {
+			principal, err := PrincipalFromClaims(claims)
+			assert.ErrorIs(t, err, ErrInvalidPrincipal)
+			assert.Nil(t, principal)
+		})
+	}
+}
+
+func TestPrincipalFromContext(t *testing.T) {
+	_, ok := PrincipalFromContext(context.Background())
+	assert.False(t, ok)
+
+	// A plain string key must not collide with the unexported key
+	ctx := context.WithValue(context.Background(), "user", &Principal{UserID: 1, Role: "admin"})
+	_, ok = PrincipalFromContext(ctx)
+	assert.False(t, ok)
+
+	ctx = WithPrincipal(context.Background(), &Principal{UserID: 5, Role: "student"})
+	principal, ok := PrincipalFromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, uint(5), principal.UserID)
+}
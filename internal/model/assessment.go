@@ -7,34 +7,219 @@ import (
 )
 
 type Assessment struct {
-	ID           uint               `json:"id" gorm:"primaryKey"`
-	Title        string             `json:"title" gorm:"size:255;not null"`
-	Subject      string             `json:"subject" gorm:"size:100;not null"`
-	Description  string             `json:"description" gorm:"type:text"`
-	Duration     int                `json:"duration" gorm:"not null"` // in minutes
-	Status       string             `json:"status" gorm:"size:50;not null;default:Draft"`
-	DueDate      *time.Time         `json:"dueDate" gorm:"type:date"`
-	CreatedByID  uint               `json:"createdById" gorm:"not null"`
-	CreatedBy    User               `json:"createdBy" gorm:"foreignKey:CreatedByID"`
-	PassingScore float64            `json:"passingScore" gorm:"not null;default:70"`
-	Questions    []Question         `json:"questions" gorm:"foreignKey:AssessmentID"`
-	Settings     AssessmentSettings `json:"settings" gorm:"foreignKey:AssessmentID"`
-	CreatedAt    time.Time          `json:"createdAt" gorm:"autoCreateTime"`
-	UpdatedAt    time.Time          `json:"updatedAt" gorm:"autoUpdateTime"`
-	DeletedAt    gorm.DeletedAt     `json:"-" gorm:"index"`
+	ID          uint             `json:"id" gorm:"primaryKey"`
+	Title       string           `json:"title" gorm:"size:255;not null"`
+	Subject     string           `json:"subject" gorm:"size:100;not null"`
+	Description string           `json:"description" gorm:"type:text"`
+	Duration    int              `json:"duration" gorm:"not null"` // in minutes
+	Status      AssessmentStatus `json:"status" gorm:"size:50;not null;default:Draft"`
+	DueDate     *time.Time       `json:"dueDate" gorm:"type:date"`
+	// AvailableFrom and AvailableUntil bound when students may take the assessment. AvailableUntil
+	// takes precedence over DueDate.
+	AvailableFrom  *time.Time          `json:"availableFrom"`
+	AvailableUntil *time.Time          `json:"availableUntil"`
+	CreatedByID    uint                `json:"createdById" gorm:"not null"`
+	CreatedBy      User                `json:"createdBy" gorm:"foreignKey:CreatedByID"`
+	PassingScore   float64             `json:"passingScore" gorm:"not null;default:70"`
+	Questions      []Question          `json:"questions" gorm:"foreignKey:AssessmentID"`
+	Sections       []AssessmentSection `json:"sections,omitempty" gorm:"foreignKey:AssessmentID"`
+	Settings       AssessmentSettings  `json:"settings" gorm:"foreignKey:AssessmentID"`
+	CreatedAt      time.Time           `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time           `json:"updatedAt" gorm:"autoUpdateTime"`
+	DeletedAt      gorm.DeletedAt      `json:"-" gorm:"index"`
 }
 
 type AssessmentSettings struct {
-	ID                          uint      `json:"id" gorm:"primaryKey"`
-	AssessmentID                uint      `json:"assessmentId" gorm:"uniqueIndex;not null"`
-	RandomizeQuestions          bool      `json:"randomizeQuestions" gorm:"default:false"`
-	ShowResults                 bool      `json:"showResults" gorm:"default:true"`
-	AllowRetake                 bool      `json:"allowRetake" gorm:"default:false"`
-	MaxAttempts                 int       `json:"maxAttempts" gorm:"default:1"`
-	TimeLimitEnforced           bool      `json:"timeLimitEnforced" gorm:"default:true"`
-	RequireWebcam               bool      `json:"requireWebcam" gorm:"default:false"`
-	PreventTabSwitching         bool      `json:"preventTabSwitching" gorm:"default:false"`
-	RequireIdentityVerification bool      `json:"requireIdentityVerification" gorm:"default:false"`
-	CreatedAt                   time.Time `json:"createdAt" gorm:"autoCreateTime"`
-	UpdatedAt                   time.Time `json:"updatedAt" gorm:"autoUpdateTime"`
+	ID                          uint       `json:"id" gorm:"primaryKey"`
+	AssessmentID                uint       `json:"assessmentId" gorm:"uniqueIndex;not null"`
+	RandomizeQuestions          bool       `json:"randomizeQuestions" gorm:"default:false"`
+	ShuffleOptions              bool       `json:"shuffleOptions" gorm:"not null;default:false"`     // each attempt gets the options of a question in its own order
+	ResumePolicy                string     `json:"resumePolicy" gorm:"size:20;not null;default:any"` // any, same_ip, same_device
+	ShowResults                 bool       `json:"showResults" gorm:"default:true"`
+	AllowRetake                 bool       `json:"allowRetake" gorm:"default:false"`
+	MaxAttempts                 int        `json:"maxAttempts" gorm:"default:1"`
+	TimeLimitEnforced           bool       `json:"timeLimitEnforced" gorm:"default:true"`
+	GracePeriod                 int        `json:"gracePeriod" gorm:"not null;default:0"` // seconds answers are still taken after time runs out
+	RequireWebcam               bool       `json:"requireWebcam" gorm:"default:false"`
+	PreventTabSwitching         bool       `json:"preventTabSwitching" gorm:"default:false"`
+	RequireIdentityVerification bool       `json:"requireIdentityVerification" gorm:"default:false"`
+	LatePolicy                  string     `json:"latePolicy" gorm:"size:20;not null;default:reject"` // reject, penalty, cutoff
+	LatePenalty                 float64    `json:"latePenalty" gorm:"not null;default:0"`             // percent taken off late work
+	LateCutoff                  *time.Time `json:"lateCutoff"`                                        // no late work after this
+	BlindGrading                bool       `json:"blindGrading" gorm:"not null;default:false"`        // hide who wrote an answer from graders
+	DoubleMarking               bool       `json:"doubleMarking" gorm:"not null;default:false"`       // two graders mark every manually graded answer
+	ReconciliationThreshold     float64    `json:"reconciliationThreshold" gorm:"not null;default:0"` // points two marks may differ by before they are reconciled
+	CreatedAt                   time.Time  `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt                   time.Time  `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+const (
+	// LatePolicyReject accepts no work after the assessment closes
+	LatePolicyReject = "reject"
+	// LatePolicyPenalty accepts late work, until LateCutoff when set, and takes LatePenalty percent off the score
+	LatePolicyPenalty = "penalty"
+	// LatePolicyCutoff accepts late work without a penalty until LateCutoff
+	LatePolicyCutoff = "cutoff"
+)
+
+const (
+	// ResumeAnywhere lets a student resume an attempt from any device
+	ResumeAnywhere = "any"
+	// ResumeSameIP lets a student resume an attempt only from the IP address it was started from
+	ResumeSameIP = "same_ip"
+	// ResumeSameDevice lets a student resume an attempt only from the IP address and the browser it
+	// was started from
+	ResumeSameDevice = "same_device"
+)
+
+// AllowsResume reports whether a student may resume an attempt from client under the resume policy
+func (s AssessmentSettings) AllowsResume(attempt *Attempt, client AttemptClient) bool {
+	switch s.ResumePolicy {
+	case ResumeSameIP:
+		return attempt.IPAddress == client.IPAddress
+	case ResumeSameDevice:
+		return attempt.IPAddress == client.IPAddress && attempt.UserAgent == client.UserAgent
+	}
+	return true
+}
+
+// ClosesAt is when the assessment stops accepting on-time work, nil when it never closes
+func (a *Assessment) ClosesAt() *time.Time {
+	if a.AvailableUntil != nil {
+		return a.AvailableUntil
+	}
+	return a.DueDate
+}
+
+// AcceptsUntil is when the assessment stops accepting any work, late work included. It is nil when
+// the assessment never closes or takes late work without a cutoff.
+func (a *Assessment) AcceptsUntil() *time.Time {
+	closesAt := a.ClosesAt()
+	switch {
+	case closesAt == nil:
+		return nil
+	case a.Settings.LatePolicy == LatePolicyPenalty && a.Settings.LateCutoff == nil:
+		return nil
+	case a.Settings.LatePolicy == LatePolicyPenalty, a.Settings.LatePolicy == LatePolicyCutoff:
+		if a.Settings.LateCutoff != nil && a.Settings.LateCutoff.After(*closesAt) {
+			return a.Settings.LateCutoff
+		}
+	}
+	return closesAt
+}
+
+// AssessmentStatus is where an assessment is in its lifecycle
+type AssessmentStatus string
+
+const (
+	// AssessmentDraft is being written and is not visible to students
+	AssessmentDraft AssessmentStatus = "Draft"
+	// AssessmentScheduled is published and opens at AvailableFrom
+	AssessmentScheduled AssessmentStatus = "Scheduled"
+	// AssessmentActive can be taken by the students it is assigned to
+	AssessmentActive AssessmentStatus = "Active"
+	// AssessmentClosed no longer accepts attempts but can be reopened
+	AssessmentClosed AssessmentStatus = "Closed"
+	// AssessmentArchived is kept for its results only and cannot change again
+	AssessmentArchived AssessmentStatus = "Archived"
+)
+
+// assessmentTransitions lists the statuses each status may move to
+var assessmentTransitions = map[AssessmentStatus][]AssessmentStatus{
+	AssessmentDraft:     {AssessmentScheduled, AssessmentActive, AssessmentArchived},
+	AssessmentScheduled: {AssessmentDraft, AssessmentActive, AssessmentClosed},
+	AssessmentActive:    {AssessmentClosed},
+	AssessmentClosed:    {AssessmentActive, AssessmentArchived},
+}
+
+// CanTransitionTo reports whether an assessment in status s may move to status to
+func (s AssessmentStatus) CanTransitionTo(to AssessmentStatus) bool {
+	for _, next := range assessmentTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// AssessmentStatusTransition records one change of an assessment's status. ChangedByID is nil when
+// the change was made by the scheduler.
+type AssessmentStatusTransition struct {
+	ID           uint             `json:"id" gorm:"primaryKey"`
+	AssessmentID uint             `json:"assessmentId" gorm:"not null;index"`
+	FromStatus   AssessmentStatus `json:"fromStatus" gorm:"size:50;not null"`
+	ToStatus     AssessmentStatus `json:"toStatus" gorm:"size:50;not null"`
+	ChangedByID  *uint            `json:"changedById"`
+	ChangedBy    *User            `json:"changedBy,omitempty" gorm:"foreignKey:ChangedByID"`
+	Reason       string           `json:"reason" gorm:"size:255"`
+	CreatedAt    time.Time        `json:"createdAt" gorm:"autoCreateTime"`
+}
+
+// AssessmentCollaborator gives a user other than the creator access to an assessment
+type AssessmentCollaborator struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	AssessmentID uint      `json:"assessmentId" gorm:"not null;uniqueIndex:idx_assessment_collaborator"`
+	UserID       uint      `json:"userId" gorm:"not null;uniqueIndex:idx_assessment_collaborator;index"`
+	User         User      `json:"user" gorm:"foreignKey:UserID"`
+	Role         string    `json:"role" gorm:"size:20;not null"` // owner, editor, grader, viewer
+	InvitedByID  uint      `json:"invitedById"`
+	CreatedAt    time.Time `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+// CollaboratorInviteDTO identifies the invitee either by user ID or by email
+type CollaboratorInviteDTO struct {
+	UserID uint   `json:"userId"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+}
+
+// AssessmentAssignment makes an assessment available to a group or to a single student. Exactly one
+// of GroupID and UserID is set.
+type AssessmentAssignment struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	AssessmentID uint      `json:"assessmentId" gorm:"not null;uniqueIndex:idx_assignment_group;uniqueIndex:idx_assignment_user"`
+	GroupID      *uint     `json:"groupId" gorm:"uniqueIndex:idx_assignment_group"`
+	Group        *Group    `json:"group,omitempty" gorm:"foreignKey:GroupID"`
+	UserID       *uint     `json:"userId" gorm:"uniqueIndex:idx_assignment_user"`
+	User         *User     `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	AssignedByID uint      `json:"assignedById"`
+	CreatedAt    time.Time `json:"createdAt" gorm:"autoCreateTime"`
+}
+
+// AssignmentDTO lists the groups and students to assign an assessment to
+type AssignmentDTO struct {
+	GroupIDs []uint `json:"groupIds"`
+	UserIDs  []uint `json:"userIds"`
+}
+
+// AssessmentAccommodation changes the time limit, attempt limit or due date of an assessment for a
+// single student or for every member of a group. Exactly one of GroupID and UserID is set.
+type AssessmentAccommodation struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	AssessmentID   uint       `json:"assessmentId" gorm:"not null;uniqueIndex:idx_accommodation_group;uniqueIndex:idx_accommodation_user"`
+	GroupID        *uint      `json:"groupId" gorm:"uniqueIndex:idx_accommodation_group"`
+	Group          *Group     `json:"group,omitempty" gorm:"foreignKey:GroupID"`
+	UserID         *uint      `json:"userId" gorm:"uniqueIndex:idx_accommodation_user"`
+	User           *User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	TimeMultiplier float64    `json:"timeMultiplier" gorm:"not null;default:1"`
+	ExtraMinutes   int        `json:"extraMinutes" gorm:"not null;default:0"`
+	ExtraAttempts  int        `json:"extraAttempts" gorm:"not null;default:0"`
+	DueDate        *time.Time `json:"dueDate"` // replaces the assessment due date when later
+	Reason         string     `json:"reason" gorm:"size:255"`
+	CreatedByID    uint       `json:"createdById"`
+	CreatedAt      time.Time  `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+// AccommodationDTO is the payload for creating or updating an accommodation. The target (GroupID or
+// UserID) is ignored on update.
+type AccommodationDTO struct {
+	GroupID        *uint      `json:"groupId"`
+	UserID         *uint      `json:"userId"`
+	TimeMultiplier float64    `json:"timeMultiplier"`
+	ExtraMinutes   int        `json:"extraMinutes"`
+	ExtraAttempts  int        `json:"extraAttempts"`
+	DueDate        *time.Time `json:"dueDate"`
+	Reason         string     `json:"reason"`
 }
@@ -1,45 +1,537 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 type Attempt struct {
-	ID           uint           `json:"id" gorm:"primaryKey"`
-	UserID       uint           `json:"userId" gorm:"not null;index"`
-	User         User           `json:"-" gorm:"foreignKey:UserID"`
-	AssessmentID uint           `json:"assessmentId" gorm:"not null;index"`
-	Assessment   Assessment     `json:"-" gorm:"foreignKey:AssessmentID"`
-	StartedAt    time.Time      `json:"startedAt" gorm:"not null"`
-	EndedAt      *time.Time     `json:"endedAt"`
-	SubmittedAt  *time.Time     `json:"submittedAt"`
-	Score        *float64       `json:"score"`
-	Duration     *int           `json:"duration"`                                           // in minutes
-	Status       string         `json:"status" gorm:"size:50;not null;default:In Progress"` // In Progress, Completed, Expired
-	Answers      []Answer       `json:"answers" gorm:"foreignKey:AttemptID"`
-	CreatedAt    time.Time      `json:"createdAt" gorm:"autoCreateTime"`
-	UpdatedAt    time.Time      `json:"updatedAt" gorm:"autoUpdateTime"`
-	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
-	Feedback     string         `json:"feedback"`
+	ID             uint                `json:"id" gorm:"primaryKey"`
+	UserID         uint                `json:"userId" gorm:"not null;index"`
+	User           User                `json:"-" gorm:"foreignKey:UserID"`
+	AssessmentID   uint                `json:"assessmentId" gorm:"not null;index"`
+	Assessment     Assessment          `json:"-" gorm:"foreignKey:AssessmentID"`
+	StartedAt      time.Time           `json:"startedAt" gorm:"not null"`
+	EndedAt        *time.Time          `json:"endedAt"`
+	SubmittedAt    *time.Time          `json:"submittedAt"`
+	Score          *float64            `json:"score"`
+	Duration       *int                `json:"duration"` // in minutes
+	Status         AttemptStatus       `json:"status" gorm:"size:50;not null;default:in_progress;index"`
+	Passed         *bool               `json:"passed"`                                // nil until the attempt is graded
+	IsLate         bool                `json:"isLate" gorm:"not null;default:false"`  // submitted after the assessment closed
+	LatePenalty    float64             `json:"latePenalty" gorm:"not null;default:0"` // percent taken off the score
+	Answers        []Answer            `json:"answers" gorm:"foreignKey:AttemptID"`
+	Selection      []AttemptQuestion   `json:"selection,omitempty" gorm:"foreignKey:AttemptID"` // the questions the attempt was given, in order
+	Seed           int64               `json:"seed" gorm:"not null;default:0"`                  // orders the attempt's questions and options
+	ShuffleOptions bool                `json:"shuffleOptions" gorm:"not null;default:false"`    // the assessment's option shuffle when the attempt started
+	Presented      []PresentedQuestion `json:"presented,omitempty" gorm:"-"`                    // the order the student saw, filled in for the attempt detail
+	IPAddress      string              `json:"ipAddress,omitempty" gorm:"size:45"`              // where the attempt was started from
+	UserAgent      string              `json:"userAgent,omitempty" gorm:"type:text"`            // the browser the attempt was started in
+	PausedAt       *time.Time          `json:"pausedAt"`                                        // set while a proctor has stopped the attempt's clock
+	PausedSeconds  int                 `json:"pausedSeconds" gorm:"not null;default:0"`         // time the attempt was paused for, the current pause left out
+	ExtraSeconds   int                 `json:"extraSeconds" gorm:"not null;default:0"`          // time proctors added to the attempt
+	HeartbeatAt    *time.Time          `json:"lastHeartbeatAt"`                                 // when the student's client last reported in on the live channel
+	FlaggedAt      *time.Time          `json:"flaggedAt"`                                       // set when a proctor flagged the attempt for review
+	FlaggedBy      *uint               `json:"flaggedBy"`
+	FlagReason     string              `json:"flagReason,omitempty" gorm:"type:text"`
+	CreatedAt      time.Time           `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time           `json:"updatedAt" gorm:"autoUpdateTime"`
+	DeletedAt      gorm.DeletedAt      `json:"-" gorm:"index"`
+	Feedback       string              `json:"feedback"`
+}
+
+// AttemptClient is the device a student starts or resumes an attempt from
+type AttemptClient struct {
+	IPAddress string
+	UserAgent string
+}
+
+// AttemptStatus is where an attempt is in its lifecycle. Whether the student passed is kept apart
+// in Attempt.Passed.
+type AttemptStatus string
+
+const (
+	// AttemptInProgress is being worked on by the student
+	AttemptInProgress AttemptStatus = "in_progress"
+	// AttemptSubmitted was handed in by the student and is waiting to be scored
+	AttemptSubmitted AttemptStatus = "submitted"
+	// AttemptAutoSubmitted was handed in by the scheduler when its time ran out
+	AttemptAutoSubmitted AttemptStatus = "auto_submitted"
+	// AttemptPendingManualGrading has answers, such as essays, that a teacher must grade
+	AttemptPendingManualGrading AttemptStatus = "pending_manual_grading"
+	// AttemptGraded has its final score and outcome
+	AttemptGraded AttemptStatus = "graded"
+	// AttemptVoided no longer counts towards results
+	AttemptVoided AttemptStatus = "voided"
+)
+
+// SubmittedAttemptStatuses are the statuses of attempts that were handed in and still count
+var SubmittedAttemptStatuses = []AttemptStatus{
+	AttemptSubmitted,
+	AttemptAutoSubmitted,
+	AttemptPendingManualGrading,
+	AttemptGraded,
+}
+
+// attemptTransitions lists the statuses each status may move to
+var attemptTransitions = map[AttemptStatus][]AttemptStatus{
+	AttemptInProgress:           {AttemptSubmitted, AttemptAutoSubmitted, AttemptVoided},
+	AttemptSubmitted:            {AttemptPendingManualGrading, AttemptGraded, AttemptVoided},
+	AttemptAutoSubmitted:        {AttemptPendingManualGrading, AttemptGraded, AttemptVoided},
+	AttemptPendingManualGrading: {AttemptGraded, AttemptVoided},
+	AttemptGraded:               {AttemptVoided},
+}
+
+// CanTransitionTo reports whether an attempt in status s may move to status to
+func (s AttemptStatus) CanTransitionTo(to AttemptStatus) bool {
+	for _, next := range attemptTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionTo moves the attempt to status to, or returns ErrInvalidAttemptTransition
+func (a *Attempt) TransitionTo(to AttemptStatus) error {
+	if !a.Status.CanTransitionTo(to) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidAttemptTransition, a.Status, to)
+	}
+	a.Status = to
+	return nil
+}
+
+// ErrInvalidAttemptTransition is returned when an attempt cannot move to the requested status
+var ErrInvalidAttemptTransition = errors.New("invalid attempt status transition")
+
+// AttemptQuestion is one question an attempt was given, with the section that drew it
+type AttemptQuestion struct {
+	ID         uint  `json:"-" gorm:"primaryKey"`
+	AttemptID  uint  `json:"-" gorm:"not null;uniqueIndex:idx_attempt_question"`
+	QuestionID uint  `json:"questionId" gorm:"not null;uniqueIndex:idx_attempt_question"`
+	SectionID  *uint `json:"sectionId,omitempty"`
+	Position   int   `json:"position" gorm:"not null"`
+}
+
+// GivenQuestions picks the questions the attempt was given out of the assessment's questions, in the
+// order they were given. Every attempt is drawn at least one question when it starts, so only attempts
+// started before questions were drawn per attempt have no selection; they were given every question.
+func (a *Attempt) GivenQuestions(questions []Question) []Question {
+	if len(a.Selection) == 0 {
+		return questions
+	}
+
+	byID := make(map[uint]Question, len(questions))
+	for _, question := range questions {
+		byID[question.ID] = question
+	}
+
+	selection := append([]AttemptQuestion(nil), a.Selection...)
+	sort.SliceStable(selection, func(i, j int) bool { return selection[i].Position < selection[j].Position })
+
+	given := make([]Question, 0, len(selection))
+	for _, selected := range selection {
+		// Questions deleted since the attempt started no longer count
+		if question, ok := byID[selected.QuestionID]; ok {
+			given = append(given, question)
+		}
+	}
+	return given
+}
+
+// PresentedQuestion is where a question was shown in an attempt and the order its options were shown in
+type PresentedQuestion struct {
+	QuestionID uint     `json:"questionId"`
+	Position   int      `json:"position"`
+	Options    []string `json:"options,omitempty"` // option IDs as the student saw them
+}
+
+// OptionSeed is the seed the options of one of the attempt's questions are shuffled with. Each
+// question gets its own order, and the attempt gets the same orders every time they are shown.
+func (a *Attempt) OptionSeed(questionID uint) int64 {
+	return int64(uint64(a.Seed) ^ uint64(questionID)*0x9e3779b97f4a7c15)
+}
+
+// IsPaused reports whether a proctor has stopped the attempt's clock
+func (a *Attempt) IsPaused() bool {
+	return a.PausedAt != nil
+}
+
+// TimeAdjustment is how much later the attempt runs out of time at now than its time limit says:
+// the time it was paused for, the current pause included, and the time proctors added
+func (a *Attempt) TimeAdjustment(now time.Time) time.Duration {
+	adjustment := time.Duration(a.PausedSeconds+a.ExtraSeconds) * time.Second
+	if a.PausedAt != nil && now.After(*a.PausedAt) {
+		adjustment += now.Sub(*a.PausedAt)
+	}
+	return adjustment
+}
+
+// Pause stops the attempt's clock at now, or returns ErrInvalidTimeEvent when it is already paused
+func (a *Attempt) Pause(now time.Time) error {
+	if a.PausedAt != nil {
+		return fmt.Errorf("%w: attempt is already paused", ErrInvalidTimeEvent)
+	}
+	a.PausedAt = &now
+	return nil
+}
+
+// Resume starts the attempt's clock again at now and returns how many seconds it was paused for, or
+// returns ErrInvalidTimeEvent when it is not paused
+func (a *Attempt) Resume(now time.Time) (int, error) {
+	if a.PausedAt == nil {
+		return 0, fmt.Errorf("%w: attempt is not paused", ErrInvalidTimeEvent)
+	}
+	seconds := 0
+	if now.After(*a.PausedAt) {
+		seconds = int(now.Sub(*a.PausedAt).Seconds())
+	}
+	a.PausedSeconds += seconds
+	a.PausedAt = nil
+	return seconds, nil
+}
+
+// AnsweredCount is how many of the questions the attempt was given have an answer
+func (a *Attempt) AnsweredCount() int {
+	answered := 0
+	for _, answer := range a.Answers {
+		if a.WasGiven(answer.QuestionID) {
+			answered++
+		}
+	}
+	return answered
+}
+
+// Flag marks the attempt for review by actorID
+func (a *Attempt) Flag(actorID uint, reason string, now time.Time) {
+	a.FlaggedAt = &now
+	a.FlaggedBy = &actorID
+	a.FlagReason = reason
+}
+
+// WasGiven reports whether the attempt was given the question
+func (a *Attempt) WasGiven(questionID uint) bool {
+	if len(a.Selection) == 0 {
+		return true
+	}
+	for _, selected := range a.Selection {
+		if selected.QuestionID == questionID {
+			return true
+		}
+	}
+	return false
+}
+
+// AttemptTimeEvent records a proctor pausing, resuming or extending an attempt. The events are the
+// ledger of the attempt's paused and extra time, and are only ever added.
+type AttemptTimeEvent struct {
+	ID        uint                 `json:"id" gorm:"primaryKey"`
+	AttemptID uint                 `json:"attemptId" gorm:"not null;index"`
+	Kind      AttemptTimeEventKind `json:"kind" gorm:"size:20;not null"`
+	Seconds   int                  `json:"seconds" gorm:"not null;default:0"` // how long a pause lasted, or the time an extension added
+	ActorID   uint                 `json:"actorId" gorm:"not null"`
+	Reason    string               `json:"reason" gorm:"type:text"`
+	CreatedAt time.Time            `json:"createdAt" gorm:"autoCreateTime"`
+}
+
+// AttemptTimeEventKind is what a proctor did to an attempt's clock
+type AttemptTimeEventKind string
+
+const (
+	// TimeEventPause stopped the attempt's clock
+	TimeEventPause AttemptTimeEventKind = "pause"
+	// TimeEventResume started the attempt's clock again
+	TimeEventResume AttemptTimeEventKind = "resume"
+	// TimeEventExtend added time to the attempt
+	TimeEventExtend AttemptTimeEventKind = "extend"
+)
+
+// ErrInvalidTimeEvent is returned when an attempt is paused twice or resumed while it is running
+var ErrInvalidTimeEvent = errors.New("invalid attempt time change")
+
+// AttemptTimeDTO is a proctor's change to a live attempt's clock. Minutes is only used to extend it.
+type AttemptTimeDTO struct {
+	Minutes int    `json:"minutes"`
+	Reason  string `json:"reason"`
 }
 
 type Answer struct {
-	ID         uint      `json:"id" gorm:"primaryKey;unique;not null"`
-	AttemptID  uint      `json:"attemptId" gorm:"not null;index"`
-	QuestionID uint      `json:"questionId" gorm:"not null"`
-	Answer     string    `json:"answer" gorm:"type:text"`
-	IsCorrect  *bool     `json:"isCorrect"`
-	CreatedAt  time.Time `json:"createdAt" gorm:"autoCreateTime"`
-	UpdatedAt  time.Time `json:"updatedAt" gorm:"autoUpdateTime"`
+	ID         uint   `json:"id" gorm:"primaryKey;unique;not null"`
+	AttemptID  uint   `json:"attemptId" gorm:"not null;index"`
+	QuestionID uint   `json:"questionId" gorm:"not null"`
+	Answer     string `json:"answer" gorm:"type:text"`
+	IsCorrect  *bool  `json:"isCorrect"`
+	// AwardedPoints is what the answer scored, which may be part of the question's points or, with
+	// negative marking, below zero. It is nil while the answer waits for a teacher.
+	AwardedPoints *float64 `json:"awardedPoints"`
+	// Comment is the grader's feedback on a manually graded answer
+	Comment      string        `json:"comment" gorm:"type:text"`
+	RubricScores []RubricScore `json:"rubricScores,omitempty" gorm:"foreignKey:AnswerID"`
+	GradedByID   *uint         `json:"gradedById"`
+	GradedAt     *time.Time    `json:"gradedAt"`
+	CreatedAt    time.Time     `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time     `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+// RubricScore is the mark a grader gave an answer on one criterion of the question's rubric
+type RubricScore struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	AnswerID    uint      `json:"answerId" gorm:"not null;uniqueIndex:idx_rubric_score_criterion"`
+	CriterionID string    `json:"criterionId" gorm:"size:50;not null;uniqueIndex:idx_rubric_score_criterion"`
+	Points      float64   `json:"points" gorm:"not null"`
+	Comment     string    `json:"comment" gorm:"type:text"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"autoCreateTime"`
 }
 
 type AttemptUpdateDTO struct {
-	Score    float64 `json:"score"`
-	Feedback string  `json:"feedback"`
-	Answers  []struct {
-		ID        uint `json:"id"`
-		IsCorrect bool `json:"isCorrect"`
-	} `json:"answers"`
+	// Score is used as is when no answers are graded, otherwise it is worked out from the answers
+	Score    float64          `json:"score"`
+	Feedback string           `json:"feedback"`
+	Answers  []AnswerGradeDTO `json:"answers"`
+	Reason   string           `json:"reason"` // why the grade changed, kept in the grade history
+}
+
+// AnswerGradeDTO is a teacher's grade for one answer. Without AwardedPoints a correct answer gets
+// the question's points and a wrong one is marked down by the question's penalty.
+type AnswerGradeDTO struct {
+	ID            uint     `json:"id"`
+	IsCorrect     bool     `json:"isCorrect"`
+	AwardedPoints *float64 `json:"awardedPoints"`
+}
+
+// AnswerGradeInput is a grader's mark for one manually graded answer. Answers to questions with a
+// rubric are marked with RubricScores, other answers with Points.
+type AnswerGradeInput struct {
+	Points       *float64      `json:"points"`
+	RubricScores []RubricScore `json:"rubricScores"`
+	Comment      string        `json:"comment"`
+	Reason       string        `json:"reason"` // why the mark changed, kept in the grade history
+}
+
+// GradingAssignment gives a grader one manually graded answer to mark. With double marking each
+// answer is given to two graders, whose marks are compared before the answer gets its points.
+type GradingAssignment struct {
+	ID           uint                    `json:"id" gorm:"primaryKey"`
+	AssessmentID uint                    `json:"assessmentId" gorm:"not null;index"`
+	AttemptID    uint                    `json:"attemptId" gorm:"not null;index"`
+	AnswerID     uint                    `json:"answerId" gorm:"not null;uniqueIndex:idx_grading_assignment_grader"`
+	GraderID     uint                    `json:"graderId" gorm:"not null;uniqueIndex:idx_grading_assignment_grader;index"`
+	Grader       User                    `json:"-" gorm:"foreignKey:GraderID"`
+	Status       GradingAssignmentStatus `json:"status" gorm:"size:20;not null;default:assigned"`
+	Points       *float64                `json:"points"` // the grader's mark, nil until it is given
+	RubricScores RubricScoreList         `json:"rubricScores,omitempty" gorm:"type:text"`
+	Comment      string                  `json:"comment" gorm:"type:text"`
+	CompletedAt  *time.Time              `json:"completedAt"`
+	CreatedAt    time.Time               `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time               `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+// GradingAssignmentStatus is whether a grader has marked the answer they were given
+type GradingAssignmentStatus string
+
+const (
+	// GradingAssigned waits for the grader's mark
+	GradingAssigned GradingAssignmentStatus = "assigned"
+	// GradingCompleted has the grader's mark
+	GradingCompleted GradingAssignmentStatus = "completed"
+)
+
+const (
+	// GradingStrategyRoundRobin hands out attempts to the graders in turn
+	GradingStrategyRoundRobin = "round_robin"
+	// GradingStrategyAssessment gives every attempt of the assessment to the same graders
+	GradingStrategyAssessment = "assessment"
+)
+
+// GraderAssignmentDTO asks for the ungraded answers of an assessment to be handed out to graders.
+// Without GraderIDs the assessment's creator and its owner and grader collaborators are used.
+type GraderAssignmentDTO struct {
+	Strategy  string `json:"strategy"`
+	GraderIDs []uint `json:"graderIds"`
+}
+
+// RubricScoreList keeps a grader's rubric scores on a grading assignment as JSON
+type RubricScoreList []RubricScore
+
+func (l RubricScoreList) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal([]RubricScore(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (l *RubricScoreList) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("cannot scan %T into RubricScoreList", value)
+	}
+	return json.Unmarshal(data, (*[]RubricScore)(l))
+}
+
+// GradeEvent records one change of an attempt's score, or of the points of one of its answers when
+// AnswerID is set. Events are only ever added. ActorID is nil when the change was made
+// automatically, such as scoring on submission.
+type GradeEvent struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	AttemptID uint           `json:"attemptId" gorm:"not null;index"`
+	AnswerID  *uint          `json:"answerId"`
+	Kind      GradeEventKind `json:"kind" gorm:"size:20;not null"`
+	ActorID   *uint          `json:"actorId"`
+	Actor     *User          `json:"actor,omitempty" gorm:"foreignKey:ActorID"`
+	OldValue  *float64       `json:"oldValue"`
+	NewValue  *float64       `json:"newValue"`
+	Reason    string         `json:"reason" gorm:"type:text"`
+	CreatedAt time.Time      `json:"createdAt" gorm:"autoCreateTime"`
+}
+
+// GradeEventKind is what caused a grade to change
+type GradeEventKind string
+
+const (
+	// GradeEventAuto is scoring done without a grader, when the attempt is submitted
+	GradeEventAuto GradeEventKind = "auto"
+	// GradeEventManual is a grader's first grade
+	GradeEventManual GradeEventKind = "manual"
+	// GradeEventRegrade changes a grade that was already given
+	GradeEventRegrade GradeEventKind = "regrade"
+)
+
+// ErrGradeEventImmutable is returned when a recorded grade event would be changed or removed
+var ErrGradeEventImmutable = errors.New("grade events cannot be changed")
+
+// BeforeUpdate keeps recorded grade events from being changed
+func (e *GradeEvent) BeforeUpdate(*gorm.DB) error {
+	return ErrGradeEventImmutable
+}
+
+// BeforeDelete keeps recorded grade events from being removed
+func (e *GradeEvent) BeforeDelete(*gorm.DB) error {
+	return ErrGradeEventImmutable
+}
+
+// GradeChange is what one grading step changed. Its parts are saved together, so a grade never
+// changes without its events and a regrade request is only closed with the regrade it asked for.
+type GradeChange struct {
+	// Attempt is saved with its score, outcome and status when set
+	Attempt *Attempt
+	// Answer is saved with its grader's mark and rubric scores when set
+	Answer *Answer
+	// Assignment is saved with the grader's mark when set
+	Assignment *GradingAssignment
+	// Request is saved with its review when set
+	Request *RegradeRequest
+	Events  []GradeEvent
+}
+
+// ScoreEvent describes the change of the attempt's score from oldScore to its current score. It
+// returns false when the score did not change.
+func (a *Attempt) ScoreEvent(oldScore *float64, kind GradeEventKind, actorID *uint, reason string) (GradeEvent, bool) {
+	if sameValue(oldScore, a.Score) {
+		return GradeEvent{}, false
+	}
+	return GradeEvent{AttemptID: a.ID, Kind: kind, ActorID: actorID, OldValue: oldScore, NewValue: a.Score, Reason: reason}, true
+}
+
+// PointsEvent describes the change of the answer's points from oldPoints to its current points. It
+// returns false when the points did not change.
+func (a *Answer) PointsEvent(oldPoints *float64, kind GradeEventKind, actorID *uint, reason string) (GradeEvent, bool) {
+	if sameValue(oldPoints, a.AwardedPoints) {
+		return GradeEvent{}, false
+	}
+	answerID := a.ID
+	return GradeEvent{AttemptID: a.AttemptID, AnswerID: &answerID, Kind: kind, ActorID: actorID, OldValue: oldPoints, NewValue: a.AwardedPoints, Reason: reason}, true
+}
+
+func sameValue(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// RegradeRequest is a student asking for a graded attempt, or one answer of it, to be looked at
+// again. A grader accepts it with a new grade or rejects it.
+type RegradeRequest struct {
+	ID           uint                 `json:"id" gorm:"primaryKey"`
+	AttemptID    uint                 `json:"attemptId" gorm:"not null;index"`
+	UserID       uint                 `json:"userId" gorm:"not null;index"`
+	AnswerID     *uint                `json:"answerId"`
+	Reason       string               `json:"reason" gorm:"type:text;not null"`
+	Status       RegradeRequestStatus `json:"status" gorm:"size:20;not null;default:pending;index"`
+	Response     string               `json:"response" gorm:"type:text"`
+	ReviewedByID *uint                `json:"reviewedById"`
+	ReviewedAt   *time.Time           `json:"reviewedAt"`
+	CreatedAt    time.Time            `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time            `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+// RegradeRequestStatus is where a regrade request is in its review
+type RegradeRequestStatus string
+
+const (
+	// RegradePending waits for a grader
+	RegradePending RegradeRequestStatus = "pending"
+	// RegradeAccepted led to a new grade
+	RegradeAccepted RegradeRequestStatus = "accepted"
+	// RegradeRejected left the grade as it was
+	RegradeRejected RegradeRequestStatus = "rejected"
+)
+
+// RegradeRequestDTO is a student's regrade request, for the whole attempt or for one answer
+type RegradeRequestDTO struct {
+	AnswerID *uint  `json:"answerId"`
+	Reason   string `json:"reason"`
+}
+
+// RegradeDecisionDTO is a grader's answer to a regrade request. Accepting it needs a new grade:
+// either a Score, or grades for Answers from which the score is worked out again.
+type RegradeDecisionDTO struct {
+	Response string           `json:"response"`
+	Score    *float64         `json:"score"`
+	Answers  []AnswerGradeDTO `json:"answers"`
+}
+
+// RescoreDTO limits rescoring to some questions of an assessment. Without questions every question
+// is scored again.
+type RescoreDTO struct {
+	QuestionIDs []uint `json:"questionIds"`
+}
+
+// RescoreSummary reports what scoring the answers of an assessment again changed
+type RescoreSummary struct {
+	AssessmentID    uint            `json:"assessmentId"`
+	AttemptsChecked int             `json:"attemptsChecked"`
+	AttemptsChanged int             `json:"attemptsChanged"`
+	AnswersChanged  int             `json:"answersChanged"`
+	Changes         []RescoreChange `json:"changes"`
+}
+
+// RescoreChange is an attempt whose answers, score or outcome changed when it was scored again. The
+// student is left out for blind assessments.
+type RescoreChange struct {
+	AttemptID      uint     `json:"attemptId"`
+	UserID         uint     `json:"userId,omitempty"`
+	OldScore       *float64 `json:"oldScore"`
+	NewScore       *float64 `json:"newScore"`
+	OldPassed      *bool    `json:"oldPassed"`
+	NewPassed      *bool    `json:"newPassed"`
+	AnswersChanged int      `json:"answersChanged"`
 }
@@ -0,0 +1,206 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BankQuestion is a reusable question in a teacher's question bank, or in their department's bank
+// when it has a department. Its content is kept in versions: editing it adds a version, and an
+// assessment keeps a copy of the version it was given.
+type BankQuestion struct {
+	ID             uint               `json:"id" gorm:"primaryKey"`
+	OwnerID        uint               `json:"ownerId" gorm:"not null;index"`
+	Owner          *User              `json:"owner,omitempty" gorm:"foreignKey:OwnerID"`
+	Department     string             `json:"department,omitempty" gorm:"size:100;index"` // teachers of the department share the question
+	Subject        string             `json:"subject" gorm:"size:100;index"`
+	Topic          string             `json:"topic" gorm:"size:100;index"`
+	Difficulty     QuestionDifficulty `json:"difficulty" gorm:"size:20;index"`
+	Tags           QuestionTags       `json:"tags" gorm:"type:text"`
+	CurrentVersion int                `json:"currentVersion" gorm:"not null;default:1"`
+	// Latest is the content of the current version
+	Latest    *BankQuestionVersion `json:"latest,omitempty" gorm:"-"`
+	CreatedAt time.Time            `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt time.Time            `json:"updatedAt" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt       `json:"-" gorm:"index"`
+}
+
+// QuestionDifficulty is how hard a bank question is meant to be
+type QuestionDifficulty string
+
+const (
+	DifficultyEasy   QuestionDifficulty = "easy"
+	DifficultyMedium QuestionDifficulty = "medium"
+	DifficultyHard   QuestionDifficulty = "hard"
+)
+
+// Valid reports whether d is a known difficulty. Questions may be left without one.
+func (d QuestionDifficulty) Valid() bool {
+	switch d {
+	case "", DifficultyEasy, DifficultyMedium, DifficultyHard:
+		return true
+	}
+	return false
+}
+
+// BankQuestionVersion is the content of a bank question as it was saved once. Versions are never
+// changed, so assessments that use one keep scoring their answers the same way.
+type BankQuestionVersion struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	BankQuestionID uint           `json:"bankQuestionId" gorm:"not null;uniqueIndex:idx_bank_question_version"`
+	Version        int            `json:"version" gorm:"not null;uniqueIndex:idx_bank_question_version"`
+	Type           string         `json:"type" gorm:"size:50;not null"`
+	Text           string         `json:"text" gorm:"type:text;not null"`
+	Options        BankOptions    `json:"options" gorm:"type:text"`
+	CorrectAnswer  string         `json:"correctAnswer" gorm:"size:255"`
+	Config         QuestionConfig `json:"config,omitempty" gorm:"type:text"`
+	Points         float64        `json:"points" gorm:"not null;default:1"`
+	PartialCredit  bool           `json:"partialCredit" gorm:"not null;default:false"`
+	Penalty        float64        `json:"penalty" gorm:"not null;default:0"`
+	AllowNegative  bool           `json:"allowNegative" gorm:"not null;default:false"`
+	CreatedByID    uint           `json:"createdById" gorm:"not null"`
+	CreatedAt      time.Time      `json:"createdAt" gorm:"autoCreateTime"`
+}
+
+// ErrBankVersionImmutable is returned when a saved version of a bank question would be changed or
+// removed
+var ErrBankVersionImmutable = errors.New("question bank versions cannot be changed")
+
+// BeforeUpdate keeps saved versions from being changed
+func (v *BankQuestionVersion) BeforeUpdate(*gorm.DB) error {
+	return ErrBankVersionImmutable
+}
+
+// BeforeDelete keeps saved versions from being removed
+func (v *BankQuestionVersion) BeforeDelete(*gorm.DB) error {
+	return ErrBankVersionImmutable
+}
+
+// Question is the version's content as a question of an assessment, which remembers the bank
+// question and version it was copied from
+func (v *BankQuestionVersion) Question(assessmentID uint) Question {
+	options := make([]QuestionOption, len(v.Options))
+	for i, option := range v.Options {
+		options[i] = QuestionOption{OptionID: option.OptionID, Text: option.Text}
+	}
+
+	bankQuestionID, version := v.BankQuestionID, v.Version
+	return Question{
+		AssessmentID:   assessmentID,
+		BankQuestionID: &bankQuestionID,
+		BankVersion:    &version,
+		Type:           v.Type,
+		Text:           v.Text,
+		Options:        options,
+		CorrectAnswer:  v.CorrectAnswer,
+		Config:         v.Config,
+		Points:         v.Points,
+		PartialCredit:  v.PartialCredit,
+		Penalty:        v.Penalty,
+		AllowNegative:  v.AllowNegative,
+	}
+}
+
+// BankOption is an option of a bank question version
+type BankOption struct {
+	OptionID string `json:"optionId"`
+	Text     string `json:"text"`
+}
+
+// BankOptions are the options of a bank question version, kept as JSON with the version
+type BankOptions []BankOption
+
+func (o BankOptions) Value() (driver.Value, error) {
+	if len(o) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal([]BankOption(o))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (o *BankOptions) Scan(value interface{}) error {
+	*o = nil
+	return scanJSON(value, (*[]BankOption)(o))
+}
+
+// QuestionTags label a bank question for searching. They are kept as a JSON list.
+type QuestionTags []string
+
+func (t QuestionTags) Value() (driver.Value, error) {
+	if len(t) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal([]string(t))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (t *QuestionTags) Scan(value interface{}) error {
+	*t = nil
+	return scanJSON(value, (*[]string)(t))
+}
+
+// Normalized lower cases the tags and drops empty and repeated ones
+func (t QuestionTags) Normalized() QuestionTags {
+	var result QuestionTags
+	seen := make(map[string]bool, len(t))
+	for _, tag := range t {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+func scanJSON(value interface{}, target interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), target)
+	case []byte:
+		return json.Unmarshal(v, target)
+	default:
+		return fmt.Errorf("cannot scan %T into %T", value, target)
+	}
+}
+
+// BankQuestionDTO is a teacher's bank question. Saving content that differs from the current
+// version adds a version.
+type BankQuestionDTO struct {
+	Department    string             `json:"department"`
+	Subject       string             `json:"subject"`
+	Topic         string             `json:"topic"`
+	Difficulty    QuestionDifficulty `json:"difficulty"`
+	Tags          []string           `json:"tags"`
+	Type          string             `json:"type"`
+	Text          string             `json:"text"`
+	Options       []BankOption       `json:"options"`
+	CorrectAnswer interface{}        `json:"correctAnswer"`
+	Config        QuestionConfig     `json:"config"`
+	Points        float64            `json:"points"`
+	PartialCredit bool               `json:"partialCredit"`
+	Penalty       float64            `json:"penalty"`
+	AllowNegative bool               `json:"allowNegative"`
+}
+
+// BankImportDTO picks the bank question, and optionally the version, to add to an assessment. The
+// current version is used when none is given.
+type BankImportDTO struct {
+	BankQuestionID uint `json:"bankQuestionId"`
+	Version        *int `json:"version"`
+}
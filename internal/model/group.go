@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Group is a class or cohort of students that assessments can be assigned to
+type Group struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"size:255;not null"`
+	Description string         `json:"description" gorm:"type:text"`
+	CreatedByID uint           `json:"createdById" gorm:"not null;index"`
+	MemberCount int64          `json:"memberCount" gorm:"->;-:migration"`
+	CreatedAt   time.Time      `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time      `json:"updatedAt" gorm:"autoUpdateTime"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+type GroupMember struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	GroupID   uint      `json:"groupId" gorm:"not null;uniqueIndex:idx_group_member"`
+	UserID    uint      `json:"userId" gorm:"not null;uniqueIndex:idx_group_member;index"`
+	User      User      `json:"user" gorm:"foreignKey:UserID"`
+	CreatedAt time.Time `json:"createdAt" gorm:"autoCreateTime"`
+}
+
+type GroupDTO struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// EnrollmentDTO lists the students to add to a group, by ID and/or by email
+type EnrollmentDTO struct {
+	UserIDs []uint   `json:"userIds"`
+	Emails  []string `json:"emails"`
+}
+
+// EnrollmentResult reports what happened to each requested student in a bulk enrollment
+type EnrollmentResult struct {
+	Enrolled        []uint   `json:"enrolled"`
+	AlreadyEnrolled []uint   `json:"alreadyEnrolled"`
+	NotFound        []string `json:"notFound"`
+	NotStudents     []uint   `json:"notStudents"`
+}
@@ -0,0 +1,121 @@
+package models
+
+import "time"
+
+// LiveEvent is pushed to the student taking an attempt over the attempt's live channel, and to the
+// proctors of the attempt's assessment when it names the assessment
+type LiveEvent struct {
+	Type         LiveEventType `json:"type"`
+	AttemptID    uint          `json:"attemptId"`
+	AssessmentID uint          `json:"assessmentId,omitempty"`
+	Data         interface{}   `json:"data,omitempty"`
+	SentAt       time.Time     `json:"sentAt"`
+}
+
+// LiveEventType is what a live event tells the student
+type LiveEventType string
+
+const (
+	// LiveEventTime carries the attempt's clock. Published without data it asks the open channels
+	// to send the clock again, after a proctor changed it.
+	LiveEventTime LiveEventType = "time"
+	// LiveEventSubmitted tells the student the attempt was submitted, by them or when its time ran out
+	LiveEventSubmitted LiveEventType = "submitted"
+	// LiveEventMessage is a proctor's message to the student
+	LiveEventMessage LiveEventType = "message"
+	// LiveEventMonitor is the response to a monitoring event, with its severity
+	LiveEventMonitor LiveEventType = "monitor"
+	// LiveEventAnswerSaved confirms an answer was saved, with how many questions are answered
+	LiveEventAnswerSaved LiveEventType = "answer_saved"
+	// LiveEventTerminated tells the student a proctor ended the attempt
+	LiveEventTerminated LiveEventType = "terminated"
+	// LiveEventStarted tells proctors a student started an attempt
+	LiveEventStarted LiveEventType = "started"
+	// LiveEventHeartbeat tells proctors the student's client reported in
+	LiveEventHeartbeat LiveEventType = "heartbeat"
+	// LiveEventFlagged tells proctors an attempt was flagged for review
+	LiveEventFlagged LiveEventType = "flagged"
+	// LiveEventSnapshot carries the proctoring dashboard of an assessment
+	LiveEventSnapshot LiveEventType = "snapshot"
+	// LiveEventSuspicious tells proctors the student's client reported suspicious activity
+	LiveEventSuspicious LiveEventType = "suspicious"
+)
+
+// ProctorOnly reports whether the event is for the assessment's proctors and not sent to the student
+func (t LiveEventType) ProctorOnly() bool {
+	switch t {
+	case LiveEventStarted, LiveEventHeartbeat, LiveEventFlagged, LiveEventSnapshot, LiveEventSuspicious:
+		return true
+	}
+	return false
+}
+
+// NewLiveEvent creates an event of the attempt sent now
+func NewLiveEvent(attemptID uint, kind LiveEventType, data interface{}) LiveEvent {
+	return LiveEvent{Type: kind, AttemptID: attemptID, Data: data, SentAt: time.Now()}
+}
+
+// NewAttemptEvent creates an event of the attempt sent now, which its assessment's proctors see too
+func NewAttemptEvent(attempt *Attempt, kind LiveEventType, data interface{}) LiveEvent {
+	event := NewLiveEvent(attempt.ID, kind, data)
+	event.AssessmentID = attempt.AssessmentID
+	return event
+}
+
+// AttemptClock is the time left on an attempt as the server counts it
+type AttemptClock struct {
+	AttemptID     uint          `json:"attemptId"`
+	Status        AttemptStatus `json:"status"`
+	EndsAt        time.Time     `json:"endsAt"`
+	TimeRemaining int64         `json:"timeRemaining"` // seconds
+	Paused        bool          `json:"paused"`
+	GracePeriod   int           `json:"gracePeriod"` // seconds
+}
+
+// LiveMessage is sent by the student's client over the attempt's live channel: a heartbeat, which
+// is answered with the attempt's clock, or an answer to save
+type LiveMessage struct {
+	Type       string      `json:"type"` // heartbeat or answer
+	QuestionID string      `json:"questionId"`
+	Answer     interface{} `json:"answer"`
+}
+
+const (
+	// LiveMessageHeartbeat asks for the attempt's clock
+	LiveMessageHeartbeat = "heartbeat"
+	// LiveMessageAnswer saves an answer
+	LiveMessageAnswer = "answer"
+)
+
+// ProctorMessageDTO is a message a proctor sends to the student taking an attempt
+type ProctorMessageDTO struct {
+	Message  string `json:"message"`
+	Severity string `json:"severity"` // INFO, WARNING or CRITICAL, INFO when empty
+}
+
+const (
+	ProctorSeverityInfo     = "INFO"
+	ProctorSeverityWarning  = "WARNING"
+	ProctorSeverityCritical = "CRITICAL"
+)
+
+// ProctorActionDTO is the reason a proctor gives when flagging or terminating an attempt
+type ProctorActionDTO struct {
+	Reason string `json:"reason"`
+}
+
+// ProctoredAttempt is an attempt in progress as its proctors see it on the live dashboard
+type ProctoredAttempt struct {
+	AttemptID       uint                 `json:"attemptId"`
+	UserID          uint                 `json:"userId"`
+	Name            string               `json:"name"`
+	Email           string               `json:"email"`
+	StartedAt       time.Time            `json:"startedAt"`
+	LastHeartbeatAt *time.Time           `json:"lastHeartbeatAt"`
+	Answered        int                  `json:"answered"`
+	Total           int                  `json:"total"` // questions the attempt was given
+	Paused          bool                 `json:"paused"`
+	FlaggedAt       *time.Time           `json:"flaggedAt"`
+	FlagReason      string               `json:"flagReason,omitempty"`
+	RecentActivity  []SuspiciousActivity `json:"recentActivity"` // newest first
+}
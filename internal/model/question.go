@@ -1,22 +1,35 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 type Question struct {
-	ID            uint             `json:"id" gorm:"primaryKey"`
-	AssessmentID  uint             `json:"assessmentId" gorm:"not null;index"`
-	Type          string           `json:"type" gorm:"size:50;not null"` // multiple-choice, true-false, essay
-	Text          string           `json:"text" gorm:"type:text;not null"`
-	Options       []QuestionOption `json:"options" gorm:"foreignKey:QuestionID"`
-	CorrectAnswer string           `json:"correctAnswer" gorm:"size:255"`
-	Points        float64          `json:"points" gorm:"not null;default:1"`
-	CreatedAt     time.Time        `json:"createdAt" gorm:"autoCreateTime"`
-	UpdatedAt     time.Time        `json:"updatedAt" gorm:"autoUpdateTime"`
-	DeletedAt     gorm.DeletedAt   `json:"-" gorm:"index"`
+	ID             uint               `json:"id" gorm:"primaryKey"`
+	AssessmentID   uint               `json:"assessmentId" gorm:"not null;index"`
+	BankQuestionID *uint              `json:"bankQuestionId,omitempty" gorm:"index"` // question bank entry the question was copied from
+	BankVersion    *int               `json:"bankVersion,omitempty"`                 // version of the bank entry that was copied
+	Type           string             `json:"type" gorm:"size:50;not null"`          // one of the types registered in questions/types
+	Topic          string             `json:"topic,omitempty" gorm:"size:100"`
+	Difficulty     QuestionDifficulty `json:"difficulty,omitempty" gorm:"size:20"`
+	Tags           QuestionTags       `json:"tags,omitempty" gorm:"type:text"` // sections draw their questions by topic, tag and difficulty
+	Text           string             `json:"text" gorm:"type:text;not null"`
+	Options        []QuestionOption   `json:"options" gorm:"foreignKey:QuestionID"`
+	CorrectAnswer  string             `json:"correctAnswer" gorm:"size:255"`
+	Config         QuestionConfig     `json:"config,omitempty" gorm:"type:text"` // type specific definition, such as accepted answers
+	Points         float64            `json:"points" gorm:"not null;default:1"`
+	PartialCredit  bool               `json:"partialCredit" gorm:"not null;default:false"` // credit partly right answers where the type supports it
+	Penalty        float64            `json:"penalty" gorm:"not null;default:0"`           // share of Points taken off for a wrong answer
+	AllowNegative  bool               `json:"allowNegative" gorm:"not null;default:false"` // let a wrong answer score below zero
+	CreatedAt      time.Time          `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time          `json:"updatedAt" gorm:"autoUpdateTime"`
+	DeletedAt      gorm.DeletedAt     `json:"-" gorm:"index"`
 }
 
 type QuestionOption struct {
@@ -27,3 +40,115 @@ type QuestionOption struct {
 	CreatedAt  time.Time `json:"createdAt" gorm:"autoCreateTime"`
 	UpdatedAt  time.Time `json:"updatedAt" gorm:"autoUpdateTime"`
 }
+
+// AssessmentSection is a part of an assessment that draws questions for each attempt from a pool of
+// the assessment's questions: those with the section's topic and tag. Each draw picks Count random
+// questions of a difficulty from the pool, and a section without draws takes the whole pool. Once an
+// assessment has sections, attempts are given only the questions their sections drew.
+type AssessmentSection struct {
+	ID           uint         `json:"id" gorm:"primaryKey"`
+	AssessmentID uint         `json:"assessmentId" gorm:"not null;index"`
+	Title        string       `json:"title" gorm:"size:255;not null"`
+	Position     int          `json:"position" gorm:"not null;default:0"` // sections are given in this order
+	Topic        string       `json:"topic,omitempty" gorm:"size:100"`
+	Tag          string       `json:"tag,omitempty" gorm:"size:100"`
+	Draws        SectionDraws `json:"draws" gorm:"type:text"`
+	CreatedAt    time.Time    `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time    `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+// InPool reports whether the question is one the section draws from
+func (s *AssessmentSection) InPool(question *Question) bool {
+	if s.Topic != "" && !strings.EqualFold(s.Topic, question.Topic) {
+		return false
+	}
+	if s.Tag == "" {
+		return true
+	}
+	for _, tag := range question.Tags {
+		if tag == strings.ToLower(s.Tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// SectionDraw picks Count questions of a difficulty, or of any difficulty when none is given
+type SectionDraw struct {
+	Difficulty QuestionDifficulty `json:"difficulty,omitempty"`
+	Count      int                `json:"count"`
+}
+
+// Matches reports whether the draw may pick the question
+func (d SectionDraw) Matches(question *Question) bool {
+	return d.Difficulty == "" || d.Difficulty == question.Difficulty
+}
+
+// SectionDraws are the draws of a section, kept as JSON with the section
+type SectionDraws []SectionDraw
+
+func (d SectionDraws) Value() (driver.Value, error) {
+	if len(d) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal([]SectionDraw(d))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (d *SectionDraws) Scan(value interface{}) error {
+	*d = nil
+	return scanJSON(value, (*[]SectionDraw)(d))
+}
+
+// SectionDTO is a teacher's section of an assessment
+type SectionDTO struct {
+	Title    string        `json:"title"`
+	Position int           `json:"position"`
+	Topic    string        `json:"topic"`
+	Tag      string        `json:"tag"`
+	Draws    []SectionDraw `json:"draws"`
+}
+
+// QuestionConfig is the type specific part of a question's definition, kept as raw JSON. Each
+// question type decodes it into its own shape.
+type QuestionConfig json.RawMessage
+
+func (c QuestionConfig) MarshalJSON() ([]byte, error) {
+	if len(c) == 0 {
+		return []byte("null"), nil
+	}
+	return c, nil
+}
+
+func (c *QuestionConfig) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*c = nil
+		return nil
+	}
+	*c = append((*c)[:0], data...)
+	return nil
+}
+
+func (c QuestionConfig) Value() (driver.Value, error) {
+	if len(c) == 0 {
+		return nil, nil
+	}
+	return string(c), nil
+}
+
+func (c *QuestionConfig) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+	case string:
+		*c = QuestionConfig(v)
+	case []byte:
+		*c = append(QuestionConfig(nil), v...)
+	default:
+		return fmt.Errorf("cannot scan %T into QuestionConfig", value)
+	}
+	return nil
+}
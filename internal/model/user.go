@@ -7,25 +7,49 @@ import (
 )
 
 type User struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Name      string         `json:"name" gorm:"size:100;not null"`
-	Email     string         `json:"email" gorm:"size:100;uniqueIndex;not null"`
-	Password  string         `json:"-" gorm:"size:255;not null"` // Password hash
-	Role      string         `json:"role" gorm:"size:50;not null"`
-	Status    string         `json:"status" gorm:"size:50;not null;default:Active"`
-	Phone     string         `json:"phone" gorm:"size:20"`
-	Address   string         `json:"address" gorm:"size:255"`
-	LastLogin *time.Time     `json:"lastLogin" gorm:"type:timestamp"`
-	CreatedAt time.Time      `json:"createdAt" gorm:"autoCreateTime"`
-	UpdatedAt time.Time      `json:"updatedAt" gorm:"autoUpdateTime"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	Name       string         `json:"name" gorm:"size:100;not null"`
+	Email      string         `json:"email" gorm:"size:100;uniqueIndex;not null"`
+	Password   string         `json:"-" gorm:"size:255;not null"` // Password hash
+	Role       string         `json:"role" gorm:"size:50;not null"`
+	Status     string         `json:"status" gorm:"size:50;not null;default:Active"`
+	Phone      string         `json:"phone" gorm:"size:20"`
+	Address    string         `json:"address" gorm:"size:255"`
+	Department string         `json:"department" gorm:"size:100;index"` // shares question banks between teachers
+	LastLogin  *time.Time     `json:"lastLogin" gorm:"type:timestamp"`
+	CreatedAt  time.Time      `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time      `json:"updatedAt" gorm:"autoUpdateTime"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 type RefreshToken struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	UserID    uint      `json:"userId" gorm:"not null"`
-	User      User      `json:"-" gorm:"foreignKey:UserID"`
-	Token     string    `json:"-" gorm:"size:255;not null;uniqueIndex"`
-	ExpiresAt time.Time `json:"expiresAt" gorm:"not null"`
-	CreatedAt time.Time `json:"createdAt" gorm:"autoCreateTime"`
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"userId" gorm:"not null;index"`
+	User      User       `json:"-" gorm:"foreignKey:UserID"`
+	Token     string     `json:"-" gorm:"size:255;not null;uniqueIndex"` // SHA-256 of the token handed to the client
+	ExpiresAt time.Time  `json:"expiresAt" gorm:"not null"`
+	RevokedAt *time.Time `json:"revokedAt"`
+	CreatedAt time.Time  `json:"createdAt" gorm:"autoCreateTime"`
+}
+
+type PasswordResetToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"userId" gorm:"not null;index"`
+	User      User       `json:"-" gorm:"foreignKey:UserID"`
+	Token     string     `json:"-" gorm:"size:255;not null;uniqueIndex"` // SHA-256 of the token sent by email
+	ExpiresAt time.Time  `json:"expiresAt" gorm:"not null"`
+	UsedAt    *time.Time `json:"usedAt"`
+	CreatedAt time.Time  `json:"createdAt" gorm:"autoCreateTime"`
+}
+
+// UserUpdateDTO carries the fields an admin can change on a user; nil fields are left untouched
+type UserUpdateDTO struct {
+	Name    *string `json:"name"`
+	Email   *string `json:"email"`
+	Role    *string `json:"role"`
+	Status  *string `json:"status"`
+	Phone   *string `json:"phone"`
+	Address *string `json:"address"`
+	// Department is the department a teacher shares question banks with
+	Department *string `json:"department"`
 }
@@ -1,10 +1,13 @@
 package rest
 
 import (
+	"assessment_service/internal/middleware"
 	models "assessment_service/internal/model"
 	"assessment_service/internal/questions/service"
+	"assessment_service/internal/questions/types"
 	"assessment_service/internal/util"
 	"encoding/json"
+	"errors"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 	"net/http"
@@ -32,11 +35,18 @@ func (h *QuestionHandler) AddQuestion(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Type          string                  `json:"type" binding:"required,oneof=multiple-choice true-false essay"`
-		Text          string                  `json:"text" binding:"required"`
-		Options       []models.QuestionOption `json:"options"`
-		CorrectAnswer string                  `json:"correctAnswer"`
-		Points        float64                 `json:"points" binding:"required"`
+		Type          string                    `json:"type" binding:"required"`
+		Text          string                    `json:"text" binding:"required"`
+		Options       []models.QuestionOption   `json:"options"`
+		CorrectAnswer string                    `json:"correctAnswer"`
+		Config        models.QuestionConfig     `json:"config"`
+		Points        float64                   `json:"points" binding:"required"`
+		PartialCredit bool                      `json:"partialCredit"`
+		Penalty       float64                   `json:"penalty"`
+		AllowNegative bool                      `json:"allowNegative"`
+		Topic         string                    `json:"topic"`
+		Difficulty    models.QuestionDifficulty `json:"difficulty"`
+		Tags          []string                  `json:"tags"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -53,11 +63,21 @@ func (h *QuestionHandler) AddQuestion(w http.ResponseWriter, r *http.Request) {
 		Text:          req.Text,
 		Options:       req.Options,
 		CorrectAnswer: req.CorrectAnswer,
+		Config:        req.Config,
 		Points:        req.Points,
+		PartialCredit: req.PartialCredit,
+		Penalty:       req.Penalty,
+		AllowNegative: req.AllowNegative,
+		Topic:         req.Topic,
+		Difficulty:    req.Difficulty,
+		Tags:          req.Tags,
 	}
 
 	question, err = h.questionService.AddQuestion(uint(assessmentID), question)
 	if err != nil {
+		if h.writeValidationError(w, err) {
+			return
+		}
 		h.log.Error("Failed to add question", zap.Error(err))
 		util.ResponseMap(w, map[string]interface{}{
 			"status":  "ERROR",
@@ -108,7 +128,16 @@ func (h *QuestionHandler) UpdateQuestion(w http.ResponseWriter, r *http.Request)
 		Text          string                  `json:"text"`
 		Options       []models.QuestionOption `json:"options"`
 		CorrectAnswer interface{}             `json:"correctAnswer"`
+		Config        models.QuestionConfig   `json:"config"`
 		Points        float64                 `json:"points"`
+		// Scoring rules are pointers so they can be switched off or set to zero
+		PartialCredit *bool    `json:"partialCredit"`
+		Penalty       *float64 `json:"penalty"`
+		AllowNegative *bool    `json:"allowNegative"`
+		// Filing details are pointers so they can be cleared
+		Topic      *string  `json:"topic"`
+		Difficulty *string  `json:"difficulty"`
+		Tags       []string `json:"tags"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -135,12 +164,52 @@ func (h *QuestionHandler) UpdateQuestion(w http.ResponseWriter, r *http.Request)
 		questionData["correctAnswer"] = req.CorrectAnswer
 	}
 
+	if req.Config != nil {
+		questionData["config"] = req.Config
+	}
+
 	if req.Options != nil {
 		questionData["options"] = req.Options
 	}
 
-	question, err := h.questionService.UpdateQuestion(uint(questionID), questionData)
+	if req.PartialCredit != nil {
+		questionData["partialCredit"] = *req.PartialCredit
+	}
+
+	if req.Penalty != nil {
+		questionData["penalty"] = *req.Penalty
+	}
+
+	if req.AllowNegative != nil {
+		questionData["allowNegative"] = *req.AllowNegative
+	}
+
+	if req.Topic != nil {
+		questionData["topic"] = *req.Topic
+	}
+
+	if req.Difficulty != nil {
+		questionData["difficulty"] = *req.Difficulty
+	}
+
+	if req.Tags != nil {
+		questionData["tags"] = req.Tags
+	}
+
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "UNAUTHORIZED",
+			"message": "User ID not found in context",
+		}, http.StatusUnauthorized)
+		return
+	}
+
+	question, err := h.questionService.UpdateQuestion(uint(questionID), principal.UserID, questionData)
 	if err != nil {
+		if h.writeValidationError(w, err) {
+			return
+		}
 		h.log.Error("Failed to update question", zap.Error(err))
 		util.ResponseMap(w, map[string]interface{}{
 			"status":  "ERROR",
@@ -179,3 +248,17 @@ func (h *QuestionHandler) DeleteQuestion(w http.ResponseWriter, r *http.Request)
 		"message": "Question deleted successfully",
 	}, http.StatusOK)
 }
+
+// writeValidationError answers with 400 when a question is of an unknown type or badly defined, and
+// reports whether it did
+func (h *QuestionHandler) writeValidationError(w http.ResponseWriter, err error) bool {
+	if !errors.Is(err, types.ErrUnknownType) && !errors.Is(err, types.ErrInvalidDefinition) {
+		return false
+	}
+
+	util.ResponseMap(w, map[string]interface{}{
+		"status":  "BAD_REQUEST",
+		"message": err.Error(),
+	}, http.StatusBadRequest)
+	return true
+}
@@ -1,7 +1,9 @@
 package rest
 
 import (
+	"assessment_service/internal/middleware"
 	models "assessment_service/internal/model"
+	"assessment_service/internal/questions/types"
 	"bytes"
 	"encoding/json"
 	"errors"
@@ -35,8 +37,8 @@ func (m *MockQuestionService) GetQuestionsByAssessment(assessmentID uint) ([]mod
 	}
 	return args.Get(0).([]models.Question), args.Error(1)
 }
-func (m *MockQuestionService) UpdateQuestion(questionID uint, questionData map[string]interface{}) (*models.Question, error) {
-	args := m.Called(questionID, questionData)
+func (m *MockQuestionService) UpdateQuestion(questionID, editorID uint, questionData map[string]interface{}) (*models.Question, error) {
+	args := m.Called(questionID, editorID, questionData)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -163,6 +165,32 @@ func TestQuestionHandler_AddQuestion_ServiceError(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestQuestionHandler_AddQuestion_InvalidDefinition(t *testing.T) {
+	mockService := new(MockQuestionService)
+	logger := zaptest.NewLogger(t)
+	handler := NewQuestionHandler(mockService, logger)
+
+	assessmentID := uint(1)
+	questionReq := map[string]interface{}{"type": "numeric", "text": "2 + 2?", "config": map[string]interface{}{"tolerance": 1}}
+	body, _ := json.Marshal(questionReq)
+	serviceError := fmt.Errorf("%w: numeric questions require an answer", types.ErrInvalidDefinition)
+
+	mockService.On("AddQuestion", assessmentID, mock.MatchedBy(func(q *models.Question) bool {
+		return q.Type == "numeric" && string(q.Config) == `{"tolerance":1}`
+	})).Return(nil, serviceError)
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/assessments/%d/questions", assessmentID), bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/assessments/{id:[0-9]+}/questions", handler.AddQuestion).Methods(http.MethodPost)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "numeric questions require an answer")
+	mockService.AssertExpectations(t)
+}
+
 func TestQuestionHandler_GetQuestionsByAssessment(t *testing.T) {
 	mockService := new(MockQuestionService)
 	logger := zaptest.NewLogger(t)
@@ -248,10 +276,11 @@ func TestQuestionHandler_UpdateQuestion(t *testing.T) {
 		Points:       12,
 	}
 
-	mockService.On("UpdateQuestion", questionID, mock.Anything).Return(updatedQuestion, nil)
+	mockService.On("UpdateQuestion", questionID, uint(3), mock.Anything).Return(updatedQuestion, nil)
 
 	reqPath := fmt.Sprintf("/assessments/%d/questions/%d", assessmentID, questionID)
 	req := httptest.NewRequest(http.MethodPut, reqPath, bytes.NewBuffer(body))
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: 3, Role: "teacher"}))
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -283,7 +312,7 @@ func TestQuestionHandler_UpdateQuestion_InvalidQuestionID(t *testing.T) {
 	router.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusBadRequest, rr.Code)
-	mockService.AssertNotCalled(t, "UpdateQuestion", mock.Anything, mock.Anything)
+	mockService.AssertNotCalled(t, "UpdateQuestion", mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestQuestionHandler_UpdateQuestion_InvalidInput(t *testing.T) {
@@ -300,7 +329,7 @@ func TestQuestionHandler_UpdateQuestion_InvalidInput(t *testing.T) {
 	router.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusBadRequest, rr.Code)
-	mockService.AssertNotCalled(t, "UpdateQuestion", mock.Anything, mock.Anything)
+	mockService.AssertNotCalled(t, "UpdateQuestion", mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestQuestionHandler_UpdateQuestion_ServiceError(t *testing.T) {
@@ -313,9 +342,10 @@ func TestQuestionHandler_UpdateQuestion_ServiceError(t *testing.T) {
 	body, _ := json.Marshal(updateReq)
 	serviceError := errors.New("service update error")
 
-	mockService.On("UpdateQuestion", questionID, mock.Anything).Return(nil, serviceError)
+	mockService.On("UpdateQuestion", questionID, uint(3), mock.Anything).Return(nil, serviceError)
 
 	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/assessments/1/questions/%d", questionID), bytes.NewBuffer(body))
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: 3, Role: "teacher"}))
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -0,0 +1,148 @@
+package rest
+
+import (
+	models "assessment_service/internal/model"
+	"assessment_service/internal/questions/service"
+	"assessment_service/internal/util"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type SectionHandler struct {
+	sectionService service.SectionService
+	log            *zap.Logger
+}
+
+func NewSectionHandler(sectionService service.SectionService, log *zap.Logger) *SectionHandler {
+	return &SectionHandler{sectionService: sectionService, log: log}
+}
+
+func (h *SectionHandler) ListSections(w http.ResponseWriter, r *http.Request) {
+	assessmentID, ok := h.parseID(w, r, "id", "Invalid assessment ID")
+	if !ok {
+		return
+	}
+
+	sections, err := h.sectionService.ListSections(assessmentID)
+	if err != nil {
+		h.writeError(w, "ListSections", err, "Failed to list sections")
+		return
+	}
+
+	util.ResponseInterface(w, sections, http.StatusOK)
+}
+
+func (h *SectionHandler) CreateSection(w http.ResponseWriter, r *http.Request) {
+	assessmentID, ok := h.parseID(w, r, "id", "Invalid assessment ID")
+	if !ok {
+		return
+	}
+
+	var req models.SectionDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("[CreateSection] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	section, err := h.sectionService.CreateSection(assessmentID, req)
+	if err != nil {
+		h.writeError(w, "CreateSection", err, "Failed to create section")
+		return
+	}
+
+	util.ResponseInterface(w, section, http.StatusCreated)
+}
+
+func (h *SectionHandler) UpdateSection(w http.ResponseWriter, r *http.Request) {
+	assessmentID, ok := h.parseID(w, r, "id", "Invalid assessment ID")
+	if !ok {
+		return
+	}
+
+	sectionID, ok := h.parseID(w, r, "sectionId", "Invalid section ID")
+	if !ok {
+		return
+	}
+
+	var req models.SectionDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("[UpdateSection] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	section, err := h.sectionService.UpdateSection(assessmentID, sectionID, req)
+	if err != nil {
+		h.writeError(w, "UpdateSection", err, "Failed to update section")
+		return
+	}
+
+	util.ResponseInterface(w, section, http.StatusOK)
+}
+
+func (h *SectionHandler) DeleteSection(w http.ResponseWriter, r *http.Request) {
+	assessmentID, ok := h.parseID(w, r, "id", "Invalid assessment ID")
+	if !ok {
+		return
+	}
+
+	sectionID, ok := h.parseID(w, r, "sectionId", "Invalid section ID")
+	if !ok {
+		return
+	}
+
+	if err := h.sectionService.DeleteSection(assessmentID, sectionID); err != nil {
+		h.writeError(w, "DeleteSection", err, "Failed to delete section")
+		return
+	}
+
+	util.ResponseMap(w, map[string]interface{}{
+		"message": "Section deleted successfully",
+	}, http.StatusOK)
+}
+
+func (h *SectionHandler) parseID(w http.ResponseWriter, r *http.Request, name, message string) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)[name], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": message,
+		}, http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeError maps service errors to HTTP responses
+func (h *SectionHandler) writeError(w http.ResponseWriter, fn string, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrAssessmentNotFound), errors.Is(err, service.ErrSectionNotFound):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "NOT_FOUND",
+			"message": err.Error(),
+		}, http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidSection):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": err.Error(),
+		}, http.StatusBadRequest)
+	default:
+		h.log.Error("["+fn+"] "+message, zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "ERROR",
+			"message": message,
+		}, http.StatusInternalServerError)
+	}
+}
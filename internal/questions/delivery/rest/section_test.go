@@ -0,0 +1,124 @@
+package rest
+
+import (
+	models "assessment_service/internal/model"
+	"assessment_service/internal/questions/service"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// --- Mock SectionService ---
+type MockSectionService struct {
+	mock.Mock
+}
+
+func (m *MockSectionService) ListSections(assessmentID uint) ([]models.AssessmentSection, error) {
+	args := m.Called(assessmentID)
+	sections, _ := args.Get(0).([]models.AssessmentSection)
+	return sections, args.Error(1)
+}
+
+func (m *MockSectionService) CreateSection(assessmentID uint, dto models.SectionDTO) (*models.AssessmentSection, error) {
+	args := m.Called(assessmentID, dto)
+	section, _ := args.Get(0).(*models.AssessmentSection)
+	return section, args.Error(1)
+}
+
+func (m *MockSectionService) UpdateSection(assessmentID, sectionID uint, dto models.SectionDTO) (*models.AssessmentSection, error) {
+	args := m.Called(assessmentID, sectionID, dto)
+	section, _ := args.Get(0).(*models.AssessmentSection)
+	return section, args.Error(1)
+}
+
+func (m *MockSectionService) DeleteSection(assessmentID, sectionID uint) error {
+	args := m.Called(assessmentID, sectionID)
+	return args.Error(0)
+}
+
+// serveSection định tuyến request tới handler phần thi giống như routes.go
+func serveSection(handler *SectionHandler, req *http.Request) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	router.HandleFunc("/assessments/{id:[0-9]+}/sections", handler.ListSections).Methods(http.MethodGet)
+	router.HandleFunc("/assessments/{id:[0-9]+}/sections", handler.CreateSection).Methods(http.MethodPost)
+	router.HandleFunc("/assessments/{id:[0-9]+}/sections/{sectionId:[0-9]+}", handler.UpdateSection).Methods(http.MethodPut)
+	router.HandleFunc("/assessments/{id:[0-9]+}/sections/{sectionId:[0-9]+}", handler.DeleteSection).Methods(http.MethodDelete)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestSectionHandler_CreateSection(t *testing.T) {
+	mockService := new(MockSectionService)
+	handler := NewSectionHandler(mockService, zaptest.NewLogger(t))
+
+	dto := models.SectionDTO{Title: "Part A", Topic: "Algebra", Draws: []models.SectionDraw{{Difficulty: models.DifficultyEasy, Count: 2}}}
+	mockService.On("CreateSection", uint(1), dto).Return(&models.AssessmentSection{ID: 5, AssessmentID: 1, Title: "Part A"}, nil)
+
+	body, _ := json.Marshal(dto)
+	rr := serveSection(handler, httptest.NewRequest(http.MethodPost, "/assessments/1/sections", bytes.NewBuffer(body)))
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var section models.AssessmentSection
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &section))
+	assert.Equal(t, uint(5), section.ID)
+	mockService.AssertExpectations(t)
+}
+
+func TestSectionHandler_CreateSection_Invalid(t *testing.T) {
+	mockService := new(MockSectionService)
+	handler := NewSectionHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("CreateSection", uint(1), models.SectionDTO{Title: "Part A"}).
+		Return(nil, fmt.Errorf("%w: no questions of the assessment match the section's topic and tag", service.ErrInvalidSection))
+
+	rr := serveSection(handler, httptest.NewRequest(http.MethodPost, "/assessments/1/sections", bytes.NewBufferString(`{"title":"Part A"}`)))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "no questions of the assessment match")
+
+	// Body không hợp lệ không gọi service
+	rr = serveSection(handler, httptest.NewRequest(http.MethodPost, "/assessments/1/sections", bytes.NewBufferString(`{`)))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertNumberOfCalls(t, "CreateSection", 1)
+}
+
+func TestSectionHandler_UpdateSection_NotFound(t *testing.T) {
+	mockService := new(MockSectionService)
+	handler := NewSectionHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("UpdateSection", uint(1), uint(5), models.SectionDTO{Title: "Part B"}).Return(nil, service.ErrSectionNotFound)
+
+	rr := serveSection(handler, httptest.NewRequest(http.MethodPut, "/assessments/1/sections/5", bytes.NewBufferString(`{"title":"Part B"}`)))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestSectionHandler_ListAndDeleteSections(t *testing.T) {
+	mockService := new(MockSectionService)
+	handler := NewSectionHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("ListSections", uint(1)).Return([]models.AssessmentSection{{ID: 5, AssessmentID: 1, Title: "Part A"}}, nil)
+	mockService.On("DeleteSection", uint(1), uint(5)).Return(nil)
+
+	rr := serveSection(handler, httptest.NewRequest(http.MethodGet, "/assessments/1/sections", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var sections []models.AssessmentSection
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &sections))
+	assert.Len(t, sections, 1)
+
+	rr = serveSection(handler, httptest.NewRequest(http.MethodDelete, "/assessments/1/sections/5", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
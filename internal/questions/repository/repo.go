@@ -3,6 +3,7 @@ package repository
 import (
 	models "assessment_service/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type QuestionRepository interface {
@@ -14,6 +15,10 @@ type QuestionRepository interface {
 	AddOption(option *models.QuestionOption) error
 	UpdateOption(option *models.QuestionOption) error
 	DeleteOption(id uint) error
+	// Transaction runs fn in a database transaction, which is rolled back when fn fails
+	Transaction(fn func(tx *gorm.DB) error) error
+	// WithTx returns the repository working inside the transaction tx
+	WithTx(tx *gorm.DB) QuestionRepository
 }
 
 type questionRepository struct {
@@ -33,9 +38,9 @@ func (r *questionRepository) Create(question *models.Question) error {
 			return err
 		}
 
-		if question.Type != "essay" {
-			question.Options = temp
-			// Create options if any
+		question.Options = temp
+		// Create options if any, essays and types such as numeric have none
+		if len(question.Options) > 0 && question.Type != "essay" {
 			for i := range question.Options {
 				question.Options[i].QuestionID = question.ID
 			}
@@ -77,7 +82,7 @@ func (r *questionRepository) FindByAssessmentID(assessmentID uint) ([]models.Que
 func (r *questionRepository) Update(question *models.Question) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		// Update question
-		if err := tx.Save(question).Error; err != nil {
+		if err := tx.Omit(clause.Associations).Save(question).Error; err != nil {
 			return err
 		}
 
@@ -110,3 +115,11 @@ func (r *questionRepository) UpdateOption(option *models.QuestionOption) error {
 func (r *questionRepository) DeleteOption(id uint) error {
 	return r.db.Delete(&models.QuestionOption{}, id).Error
 }
+
+func (r *questionRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
+func (r *questionRepository) WithTx(tx *gorm.DB) QuestionRepository {
+	return &questionRepository{db: tx}
+}
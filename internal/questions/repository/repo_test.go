@@ -26,6 +26,7 @@ func setupTestSQLiteDatabase(t *testing.T) *gorm.DB {
 		&models.Assessment{},
 		&models.Question{},
 		&models.QuestionOption{},
+		&models.AssessmentSection{},
 	)
 	require.NoError(t, err, "Failed to run migrations on SQLite")
 
@@ -222,6 +223,47 @@ func TestQuestionRepository_SQLite(t *testing.T) {
 		assert.Len(t, questionAfterDelete.Options, 3) // Quay về 3 options
 	})
 
+	t.Run("TestTransaction", func(t *testing.T) {
+		before, err := repo.FindByID(createdQuestionID)
+		require.NoError(t, err)
+		require.Len(t, before.Options, 3)
+
+		// Đổi đáp án và thay toàn bộ options trong giao dịch, rồi huỷ giao dịch
+		failed := errors.New("rescore failed")
+		err = repo.Transaction(func(tx *gorm.DB) error {
+			txRepo := repo.WithTx(tx)
+			changed := *before
+			changed.CorrectAnswer = "z"
+			if err := txRepo.Update(&changed); err != nil {
+				return err
+			}
+			for _, opt := range before.Options {
+				if err := txRepo.DeleteOption(opt.ID); err != nil {
+					return err
+				}
+			}
+			if err := txRepo.AddOption(&models.QuestionOption{QuestionID: createdQuestionID, OptionID: "z", Text: "Zed"}); err != nil {
+				return err
+			}
+
+			// Trong giao dịch đã thấy thay đổi
+			inside, err := txRepo.FindByID(createdQuestionID)
+			require.NoError(t, err)
+			assert.Equal(t, "z", inside.CorrectAnswer)
+			assert.Len(t, inside.Options, 1)
+			return failed
+		})
+		assert.ErrorIs(t, err, failed)
+
+		unchanged, err := repo.FindByID(createdQuestionID)
+		require.NoError(t, err)
+		assert.Equal(t, before.CorrectAnswer, unchanged.CorrectAnswer)
+		require.Len(t, unchanged.Options, 3)
+		for i := range before.Options {
+			assert.Equal(t, before.Options[i].ID, unchanged.Options[i].ID)
+		}
+	})
+
 	t.Run("TestDeleteQuestion", func(t *testing.T) {
 		// Lấy question và các options liên quan trước khi xóa
 		questionToDelete, err := repo.FindByID(createdQuestionID)
@@ -248,4 +290,25 @@ func TestQuestionRepository_SQLite(t *testing.T) {
 		assert.Zero(t, remainingOptions)
 	})
 
+	t.Run("TestCreateQuestion_WithoutOptions", func(t *testing.T) {
+		// Câu đúng/sai lấy từ ngân hàng câu hỏi, không có options
+		bankQuestionID, version := uint(7), 2
+		question := &models.Question{
+			AssessmentID:   assessment2.ID,
+			BankQuestionID: &bankQuestionID,
+			BankVersion:    &version,
+			Type:           "true-false",
+			Text:           "The sky is blue.",
+			CorrectAnswer:  "true",
+			Points:         1,
+		}
+		require.NoError(t, repo.Create(question))
+
+		fetchedQuestion, err := repo.FindByID(question.ID)
+		require.NoError(t, err)
+		assert.Empty(t, fetchedQuestion.Options)
+		require.NotNil(t, fetchedQuestion.BankQuestionID)
+		assert.Equal(t, uint(7), *fetchedQuestion.BankQuestionID)
+		assert.Equal(t, 2, *fetchedQuestion.BankVersion)
+	})
 }
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	models "assessment_service/internal/model"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type SectionRepository interface {
+	Create(section *models.AssessmentSection) error
+	// FindByID returns nil when the assessment has no such section
+	FindByID(assessmentID, sectionID uint) (*models.AssessmentSection, error)
+	ListByAssessment(assessmentID uint) ([]models.AssessmentSection, error)
+	Update(section *models.AssessmentSection) error
+	Delete(id uint) error
+}
+
+type sectionRepository struct {
+	db *gorm.DB
+}
+
+func NewSectionRepository(db *gorm.DB) SectionRepository {
+	return &sectionRepository{db: db}
+}
+
+func (r *sectionRepository) Create(section *models.AssessmentSection) error {
+	if err := r.db.Create(section).Error; err != nil {
+		return fmt.Errorf("failed to create section: %w", err)
+	}
+	return nil
+}
+
+func (r *sectionRepository) FindByID(assessmentID, sectionID uint) (*models.AssessmentSection, error) {
+	var section models.AssessmentSection
+	err := r.db.Where("id = ? AND assessment_id = ?", sectionID, assessmentID).First(&section).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find section: %w", err)
+	}
+	return &section, nil
+}
+
+// ListByAssessment returns the sections of an assessment in the order attempts are given them
+func (r *sectionRepository) ListByAssessment(assessmentID uint) ([]models.AssessmentSection, error) {
+	var sections []models.AssessmentSection
+	err := r.db.Where("assessment_id = ?", assessmentID).
+		Order("position, id").
+		Find(&sections).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sections: %w", err)
+	}
+	return sections, nil
+}
+
+func (r *sectionRepository) Update(section *models.AssessmentSection) error {
+	if err := r.db.Save(section).Error; err != nil {
+		return fmt.Errorf("failed to update section: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a section. Attempts that were given questions drawn by it keep them.
+func (r *sectionRepository) Delete(id uint) error {
+	if err := r.db.Delete(&models.AssessmentSection{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete section: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"testing"
+
+	models "assessment_service/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSectionRepository_SQLite(t *testing.T) {
+	db := setupTestSQLiteDatabase(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	repo := NewSectionRepository(db)
+
+	teacher := models.User{Name: "Section Tester", Email: "sections@test.com", Password: "pw", Role: "teacher"}
+	require.NoError(t, db.Create(&teacher).Error)
+
+	assessment := models.Assessment{Title: "Sectioned", CreatedByID: teacher.ID, Duration: 30}
+	other := models.Assessment{Title: "Other", CreatedByID: teacher.ID, Duration: 30}
+	require.NoError(t, db.Create(&assessment).Error)
+	require.NoError(t, db.Create(&other).Error)
+
+	second := &models.AssessmentSection{AssessmentID: assessment.ID, Title: "Part B", Position: 2, Tag: "geometry"}
+	first := &models.AssessmentSection{
+		AssessmentID: assessment.ID, Title: "Part A", Position: 1, Topic: "Algebra",
+		Draws: models.SectionDraws{{Difficulty: models.DifficultyEasy, Count: 2}, {Count: 1}},
+	}
+
+	t.Run("TestCreateAndList", func(t *testing.T) {
+		require.NoError(t, repo.Create(second))
+		require.NoError(t, repo.Create(first))
+		require.NoError(t, repo.Create(&models.AssessmentSection{AssessmentID: other.ID, Title: "Elsewhere"}))
+
+		// Các phần được sắp theo vị trí, không theo thứ tự tạo
+		sections, err := repo.ListByAssessment(assessment.ID)
+		require.NoError(t, err)
+		require.Len(t, sections, 2)
+		assert.Equal(t, first.ID, sections[0].ID)
+		assert.Equal(t, models.SectionDraws{{Difficulty: models.DifficultyEasy, Count: 2}, {Count: 1}}, sections[0].Draws)
+		assert.Equal(t, second.ID, sections[1].ID)
+		assert.Empty(t, sections[1].Draws)
+	})
+
+	t.Run("TestFindByID", func(t *testing.T) {
+		found, err := repo.FindByID(assessment.ID, first.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "Algebra", found.Topic)
+
+		// Phần thuộc bài kiểm tra khác không được tìm thấy
+		found, err = repo.FindByID(other.ID, first.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("TestUpdate", func(t *testing.T) {
+		first.Title = "Part A (updated)"
+		first.Draws = models.SectionDraws{{Difficulty: models.DifficultyHard, Count: 1}}
+		require.NoError(t, repo.Update(first))
+
+		found, err := repo.FindByID(assessment.ID, first.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Part A (updated)", found.Title)
+		assert.Equal(t, models.SectionDraws{{Difficulty: models.DifficultyHard, Count: 1}}, found.Draws)
+	})
+
+	t.Run("TestDelete", func(t *testing.T) {
+		require.NoError(t, repo.Delete(second.ID))
+
+		found, err := repo.FindByID(assessment.ID, second.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+
+		sections, err := repo.ListByAssessment(assessment.ID)
+		require.NoError(t, err)
+		assert.Len(t, sections, 1)
+	})
+}
@@ -4,28 +4,44 @@ import (
 	repository_assessment "assessment_service/internal/assessments/repository"
 	models "assessment_service/internal/model"
 	"assessment_service/internal/questions/repository"
+	"assessment_service/internal/questions/types"
+	"bytes"
 	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"strconv"
 )
 
 type QuestionService interface {
 	AddQuestion(assessmentID uint, question *models.Question) (*models.Question, error)
 	GetQuestionsByAssessment(assessmentID uint) ([]models.Question, error)
-	UpdateQuestion(questionID uint, questionData map[string]interface{}) (*models.Question, error)
+	UpdateQuestion(questionID, editorID uint, questionData map[string]interface{}) (*models.Question, error)
 	DeleteQuestion(questionID uint) error
 }
 
+// Rescorer scores the submitted answers of an assessment again after some of its questions changed,
+// inside the transaction that changes them
+type Rescorer interface {
+	RescoreAssessmentWithin(tx *gorm.DB, assessmentID, actorID uint, questionIDs []uint) (*models.RescoreSummary, error)
+}
+
 type questionService struct {
 	questionRepo   repository.QuestionRepository
 	assessmentRepo repository_assessment.AssessmentRepository
+	rescorer       Rescorer
 }
 
+// NewQuestionService creates the question service. Without a rescorer, changing how a question is
+// scored leaves submitted answers as they are.
 func NewQuestionService(
 	questionRepo repository.QuestionRepository,
 	assessmentRepo repository_assessment.AssessmentRepository,
+	rescorer Rescorer,
 ) QuestionService {
 	return &questionService{
 		questionRepo:   questionRepo,
 		assessmentRepo: assessmentRepo,
+		rescorer:       rescorer,
 	}
 }
 
@@ -38,39 +54,9 @@ func (s *questionService) AddQuestion(assessmentID uint, question *models.Questi
 
 	question.AssessmentID = assessmentID
 
-	// Validate question type
-	switch question.Type {
-	case "multiple-choice":
-		// Ensure options are provided
-		if len(question.Options) == 0 {
-			return nil, errors.New("multiple-choice questions require options")
-		}
-
-		// Validate correctAnswer exists in options
-		valid := false
-		for _, option := range question.Options {
-			if option.OptionID == question.CorrectAnswer {
-				valid = true
-				break
-			}
-		}
-
-		if !valid {
-			return nil, errors.New("correct answer must match one of the option IDs")
-		}
-
-	case "true-false":
-		// For true-false, the correct answer must be "true" or "false"
-		if question.CorrectAnswer != "true" && question.CorrectAnswer != "false" {
-			return nil, errors.New("correct answer for true-false questions must be 'true' or 'false'")
-		}
-
-	case "essay":
-		// Essay questions don't have a correct answer
-		question.CorrectAnswer = ""
-
-	default:
-		return nil, errors.New("invalid question type")
+	// Validate question type and scoring rules
+	if err := types.Validate(question); err != nil {
+		return nil, err
 	}
 
 	// Create question
@@ -94,11 +80,15 @@ func (s *questionService) GetQuestionsByAssessment(assessmentID uint) ([]models.
 	return s.questionRepo.FindByAssessmentID(assessmentID)
 }
 
-func (s *questionService) UpdateQuestion(questionID uint, questionData map[string]interface{}) (*models.Question, error) {
+// UpdateQuestion changes a question. When the change affects how answers are scored, answers that
+// were already submitted are scored again in the name of the editor. The question, its options and
+// the new scores are saved together, or the question is left unchanged.
+func (s *questionService) UpdateQuestion(questionID, editorID uint, questionData map[string]interface{}) (*models.Question, error) {
 	question, err := s.questionRepo.FindByID(questionID)
 	if err != nil {
 		return nil, errors.New("question not found")
 	}
+	before := *question
 
 	// Update text if provided
 	if text, ok := questionData["text"].(string); ok {
@@ -112,86 +102,145 @@ func (s *questionService) UpdateQuestion(questionID uint, questionData map[strin
 
 	// Update correct answer if provided
 	if correctAnswer, ok := questionData["correctAnswer"]; ok {
-		switch question.Type {
-		case "multiple-choice":
-			// Validate correctAnswer exists in options
-			ca, isString := correctAnswer.(string)
-			if !isString {
-				return nil, errors.New("correct answer must be a string for multiple-choice questions")
-			}
+		switch v := correctAnswer.(type) {
+		case string:
+			question.CorrectAnswer = v
+		case bool:
+			// true-false answers may be sent as a boolean
+			question.CorrectAnswer = strconv.FormatBool(v)
+		default:
+			return nil, fmt.Errorf("%w: correct answer must be a string", types.ErrInvalidDefinition)
+		}
+	}
 
-			// Validate correctAnswer matches an option
-			valid := false
-			for _, option := range question.Options {
-				if option.OptionID == ca {
-					valid = true
-					break
-				}
-			}
+	// Update scoring rules if provided
+	if partialCredit, ok := questionData["partialCredit"].(bool); ok {
+		question.PartialCredit = partialCredit
+	}
+	if penalty, ok := questionData["penalty"].(float64); ok {
+		question.Penalty = penalty
+	}
+	if allowNegative, ok := questionData["allowNegative"].(bool); ok {
+		question.AllowNegative = allowNegative
+	}
 
-			if !valid {
-				return nil, errors.New("correct answer must match one of the option IDs")
-			}
+	// Update where the question is filed if provided
+	if topic, ok := questionData["topic"].(string); ok {
+		question.Topic = topic
+	}
+	if difficulty, ok := questionData["difficulty"].(string); ok {
+		question.Difficulty = models.QuestionDifficulty(difficulty)
+	}
+	if tags, ok := questionData["tags"].([]string); ok {
+		question.Tags = tags
+	}
+
+	// Update the type specific config if provided
+	if config, ok := questionData["config"].(models.QuestionConfig); ok {
+		question.Config = config
+	}
+
+	// Update options if provided
+	oldOptions := question.Options
+	newOptions, replaceOptions, err := parseOptions(questionData["options"])
+	if err != nil {
+		return nil, err
+	}
+	if replaceOptions {
+		question.Options = newOptions
+	}
 
-			question.CorrectAnswer = ca
+	if err := types.Validate(question); err != nil {
+		return nil, err
+	}
 
-		case "true-false":
-			// Handle both string and boolean
-			var isTrueVal bool
+	err = s.questionRepo.Transaction(func(tx *gorm.DB) error {
+		repo := s.questionRepo.WithTx(tx)
 
-			switch v := correctAnswer.(type) {
-			case bool:
-				isTrueVal = v
-			case string:
-				isTrueVal = v == "true"
-			default:
-				return nil, errors.New("correct answer for true-false questions must be a boolean or 'true'/'false' string")
+		if replaceOptions {
+			// Delete existing options
+			for _, opt := range oldOptions {
+				if err := repo.DeleteOption(opt.ID); err != nil {
+					return err
+				}
 			}
 
-			if isTrueVal {
-				question.CorrectAnswer = "true"
-			} else {
-				question.CorrectAnswer = "false"
+			// Create new options
+			for i := range question.Options {
+				question.Options[i].QuestionID = question.ID
+				if err := repo.AddOption(&question.Options[i]); err != nil {
+					return err
+				}
 			}
 		}
-	}
 
-	// Update options if provided
-	if options, ok := questionData["options"].([]interface{}); ok && question.Type == "multiple-choice" {
-		// Delete existing options
-		for _, opt := range question.Options {
-			err := s.questionRepo.DeleteOption(opt.ID)
-			if err != nil {
-				return nil, err
+		// Update question
+		if err := repo.Update(question); err != nil {
+			return err
+		}
+
+		if s.rescorer != nil && scoringChanged(&before, question) {
+			if _, err := s.rescorer.RescoreAssessmentWithin(tx, question.AssessmentID, editorID, []uint{question.ID}); err != nil {
+				return fmt.Errorf("question was left unchanged because its answers could not be rescored: %w", err)
 			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.questionRepo.FindByID(question.ID)
+}
+
+// scoringChanged reports whether an update changed anything that answers are scored by
+func scoringChanged(before, after *models.Question) bool {
+	if before.CorrectAnswer != after.CorrectAnswer ||
+		before.Points != after.Points ||
+		before.PartialCredit != after.PartialCredit ||
+		before.Penalty != after.Penalty ||
+		before.AllowNegative != after.AllowNegative ||
+		!bytes.Equal(before.Config, after.Config) ||
+		len(before.Options) != len(after.Options) {
+		return true
+	}
+
+	for i := range before.Options {
+		if before.Options[i].OptionID != after.Options[i].OptionID {
+			return true
+		}
+	}
+	return false
+}
 
-		// Create new options
+// parseOptions reads the options of an update, which come either decoded by the handler or as raw
+// JSON objects with "id" and "text". It reports false when no options were given.
+func parseOptions(value interface{}) ([]models.QuestionOption, bool, error) {
+	switch options := value.(type) {
+	case nil:
+		return nil, false, nil
+	case []models.QuestionOption:
+		result := make([]models.QuestionOption, len(options))
+		for i, opt := range options {
+			result[i] = models.QuestionOption{OptionID: opt.OptionID, Text: opt.Text}
+		}
+		return result, true, nil
+	case []interface{}:
+		result := make([]models.QuestionOption, 0, len(options))
 		for _, opt := range options {
 			optionMap, ok := opt.(map[string]interface{})
 			if !ok {
-				return nil, errors.New("invalid options format")
+				return nil, false, errors.New("invalid options format")
 			}
 
-			option := models.QuestionOption{
-				QuestionID: question.ID,
-				OptionID:   optionMap["id"].(string),
-				Text:       optionMap["text"].(string),
-			}
-
-			err := s.questionRepo.AddOption(&option)
-			if err != nil {
-				return nil, err
-			}
-		}
-
-		// Update question
-		err = s.questionRepo.Update(question)
-		if err != nil {
-			return nil, err
+			id, _ := optionMap["id"].(string)
+			text, _ := optionMap["text"].(string)
+			result = append(result, models.QuestionOption{OptionID: id, Text: text})
 		}
+		return result, true, nil
+	default:
+		return nil, false, errors.New("invalid options format")
 	}
-	return s.questionRepo.FindByID(question.ID)
 }
 
 func (s *questionService) DeleteQuestion(questionID uint) error {
@@ -2,9 +2,13 @@ package service
 
 import (
 	models "assessment_service/internal/model"
+	repository_question "assessment_service/internal/questions/repository"
+	"assessment_service/internal/questions/types"
 	"assessment_service/internal/util"
 	"errors"
+	"gorm.io/gorm"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -52,6 +56,14 @@ func (m *MockQuestionRepository) DeleteOption(id uint) error {
 	return args.Error(0)
 }
 
+func (m *MockQuestionRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return fn(nil)
+}
+
+func (m *MockQuestionRepository) WithTx(tx *gorm.DB) repository_question.QuestionRepository {
+	return m
+}
+
 // --- Mock AssessmentRepository ---
 type MockAssessmentRepository struct {
 	mock.Mock
@@ -147,11 +159,6 @@ func (m *MockAssessmentRepository) GetResults(id uint, params util.PaginationPar
 	return results, count, args.Error(2)
 }
 
-func (m *MockAssessmentRepository) Publish(id uint) error {
-	args := m.Called(id)
-	return args.Error(0)
-}
-
 func (m *MockAssessmentRepository) Duplicate(assessment *models.Assessment) error {
 	args := m.Called(assessment)
 	return args.Error(0)
@@ -174,6 +181,29 @@ func (m *MockAssessmentRepository) GetAssessmentHasAttemptByUser(params util.Pag
 	return assessments, count, args.Error(2)
 }
 
+func (m *MockAssessmentRepository) UpdateStatus(transition *models.AssessmentStatusTransition) (bool, error) {
+	args := m.Called(transition)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAssessmentRepository) ListStatusHistory(assessmentID uint) ([]models.AssessmentStatusTransition, error) {
+	args := m.Called(assessmentID)
+	transitions, _ := args.Get(0).([]models.AssessmentStatusTransition)
+	return transitions, args.Error(1)
+}
+
+func (m *MockAssessmentRepository) ActivateScheduled(now time.Time) (int64, error) {
+	args := m.Called(now)
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
+func (m *MockAssessmentRepository) CloseEnded(now time.Time) (int64, error) {
+	args := m.Called(now)
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
 // Mock UserRepository implementation (assuming it exists based on service dependencies)
 type MockUserRepository struct {
 	mock.Mock
@@ -285,12 +315,23 @@ func (m *MockUserRepository) GetListUserByAssessment(params util.PaginationParam
 	return users, count, args.Error(2)
 }
 
+// --- Mock Rescorer ---
+type MockRescorer struct {
+	mock.Mock
+}
+
+func (m *MockRescorer) RescoreAssessmentWithin(tx *gorm.DB, assessmentID, actorID uint, questionIDs []uint) (*models.RescoreSummary, error) {
+	args := m.Called(assessmentID, actorID, questionIDs)
+	summary, _ := args.Get(0).(*models.RescoreSummary)
+	return summary, args.Error(1)
+}
+
 // --- Test Cases ---
 
 func TestQuestionService_AddQuestion_Success_MC(t *testing.T) {
 	mockQuestionRepo := new(MockQuestionRepository)
 	mockAssessmentRepo := new(MockAssessmentRepository)
-	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo)
+	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
 
 	assessmentID := uint(1)
 	question := &models.Question{
@@ -327,7 +368,7 @@ func TestQuestionService_AddQuestion_Success_MC(t *testing.T) {
 func TestQuestionService_AddQuestion_Success_TF(t *testing.T) {
 	mockQuestionRepo := new(MockQuestionRepository)
 	mockAssessmentRepo := new(MockAssessmentRepository)
-	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo)
+	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
 
 	assessmentID := uint(1)
 	question := &models.Question{
@@ -355,7 +396,7 @@ func TestQuestionService_AddQuestion_Success_TF(t *testing.T) {
 func TestQuestionService_AddQuestion_Success_Essay(t *testing.T) {
 	mockQuestionRepo := new(MockQuestionRepository)
 	mockAssessmentRepo := new(MockAssessmentRepository)
-	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo)
+	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
 
 	assessmentID := uint(1)
 	question := &models.Question{
@@ -395,10 +436,62 @@ func TestQuestionService_AddQuestion_Success_Essay(t *testing.T) {
 	mockQuestionRepo.AssertExpectations(t)
 }
 
+func TestQuestionService_AddQuestion_Success_Numeric(t *testing.T) {
+	mockQuestionRepo := new(MockQuestionRepository)
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
+
+	assessmentID := uint(1)
+	question := &models.Question{
+		Type:          "numeric",
+		Text:          "2 + 2?",
+		CorrectAnswer: "4", // Đáp án nằm trong config, service sẽ xoá cái này
+		Config:        models.QuestionConfig(`{"answer": 4, "tolerance": 0}`),
+		Points:        2,
+	}
+	createdQuestion := &models.Question{ID: 13, AssessmentID: assessmentID, Type: "numeric", Text: "2 + 2?", Points: 2}
+
+	mockAssessmentRepo.On("FindByID", assessmentID).Return(&models.Assessment{ID: assessmentID}, nil)
+	mockQuestionRepo.On("Create", mock.MatchedBy(func(q *models.Question) bool {
+		return q.CorrectAnswer == "" && string(q.Config) == `{"answer":4,"tolerance":0}`
+	})).Return(nil).Run(func(args mock.Arguments) { args.Get(0).(*models.Question).ID = 13 })
+	mockQuestionRepo.On("FindByID", uint(13)).Return(createdQuestion, nil)
+
+	result, err := service.AddQuestion(assessmentID, question)
+
+	assert.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, uint(13), result.ID)
+	mockAssessmentRepo.AssertExpectations(t)
+	mockQuestionRepo.AssertExpectations(t)
+}
+
+func TestQuestionService_AddQuestion_InvalidConfig(t *testing.T) {
+	mockQuestionRepo := new(MockQuestionRepository)
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
+
+	assessmentID := uint(1)
+	question := &models.Question{
+		Type:    "multiple-select",
+		Text:    "Pick all",
+		Options: []models.QuestionOption{{OptionID: "a", Text: "A"}},
+		Config:  models.QuestionConfig(`{"correctOptions": ["z"]}`),
+	}
+
+	mockAssessmentRepo.On("FindByID", assessmentID).Return(&models.Assessment{ID: assessmentID}, nil)
+
+	result, err := service.AddQuestion(assessmentID, question)
+
+	assert.ErrorIs(t, err, types.ErrInvalidDefinition)
+	assert.Nil(t, result)
+	mockQuestionRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
 func TestQuestionService_AddQuestion_AssessmentNotFound(t *testing.T) {
 	mockQuestionRepo := new(MockQuestionRepository)
 	mockAssessmentRepo := new(MockAssessmentRepository)
-	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo)
+	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
 
 	assessmentID := uint(99)
 	question := &models.Question{Type: "essay", Text: "Test"}
@@ -417,7 +510,7 @@ func TestQuestionService_AddQuestion_AssessmentNotFound(t *testing.T) {
 func TestQuestionService_AddQuestion_InvalidType(t *testing.T) {
 	mockQuestionRepo := new(MockQuestionRepository)
 	mockAssessmentRepo := new(MockAssessmentRepository)
-	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo)
+	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
 
 	assessmentID := uint(1)
 	question := &models.Question{Type: "invalid-type", Text: "Test"}
@@ -436,7 +529,7 @@ func TestQuestionService_AddQuestion_InvalidType(t *testing.T) {
 func TestQuestionService_AddQuestion_MC_NoOptions(t *testing.T) {
 	mockQuestionRepo := new(MockQuestionRepository)
 	mockAssessmentRepo := new(MockAssessmentRepository)
-	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo)
+	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
 
 	assessmentID := uint(1)
 	question := &models.Question{Type: "multiple-choice", Text: "Test", Options: []models.QuestionOption{}} // No options
@@ -455,7 +548,7 @@ func TestQuestionService_AddQuestion_MC_NoOptions(t *testing.T) {
 func TestQuestionService_AddQuestion_MC_InvalidCorrectAnswer(t *testing.T) {
 	mockQuestionRepo := new(MockQuestionRepository)
 	mockAssessmentRepo := new(MockAssessmentRepository)
-	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo)
+	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
 
 	assessmentID := uint(1)
 	question := &models.Question{
@@ -482,7 +575,7 @@ func TestQuestionService_AddQuestion_MC_InvalidCorrectAnswer(t *testing.T) {
 func TestQuestionService_AddQuestion_TF_InvalidCorrectAnswer(t *testing.T) {
 	mockQuestionRepo := new(MockQuestionRepository)
 	mockAssessmentRepo := new(MockAssessmentRepository)
-	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo)
+	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
 
 	assessmentID := uint(1)
 	question := &models.Question{Type: "true-false", Text: "Test", CorrectAnswer: "maybe"} // Invalid
@@ -501,7 +594,7 @@ func TestQuestionService_AddQuestion_TF_InvalidCorrectAnswer(t *testing.T) {
 func TestQuestionService_AddQuestion_RepoCreateError(t *testing.T) {
 	mockQuestionRepo := new(MockQuestionRepository)
 	mockAssessmentRepo := new(MockAssessmentRepository)
-	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo)
+	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
 
 	assessmentID := uint(1)
 	question := &models.Question{Type: "essay", Text: "Test"}
@@ -524,7 +617,7 @@ func TestQuestionService_AddQuestion_RepoCreateError(t *testing.T) {
 func TestQuestionService_GetQuestionsByAssessment(t *testing.T) {
 	mockQuestionRepo := new(MockQuestionRepository)
 	mockAssessmentRepo := new(MockAssessmentRepository)
-	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo)
+	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
 
 	assessmentID := uint(1)
 	expectedQuestions := []models.Question{
@@ -546,7 +639,7 @@ func TestQuestionService_GetQuestionsByAssessment(t *testing.T) {
 func TestQuestionService_GetQuestionsByAssessment_AssessmentNotFound(t *testing.T) {
 	mockQuestionRepo := new(MockQuestionRepository)
 	mockAssessmentRepo := new(MockAssessmentRepository)
-	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo)
+	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
 
 	assessmentID := uint(99)
 
@@ -564,7 +657,7 @@ func TestQuestionService_GetQuestionsByAssessment_AssessmentNotFound(t *testing.
 func TestQuestionService_GetQuestionsByAssessment_RepoError(t *testing.T) {
 	mockQuestionRepo := new(MockQuestionRepository)
 	mockAssessmentRepo := new(MockAssessmentRepository)
-	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo)
+	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
 
 	assessmentID := uint(1)
 	repoError := errors.New("db find error")
@@ -584,7 +677,7 @@ func TestQuestionService_GetQuestionsByAssessment_RepoError(t *testing.T) {
 func TestQuestionService_UpdateQuestion(t *testing.T) {
 	mockQuestionRepo := new(MockQuestionRepository)
 	mockAssessmentRepo := new(MockAssessmentRepository)
-	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo)
+	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
 
 	questionID := uint(5)
 	existingQuestion := &models.Question{
@@ -649,7 +742,7 @@ func TestQuestionService_UpdateQuestion(t *testing.T) {
 	// Expect repo FindByID được gọi lại cuối cùng để trả về kết quả
 	mockQuestionRepo.On("FindByID", questionID).Return(finalUpdatedQuestion, nil).Once()
 
-	result, err := service.UpdateQuestion(questionID, updateData)
+	result, err := service.UpdateQuestion(questionID, 3, updateData)
 
 	assert.NoError(t, err)
 	require.NotNil(t, result)
@@ -663,14 +756,14 @@ func TestQuestionService_UpdateQuestion(t *testing.T) {
 func TestQuestionService_UpdateQuestion_NotFound(t *testing.T) {
 	mockQuestionRepo := new(MockQuestionRepository)
 	mockAssessmentRepo := new(MockAssessmentRepository)
-	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo)
+	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
 
 	questionID := uint(99)
 	updateData := map[string]interface{}{"text": "New Text"}
 
 	mockQuestionRepo.On("FindByID", questionID).Return(nil, errors.New("question not found"))
 
-	result, err := service.UpdateQuestion(questionID, updateData)
+	result, err := service.UpdateQuestion(questionID, 3, updateData)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -684,7 +777,7 @@ func TestQuestionService_UpdateQuestion_NotFound(t *testing.T) {
 func TestQuestionService_UpdateQuestion_InvalidCorrectAnswer_MC(t *testing.T) {
 	mockQuestionRepo := new(MockQuestionRepository)
 	mockAssessmentRepo := new(MockAssessmentRepository)
-	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo)
+	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
 
 	questionID := uint(5)
 	existingQuestion := &models.Question{
@@ -695,7 +788,7 @@ func TestQuestionService_UpdateQuestion_InvalidCorrectAnswer_MC(t *testing.T) {
 
 	mockQuestionRepo.On("FindByID", questionID).Return(existingQuestion, nil)
 
-	result, err := service.UpdateQuestion(questionID, updateData)
+	result, err := service.UpdateQuestion(questionID, 3, updateData)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -707,7 +800,7 @@ func TestQuestionService_UpdateQuestion_InvalidCorrectAnswer_MC(t *testing.T) {
 func TestQuestionService_UpdateQuestion_InvalidCorrectAnswer_TF(t *testing.T) {
 	//mockQuestionRepo := new(MockQuestionRepository)
 	//mockAssessmentRepo := new(MockAssessmentRepository)
-	//service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo)
+	//service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
 	//
 	//questionID := uint(5)
 	//existingQuestion := &models.Question{ID: questionID, Type: "true-false"}
@@ -715,7 +808,7 @@ func TestQuestionService_UpdateQuestion_InvalidCorrectAnswer_TF(t *testing.T) {
 	//
 	//mockQuestionRepo.On("FindByID", questionID).Return(existingQuestion, nil)
 	//
-	//result, err := service.UpdateQuestion(questionID, updateData)
+	//result, err := service.UpdateQuestion(questionID, 3, updateData)
 	//
 	//assert.Error(t, err)
 	//assert.Nil(t, result)
@@ -727,22 +820,23 @@ func TestQuestionService_UpdateQuestion_InvalidCorrectAnswer_TF(t *testing.T) {
 func TestQuestionService_UpdateQuestion_OptionError(t *testing.T) {
 	mockQuestionRepo := new(MockQuestionRepository)
 	mockAssessmentRepo := new(MockAssessmentRepository)
-	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo)
+	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
 
 	questionID := uint(5)
 	existingQuestion := &models.Question{
-		ID: questionID, Type: "multiple-choice",
+		ID: questionID, Type: "multiple-choice", CorrectAnswer: "a",
 		Options: []models.QuestionOption{{ID: 10, OptionID: "a"}},
 	}
 	updateData := map[string]interface{}{
-		"options": []interface{}{map[string]interface{}{"id": "b", "text": "B"}},
+		"correctAnswer": "b",
+		"options":       []interface{}{map[string]interface{}{"id": "b", "text": "B"}},
 	}
 	repoError := errors.New("option delete error")
 
 	mockQuestionRepo.On("FindByID", questionID).Return(existingQuestion, nil)
 	mockQuestionRepo.On("DeleteOption", uint(10)).Return(repoError) // Giả lập lỗi khi xóa option cũ
 
-	result, err := service.UpdateQuestion(questionID, updateData)
+	result, err := service.UpdateQuestion(questionID, 3, updateData)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -752,10 +846,82 @@ func TestQuestionService_UpdateQuestion_OptionError(t *testing.T) {
 	mockQuestionRepo.AssertNotCalled(t, "AddOption", mock.Anything)
 }
 
+func TestQuestionService_UpdateQuestion_Rescore(t *testing.T) {
+	tests := []struct {
+		name       string
+		updateData map[string]interface{}
+		rescore    bool
+	}{
+		{"text only", map[string]interface{}{"text": "Clearer wording"}, false},
+		{"same options", map[string]interface{}{"options": []interface{}{
+			map[string]interface{}{"id": "a", "text": "Renamed A"},
+			map[string]interface{}{"id": "b", "text": "Renamed B"},
+		}}, false},
+		{"correct answer", map[string]interface{}{"correctAnswer": "b"}, true},
+		{"points", map[string]interface{}{"points": float64(10)}, true},
+		{"penalty", map[string]interface{}{"penalty": 0.5}, true},
+		{"options replaced", map[string]interface{}{"options": []interface{}{
+			map[string]interface{}{"id": "a", "text": "A"},
+			map[string]interface{}{"id": "c", "text": "C"},
+		}, "correctAnswer": "a"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockQuestionRepo := new(MockQuestionRepository)
+			mockRescorer := new(MockRescorer)
+			service := NewQuestionService(mockQuestionRepo, new(MockAssessmentRepository), mockRescorer)
+
+			question := &models.Question{
+				ID: 5, AssessmentID: 1, Type: "multiple-choice", Text: "Pick one", CorrectAnswer: "a", Points: 5,
+				Options: []models.QuestionOption{
+					{ID: 10, QuestionID: 5, OptionID: "a", Text: "A"},
+					{ID: 11, QuestionID: 5, OptionID: "b", Text: "B"},
+				},
+			}
+			mockQuestionRepo.On("FindByID", uint(5)).Return(question, nil)
+			mockQuestionRepo.On("DeleteOption", mock.Anything).Return(nil)
+			mockQuestionRepo.On("AddOption", mock.Anything).Return(nil)
+			mockQuestionRepo.On("Update", mock.Anything).Return(nil)
+			if tt.rescore {
+				mockRescorer.On("RescoreAssessmentWithin", uint(1), uint(3), []uint{5}).Return(&models.RescoreSummary{AssessmentID: 1}, nil)
+			}
+
+			_, err := service.UpdateQuestion(5, 3, tt.updateData)
+
+			require.NoError(t, err)
+			mockRescorer.AssertExpectations(t)
+			if !tt.rescore {
+				mockRescorer.AssertNotCalled(t, "RescoreAssessmentWithin", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestQuestionService_UpdateQuestion_RescoreError(t *testing.T) {
+	mockQuestionRepo := new(MockQuestionRepository)
+	mockRescorer := new(MockRescorer)
+	service := NewQuestionService(mockQuestionRepo, new(MockAssessmentRepository), mockRescorer)
+
+	question := &models.Question{ID: 5, AssessmentID: 1, Type: "true-false", Text: "True?", CorrectAnswer: "true", Points: 5}
+	mockQuestionRepo.On("FindByID", uint(5)).Return(question, nil)
+	mockQuestionRepo.On("Update", mock.Anything).Return(nil)
+	rescoreErr := errors.New("db down")
+	mockRescorer.On("RescoreAssessmentWithin", uint(1), uint(3), []uint{5}).Return(nil, rescoreErr)
+
+	result, err := service.UpdateQuestion(5, 3, map[string]interface{}{"correctAnswer": false})
+
+	// Lỗi được trả về từ giao dịch nên việc sửa câu hỏi bị huỷ
+	assert.Nil(t, result)
+	assert.ErrorContains(t, err, "left unchanged")
+	assert.ErrorIs(t, err, rescoreErr)
+	mockQuestionRepo.AssertNumberOfCalls(t, "FindByID", 1)
+}
+
 func TestQuestionService_DeleteQuestion(t *testing.T) {
 	mockQuestionRepo := new(MockQuestionRepository)
 	mockAssessmentRepo := new(MockAssessmentRepository)
-	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo)
+	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
 
 	questionID := uint(5)
 	existingQuestion := &models.Question{ID: questionID}
@@ -772,7 +938,7 @@ func TestQuestionService_DeleteQuestion(t *testing.T) {
 func TestQuestionService_DeleteQuestion_NotFound(t *testing.T) {
 	mockQuestionRepo := new(MockQuestionRepository)
 	mockAssessmentRepo := new(MockAssessmentRepository)
-	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo)
+	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
 
 	questionID := uint(99)
 	repoError := errors.New("not found")
@@ -790,7 +956,7 @@ func TestQuestionService_DeleteQuestion_NotFound(t *testing.T) {
 func TestQuestionService_DeleteQuestion_RepoError(t *testing.T) {
 	mockQuestionRepo := new(MockQuestionRepository)
 	mockAssessmentRepo := new(MockAssessmentRepository)
-	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo)
+	service := NewQuestionService(mockQuestionRepo, mockAssessmentRepo, nil)
 
 	questionID := uint(5)
 	existingQuestion := &models.Question{ID: questionID}
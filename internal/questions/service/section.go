@@ -0,0 +1,212 @@
+package service
+
+import (
+	repository_assessment "assessment_service/internal/assessments/repository"
+	models "assessment_service/internal/model"
+	"assessment_service/internal/questions/repository"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAssessmentNotFound = errors.New("assessment not found")
+	ErrSectionNotFound    = errors.New("section not found")
+	// ErrInvalidSection is returned for a section without a title, with a bad draw, or that draws
+	// more questions than its pool has
+	ErrInvalidSection = errors.New("invalid section")
+)
+
+// SectionService manages the sections of an assessment, which draw the questions of each attempt.
+// Changing sections only affects attempts started afterwards.
+type SectionService interface {
+	ListSections(assessmentID uint) ([]models.AssessmentSection, error)
+	CreateSection(assessmentID uint, dto models.SectionDTO) (*models.AssessmentSection, error)
+	UpdateSection(assessmentID, sectionID uint, dto models.SectionDTO) (*models.AssessmentSection, error)
+	DeleteSection(assessmentID, sectionID uint) error
+}
+
+type sectionService struct {
+	sectionRepo    repository.SectionRepository
+	questionRepo   repository.QuestionRepository
+	assessmentRepo repository_assessment.AssessmentRepository
+	log            *zap.Logger
+}
+
+func NewSectionService(
+	sectionRepo repository.SectionRepository,
+	questionRepo repository.QuestionRepository,
+	assessmentRepo repository_assessment.AssessmentRepository,
+	log *zap.Logger,
+) SectionService {
+	return &sectionService{
+		sectionRepo:    sectionRepo,
+		questionRepo:   questionRepo,
+		assessmentRepo: assessmentRepo,
+		log:            log,
+	}
+}
+
+func (s *sectionService) ListSections(assessmentID uint) ([]models.AssessmentSection, error) {
+	if err := s.ensureAssessment(assessmentID); err != nil {
+		return nil, err
+	}
+
+	sections, err := s.sectionRepo.ListByAssessment(assessmentID)
+	if err != nil {
+		s.log.Error("[ListSections] failed to list sections", zap.Error(err))
+		return nil, err
+	}
+
+	return sections, nil
+}
+
+func (s *sectionService) CreateSection(assessmentID uint, dto models.SectionDTO) (*models.AssessmentSection, error) {
+	if err := s.ensureAssessment(assessmentID); err != nil {
+		return nil, err
+	}
+
+	section := &models.AssessmentSection{AssessmentID: assessmentID}
+	if err := s.apply(section, dto); err != nil {
+		return nil, err
+	}
+
+	if err := s.sectionRepo.Create(section); err != nil {
+		s.log.Error("[CreateSection] failed to create section", zap.Error(err))
+		return nil, err
+	}
+
+	return section, nil
+}
+
+func (s *sectionService) UpdateSection(assessmentID, sectionID uint, dto models.SectionDTO) (*models.AssessmentSection, error) {
+	section, err := s.findSection(assessmentID, sectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(section, dto); err != nil {
+		return nil, err
+	}
+
+	if err := s.sectionRepo.Update(section); err != nil {
+		s.log.Error("[UpdateSection] failed to update section", zap.Error(err))
+		return nil, err
+	}
+
+	return section, nil
+}
+
+func (s *sectionService) DeleteSection(assessmentID, sectionID uint) error {
+	section, err := s.findSection(assessmentID, sectionID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.sectionRepo.Delete(section.ID); err != nil {
+		s.log.Error("[DeleteSection] failed to delete section", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// apply checks a teacher's section against the assessment's questions and copies it into section
+func (s *sectionService) apply(section *models.AssessmentSection, dto models.SectionDTO) error {
+	title := strings.TrimSpace(dto.Title)
+	if title == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidSection)
+	}
+
+	draws := make(models.SectionDraws, len(dto.Draws))
+	for i, draw := range dto.Draws {
+		draw.Difficulty = models.QuestionDifficulty(strings.ToLower(strings.TrimSpace(string(draw.Difficulty))))
+		if !draw.Difficulty.Valid() {
+			return fmt.Errorf("%w: difficulty must be easy, medium or hard", ErrInvalidSection)
+		}
+		if draw.Count < 1 {
+			return fmt.Errorf("%w: every draw must pick at least one question", ErrInvalidSection)
+		}
+		draws[i] = draw
+	}
+
+	section.Title = title
+	section.Position = dto.Position
+	section.Topic = strings.TrimSpace(dto.Topic)
+	section.Tag = strings.ToLower(strings.TrimSpace(dto.Tag))
+	section.Draws = draws
+
+	questions, err := s.questionRepo.FindByAssessmentID(section.AssessmentID)
+	if err != nil {
+		s.log.Error("failed to find questions", zap.Error(err))
+		return err
+	}
+
+	return checkPool(section, questions)
+}
+
+// checkPool makes sure the section's pool has enough questions for each of its draws and for all of
+// them together
+func checkPool(section *models.AssessmentSection, questions []models.Question) error {
+	var pool []*models.Question
+	for i := range questions {
+		if section.InPool(&questions[i]) {
+			pool = append(pool, &questions[i])
+		}
+	}
+
+	if len(pool) == 0 {
+		return fmt.Errorf("%w: no questions of the assessment match the section's topic and tag", ErrInvalidSection)
+	}
+
+	total := 0
+	for _, draw := range section.Draws {
+		matching := 0
+		for _, question := range pool {
+			if draw.Matches(question) {
+				matching++
+			}
+		}
+		if matching < draw.Count {
+			return fmt.Errorf("%w: the pool has %d questions for a draw of %d", ErrInvalidSection, matching, draw.Count)
+		}
+		total += draw.Count
+	}
+
+	if total > len(pool) {
+		return fmt.Errorf("%w: the draws pick %d questions but the pool has %d", ErrInvalidSection, total, len(pool))
+	}
+	return nil
+}
+
+func (s *sectionService) findSection(assessmentID, sectionID uint) (*models.AssessmentSection, error) {
+	if err := s.ensureAssessment(assessmentID); err != nil {
+		return nil, err
+	}
+
+	section, err := s.sectionRepo.FindByID(assessmentID, sectionID)
+	if err != nil {
+		s.log.Error("failed to find section", zap.Error(err))
+		return nil, err
+	}
+
+	if section == nil {
+		return nil, ErrSectionNotFound
+	}
+
+	return section, nil
+}
+
+func (s *sectionService) ensureAssessment(assessmentID uint) error {
+	if _, err := s.assessmentRepo.FindByID(assessmentID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAssessmentNotFound
+		}
+		s.log.Error("failed to find assessment", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	models "assessment_service/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+// --- Mock SectionRepository ---
+type MockSectionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSectionRepository) Create(section *models.AssessmentSection) error {
+	args := m.Called(section)
+	return args.Error(0)
+}
+
+func (m *MockSectionRepository) FindByID(assessmentID, sectionID uint) (*models.AssessmentSection, error) {
+	args := m.Called(assessmentID, sectionID)
+	section, _ := args.Get(0).(*models.AssessmentSection)
+	return section, args.Error(1)
+}
+
+func (m *MockSectionRepository) ListByAssessment(assessmentID uint) ([]models.AssessmentSection, error) {
+	args := m.Called(assessmentID)
+	sections, _ := args.Get(0).([]models.AssessmentSection)
+	return sections, args.Error(1)
+}
+
+func (m *MockSectionRepository) Update(section *models.AssessmentSection) error {
+	args := m.Called(section)
+	return args.Error(0)
+}
+
+func (m *MockSectionRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func newTestSectionService(t *testing.T) (SectionService, *MockSectionRepository, *MockQuestionRepository, *MockAssessmentRepository) {
+	sectionRepo := new(MockSectionRepository)
+	questionRepo := new(MockQuestionRepository)
+	assessmentRepo := new(MockAssessmentRepository)
+	return NewSectionService(sectionRepo, questionRepo, assessmentRepo, zaptest.NewLogger(t)), sectionRepo, questionRepo, assessmentRepo
+}
+
+// poolQuestions là ngân hàng câu hỏi của bài kiểm tra dùng cho các phần thi
+func poolQuestions() []models.Question {
+	return []models.Question{
+		{ID: 1, Topic: "Algebra", Difficulty: models.DifficultyEasy, Tags: models.QuestionTags{"linear"}},
+		{ID: 2, Topic: "Algebra", Difficulty: models.DifficultyEasy},
+		{ID: 3, Topic: "algebra", Difficulty: models.DifficultyHard, Tags: models.QuestionTags{"linear"}},
+		{ID: 4, Topic: "Geometry", Difficulty: models.DifficultyEasy},
+	}
+}
+
+func TestSectionService_CreateSection(t *testing.T) {
+	svc, sectionRepo, questionRepo, assessmentRepo := newTestSectionService(t)
+
+	assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+	questionRepo.On("FindByAssessmentID", uint(1)).Return(poolQuestions(), nil)
+	sectionRepo.On("Create", mock.AnythingOfType("*models.AssessmentSection")).Return(nil)
+
+	section, err := svc.CreateSection(1, models.SectionDTO{
+		Title: " Part A ", Position: 1, Topic: " Algebra ",
+		Draws: []models.SectionDraw{{Difficulty: "EASY", Count: 2}, {Difficulty: "hard", Count: 1}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), section.AssessmentID)
+	assert.Equal(t, "Part A", section.Title)
+	assert.Equal(t, "Algebra", section.Topic)
+	assert.Equal(t, models.SectionDraws{{Difficulty: models.DifficultyEasy, Count: 2}, {Difficulty: models.DifficultyHard, Count: 1}}, section.Draws)
+	sectionRepo.AssertExpectations(t)
+}
+
+func TestSectionService_CreateSection_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		dto  models.SectionDTO
+	}{
+		{"missing title", models.SectionDTO{Topic: "Algebra"}},
+		{"invalid difficulty", models.SectionDTO{Title: "A", Draws: []models.SectionDraw{{Difficulty: "extreme", Count: 1}}}},
+		{"empty draw", models.SectionDTO{Title: "A", Draws: []models.SectionDraw{{Count: 0}}}},
+		{"empty pool", models.SectionDTO{Title: "A", Topic: "Calculus"}},
+		{"draw larger than pool", models.SectionDTO{Title: "A", Tag: "Linear", Draws: []models.SectionDraw{{Difficulty: "easy", Count: 2}}}},
+		{"draws larger than pool", models.SectionDTO{Title: "A", Topic: "Algebra", Draws: []models.SectionDraw{{Count: 2}, {Difficulty: "easy", Count: 2}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, sectionRepo, questionRepo, assessmentRepo := newTestSectionService(t)
+			assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+			questionRepo.On("FindByAssessmentID", uint(1)).Return(poolQuestions(), nil).Maybe()
+
+			_, err := svc.CreateSection(1, tt.dto)
+
+			assert.ErrorIs(t, err, ErrInvalidSection)
+			sectionRepo.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+}
+
+func TestSectionService_CreateSection_AssessmentNotFound(t *testing.T) {
+	svc, sectionRepo, _, assessmentRepo := newTestSectionService(t)
+	assessmentRepo.On("FindByID", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := svc.CreateSection(1, models.SectionDTO{Title: "A"})
+
+	assert.ErrorIs(t, err, ErrAssessmentNotFound)
+	sectionRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestSectionService_UpdateSection(t *testing.T) {
+	svc, sectionRepo, questionRepo, assessmentRepo := newTestSectionService(t)
+
+	assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+	sectionRepo.On("FindByID", uint(1), uint(5)).Return(&models.AssessmentSection{ID: 5, AssessmentID: 1, Title: "Old"}, nil)
+	questionRepo.On("FindByAssessmentID", uint(1)).Return(poolQuestions(), nil)
+	sectionRepo.On("Update", mock.MatchedBy(func(s *models.AssessmentSection) bool {
+		return s.ID == 5 && s.Title == "New" && s.Tag == "linear"
+	})).Return(nil)
+
+	section, err := svc.UpdateSection(1, 5, models.SectionDTO{Title: "New", Tag: "LINEAR"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "linear", section.Tag)
+	sectionRepo.AssertExpectations(t)
+}
+
+func TestSectionService_UpdateSection_NotFound(t *testing.T) {
+	svc, sectionRepo, _, assessmentRepo := newTestSectionService(t)
+
+	assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+	sectionRepo.On("FindByID", uint(1), uint(5)).Return(nil, nil)
+
+	_, err := svc.UpdateSection(1, 5, models.SectionDTO{Title: "New"})
+
+	assert.ErrorIs(t, err, ErrSectionNotFound)
+	sectionRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestSectionService_DeleteSection(t *testing.T) {
+	svc, sectionRepo, _, assessmentRepo := newTestSectionService(t)
+
+	assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+	sectionRepo.On("FindByID", uint(1), uint(5)).Return(&models.AssessmentSection{ID: 5, AssessmentID: 1}, nil)
+	sectionRepo.On("Delete", uint(5)).Return(nil)
+
+	require.NoError(t, svc.DeleteSection(1, 5))
+	sectionRepo.AssertExpectations(t)
+}
+
+func TestSectionService_ListSections(t *testing.T) {
+	svc, sectionRepo, _, assessmentRepo := newTestSectionService(t)
+
+	sections := []models.AssessmentSection{{ID: 5, AssessmentID: 1, Title: "Part A"}}
+	assessmentRepo.On("FindByID", uint(1)).Return(&models.Assessment{ID: 1}, nil)
+	sectionRepo.On("ListByAssessment", uint(1)).Return(sections, nil)
+
+	result, err := svc.ListSections(1)
+
+	require.NoError(t, err)
+	assert.Equal(t, sections, result)
+}
@@ -0,0 +1,179 @@
+package types
+
+import (
+	models "assessment_service/internal/model"
+)
+
+// MultipleChoice has one correct option, stored in CorrectAnswer. The answer is an option ID.
+type MultipleChoice struct{}
+
+func (MultipleChoice) Name() string { return "multiple-choice" }
+
+func (MultipleChoice) ValidateDefinition(question *models.Question) error {
+	if err := requireOptions(question); err != nil {
+		return err
+	}
+	if !optionIDs(question)[question.CorrectAnswer] {
+		return invalidDefinition("correct answer must match one of the option IDs")
+	}
+	question.Config = nil
+	return nil
+}
+
+func (MultipleChoice) ValidateAnswer(question *models.Question, answer string) error {
+	if !optionIDs(question)[answer] {
+		return invalidAnswer("invalid answer for multiple-choice question")
+	}
+	return nil
+}
+
+func (MultipleChoice) Grade(question *models.Question, answer string) *bool {
+	return boolPtr(answer == question.CorrectAnswer)
+}
+
+func (MultipleChoice) Redact(question models.Question) models.Question {
+	return redactCommon(question)
+}
+
+// TrueFalse has "true" or "false" as its correct answer and answer
+type TrueFalse struct{}
+
+func (TrueFalse) Name() string { return "true-false" }
+
+func (TrueFalse) ValidateDefinition(question *models.Question) error {
+	if question.CorrectAnswer != "true" && question.CorrectAnswer != "false" {
+		return invalidDefinition("correct answer for true-false questions must be 'true' or 'false'")
+	}
+	question.Options = nil
+	question.Config = nil
+	return nil
+}
+
+func (TrueFalse) ValidateAnswer(question *models.Question, answer string) error {
+	if answer != "true" && answer != "false" {
+		return invalidAnswer("answer for true-false question must be 'true' or 'false'")
+	}
+	return nil
+}
+
+func (TrueFalse) Grade(question *models.Question, answer string) *bool {
+	return boolPtr(answer == question.CorrectAnswer)
+}
+
+func (TrueFalse) Redact(question models.Question) models.Question {
+	return redactCommon(question)
+}
+
+// MultipleSelect has any number of correct options. The answer is a JSON array of option IDs and is
+// correct when it picks exactly the correct options.
+type MultipleSelect struct{}
+
+type multipleSelectConfig struct {
+	CorrectOptions []string `json:"correctOptions"`
+}
+
+func (MultipleSelect) Name() string { return "multiple-select" }
+
+func (MultipleSelect) ValidateDefinition(question *models.Question) error {
+	if err := requireOptions(question); err != nil {
+		return err
+	}
+
+	var config multipleSelectConfig
+	if err := decodeConfig(question, &config); err != nil {
+		return err
+	}
+	if len(config.CorrectOptions) == 0 {
+		return invalidDefinition("multiple-select questions require at least one correct option")
+	}
+
+	ids := optionIDs(question)
+	seen := make(map[string]bool, len(config.CorrectOptions))
+	for _, id := range config.CorrectOptions {
+		if !ids[id] {
+			return invalidDefinition("correct option %q must match one of the option IDs", id)
+		}
+		if seen[id] {
+			return invalidDefinition("correct option %q is listed twice", id)
+		}
+		seen[id] = true
+	}
+
+	question.CorrectAnswer = ""
+	return encodeConfig(question, config)
+}
+
+func (MultipleSelect) ValidateAnswer(question *models.Question, answer string) error {
+	var selected []string
+	if err := decodeAnswer(question, answer, &selected); err != nil {
+		return err
+	}
+
+	ids := optionIDs(question)
+	seen := make(map[string]bool, len(selected))
+	for _, id := range selected {
+		if !ids[id] {
+			return invalidAnswer("%q is not an option of this question", id)
+		}
+		if seen[id] {
+			return invalidAnswer("option %q is selected twice", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+func (MultipleSelect) Grade(question *models.Question, answer string) *bool {
+	var config multipleSelectConfig
+	var selected []string
+	if decodeConfig(question, &config) != nil || decodeAnswer(question, answer, &selected) != nil {
+		return boolPtr(false)
+	}
+
+	if len(selected) != len(config.CorrectOptions) {
+		return boolPtr(false)
+	}
+	correct := make(map[string]bool, len(config.CorrectOptions))
+	for _, id := range config.CorrectOptions {
+		correct[id] = true
+	}
+	for _, id := range selected {
+		if !correct[id] {
+			return boolPtr(false)
+		}
+	}
+	return boolPtr(true)
+}
+
+// Credit gives a share of the points for each correct option picked, and counts each wrong option
+// picked as a share of the options that are not correct
+func (MultipleSelect) Credit(question *models.Question, answer string) (right, wrong float64) {
+	var config multipleSelectConfig
+	var selected []string
+	if decodeConfig(question, &config) != nil || decodeAnswer(question, answer, &selected) != nil || len(config.CorrectOptions) == 0 {
+		return 0, 1
+	}
+
+	correct := make(map[string]bool, len(config.CorrectOptions))
+	for _, id := range config.CorrectOptions {
+		correct[id] = true
+	}
+	hits, misses := 0, 0
+	for _, id := range selected {
+		if correct[id] {
+			hits++
+		} else {
+			misses++
+		}
+	}
+
+	right = float64(hits) / float64(len(config.CorrectOptions))
+	if incorrectOptions := len(question.Options) - len(config.CorrectOptions); incorrectOptions > 0 {
+		wrong = float64(misses) / float64(incorrectOptions)
+	}
+	return right, wrong
+}
+
+func (MultipleSelect) Redact(question models.Question) models.Question {
+	return redactCommon(question)
+}
@@ -0,0 +1,219 @@
+package types
+
+import models "assessment_service/internal/model"
+
+// Matching pairs each option with one of the choices in the config. The answer is a JSON object from
+// option ID to choice ID and is correct when every option is paired as in Pairs. A choice may be
+// used by several options or by none.
+type Matching struct{}
+
+type matchingChoice struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+type matchingConfig struct {
+	Choices []matchingChoice  `json:"choices"`
+	Pairs   map[string]string `json:"pairs,omitempty"`
+}
+
+func (Matching) Name() string { return "matching" }
+
+func (Matching) ValidateDefinition(question *models.Question) error {
+	if err := requireOptions(question); err != nil {
+		return err
+	}
+
+	var config matchingConfig
+	if err := decodeConfig(question, &config); err != nil {
+		return err
+	}
+	if len(config.Choices) == 0 {
+		return invalidDefinition("matching questions require choices")
+	}
+
+	choices := make(map[string]bool, len(config.Choices))
+	for _, choice := range config.Choices {
+		if choice.ID == "" {
+			return invalidDefinition("every choice needs an ID")
+		}
+		if choices[choice.ID] {
+			return invalidDefinition("choice ID %q is used twice", choice.ID)
+		}
+		choices[choice.ID] = true
+	}
+
+	ids := optionIDs(question)
+	for optionID, choiceID := range config.Pairs {
+		if !ids[optionID] {
+			return invalidDefinition("pair for %q must match one of the option IDs", optionID)
+		}
+		if !choices[choiceID] {
+			return invalidDefinition("option %q is paired with unknown choice %q", optionID, choiceID)
+		}
+	}
+	if len(config.Pairs) != len(ids) {
+		return invalidDefinition("every option must be paired with a choice")
+	}
+
+	question.CorrectAnswer = ""
+	return encodeConfig(question, config)
+}
+
+func (Matching) ValidateAnswer(question *models.Question, answer string) error {
+	var given map[string]string
+	if err := decodeAnswer(question, answer, &given); err != nil {
+		return err
+	}
+
+	var config matchingConfig
+	if err := decodeConfig(question, &config); err != nil {
+		return err
+	}
+	choices := make(map[string]bool, len(config.Choices))
+	for _, choice := range config.Choices {
+		choices[choice.ID] = true
+	}
+
+	ids := optionIDs(question)
+	for optionID, choiceID := range given {
+		if !ids[optionID] {
+			return invalidAnswer("%q is not an option of this question", optionID)
+		}
+		if !choices[choiceID] {
+			return invalidAnswer("%q is not a choice of this question", choiceID)
+		}
+	}
+	return nil
+}
+
+func (Matching) Grade(question *models.Question, answer string) *bool {
+	var config matchingConfig
+	var given map[string]string
+	if decodeConfig(question, &config) != nil || decodeAnswer(question, answer, &given) != nil {
+		return boolPtr(false)
+	}
+
+	if len(given) != len(config.Pairs) {
+		return boolPtr(false)
+	}
+	for optionID, choiceID := range config.Pairs {
+		if given[optionID] != choiceID {
+			return boolPtr(false)
+		}
+	}
+	return boolPtr(true)
+}
+
+// Credit gives a share of the points for each option paired correctly. Options paired wrongly count
+// as wrong, and options left unpaired count as neither.
+func (Matching) Credit(question *models.Question, answer string) (right, wrong float64) {
+	var config matchingConfig
+	var given map[string]string
+	if decodeConfig(question, &config) != nil || decodeAnswer(question, answer, &given) != nil || len(config.Pairs) == 0 {
+		return 0, 1
+	}
+
+	hits, misses := 0, 0
+	for optionID, choiceID := range given {
+		if config.Pairs[optionID] == choiceID {
+			hits++
+		} else {
+			misses++
+		}
+	}
+	return float64(hits) / float64(len(config.Pairs)), float64(misses) / float64(len(config.Pairs))
+}
+
+// Redact keeps the choices, which students need to answer, and drops the pairs
+func (Matching) Redact(question models.Question) models.Question {
+	redacted := redactCommon(question)
+
+	var config matchingConfig
+	if decodeConfig(&question, &config) == nil {
+		_ = encodeConfig(&redacted, matchingConfig{Choices: config.Choices})
+	}
+	return redacted
+}
+
+// Ordering asks for the options in the order given by the config. The answer is a JSON array of
+// every option ID.
+type Ordering struct{}
+
+type orderingConfig struct {
+	Order []string `json:"order"`
+}
+
+func (Ordering) Name() string { return "ordering" }
+
+func (Ordering) ValidateDefinition(question *models.Question) error {
+	if err := requireOptions(question); err != nil {
+		return err
+	}
+
+	var config orderingConfig
+	if err := decodeConfig(question, &config); err != nil {
+		return err
+	}
+	if !isPermutation(question, config.Order) {
+		return invalidDefinition("order must list every option ID once")
+	}
+
+	question.CorrectAnswer = ""
+	return encodeConfig(question, config)
+}
+
+func (Ordering) ValidateAnswer(question *models.Question, answer string) error {
+	var given []string
+	if err := decodeAnswer(question, answer, &given); err != nil {
+		return err
+	}
+	if !isPermutation(question, given) {
+		return invalidAnswer("answer for ordering question must list every option ID once")
+	}
+	return nil
+}
+
+func (Ordering) Grade(question *models.Question, answer string) *bool {
+	var config orderingConfig
+	var given []string
+	if decodeConfig(question, &config) != nil || decodeAnswer(question, answer, &given) != nil {
+		return boolPtr(false)
+	}
+
+	if len(given) != len(config.Order) {
+		return boolPtr(false)
+	}
+	for i := range config.Order {
+		if given[i] != config.Order[i] {
+			return boolPtr(false)
+		}
+	}
+	return boolPtr(true)
+}
+
+// Redact keeps the options in the order they come in. Present and Redact shuffle them first,
+// because the order they were written in gives the answer away.
+func (Ordering) Redact(question models.Question) models.Question {
+	return redactCommon(question)
+}
+
+// OrdersOptions marks ordering questions as answered by an order of their options
+func (Ordering) OrdersOptions() {}
+
+// isPermutation reports whether ids holds every option ID of the question exactly once
+func isPermutation(question *models.Question, ids []string) bool {
+	options := optionIDs(question)
+	if len(ids) != len(options) {
+		return false
+	}
+
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if !options[id] || seen[id] {
+			return false
+		}
+		seen[id] = true
+	}
+	return true
+}
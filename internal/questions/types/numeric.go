@@ -0,0 +1,67 @@
+package types
+
+import (
+	models "assessment_service/internal/model"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Numeric takes a number that is correct when it is within Tolerance of Answer
+type Numeric struct{}
+
+type numericConfig struct {
+	Answer    *float64 `json:"answer"`
+	Tolerance float64  `json:"tolerance"`
+}
+
+func (Numeric) Name() string { return "numeric" }
+
+func (Numeric) ValidateDefinition(question *models.Question) error {
+	var config numericConfig
+	if err := decodeConfig(question, &config); err != nil {
+		return err
+	}
+	if config.Answer == nil {
+		return invalidDefinition("numeric questions require an answer")
+	}
+	if config.Tolerance < 0 || math.IsNaN(config.Tolerance) {
+		return invalidDefinition("tolerance cannot be negative")
+	}
+
+	question.CorrectAnswer = ""
+	question.Options = nil
+	return encodeConfig(question, config)
+}
+
+func (Numeric) ValidateAnswer(question *models.Question, answer string) error {
+	if _, err := parseNumber(answer); err != nil {
+		return invalidAnswer("answer for numeric question must be a number")
+	}
+	return nil
+}
+
+func (Numeric) Grade(question *models.Question, answer string) *bool {
+	var config numericConfig
+	if decodeConfig(question, &config) != nil || config.Answer == nil {
+		return boolPtr(false)
+	}
+
+	given, err := parseNumber(answer)
+	if err != nil {
+		return boolPtr(false)
+	}
+	return boolPtr(math.Abs(given-*config.Answer) <= config.Tolerance)
+}
+
+func (Numeric) Redact(question models.Question) models.Question {
+	return redactCommon(question)
+}
+
+func parseNumber(answer string) (float64, error) {
+	n, err := strconv.ParseFloat(strings.TrimSpace(answer), 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, strconv.ErrSyntax
+	}
+	return n, nil
+}
@@ -0,0 +1,124 @@
+package types
+
+import (
+	models "assessment_service/internal/model"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidRubricScore is returned when rubric scores do not fit the question's rubric
+var ErrInvalidRubricScore = errors.New("invalid rubric score")
+
+// RubricCriterion is one thing a teacher judges an answer on, such as the argument of an essay
+type RubricCriterion struct {
+	ID          string        `json:"id"`
+	Title       string        `json:"title"`
+	Description string        `json:"description,omitempty"`
+	Levels      []RubricLevel `json:"levels"`
+}
+
+// RubricLevel is one of the marks a criterion can be given
+type RubricLevel struct {
+	Points      float64 `json:"points"`
+	Description string  `json:"description,omitempty"`
+}
+
+// maxPoints is the highest mark of the criterion
+func (c RubricCriterion) maxPoints() float64 {
+	highest := 0.0
+	for _, level := range c.Levels {
+		if level.Points > highest {
+			highest = level.Points
+		}
+	}
+	return highest
+}
+
+func (c RubricCriterion) hasLevel(points float64) bool {
+	for _, level := range c.Levels {
+		if level.Points == points {
+			return true
+		}
+	}
+	return false
+}
+
+// validateRubric checks that criteria have unique IDs and marks, and that the highest marks add up
+// to the question's points
+func validateRubric(question *models.Question, rubric []RubricCriterion) error {
+	total := 0.0
+	seen := make(map[string]bool, len(rubric))
+	for _, criterion := range rubric {
+		if criterion.ID == "" || criterion.Title == "" {
+			return invalidDefinition("every rubric criterion needs an ID and a title")
+		}
+		if seen[criterion.ID] {
+			return invalidDefinition("rubric criterion ID %q is used twice", criterion.ID)
+		}
+		seen[criterion.ID] = true
+
+		if len(criterion.Levels) == 0 {
+			return invalidDefinition("rubric criterion %q needs at least one level", criterion.ID)
+		}
+		levels := make(map[float64]bool, len(criterion.Levels))
+		for _, level := range criterion.Levels {
+			if level.Points < 0 {
+				return invalidDefinition("levels of rubric criterion %q cannot have negative points", criterion.ID)
+			}
+			if levels[level.Points] {
+				return invalidDefinition("rubric criterion %q has two levels worth %g points", criterion.ID, level.Points)
+			}
+			levels[level.Points] = true
+		}
+		total += criterion.maxPoints()
+	}
+
+	if total != question.Points {
+		return invalidDefinition("rubric is worth %g points but the question is worth %g", total, question.Points)
+	}
+	return nil
+}
+
+// Rubric returns the rubric of a question, or nil when it is graded without one
+func Rubric(question *models.Question) ([]RubricCriterion, error) {
+	if question.Type != (Essay{}).Name() || len(question.Config) == 0 {
+		return nil, nil
+	}
+
+	var config essayConfig
+	if err := decodeConfig(question, &config); err != nil {
+		return nil, err
+	}
+	return config.Rubric, nil
+}
+
+// ScoreRubric checks a teacher's rubric scores against the rubric and returns the points they add up
+// to. Every criterion must be scored once, with the points of one of its levels.
+func ScoreRubric(rubric []RubricCriterion, scores []models.RubricScore) (float64, error) {
+	criteria := make(map[string]RubricCriterion, len(rubric))
+	for _, criterion := range rubric {
+		criteria[criterion.ID] = criterion
+	}
+
+	total := 0.0
+	scored := make(map[string]bool, len(scores))
+	for _, score := range scores {
+		criterion, ok := criteria[score.CriterionID]
+		if !ok {
+			return 0, fmt.Errorf("%w: %q is not a criterion of this rubric", ErrInvalidRubricScore, score.CriterionID)
+		}
+		if scored[score.CriterionID] {
+			return 0, fmt.Errorf("%w: criterion %q is scored twice", ErrInvalidRubricScore, score.CriterionID)
+		}
+		if !criterion.hasLevel(score.Points) {
+			return 0, fmt.Errorf("%w: %g points is not a level of criterion %q", ErrInvalidRubricScore, score.Points, score.CriterionID)
+		}
+		scored[score.CriterionID] = true
+		total += score.Points
+	}
+
+	if len(scored) != len(rubric) {
+		return 0, fmt.Errorf("%w: every criterion of the rubric must be scored", ErrInvalidRubricScore)
+	}
+	return total, nil
+}
@@ -0,0 +1,95 @@
+package types
+
+import (
+	models "assessment_service/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const essayRubric = `{"rubric":[
+	{"id":"argument","title":"Argument","levels":[{"points":0},{"points":3,"description":"Partly convincing"},{"points":6,"description":"Convincing"}]},
+	{"id":"style","title":"Style","levels":[{"points":0},{"points":4}]}
+]}`
+
+func TestEssay_Rubric(t *testing.T) {
+	question := models.Question{Type: "essay", Text: "Discuss", Points: 10, Config: models.QuestionConfig(essayRubric)}
+	require.NoError(t, Validate(&question))
+
+	rubric, err := Rubric(&question)
+	require.NoError(t, err)
+	require.Len(t, rubric, 2)
+	assert.Equal(t, "argument", rubric[0].ID)
+	assert.Len(t, rubric[0].Levels, 3)
+
+	// Học sinh được xem rubric để biết bài được chấm thế nào
+	redacted := Redact(question)
+	assert.JSONEq(t, string(question.Config), string(redacted.Config))
+
+	// Câu hỏi không có rubric thì chấm bằng điểm
+	plain := models.Question{Type: "essay", Text: "Discuss", Points: 10, Config: models.QuestionConfig(`{"rubric":[]}`)}
+	require.NoError(t, Validate(&plain))
+	assert.Nil(t, plain.Config)
+	rubric, err = Rubric(&plain)
+	require.NoError(t, err)
+	assert.Nil(t, rubric)
+}
+
+func TestEssay_InvalidRubric(t *testing.T) {
+	tests := []struct {
+		name    string
+		points  float64
+		config  string
+		message string
+	}{
+		{"total does not match points", 12, essayRubric, "rubric is worth 10 points but the question is worth 12"},
+		{"criterion without title", 1, `{"rubric":[{"id":"a","levels":[{"points":1}]}]}`, "needs an ID and a title"},
+		{"duplicate criterion", 2, `{"rubric":[{"id":"a","title":"A","levels":[{"points":1}]},{"id":"a","title":"B","levels":[{"points":1}]}]}`, `criterion ID "a" is used twice`},
+		{"no levels", 0, `{"rubric":[{"id":"a","title":"A","levels":[]}]}`, "needs at least one level"},
+		{"duplicate level", 1, `{"rubric":[{"id":"a","title":"A","levels":[{"points":1},{"points":1}]}]}`, "two levels worth 1 points"},
+		{"negative level", 1, `{"rubric":[{"id":"a","title":"A","levels":[{"points":-1},{"points":1}]}]}`, "cannot have negative points"},
+		{"unknown field", 1, `{"criteria":[]}`, "unknown field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			question := models.Question{Type: "essay", Text: "Discuss", Points: tt.points, Config: models.QuestionConfig(tt.config)}
+			err := Validate(&question)
+			assert.ErrorIs(t, err, ErrInvalidDefinition)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestScoreRubric(t *testing.T) {
+	question := models.Question{Type: "essay", Text: "Discuss", Points: 10, Config: models.QuestionConfig(essayRubric)}
+	rubric, err := Rubric(&question)
+	require.NoError(t, err)
+
+	points, err := ScoreRubric(rubric, []models.RubricScore{
+		{CriterionID: "argument", Points: 3, Comment: "Needs more evidence"},
+		{CriterionID: "style", Points: 4},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 7.0, points)
+
+	tests := []struct {
+		name    string
+		scores  []models.RubricScore
+		message string
+	}{
+		{"missing criterion", []models.RubricScore{{CriterionID: "argument", Points: 6}}, "every criterion of the rubric must be scored"},
+		{"unknown criterion", []models.RubricScore{{CriterionID: "grammar", Points: 1}}, `"grammar" is not a criterion`},
+		{"not a level", []models.RubricScore{{CriterionID: "argument", Points: 5}, {CriterionID: "style", Points: 4}}, "5 points is not a level"},
+		{"scored twice", []models.RubricScore{{CriterionID: "style", Points: 4}, {CriterionID: "style", Points: 0}}, "scored twice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ScoreRubric(rubric, tt.scores)
+			assert.ErrorIs(t, err, ErrInvalidRubricScore)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package types
+
+import (
+	models "assessment_service/internal/model"
+	"strings"
+)
+
+// PartialGrader is implemented by question types that can give credit for an answer that is partly
+// right, such as a multiple-select answer that picks some of the correct options
+type PartialGrader interface {
+	// Credit returns how much of a valid answer is right and how much is wrong, each between 0 and 1
+	Credit(question *models.Question, answer string) (right, wrong float64)
+}
+
+// Validate checks a question's definition against its type and its scoring rules
+func Validate(question *models.Question) error {
+	questionType, err := Lookup(question.Type)
+	if err != nil {
+		return err
+	}
+
+	if err := questionType.ValidateDefinition(question); err != nil {
+		return err
+	}
+
+	if question.Points < 0 {
+		return invalidDefinition("points cannot be negative")
+	}
+	if question.Penalty < 0 || question.Penalty > 1 {
+		return invalidDefinition("penalty must be between 0 and 1")
+	}
+	if _, ok := questionType.(PartialGrader); question.PartialCredit && !ok {
+		return invalidDefinition("%s questions do not support partial credit", question.Type)
+	}
+
+	// Topic, difficulty and tags file the question into the pools that sections draw from
+	question.Topic = strings.TrimSpace(question.Topic)
+	question.Difficulty = models.QuestionDifficulty(strings.ToLower(strings.TrimSpace(string(question.Difficulty))))
+	if !question.Difficulty.Valid() {
+		return invalidDefinition("difficulty must be easy, medium or hard")
+	}
+	question.Tags = question.Tags.Normalized()
+	return nil
+}
+
+// Score grades a valid answer and works out its points. Both are nil when a teacher has to grade
+// the answer.
+func Score(questionType QuestionType, question *models.Question, answer string) (*bool, *float64) {
+	isCorrect := questionType.Grade(question, answer)
+	if isCorrect == nil {
+		return nil, nil
+	}
+	if *isCorrect {
+		points := Points(question, 1, 0)
+		return isCorrect, &points
+	}
+
+	right, wrong := 0.0, 1.0
+	if partial, ok := questionType.(PartialGrader); ok && question.PartialCredit {
+		right, wrong = partial.Credit(question, answer)
+	}
+	points := Points(question, right, wrong)
+	return isCorrect, &points
+}
+
+// Points is what an answer that is right and wrong by the given shares scores. The penalty is taken
+// off for the wrong share, and the result stays at zero or above unless the question allows
+// negative marks.
+func Points(question *models.Question, right, wrong float64) float64 {
+	points := question.Points*right - question.Points*question.Penalty*wrong
+	if points < 0 && !question.AllowNegative {
+		return 0
+	}
+	return points
+}
+
+// Percentage is the score out of 100 that answers earn on questions. Unanswered questions and
+// answers waiting for a teacher count as zero, and the score never goes below zero.
+func Percentage(questions []models.Question, answers []models.Answer) float64 {
+	awarded := make(map[uint]float64, len(answers))
+	for _, answer := range answers {
+		if answer.AwardedPoints != nil {
+			awarded[answer.QuestionID] = *answer.AwardedPoints
+		}
+	}
+
+	totalPoints := 0.0
+	earnedPoints := 0.0
+	for _, question := range questions {
+		totalPoints += question.Points
+		earnedPoints += awarded[question.ID]
+	}
+
+	if totalPoints <= 0 || earnedPoints <= 0 {
+		return 0
+	}
+	return earnedPoints / totalPoints * 100
+}
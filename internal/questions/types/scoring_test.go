@@ -0,0 +1,153 @@
+package types
+
+import (
+	models "assessment_service/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate_ScoringRules(t *testing.T) {
+	questions := validQuestions()
+
+	tests := []struct {
+		name     string
+		question models.Question
+		message  string
+	}{
+		{"negative points", withScoring(questions["multiple-choice"], -1, false, 0), "points cannot be negative"},
+		{"penalty above one", withScoring(questions["multiple-choice"], 4, false, 1.5), "penalty must be between 0 and 1"},
+		{"negative penalty", withScoring(questions["multiple-choice"], 4, false, -0.25), "penalty must be between 0 and 1"},
+		{"partial credit not supported", withScoring(questions["true-false"], 4, true, 0), "true-false questions do not support partial credit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&tt.question)
+			assert.ErrorIs(t, err, ErrInvalidDefinition)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+
+	for _, name := range []string{"multiple-select", "matching", "fill-in-the-blank"} {
+		question := withScoring(questions[name], 4, true, 0.5)
+		assert.NoError(t, Validate(&question), name)
+	}
+
+	assert.ErrorIs(t, Validate(&models.Question{Type: "drawing"}), ErrUnknownType)
+}
+
+func TestValidate_Classification(t *testing.T) {
+	question := validQuestions()["multiple-choice"]
+	question.Topic = " Algebra "
+	question.Difficulty = "Medium"
+	question.Tags = models.QuestionTags{" Linear", "linear", "Equations "}
+
+	require.NoError(t, Validate(&question))
+	assert.Equal(t, "Algebra", question.Topic)
+	assert.Equal(t, models.DifficultyMedium, question.Difficulty)
+	assert.Equal(t, models.QuestionTags{"linear", "equations"}, question.Tags)
+
+	question.Difficulty = "extreme"
+	err := Validate(&question)
+	assert.ErrorIs(t, err, ErrInvalidDefinition)
+	assert.Contains(t, err.Error(), "difficulty must be easy, medium or hard")
+}
+
+func TestScore(t *testing.T) {
+	questions := validQuestions()
+
+	tests := []struct {
+		name          string
+		question      models.Question
+		answer        string
+		wantCorrect   *bool
+		wantAwarded   *float64
+		allowNegative bool
+	}{
+		{"correct gets full points", withScoring(questions["multiple-choice"], 4, false, 0.25), "b", boolPtr(true), floatPtr(4), false},
+		{"wrong answer floored at zero", withScoring(questions["multiple-choice"], 4, false, 0.25), "a", boolPtr(false), floatPtr(0), false},
+		{"wrong answer marked down", withScoring(questions["multiple-choice"], 4, false, 0.25), "a", boolPtr(false), floatPtr(-1), true},
+		{"essay waits for a teacher", withScoring(questions["essay"], 4, false, 0.25), "Words", nil, nil, false},
+		// 1 trong 2 đáp án đúng được chọn: 4 * 1/2
+		{"multiple-select half right", withScoring(questions["multiple-select"], 4, true, 0), `["a"]`, boolPtr(false), floatPtr(2), false},
+		// 2 đúng, chọn thêm 1 trong 1 đáp án sai với penalty 0.5: 4 - 4*0.5
+		{"multiple-select extra option", withScoring(questions["multiple-select"], 4, true, 0.5), `["a","b","c"]`, boolPtr(false), floatPtr(2), false},
+		{"multiple-select only wrong", withScoring(questions["multiple-select"], 4, true, 0.5), `["b"]`, boolPtr(false), floatPtr(-2), true},
+		{"multiple-select no partial credit", withScoring(questions["multiple-select"], 4, false, 0), `["a"]`, boolPtr(false), floatPtr(0), false},
+		// 1 trong 2 cặp đúng, 1 cặp sai với penalty 0.5: 4*1/2 - 4*0.5*1/2
+		{"matching one pair wrong", withScoring(questions["matching"], 4, true, 0.5), `{"fr":"1","de":"3"}`, boolPtr(false), floatPtr(1), false},
+		{"matching one pair missing", withScoring(questions["matching"], 4, true, 0.5), `{"fr":"1"}`, boolPtr(false), floatPtr(2), false},
+		{"fill-in-the-blank one blank", withScoring(questions["fill-in-the-blank"], 4, true, 0), `["Eiffel Tower",""]`, boolPtr(false), floatPtr(2), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.question.AllowNegative = tt.allowNegative
+			questionType, err := Lookup(tt.question.Type)
+			require.NoError(t, err)
+			require.NoError(t, questionType.ValidateAnswer(&tt.question, tt.answer))
+
+			isCorrect, awarded := Score(questionType, &tt.question, tt.answer)
+
+			assert.Equal(t, tt.wantCorrect, isCorrect)
+			if tt.wantAwarded == nil {
+				assert.Nil(t, awarded)
+				return
+			}
+			require.NotNil(t, awarded)
+			assert.InDelta(t, *tt.wantAwarded, *awarded, 0.0001)
+		})
+	}
+}
+
+func TestPercentage(t *testing.T) {
+	questions := []models.Question{
+		{ID: 1, Points: 10},
+		{ID: 2, Points: 20},
+		{ID: 3, Points: 10},
+	}
+
+	tests := []struct {
+		name    string
+		answers []models.Answer
+		want    float64
+	}{
+		{"nothing answered", nil, 0},
+		{"awarded points add up", []models.Answer{
+			{QuestionID: 1, AwardedPoints: floatPtr(10)},
+			{QuestionID: 2, AwardedPoints: floatPtr(5)},
+			{QuestionID: 3}, // Chờ giáo viên chấm
+		}, 37.5},
+		{"negative marks cancel points", []models.Answer{
+			{QuestionID: 1, AwardedPoints: floatPtr(10)},
+			{QuestionID: 2, AwardedPoints: floatPtr(-6)},
+		}, 10},
+		{"never below zero", []models.Answer{
+			{QuestionID: 2, AwardedPoints: floatPtr(-6)},
+		}, 0},
+		{"answers to other questions are ignored", []models.Answer{
+			{QuestionID: 9, AwardedPoints: floatPtr(40)},
+		}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, Percentage(questions, tt.answers), 0.0001)
+		})
+	}
+
+	assert.Zero(t, Percentage(nil, []models.Answer{{QuestionID: 1, AwardedPoints: floatPtr(1)}}))
+}
+
+func withScoring(question models.Question, points float64, partialCredit bool, penalty float64) models.Question {
+	question.Points = points
+	question.PartialCredit = partialCredit
+	question.Penalty = penalty
+	return question
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
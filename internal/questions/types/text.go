@@ -0,0 +1,236 @@
+package types
+
+import (
+	models "assessment_service/internal/model"
+	"regexp"
+	"strings"
+)
+
+// Essay takes free text that a teacher grades, optionally against a rubric
+type Essay struct{}
+
+type essayConfig struct {
+	Rubric []RubricCriterion `json:"rubric"`
+}
+
+func (Essay) Name() string { return "essay" }
+
+func (Essay) ValidateDefinition(question *models.Question) error {
+	// Essay questions don't have a correct answer
+	question.CorrectAnswer = ""
+	question.Options = nil
+
+	var config essayConfig
+	if len(question.Config) > 0 {
+		if err := decodeConfig(question, &config); err != nil {
+			return err
+		}
+	}
+	if len(config.Rubric) == 0 {
+		question.Config = nil
+		return nil
+	}
+
+	if err := validateRubric(question, config.Rubric); err != nil {
+		return err
+	}
+	return encodeConfig(question, config)
+}
+
+func (Essay) ValidateAnswer(question *models.Question, answer string) error {
+	return nil
+}
+
+func (Essay) Grade(question *models.Question, answer string) *bool {
+	// Essay answers are graded manually
+	return nil
+}
+
+// Redact keeps the rubric so students know how their essay will be marked
+func (Essay) Redact(question models.Question) models.Question {
+	redacted := redactCommon(question)
+	redacted.Config = question.Config
+	return redacted
+}
+
+// ShortAnswer takes a short piece of text that is correct when it equals one of the accepted
+// answers or matches the pattern. Spacing is ignored and so is case unless CaseSensitive is set.
+type ShortAnswer struct{}
+
+type shortAnswerConfig struct {
+	AcceptedAnswers []string `json:"acceptedAnswers,omitempty"`
+	// Pattern is a regular expression the whole answer must match
+	Pattern       string `json:"pattern,omitempty"`
+	CaseSensitive bool   `json:"caseSensitive,omitempty"`
+}
+
+func (ShortAnswer) Name() string { return "short-answer" }
+
+func (ShortAnswer) ValidateDefinition(question *models.Question) error {
+	var config shortAnswerConfig
+	if err := decodeConfig(question, &config); err != nil {
+		return err
+	}
+
+	accepted := config.AcceptedAnswers[:0]
+	for _, answer := range config.AcceptedAnswers {
+		if strings.TrimSpace(answer) != "" {
+			accepted = append(accepted, answer)
+		}
+	}
+	config.AcceptedAnswers = accepted
+
+	if len(config.AcceptedAnswers) == 0 && config.Pattern == "" {
+		return invalidDefinition("short-answer questions require accepted answers or a pattern")
+	}
+	if config.Pattern != "" {
+		if _, err := config.compile(); err != nil {
+			return invalidDefinition("invalid pattern: %v", err)
+		}
+	}
+
+	question.CorrectAnswer = ""
+	question.Options = nil
+	return encodeConfig(question, config)
+}
+
+func (ShortAnswer) ValidateAnswer(question *models.Question, answer string) error {
+	return nil
+}
+
+func (ShortAnswer) Grade(question *models.Question, answer string) *bool {
+	var config shortAnswerConfig
+	if decodeConfig(question, &config) != nil {
+		return boolPtr(false)
+	}
+	return boolPtr(config.accepts(answer))
+}
+
+func (ShortAnswer) Redact(question models.Question) models.Question {
+	return redactCommon(question)
+}
+
+func (c shortAnswerConfig) compile() (*regexp.Regexp, error) {
+	pattern := "^(?:" + c.Pattern + ")$"
+	if !c.CaseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+func (c shortAnswerConfig) accepts(answer string) bool {
+	if acceptsAny(c.AcceptedAnswers, answer, c.CaseSensitive) {
+		return true
+	}
+
+	if c.Pattern != "" {
+		if re, err := c.compile(); err == nil && re.MatchString(strings.TrimSpace(answer)) {
+			return true
+		}
+	}
+	return false
+}
+
+// FillInTheBlank has blanks in its text, each written as BlankMarker. The answer is a JSON array
+// with one entry per blank, and is correct when every entry is accepted for its blank.
+type FillInTheBlank struct{}
+
+// BlankMarker marks where a blank is in the text of a fill-in-the-blank question
+const BlankMarker = "{{blank}}"
+
+type fillInTheBlankConfig struct {
+	// Blanks lists the accepted answers for each blank, in the order the blanks appear
+	Blanks        [][]string `json:"blanks"`
+	CaseSensitive bool       `json:"caseSensitive,omitempty"`
+}
+
+func (FillInTheBlank) Name() string { return "fill-in-the-blank" }
+
+func (FillInTheBlank) ValidateDefinition(question *models.Question) error {
+	var config fillInTheBlankConfig
+	if err := decodeConfig(question, &config); err != nil {
+		return err
+	}
+
+	blanks := strings.Count(question.Text, BlankMarker)
+	if blanks == 0 {
+		return invalidDefinition("fill-in-the-blank questions need at least one %s in the text", BlankMarker)
+	}
+	if len(config.Blanks) != blanks {
+		return invalidDefinition("the text has %d blanks but accepted answers were given for %d", blanks, len(config.Blanks))
+	}
+	for i, accepted := range config.Blanks {
+		if len(accepted) == 0 {
+			return invalidDefinition("blank %d has no accepted answers", i+1)
+		}
+	}
+
+	question.CorrectAnswer = ""
+	question.Options = nil
+	return encodeConfig(question, config)
+}
+
+func (FillInTheBlank) ValidateAnswer(question *models.Question, answer string) error {
+	var given []string
+	if err := decodeAnswer(question, answer, &given); err != nil {
+		return err
+	}
+	if blanks := strings.Count(question.Text, BlankMarker); len(given) != blanks {
+		return invalidAnswer("expected answers for %d blanks, got %d", blanks, len(given))
+	}
+	return nil
+}
+
+func (FillInTheBlank) Grade(question *models.Question, answer string) *bool {
+	var config fillInTheBlankConfig
+	var given []string
+	if decodeConfig(question, &config) != nil || decodeAnswer(question, answer, &given) != nil {
+		return boolPtr(false)
+	}
+	if len(given) != len(config.Blanks) {
+		return boolPtr(false)
+	}
+
+	for i, accepted := range config.Blanks {
+		if !acceptsAny(accepted, given[i], config.CaseSensitive) {
+			return boolPtr(false)
+		}
+	}
+	return boolPtr(true)
+}
+
+// Credit gives a share of the points for each blank filled in with an accepted answer. Blanks left
+// empty count as neither right nor wrong.
+func (FillInTheBlank) Credit(question *models.Question, answer string) (right, wrong float64) {
+	var config fillInTheBlankConfig
+	var given []string
+	if decodeConfig(question, &config) != nil || decodeAnswer(question, answer, &given) != nil ||
+		len(config.Blanks) == 0 || len(given) != len(config.Blanks) {
+		return 0, 1
+	}
+
+	hits, misses := 0, 0
+	for i, accepted := range config.Blanks {
+		switch {
+		case acceptsAny(accepted, given[i], config.CaseSensitive):
+			hits++
+		case strings.TrimSpace(given[i]) != "":
+			misses++
+		}
+	}
+	return float64(hits) / float64(len(config.Blanks)), float64(misses) / float64(len(config.Blanks))
+}
+
+func (FillInTheBlank) Redact(question models.Question) models.Question {
+	return redactCommon(question)
+}
+
+func acceptsAny(accepted []string, answer string, caseSensitive bool) bool {
+	given := normalizeText(answer, caseSensitive)
+	for _, a := range accepted {
+		if given == normalizeText(a, caseSensitive) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,254 @@
+package types
+
+import (
+	models "assessment_service/internal/model"
+	"assessment_service/internal/util"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrUnknownType       = errors.New("invalid question type")
+	ErrInvalidDefinition = errors.New("invalid question definition")
+	ErrInvalidAnswer     = errors.New("invalid answer")
+)
+
+// QuestionType is one kind of question. It knows what a valid question and a valid answer look
+// like, how to grade an answer and what a student may see.
+type QuestionType interface {
+	// Name is the value stored in Question.Type
+	Name() string
+	// ValidateDefinition checks the question's options, correct answer and config. It may normalise
+	// them, for example by clearing fields the type does not use.
+	ValidateDefinition(question *models.Question) error
+	// ValidateAnswer checks that a student's answer is well formed for the question
+	ValidateAnswer(question *models.Question, answer string) error
+	// Grade reports whether a valid answer is correct, or nil when a teacher has to grade it
+	Grade(question *models.Question, answer string) *bool
+	// Redact returns the question as a student sees it, without anything that gives the answer away
+	Redact(question models.Question) models.Question
+}
+
+// Registry holds the question types the service accepts, by name
+type Registry struct {
+	mu    sync.RWMutex
+	types map[string]QuestionType
+}
+
+func NewRegistry(questionTypes ...QuestionType) *Registry {
+	r := &Registry{types: make(map[string]QuestionType)}
+	for _, t := range questionTypes {
+		r.Register(t)
+	}
+	return r
+}
+
+// Register adds a question type, replacing any type registered under the same name
+func (r *Registry) Register(t QuestionType) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.types[t.Name()] = t
+}
+
+// Lookup returns the question type called name, or ErrUnknownType
+func (r *Registry) Lookup(name string) (QuestionType, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.types[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownType, name)
+	}
+	return t, nil
+}
+
+// Names lists the registered type names in alphabetical order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.types))
+	for name := range r.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Default is the registry used by the services. It starts with the built-in types; other types can
+// be added with Register.
+var Default = NewRegistry(
+	MultipleChoice{},
+	TrueFalse{},
+	Essay{},
+	MultipleSelect{},
+	ShortAnswer{},
+	Numeric{},
+	Matching{},
+	Ordering{},
+	FillInTheBlank{},
+)
+
+// Register adds a question type to the default registry
+func Register(t QuestionType) {
+	Default.Register(t)
+}
+
+// Lookup returns a question type from the default registry
+func Lookup(name string) (QuestionType, error) {
+	return Default.Lookup(name)
+}
+
+// OptionOrderer is implemented by question types whose answer is an order of the options, such as
+// ordering questions. Their options always reach students shuffled.
+type OptionOrderer interface {
+	OrdersOptions()
+}
+
+// Redact hides the answer of a question of any registered type. Questions of an unknown type keep
+// only the fields every type shares.
+func Redact(question models.Question) models.Question {
+	return Present(question, false, int64(question.ID))
+}
+
+// Present returns a question as a student sees it in an attempt: redacted, with its options
+// shuffled from seed when shuffleOptions is set or the type is an OptionOrderer. The same seed
+// always gives the same order.
+func Present(question models.Question, shuffleOptions bool, seed int64) models.Question {
+	t, err := Lookup(question.Type)
+	if err != nil {
+		return redactCommon(question)
+	}
+
+	question.Options = presentedOptions(t, question.Options, shuffleOptions, seed)
+	return t.Redact(question)
+}
+
+// OptionOrder returns the option IDs of a question in the order Present shows them
+func OptionOrder(question models.Question, shuffleOptions bool, seed int64) []string {
+	options := question.Options
+	if t, err := Lookup(question.Type); err == nil {
+		options = presentedOptions(t, options, shuffleOptions, seed)
+	}
+
+	order := make([]string, len(options))
+	for i, option := range options {
+		order[i] = option.OptionID
+	}
+	return order
+}
+
+func presentedOptions(t QuestionType, options []models.QuestionOption, shuffleOptions bool, seed int64) []models.QuestionOption {
+	if _, ordered := t.(OptionOrderer); ordered || shuffleOptions {
+		return util.ShuffleQuestionOptions(options, seed)
+	}
+	return options
+}
+
+// redactCommon keeps the fields every question type shows students
+func redactCommon(question models.Question) models.Question {
+	return models.Question{
+		ID:           question.ID,
+		AssessmentID: question.AssessmentID,
+		Type:         question.Type,
+		Text:         question.Text,
+		Options:      question.Options,
+		Points:       question.Points,
+	}
+}
+
+func invalidDefinition(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidDefinition, fmt.Sprintf(format, args...))
+}
+
+func invalidAnswer(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidAnswer, fmt.Sprintf(format, args...))
+}
+
+// decodeConfig reads a question's config into v, rejecting fields the type does not know
+func decodeConfig(question *models.Question, v interface{}) error {
+	if len(question.Config) == 0 {
+		return invalidDefinition("%s questions require a config", question.Type)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(question.Config))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return invalidDefinition("invalid config for %s question: %v", question.Type, err)
+	}
+	return nil
+}
+
+// encodeConfig stores v as the question's config
+func encodeConfig(question *models.Question, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	question.Config = data
+	return nil
+}
+
+// decodeAnswer reads a JSON encoded answer into v
+func decodeAnswer(question *models.Question, answer string, v interface{}) error {
+	if err := json.Unmarshal([]byte(answer), v); err != nil {
+		return invalidAnswer("answer for %s question must be %s", question.Type, describeJSON(v))
+	}
+	return nil
+}
+
+func describeJSON(v interface{}) string {
+	switch v.(type) {
+	case *[]string:
+		return "a JSON array of strings"
+	case *map[string]string:
+		return "a JSON object of strings"
+	default:
+		return "JSON"
+	}
+}
+
+// optionIDs returns the set of the question's option IDs
+func optionIDs(question *models.Question) map[string]bool {
+	ids := make(map[string]bool, len(question.Options))
+	for _, option := range question.Options {
+		ids[option.OptionID] = true
+	}
+	return ids
+}
+
+// requireOptions checks that the question has options and that their IDs are set and unique
+func requireOptions(question *models.Question) error {
+	if len(question.Options) == 0 {
+		return invalidDefinition("%s questions require options", question.Type)
+	}
+
+	seen := make(map[string]bool, len(question.Options))
+	for _, option := range question.Options {
+		if option.OptionID == "" {
+			return invalidDefinition("every option needs an ID")
+		}
+		if seen[option.OptionID] {
+			return invalidDefinition("option ID %q is used twice", option.OptionID)
+		}
+		seen[option.OptionID] = true
+	}
+	return nil
+}
+
+// normalizeText makes free text comparable: surrounding and repeated spaces are dropped and, unless
+// caseSensitive, letters are lower-cased
+func normalizeText(text string, caseSensitive bool) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if !caseSensitive {
+		text = strings.ToLower(text)
+	}
+	return text
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
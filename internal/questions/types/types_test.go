@@ -0,0 +1,254 @@
+package types
+
+import (
+	models "assessment_service/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func options(ids ...string) []models.QuestionOption {
+	result := make([]models.QuestionOption, len(ids))
+	for i, id := range ids {
+		result[i] = models.QuestionOption{OptionID: id, Text: "Option " + id}
+	}
+	return result
+}
+
+// validQuestions có một câu hỏi hợp lệ cho mỗi loại có sẵn
+func validQuestions() map[string]models.Question {
+	return map[string]models.Question{
+		"multiple-choice": {Type: "multiple-choice", Text: "Pick one", Options: options("a", "b"), CorrectAnswer: "b"},
+		"true-false":      {Type: "true-false", Text: "True?", CorrectAnswer: "true"},
+		"essay":           {Type: "essay", Text: "Discuss"},
+		"multiple-select": {Type: "multiple-select", Text: "Pick all", Options: options("a", "b", "c"),
+			Config: models.QuestionConfig(`{"correctOptions":["a","c"]}`)},
+		"short-answer": {Type: "short-answer", Text: "Capital of France?",
+			Config: models.QuestionConfig(`{"acceptedAnswers":["Paris"],"pattern":"paris,? france"}`)},
+		"numeric": {Type: "numeric", Text: "Pi to two places",
+			Config: models.QuestionConfig(`{"answer":3.14,"tolerance":0.005}`)},
+		"matching": {Type: "matching", Text: "Match capitals", Options: options("fr", "de"),
+			Config: models.QuestionConfig(`{"choices":[{"id":"1","text":"Paris"},{"id":"2","text":"Berlin"},{"id":"3","text":"Rome"}],"pairs":{"fr":"1","de":"2"}}`)},
+		"ordering": {Type: "ordering", Text: "Smallest first", Options: options("x", "y", "z"),
+			Config: models.QuestionConfig(`{"order":["z","x","y"]}`)},
+		"fill-in-the-blank": {Type: "fill-in-the-blank", Text: "The {{blank}} is in {{blank}}.",
+			Config: models.QuestionConfig(`{"blanks":[["Eiffel Tower","tour Eiffel"],["Paris"]]}`)},
+	}
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	assert.Equal(t, []string{
+		"essay", "fill-in-the-blank", "matching", "multiple-choice", "multiple-select",
+		"numeric", "ordering", "short-answer", "true-false",
+	}, Default.Names())
+
+	_, err := Lookup("drawing")
+	assert.ErrorIs(t, err, ErrUnknownType)
+	assert.Contains(t, err.Error(), "invalid question type")
+}
+
+type drawingType struct{ Essay }
+
+func (drawingType) Name() string { return "drawing" }
+
+func TestRegistry_Register(t *testing.T) {
+	registry := NewRegistry(Essay{})
+	registry.Register(drawingType{})
+
+	found, err := registry.Lookup("drawing")
+	require.NoError(t, err)
+	assert.Equal(t, "drawing", found.Name())
+	assert.Equal(t, []string{"drawing", "essay"}, registry.Names())
+}
+
+func TestValidateDefinition_Valid(t *testing.T) {
+	for name, question := range validQuestions() {
+		t.Run(name, func(t *testing.T) {
+			questionType, err := Lookup(name)
+			require.NoError(t, err)
+			assert.NoError(t, questionType.ValidateDefinition(&question))
+		})
+	}
+}
+
+func TestValidateDefinition_Normalises(t *testing.T) {
+	essay := models.Question{Type: "essay", CorrectAnswer: "x", Options: options("a"), Config: models.QuestionConfig(`{}`)}
+	require.NoError(t, Essay{}.ValidateDefinition(&essay))
+	assert.Empty(t, essay.CorrectAnswer)
+	assert.Nil(t, essay.Options)
+	assert.Nil(t, essay.Config)
+
+	// Câu trả lời rỗng bị bỏ đi
+	short := models.Question{Type: "short-answer", CorrectAnswer: "x",
+		Config: models.QuestionConfig(`{"acceptedAnswers":["Paris", " "]}`)}
+	require.NoError(t, ShortAnswer{}.ValidateDefinition(&short))
+	assert.Empty(t, short.CorrectAnswer)
+	assert.JSONEq(t, `{"acceptedAnswers":["Paris"]}`, string(short.Config))
+}
+
+func TestValidateDefinition_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		question models.Question
+		message  string
+	}{
+		{"MC without options", models.Question{Type: "multiple-choice", CorrectAnswer: "a"}, "multiple-choice questions require options"},
+		{"MC wrong answer", models.Question{Type: "multiple-choice", Options: options("a"), CorrectAnswer: "z"}, "correct answer must match one of the option IDs"},
+		{"MC duplicate options", models.Question{Type: "multiple-choice", Options: options("a", "a"), CorrectAnswer: "a"}, `option ID "a" is used twice`},
+		{"TF wrong answer", models.Question{Type: "true-false", CorrectAnswer: "maybe"}, "must be 'true' or 'false'"},
+		{"MS missing config", models.Question{Type: "multiple-select", Options: options("a")}, "require a config"},
+		{"MS no correct options", models.Question{Type: "multiple-select", Options: options("a"), Config: models.QuestionConfig(`{"correctOptions":[]}`)}, "at least one correct option"},
+		{"MS unknown option", models.Question{Type: "multiple-select", Options: options("a"), Config: models.QuestionConfig(`{"correctOptions":["q"]}`)}, `correct option "q"`},
+		{"MS unknown field", models.Question{Type: "multiple-select", Options: options("a"), Config: models.QuestionConfig(`{"correct":["a"]}`)}, "unknown field"},
+		{"short answer empty", models.Question{Type: "short-answer", Config: models.QuestionConfig(`{"acceptedAnswers":[]}`)}, "accepted answers or a pattern"},
+		{"short answer bad pattern", models.Question{Type: "short-answer", Config: models.QuestionConfig(`{"pattern":"(unclosed"}`)}, "invalid pattern"},
+		{"numeric no answer", models.Question{Type: "numeric", Config: models.QuestionConfig(`{"tolerance":1}`)}, "require an answer"},
+		{"numeric negative tolerance", models.Question{Type: "numeric", Config: models.QuestionConfig(`{"answer":1,"tolerance":-1}`)}, "tolerance cannot be negative"},
+		{"matching no choices", models.Question{Type: "matching", Options: options("a"), Config: models.QuestionConfig(`{"choices":[],"pairs":{"a":"1"}}`)}, "require choices"},
+		{"matching unpaired option", models.Question{Type: "matching", Options: options("a", "b"), Config: models.QuestionConfig(`{"choices":[{"id":"1"}],"pairs":{"a":"1"}}`)}, "every option must be paired"},
+		{"matching unknown choice", models.Question{Type: "matching", Options: options("a"), Config: models.QuestionConfig(`{"choices":[{"id":"1"}],"pairs":{"a":"9"}}`)}, `unknown choice "9"`},
+		{"ordering missing option", models.Question{Type: "ordering", Options: options("a", "b"), Config: models.QuestionConfig(`{"order":["a"]}`)}, "every option ID once"},
+		{"blank count mismatch", models.Question{Type: "fill-in-the-blank", Text: "{{blank}} and {{blank}}", Config: models.QuestionConfig(`{"blanks":[["x"]]}`)}, "has 2 blanks"},
+		{"no blanks", models.Question{Type: "fill-in-the-blank", Text: "No gaps", Config: models.QuestionConfig(`{"blanks":[]}`)}, "at least one {{blank}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			questionType, err := Lookup(tt.question.Type)
+			require.NoError(t, err)
+
+			err = questionType.ValidateDefinition(&tt.question)
+			assert.ErrorIs(t, err, ErrInvalidDefinition)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestValidateAnswerAndGrade(t *testing.T) {
+	tests := []struct {
+		questionType string
+		answer       string
+		invalid      bool
+		correct      *bool
+	}{
+		{"multiple-choice", "b", false, boolPtr(true)},
+		{"multiple-choice", "a", false, boolPtr(false)},
+		{"multiple-choice", "z", true, nil},
+		{"true-false", "true", false, boolPtr(true)},
+		{"true-false", "yes", true, nil},
+		{"essay", "Anything at all", false, nil},
+		{"multiple-select", `["c","a"]`, false, boolPtr(true)},
+		{"multiple-select", `["a"]`, false, boolPtr(false)},
+		{"multiple-select", `["a","b","c"]`, false, boolPtr(false)},
+		{"multiple-select", `["a","a"]`, true, nil},
+		{"multiple-select", `a,c`, true, nil},
+		{"short-answer", "  paris ", false, boolPtr(true)},
+		{"short-answer", "Paris France", false, boolPtr(true)},
+		{"short-answer", "Lyon", false, boolPtr(false)},
+		{"numeric", "3.142", false, boolPtr(true)},
+		{"numeric", "3.15", false, boolPtr(false)},
+		{"numeric", "pi", true, nil},
+		{"matching", `{"fr":"1","de":"2"}`, false, boolPtr(true)},
+		{"matching", `{"fr":"2","de":"1"}`, false, boolPtr(false)},
+		{"matching", `{"fr":"1"}`, false, boolPtr(false)},
+		{"matching", `{"it":"3"}`, true, nil},
+		{"ordering", `["z","x","y"]`, false, boolPtr(true)},
+		{"ordering", `["x","y","z"]`, false, boolPtr(false)},
+		{"ordering", `["z","x"]`, true, nil},
+		{"fill-in-the-blank", `["Tour Eiffel","paris"]`, false, boolPtr(true)},
+		{"fill-in-the-blank", `["Big Ben","Paris"]`, false, boolPtr(false)},
+		{"fill-in-the-blank", `["Paris"]`, true, nil},
+	}
+
+	questions := validQuestions()
+	for _, tt := range tests {
+		t.Run(tt.questionType+" "+tt.answer, func(t *testing.T) {
+			question := questions[tt.questionType]
+			questionType, err := Lookup(tt.questionType)
+			require.NoError(t, err)
+
+			err = questionType.ValidateAnswer(&question, tt.answer)
+			if tt.invalid {
+				assert.ErrorIs(t, err, ErrInvalidAnswer)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.correct, questionType.Grade(&question, tt.answer))
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	for name, question := range validQuestions() {
+		t.Run(name, func(t *testing.T) {
+			question.ID = 7
+			question.Points = 3
+			redacted := Redact(question)
+
+			assert.Equal(t, uint(7), redacted.ID)
+			assert.Equal(t, question.Text, redacted.Text)
+			assert.Equal(t, 3.0, redacted.Points)
+			assert.Empty(t, redacted.CorrectAnswer)
+			assert.ElementsMatch(t, question.Options, redacted.Options)
+
+			if name == "matching" {
+				// Học sinh vẫn cần thấy các lựa chọn nhưng không thấy cặp đúng
+				assert.JSONEq(t, `{"choices":[{"id":"1","text":"Paris"},{"id":"2","text":"Berlin"},{"id":"3","text":"Rome"}]}`, string(redacted.Config))
+			} else {
+				assert.Nil(t, redacted.Config)
+			}
+		})
+	}
+
+	unknown := Redact(models.Question{ID: 1, Type: "drawing", CorrectAnswer: "secret", Config: models.QuestionConfig(`{"a":1}`)})
+	assert.Empty(t, unknown.CorrectAnswer)
+	assert.Nil(t, unknown.Config)
+}
+
+func optionOrder(question models.Question) []string {
+	ids := make([]string, len(question.Options))
+	for i, option := range question.Options {
+		ids[i] = option.OptionID
+	}
+	return ids
+}
+
+func TestPresent(t *testing.T) {
+	choice := validQuestions()["multiple-choice"]
+	choice.Options = options("a", "b", "c", "d", "e", "f")
+	for i := range choice.Options {
+		choice.Options[i].ID = uint(i + 1)
+	}
+
+	// Không xáo trộn thì giữ nguyên thứ tự các lựa chọn
+	assert.Equal(t, []string{"a", "b", "c", "d", "e", "f"}, optionOrder(Present(choice, false, 1)))
+
+	// Cùng seed cho cùng thứ tự, và có seed cho thứ tự khác
+	shuffled := Present(choice, true, 7)
+	assert.Empty(t, shuffled.CorrectAnswer)
+	assert.ElementsMatch(t, []string{"a", "b", "c", "d", "e", "f"}, optionOrder(shuffled))
+	assert.Equal(t, optionOrder(shuffled), optionOrder(Present(choice, true, 7)))
+	different := false
+	for seed := int64(0); seed < 10; seed++ {
+		if !assert.ObjectsAreEqual(optionOrder(shuffled), optionOrder(Present(choice, true, seed))) {
+			different = true
+		}
+	}
+	assert.True(t, different)
+
+	// Câu hỏi sắp xếp luôn bị xáo trộn vì thứ tự viết ra chính là đáp án
+	ordering := validQuestions()["ordering"]
+	for i := range ordering.Options {
+		ordering.Options[i].ID = uint(i + 1)
+	}
+	presented := Present(ordering, false, 3)
+	assert.Equal(t, optionOrder(presented), optionOrder(Present(ordering, false, 3)))
+	assert.Equal(t, optionOrder(Present(ordering, true, 3)), optionOrder(presented))
+	assert.Equal(t, optionOrder(Present(ordering, false, int64(ordering.ID))), optionOrder(Redact(ordering)))
+
+	// OptionOrder cho đúng thứ tự học sinh đã thấy
+	assert.Equal(t, optionOrder(shuffled), OptionOrder(choice, true, 7))
+	assert.Equal(t, []string{"a", "b", "c", "d", "e", "f"}, OptionOrder(choice, false, 7))
+	assert.Equal(t, optionOrder(presented), OptionOrder(ordering, false, 3))
+}
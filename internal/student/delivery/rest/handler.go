@@ -1,17 +1,20 @@
 package rest
 
 import (
+	"assessment_service/internal/middleware"
+	models "assessment_service/internal/model"
+	"assessment_service/internal/questions/types"
 	"assessment_service/internal/student/service"
 	"assessment_service/internal/util"
 	"encoding/base64"
 	"encoding/json"
-	"github.com/golang-jwt/jwt/v5"
+	"errors"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
-	"time"
 )
 
 type StudentHandler struct {
@@ -25,9 +28,9 @@ func NewStudentHandler(studentService service.StudentService, log *zap.Logger) *
 
 func (h *StudentHandler) GetAvailableAssessments(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context (set by auth middleware)
-	userID, exists := r.Context().Value("user").(jwt.MapClaims)["userID"]
-	if !exists {
-		h.log.Error("[GetAvailableAssessments] userID not found in context")
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		h.log.Error("[GetAvailableAssessments] principal not found in context")
 		util.ResponseMap(w, map[string]interface{}{
 			"status":  "UNAUTHORIZED",
 			"message": "User ID not found in context",
@@ -38,19 +41,8 @@ func (h *StudentHandler) GetAvailableAssessments(w http.ResponseWriter, r *http.
 	// Parse pagination parameters
 	params := util.GetPaginationParams(r)
 
-	// parse userID to unit
-	userIDUnit, err := strconv.ParseUint(userID.(string), 10, 32)
-	if err != nil {
-		h.log.Error("[GetAvailableAssessments] failed to convert userID", zap.Error(err))
-		util.ResponseMap(w, map[string]interface{}{
-			"status":  "ERROR",
-			"message": "Failed to convert userID",
-		}, http.StatusInternalServerError)
-		return
-	}
-
 	// Get available assessments
-	assessments, total, err := h.studentService.GetAvailableAssessments(uint(userIDUnit), params)
+	assessments, total, err := h.studentService.GetAvailableAssessments(principal.UserID, params)
 	if err != nil {
 		h.log.Error("[GetAvailableAssessments] failed to fetch available assessments", zap.Error(err))
 		util.ResponseMap(w, map[string]interface{}{
@@ -68,9 +60,9 @@ func (h *StudentHandler) GetAvailableAssessments(w http.ResponseWriter, r *http.
 
 func (h *StudentHandler) StartAssessment(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context
-	userID, exists := r.Context().Value("user").(jwt.MapClaims)["userID"]
-	if !exists {
-		h.log.Error("[StartAssessment] userID not found in context")
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		h.log.Error("[StartAssessment] principal not found in context")
 		util.ResponseMap(w, map[string]interface{}{
 			"status":  "UNAUTHORIZED",
 			"message": "User ID not found in context",
@@ -78,17 +70,6 @@ func (h *StudentHandler) StartAssessment(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// parse userID to unit
-	userIDUnit, err := strconv.ParseUint(userID.(string), 10, 32)
-	if err != nil {
-		h.log.Error("[StartAssessment] failed to convert userID", zap.Error(err))
-		util.ResponseMap(w, map[string]interface{}{
-			"status":  "ERROR",
-			"message": "Failed to convert userID",
-		}, http.StatusInternalServerError)
-		return
-	}
-
 	// Get assessment ID from path
 	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
 	if err != nil {
@@ -101,7 +82,24 @@ func (h *StudentHandler) StartAssessment(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Start assessment
-	attempt, questions, settings, assessment, err := h.studentService.StartAssessment(uint(userIDUnit), uint(id))
+	attempt, questions, clock, assessment, err := h.studentService.StartAssessment(principal.UserID, uint(id), attemptClient(r))
+	if errors.Is(err, service.ErrAttemptInProgress) || errors.Is(err, service.ErrNoQuestionsDrawn) {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "CONFLICT",
+			"message": err.Error(),
+		}, http.StatusConflict)
+		return
+	}
+	if errors.Is(err, service.ErrAssessmentNotAssigned) ||
+		errors.Is(err, service.ErrAssessmentNotActive) ||
+		errors.Is(err, service.ErrAssessmentNotOpen) ||
+		errors.Is(err, service.ErrAssessmentClosed) {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "FORBIDDEN",
+			"message": err.Error(),
+		}, http.StatusForbidden)
+		return
+	}
 	if err != nil {
 		h.log.Error("[StartAssessment] failed to start assessment", zap.Error(err))
 		util.ResponseMap(w, map[string]interface{}{
@@ -111,29 +109,28 @@ func (h *StudentHandler) StartAssessment(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Calculate end time
-	endsAt := attempt.StartedAt.Add(time.Duration(assessment.Duration) * time.Minute)
-
 	// Create response
 	response := map[string]interface{}{
-		"attemptId":    attempt.ID,
-		"assessmentId": attempt.AssessmentID,
-		"title":        assessment.Title,
-		"duration":     assessment.Duration,
-		"timeLimit":    settings.TimeLimitEnforced,
-		"endsAt":       endsAt,
-		"questions":    questions,
-		"settings":     settings,
+		"attemptId":     attempt.ID,
+		"assessmentId":  attempt.AssessmentID,
+		"title":         assessment.Title,
+		"duration":      assessment.Duration,
+		"timeLimit":     assessment.Settings.TimeLimitEnforced,
+		"endsAt":        clock.EndsAt,
+		"timeRemaining": clock.TimeRemaining,
+		"questions":     questions,
+		"settings":      assessment.Settings,
 	}
 
 	util.ResponseInterface(w, response, http.StatusOK)
 }
 
-func (h *StudentHandler) GetAssessmentResultsHistory(w http.ResponseWriter, r *http.Request) {
-	// Get user ID from context
-	userID, exists := r.Context().Value("user").(jwt.MapClaims)["userID"]
-	if !exists {
-		h.log.Error("[GetAssessmentResultsHistory] userID not found in context")
+// ResumeAssessment returns the student's attempt of the assessment that is in progress, so they can
+// carry on after losing their browser
+func (h *StudentHandler) ResumeAssessment(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		h.log.Error("[ResumeAssessment] principal not found in context")
 		util.ResponseMap(w, map[string]interface{}{
 			"status":  "UNAUTHORIZED",
 			"message": "User ID not found in context",
@@ -141,17 +138,54 @@ func (h *StudentHandler) GetAssessmentResultsHistory(w http.ResponseWriter, r *h
 		return
 	}
 
-	// parse userID to unit
-	userIDUnit, err := strconv.ParseUint(userID.(string), 10, 32)
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
 	if err != nil {
-		h.log.Error("[GetAssessmentResultsHistory] failed to convert userID", zap.Error(err))
+		h.log.Error("[ResumeAssessment] invalid assessment ID", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid assessment ID",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	details, err := h.studentService.ResumeAssessment(principal.UserID, uint(id), attemptClient(r))
+	switch {
+	case errors.Is(err, service.ErrAttemptNotFound):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "NOT_FOUND",
+			"message": err.Error(),
+		}, http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrResumeNotAllowed):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "FORBIDDEN",
+			"message": err.Error(),
+		}, http.StatusForbidden)
+		return
+	case err != nil:
+		h.log.Error("[ResumeAssessment] failed to resume assessment", zap.Error(err))
 		util.ResponseMap(w, map[string]interface{}{
 			"status":  "ERROR",
-			"message": "Failed to convert userID",
+			"message": "Failed to resume assessment",
 		}, http.StatusInternalServerError)
 		return
 	}
 
+	util.ResponseInterface(w, details, http.StatusOK)
+}
+
+func (h *StudentHandler) GetAssessmentResultsHistory(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		h.log.Error("[GetAssessmentResultsHistory] principal not found in context")
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "UNAUTHORIZED",
+			"message": "User ID not found in context",
+		}, http.StatusUnauthorized)
+		return
+	}
+
 	// Get assessment ID from path
 	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
 	if err != nil {
@@ -164,7 +198,7 @@ func (h *StudentHandler) GetAssessmentResultsHistory(w http.ResponseWriter, r *h
 	}
 
 	// Get results
-	results, err := h.studentService.GetAssessmentResultsHistory(uint(userIDUnit), uint(id))
+	results, err := h.studentService.GetAssessmentResultsHistory(principal.UserID, uint(id))
 	if err != nil {
 		h.log.Error("[GetAssessmentResultsHistory] failed to fetch assessment results", zap.Error(err))
 		util.ResponseMap(w, map[string]interface{}{
@@ -179,26 +213,15 @@ func (h *StudentHandler) GetAssessmentResultsHistory(w http.ResponseWriter, r *h
 
 func (h *StudentHandler) GetAttemptDetails(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context
-	userID, exists := r.Context().Value("user").(jwt.MapClaims)["userID"]
-	if !exists {
-		h.log.Error("[GetAttemptDetails] userID not found in context")
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		h.log.Error("[GetAttemptDetails] principal not found in context")
 		util.ResponseMap(w, map[string]interface{}{
 			"status":  "UNAUTHORIZED",
 			"message": "User ID not found in context",
 		}, http.StatusUnauthorized)
 		return
 	}
-	// parse userID to unit
-	userIDUnit, err := strconv.ParseUint(userID.(string), 10, 32)
-	if err != nil {
-		h.log.Error("[GetAttemptDetails] failed to convert userID", zap.Error(err))
-		util.ResponseMap(w, map[string]interface{}{
-			"status":  "ERROR",
-			"message": "Failed to convert userID",
-		}, http.StatusInternalServerError)
-		return
-	}
-
 	// Get attempt ID from path
 	id, err := strconv.ParseUint(mux.Vars(r)["attemptId"], 10, 32)
 	if err != nil {
@@ -211,7 +234,14 @@ func (h *StudentHandler) GetAttemptDetails(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Get attempt details
-	details, err := h.studentService.GetAttemptDetails(uint(id), uint(userIDUnit))
+	details, err := h.studentService.GetAttemptDetails(uint(id), principal.UserID, attemptClient(r))
+	if errors.Is(err, service.ErrResumeNotAllowed) {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "FORBIDDEN",
+			"message": err.Error(),
+		}, http.StatusForbidden)
+		return
+	}
 	if err != nil {
 		h.log.Error("[GetAttemptDetails] failed to fetch attempt details", zap.Error(err))
 		util.ResponseMap(w, map[string]interface{}{
@@ -226,26 +256,15 @@ func (h *StudentHandler) GetAttemptDetails(w http.ResponseWriter, r *http.Reques
 
 func (h *StudentHandler) SaveAnswer(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context
-	userID, exists := r.Context().Value("user").(jwt.MapClaims)["userID"]
-	if !exists {
-		h.log.Error("[SaveAnswer] userID not found in context")
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		h.log.Error("[SaveAnswer] principal not found in context")
 		util.ResponseMap(w, map[string]interface{}{
 			"status":  "UNAUTHORIZED",
 			"message": "User ID not found in context",
 		}, http.StatusUnauthorized)
 		return
 	}
-	// parse userID to unit
-	userIDUnit, err := strconv.ParseUint(userID.(string), 10, 32)
-	if err != nil {
-		h.log.Error("[SaveAnswer] failed to convert userID", zap.Error(err))
-		util.ResponseMap(w, map[string]interface{}{
-			"status":  "ERROR",
-			"message": "Failed to convert userID",
-		}, http.StatusInternalServerError)
-		return
-	}
-
 	// Get attempt ID from path
 	attemptID, err := strconv.ParseUint(mux.Vars(r)["attemptId"], 10, 32)
 	if err != nil {
@@ -273,21 +292,12 @@ func (h *StudentHandler) SaveAnswer(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Convert answer to string based on its type
-	var answerStr string
-	switch v := req.Answer.(type) {
-	case string:
-		answerStr = v
-	case bool:
-		if v {
-			answerStr = "true"
-		} else {
-			answerStr = "false"
-		}
-	default:
+	answerStr, ok := answerString(req.Answer)
+	if !ok {
 		h.log.Error("[SaveAnswer] invalid answer type", zap.Any("answer", req.Answer))
 		util.ResponseMap(w, map[string]interface{}{
 			"status":  "BAD_REQUEST",
-			"message": "Invalid answer type, must be boolean or string",
+			"message": "Invalid answer type, must be a string, boolean, number, array or object",
 		}, http.StatusBadRequest)
 		return
 	}
@@ -300,11 +310,33 @@ func (h *StudentHandler) SaveAnswer(w http.ResponseWriter, r *http.Request) {
 			"status":  "ERROR",
 			"message": "Failed to convert questionID",
 		}, http.StatusBadRequest)
+		return
 	}
 
 	// Save answer
-	err = h.studentService.SaveAnswer(uint(attemptID), uint(questionIDUnit), answerStr, uint(userIDUnit))
+	err = h.studentService.SaveAnswer(uint(attemptID), uint(questionIDUnit), answerStr, principal.UserID, attemptClient(r))
 	if err != nil {
+		if errors.Is(err, service.ErrResumeNotAllowed) {
+			util.ResponseMap(w, map[string]interface{}{
+				"status":  "FORBIDDEN",
+				"message": err.Error(),
+			}, http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, service.ErrAttemptPaused) || errors.Is(err, service.ErrTimeUp) {
+			util.ResponseMap(w, map[string]interface{}{
+				"status":  "CONFLICT",
+				"message": err.Error(),
+			}, http.StatusConflict)
+			return
+		}
+		if errors.Is(err, types.ErrInvalidAnswer) {
+			util.ResponseMap(w, map[string]interface{}{
+				"status":  "BAD_REQUEST",
+				"message": err.Error(),
+			}, http.StatusBadRequest)
+			return
+		}
 		h.log.Error("[SaveAnswer] failed to save answer", zap.Error(err))
 		util.ResponseMap(w, map[string]interface{}{
 			"status":  "ERROR",
@@ -321,26 +353,15 @@ func (h *StudentHandler) SaveAnswer(w http.ResponseWriter, r *http.Request) {
 
 func (h *StudentHandler) SubmitAssessment(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context
-	userID, exists := r.Context().Value("user").(jwt.MapClaims)["userID"]
-	if !exists {
-		h.log.Error("[SubmitAssessment] userID not found in context")
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		h.log.Error("[SubmitAssessment] principal not found in context")
 		util.ResponseMap(w, map[string]interface{}{
 			"status":  "UNAUTHORIZED",
 			"message": "User ID not found in context",
 		}, http.StatusUnauthorized)
 		return
 	}
-	// parse userID to unit
-	userIDUnit, err := strconv.ParseUint(userID.(string), 10, 32)
-	if err != nil {
-		h.log.Error("[SubmitAssessment] failed to convert userID", zap.Error(err))
-		util.ResponseMap(w, map[string]interface{}{
-			"status":  "ERROR",
-			"message": "Failed to convert userID",
-		}, http.StatusInternalServerError)
-		return
-	}
-
 	// Get attempt ID from path
 	attemptID, err := strconv.ParseUint(mux.Vars(r)["attemptId"], 10, 32)
 	if err != nil {
@@ -353,7 +374,21 @@ func (h *StudentHandler) SubmitAssessment(w http.ResponseWriter, r *http.Request
 	}
 
 	// Submit assessment
-	result, err := h.studentService.SubmitAssessment(uint(attemptID), uint(userIDUnit))
+	result, err := h.studentService.SubmitAssessment(uint(attemptID), principal.UserID, attemptClient(r))
+	if errors.Is(err, service.ErrResumeNotAllowed) {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "FORBIDDEN",
+			"message": err.Error(),
+		}, http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, service.ErrAttemptPaused) {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "CONFLICT",
+			"message": err.Error(),
+		}, http.StatusConflict)
+		return
+	}
 	if err != nil {
 		h.log.Error("[SubmitAssessment] failed to submit assessment", zap.Error(err))
 		util.ResponseMap(w, map[string]interface{}{
@@ -368,26 +403,15 @@ func (h *StudentHandler) SubmitAssessment(w http.ResponseWriter, r *http.Request
 
 func (h *StudentHandler) SubmitMonitorEvent(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context
-	userID, exists := r.Context().Value("user").(jwt.MapClaims)["userID"]
-	if !exists {
-		h.log.Error("[SubmitMonitorEvent] userID not found in context")
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		h.log.Error("[SubmitMonitorEvent] principal not found in context")
 		util.ResponseMap(w, map[string]interface{}{
 			"status":  "UNAUTHORIZED",
 			"message": "User ID not found in context",
 		}, http.StatusUnauthorized)
 		return
 	}
-	// parse userID to unit
-	userIDUnit, err := strconv.ParseUint(userID.(string), 10, 32)
-	if err != nil {
-		h.log.Error("[SubmitMonitorEvent] failed to convert userID", zap.Error(err))
-		util.ResponseMap(w, map[string]interface{}{
-			"status":  "ERROR",
-			"message": "Failed to convert userID",
-		}, http.StatusInternalServerError)
-		return
-	}
-
 	// Get attempt ID from path
 	attemptID, err := strconv.ParseUint(mux.Vars(r)["attemptId"], 10, 32)
 	if err != nil {
@@ -432,7 +456,14 @@ func (h *StudentHandler) SubmitMonitorEvent(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Submit event
-	result, err := h.studentService.SubmitMonitorEvent(uint(attemptID), req.EventType, req.Details, imageData, uint(userIDUnit))
+	result, err := h.studentService.SubmitMonitorEvent(uint(attemptID), req.EventType, req.Details, imageData, principal.UserID, attemptClient(r))
+	if errors.Is(err, service.ErrResumeNotAllowed) {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "FORBIDDEN",
+			"message": err.Error(),
+		}, http.StatusForbidden)
+		return
+	}
 	if err != nil {
 		h.log.Error("[SubmitMonitorEvent] failed to submit monitor event", zap.Error(err))
 		util.ResponseMap(w, map[string]interface{}{
@@ -475,3 +506,133 @@ func (h *StudentHandler) GetAllAttemptForUser(w http.ResponseWriter, r *http.Req
 
 	util.ResponseInterface(w, result, http.StatusOK)
 }
+
+// answerString turns an answer from the request body into the string stored with the attempt.
+// Arrays and objects, used by question types such as matching or ordering, are kept as JSON.
+// RequestRegrade asks for a graded attempt, or one answer of it, to be looked at again
+func (h *StudentHandler) RequestRegrade(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		h.log.Error("[RequestRegrade] principal not found in context")
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "UNAUTHORIZED",
+			"message": "User ID not found in context",
+		}, http.StatusUnauthorized)
+		return
+	}
+
+	attemptID, err := strconv.ParseUint(mux.Vars(r)["attemptId"], 10, 32)
+	if err != nil {
+		h.log.Error("[RequestRegrade] invalid attempt ID", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid attempt ID",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	var request models.RegradeRequestDTO
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.log.Error("[RequestRegrade] invalid request body", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid request body",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	regrade, err := h.studentService.RequestRegrade(uint(attemptID), principal.UserID, request)
+	if err != nil {
+		h.writeRegradeError(w, "RequestRegrade", err, "Failed to request regrade")
+		return
+	}
+
+	util.ResponseInterface(w, regrade, http.StatusCreated)
+}
+
+// GetRegradeRequests lists the student's regrade requests for an attempt with the graders' answers
+func (h *StudentHandler) GetRegradeRequests(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		h.log.Error("[GetRegradeRequests] principal not found in context")
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "UNAUTHORIZED",
+			"message": "User ID not found in context",
+		}, http.StatusUnauthorized)
+		return
+	}
+
+	attemptID, err := strconv.ParseUint(mux.Vars(r)["attemptId"], 10, 32)
+	if err != nil {
+		h.log.Error("[GetRegradeRequests] invalid attempt ID", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid attempt ID",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	requests, err := h.studentService.GetRegradeRequests(uint(attemptID), principal.UserID)
+	if err != nil {
+		h.writeRegradeError(w, "GetRegradeRequests", err, "Failed to get regrade requests")
+		return
+	}
+
+	util.ResponseInterface(w, requests, http.StatusOK)
+}
+
+// writeRegradeError maps regrade request errors to HTTP responses
+func (h *StudentHandler) writeRegradeError(w http.ResponseWriter, fn string, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrAttemptNotFound):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "NOT_FOUND",
+			"message": err.Error(),
+		}, http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidRegradeRequest):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": err.Error(),
+		}, http.StatusBadRequest)
+	case errors.Is(err, service.ErrNotRegradable), errors.Is(err, service.ErrRegradePending):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "CONFLICT",
+			"message": err.Error(),
+		}, http.StatusConflict)
+	default:
+		h.log.Error("["+fn+"] "+message, zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "ERROR",
+			"message": message,
+		}, http.StatusInternalServerError)
+	}
+}
+
+func answerString(answer interface{}) (string, bool) {
+	switch v := answer.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case []interface{}, map[string]interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+		return string(data), true
+	default:
+		return "", false
+	}
+}
+
+// attemptClient is the device a request comes from. The port is left out of the IP address because
+// it changes from one connection to the next.
+func attemptClient(r *http.Request) models.AttemptClient {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	return models.AttemptClient{IPAddress: ip, UserAgent: r.UserAgent()}
+}
@@ -1,10 +1,12 @@
 package rest
 
 import (
+	"assessment_service/internal/middleware"
 	models "assessment_service/internal/model"
+	"assessment_service/internal/questions/types"
+	"assessment_service/internal/student/service"
 	"assessment_service/internal/util"
 	"bytes"
-	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,7 +16,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -34,8 +35,8 @@ func (m *MockStudentService) GetAvailableAssessments(userID uint, params util.Pa
 	}
 	return args.Get(0).([]map[string]interface{}), args.Get(1).(int64), args.Error(2)
 }
-func (m *MockStudentService) StartAssessment(userID, assessmentID uint) (*models.Attempt, []models.Question, *models.AssessmentSettings, *models.Assessment, error) {
-	args := m.Called(userID, assessmentID)
+func (m *MockStudentService) StartAssessment(userID, assessmentID uint, client models.AttemptClient) (*models.Attempt, []models.Question, *models.AttemptClock, *models.Assessment, error) {
+	args := m.Called(userID, assessmentID, client)
 	// Handle nil returns carefully
 	var attempt *models.Attempt
 	if args.Get(0) != nil {
@@ -45,15 +46,20 @@ func (m *MockStudentService) StartAssessment(userID, assessmentID uint) (*models
 	if args.Get(1) != nil {
 		questions = args.Get(1).([]models.Question)
 	}
-	var settings *models.AssessmentSettings
+	var clock *models.AttemptClock
 	if args.Get(2) != nil {
-		settings = args.Get(2).(*models.AssessmentSettings)
+		clock = args.Get(2).(*models.AttemptClock)
 	}
 	var assessment *models.Assessment
 	if args.Get(3) != nil {
 		assessment = args.Get(3).(*models.Assessment)
 	}
-	return attempt, questions, settings, assessment, args.Error(4)
+	return attempt, questions, clock, assessment, args.Error(4)
+}
+func (m *MockStudentService) ResumeAssessment(userID, assessmentID uint, client models.AttemptClient) (*map[string]interface{}, error) {
+	args := m.Called(userID, assessmentID, client)
+	details, _ := args.Get(0).(*map[string]interface{})
+	return details, args.Error(1)
 }
 func (m *MockStudentService) GetAssessmentResultsHistory(userID, assessmentID uint) ([]map[string]interface{}, error) {
 	args := m.Called(userID, assessmentID)
@@ -62,8 +68,8 @@ func (m *MockStudentService) GetAssessmentResultsHistory(userID, assessmentID ui
 	}
 	return args.Get(0).([]map[string]interface{}), args.Error(1)
 }
-func (m *MockStudentService) GetAttemptDetails(attemptID, userID uint) (*map[string]interface{}, error) {
-	args := m.Called(attemptID, userID)
+func (m *MockStudentService) GetAttemptDetails(attemptID, userID uint, client models.AttemptClient) (*map[string]interface{}, error) {
+	args := m.Called(attemptID, userID, client)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -71,21 +77,33 @@ func (m *MockStudentService) GetAttemptDetails(attemptID, userID uint) (*map[str
 	resMap := args.Get(0).(*map[string]interface{})
 	return resMap, args.Error(1)
 }
-func (m *MockStudentService) SaveAnswer(attemptID, questionID uint, answer string, userID uint) error {
-	args := m.Called(attemptID, questionID, answer, userID)
+
+func (m *MockStudentService) AttemptClock(attemptID, userID uint, client models.AttemptClient) (*models.AttemptClock, error) {
+	args := m.Called(attemptID, userID, client)
+	clock, _ := args.Get(0).(*models.AttemptClock)
+	return clock, args.Error(1)
+}
+
+func (m *MockStudentService) Heartbeat(attemptID, userID uint, client models.AttemptClient) (*models.AttemptClock, error) {
+	args := m.Called(attemptID, userID, client)
+	clock, _ := args.Get(0).(*models.AttemptClock)
+	return clock, args.Error(1)
+}
+func (m *MockStudentService) SaveAnswer(attemptID, questionID uint, answer string, userID uint, client models.AttemptClient) error {
+	args := m.Called(attemptID, questionID, answer, userID, client)
 	return args.Error(0)
 }
-func (m *MockStudentService) SubmitAssessment(attemptID, userID uint) (*map[string]interface{}, error) {
-	args := m.Called(attemptID, userID)
+func (m *MockStudentService) SubmitAssessment(attemptID, userID uint, client models.AttemptClient) (*map[string]interface{}, error) {
+	args := m.Called(attemptID, userID, client)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	resMap, _ := args.Get(0).(*map[string]interface{})
 	return resMap, args.Error(1)
 }
-func (m *MockStudentService) SubmitMonitorEvent(attemptID uint, eventType string, details map[string]interface{}, imageData []byte, userID uint) (*map[string]interface{}, error) {
+func (m *MockStudentService) SubmitMonitorEvent(attemptID uint, eventType string, details map[string]interface{}, imageData []byte, userID uint, client models.AttemptClient) (*map[string]interface{}, error) {
 	// Note: Comparing []byte might be tricky with mock.Anything, consider specific matching if needed
-	args := m.Called(attemptID, eventType, details, imageData, userID)
+	args := m.Called(attemptID, eventType, details, imageData, userID, client)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -104,11 +122,23 @@ func (m *MockStudentService) GetAllAttemptByUserID(userID uint, params util.Pagi
 	return args.Get(0).([]models.Attempt), args.Get(1).(int64), args.Error(2)
 }
 
-// Helper function to create a request with context containing JWT claims
-func createRequestWithStudentClaims(method, url string, body []byte, claims jwt.MapClaims) *http.Request {
+func (m *MockStudentService) RequestRegrade(attemptID, userID uint, request models.RegradeRequestDTO) (*models.RegradeRequest, error) {
+	args := m.Called(attemptID, userID, request)
+	regrade, _ := args.Get(0).(*models.RegradeRequest)
+	return regrade, args.Error(1)
+}
+
+func (m *MockStudentService) GetRegradeRequests(attemptID, userID uint) ([]models.RegradeRequest, error) {
+	args := m.Called(attemptID, userID)
+	requests, _ := args.Get(0).([]models.RegradeRequest)
+	return requests, args.Error(1)
+}
+
+// Helper function to create a request with context containing the authenticated principal
+func createRequestWithStudentClaims(method, url string, body []byte, principal *middleware.Principal) *http.Request {
 	req := httptest.NewRequest(method, url, bytes.NewBuffer(body))
-	if claims != nil {
-		ctx := context.WithValue(req.Context(), "user", claims) // Key "user" phải khớp
+	if principal != nil {
+		ctx := middleware.WithPrincipal(req.Context(), principal)
 		req = req.WithContext(ctx)
 	}
 	return req
@@ -126,10 +156,10 @@ func TestStudentHandler_GetAvailableAssessments(t *testing.T) {
 	expectedTotal := int64(1)
 	expectedParams := util.PaginationParams{Page: 0, Limit: 10, Offset: 0, SortBy: "created_at", SortDir: "DESC", Filters: map[string]interface{}{}} // Default params
 
-	claims := jwt.MapClaims{"userID": "123"} // User ID từ token
+	principal := &middleware.Principal{UserID: 123, Role: "student"} // User ID từ token
 	mockService.On("GetAvailableAssessments", userID, expectedParams).Return(expectedAssessments, expectedTotal, nil)
 
-	req := createRequestWithStudentClaims(http.MethodGet, "/student/assessments/available", nil, claims)
+	req := createRequestWithStudentClaims(http.MethodGet, "/student/assessments/available", nil, principal)
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -149,18 +179,18 @@ func TestStudentHandler_GetAvailableAssessments(t *testing.T) {
 }
 
 func TestStudentHandler_GetAvailableAssessments_NoUserID(t *testing.T) {
-	//mockService := new(MockStudentService)
-	//logger := zaptest.NewLogger(t)
-	//handler := NewStudentHandler(mockService, logger)
-	//
-	//req := createRequestWithStudentClaims(http.MethodGet, "/student/assessments/available", nil, nil) // No claims
-	//rr := httptest.NewRecorder()
-	//router := mux.NewRouter()
-	//router.HandleFunc("/student/assessments/available", handler.GetAvailableAssessments).Methods(http.MethodGet)
-	//router.ServeHTTP(rr, req)
-	//
-	//assert.Equal(t, http.StatusUnauthorized, rr.Code)
-	//mockService.AssertNotCalled(t, "GetAvailableAssessments", mock.Anything, mock.Anything)
+	mockService := new(MockStudentService)
+	logger := zaptest.NewLogger(t)
+	handler := NewStudentHandler(mockService, logger)
+
+	req := createRequestWithStudentClaims(http.MethodGet, "/student/assessments/available", nil, nil) // No principal
+	rr := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/student/assessments/available", handler.GetAvailableAssessments).Methods(http.MethodGet)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	mockService.AssertNotCalled(t, "GetAvailableAssessments", mock.Anything, mock.Anything)
 }
 
 func TestStudentHandler_GetAvailableAssessments_ServiceError(t *testing.T) {
@@ -170,11 +200,11 @@ func TestStudentHandler_GetAvailableAssessments_ServiceError(t *testing.T) {
 
 	userID := uint(123)
 	params := util.PaginationParams{Page: 0, Limit: 10, Offset: 0, SortBy: "created_at", SortDir: "DESC", Filters: map[string]interface{}{}}
-	claims := jwt.MapClaims{"userID": "123"}
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
 
 	mockService.On("GetAvailableAssessments", userID, params).Return(nil, int64(0), errors.New("service error"))
 
-	req := createRequestWithStudentClaims(http.MethodGet, "/student/assessments/available", nil, claims)
+	req := createRequestWithStudentClaims(http.MethodGet, "/student/assessments/available", nil, principal)
 	rr := httptest.NewRecorder()
 	router := mux.NewRouter()
 	router.HandleFunc("/student/assessments/available", handler.GetAvailableAssessments).Methods(http.MethodGet)
@@ -194,13 +224,15 @@ func TestStudentHandler_StartAssessment(t *testing.T) {
 	now := time.Now()
 	expectedAttempt := &models.Attempt{ID: 99, UserID: userID, AssessmentID: assessmentID, StartedAt: now}
 	expectedQuestions := []models.Question{{ID: 1, Text: "Q1"}}
-	expectedSettings := &models.AssessmentSettings{TimeLimitEnforced: true}
-	expectedAssessment := &models.Assessment{ID: assessmentID, Title: "Test Quiz", Duration: 60}
+	// Hạn chót có cả giây, không làm tròn theo phút
+	endsAt := now.Add(90*time.Minute + 30*time.Second)
+	expectedClock := &models.AttemptClock{AttemptID: 99, EndsAt: endsAt, TimeRemaining: 5430}
+	expectedAssessment := &models.Assessment{ID: assessmentID, Title: "Test Quiz", Duration: 90, Settings: models.AssessmentSettings{TimeLimitEnforced: true}}
 
-	claims := jwt.MapClaims{"userID": "123"}
-	mockService.On("StartAssessment", userID, assessmentID).Return(expectedAttempt, expectedQuestions, expectedSettings, expectedAssessment, nil)
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
+	mockService.On("StartAssessment", userID, assessmentID, models.AttemptClient{IPAddress: "192.0.2.1"}).Return(expectedAttempt, expectedQuestions, expectedClock, expectedAssessment, nil)
 
-	req := createRequestWithStudentClaims(http.MethodPost, fmt.Sprintf("/student/assessments/%d/start", assessmentID), nil, claims)
+	req := createRequestWithStudentClaims(http.MethodPost, fmt.Sprintf("/student/assessments/%d/start", assessmentID), nil, principal)
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -216,13 +248,129 @@ func TestStudentHandler_StartAssessment(t *testing.T) {
 	assert.Equal(t, float64(expectedAttempt.ID), resp["attemptId"])
 	assert.Equal(t, float64(assessmentID), resp["assessmentId"])
 	assert.Equal(t, expectedAssessment.Title, resp["title"])
+	assert.Equal(t, endsAt.Format(time.RFC3339Nano), resp["endsAt"])
+	assert.Equal(t, float64(5430), resp["timeRemaining"])
+	assert.Equal(t, true, resp["timeLimit"])
 	questionsResp, ok := resp["questions"].([]interface{})
 	assert.True(t, ok)
 	assert.Len(t, questionsResp, 1)
 }
 
+func TestStudentHandler_StartAssessment_NotAssigned(t *testing.T) {
+	mockService := new(MockStudentService)
+	logger := zaptest.NewLogger(t)
+	handler := NewStudentHandler(mockService, logger)
+
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
+	mockService.On("StartAssessment", uint(123), uint(10), mock.AnythingOfType("models.AttemptClient")).Return(nil, nil, nil, nil, service.ErrAssessmentNotAssigned)
+
+	req := createRequestWithStudentClaims(http.MethodPost, "/student/assessments/10/start", nil, principal)
+	rr := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/student/assessments/{id:[0-9]+}/start", handler.StartAssessment).Methods(http.MethodPost)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestStudentHandler_StartAssessment_OutsideWindow(t *testing.T) {
+	for _, err := range []error{service.ErrAssessmentNotOpen, service.ErrAssessmentClosed} {
+		mockService := new(MockStudentService)
+		logger := zaptest.NewLogger(t)
+		handler := NewStudentHandler(mockService, logger)
+
+		principal := &middleware.Principal{UserID: 123, Role: "student"}
+		mockService.On("StartAssessment", uint(123), uint(10), mock.AnythingOfType("models.AttemptClient")).Return(nil, nil, nil, nil, err)
+
+		req := createRequestWithStudentClaims(http.MethodPost, "/student/assessments/10/start", nil, principal)
+		rr := httptest.NewRecorder()
+
+		router := mux.NewRouter()
+		router.HandleFunc("/student/assessments/{id:[0-9]+}/start", handler.StartAssessment).Methods(http.MethodPost)
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code, err.Error())
+	}
+}
+
 // Thêm test case lỗi cho StartAssessment (invalid ID, service error, user not found in context)
 
+func TestStudentHandler_StartAssessment_AlreadyInProgress(t *testing.T) {
+	mockService := new(MockStudentService)
+	handler := NewStudentHandler(mockService, zaptest.NewLogger(t))
+
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
+	mockService.On("StartAssessment", uint(123), uint(10), mock.AnythingOfType("models.AttemptClient")).Return(nil, nil, nil, nil, service.ErrAttemptInProgress)
+
+	req := createRequestWithStudentClaims(http.MethodPost, "/student/assessments/10/start", nil, principal)
+	rr := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/student/assessments/{id:[0-9]+}/start", handler.StartAssessment).Methods(http.MethodPost)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+}
+
+func TestStudentHandler_StartAssessment_NoQuestionsDrawn(t *testing.T) {
+	mockService := new(MockStudentService)
+	handler := NewStudentHandler(mockService, zaptest.NewLogger(t))
+
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
+	mockService.On("StartAssessment", uint(123), uint(10), mock.AnythingOfType("models.AttemptClient")).Return(nil, nil, nil, nil, service.ErrNoQuestionsDrawn)
+
+	req := createRequestWithStudentClaims(http.MethodPost, "/student/assessments/10/start", nil, principal)
+	rr := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/student/assessments/{id:[0-9]+}/start", handler.StartAssessment).Methods(http.MethodPost)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+}
+
+func TestStudentHandler_ResumeAssessment(t *testing.T) {
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"resumed", nil, http.StatusOK},
+		{"no attempt in progress", service.ErrAttemptNotFound, http.StatusNotFound},
+		{"other device", service.ErrResumeNotAllowed, http.StatusForbidden},
+		{"service error", errors.New("db down"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockStudentService)
+			handler := NewStudentHandler(mockService, zaptest.NewLogger(t))
+
+			var details *map[string]interface{}
+			if tt.err == nil {
+				details = &map[string]interface{}{"attemptId": uint(99), "timeRemaining": int64(600)}
+			}
+			// Địa chỉ IP không kèm cổng, vì cổng đổi theo từng kết nối
+			client := models.AttemptClient{IPAddress: "192.0.2.1", UserAgent: "Firefox"}
+			mockService.On("ResumeAssessment", uint(123), uint(10), client).Return(details, tt.err)
+
+			req := createRequestWithStudentClaims(http.MethodPost, "/student/assessments/10/resume", nil, principal)
+			req.Header.Set("User-Agent", "Firefox")
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/student/assessments/{id:[0-9]+}/resume", handler.ResumeAssessment).Methods(http.MethodPost)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.want, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestStudentHandler_GetAssessmentResultsHistory(t *testing.T) {
 	mockService := new(MockStudentService)
 	logger := zaptest.NewLogger(t)
@@ -231,11 +379,11 @@ func TestStudentHandler_GetAssessmentResultsHistory(t *testing.T) {
 	userID := uint(123)
 	assessmentID := uint(10)
 	expectedResults := []map[string]interface{}{{"attemptId": float64(1), "score": float64(90.0)}}
-	claims := jwt.MapClaims{"userID": "123"}
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
 
 	mockService.On("GetAssessmentResultsHistory", userID, assessmentID).Return(expectedResults, nil)
 
-	req := createRequestWithStudentClaims(http.MethodGet, fmt.Sprintf("/student/assessments/%d/results", assessmentID), nil, claims)
+	req := createRequestWithStudentClaims(http.MethodGet, fmt.Sprintf("/student/assessments/%d/results", assessmentID), nil, principal)
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -260,12 +408,12 @@ func TestStudentHandler_GetAttemptDetails(t *testing.T) {
 
 	attemptID := uint(5)
 	userID := uint(123)
-	expectedDetails := map[string]interface{}{"attemptId": float64(attemptID), "status": "In Progress"}
-	claims := jwt.MapClaims{"userID": "123"}
+	expectedDetails := map[string]interface{}{"attemptId": float64(attemptID), "status": "in_progress"}
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
 
-	mockService.On("GetAttemptDetails", attemptID, userID).Return(&expectedDetails, nil)
+	mockService.On("GetAttemptDetails", attemptID, userID, mock.AnythingOfType("models.AttemptClient")).Return(&expectedDetails, nil)
 
-	req := createRequestWithStudentClaims(http.MethodGet, fmt.Sprintf("/student/attempts/%d", attemptID), nil, claims)
+	req := createRequestWithStudentClaims(http.MethodGet, fmt.Sprintf("/student/attempts/%d", attemptID), nil, principal)
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -293,11 +441,11 @@ func TestStudentHandler_SaveAnswer(t *testing.T) {
 	userID := uint(123)
 	answerReq := map[string]interface{}{"questionId": strconv.Itoa(int(questionID)), "answer": "true"}
 	body, _ := json.Marshal(answerReq)
-	claims := jwt.MapClaims{"userID": "123"}
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
 
-	mockService.On("SaveAnswer", attemptID, questionID, "true", userID).Return(nil)
+	mockService.On("SaveAnswer", attemptID, questionID, "true", userID, mock.AnythingOfType("models.AttemptClient")).Return(nil)
 
-	req := createRequestWithStudentClaims(http.MethodPost, fmt.Sprintf("/student/attempts/%d/answers", attemptID), body, claims)
+	req := createRequestWithStudentClaims(http.MethodPost, fmt.Sprintf("/student/attempts/%d/answers", attemptID), body, principal)
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -313,6 +461,108 @@ func TestStudentHandler_SaveAnswer(t *testing.T) {
 	assert.Equal(t, "SUCCESS", resp["status"])
 }
 
+func TestStudentHandler_SaveAnswer_ArrayAnswer(t *testing.T) {
+	mockService := new(MockStudentService)
+	logger := zaptest.NewLogger(t)
+	handler := NewStudentHandler(mockService, logger)
+
+	attemptID := uint(1)
+	questionID := uint(102)
+	userID := uint(123)
+	// Câu hỏi multiple-select gửi mảng các option ID, service nhận chuỗi JSON
+	answerReq := map[string]interface{}{"questionId": strconv.Itoa(int(questionID)), "answer": []string{"a", "c"}}
+	body, _ := json.Marshal(answerReq)
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
+
+	mockService.On("SaveAnswer", attemptID, questionID, `["a","c"]`, userID, mock.AnythingOfType("models.AttemptClient")).Return(nil)
+
+	req := createRequestWithStudentClaims(http.MethodPost, fmt.Sprintf("/student/attempts/%d/answers", attemptID), body, principal)
+	rr := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/student/attempts/{attemptId:[0-9]+}/answers", handler.SaveAnswer).Methods(http.MethodPost)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestStudentHandler_SaveAnswer_InvalidAnswer(t *testing.T) {
+	mockService := new(MockStudentService)
+	logger := zaptest.NewLogger(t)
+	handler := NewStudentHandler(mockService, logger)
+
+	attemptID := uint(1)
+	questionID := uint(103)
+	answerReq := map[string]interface{}{"questionId": strconv.Itoa(int(questionID)), "answer": "pi"}
+	body, _ := json.Marshal(answerReq)
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
+
+	serviceError := fmt.Errorf("%w: answer for numeric question must be a number", types.ErrInvalidAnswer)
+	mockService.On("SaveAnswer", attemptID, questionID, "pi", uint(123), mock.AnythingOfType("models.AttemptClient")).Return(serviceError)
+
+	req := createRequestWithStudentClaims(http.MethodPost, fmt.Sprintf("/student/attempts/%d/answers", attemptID), body, principal)
+	rr := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/student/attempts/{attemptId:[0-9]+}/answers", handler.SaveAnswer).Methods(http.MethodPost)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "must be a number")
+	mockService.AssertExpectations(t)
+}
+
+func TestStudentHandler_SaveAnswer_TimeUp(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"time up", service.ErrTimeUp},
+		{"paused", service.ErrAttemptPaused},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockStudentService)
+			handler := NewStudentHandler(mockService, zaptest.NewLogger(t))
+
+			body, _ := json.Marshal(map[string]interface{}{"questionId": "101", "answer": "true"})
+			principal := &middleware.Principal{UserID: 123, Role: "student"}
+			mockService.On("SaveAnswer", uint(1), uint(101), "true", uint(123), mock.AnythingOfType("models.AttemptClient")).Return(tt.err)
+
+			req := createRequestWithStudentClaims(http.MethodPost, "/student/attempts/1/answers", body, principal)
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/student/attempts/{attemptId:[0-9]+}/answers", handler.SaveAnswer).Methods(http.MethodPost)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusConflict, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.err.Error())
+		})
+	}
+}
+
+func TestStudentHandler_SaveAnswer_OtherDevice(t *testing.T) {
+	mockService := new(MockStudentService)
+	handler := NewStudentHandler(mockService, zaptest.NewLogger(t))
+
+	body, _ := json.Marshal(map[string]interface{}{"questionId": "101", "answer": "true"})
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
+	mockService.On("SaveAnswer", uint(1), uint(101), "true", uint(123), mock.AnythingOfType("models.AttemptClient")).Return(service.ErrResumeNotAllowed)
+
+	req := createRequestWithStudentClaims(http.MethodPost, "/student/attempts/1/answers", body, principal)
+	rr := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/student/attempts/{attemptId:[0-9]+}/answers", handler.SaveAnswer).Methods(http.MethodPost)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Contains(t, rr.Body.String(), service.ErrResumeNotAllowed.Error())
+}
+
 // Thêm test case lỗi cho SaveAnswer
 
 func TestStudentHandler_SubmitAssessment(t *testing.T) {
@@ -323,11 +573,11 @@ func TestStudentHandler_SubmitAssessment(t *testing.T) {
 	attemptID := uint(1)
 	userID := uint(123)
 	expectedResult := map[string]interface{}{"completed": true, "score": 80.0}
-	claims := jwt.MapClaims{"userID": "123"}
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
 
-	mockService.On("SubmitAssessment", attemptID, userID).Return(&expectedResult, nil)
+	mockService.On("SubmitAssessment", attemptID, userID, mock.AnythingOfType("models.AttemptClient")).Return(&expectedResult, nil)
 
-	req := createRequestWithStudentClaims(http.MethodPost, fmt.Sprintf("/student/attempts/%d/submit", attemptID), nil, claims)
+	req := createRequestWithStudentClaims(http.MethodPost, fmt.Sprintf("/student/attempts/%d/submit", attemptID), nil, principal)
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -358,13 +608,13 @@ func TestStudentHandler_SubmitMonitorEvent(t *testing.T) {
 		"details":   map[string]interface{}{"count": 1.0},
 	}
 	body, _ := json.Marshal(eventReq)
-	claims := jwt.MapClaims{"userID": "123"}
+	principal := &middleware.Principal{UserID: 123, Role: "student"}
 	expectedResponse := map[string]interface{}{"received": true, "severity": "CRITICAL"}
 
 	// Sử dụng mock.AnythingOfType cho imageData ([]byte) vì so sánh byte slice phức tạp
-	mockService.On("SubmitMonitorEvent", attemptID, "TAB_SWITCH", mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("[]uint8"), userID).Return(&expectedResponse, nil)
+	mockService.On("SubmitMonitorEvent", attemptID, "TAB_SWITCH", mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("[]uint8"), userID, mock.AnythingOfType("models.AttemptClient")).Return(&expectedResponse, nil)
 
-	req := createRequestWithStudentClaims(http.MethodPost, fmt.Sprintf("/student/attempts/%d/monitor", attemptID), body, claims)
+	req := createRequestWithStudentClaims(http.MethodPost, fmt.Sprintf("/student/attempts/%d/monitor", attemptID), body, principal)
 	rr := httptest.NewRecorder()
 
 	router := mux.NewRouter()
@@ -413,3 +663,64 @@ func TestStudentHandler_GetAllAttemptForUser(t *testing.T) {
 	assert.True(t, ok)
 	assert.Len(t, content, len(expectedAttempts))
 }
+
+func TestStudentHandler_RequestRegrade(t *testing.T) {
+	answerID := uint(11)
+	request := models.RegradeRequestDTO{AnswerID: &answerID, Reason: "My essay covers the second point"}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"Success", nil, http.StatusCreated},
+		{"NotFound", service.ErrAttemptNotFound, http.StatusNotFound},
+		{"NotGraded", service.ErrNotRegradable, http.StatusConflict},
+		{"AlreadyPending", service.ErrRegradePending, http.StatusConflict},
+		{"NoReason", service.ErrInvalidRegradeRequest, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockStudentService)
+			handler := NewStudentHandler(mockService, zaptest.NewLogger(t))
+
+			var regrade *models.RegradeRequest
+			if tt.err == nil {
+				regrade = &models.RegradeRequest{ID: 4, AttemptID: 1, Status: models.RegradePending}
+			}
+			mockService.On("RequestRegrade", uint(1), uint(123), request).Return(regrade, tt.err)
+
+			body, _ := json.Marshal(request)
+			req := createRequestWithStudentClaims(http.MethodPost, "/student/attempts/1/regrade-requests", body, &middleware.Principal{UserID: 123, Role: "student"})
+			rr := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/student/attempts/{attemptId:[0-9]+}/regrade-requests", handler.RequestRegrade).Methods(http.MethodPost)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStudentHandler_GetRegradeRequests(t *testing.T) {
+	mockService := new(MockStudentService)
+	handler := NewStudentHandler(mockService, zaptest.NewLogger(t))
+
+	mockService.On("GetRegradeRequests", uint(1), uint(123)).Return([]models.RegradeRequest{
+		{ID: 4, AttemptID: 1, Status: models.RegradeRejected, Response: "The rubric was applied correctly"},
+	}, nil)
+
+	req := createRequestWithStudentClaims(http.MethodGet, "/student/attempts/1/regrade-requests", nil, &middleware.Principal{UserID: 123, Role: "student"})
+	rr := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/student/attempts/{attemptId:[0-9]+}/regrade-requests", handler.GetRegradeRequests).Methods(http.MethodGet)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "The rubric was applied correctly")
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,222 @@
+package rest
+
+import (
+	"assessment_service/internal/live"
+	"assessment_service/internal/middleware"
+	models "assessment_service/internal/model"
+	"assessment_service/internal/questions/types"
+	"assessment_service/internal/student/service"
+	"assessment_service/internal/util"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// clockInterval is how often the live channel sends the attempt's clock
+const clockInterval = 10 * time.Second
+
+// LiveHandler serves the live channel of an attempt: a Server-Sent Events stream of the attempt's
+// clock and of what happens to it, and the route the student's client sends its messages to
+type LiveHandler struct {
+	studentService service.StudentService
+	hub            live.Hub
+	log            *zap.Logger
+	interval       time.Duration
+}
+
+func NewLiveHandler(studentService service.StudentService, hub live.Hub, log *zap.Logger) *LiveHandler {
+	return &LiveHandler{studentService: studentService, hub: hub, log: log, interval: clockInterval}
+}
+
+// Stream sends the attempt's clock when it opens and every interval after, along with the events
+// published for the attempt. It ends once the attempt is no longer in progress.
+func (h *LiveHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	principal, attemptID, ok := h.liveVars(w, r, "Stream")
+	if !ok {
+		return
+	}
+
+	client := attemptClient(r)
+	clock, err := h.studentService.AttemptClock(attemptID, principal.UserID, client)
+	if err != nil {
+		h.writeError(w, "Stream", err, "Failed to open live channel")
+		return
+	}
+
+	events, unsubscribe := h.hub.Subscribe(attemptID)
+	defer unsubscribe()
+
+	rc := live.OpenStream(w, h.log)
+	if !h.sendClock(w, rc, clock) {
+		return
+	}
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if !h.refreshClock(w, rc, attemptID, principal.UserID, client) {
+				return
+			}
+		case event, open := <-events:
+			if !open {
+				return
+			}
+			// A clock event without data asks for the clock again, after a proctor changed it
+			if event.Type == models.LiveEventTime && event.Data == nil {
+				if !h.refreshClock(w, rc, attemptID, principal.UserID, client) {
+					return
+				}
+				continue
+			}
+			if err := live.WriteEvent(w, rc, event); err != nil || event.Type == models.LiveEventSubmitted || event.Type == models.LiveEventTerminated {
+				return
+			}
+		}
+	}
+}
+
+// Send handles a message from the student's client: a heartbeat is recorded for the attempt's
+// proctors and answered with the attempt's clock, and an answer is saved and confirmed
+func (h *LiveHandler) Send(w http.ResponseWriter, r *http.Request) {
+	principal, attemptID, ok := h.liveVars(w, r, "Send")
+	if !ok {
+		return
+	}
+
+	var message models.LiveMessage
+	if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+		h.log.Error("[Send] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	switch message.Type {
+	case models.LiveMessageHeartbeat:
+		clock, err := h.studentService.Heartbeat(attemptID, principal.UserID, attemptClient(r))
+		if err != nil {
+			h.writeError(w, "Send", err, "Failed to record heartbeat")
+			return
+		}
+		util.ResponseInterface(w, models.NewLiveEvent(attemptID, models.LiveEventTime, clock), http.StatusOK)
+	case models.LiveMessageAnswer:
+		answer, ok := answerString(message.Answer)
+		if !ok {
+			util.ResponseMap(w, map[string]interface{}{
+				"status":  "BAD_REQUEST",
+				"message": "Invalid answer type, must be a string, boolean, number, array or object",
+			}, http.StatusBadRequest)
+			return
+		}
+		questionID, err := strconv.ParseUint(message.QuestionID, 10, 32)
+		if err != nil {
+			util.ResponseMap(w, map[string]interface{}{
+				"status":  "BAD_REQUEST",
+				"message": "Invalid question ID",
+			}, http.StatusBadRequest)
+			return
+		}
+
+		if err := h.studentService.SaveAnswer(attemptID, uint(questionID), answer, principal.UserID, attemptClient(r)); err != nil {
+			h.writeError(w, "Send", err, "Failed to save answer")
+			return
+		}
+
+		// The other live channels of the attempt hear of the answer from the service
+		event := models.NewLiveEvent(attemptID, models.LiveEventAnswerSaved, map[string]interface{}{
+			"questionId": uint(questionID),
+		})
+		util.ResponseInterface(w, event, http.StatusOK)
+	default:
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Unknown message type, must be heartbeat or answer",
+		}, http.StatusBadRequest)
+	}
+}
+
+// refreshClock sends the attempt's current clock, reporting whether the stream should go on
+func (h *LiveHandler) refreshClock(w http.ResponseWriter, rc *http.ResponseController, attemptID, userID uint, client models.AttemptClient) bool {
+	clock, err := h.studentService.AttemptClock(attemptID, userID, client)
+	if err != nil {
+		h.log.Error("[Stream] failed to get attempt clock", zap.Error(err))
+		return false
+	}
+	return h.sendClock(w, rc, clock)
+}
+
+// sendClock writes the clock, reporting whether the stream should go on
+func (h *LiveHandler) sendClock(w http.ResponseWriter, rc *http.ResponseController, clock *models.AttemptClock) bool {
+	if err := live.WriteEvent(w, rc, models.NewLiveEvent(clock.AttemptID, models.LiveEventTime, clock)); err != nil {
+		return false
+	}
+	return clock.Status == models.AttemptInProgress
+}
+
+// liveVars reads the student and the attempt ID of a live channel route, writing the error response
+// when one is missing or invalid
+func (h *LiveHandler) liveVars(w http.ResponseWriter, r *http.Request, fn string) (*middleware.Principal, uint, bool) {
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		h.log.Error("[" + fn + "] principal not found in context")
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "UNAUTHORIZED",
+			"message": "User ID not found in context",
+		}, http.StatusUnauthorized)
+		return nil, 0, false
+	}
+
+	attemptID, err := strconv.ParseUint(mux.Vars(r)["attemptId"], 10, 32)
+	if err != nil {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid attempt ID",
+		}, http.StatusBadRequest)
+		return nil, 0, false
+	}
+
+	return principal, uint(attemptID), true
+}
+
+// writeError maps live channel errors to HTTP responses
+func (h *LiveHandler) writeError(w http.ResponseWriter, fn string, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrAttemptNotFound):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "NOT_FOUND",
+			"message": err.Error(),
+		}, http.StatusNotFound)
+	case errors.Is(err, service.ErrResumeNotAllowed):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "FORBIDDEN",
+			"message": err.Error(),
+		}, http.StatusForbidden)
+	case errors.Is(err, types.ErrInvalidAnswer):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": err.Error(),
+		}, http.StatusBadRequest)
+	case errors.Is(err, service.ErrAttemptPaused), errors.Is(err, service.ErrTimeUp):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "CONFLICT",
+			"message": err.Error(),
+		}, http.StatusConflict)
+	default:
+		h.log.Error("["+fn+"] "+message, zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "ERROR",
+			"message": message,
+		}, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,247 @@
+package rest
+
+import (
+	"assessment_service/internal/live"
+	"assessment_service/internal/middleware"
+	models "assessment_service/internal/model"
+	"assessment_service/internal/student/service"
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// liveServer phục vụ kênh trực tiếp cho học sinh userID qua một server thật để có thể đọc luồng SSE
+func liveServer(t *testing.T, handler *LiveHandler, userID uint) *httptest.Server {
+	router := mux.NewRouter()
+	router.HandleFunc("/student/attempts/{attemptId:[0-9]+}/live", handler.Stream).Methods(http.MethodGet)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := middleware.WithPrincipal(r.Context(), &middleware.Principal{UserID: userID, Role: "student"})
+		router.ServeHTTP(w, r.WithContext(ctx))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// readEvent đọc một sự kiện SSE, trả về false khi luồng đã đóng
+func readEvent(t *testing.T, reader *bufio.Reader) (models.LiveEvent, bool) {
+	var event models.LiveEvent
+	var name string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return event, false
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+		case line == "":
+			assert.Equal(t, string(event.Type), name)
+			return event, true
+		}
+	}
+}
+
+func TestLiveHandler_Stream(t *testing.T) {
+	mockService := new(MockStudentService)
+	hub := live.NewMemoryHub()
+	handler := NewLiveHandler(mockService, hub, zaptest.NewLogger(t))
+	handler.interval = time.Hour // Không để đồng hồ tự gửi trong test
+	server := liveServer(t, handler, 1)
+
+	mockService.On("AttemptClock", uint(5), uint(1), mock.AnythingOfType("models.AttemptClient")).Return(&models.AttemptClock{AttemptID: 5, Status: models.AttemptInProgress, TimeRemaining: 600}, nil).Once()
+	mockService.On("AttemptClock", uint(5), uint(1), mock.AnythingOfType("models.AttemptClient")).Return(&models.AttemptClock{AttemptID: 5, Status: models.AttemptInProgress, TimeRemaining: 900, Paused: true}, nil).Once()
+
+	resp, err := http.Get(server.URL + "/student/attempts/5/live")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	reader := bufio.NewReader(resp.Body)
+
+	// Đồng hồ được gửi ngay khi mở kênh
+	event, ok := readEvent(t, reader)
+	require.True(t, ok)
+	assert.Equal(t, models.LiveEventTime, event.Type)
+	assert.Equal(t, float64(600), event.Data.(map[string]interface{})["timeRemaining"])
+
+	// Giám thị thay đổi đồng hồ: kênh gửi lại đồng hồ mới
+	require.NoError(t, hub.Publish(models.NewLiveEvent(5, models.LiveEventTime, nil)))
+	event, ok = readEvent(t, reader)
+	require.True(t, ok)
+	assert.Equal(t, models.LiveEventTime, event.Type)
+	assert.Equal(t, true, event.Data.(map[string]interface{})["paused"])
+
+	// Tin nhắn của giám thị được chuyển tiếp
+	require.NoError(t, hub.Publish(models.NewLiveEvent(5, models.LiveEventMessage, map[string]interface{}{"message": "Eyes on your screen"})))
+	event, ok = readEvent(t, reader)
+	require.True(t, ok)
+	assert.Equal(t, models.LiveEventMessage, event.Type)
+
+	// Bài bị nộp thì kênh đóng
+	require.NoError(t, hub.Publish(models.NewLiveEvent(5, models.LiveEventSubmitted, map[string]interface{}{"forced": true})))
+	event, ok = readEvent(t, reader)
+	require.True(t, ok)
+	assert.Equal(t, models.LiveEventSubmitted, event.Type)
+	_, ok = readEvent(t, reader)
+	assert.False(t, ok)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestLiveHandler_Stream_Terminated(t *testing.T) {
+	mockService := new(MockStudentService)
+	hub := live.NewMemoryHub()
+	handler := NewLiveHandler(mockService, hub, zaptest.NewLogger(t))
+	handler.interval = time.Hour
+	server := liveServer(t, handler, 1)
+
+	mockService.On("AttemptClock", uint(5), uint(1), mock.AnythingOfType("models.AttemptClient")).Return(&models.AttemptClock{AttemptID: 5, Status: models.AttemptInProgress, TimeRemaining: 600}, nil).Once()
+
+	resp, err := http.Get(server.URL + "/student/attempts/5/live")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+
+	_, ok := readEvent(t, reader)
+	require.True(t, ok)
+
+	// Sự kiện chỉ dành cho giám thị không tới kênh của học sinh
+	require.NoError(t, hub.Publish(models.NewLiveEvent(5, models.LiveEventHeartbeat, nil)))
+
+	// Giám thị đình chỉ bài làm thì kênh đóng
+	require.NoError(t, hub.Publish(models.NewLiveEvent(5, models.LiveEventTerminated, map[string]interface{}{"reason": "Phone in hand"})))
+	event, ok := readEvent(t, reader)
+	require.True(t, ok)
+	assert.Equal(t, models.LiveEventTerminated, event.Type)
+	_, ok = readEvent(t, reader)
+	assert.False(t, ok)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestLiveHandler_Stream_Ticks(t *testing.T) {
+	mockService := new(MockStudentService)
+	handler := NewLiveHandler(mockService, live.NewMemoryHub(), zaptest.NewLogger(t))
+	handler.interval = 10 * time.Millisecond
+	server := liveServer(t, handler, 1)
+
+	mockService.On("AttemptClock", uint(5), uint(1), mock.AnythingOfType("models.AttemptClient")).Return(&models.AttemptClock{AttemptID: 5, Status: models.AttemptInProgress, TimeRemaining: 1}, nil).Once()
+	mockService.On("AttemptClock", uint(5), uint(1), mock.AnythingOfType("models.AttemptClient")).Return(&models.AttemptClock{AttemptID: 5, Status: models.AttemptSubmitted}, nil).Once()
+
+	resp, err := http.Get(server.URL + "/student/attempts/5/live")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+
+	_, ok := readEvent(t, reader)
+	require.True(t, ok)
+
+	// Lần gửi sau thấy bài không còn làm nữa nên kênh đóng
+	event, ok := readEvent(t, reader)
+	require.True(t, ok)
+	assert.Equal(t, string(models.AttemptSubmitted), event.Data.(map[string]interface{})["status"])
+	_, ok = readEvent(t, reader)
+	assert.False(t, ok)
+}
+
+func TestLiveHandler_Stream_NotFound(t *testing.T) {
+	mockService := new(MockStudentService)
+	handler := NewLiveHandler(mockService, live.NewMemoryHub(), zaptest.NewLogger(t))
+	server := liveServer(t, handler, 2)
+
+	mockService.On("AttemptClock", uint(5), uint(2), mock.AnythingOfType("models.AttemptClient")).Return(nil, service.ErrAttemptNotFound)
+
+	resp, err := http.Get(server.URL + "/student/attempts/5/live")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestLiveHandler_Send(t *testing.T) {
+	principal := &middleware.Principal{UserID: 1, Role: "student"}
+
+	tests := []struct {
+		name       string
+		body       string
+		setup      func(m *MockStudentService)
+		wantStatus int
+		wantEvent  models.LiveEventType
+	}{
+		{
+			name: "Heartbeat",
+			body: `{"type":"heartbeat"}`,
+			setup: func(m *MockStudentService) {
+				m.On("Heartbeat", uint(5), uint(1), mock.AnythingOfType("models.AttemptClient")).Return(&models.AttemptClock{AttemptID: 5, Status: models.AttemptInProgress}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantEvent:  models.LiveEventTime,
+		},
+		{
+			name: "Answer",
+			body: `{"type":"answer","questionId":"3","answer":["a","b"]}`,
+			setup: func(m *MockStudentService) {
+				m.On("SaveAnswer", uint(5), uint(3), `["a","b"]`, uint(1), mock.AnythingOfType("models.AttemptClient")).Return(nil)
+			},
+			wantStatus: http.StatusOK,
+			wantEvent:  models.LiveEventAnswerSaved,
+		},
+		{
+			name: "AnswerAfterTimeUp",
+			body: `{"type":"answer","questionId":"3","answer":"a"}`,
+			setup: func(m *MockStudentService) {
+				m.On("SaveAnswer", uint(5), uint(3), "a", uint(1), mock.AnythingOfType("models.AttemptClient")).Return(service.ErrTimeUp)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "InvalidQuestionID",
+			body:       `{"type":"answer","questionId":"x","answer":"a"}`,
+			setup:      func(m *MockStudentService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "UnknownType",
+			body:       `{"type":"chat"}`,
+			setup:      func(m *MockStudentService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockStudentService)
+			tt.setup(mockService)
+			handler := NewLiveHandler(mockService, live.NewMemoryHub(), zaptest.NewLogger(t))
+
+			req := createRequestWithStudentClaims(http.MethodPost, "/student/attempts/5/live", []byte(tt.body), principal)
+			rr := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/student/attempts/{attemptId:[0-9]+}/live", handler.Send).Methods(http.MethodPost)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			mockService.AssertExpectations(t)
+			if tt.wantEvent == "" {
+				return
+			}
+
+			var event models.LiveEvent
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &event))
+			assert.Equal(t, tt.wantEvent, event.Type)
+			assert.Equal(t, uint(5), event.AttemptID)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	models "assessment_service/internal/model"
+	"math"
+	"time"
+)
+
+// accommodation is the combined effect of every accommodation a student has for one assessment.
+// When both the student and one of their groups have one, the most generous value of each field wins.
+type accommodation struct {
+	timeMultiplier float64
+	extraMinutes   int
+	extraAttempts  int
+	dueDate        *time.Time
+}
+
+func mergeAccommodations(records []models.AssessmentAccommodation, assessmentID uint) accommodation {
+	merged := accommodation{timeMultiplier: 1}
+	for _, record := range records {
+		if record.AssessmentID != assessmentID {
+			continue
+		}
+
+		merged.timeMultiplier = math.Max(merged.timeMultiplier, record.TimeMultiplier)
+		merged.extraMinutes = max(merged.extraMinutes, record.ExtraMinutes)
+		merged.extraAttempts = max(merged.extraAttempts, record.ExtraAttempts)
+		if record.DueDate != nil && (merged.dueDate == nil || record.DueDate.After(*merged.dueDate)) {
+			merged.dueDate = record.DueDate
+		}
+	}
+	return merged
+}
+
+// duration returns the accommodated time limit in whole minutes. A multiplied limit is rounded up,
+// ignoring floating point noise such as 60 * 1.1 = 66.00000000000001.
+func (a accommodation) duration(minutes int) int {
+	return int(math.Ceil(float64(minutes)*a.timeMultiplier-1e-9)) + a.extraMinutes
+}
+
+func (a accommodation) maxAttempts(attempts int) int {
+	return attempts + a.extraAttempts
+}
+
+// deadline returns the later of the assessment due date and the accommodated one. An assessment
+// without a due date stays without one.
+func (a accommodation) deadline(dueDate *time.Time) *time.Time {
+	if dueDate == nil || a.dueDate == nil || !a.dueDate.After(*dueDate) {
+		return dueDate
+	}
+	return a.dueDate
+}
+
+// endsAt is when an attempt started at startedAt runs out of time
+func (a accommodation) endsAt(startedAt time.Time, minutes int) time.Time {
+	return startedAt.Add(time.Duration(a.duration(minutes)) * time.Minute)
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	models "assessment_service/internal/model"
+	"errors"
+	"time"
+)
+
+var (
+	ErrAssessmentNotOpen = errors.New("assessment is not open yet")
+	ErrAssessmentClosed  = errors.New("assessment due date has passed")
+)
+
+// schedule is an assessment's availability window as it applies to one student, with their
+// accommodation already taken into account
+type schedule struct {
+	opensAt      *time.Time
+	closesAt     *time.Time // on-time work is accepted until here
+	acceptsUntil *time.Time // late work is accepted until here, nil when there is no limit
+	latePolicy   string
+	latePenalty  float64
+}
+
+func scheduleFor(assessment *models.Assessment, accommodation accommodation) schedule {
+	closesAt := accommodation.deadline(assessment.ClosesAt())
+
+	acceptsUntil := assessment.AcceptsUntil()
+	if acceptsUntil != nil && closesAt != nil && closesAt.After(*acceptsUntil) {
+		acceptsUntil = closesAt
+	}
+
+	return schedule{
+		opensAt:      assessment.AvailableFrom,
+		closesAt:     closesAt,
+		acceptsUntil: acceptsUntil,
+		latePolicy:   assessment.Settings.LatePolicy,
+		latePenalty:  assessment.Settings.LatePenalty,
+	}
+}
+
+// checkStart reports whether a new attempt may be started at now
+func (s schedule) checkStart(now time.Time) error {
+	if s.opensAt != nil && now.Before(*s.opensAt) {
+		return ErrAssessmentNotOpen
+	}
+	if s.acceptsUntil != nil && now.After(*s.acceptsUntil) {
+		return ErrAssessmentClosed
+	}
+	return nil
+}
+
+// endsAt is when an attempt runs out of time: the end of its time limit, or the moment the
+// assessment stops accepting work if that comes first
+func (s schedule) endsAt(startedAt time.Time, minutes int, accommodation accommodation) time.Time {
+	endsAt := accommodation.endsAt(startedAt, minutes)
+	if s.acceptsUntil != nil && s.acceptsUntil.Before(endsAt) {
+		return *s.acceptsUntil
+	}
+	return endsAt
+}
+
+// deadline is when the attempt runs out of time at now: endsAt moved on by the time proctors paused
+// the attempt for or added to it. The clock does not run while the attempt is paused.
+func (s schedule) deadline(attempt *models.Attempt, minutes int, accommodation accommodation, now time.Time) time.Time {
+	return s.endsAt(attempt.StartedAt, minutes, accommodation).Add(attempt.TimeAdjustment(now))
+}
+
+func (s schedule) isLate(submittedAt time.Time) bool {
+	return s.closesAt != nil && submittedAt.After(*s.closesAt)
+}
+
+// penaltyAt is the percentage taken off the score of work submitted at submittedAt
+func (s schedule) penaltyAt(submittedAt time.Time) float64 {
+	if s.latePolicy != models.LatePolicyPenalty || !s.isLate(submittedAt) {
+		return 0
+	}
+	return s.latePenalty
+}
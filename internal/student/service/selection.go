@@ -0,0 +1,89 @@
+package service
+
+import (
+	models "assessment_service/internal/model"
+	"assessment_service/internal/questions/types"
+	"math/rand"
+	"sort"
+)
+
+// drawQuestions picks the questions an attempt is given and the order it gets them in. Without
+// sections the attempt gets every question. Otherwise each section in turn draws from its pool, and
+// a question drawn by one section is not drawn again by a later one. Questions keep the assessment's
+// order within their section unless the assessment randomizes questions.
+//
+// A draw that asks for more questions than its pool still has takes what there is; missing counts
+// the questions that could not be drawn.
+func drawQuestions(questions []models.Question, sections []models.AssessmentSection, randomize bool, r *rand.Rand) (selection []models.AttemptQuestion, missing int) {
+	if len(sections) == 0 {
+		order := make([]int, len(questions))
+		for i := range order {
+			order[i] = i
+		}
+		if randomize {
+			r.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+		}
+		for _, i := range order {
+			selection = append(selection, models.AttemptQuestion{QuestionID: questions[i].ID, Position: len(selection)})
+		}
+		return selection, 0
+	}
+
+	used := make(map[uint]bool, len(questions))
+	for _, section := range sections {
+		sectionID := section.ID
+
+		var drawn []int
+		if len(section.Draws) == 0 {
+			for i := range questions {
+				if !used[questions[i].ID] && section.InPool(&questions[i]) {
+					drawn = append(drawn, i)
+					used[questions[i].ID] = true
+				}
+			}
+		}
+
+		for _, draw := range section.Draws {
+			var candidates []int
+			for i := range questions {
+				if !used[questions[i].ID] && section.InPool(&questions[i]) && draw.Matches(&questions[i]) {
+					candidates = append(candidates, i)
+				}
+			}
+			r.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+
+			count := draw.Count
+			if count > len(candidates) {
+				missing += count - len(candidates)
+				count = len(candidates)
+			}
+			for _, i := range candidates[:count] {
+				drawn = append(drawn, i)
+				used[questions[i].ID] = true
+			}
+		}
+
+		if randomize {
+			r.Shuffle(len(drawn), func(i, j int) { drawn[i], drawn[j] = drawn[j], drawn[i] })
+		} else {
+			sort.Ints(drawn)
+		}
+		for _, i := range drawn {
+			selection = append(selection, models.AttemptQuestion{QuestionID: questions[i].ID, SectionID: &sectionID, Position: len(selection)})
+		}
+	}
+
+	return selection, missing
+}
+
+// presentQuestions returns the questions an attempt was given as its student sees them, in the order
+// they were given and with their options in the attempt's order. Options are shuffled as they were
+// when the attempt started, whatever the assessment's settings say now.
+func presentQuestions(attempt *models.Attempt, questions []models.Question) []models.Question {
+	given := attempt.GivenQuestions(questions)
+	presented := make([]models.Question, len(given))
+	for i, question := range given {
+		presented[i] = types.Present(question, attempt.ShuffleOptions, attempt.OptionSeed(question.ID))
+	}
+	return presented
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	models "assessment_service/internal/model"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func selectionIDs(selection []models.AttemptQuestion) []uint {
+	ids := make([]uint, len(selection))
+	for i, question := range selection {
+		ids[i] = question.QuestionID
+	}
+	return ids
+}
+
+func TestDrawQuestions_NoSections(t *testing.T) {
+	questions := []models.Question{{ID: 1}, {ID: 2}, {ID: 3}}
+
+	selection, missing := drawQuestions(questions, nil, false, rand.New(rand.NewSource(1)))
+
+	assert.Zero(t, missing)
+	assert.Equal(t, []uint{1, 2, 3}, selectionIDs(selection))
+	for i, question := range selection {
+		assert.Equal(t, i, question.Position)
+		assert.Nil(t, question.SectionID)
+	}
+
+	// Xáo trộn vẫn giữ đủ câu hỏi
+	selection, _ = drawQuestions(questions, nil, true, rand.New(rand.NewSource(1)))
+	assert.ElementsMatch(t, []uint{1, 2, 3}, selectionIDs(selection))
+}
+
+func TestDrawQuestions_Sections(t *testing.T) {
+	questions := []models.Question{
+		{ID: 1, Topic: "Algebra", Difficulty: models.DifficultyEasy},
+		{ID: 2, Topic: "Algebra", Difficulty: models.DifficultyHard},
+		{ID: 3, Topic: "Algebra", Difficulty: models.DifficultyEasy},
+		{ID: 4, Topic: "Geometry", Tags: models.QuestionTags{"angles"}},
+		{ID: 5, Topic: "Geometry", Tags: models.QuestionTags{"angles"}},
+		{ID: 6, Topic: "Algebra", Difficulty: models.DifficultyEasy},
+	}
+	sections := []models.AssessmentSection{
+		{ID: 10, Topic: "algebra", Draws: models.SectionDraws{{Difficulty: models.DifficultyEasy, Count: 2}, {Difficulty: models.DifficultyHard, Count: 1}}},
+		{ID: 11, Tag: "angles"},
+	}
+
+	for seed := int64(1); seed <= 20; seed++ {
+		selection, missing := drawQuestions(questions, sections, false, rand.New(rand.NewSource(seed)))
+		assert.Zero(t, missing)
+		require.Len(t, selection, 5)
+
+		// Phần đầu rút 2 câu dễ và 1 câu khó theo thứ tự của bài kiểm tra, phần sau lấy cả nhóm
+		ids := selectionIDs(selection)
+		assert.Contains(t, ids[:3], uint(2))
+		assert.IsIncreasing(t, ids[:3])
+		assert.Equal(t, []uint{4, 5}, ids[3:])
+		for i, question := range selection {
+			assert.Equal(t, i, question.Position)
+			require.NotNil(t, question.SectionID)
+		}
+		assert.Equal(t, uint(10), *selection[0].SectionID)
+		assert.Equal(t, uint(11), *selection[4].SectionID)
+	}
+
+	// Cùng một seed cho cùng một lựa chọn
+	first, _ := drawQuestions(questions, sections, true, rand.New(rand.NewSource(7)))
+	again, _ := drawQuestions(questions, sections, true, rand.New(rand.NewSource(7)))
+	assert.Equal(t, first, again)
+}
+
+func TestDrawQuestions_NoRepeatsAcrossSections(t *testing.T) {
+	questions := []models.Question{
+		{ID: 1, Topic: "Algebra", Tags: models.QuestionTags{"linear"}},
+		{ID: 2, Topic: "Algebra"},
+	}
+	sections := []models.AssessmentSection{
+		{ID: 10, Tag: "linear"},
+		{ID: 11, Topic: "Algebra", Draws: models.SectionDraws{{Count: 2}}},
+	}
+
+	selection, missing := drawQuestions(questions, sections, false, rand.New(rand.NewSource(1)))
+
+	// Câu 1 đã được phần đầu rút nên phần sau thiếu một câu
+	assert.Equal(t, 1, missing)
+	assert.Equal(t, []uint{1, 2}, selectionIDs(selection))
+}
@@ -3,34 +3,89 @@ package service
 import (
 	"assessment_service/internal/assessments/repository"
 	repository2 "assessment_service/internal/attempts/repository"
+	"assessment_service/internal/live"
 	models "assessment_service/internal/model"
 	repository3 "assessment_service/internal/questions/repository"
+	"assessment_service/internal/questions/types"
 	repository4 "assessment_service/internal/users/repository"
 	"assessment_service/internal/util"
+	"database/sql"
 	"errors"
 	"fmt"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"math/rand"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// ErrAssessmentNotAssigned is returned when a student tries to start an assessment that was not
+// assigned to them or to any of their groups
+var (
+	ErrAssessmentNotAssigned = errors.New("assessment is not assigned to you")
+	ErrAssessmentNotActive   = errors.New("assessment is not active")
+	ErrAttemptNotFound       = errors.New("attempt not found")
+	// ErrAttemptInProgress is returned when a student starts an assessment while one of their attempts
+	// is still in progress. Clients carry on with an attempt of the same assessment through
+	// POST /student/assessments/{id}/resume.
+	ErrAttemptInProgress = errors.New("you are already taking an assessment")
+	// ErrResumeNotAllowed is returned when the assessment's resume policy does not allow working on
+	// the attempt from the student's current device
+	ErrResumeNotAllowed = errors.New("this attempt cannot be resumed from a different device")
+	// ErrAttemptPaused is returned when a student works on an attempt a proctor has paused
+	ErrAttemptPaused = errors.New("attempt is paused by a proctor")
+	// ErrTimeUp is returned when a student saves an answer after the attempt's time and grace period
+	// ran out
+	ErrTimeUp = errors.New("time is up for this attempt")
+	// ErrNotRegradable is returned when a regrade is requested for an attempt that is not graded yet
+	ErrNotRegradable = errors.New("only graded attempts can be regraded")
+	// ErrInvalidRegradeRequest is returned for a regrade request without a reason or for an answer
+	// of another attempt
+	ErrInvalidRegradeRequest = errors.New("invalid regrade request")
+	// ErrRegradePending is returned when the attempt already has a regrade request waiting for a grader
+	ErrRegradePending = errors.New("attempt already has a pending regrade request")
+	// ErrNoQuestionsDrawn is returned when an attempt would start without questions, such as when the
+	// assessment's sections find nothing in their pools
+	ErrNoQuestionsDrawn = errors.New("the assessment has no questions to give")
+)
+
 type StudentService interface {
 	GetAvailableAssessments(userID uint, params util.PaginationParams) ([]map[string]interface{}, int64, error)
-	StartAssessment(userID, assessmentID uint) (*models.Attempt, []models.Question, *models.AssessmentSettings, *models.Assessment, error)
+	// StartAssessment returns the assessment with the student's own time limit and closing time, and
+	// the new attempt's clock, whose EndsAt is the exact deadline
+	StartAssessment(userID, assessmentID uint, client models.AttemptClient) (*models.Attempt, []models.Question, *models.AttemptClock, *models.Assessment, error)
+	// ResumeAssessment returns the student's attempt of the assessment that is in progress, with the
+	// questions, saved answers and remaining time, when the resume policy allows client
+	ResumeAssessment(userID, assessmentID uint, client models.AttemptClient) (*map[string]interface{}, error)
 	GetAssessmentResultsHistory(userID, assessmentID uint) ([]map[string]interface{}, error)
-	GetAttemptDetails(attemptID, userID uint) (*map[string]interface{}, error)
-	SaveAnswer(attemptID, questionID uint, answer string, userID uint) error
-	SubmitAssessment(attemptID, userID uint) (*map[string]interface{}, error)
-	SubmitMonitorEvent(attemptID uint, eventType string, details map[string]interface{}, imageData []byte, userID uint) (*map[string]interface{}, error)
+	// GetAttemptDetails, AttemptClock, Heartbeat, SaveAnswer, SubmitAssessment and SubmitMonitorEvent
+	// hold an attempt in progress to the resume policy just as ResumeAssessment does, so client must be
+	// one the policy allows
+	GetAttemptDetails(attemptID, userID uint, client models.AttemptClient) (*map[string]interface{}, error)
+	// AttemptClock returns the time left on the student's attempt, for its live channel
+	AttemptClock(attemptID, userID uint, client models.AttemptClient) (*models.AttemptClock, error)
+	// Heartbeat records that the student's client reported in on the attempt's live channel and
+	// returns the attempt's clock
+	Heartbeat(attemptID, userID uint, client models.AttemptClient) (*models.AttemptClock, error)
+	SaveAnswer(attemptID, questionID uint, answer string, userID uint, client models.AttemptClient) error
+	SubmitAssessment(attemptID, userID uint, client models.AttemptClient) (*map[string]interface{}, error)
+	SubmitMonitorEvent(attemptID uint, eventType string, details map[string]interface{}, imageData []byte, userID uint, client models.AttemptClient) (*map[string]interface{}, error)
 	AutoSubmitAssessment() error
 	GetAllAttemptByUserID(userID uint, params util.PaginationParams) ([]models.Attempt, int64, error)
+	RequestRegrade(attemptID, userID uint, request models.RegradeRequestDTO) (*models.RegradeRequest, error)
+	GetRegradeRequests(attemptID, userID uint) ([]models.RegradeRequest, error)
 }
 
 type studentService struct {
-	assessmentRepo repository.AssessmentRepository
-	attemptRepo    repository2.AttemptRepository
-	questionRepo   repository3.QuestionRepository
-	userRepo       repository4.UserRepository
-	log            *zap.Logger
+	assessmentRepo    repository.AssessmentRepository
+	attemptRepo       repository2.AttemptRepository
+	questionRepo      repository3.QuestionRepository
+	userRepo          repository4.UserRepository
+	assignmentRepo    repository.AssignmentRepository
+	accommodationRepo repository.AccommodationRepository
+	hub               live.Publisher
+	log               *zap.Logger
 }
 
 func NewStudentService(
@@ -38,14 +93,20 @@ func NewStudentService(
 	attemptRepo repository2.AttemptRepository,
 	questionRepo repository3.QuestionRepository,
 	userRepo repository4.UserRepository,
+	assignmentRepo repository.AssignmentRepository,
+	accommodationRepo repository.AccommodationRepository,
+	hub live.Publisher,
 	log *zap.Logger,
 ) StudentService {
 	return &studentService{
-		assessmentRepo: assessmentRepo,
-		attemptRepo:    attemptRepo,
-		questionRepo:   questionRepo,
-		userRepo:       userRepo,
-		log:            log,
+		assessmentRepo:    assessmentRepo,
+		attemptRepo:       attemptRepo,
+		questionRepo:      questionRepo,
+		userRepo:          userRepo,
+		assignmentRepo:    assignmentRepo,
+		accommodationRepo: accommodationRepo,
+		hub:               hub,
+		log:               log,
 	}
 }
 
@@ -56,12 +117,77 @@ func (s *studentService) GetAvailableAssessments(userID uint, params util.Pagina
 		return nil, 0, errors.New("user not found")
 	}
 
-	return s.attemptRepo.FindAvailableAssessments(userID, params)
+	assessments, total, err := s.attemptRepo.FindAvailableAssessments(userID, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := s.applyAccommodations(userID, assessments); err != nil {
+		return nil, 0, err
+	}
+
+	return assessments, total, nil
+}
+
+// applyAccommodations rewrites the duration, due date and attempt limit of each available assessment
+// with the values accommodated for the user
+func (s *studentService) applyAccommodations(userID uint, assessments []map[string]interface{}) error {
+	ids := make([]uint, 0, len(assessments))
+	for _, assessment := range assessments {
+		if id, err := strconv.ParseUint(fmt.Sprint(assessment["id"]), 10, 32); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+
+	records, err := s.accommodationRepo.FindForUser(userID, ids)
+	if err != nil {
+		s.log.Error("[GetAvailableAssessments] failed to find accommodations", zap.Error(err))
+		return err
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	for _, assessment := range assessments {
+		id, err := strconv.ParseUint(fmt.Sprint(assessment["id"]), 10, 32)
+		if err != nil {
+			continue
+		}
+		accommodation := mergeAccommodations(records, uint(id))
+
+		if duration, ok := assessment["duration"].(int); ok {
+			assessment["duration"] = accommodation.duration(duration)
+		}
+
+		if dueDate, ok := assessment["due_date"].(sql.NullTime); ok && dueDate.Valid {
+			assessment["due_date"] = sql.NullTime{Time: *accommodation.deadline(&dueDate.Time), Valid: true}
+		}
+
+		if maxAttempts, ok := assessment["max_attempts"].(int); ok && maxAttempts > 0 {
+			maxAttempts = accommodation.maxAttempts(maxAttempts)
+			attemptCount, _ := assessment["attempt_count"].(int)
+			assessment["max_attempts"] = maxAttempts
+			assessment["can_attempt"] = attemptCount < maxAttempts
+		}
+	}
+
+	return nil
+}
+
+// accommodationFor merges the accommodations the user has for the assessment, directly or through
+// one of their groups
+func (s *studentService) accommodationFor(userID, assessmentID uint) (accommodation, error) {
+	records, err := s.accommodationRepo.FindForUser(userID, []uint{assessmentID})
+	if err != nil {
+		return accommodation{}, err
+	}
+	return mergeAccommodations(records, assessmentID), nil
 }
 
-func (s *studentService) StartAssessment(userID, assessmentID uint) (*models.Attempt, []models.Question, *models.AssessmentSettings, *models.Assessment, error) {
+func (s *studentService) StartAssessment(userID, assessmentID uint, client models.AttemptClient) (*models.Attempt, []models.Question, *models.AttemptClock, *models.Assessment, error) {
 	// Check if user exists
-	_, err := s.userRepo.FindByID(userID)
+	user, err := s.userRepo.FindByID(userID)
 	if err != nil {
 		return nil, nil, nil, nil, errors.New("user not found")
 	}
@@ -72,13 +198,29 @@ func (s *studentService) StartAssessment(userID, assessmentID uint) (*models.Att
 		return nil, nil, nil, nil, errors.New("assessment not found")
 	}
 
-	if assessment.Status != "active" {
-		return nil, nil, nil, nil, errors.New("assessment is not active")
+	if assessment.Status != models.AssessmentActive {
+		return nil, nil, nil, nil, ErrAssessmentNotActive
+	}
+
+	// Check if assessment is assigned to the user or one of their groups
+	isAssigned, err := s.assignmentRepo.IsAssigned(assessmentID, userID)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	if !isAssigned {
+		return nil, nil, nil, nil, ErrAssessmentNotAssigned
 	}
 
-	// Check if due date has passed
-	if assessment.DueDate != nil && assessment.DueDate.Before(time.Now()) {
-		return nil, nil, nil, nil, errors.New("assessment due date has passed")
+	accommodation, err := s.accommodationFor(userID, assessmentID)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	// Check if the assessment is open, late work included
+	schedule := scheduleFor(assessment, accommodation)
+	if err := schedule.checkStart(time.Now()); err != nil {
+		return nil, nil, nil, nil, err
 	}
 
 	// Check if user is taking assessment
@@ -88,17 +230,23 @@ func (s *studentService) StartAssessment(userID, assessmentID uint) (*models.Att
 	}
 
 	if isInAttempt {
-		return nil, nil, nil, nil, errors.New("you are already taking an assessment")
+		return nil, nil, nil, nil, ErrAttemptInProgress
 	}
 
-	// Check if user has remaining attempts
-	if assessment.Settings.AllowRetake {
+	// Check if user has remaining attempts. Extra attempts from an accommodation also apply to
+	// assessments that do not allow retakes.
+	if assessment.Settings.AllowRetake || accommodation.extraAttempts > 0 {
+		maxAttempts := 1
+		if assessment.Settings.AllowRetake {
+			maxAttempts = assessment.Settings.MaxAttempts
+		}
+
 		attemptsCount, err := s.attemptRepo.CountAttemptsByUserAndAssessment(userID, assessmentID)
 		if err != nil {
 			return nil, nil, nil, nil, err
 		}
 
-		if attemptsCount >= assessment.Settings.MaxAttempts {
+		if attemptsCount >= accommodation.maxAttempts(maxAttempts) {
 			return nil, nil, nil, nil, errors.New("maximum attempts reached")
 		}
 	} else {
@@ -113,43 +261,64 @@ func (s *studentService) StartAssessment(userID, assessmentID uint) (*models.Att
 		}
 	}
 
-	// Create new attempt
+	// Get questions
+	questions, err := s.questionRepo.FindByAssessmentID(assessmentID)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	// Create new attempt. Its seed orders its questions and options the same way every time they are
+	// shown, and it keeps whether options are shuffled so changing the setting leaves it alone.
 	attempt := &models.Attempt{
-		UserID:       userID,
-		AssessmentID: assessmentID,
-		StartedAt:    time.Now(),
-		Status:       "In Progress",
+		Seed:           rand.Int63(),
+		ShuffleOptions: assessment.Settings.ShuffleOptions,
+		UserID:         userID,
+		AssessmentID:   assessmentID,
+		StartedAt:      time.Now(),
+		Status:         models.AttemptInProgress,
+		IPAddress:      client.IPAddress,
+		UserAgent:      client.UserAgent,
 	}
 
-	err = s.attemptRepo.Create(attempt)
-	if err != nil {
-		return nil, nil, nil, nil, err
+	// Draw the attempt's questions from the assessment's sections, in the order the student gets
+	// them. They are kept with the attempt, which is scored on these questions only.
+	random := rand.New(rand.NewSource(attempt.Seed))
+	selection, missing := drawQuestions(questions, assessment.Sections, assessment.Settings.RandomizeQuestions, random)
+	if missing > 0 {
+		s.log.Warn("[StartAssessment] sections have fewer questions than they draw",
+			zap.Uint("assessmentId", assessmentID), zap.Int("missing", missing))
+	}
+	// An attempt without a selection is taken to predate sections and given every question
+	if len(selection) == 0 {
+		return nil, nil, nil, nil, ErrNoQuestionsDrawn
 	}
+	attempt.Selection = selection
 
-	// Get questions
-	questions, err := s.questionRepo.FindByAssessmentID(assessmentID)
+	err = s.attemptRepo.Create(attempt)
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
 
-	// If randomize is enabled, shuffle the questions
-	if assessment.Settings.RandomizeQuestions {
-		questions = util.ShuffleQuestions(questions)
-	}
+	live.Send(s.hub, models.NewAttemptEvent(attempt, models.LiveEventStarted, map[string]interface{}{
+		"userId": user.ID,
+		"name":   user.Name,
+		"email":  user.Email,
+		"total":  len(selection),
+	}), s.log)
 
 	// Remove correct answers for student view
-	studentQuestions := make([]models.Question, len(questions))
-	for i, q := range questions {
-		studentQuestions[i] = models.Question{
-			ID:      q.ID,
-			Type:    q.Type,
-			Text:    q.Text,
-			Options: q.Options,
-			Points:  q.Points,
-		}
+	studentQuestions := presentQuestions(attempt, questions)
+
+	// Duration is shown in whole minutes; the clock keeps the exact deadline
+	clock := attemptClock(attempt, assessment, accommodation, attempt.StartedAt)
+	assessment.Duration = int(clock.EndsAt.Sub(attempt.StartedAt).Minutes())
+	if assessment.AvailableUntil != nil {
+		assessment.AvailableUntil = schedule.closesAt
+	} else {
+		assessment.DueDate = schedule.closesAt
 	}
 
-	return attempt, studentQuestions, &assessment.Settings, assessment, nil
+	return attempt, studentQuestions, &clock, assessment, nil
 }
 
 func (s *studentService) GetAssessmentResultsHistory(userID, assessmentID uint) ([]map[string]interface{}, error) {
@@ -168,7 +337,7 @@ func (s *studentService) GetAssessmentResultsHistory(userID, assessmentID uint)
 	return s.attemptRepo.FindCompletedAttemptsByUserAndAssessment(userID, assessmentID)
 }
 
-func (s *studentService) GetAttemptDetails(attemptID, userID uint) (*map[string]interface{}, error) {
+func (s *studentService) GetAttemptDetails(attemptID, userID uint, client models.AttemptClient) (*map[string]interface{}, error) {
 	// Get attempt details
 	attempt, err := s.attemptRepo.FindByID(attemptID)
 	if err != nil {
@@ -186,18 +355,78 @@ func (s *studentService) GetAttemptDetails(attemptID, userID uint) (*map[string]
 		return nil, errors.New("assessment not found")
 	}
 
-	// Calculate time remaining
-	timeRemaining := int64(0)
-	if attempt.Status == "In Progress" {
-		endTime := attempt.StartedAt.Add(time.Duration(assessment.Duration) * time.Minute)
-		if time.Now().Before(endTime) {
-			timeRemaining = int64(endTime.Sub(time.Now()).Seconds())
-		}
+	if err := s.checkClient("GetAttemptDetails", attempt, assessment, client); err != nil {
+		return nil, err
+	}
+
+	result, err := s.attemptDetails(attempt, assessment)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (s *studentService) ResumeAssessment(userID, assessmentID uint, client models.AttemptClient) (*map[string]interface{}, error) {
+	attempt, err := s.attemptRepo.FindInProgressAttempt(userID, assessmentID)
+	if err != nil {
+		s.log.Error("[ResumeAssessment] failed to find attempt in progress", zap.Error(err))
+		return nil, err
+	}
+
+	if attempt == nil {
+		return nil, fmt.Errorf("%w: no attempt of this assessment is in progress", ErrAttemptNotFound)
 	}
 
-	// Calculate progress
-	totalQuestions := len(assessment.Questions)
-	answeredQuestions := len(attempt.Answers)
+	assessment, err := s.assessmentRepo.FindByID(assessmentID)
+	if err != nil {
+		return nil, errors.New("assessment not found")
+	}
+
+	if err := s.checkClient("ResumeAssessment", attempt, assessment, client); err != nil {
+		return nil, err
+	}
+
+	result, err := s.attemptDetails(attempt, assessment)
+	if err != nil {
+		return nil, err
+	}
+	result["timeLimit"] = assessment.Settings.TimeLimitEnforced
+	result["settings"] = assessment.Settings
+
+	return &result, nil
+}
+
+// checkClient refuses work on an attempt in progress from a device the assessment's resume policy
+// does not allow, so the policy binds the attempt to its device on every request and not only when
+// resuming
+func (s *studentService) checkClient(fn string, attempt *models.Attempt, assessment *models.Assessment, client models.AttemptClient) error {
+	if attempt.Status == models.AttemptInProgress && !assessment.Settings.AllowsResume(attempt, client) {
+		s.log.Warn("["+fn+"] access from another device refused",
+			zap.Uint("attemptId", attempt.ID), zap.String("ipAddress", client.IPAddress))
+		return ErrResumeNotAllowed
+	}
+	return nil
+}
+
+// attemptDetails describes an attempt to its student: its time, progress, questions and answers
+func (s *studentService) attemptDetails(attempt *models.Attempt, assessment *models.Assessment) (map[string]interface{}, error) {
+	accommodation, err := s.accommodationFor(attempt.UserID, attempt.AssessmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Calculate time remaining, which stands still while the attempt is paused
+	clock := attemptClock(attempt, assessment, accommodation, time.Now())
+
+	// Calculate progress on the questions the attempt was given
+	given := attempt.GivenQuestions(assessment.Questions)
+	questionIDs := make([]uint, len(given))
+	for i, question := range given {
+		questionIDs[i] = question.ID
+	}
+	totalQuestions := len(given)
+	answeredQuestions := attempt.AnsweredCount()
 	percentage := 0
 	if totalQuestions > 0 {
 		percentage = (answeredQuestions * 100) / totalQuestions
@@ -210,20 +439,94 @@ func (s *studentService) GetAttemptDetails(attemptID, userID uint) (*map[string]
 		"title":         assessment.Title,
 		"status":        attempt.Status,
 		"startedAt":     attempt.StartedAt,
-		"endsAt":        attempt.StartedAt.Add(time.Duration(assessment.Duration) * time.Minute),
-		"timeRemaining": timeRemaining,
+		"endsAt":        clock.EndsAt,
+		"timeRemaining": clock.TimeRemaining,
+		"paused":        clock.Paused,
+		"gracePeriod":   clock.GracePeriod,
 		"progress": map[string]interface{}{
 			"answered":   answeredQuestions,
 			"total":      totalQuestions,
 			"percentage": percentage,
 		},
-		"answers": attempt.Answers,
+		"questionIds": questionIDs,
+		"questions":   presentQuestions(attempt, assessment.Questions),
+		"answers":     attempt.Answers,
 	}
 
-	return &result, nil
+	return result, nil
+}
+
+func (s *studentService) AttemptClock(attemptID, userID uint, client models.AttemptClient) (*models.AttemptClock, error) {
+	_, clock, err := s.ownClock("AttemptClock", attemptID, userID, client)
+	return clock, err
+}
+
+func (s *studentService) Heartbeat(attemptID, userID uint, client models.AttemptClient) (*models.AttemptClock, error) {
+	attempt, clock, err := s.ownClock("Heartbeat", attemptID, userID, client)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only attempts in progress are watched by proctors
+	if attempt.Status != models.AttemptInProgress {
+		return clock, nil
+	}
+
+	now := time.Now()
+	if err := s.attemptRepo.SaveHeartbeat(attemptID, now); err != nil {
+		s.log.Error("[Heartbeat] failed to save heartbeat", zap.Error(err))
+		return nil, err
+	}
+
+	live.Send(s.hub, models.NewAttemptEvent(attempt, models.LiveEventHeartbeat, map[string]interface{}{
+		"lastHeartbeatAt": now,
+	}), s.log)
+
+	return clock, nil
+}
+
+// ownClock finds the student's attempt and counts the time left on it
+func (s *studentService) ownClock(fn string, attemptID, userID uint, client models.AttemptClient) (*models.Attempt, *models.AttemptClock, error) {
+	attempt, err := s.findOwnAttempt(attemptID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	assessment, err := s.assessmentRepo.FindByID(attempt.AssessmentID)
+	if err != nil {
+		return nil, nil, errors.New("assessment not found")
+	}
+
+	if err := s.checkClient(fn, attempt, assessment, client); err != nil {
+		return nil, nil, err
+	}
+
+	accommodation, err := s.accommodationFor(attempt.UserID, attempt.AssessmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clock := attemptClock(attempt, assessment, accommodation, time.Now())
+	return attempt, &clock, nil
 }
 
-func (s *studentService) SaveAnswer(attemptID, questionID uint, answer string, userID uint) error {
+// attemptClock counts the time left on the attempt at now. It stands still while the attempt is
+// paused and is zero once the attempt is no longer in progress.
+func attemptClock(attempt *models.Attempt, assessment *models.Assessment, accommodation accommodation, now time.Time) models.AttemptClock {
+	clock := models.AttemptClock{
+		AttemptID:   attempt.ID,
+		Status:      attempt.Status,
+		EndsAt:      scheduleFor(assessment, accommodation).deadline(attempt, assessment.Duration, accommodation, now),
+		Paused:      attempt.IsPaused(),
+		GracePeriod: assessment.Settings.GracePeriod,
+	}
+	if attempt.Status == models.AttemptInProgress && now.Before(clock.EndsAt) {
+		clock.TimeRemaining = int64(clock.EndsAt.Sub(now).Seconds())
+	}
+	return clock
+}
+
+func (s *studentService) SaveAnswer(attemptID, questionID uint, answer string, userID uint, client models.AttemptClient) error {
 	// Check if attempt exists
 	attempt, err := s.attemptRepo.FindByID(attemptID)
 	if err != nil {
@@ -236,7 +539,7 @@ func (s *studentService) SaveAnswer(attemptID, questionID uint, answer string, u
 	}
 
 	// Check if attempt is still in progress
-	if attempt.Status != "In Progress" {
+	if attempt.Status != models.AttemptInProgress {
 		return errors.New("attempt is not in progress")
 	}
 
@@ -250,60 +553,72 @@ func (s *studentService) SaveAnswer(attemptID, questionID uint, answer string, u
 		return errors.New("question does not belong to this assessment")
 	}
 
-	// Check if answer is valid for the question type
-	var isCorrect *bool
-	switch question.Type {
-	case "multiple-choice":
-		// Check if answer is one of the options
-		valid := false
-		for _, option := range question.Options {
-			if option.OptionID == answer {
-				valid = true
-				break
-			}
-		}
+	if !attempt.WasGiven(questionID) {
+		return errors.New("question was not given in this attempt")
+	}
 
-		if !valid {
-			return errors.New("invalid answer for multiple-choice question")
-		}
+	assessment, err := s.assessmentRepo.FindByID(attempt.AssessmentID)
+	if err != nil {
+		return errors.New("assessment not found")
+	}
 
-		correctVal := answer == question.CorrectAnswer
-		isCorrect = &correctVal
+	if err := s.checkClient("SaveAnswer", attempt, assessment, client); err != nil {
+		return err
+	}
 
-	case "true-false":
-		if answer != "true" && answer != "false" {
-			return errors.New("answer for true-false question must be 'true' or 'false'")
-		}
+	if err := s.checkTime(attempt, assessment, time.Now()); err != nil {
+		return err
+	}
 
-		correctVal := answer == question.CorrectAnswer
-		isCorrect = &correctVal
+	// Check if answer is valid for the question type
+	questionType, err := types.Lookup(question.Type)
+	if err != nil {
+		return err
+	}
 
-	case "essay":
-		// Essay answers are graded manually, so isCorrect is nil
-		isCorrect = nil
+	if err := questionType.ValidateAnswer(question, answer); err != nil {
+		return err
 	}
 
+	// Answers the type cannot grade, such as essays, are graded manually and keep isCorrect and
+	// awardedPoints nil
+	isCorrect, awardedPoints := types.Score(questionType, question, answer)
+
 	// Check if the answer already exists
 	existingAnswer, err := s.attemptRepo.FindAnswerByAttemptAndQuestion(attemptID, questionID)
 	if err == nil && existingAnswer != nil {
 		// Update existing answer
 		existingAnswer.Answer = answer
 		existingAnswer.IsCorrect = isCorrect
-		return s.attemptRepo.UpdateAnswer(existingAnswer)
-	}
+		existingAnswer.AwardedPoints = awardedPoints
+		if err := s.attemptRepo.UpdateAnswer(existingAnswer); err != nil {
+			return err
+		}
+	} else {
+		// Create new answer
+		answerObj := &models.Answer{
+			AttemptID:     attemptID,
+			QuestionID:    questionID,
+			Answer:        answer,
+			IsCorrect:     isCorrect,
+			AwardedPoints: awardedPoints,
+		}
 
-	// Create new answer
-	answerObj := &models.Answer{
-		AttemptID:  attemptID,
-		QuestionID: questionID,
-		Answer:     answer,
-		IsCorrect:  isCorrect,
+		if err := s.attemptRepo.SaveAnswer(answerObj); err != nil {
+			return err
+		}
+		attempt.Answers = append(attempt.Answers, *answerObj)
 	}
 
-	return s.attemptRepo.SaveAnswer(answerObj)
+	live.Send(s.hub, models.NewAttemptEvent(attempt, models.LiveEventAnswerSaved, map[string]interface{}{
+		"questionId": questionID,
+		"answered":   attempt.AnsweredCount(),
+	}), s.log)
+
+	return nil
 }
 
-func (s *studentService) SubmitAssessment(attemptID, userID uint) (*map[string]interface{}, error) {
+func (s *studentService) SubmitAssessment(attemptID, userID uint, client models.AttemptClient) (*map[string]interface{}, error) {
 	// Check if attempt exists
 	attempt, err := s.attemptRepo.FindByID(attemptID)
 	if err != nil {
@@ -316,7 +631,7 @@ func (s *studentService) SubmitAssessment(attemptID, userID uint) (*map[string]i
 	}
 
 	// Check if attempt is still in progress
-	if attempt.Status != "In Progress" {
+	if attempt.Status != models.AttemptInProgress {
 		return nil, errors.New("attempt is not in progress")
 	}
 
@@ -326,26 +641,63 @@ func (s *studentService) SubmitAssessment(attemptID, userID uint) (*map[string]i
 		return nil, errors.New("assessment not found")
 	}
 
-	// Get all questions for this assessment
+	if err := s.checkClient("SubmitAssessment", attempt, assessment, client); err != nil {
+		return nil, err
+	}
+
+	// Get the questions the attempt was given
 	questions, err := s.questionRepo.FindByAssessmentID(assessment.ID)
 	if err != nil {
 		return nil, err
 	}
+	questions = attempt.GivenQuestions(questions)
 
-	totalQuestions, correctAnswers, incorrectAnswers, unanswered, essayQuestions, score, status, now, duration, feedback := judgmentAssessment(questions, attempt.Answers, assessment, attempt)
+	accommodation, err := s.accommodationFor(attempt.UserID, attempt.AssessmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if attempt.IsPaused() {
+		return nil, ErrAttemptPaused
+	}
+
+	// With the time limit enforced, work handed in after the attempt ran out of time counts as
+	// handed in when it did
+	schedule := scheduleFor(assessment, accommodation)
+	workEndedAt := time.Now()
+	deadline := schedule.deadline(attempt, assessment.Duration, accommodation, workEndedAt)
+	if assessment.Settings.TimeLimitEnforced && workEndedAt.After(deadline) {
+		workEndedAt = deadline
+	}
+	latePenalty := schedule.penaltyAt(workEndedAt)
+
+	totalQuestions, correctAnswers, incorrectAnswers, unanswered, essayQuestions, score, passed, now, duration, feedback := judgmentAssessment(questions, attempt.Answers, assessment, attempt, latePenalty)
 
 	// Update attempt
+	if err := attempt.TransitionTo(models.AttemptSubmitted); err != nil {
+		return nil, err
+	}
+	if err := scoreSubmission(attempt, essayQuestions, passed); err != nil {
+		return nil, err
+	}
 	attempt.SubmittedAt = &now
 	attempt.EndedAt = &now
 	attempt.Score = &score
 	attempt.Duration = &duration
-	attempt.Status = status
+	attempt.IsLate = schedule.isLate(workEndedAt)
+	attempt.LatePenalty = latePenalty
 
-	err = s.attemptRepo.Update(attempt)
-	if err != nil {
+	// The score is saved with its grade events
+	if err := s.attemptRepo.SaveGrade(models.GradeChange{Attempt: attempt, Events: autoGradeEvents(attempt)}); err != nil {
+		s.log.Error("[SubmitAssessment] failed to save submission", zap.Error(err))
 		return nil, err
 	}
 
+	live.Send(s.hub, models.NewAttemptEvent(attempt, models.LiveEventSubmitted, map[string]interface{}{
+		"status": attempt.Status,
+		"forced": false,
+	}), s.log)
+
 	// Create response
 	result := map[string]interface{}{
 		"attemptId":    attempt.ID,
@@ -361,38 +713,37 @@ func (s *studentService) SubmitAssessment(attemptID, userID uint) (*map[string]i
 			"incorrectAnswers": incorrectAnswers,
 			"unanswered":       unanswered,
 			"essayQuestions":   essayQuestions,
-			"status":           status,
+			"status":           attempt.Status,
+			"passed":           attempt.Passed,
 			"feedback":         feedback,
+			"isLate":           attempt.IsLate,
+			"latePenalty":      latePenalty,
 		},
 	}
 
 	return &result, nil
 }
 
-func judgmentAssessment(questions []models.Question, answers []models.Answer, assessment *models.Assessment, attempt *models.Attempt) (int, int, int, int, int, float64, string, time.Time, int, string) {
+func judgmentAssessment(questions []models.Question, answers []models.Answer, assessment *models.Assessment, attempt *models.Attempt, latePenalty float64) (int, int, int, int, int, float64, bool, time.Time, int, string) {
 	// Calculate score
 	totalQuestions := len(questions)
 	correctAnswers := 0
 	incorrectAnswers := 0
 	unanswered := 0
 	essayQuestions := 0
-	totalPoints := 0.0
-	earnedPoints := 0.0
 
 	for _, q := range questions {
-		totalPoints += q.Points
-
 		isAnswered := false
 		for _, val := range answers {
 			if val.QuestionID == q.ID {
 				isAnswered = true
 
-				if q.Type == "essay" {
+				if val.IsCorrect == nil {
+					// Waiting for a teacher, like an essay
 					essayQuestions++
-				} else if val.IsCorrect != nil {
+				} else {
 					if *val.IsCorrect {
 						correctAnswers++
-						earnedPoints += q.Points
 					} else {
 						incorrectAnswers++
 					}
@@ -408,17 +759,14 @@ func judgmentAssessment(questions []models.Question, answers []models.Answer, as
 		}
 	}
 
-	// Calculate percentage score
-	var score float64
-	if totalPoints > 0 {
-		score = (earnedPoints / totalPoints) * 100
-	}
+	// Calculate percentage score from the points each answer was awarded
+	score := types.Percentage(questions, answers)
 
-	// Determine pass/fail status
-	status := "Failed"
-	if score >= assessment.PassingScore {
-		status = "Passed"
-	}
+	// Take the late penalty off before deciding pass/fail
+	score -= score * latePenalty / 100
+
+	// Determine pass/fail outcome
+	passed := score >= assessment.PassingScore
 
 	// Calculate duration
 	now := time.Now()
@@ -429,10 +777,43 @@ func judgmentAssessment(questions []models.Question, answers []models.Answer, as
 	if essayQuestions > 0 {
 		feedback += " Your essay will be graded manually."
 	}
-	return totalQuestions, correctAnswers, incorrectAnswers, unanswered, essayQuestions, score, status, now, duration, feedback
+	return totalQuestions, correctAnswers, incorrectAnswers, unanswered, essayQuestions, score, passed, now, duration, feedback
 }
 
-func (s *studentService) SubmitMonitorEvent(attemptID uint, eventType string, details map[string]interface{}, imageData []byte, userID uint) (*map[string]interface{}, error) {
+// scoreSubmission moves a submitted attempt on to graded, or to pending manual grading while essay
+// answers still need a teacher. The pass/fail outcome is only recorded once the attempt is graded.
+func scoreSubmission(attempt *models.Attempt, essayAnswers int, passed bool) error {
+	if essayAnswers > 0 {
+		return attempt.TransitionTo(models.AttemptPendingManualGrading)
+	}
+
+	attempt.Passed = &passed
+	return attempt.TransitionTo(models.AttemptGraded)
+}
+
+// checkTime reports whether the student may still work on the attempt at now. They may not while a
+// proctor has paused it or, with the time limit enforced, once its time and the grace period after
+// it have run out.
+func (s *studentService) checkTime(attempt *models.Attempt, assessment *models.Assessment, now time.Time) error {
+	if attempt.IsPaused() {
+		return ErrAttemptPaused
+	}
+
+	accommodation, err := s.accommodationFor(attempt.UserID, attempt.AssessmentID)
+	if err != nil {
+		return err
+	}
+
+	deadline := scheduleFor(assessment, accommodation).deadline(attempt, assessment.Duration, accommodation, now)
+	grace := time.Duration(assessment.Settings.GracePeriod) * time.Second
+	if assessment.Settings.TimeLimitEnforced && now.After(deadline.Add(grace)) {
+		return ErrTimeUp
+	}
+
+	return nil
+}
+
+func (s *studentService) SubmitMonitorEvent(attemptID uint, eventType string, details map[string]interface{}, imageData []byte, userID uint, client models.AttemptClient) (*map[string]interface{}, error) {
 	// Check if attempt exists and belongs to user
 	attempt, err := s.attemptRepo.FindByID(attemptID)
 	if err != nil {
@@ -444,10 +825,19 @@ func (s *studentService) SubmitMonitorEvent(attemptID uint, eventType string, de
 	}
 
 	// Check if attempt is still in progress
-	if attempt.Status != "In Progress" {
+	if attempt.Status != models.AttemptInProgress {
 		return nil, errors.New("attempt is not in progress")
 	}
 
+	assessment, err := s.assessmentRepo.FindByID(attempt.AssessmentID)
+	if err != nil {
+		return nil, errors.New("assessment not found")
+	}
+
+	if err := s.checkClient("SubmitMonitorEvent", attempt, assessment, client); err != nil {
+		return nil, err
+	}
+
 	// Determine severity based on event type
 	severity := "NONE"
 	message := "Event recorded."
@@ -496,6 +886,13 @@ func (s *studentService) SubmitMonitorEvent(attemptID uint, eventType string, de
 		"message":  message,
 	}
 
+	live.Send(s.hub, models.NewAttemptEvent(attempt, models.LiveEventMonitor, map[string]interface{}{
+		"type":     eventType,
+		"severity": severity,
+		"message":  message,
+		"activity": suspiciousActivity,
+	}), s.log)
+
 	return &result, nil
 }
 
@@ -518,6 +915,21 @@ func eventTypeToDetails(eventType string, details map[string]interface{}) string
 	}
 }
 
+// autoGradeEvents describes the scoring done when an attempt is submitted: the points of every
+// answer that was scored automatically and the attempt's score
+func autoGradeEvents(attempt *models.Attempt) []models.GradeEvent {
+	var events []models.GradeEvent
+	for _, answer := range attempt.Answers {
+		if event, ok := answer.PointsEvent(nil, models.GradeEventAuto, nil, ""); ok {
+			events = append(events, event)
+		}
+	}
+	if event, ok := attempt.ScoreEvent(nil, models.GradeEventAuto, nil, ""); ok {
+		events = append(events, event)
+	}
+	return events
+}
+
 func (s *studentService) AutoSubmitAssessment() error {
 	// Get all expired attempts
 	expiredAttempts, err := s.attemptRepo.ExpiredAttempt()
@@ -533,30 +945,55 @@ func (s *studentService) AutoSubmitAssessment() error {
 			return err
 		}
 
-		if (val.Status == "In Progress" || val.SubmittedAt == nil) && (time.Now().Sub(val.StartedAt).Minutes() > float64(assessment.Duration)) {
+		accommodation, err := s.accommodationFor(val.UserID, val.AssessmentID)
+		if err != nil {
+			s.log.Error("failed to find accommodations", zap.Error(err))
+			return err
+		}
+
+		// Paused attempts wait for the proctor, and answers still come in during the grace period
+		schedule := scheduleFor(assessment, accommodation)
+		endsAt := schedule.deadline(&val, assessment.Duration, accommodation, time.Now())
+		grace := time.Duration(assessment.Settings.GracePeriod) * time.Second
+
+		if val.Status == models.AttemptInProgress && !val.IsPaused() && time.Now().After(endsAt.Add(grace)) {
 			// auto submit attempt
-			// Get all questions for this assessment
+			// Get the questions the attempt was given
 			questions, err := s.questionRepo.FindByAssessmentID(val.AssessmentID)
 			if err != nil {
 				return err
 			}
+			questions = val.GivenQuestions(questions)
 
 			_, _, _,
-				_, _,
-				score, status, now, duration,
-				_ := judgmentAssessment(questions, val.Answers, assessment, &val)
+				_, essayQuestions,
+				score, passed, now, duration,
+				_ := judgmentAssessment(questions, val.Answers, assessment, &val, schedule.penaltyAt(endsAt))
 			// Update attempt
+			if err := val.TransitionTo(models.AttemptAutoSubmitted); err != nil {
+				return err
+			}
+			if err := scoreSubmission(&val, essayQuestions, passed); err != nil {
+				return err
+			}
 			val.SubmittedAt = &now
 			val.EndedAt = &now
 			val.Score = &score
 			val.Duration = &duration
-			val.Status = status
+			// The attempt's work ended when its time ran out, not when this job picked it up
+			val.IsLate = schedule.isLate(endsAt)
+			val.LatePenalty = schedule.penaltyAt(endsAt)
 
-			err = s.attemptRepo.Update(&val)
-			if err != nil {
+			if err := s.attemptRepo.SaveGrade(models.GradeChange{Attempt: &val, Events: autoGradeEvents(&val)}); err != nil {
 				s.log.Error("failed to update attempt status", zap.Error(err))
 				return err
 			}
+
+			// Tell the student their time ran out
+			live.Send(s.hub, models.NewAttemptEvent(&val, models.LiveEventSubmitted, map[string]interface{}{
+				"status": val.Status,
+				"forced": true,
+			}), s.log)
 		}
 	}
 	return nil
@@ -587,3 +1024,91 @@ func (s *studentService) GetAllAttemptByUserID(userID uint, params util.Paginati
 
 	return attempt, total, err
 }
+
+// RequestRegrade asks for a graded attempt, or one answer of it, to be looked at again. An attempt
+// has at most one request waiting for a grader at a time.
+func (s *studentService) RequestRegrade(attemptID, userID uint, request models.RegradeRequestDTO) (*models.RegradeRequest, error) {
+	attempt, err := s.findOwnAttempt(attemptID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if attempt.Status != models.AttemptGraded {
+		return nil, fmt.Errorf("%w: attempt is %s", ErrNotRegradable, attempt.Status)
+	}
+
+	if strings.TrimSpace(request.Reason) == "" {
+		return nil, fmt.Errorf("%w: a reason is required", ErrInvalidRegradeRequest)
+	}
+
+	if request.AnswerID != nil {
+		found := false
+		for _, answer := range attempt.Answers {
+			if answer.ID == *request.AnswerID {
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: answer %d is not part of this attempt", ErrInvalidRegradeRequest, *request.AnswerID)
+		}
+	}
+
+	existing, err := s.attemptRepo.FindRegradeRequestsByAttempt(attemptID)
+	if err != nil {
+		s.log.Error("[RequestRegrade] failed to find regrade requests", zap.Error(err))
+		return nil, err
+	}
+	for _, r := range existing {
+		if r.Status == models.RegradePending {
+			return nil, ErrRegradePending
+		}
+	}
+
+	regrade := &models.RegradeRequest{
+		AttemptID: attemptID,
+		UserID:    userID,
+		AnswerID:  request.AnswerID,
+		Reason:    request.Reason,
+		Status:    models.RegradePending,
+	}
+	if err := s.attemptRepo.CreateRegradeRequest(regrade); err != nil {
+		s.log.Error("[RequestRegrade] failed to create regrade request", zap.Error(err))
+		return nil, err
+	}
+
+	return regrade, nil
+}
+
+// GetRegradeRequests returns the student's regrade requests for an attempt, newest first
+func (s *studentService) GetRegradeRequests(attemptID, userID uint) ([]models.RegradeRequest, error) {
+	if _, err := s.findOwnAttempt(attemptID, userID); err != nil {
+		return nil, err
+	}
+
+	requests, err := s.attemptRepo.FindRegradeRequestsByAttempt(attemptID)
+	if err != nil {
+		s.log.Error("[GetRegradeRequests] failed to find regrade requests", zap.Error(err))
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+// findOwnAttempt finds an attempt of the student. Attempts of other students are reported as not
+// found.
+func (s *studentService) findOwnAttempt(attemptID, userID uint) (*models.Attempt, error) {
+	attempt, err := s.attemptRepo.FindByID(attemptID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttemptNotFound
+		}
+		s.log.Error("[findOwnAttempt] failed to find attempt", zap.Error(err))
+		return nil, err
+	}
+
+	if attempt == nil || attempt.UserID != userID {
+		return nil, ErrAttemptNotFound
+	}
+
+	return attempt, nil
+}
@@ -2,8 +2,12 @@ package service
 
 import (
 	// Không import mock repo nữa
+	repository_attempt "assessment_service/internal/attempts/repository"
 	models "assessment_service/internal/model"
+	repository_question "assessment_service/internal/questions/repository"
+	"assessment_service/internal/questions/types"
 	"assessment_service/internal/util"
+	"database/sql"
 	"errors"
 	"testing"
 	"time"
@@ -12,6 +16,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
 )
 
 // --- Mock AssessmentRepository ---
@@ -56,7 +61,6 @@ func (m *MockAssessmentRepository) GetResults(id uint, params util.PaginationPar
 	args := m.Called(id, params)
 	return args.Get(0).([]map[string]interface{}), args.Get(1).(int64), args.Error(2)
 }
-func (m *MockAssessmentRepository) Publish(id uint) error { args := m.Called(id); return args.Error(0) }
 func (m *MockAssessmentRepository) Duplicate(assessment *models.Assessment) error {
 	args := m.Called(assessment)
 	return args.Error(0)
@@ -66,6 +70,29 @@ func (m *MockAssessmentRepository) GetAssessmentHasAttemptByUser(params util.Pag
 	return args.Get(0).([]models.Assessment), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockAssessmentRepository) UpdateStatus(transition *models.AssessmentStatusTransition) (bool, error) {
+	args := m.Called(transition)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAssessmentRepository) ListStatusHistory(assessmentID uint) ([]models.AssessmentStatusTransition, error) {
+	args := m.Called(assessmentID)
+	transitions, _ := args.Get(0).([]models.AssessmentStatusTransition)
+	return transitions, args.Error(1)
+}
+
+func (m *MockAssessmentRepository) ActivateScheduled(now time.Time) (int64, error) {
+	args := m.Called(now)
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
+func (m *MockAssessmentRepository) CloseEnded(now time.Time) (int64, error) {
+	args := m.Called(now)
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
 // --- Mock AttemptRepository ---
 type MockAttemptRepository struct{ mock.Mock }
 
@@ -112,6 +139,116 @@ func (m *MockAttemptRepository) FindAnswersByAttemptID(attemptID uint) ([]models
 	return answers, args.Error(1)
 }
 
+func (m *MockAttemptRepository) SaveAnswerGrade(answer *models.Answer) error {
+	args := m.Called(answer)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindPendingManualAnswers(params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	args := m.Called(params)
+	results, _ := args.Get(0).([]map[string]interface{})
+	count, _ := args.Get(1).(int64)
+	return results, count, args.Error(2)
+}
+
+func (m *MockAttemptRepository) FindGraderIDs(assessmentID uint) ([]uint, error) {
+	args := m.Called(assessmentID)
+	ids, _ := args.Get(0).([]uint)
+	return ids, args.Error(1)
+}
+
+func (m *MockAttemptRepository) FindUnassignedManualAnswers(assessmentID uint) ([]models.Answer, error) {
+	args := m.Called(assessmentID)
+	answers, _ := args.Get(0).([]models.Answer)
+	return answers, args.Error(1)
+}
+
+func (m *MockAttemptRepository) CreateGradingAssignments(assignments []models.GradingAssignment) error {
+	args := m.Called(assignments)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindAssignmentsByAnswer(answerID uint) ([]models.GradingAssignment, error) {
+	args := m.Called(answerID)
+	assignments, _ := args.Get(0).([]models.GradingAssignment)
+	return assignments, args.Error(1)
+}
+
+func (m *MockAttemptRepository) UpdateGradingAssignment(assignment *models.GradingAssignment) error {
+	args := m.Called(assignment)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindAssignmentsByGrader(graderID uint, params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	args := m.Called(graderID, params)
+	results, _ := args.Get(0).([]map[string]interface{})
+	count, _ := args.Get(1).(int64)
+	return results, count, args.Error(2)
+}
+
+func (m *MockAttemptRepository) GetGradingProgress(assessmentID uint) (map[string]interface{}, error) {
+	args := m.Called(assessmentID)
+	progress, _ := args.Get(0).(map[string]interface{})
+	return progress, args.Error(1)
+}
+
+func (m *MockAttemptRepository) CreateGradeEvents(events []models.GradeEvent) error {
+	args := m.Called(events)
+	return args.Error(0)
+}
+func (m *MockAttemptRepository) SaveGrade(change models.GradeChange) error {
+	args := m.Called(change)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindGradeEventsByAttempt(attemptID uint) ([]models.GradeEvent, error) {
+	args := m.Called(attemptID)
+	events, _ := args.Get(0).([]models.GradeEvent)
+	return events, args.Error(1)
+}
+
+func (m *MockAttemptRepository) CreateRegradeRequest(request *models.RegradeRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindRegradeRequestByID(id uint) (*models.RegradeRequest, error) {
+	args := m.Called(id)
+	request, _ := args.Get(0).(*models.RegradeRequest)
+	return request, args.Error(1)
+}
+
+func (m *MockAttemptRepository) FindRegradeRequestsByAttempt(attemptID uint) ([]models.RegradeRequest, error) {
+	args := m.Called(attemptID)
+	requests, _ := args.Get(0).([]models.RegradeRequest)
+	return requests, args.Error(1)
+}
+
+func (m *MockAttemptRepository) FindRegradeRequests(params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	args := m.Called(params)
+	results, _ := args.Get(0).([]map[string]interface{})
+	count, _ := args.Get(1).(int64)
+	return results, count, args.Error(2)
+}
+
+func (m *MockAttemptRepository) UpdateRegradeRequest(request *models.RegradeRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindScoredAttempts(assessmentID uint) ([]models.Attempt, error) {
+	args := m.Called(assessmentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Attempt), args.Error(1)
+}
+
+func (m *MockAttemptRepository) SaveRescore(attempts []*models.Attempt, answers []*models.Answer, events []models.GradeEvent) error {
+	args := m.Called(attempts, answers, events)
+	return args.Error(0)
+}
+
 func (m *MockAttemptRepository) FindAnswerByAttemptAndQuestion(attemptID, questionID uint) (*models.Answer, error) {
 	args := m.Called(attemptID, questionID)
 	if args.Get(0) == nil {
@@ -233,6 +370,49 @@ func (m *MockAttemptRepository) IsUserInAttempt(userID uint) (bool, error) {
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockAttemptRepository) WithTx(tx *gorm.DB) repository_attempt.AttemptRepository {
+	return m
+}
+
+func (m *MockAttemptRepository) FindInProgressAttempt(userID, assessmentID uint) (*models.Attempt, error) {
+	args := m.Called(userID, assessmentID)
+	attempt, _ := args.Get(0).(*models.Attempt)
+	return attempt, args.Error(1)
+}
+
+func (m *MockAttemptRepository) SaveTimeEvent(attempt *models.Attempt, event *models.AttemptTimeEvent) error {
+	args := m.Called(attempt, event)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindTimeEventsByAttempt(attemptID uint) ([]models.AttemptTimeEvent, error) {
+	args := m.Called(attemptID)
+	events, _ := args.Get(0).([]models.AttemptTimeEvent)
+	return events, args.Error(1)
+}
+
+func (m *MockAttemptRepository) SaveHeartbeat(attemptID uint, at time.Time) error {
+	args := m.Called(attemptID, at)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepository) FindLiveAttempts(assessmentID uint) ([]models.Attempt, error) {
+	args := m.Called(assessmentID)
+	attempts, _ := args.Get(0).([]models.Attempt)
+	return attempts, args.Error(1)
+}
+
+func (m *MockAttemptRepository) FindSuspiciousActivitiesByAttempts(attemptIDs []uint) ([]models.SuspiciousActivity, error) {
+	args := m.Called(attemptIDs)
+	activities, _ := args.Get(0).([]models.SuspiciousActivity)
+	return activities, args.Error(1)
+}
+
+func (m *MockAttemptRepository) SaveProctorAction(attempt *models.Attempt) error {
+	args := m.Called(attempt)
+	return args.Error(0)
+}
+
 // Thêm các hàm mock còn thiếu nếu cần
 
 // --- Mock QuestionRepository ---
@@ -274,6 +454,14 @@ func (m *MockQuestionRepository) DeleteOption(id uint) error {
 	return args.Error(0)
 }
 
+func (m *MockQuestionRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return fn(nil)
+}
+
+func (m *MockQuestionRepository) WithTx(tx *gorm.DB) repository_question.QuestionRepository {
+	return m
+}
+
 // --- Mock UserRepository ---
 type MockUserRepository struct{ mock.Mock }
 
@@ -325,6 +513,86 @@ func (m *MockUserRepository) GetListUserByAssessment(params util.PaginationParam
 	return args.Get(0).([]models.User), args.Get(1).(int64), args.Error(2)
 }
 
+// --- Mock AssignmentRepository ---
+type MockAssignmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAssignmentRepository) Create(assignments []models.AssessmentAssignment) error {
+	args := m.Called(assignments)
+	return args.Error(0)
+}
+
+func (m *MockAssignmentRepository) ListByAssessment(assessmentID uint) ([]models.AssessmentAssignment, error) {
+	args := m.Called(assessmentID)
+	assignments, _ := args.Get(0).([]models.AssessmentAssignment)
+	return assignments, args.Error(1)
+}
+
+func (m *MockAssignmentRepository) Delete(assessmentID, assignmentID uint) (bool, error) {
+	args := m.Called(assessmentID, assignmentID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAssignmentRepository) IsAssigned(assessmentID, userID uint) (bool, error) {
+	args := m.Called(assessmentID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+// --- Mock AccommodationRepository ---
+type MockAccommodationRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccommodationRepository) Create(accommodation *models.AssessmentAccommodation) error {
+	args := m.Called(accommodation)
+	return args.Error(0)
+}
+
+func (m *MockAccommodationRepository) FindByID(assessmentID, accommodationID uint) (*models.AssessmentAccommodation, error) {
+	args := m.Called(assessmentID, accommodationID)
+	accommodation, _ := args.Get(0).(*models.AssessmentAccommodation)
+	return accommodation, args.Error(1)
+}
+
+func (m *MockAccommodationRepository) FindByTarget(assessmentID uint, groupID, userID *uint) (*models.AssessmentAccommodation, error) {
+	args := m.Called(assessmentID, groupID, userID)
+	accommodation, _ := args.Get(0).(*models.AssessmentAccommodation)
+	return accommodation, args.Error(1)
+}
+
+func (m *MockAccommodationRepository) ListByAssessment(assessmentID uint) ([]models.AssessmentAccommodation, error) {
+	args := m.Called(assessmentID)
+	accommodations, _ := args.Get(0).([]models.AssessmentAccommodation)
+	return accommodations, args.Error(1)
+}
+
+func (m *MockAccommodationRepository) Update(accommodation *models.AssessmentAccommodation) error {
+	args := m.Called(accommodation)
+	return args.Error(0)
+}
+
+func (m *MockAccommodationRepository) Delete(assessmentID, accommodationID uint) (bool, error) {
+	args := m.Called(assessmentID, accommodationID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAccommodationRepository) FindForUser(userID uint, assessmentIDs []uint) ([]models.AssessmentAccommodation, error) {
+	args := m.Called(userID, assessmentIDs)
+	accommodations, _ := args.Get(0).([]models.AssessmentAccommodation)
+	return accommodations, args.Error(1)
+}
+
+// --- Mock Publisher ---
+type MockPublisher struct {
+	mock.Mock
+}
+
+func (m *MockPublisher) Publish(event models.LiveEvent) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
 // --- Test Cases ---
 
 func TestStudentService_GetAvailableAssessments(t *testing.T) {
@@ -332,8 +600,9 @@ func TestStudentService_GetAvailableAssessments(t *testing.T) {
 	mockAttemptRepo := new(MockAttemptRepository)
 	mockQuestionRepo := new(MockQuestionRepository)
 	mockUserRepo := new(MockUserRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
 	logger := zaptest.NewLogger(t)
-	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, mockUserRepo, logger)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, mockUserRepo, nil, mockAccommodationRepo, nil, logger)
 
 	userID := uint(1)
 	params := util.PaginationParams{Page: 0, Limit: 10}
@@ -342,6 +611,7 @@ func TestStudentService_GetAvailableAssessments(t *testing.T) {
 
 	mockUserRepo.On("FindByID", userID).Return(&models.User{ID: userID}, nil)
 	mockAttemptRepo.On("FindAvailableAssessments", userID, params).Return(expectedAssessments, expectedTotal, nil)
+	mockAccommodationRepo.On("FindForUser", userID, []uint{1}).Return(nil, nil)
 
 	assessments, total, err := service.GetAvailableAssessments(userID, params)
 
@@ -350,13 +620,48 @@ func TestStudentService_GetAvailableAssessments(t *testing.T) {
 	assert.Equal(t, expectedTotal, total)
 	mockUserRepo.AssertExpectations(t)
 	mockAttemptRepo.AssertExpectations(t)
+	mockAccommodationRepo.AssertExpectations(t)
+}
+
+func TestStudentService_GetAvailableAssessments_Accommodated(t *testing.T) {
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockUserRepo := new(MockUserRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	logger := zaptest.NewLogger(t)
+	service := NewStudentService(nil, mockAttemptRepo, nil, mockUserRepo, nil, mockAccommodationRepo, nil, logger)
+
+	userID := uint(1)
+	params := util.PaginationParams{Page: 0, Limit: 10}
+	dueDate := time.Now().Add(-time.Hour)
+	extendedDueDate := time.Now().Add(24 * time.Hour)
+	available := []map[string]interface{}{
+		{"id": "1", "duration": 60, "due_date": sql.NullTime{Time: dueDate, Valid: true}, "max_attempts": 1, "attempt_count": 1, "can_attempt": false},
+		{"id": "2", "duration": 30, "due_date": sql.NullTime{}, "max_attempts": 1, "attempt_count": 0, "can_attempt": true},
+	}
+
+	mockUserRepo.On("FindByID", userID).Return(&models.User{ID: userID}, nil)
+	mockAttemptRepo.On("FindAvailableAssessments", userID, params).Return(available, int64(2), nil)
+	mockAccommodationRepo.On("FindForUser", userID, []uint{1, 2}).Return([]models.AssessmentAccommodation{
+		{AssessmentID: 1, TimeMultiplier: 1.5, ExtraAttempts: 1, DueDate: &extendedDueDate},
+	}, nil)
+
+	assessments, _, err := service.GetAvailableAssessments(userID, params)
+
+	require.NoError(t, err)
+	assert.Equal(t, 90, assessments[0]["duration"])
+	assert.Equal(t, sql.NullTime{Time: extendedDueDate, Valid: true}, assessments[0]["due_date"])
+	assert.Equal(t, 2, assessments[0]["max_attempts"])
+	assert.Equal(t, true, assessments[0]["can_attempt"])
+	// Bài không có accommodation giữ nguyên
+	assert.Equal(t, 30, assessments[1]["duration"])
+	assert.Equal(t, 1, assessments[1]["max_attempts"])
 }
 
 func TestStudentService_GetAvailableAssessments_UserNotFound(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockAttemptRepo := new(MockAttemptRepository)
 	logger := zaptest.NewLogger(t)
-	service := NewStudentService(nil, mockAttemptRepo, nil, mockUserRepo, logger)
+	service := NewStudentService(nil, mockAttemptRepo, nil, mockUserRepo, nil, nil, nil, logger)
 
 	userID := uint(99)
 	params := util.PaginationParams{}
@@ -379,33 +684,38 @@ func TestStudentService_StartAssessment_Success(t *testing.T) {
 	mockQuestionRepo := new(MockQuestionRepository)
 	mockUserRepo := new(MockUserRepository)
 	logger := zaptest.NewLogger(t)
-	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, mockUserRepo, logger)
+	mockAssignmentRepo := new(MockAssignmentRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, mockUserRepo, mockAssignmentRepo, mockAccommodationRepo, nil, logger)
 
 	userID := uint(1)
 	assessmentID := uint(10)
 	assessment := &models.Assessment{
 		ID:       assessmentID,
-		Status:   "active",
+		Status:   models.AssessmentActive,
 		Duration: 60,
 		Settings: models.AssessmentSettings{
 			AllowRetake:        false, // Chỉ được làm 1 lần
 			MaxAttempts:        1,
 			RandomizeQuestions: false, // Không random
+			ShuffleOptions:     true,
 		},
 	}
 	questions := []models.Question{
 		{ID: 101, AssessmentID: assessmentID, Text: "Q1", CorrectAnswer: "A"},
 		{ID: 102, AssessmentID: assessmentID, Text: "Q2", CorrectAnswer: "B"},
 	}
-	expectedAttempt := &models.Attempt{UserID: userID, AssessmentID: assessmentID, Status: "In Progress"} // Trạng thái sau khi tạo
+	expectedAttempt := &models.Attempt{UserID: userID, AssessmentID: assessmentID, Status: models.AttemptInProgress} // Trạng thái sau khi tạo
 
 	mockUserRepo.On("FindByID", userID).Return(&models.User{ID: userID}, nil)
 	mockAssessmentRepo.On("FindByID", assessmentID).Return(assessment, nil)
-	mockAttemptRepo.On("IsUserInAttempt", userID).Return(false, nil)                      // User không đang làm bài
-	mockAttemptRepo.On("HasCompletedAssessment", userID, assessmentID).Return(false, nil) // User chưa hoàn thành bài này
+	mockAssignmentRepo.On("IsAssigned", assessmentID, userID).Return(true, nil)            // Bài được giao cho user
+	mockAccommodationRepo.On("FindForUser", userID, []uint{assessmentID}).Return(nil, nil) // Không có accommodation
+	mockAttemptRepo.On("IsUserInAttempt", userID).Return(false, nil)                       // User không đang làm bài
+	mockAttemptRepo.On("HasCompletedAssessment", userID, assessmentID).Return(false, nil)  // User chưa hoàn thành bài này
 	// Expect Create attempt được gọi
 	mockAttemptRepo.On("Create", mock.MatchedBy(func(att *models.Attempt) bool {
-		return att.UserID == userID && att.AssessmentID == assessmentID && att.Status == "In Progress"
+		return att.UserID == userID && att.AssessmentID == assessmentID && att.Status == models.AttemptInProgress
 	})).Return(nil).Run(func(args mock.Arguments) {
 		// Gán ID giả lập sau khi tạo
 		att := args.Get(0).(*models.Attempt)
@@ -415,13 +725,18 @@ func TestStudentService_StartAssessment_Success(t *testing.T) {
 	})
 	mockQuestionRepo.On("FindByAssessmentID", assessmentID).Return(questions, nil)
 
-	attempt, studentQuestions, settings, returnedAssessment, err := service.StartAssessment(userID, assessmentID)
+	attempt, studentQuestions, clock, returnedAssessment, err := service.StartAssessment(userID, assessmentID, models.AttemptClient{IPAddress: "10.0.0.5", UserAgent: "Firefox"})
 
 	assert.NoError(t, err)
 	require.NotNil(t, attempt)
 	assert.Equal(t, expectedAttempt.ID, attempt.ID)
-	assert.Equal(t, "In Progress", attempt.Status)
-	assert.Equal(t, assessment.Settings, *settings)
+	assert.Equal(t, models.AttemptInProgress, attempt.Status)
+	assert.Equal(t, "10.0.0.5", attempt.IPAddress) // Thiết bị bắt đầu bài được lưu để tiếp tục sau này
+	assert.Equal(t, "Firefox", attempt.UserAgent)
+	assert.True(t, attempt.ShuffleOptions) // Cách xáo trộn lựa chọn được giữ cùng lượt làm bài
+	require.NotNil(t, clock)
+	assert.Equal(t, attempt.StartedAt.Add(60*time.Minute), clock.EndsAt)
+	assert.Equal(t, int64(3600), clock.TimeRemaining)
 	assert.Equal(t, assessment, returnedAssessment)
 	require.Len(t, studentQuestions, 2)
 	assert.Empty(t, studentQuestions[0].CorrectAnswer) // Đảm bảo đáp án đúng đã bị xóa
@@ -433,6 +748,267 @@ func TestStudentService_StartAssessment_Success(t *testing.T) {
 	mockQuestionRepo.AssertExpectations(t)
 }
 
+func TestStudentService_StartAssessment_Sections(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	mockUserRepo := new(MockUserRepository)
+	mockAssignmentRepo := new(MockAssignmentRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, mockUserRepo, mockAssignmentRepo, mockAccommodationRepo, nil, zaptest.NewLogger(t))
+
+	assessment := &models.Assessment{
+		ID: 10, Status: models.AssessmentActive, Duration: 60,
+		Settings: models.AssessmentSettings{MaxAttempts: 1},
+		Sections: []models.AssessmentSection{
+			{ID: 7, AssessmentID: 10, Topic: "Algebra", Draws: models.SectionDraws{{Count: 2}}},
+		},
+	}
+	questions := []models.Question{
+		{ID: 101, AssessmentID: 10, Topic: "Algebra", CorrectAnswer: "A"},
+		{ID: 102, AssessmentID: 10, Topic: "Geometry", CorrectAnswer: "B"},
+		{ID: 103, AssessmentID: 10, Topic: "Algebra", CorrectAnswer: "C"},
+		{ID: 104, AssessmentID: 10, Topic: "Algebra", CorrectAnswer: "D"},
+	}
+
+	mockUserRepo.On("FindByID", uint(1)).Return(&models.User{ID: 1}, nil)
+	mockAssessmentRepo.On("FindByID", uint(10)).Return(assessment, nil)
+	mockAssignmentRepo.On("IsAssigned", uint(10), uint(1)).Return(true, nil)
+	mockAccommodationRepo.On("FindForUser", uint(1), []uint{10}).Return(nil, nil)
+	mockAttemptRepo.On("IsUserInAttempt", uint(1)).Return(false, nil)
+	mockAttemptRepo.On("HasCompletedAssessment", uint(1), uint(10)).Return(false, nil)
+	mockQuestionRepo.On("FindByAssessmentID", uint(10)).Return(questions, nil)
+	// Lựa chọn câu hỏi được lưu cùng lượt làm bài
+	mockAttemptRepo.On("Create", mock.MatchedBy(func(att *models.Attempt) bool {
+		return len(att.Selection) == 2 && att.Selection[0].SectionID != nil && *att.Selection[0].SectionID == 7
+	})).Return(nil)
+
+	attempt, studentQuestions, _, _, err := service.StartAssessment(1, 10, models.AttemptClient{})
+
+	require.NoError(t, err)
+	require.Len(t, studentQuestions, 2)
+	for i, question := range studentQuestions {
+		assert.Equal(t, attempt.Selection[i].QuestionID, question.ID)
+		assert.NotEqual(t, uint(102), question.ID) // Câu 102 không thuộc nhóm câu hỏi của phần
+		assert.Empty(t, question.CorrectAnswer)
+	}
+	mockAttemptRepo.AssertExpectations(t)
+}
+
+func TestStudentService_StartAssessment_NoQuestionsDrawn(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	mockUserRepo := new(MockUserRepository)
+	mockAssignmentRepo := new(MockAssignmentRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, mockUserRepo, mockAssignmentRepo, mockAccommodationRepo, nil, zaptest.NewLogger(t))
+
+	// Phần chỉ lấy câu hỏi chủ đề Algebra nhưng bài không có câu nào như vậy
+	assessment := &models.Assessment{
+		ID: 10, Status: models.AssessmentActive, Duration: 60,
+		Settings: models.AssessmentSettings{MaxAttempts: 1},
+		Sections: []models.AssessmentSection{
+			{ID: 7, AssessmentID: 10, Topic: "Algebra", Draws: models.SectionDraws{{Count: 2}}},
+		},
+	}
+
+	mockUserRepo.On("FindByID", uint(1)).Return(&models.User{ID: 1}, nil)
+	mockAssessmentRepo.On("FindByID", uint(10)).Return(assessment, nil)
+	mockAssignmentRepo.On("IsAssigned", uint(10), uint(1)).Return(true, nil)
+	mockAccommodationRepo.On("FindForUser", uint(1), []uint{10}).Return(nil, nil)
+	mockAttemptRepo.On("IsUserInAttempt", uint(1)).Return(false, nil)
+	mockAttemptRepo.On("HasCompletedAssessment", uint(1), uint(10)).Return(false, nil)
+	mockQuestionRepo.On("FindByAssessmentID", uint(10)).Return([]models.Question{
+		{ID: 102, AssessmentID: 10, Topic: "Geometry", CorrectAnswer: "B"},
+	}, nil)
+
+	_, _, _, _, err := service.StartAssessment(1, 10, models.AttemptClient{})
+
+	assert.ErrorIs(t, err, ErrNoQuestionsDrawn)
+	mockAttemptRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestStudentService_StartAssessment_NotAssigned(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockUserRepo := new(MockUserRepository)
+	mockAssignmentRepo := new(MockAssignmentRepository)
+	logger := zaptest.NewLogger(t)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, nil, mockUserRepo, mockAssignmentRepo, nil, nil, logger)
+
+	userID := uint(1)
+	assessmentID := uint(10)
+
+	mockUserRepo.On("FindByID", userID).Return(&models.User{ID: userID}, nil)
+	mockAssessmentRepo.On("FindByID", assessmentID).Return(&models.Assessment{ID: assessmentID, Status: models.AssessmentActive}, nil)
+	mockAssignmentRepo.On("IsAssigned", assessmentID, userID).Return(false, nil)
+
+	attempt, _, _, _, err := service.StartAssessment(userID, assessmentID, models.AttemptClient{})
+
+	assert.Nil(t, attempt)
+	assert.EqualError(t, err, "assessment is not assigned to you")
+	mockAttemptRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestStudentService_StartAssessment_Schedule(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		from     *time.Time
+		until    *time.Time
+		settings models.AssessmentSettings
+		wantErr  error
+	}{
+		{name: "not open yet", from: &future, wantErr: ErrAssessmentNotOpen},
+		{name: "closed", until: &past, settings: models.AssessmentSettings{LatePolicy: models.LatePolicyReject}, wantErr: ErrAssessmentClosed},
+		{name: "late cutoff passed", until: &past, settings: models.AssessmentSettings{LatePolicy: models.LatePolicyCutoff, LateCutoff: &past}, wantErr: ErrAssessmentClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAssessmentRepo := new(MockAssessmentRepository)
+			mockUserRepo := new(MockUserRepository)
+			mockAssignmentRepo := new(MockAssignmentRepository)
+			mockAccommodationRepo := new(MockAccommodationRepository)
+			logger := zaptest.NewLogger(t)
+			service := NewStudentService(mockAssessmentRepo, new(MockAttemptRepository), nil, mockUserRepo, mockAssignmentRepo, mockAccommodationRepo, nil, logger)
+
+			assessment := &models.Assessment{ID: 10, Status: models.AssessmentActive, Duration: 60, AvailableFrom: tt.from, AvailableUntil: tt.until, Settings: tt.settings}
+
+			mockUserRepo.On("FindByID", uint(1)).Return(&models.User{ID: 1}, nil)
+			mockAssessmentRepo.On("FindByID", uint(10)).Return(assessment, nil)
+			mockAssignmentRepo.On("IsAssigned", uint(10), uint(1)).Return(true, nil)
+			mockAccommodationRepo.On("FindForUser", uint(1), []uint{10}).Return(nil, nil)
+
+			_, _, _, _, err := service.StartAssessment(1, 10, models.AttemptClient{})
+
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestStudentService_StartAssessment_ClosesBeforeTimeLimit(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	mockUserRepo := new(MockUserRepository)
+	mockAssignmentRepo := new(MockAssignmentRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	logger := zaptest.NewLogger(t)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, mockUserRepo, mockAssignmentRepo, mockAccommodationRepo, nil, logger)
+
+	// Bài đóng giữa chừng một phút, trước khi hết thời gian làm bài
+	until := time.Now().Add(30*time.Minute + 45*time.Second)
+	assessment := &models.Assessment{
+		ID:             10,
+		Status:         models.AssessmentActive,
+		Duration:       60,
+		AvailableUntil: &until,
+		Settings:       models.AssessmentSettings{LatePolicy: models.LatePolicyReject, MaxAttempts: 1},
+	}
+
+	mockUserRepo.On("FindByID", uint(1)).Return(&models.User{ID: 1}, nil)
+	mockAssessmentRepo.On("FindByID", uint(10)).Return(assessment, nil)
+	mockAssignmentRepo.On("IsAssigned", uint(10), uint(1)).Return(true, nil)
+	mockAccommodationRepo.On("FindForUser", uint(1), []uint{10}).Return(nil, nil)
+	mockAttemptRepo.On("IsUserInAttempt", uint(1)).Return(false, nil)
+	mockAttemptRepo.On("HasCompletedAssessment", uint(1), uint(10)).Return(false, nil)
+	mockAttemptRepo.On("Create", mock.AnythingOfType("*models.Attempt")).Return(nil)
+	mockQuestionRepo.On("FindByAssessmentID", uint(10)).Return([]models.Question{{ID: 101, AssessmentID: 10}}, nil)
+
+	_, _, clock, returnedAssessment, err := service.StartAssessment(1, 10, models.AttemptClient{})
+
+	require.NoError(t, err)
+	require.NotNil(t, clock)
+	// Hạn chót giữ nguyên đến từng giây, chỉ thời lượng hiển thị là làm tròn theo phút
+	assert.True(t, until.Equal(clock.EndsAt))
+	assert.Equal(t, 30, returnedAssessment.Duration)
+}
+
+func TestStudentService_StartAssessment_Accommodated(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	mockUserRepo := new(MockUserRepository)
+	mockAssignmentRepo := new(MockAssignmentRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	logger := zaptest.NewLogger(t)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, mockUserRepo, mockAssignmentRepo, mockAccommodationRepo, nil, logger)
+
+	userID := uint(1)
+	assessmentID := uint(10)
+	dueDate := time.Now().Add(-time.Hour) // Đã quá hạn chung
+	extendedDueDate := time.Now().Add(24 * time.Hour)
+	assessment := &models.Assessment{
+		ID:       assessmentID,
+		Status:   models.AssessmentActive,
+		Duration: 45,
+		DueDate:  &dueDate,
+		Settings: models.AssessmentSettings{AllowRetake: false, MaxAttempts: 1},
+	}
+
+	mockUserRepo.On("FindByID", userID).Return(&models.User{ID: userID}, nil)
+	mockAssessmentRepo.On("FindByID", assessmentID).Return(assessment, nil)
+	mockAssignmentRepo.On("IsAssigned", assessmentID, userID).Return(true, nil)
+	// Accommodation riêng của user và của lớp: lấy giá trị có lợi nhất
+	mockAccommodationRepo.On("FindForUser", userID, []uint{assessmentID}).Return([]models.AssessmentAccommodation{
+		{AssessmentID: assessmentID, TimeMultiplier: 1.5, ExtraAttempts: 1},
+		{AssessmentID: assessmentID, TimeMultiplier: 1, ExtraMinutes: 10, DueDate: &extendedDueDate},
+	}, nil)
+	mockAttemptRepo.On("IsUserInAttempt", userID).Return(false, nil)
+	// Đã làm 1 lần nhưng còn 1 lượt thêm
+	mockAttemptRepo.On("CountAttemptsByUserAndAssessment", userID, assessmentID).Return(1, nil)
+	mockAttemptRepo.On("Create", mock.AnythingOfType("*models.Attempt")).Return(nil)
+	mockQuestionRepo.On("FindByAssessmentID", assessmentID).Return([]models.Question{{ID: 101, AssessmentID: assessmentID}}, nil)
+
+	attempt, _, clock, returnedAssessment, err := service.StartAssessment(userID, assessmentID, models.AttemptClient{})
+
+	require.NoError(t, err)
+	require.NotNil(t, attempt)
+	assert.Equal(t, 78, returnedAssessment.Duration) // ceil(45 * 1.5) + 10
+	assert.Equal(t, attempt.StartedAt.Add(78*time.Minute), clock.EndsAt)
+	assert.Equal(t, &extendedDueDate, returnedAssessment.DueDate)
+	mockAttemptRepo.AssertNotCalled(t, "HasCompletedAssessment", mock.Anything, mock.Anything)
+	mockAttemptRepo.AssertExpectations(t)
+}
+
+func TestStudentService_StartAssessment_ExtraAttemptsUsed(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockUserRepo := new(MockUserRepository)
+	mockAssignmentRepo := new(MockAssignmentRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	logger := zaptest.NewLogger(t)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, nil, mockUserRepo, mockAssignmentRepo, mockAccommodationRepo, nil, logger)
+
+	userID := uint(1)
+	assessmentID := uint(10)
+	assessment := &models.Assessment{
+		ID:       assessmentID,
+		Status:   models.AssessmentActive,
+		Duration: 30,
+		Settings: models.AssessmentSettings{AllowRetake: true, MaxAttempts: 2},
+	}
+
+	mockUserRepo.On("FindByID", userID).Return(&models.User{ID: userID}, nil)
+	mockAssessmentRepo.On("FindByID", assessmentID).Return(assessment, nil)
+	mockAssignmentRepo.On("IsAssigned", assessmentID, userID).Return(true, nil)
+	mockAccommodationRepo.On("FindForUser", userID, []uint{assessmentID}).Return([]models.AssessmentAccommodation{
+		{AssessmentID: assessmentID, TimeMultiplier: 1, ExtraAttempts: 1},
+	}, nil)
+	mockAttemptRepo.On("IsUserInAttempt", userID).Return(false, nil)
+	mockAttemptRepo.On("CountAttemptsByUserAndAssessment", userID, assessmentID).Return(3, nil)
+
+	attempt, _, _, _, err := service.StartAssessment(userID, assessmentID, models.AttemptClient{})
+
+	assert.Nil(t, attempt)
+	assert.EqualError(t, err, "maximum attempts reached")
+	mockAttemptRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
 // Thêm các test case lỗi cho StartAssessment:
 // - Assessment không tìm thấy
 // - Assessment không active
@@ -448,7 +1024,7 @@ func TestStudentService_GetAssessmentResultsHistory(t *testing.T) {
 	mockAttemptRepo := new(MockAttemptRepository)
 	mockUserRepo := new(MockUserRepository)
 	logger := zaptest.NewLogger(t)
-	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, nil, mockUserRepo, logger)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, nil, mockUserRepo, nil, nil, nil, logger)
 
 	userID := uint(1)
 	assessmentID := uint(10)
@@ -473,24 +1049,26 @@ func TestStudentService_GetAttemptDetails(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockAttemptRepo := new(MockAttemptRepository)
 	logger := zaptest.NewLogger(t)
-	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, nil, nil, logger) // Không cần UserRepo
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, nil, nil, nil, mockAccommodationRepo, nil, logger) // Không cần UserRepo
 
 	attemptID := uint(5)
 	userID := uint(1)
 	assessmentID := uint(10)
 	startTime := time.Now().Add(-30 * time.Minute)
-	attempt := &models.Attempt{ID: attemptID, UserID: userID, AssessmentID: assessmentID, StartedAt: startTime, Status: "In Progress", Answers: []models.Answer{{QuestionID: 101}}}
+	attempt := &models.Attempt{ID: attemptID, UserID: userID, AssessmentID: assessmentID, StartedAt: startTime, Status: models.AttemptInProgress, Answers: []models.Answer{{QuestionID: 101}}}
 	assessment := &models.Assessment{ID: assessmentID, Duration: 60, Questions: []models.Question{{ID: 101}, {ID: 102}}} // 2 câu hỏi
 
 	mockAttemptRepo.On("FindByID", attemptID).Return(attempt, nil)
 	mockAssessmentRepo.On("FindByID", assessmentID).Return(assessment, nil)
+	mockAccommodationRepo.On("FindForUser", userID, []uint{assessmentID}).Return(nil, nil)
 
-	details, err := service.GetAttemptDetails(attemptID, userID)
+	details, err := service.GetAttemptDetails(attemptID, userID, models.AttemptClient{})
 
 	assert.NoError(t, err)
 	require.NotNil(t, details)
 	assert.Equal(t, (attemptID), (*details)["attemptId"]) // JSON number
-	assert.Equal(t, "In Progress", (*details)["status"])
+	assert.Equal(t, models.AttemptInProgress, (*details)["status"])
 	progress, ok := (*details)["progress"].(map[string]interface{})
 	assert.True(t, ok)
 	assert.Equal(t, int(1), progress["answered"])    // 1 câu đã trả lời
@@ -501,24 +1079,315 @@ func TestStudentService_GetAttemptDetails(t *testing.T) {
 	mockAssessmentRepo.AssertExpectations(t)
 }
 
+func TestStudentService_GetAttemptDetails_StableOrder(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, nil, nil, nil, mockAccommodationRepo, nil, zaptest.NewLogger(t))
+
+	choiceOptions := []models.QuestionOption{{ID: 1, OptionID: "a"}, {ID: 2, OptionID: "b"}, {ID: 3, OptionID: "c"}, {ID: 4, OptionID: "d"}}
+	assessment := &models.Assessment{
+		ID: 10, Duration: 60,
+		Settings: models.AssessmentSettings{RandomizeQuestions: true, ShuffleOptions: true},
+		Questions: []models.Question{
+			{ID: 101, Type: "multiple-choice", CorrectAnswer: "a", Options: choiceOptions},
+			{ID: 102, Type: "multiple-choice", CorrectAnswer: "b", Options: choiceOptions},
+		},
+	}
+	// Lượt làm bài đã được giao câu 102 trước câu 101
+	attempt := &models.Attempt{ID: 5, UserID: 1, AssessmentID: 10, StartedAt: time.Now(), Status: models.AttemptInProgress, Seed: 12345, ShuffleOptions: true,
+		Selection: []models.AttemptQuestion{{QuestionID: 102, Position: 0}, {QuestionID: 101, Position: 1}}}
+
+	mockAttemptRepo.On("FindByID", uint(5)).Return(attempt, nil)
+	mockAssessmentRepo.On("FindByID", uint(10)).Return(assessment, nil)
+	mockAccommodationRepo.On("FindForUser", uint(1), []uint{10}).Return(nil, nil)
+
+	first, err := service.GetAttemptDetails(5, 1, models.AttemptClient{})
+	require.NoError(t, err)
+	// Tắt xáo trộn giữa chừng không đổi thứ tự của lượt làm bài đã bắt đầu
+	assessment.Settings.ShuffleOptions = false
+	again, err := service.GetAttemptDetails(5, 1, models.AttemptClient{})
+	require.NoError(t, err)
+
+	// Tải lại trang cho cùng thứ tự câu hỏi và lựa chọn
+	questions := (*first)["questions"].([]models.Question)
+	require.Len(t, questions, 2)
+	assert.Equal(t, uint(102), questions[0].ID)
+	assert.Equal(t, uint(101), questions[1].ID)
+	assert.Equal(t, questions, (*again)["questions"])
+	for _, question := range questions {
+		assert.Empty(t, question.CorrectAnswer)
+		assert.ElementsMatch(t, choiceOptions, question.Options)
+	}
+
+	// Người chấm dựng lại được thứ tự từ seed của lượt làm bài
+	assert.Equal(t, types.Present(assessment.Questions[1], true, attempt.OptionSeed(102)), questions[0])
+}
+
+func TestStudentService_ResumeAssessment(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, nil, nil, nil, mockAccommodationRepo, nil, zaptest.NewLogger(t))
+
+	laptop := models.AttemptClient{IPAddress: "10.0.0.5", UserAgent: "Firefox"}
+	attempt := &models.Attempt{ID: 5, UserID: 1, AssessmentID: 10, StartedAt: time.Now().Add(-15 * time.Minute), Status: models.AttemptInProgress,
+		IPAddress: laptop.IPAddress, UserAgent: laptop.UserAgent, Answers: []models.Answer{{QuestionID: 101, Answer: "true"}}}
+	assessment := &models.Assessment{ID: 10, Title: "Quiz", Duration: 60,
+		Settings:  models.AssessmentSettings{TimeLimitEnforced: true, ResumePolicy: models.ResumeSameDevice},
+		Questions: []models.Question{{ID: 101, Type: "true-false", CorrectAnswer: "true"}, {ID: 102, Type: "true-false", CorrectAnswer: "false"}}}
+
+	mockAttemptRepo.On("FindInProgressAttempt", uint(1), uint(10)).Return(attempt, nil)
+	mockAssessmentRepo.On("FindByID", uint(10)).Return(assessment, nil)
+	mockAccommodationRepo.On("FindForUser", uint(1), []uint{10}).Return(nil, nil)
+
+	details, err := service.ResumeAssessment(1, 10, laptop)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(5), (*details)["attemptId"])
+	assert.Equal(t, true, (*details)["timeLimit"])
+	assert.Equal(t, attempt.Answers, (*details)["answers"])
+	assert.InDelta(t, 45*60, (*details)["timeRemaining"].(int64), 5) // Còn khoảng 45 phút
+	questions := (*details)["questions"].([]models.Question)
+	require.Len(t, questions, 2)
+	assert.Empty(t, questions[0].CorrectAnswer)
+
+	// Chính sách same_device không cho tiếp tục từ trình duyệt khác
+	_, err = service.ResumeAssessment(1, 10, models.AttemptClient{IPAddress: "10.0.0.5", UserAgent: "Chrome"})
+	assert.ErrorIs(t, err, ErrResumeNotAllowed)
+
+	// Chính sách same_ip chỉ so sánh địa chỉ IP
+	assessment.Settings.ResumePolicy = models.ResumeSameIP
+	_, err = service.ResumeAssessment(1, 10, models.AttemptClient{IPAddress: "10.0.0.5", UserAgent: "Chrome"})
+	assert.NoError(t, err)
+	_, err = service.ResumeAssessment(1, 10, models.AttemptClient{IPAddress: "10.0.0.9", UserAgent: "Firefox"})
+	assert.ErrorIs(t, err, ErrResumeNotAllowed)
+
+	// Mặc định được tiếp tục từ bất kỳ đâu
+	assessment.Settings.ResumePolicy = ""
+	_, err = service.ResumeAssessment(1, 10, models.AttemptClient{IPAddress: "10.0.0.9", UserAgent: "Safari"})
+	assert.NoError(t, err)
+}
+
+func TestStudentService_InProgressAttempt_OtherDevice(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, nil, nil, mockAccommodationRepo, nil, zaptest.NewLogger(t))
+
+	laptop := models.AttemptClient{IPAddress: "10.0.0.5", UserAgent: "Firefox"}
+	phone := models.AttemptClient{IPAddress: "10.0.0.9", UserAgent: "Safari"}
+	attempt := &models.Attempt{ID: 5, UserID: 1, AssessmentID: 10, StartedAt: time.Now().Add(-15 * time.Minute), Status: models.AttemptInProgress,
+		IPAddress: laptop.IPAddress, UserAgent: laptop.UserAgent}
+	assessment := &models.Assessment{ID: 10, Duration: 60,
+		Settings:  models.AssessmentSettings{ResumePolicy: models.ResumeSameDevice},
+		Questions: []models.Question{{ID: 101, AssessmentID: 10, Type: "true-false", CorrectAnswer: "true"}}}
+
+	mockAttemptRepo.On("FindByID", uint(5)).Return(attempt, nil)
+	mockAssessmentRepo.On("FindByID", uint(10)).Return(assessment, nil)
+	mockQuestionRepo.On("FindByID", uint(101)).Return(&assessment.Questions[0], nil)
+	mockAccommodationRepo.On("FindForUser", uint(1), []uint{10}).Return(nil, nil)
+
+	// Chính sách tiếp tục áp dụng cho mọi thao tác trên lượt làm bài, không chỉ khi tiếp tục
+	_, err := service.GetAttemptDetails(5, 1, phone)
+	assert.ErrorIs(t, err, ErrResumeNotAllowed)
+	assert.ErrorIs(t, service.SaveAnswer(5, 101, "true", 1, phone), ErrResumeNotAllowed)
+	_, err = service.SubmitAssessment(5, 1, phone)
+	assert.ErrorIs(t, err, ErrResumeNotAllowed)
+	_, err = service.AttemptClock(5, 1, phone)
+	assert.ErrorIs(t, err, ErrResumeNotAllowed)
+	_, err = service.Heartbeat(5, 1, phone)
+	assert.ErrorIs(t, err, ErrResumeNotAllowed)
+	_, err = service.SubmitMonitorEvent(5, "TAB_SWITCH", nil, nil, 1, phone)
+	assert.ErrorIs(t, err, ErrResumeNotAllowed)
+	mockAttemptRepo.AssertNotCalled(t, "SaveAnswer", mock.Anything)
+	mockAttemptRepo.AssertNotCalled(t, "SaveSuspiciousActivity", mock.Anything)
+
+	// Thiết bị bắt đầu bài vẫn làm được
+	_, err = service.AttemptClock(5, 1, laptop)
+	assert.NoError(t, err)
+
+	// Bài đã nộp xem lại được từ thiết bị khác
+	attempt.Status = models.AttemptGraded
+	_, err = service.GetAttemptDetails(5, 1, phone)
+	assert.NoError(t, err)
+}
+
+func TestStudentService_ResumeAssessment_NoAttempt(t *testing.T) {
+	mockAttemptRepo := new(MockAttemptRepository)
+	service := NewStudentService(nil, mockAttemptRepo, nil, nil, nil, nil, nil, zaptest.NewLogger(t))
+
+	mockAttemptRepo.On("FindInProgressAttempt", uint(1), uint(10)).Return(nil, nil)
+
+	_, err := service.ResumeAssessment(1, 10, models.AttemptClient{})
+
+	assert.ErrorIs(t, err, ErrAttemptNotFound)
+}
+
+func TestStudentService_GetAttemptDetails_ExtendedTime(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	logger := zaptest.NewLogger(t)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, nil, nil, nil, mockAccommodationRepo, nil, logger)
+
+	attemptID := uint(5)
+	userID := uint(1)
+	assessmentID := uint(10)
+	startTime := time.Now().Add(-70 * time.Minute) // Quá thời gian chung 60 phút
+	attempt := &models.Attempt{ID: attemptID, UserID: userID, AssessmentID: assessmentID, StartedAt: startTime, Status: models.AttemptInProgress}
+	assessment := &models.Assessment{ID: assessmentID, Duration: 60}
+
+	mockAttemptRepo.On("FindByID", attemptID).Return(attempt, nil)
+	mockAssessmentRepo.On("FindByID", assessmentID).Return(assessment, nil)
+	mockAccommodationRepo.On("FindForUser", userID, []uint{assessmentID}).Return([]models.AssessmentAccommodation{
+		{AssessmentID: assessmentID, TimeMultiplier: 1.5},
+	}, nil)
+
+	details, err := service.GetAttemptDetails(attemptID, userID, models.AttemptClient{})
+
+	require.NoError(t, err)
+	assert.Equal(t, startTime.Add(90*time.Minute), (*details)["endsAt"])
+	remaining := (*details)["timeRemaining"].(int64)
+	assert.InDelta(t, 20*60, remaining, 5) // Còn khoảng 20 phút
+}
+
+func TestStudentService_GetAttemptDetails_Paused(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, nil, nil, nil, mockAccommodationRepo, nil, zaptest.NewLogger(t))
+
+	// Bắt đầu 70 phút trước, đã tạm dừng 10 phút, được thêm 5 phút và đang tạm dừng từ 20 phút trước
+	pausedAt := time.Now().Add(-20 * time.Minute)
+	attempt := &models.Attempt{ID: 5, UserID: 1, AssessmentID: 10, StartedAt: time.Now().Add(-70 * time.Minute), Status: models.AttemptInProgress,
+		PausedAt: &pausedAt, PausedSeconds: 600, ExtraSeconds: 300}
+	mockAttemptRepo.On("FindByID", uint(5)).Return(attempt, nil)
+	mockAssessmentRepo.On("FindByID", uint(10)).Return(&models.Assessment{ID: 10, Duration: 60}, nil)
+	mockAccommodationRepo.On("FindForUser", uint(1), []uint{uint(10)}).Return(nil, nil)
+
+	details, err := service.GetAttemptDetails(5, 1, models.AttemptClient{})
+
+	require.NoError(t, err)
+	assert.Equal(t, true, (*details)["paused"])
+	assert.InDelta(t, 25*60, (*details)["timeRemaining"].(int64), 5) // Còn khoảng 25 phút, đồng hồ đứng yên khi tạm dừng
+}
+
 // Thêm test case lỗi cho GetAttemptDetails (attempt not found, unauthorized, assessment not found)
 
+func TestStudentService_AttemptClock(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, nil, nil, nil, mockAccommodationRepo, nil, zaptest.NewLogger(t))
+
+	startTime := time.Now().Add(-30 * time.Minute)
+	attempt := &models.Attempt{ID: 5, UserID: 1, AssessmentID: 10, StartedAt: startTime, Status: models.AttemptInProgress, ExtraSeconds: 300}
+	mockAttemptRepo.On("FindByID", uint(5)).Return(attempt, nil)
+	mockAssessmentRepo.On("FindByID", uint(10)).Return(&models.Assessment{ID: 10, Duration: 60, Settings: models.AssessmentSettings{GracePeriod: 30}}, nil)
+	mockAccommodationRepo.On("FindForUser", uint(1), []uint{uint(10)}).Return(nil, nil)
+
+	clock, err := service.AttemptClock(5, 1, models.AttemptClient{})
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(5), clock.AttemptID)
+	assert.Equal(t, models.AttemptInProgress, clock.Status)
+	assert.Equal(t, startTime.Add(65*time.Minute), clock.EndsAt)
+	assert.InDelta(t, 35*60, clock.TimeRemaining, 5) // Còn khoảng 35 phút nhờ 5 phút được thêm
+	assert.False(t, clock.Paused)
+	assert.Equal(t, 30, clock.GracePeriod)
+
+	// Bài làm của người khác không được xem đồng hồ
+	_, err = service.AttemptClock(5, 2, models.AttemptClient{})
+	assert.ErrorIs(t, err, ErrAttemptNotFound)
+}
+
+func TestStudentService_Heartbeat(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	mockHub := new(MockPublisher)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, nil, nil, nil, mockAccommodationRepo, mockHub, zaptest.NewLogger(t))
+
+	attempt := &models.Attempt{ID: 5, UserID: 1, AssessmentID: 10, StartedAt: time.Now(), Status: models.AttemptInProgress}
+	mockAttemptRepo.On("FindByID", uint(5)).Return(attempt, nil)
+	mockAssessmentRepo.On("FindByID", uint(10)).Return(&models.Assessment{ID: 10, Duration: 60}, nil)
+	mockAccommodationRepo.On("FindForUser", uint(1), []uint{uint(10)}).Return(nil, nil)
+	mockAttemptRepo.On("SaveHeartbeat", uint(5), mock.AnythingOfType("time.Time")).Return(nil).Once()
+	// Bảng giám sát của bài kiểm tra nhận nhịp tim
+	mockHub.On("Publish", mock.MatchedBy(func(event models.LiveEvent) bool {
+		return event.AttemptID == 5 && event.AssessmentID == 10 && event.Type == models.LiveEventHeartbeat
+	})).Return(nil).Once()
+
+	clock, err := service.Heartbeat(5, 1, models.AttemptClient{})
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(5), clock.AttemptID)
+	assert.Positive(t, clock.TimeRemaining)
+
+	// Bài đã nộp vẫn trả về đồng hồ nhưng không còn ghi nhịp tim
+	attempt.Status = models.AttemptSubmitted
+	clock, err = service.Heartbeat(5, 1, models.AttemptClient{})
+	require.NoError(t, err)
+	assert.Equal(t, models.AttemptSubmitted, clock.Status)
+
+	// Bài làm của người khác
+	_, err = service.Heartbeat(5, 2, models.AttemptClient{})
+	assert.ErrorIs(t, err, ErrAttemptNotFound)
+
+	mockAttemptRepo.AssertExpectations(t)
+	mockHub.AssertExpectations(t)
+}
+
+func TestStudentService_SaveAnswer_Live(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	mockHub := new(MockPublisher)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, nil, nil, mockAccommodationRepo, mockHub, zaptest.NewLogger(t))
+
+	attempt := &models.Attempt{ID: 1, UserID: 5, AssessmentID: 10, Status: models.AttemptInProgress, Answers: []models.Answer{{AttemptID: 1, QuestionID: 100, Answer: "false"}}}
+	mockAttemptRepo.On("FindByID", uint(1)).Return(attempt, nil)
+	mockQuestionRepo.On("FindByID", uint(101)).Return(&models.Question{ID: 101, AssessmentID: 10, Type: "true-false", CorrectAnswer: "true", Points: 1}, nil)
+	mockAssessmentRepo.On("FindByID", uint(10)).Return(&models.Assessment{ID: 10, Duration: 60}, nil)
+	mockAccommodationRepo.On("FindForUser", uint(5), []uint{uint(10)}).Return(nil, nil)
+	mockAttemptRepo.On("FindAnswerByAttemptAndQuestion", uint(1), uint(101)).Return(nil, nil)
+	mockAttemptRepo.On("SaveAnswer", mock.Anything).Return(nil)
+	// Câu trả lời mới được tính vào số câu đã trả lời
+	mockHub.On("Publish", mock.MatchedBy(func(event models.LiveEvent) bool {
+		data := event.Data.(map[string]interface{})
+		return event.AttemptID == 1 && event.AssessmentID == 10 && event.Type == models.LiveEventAnswerSaved &&
+			data["questionId"] == uint(101) && data["answered"] == 2
+	})).Return(nil).Once()
+
+	err := service.SaveAnswer(1, 101, "true", 5, models.AttemptClient{})
+
+	require.NoError(t, err)
+	mockHub.AssertExpectations(t)
+}
+
 func TestStudentService_SaveAnswer_New(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockAttemptRepo := new(MockAttemptRepository)
 	mockQuestionRepo := new(MockQuestionRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
 	logger := zaptest.NewLogger(t)
-	service := NewStudentService(nil, mockAttemptRepo, mockQuestionRepo, nil, logger)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, nil, nil, mockAccommodationRepo, nil, logger)
 
 	attemptID := uint(1)
 	questionID := uint(101)
 	userID := uint(5)
 	answerStr := "true"
-	attempt := &models.Attempt{ID: attemptID, UserID: userID, AssessmentID: 10, Status: "In Progress"}
+	attempt := &models.Attempt{ID: attemptID, UserID: userID, AssessmentID: 10, Status: models.AttemptInProgress}
 	question := &models.Question{ID: questionID, AssessmentID: 10, Type: "true-false", CorrectAnswer: "true", Points: 1}
 	expectedAnswer := &models.Answer{AttemptID: attemptID, QuestionID: questionID, Answer: answerStr, IsCorrect: &[]bool{true}[0]} // Correct answer
 
 	mockAttemptRepo.On("FindByID", attemptID).Return(attempt, nil)
 	mockQuestionRepo.On("FindByID", questionID).Return(question, nil)
+	mockAssessmentRepo.On("FindByID", uint(10)).Return(&models.Assessment{ID: 10, Duration: 60}, nil)
+	mockAccommodationRepo.On("FindForUser", uint(5), []uint{uint(10)}).Return(nil, nil)
 	// Expect FindAnswerByAttemptAndQuestion to return not found (nil, nil)
 	mockAttemptRepo.On("FindAnswerByAttemptAndQuestion", attemptID, questionID).Return(nil, nil) // Simulate answer not existing
 	// Expect SaveAnswer to be called
@@ -526,33 +1395,67 @@ func TestStudentService_SaveAnswer_New(t *testing.T) {
 		return ans.AttemptID == expectedAnswer.AttemptID &&
 			ans.QuestionID == expectedAnswer.QuestionID &&
 			ans.Answer == expectedAnswer.Answer &&
-			ans.IsCorrect != nil && *ans.IsCorrect == *expectedAnswer.IsCorrect
+			ans.IsCorrect != nil && *ans.IsCorrect == *expectedAnswer.IsCorrect &&
+			ans.AwardedPoints != nil && *ans.AwardedPoints == question.Points
 	})).Return(nil)
 
-	err := service.SaveAnswer(attemptID, questionID, answerStr, userID)
+	err := service.SaveAnswer(attemptID, questionID, answerStr, userID, models.AttemptClient{})
 
 	assert.NoError(t, err)
 	mockAttemptRepo.AssertExpectations(t)
 	mockQuestionRepo.AssertExpectations(t)
 }
 
+func TestStudentService_SaveAnswer_PartialCredit(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, nil, nil, mockAccommodationRepo, nil, zaptest.NewLogger(t))
+
+	question := &models.Question{
+		ID: 101, AssessmentID: 10, Type: "multiple-select", Points: 6, PartialCredit: true, Penalty: 0.5,
+		Options: []models.QuestionOption{{OptionID: "a"}, {OptionID: "b"}, {OptionID: "c"}, {OptionID: "d"}},
+		Config:  models.QuestionConfig(`{"correctOptions":["a","b"]}`),
+	}
+
+	mockAttemptRepo.On("FindByID", uint(1)).Return(&models.Attempt{ID: 1, UserID: 5, AssessmentID: 10, Status: models.AttemptInProgress}, nil)
+	mockQuestionRepo.On("FindByID", uint(101)).Return(question, nil)
+	mockAssessmentRepo.On("FindByID", uint(10)).Return(&models.Assessment{ID: 10, Duration: 60}, nil)
+	mockAccommodationRepo.On("FindForUser", uint(5), []uint{uint(10)}).Return(nil, nil)
+	mockAttemptRepo.On("FindAnswerByAttemptAndQuestion", uint(1), uint(101)).Return(nil, nil)
+	// Chọn 1/2 đáp án đúng và 1/2 đáp án sai: 6*1/2 - 6*0.5*1/2 = 1.5
+	mockAttemptRepo.On("SaveAnswer", mock.MatchedBy(func(ans *models.Answer) bool {
+		return ans.IsCorrect != nil && !*ans.IsCorrect && ans.AwardedPoints != nil && *ans.AwardedPoints == 1.5
+	})).Return(nil)
+
+	err := service.SaveAnswer(1, 101, `["a","c"]`, 5, models.AttemptClient{})
+
+	assert.NoError(t, err)
+	mockAttemptRepo.AssertExpectations(t)
+}
+
 func TestStudentService_SaveAnswer_Update(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockAttemptRepo := new(MockAttemptRepository)
 	mockQuestionRepo := new(MockQuestionRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
 	logger := zaptest.NewLogger(t)
-	service := NewStudentService(nil, mockAttemptRepo, mockQuestionRepo, nil, logger)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, nil, nil, mockAccommodationRepo, nil, logger)
 
 	attemptID := uint(1)
 	questionID := uint(101)
 	userID := uint(5)
 	newAnswerStr := "false"
-	attempt := &models.Attempt{ID: attemptID, UserID: userID, AssessmentID: 10, Status: "In Progress"}
+	attempt := &models.Attempt{ID: attemptID, UserID: userID, AssessmentID: 10, Status: models.AttemptInProgress}
 	question := &models.Question{ID: questionID, AssessmentID: 10, Type: "true-false", CorrectAnswer: "true", Points: 1}
 	existingAnswer := &models.Answer{ID: 50, AttemptID: attemptID, QuestionID: questionID, Answer: "true", IsCorrect: &[]bool{true}[0]}
 	expectedUpdatedAnswer := &models.Answer{ID: 50, AttemptID: attemptID, QuestionID: questionID, Answer: newAnswerStr, IsCorrect: &[]bool{false}[0]} // Incorrect answer now
 
 	mockAttemptRepo.On("FindByID", attemptID).Return(attempt, nil)
 	mockQuestionRepo.On("FindByID", questionID).Return(question, nil)
+	mockAssessmentRepo.On("FindByID", uint(10)).Return(&models.Assessment{ID: 10, Duration: 60}, nil)
+	mockAccommodationRepo.On("FindForUser", uint(5), []uint{uint(10)}).Return(nil, nil)
 	// Expect FindAnswerByAttemptAndQuestion to return the existing answer
 	mockAttemptRepo.On("FindAnswerByAttemptAndQuestion", attemptID, questionID).Return(existingAnswer, nil)
 	// Expect UpdateAnswer to be called
@@ -562,7 +1465,7 @@ func TestStudentService_SaveAnswer_Update(t *testing.T) {
 			ans.IsCorrect != nil && *ans.IsCorrect == *expectedUpdatedAnswer.IsCorrect
 	})).Return(nil)
 
-	err := service.SaveAnswer(attemptID, questionID, newAnswerStr, userID)
+	err := service.SaveAnswer(attemptID, questionID, newAnswerStr, userID, models.AttemptClient{})
 
 	assert.NoError(t, err)
 	mockAttemptRepo.AssertExpectations(t)
@@ -570,20 +1473,85 @@ func TestStudentService_SaveAnswer_Update(t *testing.T) {
 	mockAttemptRepo.AssertNotCalled(t, "SaveAnswer", mock.Anything) // SaveAnswer should not be called
 }
 
+func TestStudentService_SaveAnswer_NotGiven(t *testing.T) {
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	service := NewStudentService(nil, mockAttemptRepo, mockQuestionRepo, nil, nil, nil, nil, zaptest.NewLogger(t))
+
+	// Lượt làm bài chỉ được giao câu 101
+	attempt := &models.Attempt{ID: 1, UserID: 5, AssessmentID: 10, Status: models.AttemptInProgress,
+		Selection: []models.AttemptQuestion{{QuestionID: 101}}}
+	mockAttemptRepo.On("FindByID", uint(1)).Return(attempt, nil)
+	mockQuestionRepo.On("FindByID", uint(102)).Return(&models.Question{ID: 102, AssessmentID: 10, Type: "true-false", CorrectAnswer: "true"}, nil)
+
+	err := service.SaveAnswer(1, 102, "true", 5, models.AttemptClient{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "question was not given in this attempt")
+	mockAttemptRepo.AssertNotCalled(t, "SaveAnswer", mock.Anything)
+}
+
+func TestStudentService_SaveAnswer_Timer(t *testing.T) {
+	pausedAt := time.Now().Add(-5 * time.Minute)
+	tests := []struct {
+		name      string
+		startedAt time.Time
+		pausedAt  *time.Time
+		settings  models.AssessmentSettings
+		wantErr   error
+	}{
+		{"in time", time.Now().Add(-30 * time.Minute), nil, models.AssessmentSettings{TimeLimitEnforced: true}, nil},
+		{"time up", time.Now().Add(-61 * time.Minute), nil, models.AssessmentSettings{TimeLimitEnforced: true}, ErrTimeUp},
+		{"grace period", time.Now().Add(-61 * time.Minute), nil, models.AssessmentSettings{TimeLimitEnforced: true, GracePeriod: 120}, nil},
+		{"time limit not enforced", time.Now().Add(-90 * time.Minute), nil, models.AssessmentSettings{}, nil},
+		{"paused", time.Now().Add(-30 * time.Minute), &pausedAt, models.AssessmentSettings{TimeLimitEnforced: true}, ErrAttemptPaused},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAssessmentRepo := new(MockAssessmentRepository)
+			mockAttemptRepo := new(MockAttemptRepository)
+			mockQuestionRepo := new(MockQuestionRepository)
+			mockAccommodationRepo := new(MockAccommodationRepository)
+			service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, nil, nil, mockAccommodationRepo, nil, zaptest.NewLogger(t))
+
+			attempt := &models.Attempt{ID: 1, UserID: 5, AssessmentID: 10, StartedAt: tt.startedAt, PausedAt: tt.pausedAt, Status: models.AttemptInProgress}
+			mockAttemptRepo.On("FindByID", uint(1)).Return(attempt, nil)
+			mockQuestionRepo.On("FindByID", uint(101)).Return(&models.Question{ID: 101, AssessmentID: 10, Type: "true-false", CorrectAnswer: "true", Points: 1}, nil)
+			mockAssessmentRepo.On("FindByID", uint(10)).Return(&models.Assessment{ID: 10, Duration: 60, Settings: tt.settings}, nil)
+			mockAccommodationRepo.On("FindForUser", uint(5), []uint{uint(10)}).Return(nil, nil).Maybe()
+			mockAttemptRepo.On("FindAnswerByAttemptAndQuestion", uint(1), uint(101)).Return(nil, nil).Maybe()
+			mockAttemptRepo.On("SaveAnswer", mock.AnythingOfType("*models.Answer")).Return(nil).Maybe()
+
+			err := service.SaveAnswer(1, 101, "true", 5, models.AttemptClient{})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockAttemptRepo.AssertNotCalled(t, "SaveAnswer", mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			mockAttemptRepo.AssertCalled(t, "SaveAnswer", mock.Anything)
+		})
+	}
+}
+
 // Thêm test case lỗi cho SaveAnswer (attempt not found, unauthorized, attempt not in progress, question not found, question not in assessment, invalid answer format)
 
 func TestStudentService_SubmitAssessment(t *testing.T) {
 	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockAttemptRepo := new(MockAttemptRepository)
 	mockQuestionRepo := new(MockQuestionRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
 	logger := zaptest.NewLogger(t)
-	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, nil, logger)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, nil, nil, mockAccommodationRepo, nil, logger)
 
 	attemptID := uint(1)
 	userID := uint(5)
 	assessmentID := uint(10)
 	startTime := time.Now().Add(-20 * time.Minute) // Started 20 mins ago
 	correct := true
+	awarded := 10.0
 	// incorrect := false
 
 	attempt := &models.Attempt{
@@ -591,10 +1559,10 @@ func TestStudentService_SubmitAssessment(t *testing.T) {
 		UserID:       userID,
 		AssessmentID: assessmentID,
 		StartedAt:    startTime,
-		Status:       "In Progress",
+		Status:       models.AttemptInProgress,
 		Answers: []models.Answer{
-			{QuestionID: 101, Answer: "true", IsCorrect: &correct},  // Correct
-			{QuestionID: 102, Answer: "Essay text", IsCorrect: nil}, // Essay, needs grading
+			{QuestionID: 101, Answer: "true", IsCorrect: &correct, AwardedPoints: &awarded}, // Correct
+			{QuestionID: 102, Answer: "Essay text", IsCorrect: nil},                         // Essay, needs grading
 			// Question 103 is unanswered
 		},
 	}
@@ -609,25 +1577,31 @@ func TestStudentService_SubmitAssessment(t *testing.T) {
 		{ID: 102, AssessmentID: assessmentID, Points: 15, Type: "essay"},
 		{ID: 103, AssessmentID: assessmentID, Points: 5, Type: "multiple-choice", CorrectAnswer: "a"}, // Unanswered
 	}
-	totalPoints := 10.0 + 15.0 + 5.0                    // 30
-	earnedPoints := 10.0                                // Only from Q101
-	expectedScore := (earnedPoints / totalPoints) * 100 // ~33.33
-	expectedStatus := "Failed"                          // Below passing score
+	totalPoints := 10.0 + 15.0 + 5.0                     // 30
+	earnedPoints := 10.0                                 // Only from Q101
+	expectedScore := (earnedPoints / totalPoints) * 100  // ~33.33
+	expectedStatus := models.AttemptPendingManualGrading // Essay answer still needs a teacher
 
 	mockAttemptRepo.On("FindByID", attemptID).Return(attempt, nil)
 	mockAssessmentRepo.On("FindByID", assessmentID).Return(assessment, nil)
 	mockQuestionRepo.On("FindByAssessmentID", assessmentID).Return(questions, nil)
-	// Expect Update attempt được gọi với điểm và status đã tính
-	mockAttemptRepo.On("Update", mock.MatchedBy(func(att *models.Attempt) bool {
+	mockAccommodationRepo.On("FindForUser", userID, []uint{assessmentID}).Return(nil, nil)
+	// Expect bài được lưu với điểm và status đã tính, cùng lịch sử điểm
+	mockAttemptRepo.On("SaveGrade", mock.MatchedBy(func(change models.GradeChange) bool {
+		att := change.Attempt
 		scoreMatch := att.Score != nil && *att.Score >= expectedScore-0.01 && *att.Score <= expectedScore+0.01 // Check score with tolerance
-		statusMatch := att.Status == expectedStatus
+		statusMatch := att.Status == expectedStatus && att.Passed == nil                                       // Chưa có kết quả đạt/trượt cho tới khi chấm xong
 		submittedMatch := att.SubmittedAt != nil
 		endedMatch := att.EndedAt != nil
 		durationMatch := att.Duration != nil && *att.Duration >= 20 // Duration should be around 20 mins
-		return scoreMatch && statusMatch && submittedMatch && endedMatch && durationMatch
+		// Điểm chấm tự động của câu 101 và của cả bài được ghi vào lịch sử điểm, không có người chấm
+		events := change.Events
+		return scoreMatch && statusMatch && submittedMatch && endedMatch && durationMatch && len(events) == 2 &&
+			events[0].AnswerID != nil && events[0].Kind == models.GradeEventAuto && *events[0].NewValue == 10 && events[0].ActorID == nil &&
+			events[1].AnswerID == nil && events[1].Kind == models.GradeEventAuto && events[1].OldValue == nil
 	})).Return(nil)
 
-	result, err := service.SubmitAssessment(attemptID, userID)
+	result, err := service.SubmitAssessment(attemptID, userID, models.AttemptClient{})
 
 	assert.NoError(t, err)
 	require.NotNil(t, result)
@@ -642,26 +1616,209 @@ func TestStudentService_SubmitAssessment(t *testing.T) {
 	assert.Equal(t, (1), resultsMap["unanswered"])
 	assert.Equal(t, (1), resultsMap["essayQuestions"])
 	assert.Equal(t, expectedStatus, resultsMap["status"])
+	assert.Nil(t, resultsMap["passed"])
 
 	mockAttemptRepo.AssertExpectations(t)
 	mockAssessmentRepo.AssertExpectations(t)
 	mockQuestionRepo.AssertExpectations(t)
 }
 
+func TestStudentService_SubmitAssessment_Paused(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, nil, nil, mockAccommodationRepo, nil, zaptest.NewLogger(t))
+
+	pausedAt := time.Now().Add(-time.Minute)
+	attempt := &models.Attempt{ID: 1, UserID: 5, AssessmentID: 10, StartedAt: time.Now().Add(-10 * time.Minute), PausedAt: &pausedAt, Status: models.AttemptInProgress}
+	mockAttemptRepo.On("FindByID", uint(1)).Return(attempt, nil)
+	mockAssessmentRepo.On("FindByID", uint(10)).Return(&models.Assessment{ID: 10, Duration: 60}, nil)
+	mockQuestionRepo.On("FindByAssessmentID", uint(10)).Return([]models.Question{}, nil)
+	mockAccommodationRepo.On("FindForUser", uint(5), []uint{uint(10)}).Return(nil, nil)
+
+	_, err := service.SubmitAssessment(1, 5, models.AttemptClient{})
+
+	assert.ErrorIs(t, err, ErrAttemptPaused)
+	mockAttemptRepo.AssertNotCalled(t, "SaveGrade", mock.Anything)
+}
+
+func TestStudentService_SubmitAssessment_AfterTimeUp(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, nil, nil, mockAccommodationRepo, nil, zaptest.NewLogger(t))
+
+	// Hết giờ lúc 10 phút trước, bài kiểm tra đóng 5 phút trước: bài nộp muộn vẫn tính là nộp lúc hết giờ
+	closesAt := time.Now().Add(-5 * time.Minute)
+	attempt := &models.Attempt{ID: 1, UserID: 5, AssessmentID: 10, StartedAt: time.Now().Add(-70 * time.Minute), Status: models.AttemptInProgress}
+	assessment := &models.Assessment{ID: 10, Duration: 60, AvailableUntil: &closesAt, Settings: models.AssessmentSettings{
+		TimeLimitEnforced: true, LatePolicy: models.LatePolicyPenalty, LatePenalty: 10,
+	}}
+	mockAttemptRepo.On("FindByID", uint(1)).Return(attempt, nil)
+	mockAssessmentRepo.On("FindByID", uint(10)).Return(assessment, nil)
+	mockQuestionRepo.On("FindByAssessmentID", uint(10)).Return([]models.Question{}, nil)
+	mockAccommodationRepo.On("FindForUser", uint(5), []uint{uint(10)}).Return(nil, nil)
+	mockAttemptRepo.On("SaveGrade", mock.MatchedBy(func(change models.GradeChange) bool {
+		att := change.Attempt
+		return !att.IsLate && att.LatePenalty == 0
+	})).Return(nil)
+
+	_, err := service.SubmitAssessment(1, 5, models.AttemptClient{})
+
+	require.NoError(t, err)
+	mockAttemptRepo.AssertExpectations(t)
+}
+
+func TestStudentService_SubmitAssessment_ScoresGivenQuestions(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, nil, nil, mockAccommodationRepo, nil, zaptest.NewLogger(t))
+
+	correct := true
+	awarded := 10.0
+	// Lượt làm bài được giao câu 101 và 103, không được giao câu 102
+	attempt := &models.Attempt{
+		ID: 1, UserID: 5, AssessmentID: 10, StartedAt: time.Now().Add(-10 * time.Minute), Status: models.AttemptInProgress,
+		Answers:   []models.Answer{{ID: 20, QuestionID: 101, Answer: "true", IsCorrect: &correct, AwardedPoints: &awarded}},
+		Selection: []models.AttemptQuestion{{QuestionID: 103, Position: 0}, {QuestionID: 101, Position: 1}},
+	}
+	assessment := &models.Assessment{ID: 10, PassingScore: 50, Duration: 30}
+	questions := []models.Question{
+		{ID: 101, AssessmentID: 10, Points: 10, Type: "true-false", CorrectAnswer: "true"},
+		{ID: 102, AssessmentID: 10, Points: 30, Type: "true-false", CorrectAnswer: "true"},
+		{ID: 103, AssessmentID: 10, Points: 10, Type: "true-false", CorrectAnswer: "false"},
+	}
+
+	mockAttemptRepo.On("FindByID", uint(1)).Return(attempt, nil)
+	mockAssessmentRepo.On("FindByID", uint(10)).Return(assessment, nil)
+	mockQuestionRepo.On("FindByAssessmentID", uint(10)).Return(questions, nil)
+	mockAccommodationRepo.On("FindForUser", uint(5), []uint{10}).Return(nil, nil)
+	// 10 trên 20 điểm của các câu được giao
+	mockAttemptRepo.On("SaveGrade", mock.MatchedBy(func(change models.GradeChange) bool {
+		att := change.Attempt
+		return att.Score != nil && *att.Score == 50 && att.Passed != nil && *att.Passed
+	})).Return(nil)
+
+	result, err := service.SubmitAssessment(1, 5, models.AttemptClient{})
+
+	require.NoError(t, err)
+	resultsMap := (*result)["results"].(map[string]interface{})
+	assert.Equal(t, 2, resultsMap["totalQuestions"])
+	assert.Equal(t, 1, resultsMap["unanswered"])
+	mockAttemptRepo.AssertExpectations(t)
+}
+
+func TestStudentService_SubmitAssessment_LatePenalty(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	logger := zaptest.NewLogger(t)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, nil, nil, mockAccommodationRepo, nil, logger)
+
+	attemptID := uint(1)
+	userID := uint(5)
+	assessmentID := uint(10)
+	closedAt := time.Now().Add(-10 * time.Minute) // Nộp muộn 10 phút
+	correct := true
+	awarded := 10.0
+	attempt := &models.Attempt{
+		ID:           attemptID,
+		UserID:       userID,
+		AssessmentID: assessmentID,
+		StartedAt:    time.Now().Add(-30 * time.Minute),
+		Status:       models.AttemptInProgress,
+		Answers:      []models.Answer{{QuestionID: 101, Answer: "true", IsCorrect: &correct, AwardedPoints: &awarded}},
+	}
+	assessment := &models.Assessment{
+		ID:             assessmentID,
+		PassingScore:   80,
+		Duration:       60,
+		AvailableUntil: &closedAt,
+		Settings:       models.AssessmentSettings{LatePolicy: models.LatePolicyPenalty, LatePenalty: 25},
+	}
+
+	mockAttemptRepo.On("FindByID", attemptID).Return(attempt, nil)
+	mockAssessmentRepo.On("FindByID", assessmentID).Return(assessment, nil)
+	mockQuestionRepo.On("FindByAssessmentID", assessmentID).Return([]models.Question{
+		{ID: 101, AssessmentID: assessmentID, Points: 10, Type: "true-false", CorrectAnswer: "true"},
+	}, nil)
+	mockAccommodationRepo.On("FindForUser", userID, []uint{assessmentID}).Return(nil, nil)
+	// 100 điểm bị trừ 25% còn 75, dưới điểm đạt 80
+	mockAttemptRepo.On("SaveGrade", mock.MatchedBy(func(change models.GradeChange) bool {
+		att := change.Attempt
+		return att.IsLate && att.LatePenalty == 25 && att.Score != nil && *att.Score == 75 &&
+			att.Status == models.AttemptGraded && att.Passed != nil && !*att.Passed
+	})).Return(nil)
+
+	result, err := service.SubmitAssessment(attemptID, userID, models.AttemptClient{})
+
+	require.NoError(t, err)
+	resultsMap := (*result)["results"].(map[string]interface{})
+	assert.Equal(t, true, resultsMap["isLate"])
+	assert.Equal(t, 25.0, resultsMap["latePenalty"])
+	mockAttemptRepo.AssertExpectations(t)
+}
+
+func TestStudentService_SubmitAssessment_ExtendedDueDateNotLate(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	logger := zaptest.NewLogger(t)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, nil, nil, mockAccommodationRepo, nil, logger)
+
+	attemptID := uint(1)
+	userID := uint(5)
+	assessmentID := uint(10)
+	closedAt := time.Now().Add(-10 * time.Minute)
+	extendedTo := time.Now().Add(24 * time.Hour) // Được gia hạn nên không tính muộn
+	attempt := &models.Attempt{ID: attemptID, UserID: userID, AssessmentID: assessmentID, StartedAt: time.Now().Add(-30 * time.Minute), Status: models.AttemptInProgress}
+	assessment := &models.Assessment{
+		ID:           assessmentID,
+		Duration:     60,
+		DueDate:      &closedAt,
+		PassingScore: 50,
+		Settings:     models.AssessmentSettings{LatePolicy: models.LatePolicyPenalty, LatePenalty: 25},
+	}
+
+	mockAttemptRepo.On("FindByID", attemptID).Return(attempt, nil)
+	mockAssessmentRepo.On("FindByID", assessmentID).Return(assessment, nil)
+	mockQuestionRepo.On("FindByAssessmentID", assessmentID).Return([]models.Question{}, nil)
+	mockAccommodationRepo.On("FindForUser", userID, []uint{assessmentID}).Return([]models.AssessmentAccommodation{
+		{AssessmentID: assessmentID, TimeMultiplier: 1, DueDate: &extendedTo},
+	}, nil)
+	mockAttemptRepo.On("SaveGrade", mock.MatchedBy(func(change models.GradeChange) bool {
+		att := change.Attempt
+		return !att.IsLate && att.LatePenalty == 0
+	})).Return(nil)
+
+	_, err := service.SubmitAssessment(attemptID, userID, models.AttemptClient{})
+
+	require.NoError(t, err)
+	mockAttemptRepo.AssertExpectations(t)
+}
+
 // Thêm test case lỗi cho SubmitAssessment
 
 func TestStudentService_SubmitMonitorEvent(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
 	mockAttemptRepo := new(MockAttemptRepository)
 	logger := zaptest.NewLogger(t)
-	service := NewStudentService(nil, mockAttemptRepo, nil, nil, logger)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, nil, nil, nil, nil, nil, logger)
 
 	attemptID := uint(1)
 	userID := uint(5)
-	attempt := &models.Attempt{ID: attemptID, UserID: userID, AssessmentID: 10, Status: "In Progress"}
+	attempt := &models.Attempt{ID: attemptID, UserID: userID, AssessmentID: 10, Status: models.AttemptInProgress}
 	eventType := "TAB_SWITCH"
 	details := map[string]interface{}{"count": 3.0}
 
 	mockAttemptRepo.On("FindByID", attemptID).Return(attempt, nil)
+	mockAssessmentRepo.On("FindByID", uint(10)).Return(&models.Assessment{ID: 10}, nil)
 	// Expect SaveSuspiciousActivity to be called
 	mockAttemptRepo.On("SaveSuspiciousActivity", mock.MatchedBy(func(sa *models.SuspiciousActivity) bool {
 		return sa.AttemptID == attemptID &&
@@ -670,7 +1827,7 @@ func TestStudentService_SubmitMonitorEvent(t *testing.T) {
 			sa.Severity == "CRITICAL" // Severity for TAB_SWITCH
 	})).Return(nil)
 
-	result, err := service.SubmitMonitorEvent(attemptID, eventType, details, nil, userID) // No image data
+	result, err := service.SubmitMonitorEvent(attemptID, eventType, details, nil, userID, models.AttemptClient{}) // No image data
 
 	assert.NoError(t, err)
 	require.NotNil(t, result)
@@ -681,13 +1838,36 @@ func TestStudentService_SubmitMonitorEvent(t *testing.T) {
 	mockAttemptRepo.AssertExpectations(t)
 }
 
+func TestStudentService_SubmitMonitorEvent_Live(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockHub := new(MockPublisher)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, nil, nil, nil, nil, mockHub, zaptest.NewLogger(t))
+
+	mockAttemptRepo.On("FindByID", uint(1)).Return(&models.Attempt{ID: 1, UserID: 5, AssessmentID: 10, Status: models.AttemptInProgress}, nil)
+	mockAssessmentRepo.On("FindByID", uint(10)).Return(&models.Assessment{ID: 10}, nil)
+	mockAttemptRepo.On("SaveSuspiciousActivity", mock.Anything).Return(nil)
+	// Kênh trực tiếp nhận mức độ nghiêm trọng của sự kiện
+	mockHub.On("Publish", mock.MatchedBy(func(event models.LiveEvent) bool {
+		data := event.Data.(map[string]interface{})
+		return event.AttemptID == 1 && event.Type == models.LiveEventMonitor && data["severity"] == "CRITICAL" && data["type"] == "TAB_SWITCH"
+	})).Return(errors.New("redis down"))
+
+	// Lỗi của kênh trực tiếp không làm hỏng yêu cầu
+	result, err := service.SubmitMonitorEvent(1, "TAB_SWITCH", map[string]interface{}{"count": 3.0}, nil, 5, models.AttemptClient{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "CRITICAL", (*result)["severity"])
+	mockHub.AssertExpectations(t)
+}
+
 // Thêm test case lỗi cho SubmitMonitorEvent
 
 func TestStudentService_GetAllAttemptByUserID(t *testing.T) {
 	mockAttemptRepo := new(MockAttemptRepository)
 	mockUserRepo := new(MockUserRepository)
 	logger := zaptest.NewLogger(t)
-	service := NewStudentService(nil, mockAttemptRepo, nil, mockUserRepo, logger)
+	service := NewStudentService(nil, mockAttemptRepo, nil, mockUserRepo, nil, nil, nil, logger)
 
 	userID := uint(1)
 	params := util.PaginationParams{Limit: 5}
@@ -711,3 +1891,146 @@ func TestStudentService_GetAllAttemptByUserID(t *testing.T) {
 // Test cho AutoSubmitAssessment phức tạp hơn vì nó liên quan đến thời gian và nhiều bước,
 // có thể phù hợp hơn với integration test hoặc test thủ công.
 // Tuy nhiên, có thể viết unit test bằng cách mock ExpiredAttempt, FindByID, FindByAssessmentID, Update.
+
+func TestStudentService_AutoSubmitAssessment_ExtendedTime(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	logger := zaptest.NewLogger(t)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, nil, nil, mockAccommodationRepo, nil, logger)
+
+	assessmentID := uint(10)
+	startTime := time.Now().Add(-70 * time.Minute)
+	attempts := []models.Attempt{
+		{ID: 1, UserID: 1, AssessmentID: assessmentID, StartedAt: startTime, Status: models.AttemptInProgress}, // Được thêm giờ
+		{ID: 2, UserID: 2, AssessmentID: assessmentID, StartedAt: startTime, Status: models.AttemptInProgress}, // Đã hết giờ
+	}
+
+	mockAttemptRepo.On("ExpiredAttempt").Return(attempts, nil)
+	mockAssessmentRepo.On("FindByID", assessmentID).Return(&models.Assessment{ID: assessmentID, Duration: 60, PassingScore: 50}, nil)
+	mockAccommodationRepo.On("FindForUser", uint(1), []uint{assessmentID}).Return([]models.AssessmentAccommodation{
+		{AssessmentID: assessmentID, TimeMultiplier: 1, ExtraMinutes: 15},
+	}, nil)
+	mockAccommodationRepo.On("FindForUser", uint(2), []uint{assessmentID}).Return(nil, nil)
+	mockQuestionRepo.On("FindByAssessmentID", assessmentID).Return([]models.Question{}, nil)
+	// Không có câu hỏi nên điểm 0, dưới điểm đạt 50
+	mockAttemptRepo.On("SaveGrade", mock.MatchedBy(func(change models.GradeChange) bool {
+		att, events := change.Attempt, change.Events
+		return att.ID == 2 && att.Status == models.AttemptGraded && att.Passed != nil && !*att.Passed &&
+			len(events) == 1 && events[0].AttemptID == 2 && *events[0].NewValue == 0
+	})).Return(nil)
+
+	err := service.AutoSubmitAssessment()
+
+	assert.NoError(t, err)
+	mockAttemptRepo.AssertNumberOfCalls(t, "SaveGrade", 1)
+	mockAttemptRepo.AssertExpectations(t)
+}
+
+func TestStudentService_AutoSubmitAssessment_PausedAndGrace(t *testing.T) {
+	mockAssessmentRepo := new(MockAssessmentRepository)
+	mockAttemptRepo := new(MockAttemptRepository)
+	mockQuestionRepo := new(MockQuestionRepository)
+	mockAccommodationRepo := new(MockAccommodationRepository)
+	mockHub := new(MockPublisher)
+	service := NewStudentService(mockAssessmentRepo, mockAttemptRepo, mockQuestionRepo, nil, nil, mockAccommodationRepo, mockHub, zaptest.NewLogger(t))
+
+	assessmentID := uint(10)
+	pausedAt := time.Now().Add(-30 * time.Minute)
+	attempts := []models.Attempt{
+		{ID: 1, UserID: 1, AssessmentID: assessmentID, StartedAt: time.Now().Add(-80 * time.Minute), PausedAt: &pausedAt, Status: models.AttemptInProgress}, // Đang tạm dừng
+		{ID: 2, UserID: 2, AssessmentID: assessmentID, StartedAt: time.Now().Add(-65 * time.Minute), Status: models.AttemptInProgress},                      // Còn trong thời gian ân hạn
+		{ID: 3, UserID: 3, AssessmentID: assessmentID, StartedAt: time.Now().Add(-80 * time.Minute), Status: models.AttemptInProgress},                      // Đã hết giờ
+	}
+
+	mockAttemptRepo.On("ExpiredAttempt").Return(attempts, nil)
+	mockAssessmentRepo.On("FindByID", assessmentID).Return(&models.Assessment{ID: assessmentID, Duration: 60, Settings: models.AssessmentSettings{GracePeriod: 600}}, nil)
+	mockAccommodationRepo.On("FindForUser", mock.Anything, []uint{assessmentID}).Return(nil, nil)
+	mockQuestionRepo.On("FindByAssessmentID", assessmentID).Return([]models.Question{}, nil)
+	mockAttemptRepo.On("SaveGrade", mock.MatchedBy(func(change models.GradeChange) bool { return change.Attempt.ID == 3 })).Return(nil)
+	// Học sinh được báo bài đã bị nộp khi hết giờ
+	mockHub.On("Publish", mock.MatchedBy(func(event models.LiveEvent) bool {
+		return event.AttemptID == 3 && event.Type == models.LiveEventSubmitted && event.Data.(map[string]interface{})["forced"] == true
+	})).Return(nil)
+
+	err := service.AutoSubmitAssessment()
+
+	assert.NoError(t, err)
+	mockAttemptRepo.AssertNumberOfCalls(t, "SaveGrade", 1)
+	mockAttemptRepo.AssertExpectations(t)
+	mockHub.AssertExpectations(t)
+}
+
+func TestStudentService_RequestRegrade(t *testing.T) {
+	mockAttemptRepo := new(MockAttemptRepository)
+	service := NewStudentService(nil, mockAttemptRepo, nil, nil, nil, nil, nil, zaptest.NewLogger(t))
+
+	answerID := uint(11)
+	mockAttemptRepo.On("FindByID", uint(1)).Return(&models.Attempt{
+		ID: 1, UserID: 5, Status: models.AttemptGraded,
+		Answers: []models.Answer{{ID: 11, AttemptID: 1}},
+	}, nil)
+	mockAttemptRepo.On("FindRegradeRequestsByAttempt", uint(1)).Return([]models.RegradeRequest{
+		{ID: 2, AttemptID: 1, Status: models.RegradeRejected},
+	}, nil)
+	mockAttemptRepo.On("CreateRegradeRequest", mock.MatchedBy(func(r *models.RegradeRequest) bool {
+		return r.AttemptID == 1 && r.UserID == 5 && *r.AnswerID == 11 && r.Status == models.RegradePending
+	})).Return(nil)
+
+	request, err := service.RequestRegrade(1, 5, models.RegradeRequestDTO{AnswerID: &answerID, Reason: "My essay covers the second point"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "My essay covers the second point", request.Reason)
+	mockAttemptRepo.AssertExpectations(t)
+}
+
+func TestStudentService_RequestRegrade_Errors(t *testing.T) {
+	otherAnswer := uint(99)
+
+	tests := []struct {
+		name     string
+		attempt  *models.Attempt
+		existing []models.RegradeRequest
+		request  models.RegradeRequestDTO
+		wantErr  error
+	}{
+		{"attempt of another student", &models.Attempt{ID: 1, UserID: 6, Status: models.AttemptGraded}, nil, models.RegradeRequestDTO{Reason: "Why?"}, ErrAttemptNotFound},
+		{"attempt not graded yet", &models.Attempt{ID: 1, UserID: 5, Status: models.AttemptPendingManualGrading}, nil, models.RegradeRequestDTO{Reason: "Why?"}, ErrNotRegradable},
+		{"no reason", &models.Attempt{ID: 1, UserID: 5, Status: models.AttemptGraded}, nil, models.RegradeRequestDTO{Reason: "  "}, ErrInvalidRegradeRequest},
+		{"answer of another attempt", &models.Attempt{ID: 1, UserID: 5, Status: models.AttemptGraded}, nil, models.RegradeRequestDTO{AnswerID: &otherAnswer, Reason: "Why?"}, ErrInvalidRegradeRequest},
+		{"request already pending", &models.Attempt{ID: 1, UserID: 5, Status: models.AttemptGraded},
+			[]models.RegradeRequest{{ID: 2, AttemptID: 1, Status: models.RegradePending}}, models.RegradeRequestDTO{Reason: "Why?"}, ErrRegradePending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAttemptRepo := new(MockAttemptRepository)
+			service := NewStudentService(nil, mockAttemptRepo, nil, nil, nil, nil, nil, zaptest.NewLogger(t))
+			mockAttemptRepo.On("FindByID", uint(1)).Return(tt.attempt, nil)
+			mockAttemptRepo.On("FindRegradeRequestsByAttempt", uint(1)).Return(tt.existing, nil)
+
+			_, err := service.RequestRegrade(1, 5, tt.request)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			mockAttemptRepo.AssertNotCalled(t, "CreateRegradeRequest", mock.Anything)
+		})
+	}
+}
+
+func TestStudentService_GetRegradeRequests(t *testing.T) {
+	mockAttemptRepo := new(MockAttemptRepository)
+	service := NewStudentService(nil, mockAttemptRepo, nil, nil, nil, nil, nil, zaptest.NewLogger(t))
+
+	requests := []models.RegradeRequest{{ID: 2, AttemptID: 1, Status: models.RegradeAccepted, Response: "Fixed"}}
+	mockAttemptRepo.On("FindByID", uint(1)).Return(&models.Attempt{ID: 1, UserID: 5}, nil)
+	mockAttemptRepo.On("FindRegradeRequestsByAttempt", uint(1)).Return(requests, nil)
+
+	result, err := service.GetRegradeRequests(1, 5)
+	require.NoError(t, err)
+	assert.Equal(t, requests, result)
+
+	// Học sinh khác không xem được yêu cầu chấm lại
+	_, err = service.GetRegradeRequests(1, 6)
+	assert.ErrorIs(t, err, ErrAttemptNotFound)
+}
@@ -0,0 +1,360 @@
+package rest
+
+import (
+	"assessment_service/internal/middleware"
+	models "assessment_service/internal/model"
+	"assessment_service/internal/users/service"
+	"assessment_service/internal/util"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type UserHandler struct {
+	userService service.UserService
+	log         *zap.Logger
+}
+
+func NewUserHandler(userService service.UserService, log *zap.Logger) *UserHandler {
+	return &UserHandler{userService: userService, log: log}
+}
+
+// currentUser returns the ID and role of the caller from the request principal
+func currentUser(r *http.Request) (uint, string, bool) {
+	principal, ok := middleware.PrincipalFromRequest(r)
+	if !ok {
+		return 0, "", false
+	}
+
+	return principal.UserID, principal.Role, true
+}
+
+// resolveTarget parses the {id} path variable and checks that the caller is either that user or an admin
+func (h *UserHandler) resolveTarget(w http.ResponseWriter, r *http.Request, fn string) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.log.Error("["+fn+"] invalid user ID", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid user ID",
+		}, http.StatusBadRequest)
+		return 0, false
+	}
+
+	callerID, role, ok := currentUser(r)
+	if !ok {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "UNAUTHORIZED",
+			"message": "Unauthorized",
+		}, http.StatusUnauthorized)
+		return 0, false
+	}
+
+	if role != "admin" && callerID != uint(id) {
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "FORBIDDEN",
+			"message": "You can only access your own account",
+		}, http.StatusForbidden)
+		return 0, false
+	}
+
+	return uint(id), true
+}
+
+// writeError maps service errors to HTTP responses
+func (h *UserHandler) writeError(w http.ResponseWriter, fn string, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "NOT_FOUND",
+			"message": err.Error(),
+		}, http.StatusNotFound)
+	case errors.Is(err, service.ErrEmailAlreadyExists),
+		errors.Is(err, service.ErrInvalidRole),
+		errors.Is(err, service.ErrInvalidStatus),
+		errors.Is(err, service.ErrInvalidPassword),
+		errors.Is(err, service.ErrMissingRequiredField),
+		errors.Is(err, util.ErrPasswordTooShort):
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": err.Error(),
+		}, http.StatusBadRequest)
+	default:
+		h.log.Error("["+fn+"] "+message, zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "ERROR",
+			"message": message,
+		}, http.StatusInternalServerError)
+	}
+}
+
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	params := util.GetPaginationParams(r)
+
+	if role := r.URL.Query().Get("role"); role != "" {
+		if normalized, ok := service.NormalizeRole(role); ok {
+			params.Filters["role"] = normalized
+		}
+	}
+
+	if status := r.URL.Query().Get("status"); status != "" {
+		if normalized, ok := service.NormalizeStatus(status); ok {
+			params.Filters["status"] = normalized
+		}
+	}
+
+	users, total, err := h.userService.ListUsers(params)
+	if err != nil {
+		h.writeError(w, "ListUsers", err, "Failed to list users")
+		return
+	}
+
+	util.ResponseInterface(w, util.CreatePaginationResponse(users, total, params), http.StatusOK)
+}
+
+func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name     string `json:"name" binding:"required"`
+		Email    string `json:"email" binding:"required"`
+		Role     string `json:"role" binding:"required"`
+		Status   string `json:"status"`
+		Phone    string `json:"phone"`
+		Address  string `json:"address"`
+		Password string `json:"password" binding:"required"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Password == "" {
+		h.log.Error("[CreateUser] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	user := &models.User{
+		Name:    req.Name,
+		Email:   req.Email,
+		Role:    req.Role,
+		Status:  req.Status,
+		Phone:   req.Phone,
+		Address: req.Address,
+	}
+
+	if err := h.userService.CreateUser(user, req.Password); err != nil {
+		h.writeError(w, "CreateUser", err, "Failed to create user")
+		return
+	}
+
+	util.ResponseInterface(w, user, http.StatusCreated)
+}
+
+func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.resolveTarget(w, r, "GetUser")
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.GetUser(id)
+	if err != nil {
+		h.writeError(w, "GetUser", err, "Failed to get user")
+		return
+	}
+
+	util.ResponseInterface(w, user, http.StatusOK)
+}
+
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.log.Error("[UpdateUser] invalid user ID", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid user ID",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	var req models.UserUpdateDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Error("[UpdateUser] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userService.UpdateUser(uint(id), req)
+	if err != nil {
+		h.writeError(w, "UpdateUser", err, "Failed to update user")
+		return
+	}
+
+	util.ResponseInterface(w, user, http.StatusOK)
+}
+
+func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.log.Error("[DeleteUser] invalid user ID", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid user ID",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.userService.DeleteUser(uint(id)); err != nil {
+		h.writeError(w, "DeleteUser", err, "Failed to delete user")
+		return
+	}
+
+	util.ResponseMap(w, map[string]interface{}{
+		"message": "User deleted successfully",
+	}, http.StatusOK)
+}
+
+func (h *UserHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.log.Error("[ChangeRole] invalid user ID", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid user ID",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Role string `json:"role" binding:"required"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Role == "" {
+		h.log.Error("[ChangeRole] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userService.ChangeRole(uint(id), req.Role)
+	if err != nil {
+		h.writeError(w, "ChangeRole", err, "Failed to change role")
+		return
+	}
+
+	util.ResponseInterface(w, user, http.StatusOK)
+}
+
+func (h *UserHandler) ActivateUser(w http.ResponseWriter, r *http.Request) {
+	h.setStatus(w, r, "ActivateUser", service.STATUS_ACTIVE)
+}
+
+func (h *UserHandler) DeactivateUser(w http.ResponseWriter, r *http.Request) {
+	h.setStatus(w, r, "DeactivateUser", service.STATUS_INACTIVE)
+}
+
+func (h *UserHandler) setStatus(w http.ResponseWriter, r *http.Request, fn string, status string) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		h.log.Error("["+fn+"] invalid user ID", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid user ID",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userService.SetStatus(uint(id), status)
+	if err != nil {
+		h.writeError(w, fn, err, "Failed to update user status")
+		return
+	}
+
+	util.ResponseInterface(w, user, http.StatusOK)
+}
+
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.resolveTarget(w, r, "ChangePassword")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		CurrentPassword string `json:"currentPassword"`
+		NewPassword     string `json:"newPassword" binding:"required"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.NewPassword == "" {
+		h.log.Error("[ChangePassword] invalid input", zap.Error(err))
+		util.ResponseMap(w, map[string]interface{}{
+			"status":  "BAD_REQUEST",
+			"message": "Invalid input",
+		}, http.StatusBadRequest)
+		return
+	}
+
+	// Users must prove they know their current password; admins resetting someone else's do not
+	callerID, role, _ := currentUser(r)
+	var err error
+	if role == "admin" && callerID != id {
+		err = h.userService.SetPassword(id, req.NewPassword)
+	} else {
+		err = h.userService.ChangePassword(id, req.CurrentPassword, req.NewPassword)
+	}
+
+	if err != nil {
+		h.writeError(w, "ChangePassword", err, "Failed to change password")
+		return
+	}
+
+	util.ResponseMap(w, map[string]interface{}{
+		"message": "Password changed successfully",
+	}, http.StatusOK)
+}
+
+func (h *UserHandler) GetUserActivity(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.resolveTarget(w, r, "GetUserActivity")
+	if !ok {
+		return
+	}
+
+	params := util.GetPaginationParams(r)
+	if from := r.URL.Query().Get("from"); from != "" {
+		params.Filters["from"] = from
+	}
+	if to := r.URL.Query().Get("to"); to != "" {
+		params.Filters["to"] = to
+	}
+
+	activities, total, err := h.userService.GetUserActivity(id, params)
+	if err != nil {
+		h.writeError(w, "GetUserActivity", err, "Failed to get user activity")
+		return
+	}
+
+	util.ResponseInterface(w, util.CreatePaginationResponse(activities, total, params), http.StatusOK)
+}
+
+func (h *UserHandler) GetUserAssessments(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.resolveTarget(w, r, "GetUserAssessments")
+	if !ok {
+		return
+	}
+
+	params := util.GetPaginationParams(r)
+
+	assessments, total, err := h.userService.GetUserAssessments(id, params)
+	if err != nil {
+		h.writeError(w, "GetUserAssessments", err, "Failed to get user assessments")
+		return
+	}
+
+	util.ResponseInterface(w, util.CreatePaginationResponse(assessments, total, params), http.StatusOK)
+}
@@ -0,0 +1,315 @@
+package rest
+
+import (
+	"assessment_service/internal/middleware"
+	models "assessment_service/internal/model"
+	"assessment_service/internal/users/service"
+	"assessment_service/internal/util"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// --- Mock UserService ---
+type MockUserService struct {
+	mock.Mock
+}
+
+func (m *MockUserService) ListUsers(params util.PaginationParams) ([]models.User, int64, error) {
+	args := m.Called(params)
+	users, _ := args.Get(0).([]models.User)
+	total, _ := args.Get(1).(int64)
+	return users, total, args.Error(2)
+}
+
+func (m *MockUserService) GetUser(id uint) (*models.User, error) {
+	args := m.Called(id)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserService) CreateUser(user *models.User, password string) error {
+	args := m.Called(user, password)
+	return args.Error(0)
+}
+
+func (m *MockUserService) UpdateUser(id uint, update models.UserUpdateDTO) (*models.User, error) {
+	args := m.Called(id, update)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserService) DeleteUser(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ChangeRole(id uint, role string) (*models.User, error) {
+	args := m.Called(id, role)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserService) SetStatus(id uint, status string) (*models.User, error) {
+	args := m.Called(id, status)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserService) ChangePassword(id uint, currentPassword, newPassword string) error {
+	args := m.Called(id, currentPassword, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockUserService) SetPassword(id uint, newPassword string) error {
+	args := m.Called(id, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockUserService) GetUserActivity(id uint, params util.PaginationParams) ([]models.Activity, int64, error) {
+	args := m.Called(id, params)
+	activities, _ := args.Get(0).([]models.Activity)
+	total, _ := args.Get(1).(int64)
+	return activities, total, args.Error(2)
+}
+
+func (m *MockUserService) GetUserAssessments(id uint, params util.PaginationParams) ([]models.Assessment, int64, error) {
+	args := m.Called(id, params)
+	assessments, _ := args.Get(0).([]models.Assessment)
+	total, _ := args.Get(1).(int64)
+	return assessments, total, args.Error(2)
+}
+
+func newTestRouter(handler *UserHandler) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/users", handler.ListUsers).Methods(http.MethodGet)
+	router.HandleFunc("/users", handler.CreateUser).Methods(http.MethodPost)
+	router.HandleFunc("/users/{id}", handler.GetUser).Methods(http.MethodGet)
+	router.HandleFunc("/users/{id}", handler.UpdateUser).Methods(http.MethodPut)
+	router.HandleFunc("/users/{id}", handler.DeleteUser).Methods(http.MethodDelete)
+	router.HandleFunc("/users/{id}/role", handler.ChangeRole).Methods(http.MethodPut)
+	router.HandleFunc("/users/{id}/deactivate", handler.DeactivateUser).Methods(http.MethodPost)
+	router.HandleFunc("/users/{id}/change-password", handler.ChangePassword).Methods(http.MethodPost)
+	router.HandleFunc("/users/{id}/activity", handler.GetUserActivity).Methods(http.MethodGet)
+	router.HandleFunc("/users/{id}/assessments", handler.GetUserAssessments).Methods(http.MethodGet)
+	return router
+}
+
+// Helper function to create a request carrying the JWT claims set by AuthMiddleware
+func requestAs(method, url, body, userID, role string) *http.Request {
+	req := httptest.NewRequest(method, url, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	id, _ := strconv.ParseUint(userID, 10, 32)
+	ctx := middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: uint(id), Role: role})
+	return req.WithContext(ctx)
+}
+
+func serve(router *mux.Router, req *http.Request) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestUserHandler_ListUsers(t *testing.T) {
+	mockService := new(MockUserService)
+	router := newTestRouter(NewUserHandler(mockService, zaptest.NewLogger(t)))
+
+	mockService.On("ListUsers", mock.MatchedBy(func(p util.PaginationParams) bool {
+		return p.Filters["role"] == "teacher" && p.Filters["status"] == service.STATUS_INACTIVE && p.Search == "mike"
+	})).Return([]models.User{{ID: 1}}, int64(1), nil)
+
+	rr := serve(router, requestAs(http.MethodGet, "/users?role=Teacher&status=inactive&search=mike", "", "1", "admin"))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, float64(1), body["totalElements"])
+	mockService.AssertExpectations(t)
+}
+
+func TestUserHandler_CreateUser(t *testing.T) {
+	mockService := new(MockUserService)
+	router := newTestRouter(NewUserHandler(mockService, zaptest.NewLogger(t)))
+
+	mockService.On("CreateUser", mock.MatchedBy(func(u *models.User) bool {
+		return u.Email == "mike@example.com" && u.Role == "Student"
+	}), "securePassword123").Return(nil)
+
+	body := `{"name":"Mike","email":"mike@example.com","role":"Student","status":"Active","password":"securePassword123"}`
+	rr := serve(router, requestAs(http.MethodPost, "/users", body, "1", "admin"))
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "securePassword123")
+}
+
+func TestUserHandler_CreateUser_EmailTaken(t *testing.T) {
+	mockService := new(MockUserService)
+	router := newTestRouter(NewUserHandler(mockService, zaptest.NewLogger(t)))
+
+	mockService.On("CreateUser", mock.Anything, "securePassword123").Return(service.ErrEmailAlreadyExists)
+
+	body := `{"name":"Mike","email":"mike@example.com","role":"Student","password":"securePassword123"}`
+	rr := serve(router, requestAs(http.MethodPost, "/users", body, "1", "admin"))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestUserHandler_GetUser(t *testing.T) {
+	t.Run("Self", func(t *testing.T) {
+		mockService := new(MockUserService)
+		router := newTestRouter(NewUserHandler(mockService, zaptest.NewLogger(t)))
+		mockService.On("GetUser", uint(5)).Return(&models.User{ID: 5}, nil)
+
+		rr := serve(router, requestAs(http.MethodGet, "/users/5", "", "5", "student"))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("OtherUserForbidden", func(t *testing.T) {
+		mockService := new(MockUserService)
+		router := newTestRouter(NewUserHandler(mockService, zaptest.NewLogger(t)))
+
+		rr := serve(router, requestAs(http.MethodGet, "/users/6", "", "5", "student"))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockService.AssertNotCalled(t, "GetUser", mock.Anything)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockService := new(MockUserService)
+		router := newTestRouter(NewUserHandler(mockService, zaptest.NewLogger(t)))
+		mockService.On("GetUser", uint(6)).Return(nil, service.ErrUserNotFound)
+
+		rr := serve(router, requestAs(http.MethodGet, "/users/6", "", "1", "admin"))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		mockService := new(MockUserService)
+		router := newTestRouter(NewUserHandler(mockService, zaptest.NewLogger(t)))
+
+		rr := serve(router, requestAs(http.MethodGet, "/users/abc", "", "1", "admin"))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestUserHandler_UpdateUser(t *testing.T) {
+	mockService := new(MockUserService)
+	router := newTestRouter(NewUserHandler(mockService, zaptest.NewLogger(t)))
+
+	mockService.On("UpdateUser", uint(5), mock.MatchedBy(func(dto models.UserUpdateDTO) bool {
+		return dto.Name != nil && *dto.Name == "Michael" && dto.Email == nil
+	})).Return(&models.User{ID: 5, Name: "Michael"}, nil)
+
+	rr := serve(router, requestAs(http.MethodPut, "/users/5", `{"name":"Michael"}`, "1", "admin"))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestUserHandler_DeleteUser(t *testing.T) {
+	mockService := new(MockUserService)
+	router := newTestRouter(NewUserHandler(mockService, zaptest.NewLogger(t)))
+
+	mockService.On("DeleteUser", uint(5)).Return(errors.New("db error"))
+
+	rr := serve(router, requestAs(http.MethodDelete, "/users/5", "", "1", "admin"))
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+}
+
+func TestUserHandler_ChangeRole(t *testing.T) {
+	mockService := new(MockUserService)
+	router := newTestRouter(NewUserHandler(mockService, zaptest.NewLogger(t)))
+
+	mockService.On("ChangeRole", uint(5), "superuser").Return(nil, service.ErrInvalidRole)
+
+	rr := serve(router, requestAs(http.MethodPut, "/users/5/role", `{"role":"superuser"}`, "1", "admin"))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestUserHandler_DeactivateUser(t *testing.T) {
+	mockService := new(MockUserService)
+	router := newTestRouter(NewUserHandler(mockService, zaptest.NewLogger(t)))
+
+	mockService.On("SetStatus", uint(5), service.STATUS_INACTIVE).Return(&models.User{ID: 5, Status: service.STATUS_INACTIVE}, nil)
+
+	rr := serve(router, requestAs(http.MethodPost, "/users/5/deactivate", "", "1", "admin"))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestUserHandler_ChangePassword(t *testing.T) {
+	t.Run("Self", func(t *testing.T) {
+		mockService := new(MockUserService)
+		router := newTestRouter(NewUserHandler(mockService, zaptest.NewLogger(t)))
+		mockService.On("ChangePassword", uint(5), "oldPassword123", "newPassword456").Return(nil)
+
+		body := `{"currentPassword":"oldPassword123","newPassword":"newPassword456"}`
+		rr := serve(router, requestAs(http.MethodPost, "/users/5/change-password", body, "5", "student"))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("SelfWrongPassword", func(t *testing.T) {
+		mockService := new(MockUserService)
+		router := newTestRouter(NewUserHandler(mockService, zaptest.NewLogger(t)))
+		mockService.On("ChangePassword", uint(5), "nope", "newPassword456").Return(service.ErrInvalidPassword)
+
+		body := `{"currentPassword":"nope","newPassword":"newPassword456"}`
+		rr := serve(router, requestAs(http.MethodPost, "/users/5/change-password", body, "5", "student"))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("AdminForOtherUser", func(t *testing.T) {
+		mockService := new(MockUserService)
+		router := newTestRouter(NewUserHandler(mockService, zaptest.NewLogger(t)))
+		mockService.On("SetPassword", uint(5), "newPassword456").Return(nil)
+
+		rr := serve(router, requestAs(http.MethodPost, "/users/5/change-password", `{"newPassword":"newPassword456"}`, "1", "admin"))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertNotCalled(t, "ChangePassword", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserHandler_GetUserActivity(t *testing.T) {
+	mockService := new(MockUserService)
+	router := newTestRouter(NewUserHandler(mockService, zaptest.NewLogger(t)))
+
+	mockService.On("GetUserActivity", uint(5), mock.MatchedBy(func(p util.PaginationParams) bool {
+		return p.Filters["from"] == "2025-01-01" && p.Filters["to"] == "2025-02-01"
+	})).Return([]models.Activity{{ID: 1}}, int64(1), nil)
+
+	rr := serve(router, requestAs(http.MethodGet, "/users/5/activity?from=2025-01-01&to=2025-02-01", "", "1", "admin"))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestUserHandler_GetUserAssessments(t *testing.T) {
+	mockService := new(MockUserService)
+	router := newTestRouter(NewUserHandler(mockService, zaptest.NewLogger(t)))
+
+	mockService.On("GetUserAssessments", uint(5), mock.AnythingOfType("util.PaginationParams")).Return([]models.Assessment{{ID: 8}}, int64(1), nil)
+
+	rr := serve(router, requestAs(http.MethodGet, "/users/5/assessments", "", "5", "student"))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
@@ -0,0 +1,345 @@
+package service
+
+import (
+	repository4 "assessment_service/internal/activity/repository"
+	repository3 "assessment_service/internal/assessments/repository"
+	repository2 "assessment_service/internal/auth/repository"
+	models "assessment_service/internal/model"
+	"assessment_service/internal/users/repository"
+	"assessment_service/internal/util"
+	"errors"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserNotFound         = errors.New("user not found")
+	ErrEmailAlreadyExists   = errors.New("email already registered")
+	ErrInvalidRole          = errors.New("role must be one of admin, teacher, student")
+	ErrInvalidStatus        = errors.New("status must be Active or Inactive")
+	ErrInvalidPassword      = errors.New("current password is incorrect")
+	ErrMissingRequiredField = errors.New("name and email are required")
+)
+
+const (
+	STATUS_ACTIVE   = "Active"
+	STATUS_INACTIVE = "Inactive"
+)
+
+var validRoles = map[string]bool{
+	"admin":   true,
+	"teacher": true,
+	"student": true,
+}
+
+// NormalizeRole lowercases a role and reports whether it is a known one
+func NormalizeRole(role string) (string, bool) {
+	role = strings.ToLower(strings.TrimSpace(role))
+	return role, validRoles[role]
+}
+
+// NormalizeStatus maps any casing of active/inactive to the stored form
+func NormalizeStatus(status string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "active":
+		return STATUS_ACTIVE, true
+	case "inactive":
+		return STATUS_INACTIVE, true
+	default:
+		return "", false
+	}
+}
+
+type UserService interface {
+	ListUsers(params util.PaginationParams) ([]models.User, int64, error)
+	GetUser(id uint) (*models.User, error)
+	CreateUser(user *models.User, password string) error
+	UpdateUser(id uint, update models.UserUpdateDTO) (*models.User, error)
+	DeleteUser(id uint) error
+	ChangeRole(id uint, role string) (*models.User, error)
+	SetStatus(id uint, status string) (*models.User, error)
+	ChangePassword(id uint, currentPassword, newPassword string) error
+	SetPassword(id uint, newPassword string) error
+	GetUserActivity(id uint, params util.PaginationParams) ([]models.Activity, int64, error)
+	GetUserAssessments(id uint, params util.PaginationParams) ([]models.Assessment, int64, error)
+}
+
+type userService struct {
+	userRepo         repository.UserRepository
+	activityRepo     repository4.ActivityRepository
+	assessmentRepo   repository3.AssessmentRepository
+	refreshTokenRepo repository2.RefreshTokenRepository
+	log              *zap.Logger
+}
+
+func NewUserService(
+	userRepo repository.UserRepository,
+	activityRepo repository4.ActivityRepository,
+	assessmentRepo repository3.AssessmentRepository,
+	refreshTokenRepo repository2.RefreshTokenRepository,
+	log *zap.Logger,
+) UserService {
+	return &userService{
+		userRepo:         userRepo,
+		activityRepo:     activityRepo,
+		assessmentRepo:   assessmentRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		log:              log,
+	}
+}
+
+func (s *userService) ListUsers(params util.PaginationParams) ([]models.User, int64, error) {
+	users, total, err := s.userRepo.List(params)
+	if err != nil {
+		s.log.Error("[ListUsers] failed to list users", zap.Error(err))
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+func (s *userService) GetUser(id uint) (*models.User, error) {
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		s.log.Error("[GetUser] failed to find user", zap.Error(err))
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *userService) CreateUser(user *models.User, password string) error {
+	user.Name = strings.TrimSpace(user.Name)
+	user.Email = strings.TrimSpace(strings.ToLower(user.Email))
+	if user.Name == "" || user.Email == "" {
+		return ErrMissingRequiredField
+	}
+
+	role, ok := NormalizeRole(user.Role)
+	if !ok {
+		return ErrInvalidRole
+	}
+	user.Role = role
+
+	if user.Status == "" {
+		user.Status = STATUS_ACTIVE
+	}
+	status, ok := NormalizeStatus(user.Status)
+	if !ok {
+		return ErrInvalidStatus
+	}
+	user.Status = status
+
+	if err := s.ensureEmailAvailable(user.Email, 0); err != nil {
+		return err
+	}
+
+	hashed, err := util.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	user.Password = hashed
+
+	if err := s.userRepo.Create(user); err != nil {
+		s.log.Error("[CreateUser] failed to create user", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (s *userService) UpdateUser(id uint, update models.UserUpdateDTO) (*models.User, error) {
+	user, err := s.GetUser(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		name := strings.TrimSpace(*update.Name)
+		if name == "" {
+			return nil, ErrMissingRequiredField
+		}
+		user.Name = name
+	}
+
+	if update.Email != nil {
+		email := strings.TrimSpace(strings.ToLower(*update.Email))
+		if email == "" {
+			return nil, ErrMissingRequiredField
+		}
+		if email != user.Email {
+			if err := s.ensureEmailAvailable(email, user.ID); err != nil {
+				return nil, err
+			}
+		}
+		user.Email = email
+	}
+
+	if update.Role != nil {
+		role, ok := NormalizeRole(*update.Role)
+		if !ok {
+			return nil, ErrInvalidRole
+		}
+		user.Role = role
+	}
+
+	deactivated := false
+	if update.Status != nil {
+		status, ok := NormalizeStatus(*update.Status)
+		if !ok {
+			return nil, ErrInvalidStatus
+		}
+		deactivated = status == STATUS_INACTIVE && user.Status != STATUS_INACTIVE
+		user.Status = status
+	}
+
+	if update.Phone != nil {
+		user.Phone = strings.TrimSpace(*update.Phone)
+	}
+
+	if update.Address != nil {
+		user.Address = strings.TrimSpace(*update.Address)
+	}
+
+	if update.Department != nil {
+		user.Department = strings.TrimSpace(*update.Department)
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		s.log.Error("[UpdateUser] failed to update user", zap.Error(err))
+		return nil, err
+	}
+
+	if deactivated {
+		s.revokeSessions(user.ID)
+	}
+
+	return user, nil
+}
+
+func (s *userService) DeleteUser(id uint) error {
+	if _, err := s.GetUser(id); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.Delete(id); err != nil {
+		s.log.Error("[DeleteUser] failed to delete user", zap.Error(err))
+		return err
+	}
+
+	s.revokeSessions(id)
+
+	return nil
+}
+
+func (s *userService) ChangeRole(id uint, role string) (*models.User, error) {
+	return s.UpdateUser(id, models.UserUpdateDTO{Role: &role})
+}
+
+func (s *userService) SetStatus(id uint, status string) (*models.User, error) {
+	return s.UpdateUser(id, models.UserUpdateDTO{Status: &status})
+}
+
+// ChangePassword is used by users changing their own password, so the current one must match
+func (s *userService) ChangePassword(id uint, currentPassword, newPassword string) error {
+	user, err := s.GetUser(id)
+	if err != nil {
+		return err
+	}
+
+	if !util.CheckPassword(user.Password, currentPassword) {
+		return ErrInvalidPassword
+	}
+
+	return s.updatePassword(user, newPassword)
+}
+
+// SetPassword lets an admin override a user's password without knowing the current one
+func (s *userService) SetPassword(id uint, newPassword string) error {
+	user, err := s.GetUser(id)
+	if err != nil {
+		return err
+	}
+
+	return s.updatePassword(user, newPassword)
+}
+
+func (s *userService) GetUserActivity(id uint, params util.PaginationParams) ([]models.Activity, int64, error) {
+	if _, err := s.GetUser(id); err != nil {
+		return nil, 0, err
+	}
+
+	activities, total, err := s.activityRepo.FindByUserID(id, params)
+	if err != nil {
+		s.log.Error("[GetUserActivity] failed to get user activity", zap.Error(err))
+		return nil, 0, err
+	}
+
+	return activities, total, nil
+}
+
+func (s *userService) GetUserAssessments(id uint, params util.PaginationParams) ([]models.Assessment, int64, error) {
+	if _, err := s.GetUser(id); err != nil {
+		return nil, 0, err
+	}
+
+	// The query joins attempts, so unqualified columns such as created_at are ambiguous
+	if params.SortBy != "" && !strings.Contains(params.SortBy, ".") {
+		params.SortBy = "assessments." + params.SortBy
+	}
+
+	assessments, total, err := s.assessmentRepo.GetAssessmentHasAttemptByUser(params, id)
+	if err != nil {
+		s.log.Error("[GetUserAssessments] failed to get user assessments", zap.Error(err))
+		return nil, 0, err
+	}
+
+	return assessments, total, nil
+}
+
+func (s *userService) ensureEmailAvailable(email string, currentUserID uint) error {
+	existing, err := s.userRepo.FindByEmail(email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.log.Error("failed to check email", zap.Error(err))
+		return err
+	}
+
+	if existing != nil && existing.ID != currentUserID {
+		return ErrEmailAlreadyExists
+	}
+
+	return nil
+}
+
+func (s *userService) updatePassword(user *models.User, newPassword string) error {
+	hashed, err := util.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	user.Password = hashed
+	if err := s.userRepo.Update(user); err != nil {
+		s.log.Error("failed to update password", zap.Error(err))
+		return err
+	}
+
+	// Sessions opened with the old password must not outlive it
+	if err := s.refreshTokenRepo.RevokeAllByUserID(user.ID); err != nil {
+		s.log.Error("failed to revoke refresh tokens", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// revokeSessions logs the user out everywhere; failures are logged but do not fail the change itself
+func (s *userService) revokeSessions(userID uint) {
+	if err := s.refreshTokenRepo.RevokeAllByUserID(userID); err != nil {
+		s.log.Error("failed to revoke refresh tokens", zap.Uint("userID", userID), zap.Error(err))
+	}
+}
@@ -0,0 +1,470 @@
+package service
+
+import (
+	models "assessment_service/internal/model"
+	"assessment_service/internal/util"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+// --- Mock UserRepository ---
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) Create(user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindByID(id uint) (*models.User, error) {
+	args := m.Called(id)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserRepository) FindByEmail(email string) (*models.User, error) {
+	args := m.Called(email)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+func (m *MockUserRepository) Update(user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) List(params util.PaginationParams) ([]models.User, int64, error) {
+	args := m.Called(params)
+	users, _ := args.Get(0).([]models.User)
+	count, _ := args.Get(1).(int64)
+	return users, count, args.Error(2)
+}
+
+func (m *MockUserRepository) UpdateLastLogin(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUserStats() (int64, int64, error) {
+	args := m.Called()
+	active, _ := args.Get(0).(int64)
+	inactive, _ := args.Get(1).(int64)
+	return active, inactive, args.Error(2)
+}
+
+func (m *MockUserRepository) CountAll() (int64, error) {
+	args := m.Called()
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
+func (m *MockUserRepository) GetNewUsersCount(days int) (int64, error) {
+	args := m.Called(days)
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
+func (m *MockUserRepository) GetListUserByAssessment(params util.PaginationParams, assessmentID uint) ([]models.User, int64, error) {
+	args := m.Called(params, assessmentID)
+	users, _ := args.Get(0).([]models.User)
+	count, _ := args.Get(1).(int64)
+	return users, count, args.Error(2)
+}
+
+// --- Mock RefreshTokenRepository ---
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefreshTokenRepository) Create(token *models.RefreshToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) FindByToken(tokenHash string) (*models.RefreshToken, error) {
+	args := m.Called(tokenHash)
+	token, _ := args.Get(0).(*models.RefreshToken)
+	return token, args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) Revoke(id uint) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) RevokeAllByUserID(userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+// --- Mock AssessmentRepository ---
+type MockAssessmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAssessmentRepository) Create(assessment *models.Assessment) error {
+	args := m.Called(assessment)
+	return args.Error(0)
+}
+func (m *MockAssessmentRepository) FindByID(id uint) (*models.Assessment, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Assessment), args.Error(1)
+}
+func (m *MockAssessmentRepository) Update(assessment *models.Assessment) error {
+	args := m.Called(assessment)
+	return args.Error(0)
+}
+func (m *MockAssessmentRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+func (m *MockAssessmentRepository) List(params util.PaginationParams) ([]models.Assessment, int64, error) {
+	args := m.Called(params)
+	return args.Get(0).([]models.Assessment), args.Get(1).(int64), args.Error(2)
+}
+func (m *MockAssessmentRepository) FindRecent(limit int) ([]models.Assessment, error) {
+	args := m.Called(limit)
+	return args.Get(0).([]models.Assessment), args.Error(1)
+}
+func (m *MockAssessmentRepository) GetStatistics() (map[string]interface{}, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]interface{}), args.Error(1)
+}
+func (m *MockAssessmentRepository) UpdateSettings(id uint, settings *models.AssessmentSettings) error {
+	args := m.Called(id, settings)
+	return args.Error(0)
+}
+func (m *MockAssessmentRepository) GetResults(id uint, params util.PaginationParams) ([]map[string]interface{}, int64, error) {
+	args := m.Called(id, params)
+	return args.Get(0).([]map[string]interface{}), args.Get(1).(int64), args.Error(2)
+}
+func (m *MockAssessmentRepository) Duplicate(assessment *models.Assessment) error {
+	args := m.Called(assessment)
+	return args.Error(0)
+}
+func (m *MockAssessmentRepository) GetAssessmentHasAttemptByUser(params util.PaginationParams, userID uint) ([]models.Assessment, int64, error) {
+	args := m.Called(params, userID)
+	return args.Get(0).([]models.Assessment), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockAssessmentRepository) UpdateStatus(transition *models.AssessmentStatusTransition) (bool, error) {
+	args := m.Called(transition)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAssessmentRepository) ListStatusHistory(assessmentID uint) ([]models.AssessmentStatusTransition, error) {
+	args := m.Called(assessmentID)
+	transitions, _ := args.Get(0).([]models.AssessmentStatusTransition)
+	return transitions, args.Error(1)
+}
+
+func (m *MockAssessmentRepository) ActivateScheduled(now time.Time) (int64, error) {
+	args := m.Called(now)
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
+func (m *MockAssessmentRepository) CloseEnded(now time.Time) (int64, error) {
+	args := m.Called(now)
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
+// --- Mock ActivityRepository ---
+type MockActivityRepository struct {
+	mock.Mock
+}
+
+func (m *MockActivityRepository) Create(activity *models.Activity) error {
+	args := m.Called(activity)
+	return args.Error(0)
+}
+func (m *MockActivityRepository) FindByUserID(userID uint, params util.PaginationParams) ([]models.Activity, int64, error) {
+	args := m.Called(userID, params)
+	return args.Get(0).([]models.Activity), args.Get(1).(int64), args.Error(2)
+}
+func (m *MockActivityRepository) GetDailyActiveUsers(days int) ([]map[string]interface{}, error) {
+	args := m.Called(days)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]map[string]interface{}), args.Error(1)
+}
+func (m *MockActivityRepository) GetActivityByHour() ([]map[string]interface{}, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]map[string]interface{}), args.Error(1)
+}
+func (m *MockActivityRepository) GetActivityByType() ([]map[string]interface{}, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]map[string]interface{}), args.Error(1)
+}
+func (m *MockActivityRepository) GetTotalActiveUsers() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *MockActivityRepository) GetRecentActivity(hours int) ([]map[string]interface{}, error) {
+	args := m.Called(hours)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]map[string]interface{}), args.Error(1)
+}
+func (m *MockActivityRepository) GetActiveUsers(minutes int) (int64, error) {
+	args := m.Called(minutes)
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *MockActivityRepository) BulkCreate(activities []models.Activity) error {
+	args := m.Called(activities)
+	return args.Error(0)
+}
+func (m *MockActivityRepository) FindByAssessmentID(assessmentID uint, params util.PaginationParams) ([]models.Activity, int64, error) {
+	args := m.Called(assessmentID, params)
+	return args.Get(0).([]models.Activity), args.Get(1).(int64), args.Error(2)
+}
+func (m *MockActivityRepository) FindSuspiciousActivity(userID uint, attemptID uint, params util.PaginationParams) ([]models.SuspiciousActivity, int64, error) {
+	args := m.Called(userID, attemptID, params)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]models.SuspiciousActivity), args.Get(1).(int64), args.Error(2)
+}
+func (m *MockActivityRepository) CountByPeriod(days int) (int64, error) {
+	args := m.Called(days)
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *MockActivityRepository) GetTrending() ([]map[string]interface{}, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]map[string]interface{}), args.Error(1)
+}
+
+type testUserService struct {
+	UserService
+	userRepo       *MockUserRepository
+	activityRepo   *MockActivityRepository
+	assessmentRepo *MockAssessmentRepository
+	tokenRepo      *MockRefreshTokenRepository
+}
+
+func newTestUserService(t *testing.T) testUserService {
+	userRepo := new(MockUserRepository)
+	activityRepo := new(MockActivityRepository)
+	assessmentRepo := new(MockAssessmentRepository)
+	tokenRepo := new(MockRefreshTokenRepository)
+	return testUserService{
+		UserService:    NewUserService(userRepo, activityRepo, assessmentRepo, tokenRepo, zaptest.NewLogger(t)),
+		userRepo:       userRepo,
+		activityRepo:   activityRepo,
+		assessmentRepo: assessmentRepo,
+		tokenRepo:      tokenRepo,
+	}
+}
+
+// --- Test Cases ---
+
+func TestUserService_CreateUser(t *testing.T) {
+	svc := newTestUserService(t)
+
+	svc.userRepo.On("FindByEmail", "mike@example.com").Return(nil, gorm.ErrRecordNotFound)
+	svc.userRepo.On("Create", mock.MatchedBy(func(u *models.User) bool {
+		return u.Role == "teacher" && u.Status == STATUS_ACTIVE && util.CheckPassword(u.Password, "securePassword123")
+	})).Return(nil)
+
+	user := &models.User{Name: "Mike", Email: " Mike@Example.com", Role: "Teacher"}
+	err := svc.CreateUser(user, "securePassword123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "mike@example.com", user.Email)
+	svc.userRepo.AssertExpectations(t)
+}
+
+func TestUserService_CreateUser_Errors(t *testing.T) {
+	t.Run("InvalidRole", func(t *testing.T) {
+		svc := newTestUserService(t)
+		err := svc.CreateUser(&models.User{Name: "A", Email: "a@example.com", Role: "superuser"}, "password123")
+		assert.ErrorIs(t, err, ErrInvalidRole)
+	})
+
+	t.Run("InvalidStatus", func(t *testing.T) {
+		svc := newTestUserService(t)
+		err := svc.CreateUser(&models.User{Name: "A", Email: "a@example.com", Role: "student", Status: "Banned"}, "password123")
+		assert.ErrorIs(t, err, ErrInvalidStatus)
+	})
+
+	t.Run("EmailTaken", func(t *testing.T) {
+		svc := newTestUserService(t)
+		svc.userRepo.On("FindByEmail", "a@example.com").Return(&models.User{ID: 2}, nil)
+		err := svc.CreateUser(&models.User{Name: "A", Email: "a@example.com", Role: "student"}, "password123")
+		assert.ErrorIs(t, err, ErrEmailAlreadyExists)
+		svc.userRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestUserService_GetUser_NotFound(t *testing.T) {
+	svc := newTestUserService(t)
+	svc.userRepo.On("FindByID", uint(9)).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := svc.GetUser(9)
+
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestUserService_UpdateUser(t *testing.T) {
+	svc := newTestUserService(t)
+
+	svc.userRepo.On("FindByID", uint(3)).Return(&models.User{ID: 3, Name: "Old", Email: "old@example.com", Role: "student", Status: STATUS_ACTIVE}, nil)
+	svc.userRepo.On("FindByEmail", "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+	svc.userRepo.On("Update", mock.Anything).Return(nil)
+
+	name, email, phone, department := "New", "New@example.com", "+123", " Mathematics "
+	user, err := svc.UpdateUser(3, models.UserUpdateDTO{Name: &name, Email: &email, Phone: &phone, Department: &department})
+
+	require.NoError(t, err)
+	assert.Equal(t, "New", user.Name)
+	assert.Equal(t, "new@example.com", user.Email)
+	assert.Equal(t, "+123", user.Phone)
+	assert.Equal(t, "Mathematics", user.Department)
+	assert.Equal(t, "student", user.Role, "Fields not in the update are untouched")
+	svc.tokenRepo.AssertNotCalled(t, "RevokeAllByUserID", mock.Anything)
+}
+
+func TestUserService_ChangeRole(t *testing.T) {
+	svc := newTestUserService(t)
+
+	svc.userRepo.On("FindByID", uint(3)).Return(&models.User{ID: 3, Role: "student", Status: STATUS_ACTIVE}, nil)
+	svc.userRepo.On("Update", mock.MatchedBy(func(u *models.User) bool { return u.Role == "teacher" })).Return(nil)
+
+	user, err := svc.ChangeRole(3, "TEACHER")
+
+	require.NoError(t, err)
+	assert.Equal(t, "teacher", user.Role)
+}
+
+func TestUserService_SetStatus_DeactivateRevokesSessions(t *testing.T) {
+	svc := newTestUserService(t)
+
+	svc.userRepo.On("FindByID", uint(3)).Return(&models.User{ID: 3, Status: STATUS_ACTIVE}, nil)
+	svc.userRepo.On("Update", mock.Anything).Return(nil)
+	svc.tokenRepo.On("RevokeAllByUserID", uint(3)).Return(nil)
+
+	user, err := svc.SetStatus(3, "inactive")
+
+	require.NoError(t, err)
+	assert.Equal(t, STATUS_INACTIVE, user.Status)
+	svc.tokenRepo.AssertExpectations(t)
+}
+
+func TestUserService_SetStatus_Invalid(t *testing.T) {
+	svc := newTestUserService(t)
+	svc.userRepo.On("FindByID", uint(3)).Return(&models.User{ID: 3, Status: STATUS_ACTIVE}, nil)
+
+	_, err := svc.SetStatus(3, "suspended")
+
+	assert.ErrorIs(t, err, ErrInvalidStatus)
+	svc.userRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	hashed, err := util.HashPassword("oldPassword123")
+	require.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		svc := newTestUserService(t)
+		svc.userRepo.On("FindByID", uint(3)).Return(&models.User{ID: 3, Password: hashed}, nil)
+		svc.userRepo.On("Update", mock.MatchedBy(func(u *models.User) bool {
+			return util.CheckPassword(u.Password, "newPassword456")
+		})).Return(nil)
+		svc.tokenRepo.On("RevokeAllByUserID", uint(3)).Return(nil)
+
+		assert.NoError(t, svc.ChangePassword(3, "oldPassword123", "newPassword456"))
+		svc.tokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("WrongCurrentPassword", func(t *testing.T) {
+		svc := newTestUserService(t)
+		svc.userRepo.On("FindByID", uint(3)).Return(&models.User{ID: 3, Password: hashed}, nil)
+
+		err := svc.ChangePassword(3, "wrong-password", "newPassword456")
+
+		assert.ErrorIs(t, err, ErrInvalidPassword)
+		svc.userRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("AdminOverride", func(t *testing.T) {
+		svc := newTestUserService(t)
+		svc.userRepo.On("FindByID", uint(3)).Return(&models.User{ID: 3, Password: hashed}, nil)
+		svc.userRepo.On("Update", mock.Anything).Return(nil)
+		svc.tokenRepo.On("RevokeAllByUserID", uint(3)).Return(nil)
+
+		assert.NoError(t, svc.SetPassword(3, "newPassword456"))
+	})
+}
+
+func TestUserService_DeleteUser(t *testing.T) {
+	svc := newTestUserService(t)
+
+	svc.userRepo.On("FindByID", uint(3)).Return(&models.User{ID: 3}, nil)
+	svc.userRepo.On("Delete", uint(3)).Return(nil)
+	svc.tokenRepo.On("RevokeAllByUserID", uint(3)).Return(errors.New("db error"))
+
+	// Failing to revoke sessions is logged but does not undo the delete
+	assert.NoError(t, svc.DeleteUser(3))
+	svc.userRepo.AssertExpectations(t)
+}
+
+func TestUserService_GetUserActivity(t *testing.T) {
+	svc := newTestUserService(t)
+	params := util.PaginationParams{Limit: 10, Filters: map[string]interface{}{"from": "2025-01-01"}}
+
+	svc.userRepo.On("FindByID", uint(3)).Return(&models.User{ID: 3}, nil)
+	svc.activityRepo.On("FindByUserID", uint(3), params).Return([]models.Activity{{ID: 1, UserID: 3}}, int64(1), nil)
+
+	activities, total, err := svc.GetUserActivity(3, params)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, activities, 1)
+}
+
+func TestUserService_GetUserAssessments(t *testing.T) {
+	svc := newTestUserService(t)
+	params := util.PaginationParams{Limit: 10, SortBy: "created_at", SortDir: "DESC"}
+
+	svc.userRepo.On("FindByID", uint(3)).Return(&models.User{ID: 3}, nil)
+	svc.assessmentRepo.On("GetAssessmentHasAttemptByUser", mock.MatchedBy(func(p util.PaginationParams) bool {
+		return p.SortBy == "assessments.created_at"
+	}), uint(3)).Return([]models.Assessment{{ID: 8}}, int64(1), nil)
+
+	assessments, total, err := svc.GetUserAssessments(3, params)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, uint(8), assessments[0].ID)
+}
@@ -1,9 +1,19 @@
 package util
 
 import (
-	"github.com/golang-jwt/jwt/v5"
+	"assessment_service/configs"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
 	"os"
+	"sort"
 	"time"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
 type Jwt interface {
@@ -11,24 +21,154 @@ type Jwt interface {
 	ValidateToken(token string) (jwt.MapClaims, error)
 }
 
-const TOKEN_EXPIRED_TIME = 30 * time.Minute
+// KeySet exposes the public verification keys, served as the JWKS document
+type KeySet interface {
+	JWKS() JWKSet
+}
+
+// JWK is a single public key in a JSON Web Key Set (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+var ErrUnknownKeyID = errors.New("unknown jwt key id")
 
+// JwtImpl signs access tokens with the active key and verifies them with the active key or any
+// retired key still listed in the config, selected by the kid header
 type JwtImpl struct {
+	method     jwt.SigningMethod
+	kid        string
+	signingKey interface{}
+	verifyKeys map[string]interface{}
+	issuer     string
+	audience   string
+	expiry     time.Duration
+}
+
+func NewJwtImpl(config configs.AuthConfig) (*JwtImpl, error) {
+	j := &JwtImpl{
+		kid:        config.JWTKeyID,
+		verifyKeys: make(map[string]interface{}),
+		issuer:     config.JWTIssuer,
+		audience:   config.JWTAudience,
+		expiry:     config.AccessTokenExpiry,
+	}
+
+	if j.kid == "" {
+		j.kid = "default"
+	}
+
+	switch config.JWTAlgorithm {
+	case "", "HS256":
+		if config.JWTSecret == "" {
+			return nil, errors.New("jwt secret is required for HS256")
+		}
+		j.method = jwt.SigningMethodHS256
+		j.signingKey = []byte(config.JWTSecret)
+		j.verifyKeys[j.kid] = j.signingKey
+		for kid, secret := range config.JWTRetiredKeys {
+			j.verifyKeys[kid] = []byte(secret)
+		}
+	case "RS256", "EdDSA":
+		if config.JWTAlgorithm == "RS256" {
+			j.method = jwt.SigningMethodRS256
+		} else {
+			j.method = jwt.SigningMethodEdDSA
+		}
+
+		pemBytes, err := os.ReadFile(config.JWTPrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read jwt private key: %w", err)
+		}
+
+		privateKey, err := parsePrivateKey(j.method, pemBytes)
+		if err != nil {
+			return nil, err
+		}
+		j.signingKey = privateKey
+		j.verifyKeys[j.kid] = privateKey.Public()
+
+		for kid, path := range config.JWTRetiredKeys {
+			pemBytes, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read jwt public key %s: %w", kid, err)
+			}
+			publicKey, err := parsePublicKey(j.method, pemBytes)
+			if err != nil {
+				return nil, fmt.Errorf("invalid jwt public key %s: %w", kid, err)
+			}
+			j.verifyKeys[kid] = publicKey
+		}
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm %q", config.JWTAlgorithm)
+	}
+
+	if j.expiry <= 0 {
+		j.expiry = 30 * time.Minute
+	}
+
+	return j, nil
+}
+
+func parsePrivateKey(method jwt.SigningMethod, pemBytes []byte) (crypto.Signer, error) {
+	if method == jwt.SigningMethodRS256 {
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA private key: %w", err)
+		}
+		return key, nil
+	}
+
+	key, err := jwt.ParseEdPrivateKeyFromPEM(pemBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Ed25519 private key: %w", err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("invalid Ed25519 private key")
+	}
+	return signer, nil
 }
 
-func NewJwtImpl() *JwtImpl {
-	return &JwtImpl{}
+func parsePublicKey(method jwt.SigningMethod, pemBytes []byte) (crypto.PublicKey, error) {
+	if method == jwt.SigningMethodRS256 {
+		return jwt.ParseRSAPublicKeyFromPEM(pemBytes)
+	}
+	return jwt.ParseEdPublicKeyFromPEM(pemBytes)
 }
 
-func (j JwtImpl) GenerateToken(userID string, role string) (string, error) {
-	expireTime := time.Now().Add(TOKEN_EXPIRED_TIME)
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+func (j *JwtImpl) GenerateToken(userID string, role string) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub":    userID,
 		"userID": userID,
 		"role":   role,
-		"exp":    expireTime.Unix(),
-	})
+		"iat":    now.Unix(),
+		"exp":    now.Add(j.expiry).Unix(),
+	}
+	if j.issuer != "" {
+		claims["iss"] = j.issuer
+	}
+	if j.audience != "" {
+		claims["aud"] = j.audience
+	}
 
-	tokenString, err := token.SignedString([]byte(os.Getenv("SECRET_KEY")))
+	token := jwt.NewWithClaims(j.method, claims)
+	token.Header["kid"] = j.kid
+
+	tokenString, err := token.SignedString(j.signingKey)
 	if err != nil {
 		return "", err
 	}
@@ -36,13 +176,20 @@ func (j JwtImpl) GenerateToken(userID string, role string) (string, error) {
 	return tokenString, nil
 }
 
-func (j JwtImpl) ValidateToken(token string) (jwt.MapClaims, error) {
-	t, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-		return []byte(os.Getenv("SECRET_KEY")), nil
-	})
+func (j *JwtImpl) ValidateToken(token string) (jwt.MapClaims, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{j.method.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if j.issuer != "" {
+		options = append(options, jwt.WithIssuer(j.issuer))
+	}
+	if j.audience != "" {
+		options = append(options, jwt.WithAudience(j.audience))
+	}
+
+	t, err := jwt.Parse(token, j.keyFunc, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -52,5 +199,61 @@ func (j JwtImpl) ValidateToken(token string) (jwt.MapClaims, error) {
 		return nil, jwt.ErrTokenInvalidClaims
 	}
 
+	if _, ok := claims["iat"]; !ok {
+		return nil, fmt.Errorf("%w: missing iat", jwt.ErrTokenInvalidClaims)
+	}
+
+	if sub, err := claims.GetSubject(); err != nil || sub == "" {
+		return nil, fmt.Errorf("%w: missing sub", jwt.ErrTokenInvalidClaims)
+	}
+
 	return claims, nil
 }
+
+// keyFunc picks the verification key named by the kid header; tokens without a kid use the active key
+func (j *JwtImpl) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = j.kid
+	}
+
+	key, ok := j.verifyKeys[kid]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+
+	return key, nil
+}
+
+// JWKS returns the public keys other services need to verify our tokens. HS256 secrets are never
+// published, so the set is empty in that mode.
+func (j *JwtImpl) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+
+	for kid, key := range j.verifyKeys {
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: j.method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Kid: kid,
+				Use: "sig",
+				Alg: j.method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(k),
+			})
+		}
+	}
+
+	sort.Slice(set.Keys, func(a, b int) bool { return set.Keys[a].Kid < set.Keys[b].Kid })
+
+	return set
+}
@@ -1,34 +1,74 @@
 package util
 
 import (
+	"assessment_service/configs"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
-	"github.com/stretchr/testify/require"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-// setup sets the environment variable for the secret key before tests run.
-func setupJWTTest(t *testing.T) func(t *testing.T) {
-	// Set a temporary secret key for testing
-	originalSecret := os.Getenv("SECRET_KEY")
-	os.Setenv("SECRET_KEY", "test-secret-key-for-util") // Use a specific key for this test
+const testJWTSecret = "test-secret-key-for-util"
 
-	// Return a teardown function to restore the original value
-	return func(t *testing.T) {
-		os.Setenv("SECRET_KEY", originalSecret)
+func testJWTConfig() configs.AuthConfig {
+	return configs.AuthConfig{
+		JWTSecret:         testJWTSecret,
+		JWTAlgorithm:      "HS256",
+		JWTKeyID:          "k1",
+		JWTIssuer:         "assessment_service",
+		JWTAudience:       "assessment_service",
+		AccessTokenExpiry: 15 * time.Minute,
 	}
 }
 
-func TestGenerateToken(t *testing.T) {
-	// Setup
-	teardown := setupJWTTest(t)
-	defer teardown(t) // Ensure teardown runs even if test panics
+func newTestJwt(t *testing.T) *JwtImpl {
+	jwtService, err := NewJwtImpl(testJWTConfig())
+	require.NoError(t, err)
+	return jwtService
+}
 
-	jwtService := NewJwtImpl()
+// signHS256 signs arbitrary claims with the test secret, bypassing GenerateToken
+func signHS256(t *testing.T, claims jwt.MapClaims, kid string, secret string) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	tokenString, err := token.SignedString([]byte(secret))
+	require.NoError(t, err)
+	return tokenString
+}
+
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"sub":    "123",
+		"userID": "123",
+		"role":   "student",
+		"iss":    "assessment_service",
+		"aud":    "assessment_service",
+		"iat":    now.Unix(),
+		"exp":    now.Add(time.Hour).Unix(),
+	}
+}
+
+func writePEM(t *testing.T, blockType string, der []byte) string {
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return path
+}
+
+func TestGenerateToken(t *testing.T) {
+	jwtService := newTestJwt(t)
 	userID := "123"
 	role := "teacher"
 
@@ -39,148 +79,235 @@ func TestGenerateToken(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
 
-	// Optional: Decode and check claims (without validation here)
+	// Decode and check claims (without validation here)
 	parsedToken, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
 	assert.NoError(t, err, "Should be able to parse the generated token")
+	assert.Equal(t, "k1", parsedToken.Header["kid"])
 	claims, ok := parsedToken.Claims.(jwt.MapClaims)
 	assert.True(t, ok, "Claims should be MapClaims")
 	assert.Equal(t, userID, claims["userID"])
+	assert.Equal(t, userID, claims["sub"])
 	assert.Equal(t, role, claims["role"])
-	// Check expiry is roughly correct (within a reasonable window)
+	assert.Equal(t, "assessment_service", claims["iss"])
+	assert.Equal(t, "assessment_service", claims["aud"])
+	assert.NotNil(t, claims["iat"])
+	// Expiry comes from AuthConfig.AccessTokenExpiry
 	expFloat, ok := claims["exp"].(float64)
 	assert.True(t, ok, "Expiry should be a number")
-	expectedExp := time.Now().Add(TOKEN_EXPIRED_TIME).Unix()
+	expectedExp := time.Now().Add(15 * time.Minute).Unix()
 	assert.InDelta(t, expectedExp, int64(expFloat), 5, "Expiry time should be close to expected") // Allow 5s delta
 }
 
 func TestValidateToken(t *testing.T) {
-	// Setup
-	teardown := setupJWTTest(t)
-	defer teardown(t)
-
-	jwtService := NewJwtImpl()
-	userID := "123"
-	role := "teacher"
+	jwtService := newTestJwt(t)
 
 	// Generate a token first
-	token, err := jwtService.GenerateToken(userID, role)
-	require.NoError(t, err) // Use require as validation depends on valid generation
+	token, err := jwtService.GenerateToken("123", "teacher")
+	require.NoError(t, err)
 
-	// Test validation
 	claims, err := jwtService.ValidateToken(token)
 
-	// Assertions
 	assert.NoError(t, err)
-	require.NotNil(t, claims) // Use require as subsequent checks depend on non-nil claims
-	assert.Equal(t, userID, claims["userID"])
-	assert.Equal(t, role, claims["role"])
+	require.NotNil(t, claims)
+	assert.Equal(t, "123", claims["userID"])
+	assert.Equal(t, "teacher", claims["role"])
 }
 
 func TestValidateExpiredToken(t *testing.T) {
-	// Setup
-	teardown := setupJWTTest(t)
-	defer teardown(t)
+	jwtService := newTestJwt(t)
 
-	jwtService := NewJwtImpl()
-	secretKey := []byte(os.Getenv("SECRET_KEY")) // Get the key set in setup
+	claims := validClaims()
+	claims["exp"] = time.Now().Add(-time.Hour).Unix()
+	tokenString := signHS256(t, claims, "k1", testJWTSecret)
 
-	// Create an expired token manually
-	expiredTime := time.Now().Add(-time.Hour) // 1 hour in the past
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"userID": "123",
-		"role":   "student",
-		"exp":    expiredTime.Unix(),
-	})
+	result, err := jwtService.ValidateToken(tokenString)
 
-	tokenString, err := token.SignedString(secretKey)
-	require.NoError(t, err)
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, jwt.ErrTokenExpired), "Expected ErrTokenExpired")
+}
 
-	// Validate the expired token
-	claims, err := jwtService.ValidateToken(tokenString)
+func TestValidateInvalidTokenSignature(t *testing.T) {
+	jwtService := newTestJwt(t)
+
+	tokenSignedWithWrongKey := signHS256(t, validClaims(), "k1", "different-secret-key")
+
+	claims, err := jwtService.ValidateToken(tokenSignedWithWrongKey)
 
-	// Should get an error for expired token
 	assert.Error(t, err)
 	assert.Nil(t, claims)
-	// Check for specific JWT error if possible (optional but good)
-	assert.True(t, errors.Is(err, jwt.ErrTokenExpired) || errors.Is(err, jwt.ErrTokenInvalidClaims), "Expected ErrTokenExpired or ErrTokenInvalidClaims")
+	assert.True(t, errors.Is(err, jwt.ErrSignatureInvalid), "Expected ErrSignatureInvalid")
+}
+
+func TestValidateMalformedToken(t *testing.T) {
+	jwtService := newTestJwt(t)
+
+	claims, err := jwtService.ValidateToken("this.is.not.a.valid.jwt")
 
+	assert.Error(t, err)
+	assert.Nil(t, claims)
 }
 
-func TestValidateInvalidTokenSignature(t *testing.T) {
-	// Setup
-	teardown := setupJWTTest(t)
-	defer teardown(t)
+func TestValidateToken_StandardClaims(t *testing.T) {
+	jwtService := newTestJwt(t)
 
-	jwtService := NewJwtImpl()
-	secretKey := []byte(os.Getenv("SECRET_KEY"))
-	wrongSecretKey := []byte("different-secret-key")
+	cases := map[string]func(jwt.MapClaims){
+		"WrongIssuer":   func(c jwt.MapClaims) { c["iss"] = "someone-else" },
+		"WrongAudience": func(c jwt.MapClaims) { c["aud"] = "another-service" },
+		"MissingSub":    func(c jwt.MapClaims) { delete(c, "sub") },
+		"MissingIat":    func(c jwt.MapClaims) { delete(c, "iat") },
+		"IatInFuture":   func(c jwt.MapClaims) { c["iat"] = time.Now().Add(time.Hour).Unix() },
+		"MissingExp":    func(c jwt.MapClaims) { delete(c, "exp") },
+	}
 
-	// Generate token with correct key
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"userID": "123",
-		"role":   "student",
-		"exp":    time.Now().Add(time.Hour).Unix(),
-	})
-	_, err := token.SignedString(secretKey)
-	require.NoError(t, err)
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			claims := validClaims()
+			mutate(claims)
+
+			result, err := jwtService.ValidateToken(signHS256(t, claims, "k1", testJWTSecret))
+
+			assert.Error(t, err)
+			assert.Nil(t, result)
+		})
+	}
+}
+
+func TestValidateToken_RejectsOtherAlgorithms(t *testing.T) {
+	jwtService := newTestJwt(t)
 
-	// Try to validate with the wrong key (simulated by tampering or wrong key)
-	// We can't directly validate with a wrong key in ValidateToken,
-	// but we can test with a token signed by a different key.
-	tokenSignedWithWrongKey, err := token.SignedString(wrongSecretKey)
+	token := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims())
+	tokenString, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
 	require.NoError(t, err)
 
-	claims, err := jwtService.ValidateToken(tokenSignedWithWrongKey)
+	claims, err := jwtService.ValidateToken(tokenString)
 
-	// Should get an error for invalid signature
 	assert.Error(t, err)
 	assert.Nil(t, claims)
-	assert.True(t, errors.Is(err, jwt.ErrSignatureInvalid), "Expected ErrSignatureInvalid")
 }
 
-func TestValidateMalformedToken(t *testing.T) {
-	// Setup
-	teardown := setupJWTTest(t)
-	defer teardown(t)
+func TestValidateToken_KeyRotation(t *testing.T) {
+	config := testJWTConfig()
+	config.JWTSecret = "new-secret"
+	config.JWTKeyID = "k2"
+	config.JWTRetiredKeys = map[string]string{"k1": testJWTSecret}
+	jwtService, err := NewJwtImpl(config)
+	require.NoError(t, err)
+
+	t.Run("RetiredKeyStillAccepted", func(t *testing.T) {
+		claims, err := jwtService.ValidateToken(signHS256(t, validClaims(), "k1", testJWTSecret))
+		assert.NoError(t, err)
+		assert.Equal(t, "123", claims["userID"])
+	})
+
+	t.Run("NewTokensUseActiveKey", func(t *testing.T) {
+		token, err := jwtService.GenerateToken("1", "admin")
+		require.NoError(t, err)
+		parsed, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+		require.NoError(t, err)
+		assert.Equal(t, "k2", parsed.Header["kid"])
+	})
+
+	t.Run("UnknownKid", func(t *testing.T) {
+		_, err := jwtService.ValidateToken(signHS256(t, validClaims(), "k0", testJWTSecret))
+		assert.ErrorIs(t, err, ErrUnknownKeyID)
+	})
+
+	t.Run("HS256KeysAreNotPublished", func(t *testing.T) {
+		assert.Empty(t, jwtService.JWKS().Keys)
+	})
+}
+
+func TestJwt_RS256(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	config := testJWTConfig()
+	config.JWTAlgorithm = "RS256"
+	config.JWTPrivateKeyFile = writePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(privateKey))
+	jwtService, err := NewJwtImpl(config)
+	require.NoError(t, err)
 
-	jwtService := NewJwtImpl()
+	token, err := jwtService.GenerateToken("42", "teacher")
+	require.NoError(t, err)
 
-	// Test with invalid token string
-	malformedToken := "this.is.not.a.valid.jwt"
-	claims, err := jwtService.ValidateToken(malformedToken)
+	claims, err := jwtService.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "42", claims["sub"])
 
-	// Should get an error
+	// An HS256 token signed with the public modulus must not pass (algorithm confusion)
+	_, err = jwtService.ValidateToken(signHS256(t, validClaims(), "k1", string(privateKey.N.Bytes())))
 	assert.Error(t, err)
-	assert.Nil(t, claims)
+
+	jwks := jwtService.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+	assert.Equal(t, "k1", jwks.Keys[0].Kid)
+	assert.Equal(t, "RS256", jwks.Keys[0].Alg)
+	assert.Equal(t, "AQAB", jwks.Keys[0].E)
+	assert.NotEmpty(t, jwks.Keys[0].N)
 }
 
-func TestValidateToken_MissingClaims(t *testing.T) {
-	// Setup
-	teardown := setupJWTTest(t)
-	defer teardown(t)
+func TestJwt_EdDSA_WithRetiredKey(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
 
-	jwtService := NewJwtImpl()
-	secretKey := []byte(os.Getenv("SECRET_KEY"))
+	oldPublic, oldPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	oldPublicDER, err := x509.MarshalPKIXPublicKey(oldPublic)
+	require.NoError(t, err)
 
-	// Create a token *without* userID or role
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"exp": time.Now().Add(time.Hour).Unix(),
-		// Missing "userID" and "role"
-	})
-	tokenString, err := token.SignedString(secretKey)
+	config := testJWTConfig()
+	config.JWTAlgorithm = "EdDSA"
+	config.JWTKeyID = "ed-2"
+	config.JWTPrivateKeyFile = writePEM(t, "PRIVATE KEY", privateDER)
+	config.JWTRetiredKeys = map[string]string{"ed-1": writePEM(t, "PUBLIC KEY", oldPublicDER)}
+	jwtService, err := NewJwtImpl(config)
 	require.NoError(t, err)
 
-	// Validate the token
-	claims, err := jwtService.ValidateToken(tokenString)
+	token, err := jwtService.GenerateToken("7", "student")
+	require.NoError(t, err)
+	_, err = jwtService.ValidateToken(token)
+	assert.NoError(t, err)
 
-	// Validation itself might pass if expiry is okay,
-	// but the claims map will be missing expected fields.
-	// The current ValidateToken doesn't explicitly check for missing required claims,
-	// it just returns the parsed claims. Middleware using this should check.
-	assert.NoError(t, err) // Expect no parsing/validation error based on expiry/signature
-	require.NotNil(t, claims)
-	_, userIDExists := claims["userID"]
-	_, roleExists := claims["role"]
-	assert.False(t, userIDExists, "userID claim should be missing")
-	assert.False(t, roleExists, "role claim should be missing")
+	// Token signed by the retired key is still valid
+	old := jwt.NewWithClaims(jwt.SigningMethodEdDSA, validClaims())
+	old.Header["kid"] = "ed-1"
+	oldToken, err := old.SignedString(oldPrivate)
+	require.NoError(t, err)
+	_, err = jwtService.ValidateToken(oldToken)
+	assert.NoError(t, err)
+
+	jwks := jwtService.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "ed-1", jwks.Keys[0].Kid)
+	assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+	assert.Equal(t, "Ed25519", jwks.Keys[0].Crv)
+	assert.Equal(t, "ed-2", jwks.Keys[1].Kid)
+}
+
+func TestNewJwtImpl_InvalidConfig(t *testing.T) {
+	t.Run("UnsupportedAlgorithm", func(t *testing.T) {
+		config := testJWTConfig()
+		config.JWTAlgorithm = "HS512"
+		_, err := NewJwtImpl(config)
+		assert.Error(t, err)
+	})
+
+	t.Run("MissingPrivateKey", func(t *testing.T) {
+		config := testJWTConfig()
+		config.JWTAlgorithm = "RS256"
+		config.JWTPrivateKeyFile = filepath.Join(t.TempDir(), "missing.pem")
+		_, err := NewJwtImpl(config)
+		assert.Error(t, err)
+	})
+
+	t.Run("EmptySecret", func(t *testing.T) {
+		config := testJWTConfig()
+		config.JWTSecret = ""
+		_, err := NewJwtImpl(config)
+		assert.Error(t, err)
+	})
 }
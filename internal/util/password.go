@@ -0,0 +1,50 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const MIN_PASSWORD_LENGTH = 8
+
+var ErrPasswordTooShort = errors.New("password must be at least 8 characters")
+
+// HashPassword hashes a plain text password with bcrypt
+func HashPassword(password string) (string, error) {
+	if len(password) < MIN_PASSWORD_LENGTH {
+		return "", ErrPasswordTooShort
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hashed), nil
+}
+
+// CheckPassword reports whether the plain text password matches the bcrypt hash
+func CheckPassword(hashed, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hashed), []byte(password)) == nil
+}
+
+// GenerateOpaqueToken returns a random URL-safe token, used for refresh and reset tokens
+func GenerateOpaqueToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// HashToken returns the SHA-256 hex digest of an opaque token so it can be stored safely
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,43 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashPassword(t *testing.T) {
+	hashed, err := HashPassword("correct-horse")
+	require.NoError(t, err)
+	assert.NotEqual(t, "correct-horse", hashed)
+
+	assert.True(t, CheckPassword(hashed, "correct-horse"))
+	assert.False(t, CheckPassword(hashed, "wrong-horse"))
+}
+
+func TestHashPassword_TooShort(t *testing.T) {
+	hashed, err := HashPassword("short")
+	assert.ErrorIs(t, err, ErrPasswordTooShort)
+	assert.Empty(t, hashed)
+}
+
+func TestCheckPassword_InvalidHash(t *testing.T) {
+	assert.False(t, CheckPassword("not-a-bcrypt-hash", "whatever"))
+}
+
+func TestGenerateOpaqueToken(t *testing.T) {
+	first, err := GenerateOpaqueToken()
+	require.NoError(t, err)
+	second, err := GenerateOpaqueToken()
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, first)
+	assert.NotEqual(t, first, second, "Tokens should be random")
+}
+
+func TestHashToken(t *testing.T) {
+	assert.Equal(t, HashToken("abc"), HashToken("abc"))
+	assert.NotEqual(t, HashToken("abc"), HashToken("abd"))
+	assert.Len(t, HashToken("abc"), 64)
+}
@@ -3,19 +3,17 @@ package util
 import (
 	models "assessment_service/internal/model"
 	"math/rand"
-
-	"time"
+	"sort"
 )
 
 // ShuffleQuestions randomizes the order of questions using Fisher-Yates shuffle algorithm
-// This is used when an assessment has the RandomizeQuestions setting enabled
-func ShuffleQuestions(questions []models.Question) []models.Question {
+// The order comes from seed, so the same seed always gives the same order
+func ShuffleQuestions(questions []models.Question, seed int64) []models.Question {
 	// Create a copy of the original slice to avoid modifying the input
 	result := make([]models.Question, len(questions))
 	copy(result, questions)
 
-	// Use current time as a seed for randomness
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	r := rand.New(rand.NewSource(seed))
 
 	// Fisher-Yates shuffle algorithm
 	for i := len(result) - 1; i > 0; i-- {
@@ -29,15 +27,16 @@ func ShuffleQuestions(questions []models.Question) []models.Question {
 	return result
 }
 
-// ShuffleQuestionOptions randomizes the order of options for multiple-choice questions
-// This can be used to further randomize the assessment experience
-func ShuffleQuestionOptions(options []models.QuestionOption) []models.QuestionOption {
+// ShuffleQuestionOptions randomizes the order of a question's options
+// The options are put in the order they were created first, so the same seed gives the same order
+// however the options were loaded
+func ShuffleQuestionOptions(options []models.QuestionOption, seed int64) []models.QuestionOption {
 	// Create a copy of the original slice to avoid modifying the input
 	result := make([]models.QuestionOption, len(options))
 	copy(result, options)
+	sort.SliceStable(result, func(i, j int) bool { return result[i].ID < result[j].ID })
 
-	// Use current time as a seed for randomness
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	r := rand.New(rand.NewSource(seed))
 
 	// Fisher-Yates shuffle algorithm
 	for i := len(result) - 1; i > 0; i-- {
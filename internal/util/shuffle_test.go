@@ -78,7 +78,7 @@ func TestShuffleQuestions(t *testing.T) {
 	}
 
 	// Test with multiple questions
-	shuffled := ShuffleQuestions(originalQuestions)
+	shuffled := ShuffleQuestions(originalQuestions, 42)
 
 	// 1. Check length
 	assert.Equal(t, len(originalQuestions), len(shuffled), "Shuffled slice should have the same length")
@@ -91,28 +91,27 @@ func TestShuffleQuestions(t *testing.T) {
 		{ID: 1, Text: "Q1"}, {ID: 2, Text: "Q2"}, {ID: 3, Text: "Q3"}, {ID: 4, Text: "Q4"}, {ID: 5, Text: "Q5"},
 	}), "Original slice should not be modified")
 
-	// 4. Check for potential shuffling (probabilistic)
-	// Run shuffle multiple times and check if the result is different from original at least once
+	// 4. The same seed always gives the same order
+	assert.True(t, areQuestionSlicesIdentical(shuffled, ShuffleQuestions(originalQuestions, 42)), "The same seed should give the same order")
+
+	// 5. Different seeds give different orders
 	isDifferent := false
-	for i := 0; i < 10; i++ { // Run a few times
-		shuffledAgain := ShuffleQuestions(originalQuestions)
-		if !areQuestionSlicesIdentical(originalQuestions, shuffledAgain) {
+	for seed := int64(0); seed < 10; seed++ {
+		if !areQuestionSlicesIdentical(originalQuestions, ShuffleQuestions(originalQuestions, seed)) {
 			isDifferent = true
 			break
 		}
 	}
-	// This assertion might occasionally fail due to pure chance, especially with small slices.
-	// For robust testing of randomness, more sophisticated statistical tests would be needed.
-	assert.True(t, isDifferent, "Shuffle function should likely produce a different order (probabilistic check)")
+	assert.True(t, isDifferent, "Some seed should produce a different order")
 
 	// Test with empty slice
 	emptyOriginal := []models.Question{}
-	shuffledEmpty := ShuffleQuestions(emptyOriginal)
+	shuffledEmpty := ShuffleQuestions(emptyOriginal, 1)
 	assert.Empty(t, shuffledEmpty, "Shuffling an empty slice should result in an empty slice")
 
 	// Test with single element slice
 	singleOriginal := []models.Question{{ID: 10, Text: "Single"}}
-	shuffledSingle := ShuffleQuestions(singleOriginal)
+	shuffledSingle := ShuffleQuestions(singleOriginal, 1)
 	assert.Equal(t, singleOriginal, shuffledSingle, "Shuffling a single-element slice should return the same slice")
 	assert.True(t, areQuestionSlicesIdentical(singleOriginal, shuffledSingle))
 
@@ -126,7 +125,7 @@ func TestShuffleQuestionOptions(t *testing.T) {
 		{ID: 4, OptionID: "d", Text: "Opt4"},
 	}
 
-	shuffled := ShuffleQuestionOptions(originalOptions)
+	shuffled := ShuffleQuestionOptions(originalOptions, 42)
 
 	// 1. Check length
 	assert.Equal(t, len(originalOptions), len(shuffled), "Shuffled slice should have the same length")
@@ -139,25 +138,28 @@ func TestShuffleQuestionOptions(t *testing.T) {
 		{ID: 1, OptionID: "a", Text: "Opt1"}, {ID: 2, OptionID: "b", Text: "Opt2"}, {ID: 3, OptionID: "c", Text: "Opt3"}, {ID: 4, OptionID: "d", Text: "Opt4"},
 	}), "Original slice should not be modified")
 
-	// 4. Check for potential shuffling (probabilistic)
+	// 4. The same seed gives the same order, whatever order the options were loaded in
+	reversed := []models.QuestionOption{originalOptions[3], originalOptions[2], originalOptions[1], originalOptions[0]}
+	assert.True(t, areOptionSlicesIdentical(shuffled, ShuffleQuestionOptions(reversed, 42)), "The same seed should give the same order")
+
+	// 5. Different seeds give different orders
 	isDifferent := false
-	for i := 0; i < 10; i++ {
-		shuffledAgain := ShuffleQuestionOptions(originalOptions)
-		if !areOptionSlicesIdentical(originalOptions, shuffledAgain) {
+	for seed := int64(0); seed < 10; seed++ {
+		if !areOptionSlicesIdentical(originalOptions, ShuffleQuestionOptions(originalOptions, seed)) {
 			isDifferent = true
 			break
 		}
 	}
-	assert.True(t, isDifferent, "Shuffle function should likely produce a different order (probabilistic check)")
+	assert.True(t, isDifferent, "Some seed should produce a different order")
 
 	// Test with empty slice
 	emptyOriginal := []models.QuestionOption{}
-	shuffledEmpty := ShuffleQuestionOptions(emptyOriginal)
+	shuffledEmpty := ShuffleQuestionOptions(emptyOriginal, 1)
 	assert.Empty(t, shuffledEmpty, "Shuffling an empty slice should result in an empty slice")
 
 	// Test with single element slice
 	singleOriginal := []models.QuestionOption{{ID: 10, OptionID: "a", Text: "Single"}}
-	shuffledSingle := ShuffleQuestionOptions(singleOriginal)
+	shuffledSingle := ShuffleQuestionOptions(singleOriginal, 1)
 	assert.Equal(t, singleOriginal, shuffledSingle, "Shuffling a single-element slice should return the same slice")
 
 }
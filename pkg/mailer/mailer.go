@@ -0,0 +1,201 @@
+package mailer
+
+import (
+	"assessment_service/configs"
+	"bytes"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mailer sends HTML emails rendered from the templates in the template directory
+type Mailer interface {
+	Send(to []string, subject, templateName string, data interface{}) error
+}
+
+// Message is a rendered email
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+	SentAt  time.Time
+}
+
+// NewMailer creates the Mailer selected by config.Driver
+func NewMailer(config configs.MailConfig) (Mailer, error) {
+	renderer := NewTemplateRenderer(config.TemplateDir)
+
+	switch config.Driver {
+	case "smtp":
+		return NewSMTPMailer(renderer, config), nil
+	case "file":
+		return NewFileMailer(renderer, config.OutputDir), nil
+	case "memory":
+		return NewMemoryMailer(renderer), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", config.Driver)
+	}
+}
+
+// TemplateRenderer parses templates from a directory and caches them by name
+type TemplateRenderer struct {
+	dir       string
+	mu        sync.Mutex
+	templates map[string]*template.Template
+}
+
+func NewTemplateRenderer(dir string) *TemplateRenderer {
+	return &TemplateRenderer{dir: dir, templates: make(map[string]*template.Template)}
+}
+
+// Render executes the named template (e.g. "reset_password.html") with data
+func (r *TemplateRenderer) Render(name string, data interface{}) (string, error) {
+	r.mu.Lock()
+	tmpl, ok := r.templates[name]
+	if !ok {
+		var err error
+		tmpl, err = template.ParseFiles(filepath.Join(r.dir, name))
+		if err != nil {
+			r.mu.Unlock()
+			return "", fmt.Errorf("failed to parse mail template %s: %w", name, err)
+		}
+		r.templates[name] = tmpl
+	}
+	r.mu.Unlock()
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render mail template %s: %w", name, err)
+	}
+
+	return buf.String(), nil
+}
+
+// SMTPMailer delivers emails through an SMTP server
+type SMTPMailer struct {
+	renderer *TemplateRenderer
+	addr     string
+	auth     smtp.Auth
+	from     string
+}
+
+func NewSMTPMailer(renderer *TemplateRenderer, config configs.MailConfig) *SMTPMailer {
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+
+	return &SMTPMailer{
+		renderer: renderer,
+		addr:     fmt.Sprintf("%s:%d", config.Host, config.Port),
+		auth:     auth,
+		from:     config.From,
+	}
+}
+
+func (m *SMTPMailer) Send(to []string, subject, templateName string, data interface{}) error {
+	body, err := m.renderer.Render(templateName, data)
+	if err != nil {
+		return err
+	}
+
+	msg, err := buildMessage(m.from, to, subject, body)
+	if err != nil {
+		return err
+	}
+
+	if err := smtp.SendMail(m.addr, m.auth, m.from, to, msg); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+
+	return nil
+}
+
+// buildMessage writes the headers and body of an HTML email. The subject is encoded so that it can
+// hold any UTF-8 text, and a line break in it is rejected so it cannot add headers.
+func buildMessage(from string, to []string, subject, body string) ([]byte, error) {
+	if strings.ContainsAny(subject, "\r\n") {
+		return nil, fmt.Errorf("invalid mail subject: must not contain line breaks")
+	}
+
+	var msg bytes.Buffer
+	msg.WriteString("From: " + from + "\r\n")
+	msg.WriteString("To: " + strings.Join(to, ", ") + "\r\n")
+	msg.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=\"UTF-8\"\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(body)
+
+	return msg.Bytes(), nil
+}
+
+// FileMailer writes every email to an HTML file instead of sending it, for local development
+type FileMailer struct {
+	renderer *TemplateRenderer
+	dir      string
+}
+
+func NewFileMailer(renderer *TemplateRenderer, dir string) *FileMailer {
+	return &FileMailer{renderer: renderer, dir: dir}
+}
+
+func (m *FileMailer) Send(to []string, subject, templateName string, data interface{}) error {
+	body, err := m.renderer.Render(templateName, data)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create mail output dir: %w", err)
+	}
+
+	name := fmt.Sprintf("%d-%s", time.Now().UnixNano(), templateName)
+	content := fmt.Sprintf("<!-- To: %s -->\n<!-- Subject: %s -->\n%s", strings.Join(to, ", "), subject, body)
+
+	if err := os.WriteFile(filepath.Join(m.dir, name), []byte(content), 0o644); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+
+	return nil
+}
+
+// MemoryMailer keeps sent emails in memory, for tests
+type MemoryMailer struct {
+	renderer *TemplateRenderer
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryMailer(renderer *TemplateRenderer) *MemoryMailer {
+	return &MemoryMailer{renderer: renderer}
+}
+
+func (m *MemoryMailer) Send(to []string, subject, templateName string, data interface{}) error {
+	body, err := m.renderer.Render(templateName, data)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, Message{To: to, Subject: subject, Body: body, SentAt: time.Now()})
+
+	return nil
+}
+
+// Messages returns a copy of the emails sent so far
+func (m *MemoryMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make([]Message, len(m.messages))
+	copy(result, m.messages)
+	return result
+}
@@ -0,0 +1,77 @@
+package mailer
+
+import (
+	"assessment_service/configs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTemplateDir = "../../assets"
+
+func TestMemoryMailer_RendersResetTemplate(t *testing.T) {
+	m := NewMemoryMailer(NewTemplateRenderer(testTemplateDir))
+
+	err := m.Send([]string{"john@example.com"}, "Reset", "reset_password.html", map[string]interface{}{
+		"Name":      "John",
+		"ResetLink": "http://localhost/reset?token=abc",
+		"ExpiresAt": "10:00 01/01/2025",
+	})
+	require.NoError(t, err)
+
+	messages := m.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, []string{"john@example.com"}, messages[0].To)
+	assert.Contains(t, messages[0].Body, "Xin chào John")
+	assert.Contains(t, messages[0].Body, "http://localhost/reset?token=abc")
+}
+
+func TestMemoryMailer_UnknownTemplate(t *testing.T) {
+	m := NewMemoryMailer(NewTemplateRenderer(testTemplateDir))
+
+	err := m.Send([]string{"john@example.com"}, "Reset", "missing.html", nil)
+
+	assert.Error(t, err)
+	assert.Empty(t, m.Messages())
+}
+
+func TestFileMailer_WritesMessage(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewMailer(configs.MailConfig{Driver: "file", TemplateDir: testTemplateDir, OutputDir: dir})
+	require.NoError(t, err)
+
+	err = m.Send([]string{"john@example.com"}, "Reset", "reset_password.html", map[string]interface{}{"Name": "John"})
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*-reset_password.html"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	content, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "<!-- To: john@example.com -->")
+}
+
+func TestNewMailer_UnknownDriver(t *testing.T) {
+	_, err := NewMailer(configs.MailConfig{Driver: "pigeon"})
+	assert.Error(t, err)
+}
+
+func TestBuildMessage_Subject(t *testing.T) {
+	msg, err := buildMessage("noreply@example.com", []string{"john@example.com"}, "Đặt lại mật khẩu", "<p>Hi</p>")
+	require.NoError(t, err)
+	assert.Contains(t, string(msg), "Subject: =?utf-8?q?")
+	assert.NotContains(t, string(msg), "Đặt lại")
+
+	// Tiêu đề thuần ASCII giữ nguyên
+	msg, err = buildMessage("noreply@example.com", []string{"john@example.com"}, "Reset", "<p>Hi</p>")
+	require.NoError(t, err)
+	assert.Contains(t, string(msg), "Subject: Reset\r\n")
+
+	// Xuống dòng trong tiêu đề có thể chèn thêm header
+	_, err = buildMessage("noreply@example.com", []string{"john@example.com"}, "Reset\r\nBcc: evil@example.com", "<p>Hi</p>")
+	assert.Error(t, err)
+}
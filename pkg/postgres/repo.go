@@ -25,18 +25,152 @@ func Connect(config configs.DatabaseConfig) (*gorm.DB, error) {
 }
 
 func RunMigrations(db *gorm.DB) error {
+	// Attempts started before they kept their option shuffle take it from their assessment, once
+	snapshotShuffle := !db.Migrator().HasColumn(&models.Attempt{}, "ShuffleOptions")
+
 	// Migrate all models
-	return db.AutoMigrate(
+	err := db.AutoMigrate(
 		&models.User{},
+		&models.RefreshToken{},
+		&models.PasswordResetToken{},
 		&models.Assessment{},
 		&models.Question{},
 		&models.QuestionOption{},
+		&models.AssessmentSection{},
+		&models.BankQuestion{},
+		&models.BankQuestionVersion{},
 		&models.Attempt{},
+		&models.AttemptQuestion{},
+		&models.AttemptTimeEvent{},
 		&models.Answer{},
+		&models.RubricScore{},
+		&models.GradingAssignment{},
+		&models.GradeEvent{},
+		&models.RegradeRequest{},
 		&models.Activity{},
 		&models.SuspiciousActivity{},
 		&models.AssessmentSettings{},
+		&models.AssessmentCollaborator{},
+		&models.Group{},
+		&models.GroupMember{},
+		&models.AssessmentAssignment{},
+		&models.AssessmentAccommodation{},
+		&models.AssessmentStatusTransition{},
 	)
+	if err != nil {
+		return err
+	}
+
+	if err := migrateAssessmentStatuses(db); err != nil {
+		return err
+	}
+
+	if err := migrateAttemptStatuses(db); err != nil {
+		return err
+	}
+
+	if snapshotShuffle {
+		if err := migrateShuffleOptions(db); err != nil {
+			return err
+		}
+	}
+
+	return migrateAwardedPoints(db)
+}
+
+// migrateAssessmentStatuses rewrites the statuses assessments were saved with before the status
+// lifecycle, whatever their case, to the typed statuses: Duplicate wrote "draft" and the student
+// service looked for "active". Assessments closed by the old scheduler were marked "Expired", which
+// is now "Closed", and assessments without a status are drafts.
+func migrateAssessmentStatuses(db *gorm.DB) error {
+	legacy := map[models.AssessmentStatus][]string{
+		models.AssessmentDraft:     {"draft", ""},
+		models.AssessmentScheduled: {"scheduled"},
+		models.AssessmentActive:    {"active"},
+		models.AssessmentClosed:    {"closed", "expired"},
+		models.AssessmentArchived:  {"archived"},
+	}
+
+	for status, values := range legacy {
+		err := db.Unscoped().Model(&models.Assessment{}).
+			Where("LOWER(COALESCE(status, '')) IN ? AND COALESCE(status, '') <> ?", values, status).
+			UpdateColumn("status", status).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateAttemptStatuses rewrites attempts saved before the attempt lifecycle, when the status mixed
+// progress with the pass/fail outcome. Attempts already migrated are left alone.
+func migrateAttemptStatuses(db *gorm.DB) error {
+	passedByScore := gorm.Expr("score >= (SELECT passing_score FROM assessments WHERE assessments.id = attempts.assessment_id)")
+
+	migrations := []struct {
+		where   string
+		args    []interface{}
+		columns map[string]interface{}
+	}{
+		{"status IN ?", []interface{}{[]string{"In Progress", "in progress"}},
+			map[string]interface{}{"status": models.AttemptInProgress}},
+		// The old code passed or failed attempts while essays still waited for a teacher, so those
+		// attempts go back to the grading queue without an outcome
+		{"status IN ? AND EXISTS (SELECT 1 FROM answers WHERE answers.attempt_id = attempts.id AND answers.is_correct IS NULL)",
+			[]interface{}{[]string{"Passed", "Failed", "Completed", "completed"}},
+			map[string]interface{}{"status": models.AttemptPendingManualGrading, "passed": nil}},
+		{"status = ?", []interface{}{"Passed"},
+			map[string]interface{}{"status": models.AttemptGraded, "passed": true}},
+		{"status = ?", []interface{}{"Failed"},
+			map[string]interface{}{"status": models.AttemptGraded, "passed": false}},
+		// Completed attempts never recorded an outcome, so it is worked out from the score
+		{"status IN ? AND score IS NOT NULL", []interface{}{[]string{"Completed", "completed"}},
+			map[string]interface{}{"status": models.AttemptGraded, "passed": passedByScore}},
+		{"status IN ?", []interface{}{[]string{"Completed", "completed"}},
+			map[string]interface{}{"status": models.AttemptSubmitted}},
+		// Expired attempts ran out of time without being handed in
+		{"status = ?", []interface{}{"Expired"},
+			map[string]interface{}{"status": models.AttemptVoided}},
+	}
+
+	for _, m := range migrations {
+		err := db.Unscoped().Model(&models.Attempt{}).
+			Where(m.where, m.args...).
+			UpdateColumns(m.columns).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateShuffleOptions marks the attempts of assessments that shuffle options as shuffled, which is
+// how they were shown while the setting was read when presenting them
+func migrateShuffleOptions(db *gorm.DB) error {
+	shuffled := db.Model(&models.AssessmentSettings{}).Select("assessment_id").Where("shuffle_options = ?", true)
+
+	return db.Unscoped().Model(&models.Attempt{}).
+		Where("assessment_id IN (?)", shuffled).
+		UpdateColumn("shuffle_options", true).Error
+}
+
+// migrateAwardedPoints gives answers graded before partial credit the points they earned then: all
+// of the question's points when correct and none when wrong. Answers waiting for a teacher keep nil.
+func migrateAwardedPoints(db *gorm.DB) error {
+	questionPoints := gorm.Expr("(SELECT points FROM questions WHERE questions.id = answers.question_id)")
+
+	err := db.Model(&models.Answer{}).
+		Where("awarded_points IS NULL AND is_correct = ?", true).
+		UpdateColumn("awarded_points", questionPoints).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Model(&models.Answer{}).
+		Where("awarded_points IS NULL AND is_correct = ?", false).
+		UpdateColumn("awarded_points", 0).Error
 }
 
 // Close the database connection